@@ -3,6 +3,7 @@ package ast
 import (
 	"bytes"
 	"strings"
+	"sync/atomic"
 
 	"github.com/ysugimoto/falco/v2/token"
 )
@@ -103,18 +104,39 @@ func (m *Meta) CloneWithoutComments() *Meta {
 	}
 }
 
-var idCounter uint64
+var idCounter atomic.Uint64
 
 func New(t token.Token, nest int, comments ...Comments) *Meta {
-	idCounter++
-	m := &Meta{
-		ID:       idCounter,
-		Token:    t,
-		Nest:     nest,
-		Leading:  Comments{},
-		Trailing: Comments{},
-		Infix:    Comments{},
+	m := &Meta{}
+	initMeta(m, t, nest, comments...)
+	return m
+}
+
+// MetaAllocator allocates Meta structs in chunks to reduce allocations on parsing large VCLs.
+// The allocator is not goroutine safe so it should be owned by a parser
+type MetaAllocator struct {
+	chunk []Meta
+}
+
+const metaChunkSize = 256
+
+func (a *MetaAllocator) New(t token.Token, nest int, comments ...Comments) *Meta {
+	if len(a.chunk) == 0 {
+		a.chunk = make([]Meta, metaChunkSize)
 	}
+	m := &a.chunk[0]
+	a.chunk = a.chunk[1:]
+	initMeta(m, t, nest, comments...)
+	return m
+}
+
+func initMeta(m *Meta, t token.Token, nest int, comments ...Comments) {
+	m.ID = idCounter.Add(1)
+	m.Token = t
+	m.Nest = nest
+	m.Leading = Comments{}
+	m.Trailing = Comments{}
+	m.Infix = Comments{}
 
 	switch len(comments) {
 	case 0:
@@ -129,8 +151,6 @@ func New(t token.Token, nest int, comments ...Comments) *Meta {
 		m.Trailing = comments[1]
 		m.Infix = comments[2]
 	}
-
-	return m
 }
 
 type Operator struct {
@@ -70,6 +70,10 @@ const (
 
 	// Root VCL
 	VCL
+
+	// falco extension declarations.
+	// Append new frame types here in order not to change the value of existing types
+	CONST_DECLARATION
 )
 
 // nolint:funlen,gocyclo
@@ -179,6 +183,8 @@ func (ft FrameType) String() string {
 		return "OPERATOR"
 	case VCL:
 		return "VCL"
+	case CONST_DECLARATION:
+		return "CONST_DECLARATION"
 	default:
 		return "UNKNOWN"
 	}
@@ -298,3 +298,16 @@ OUT:
 
 	return tbl, nil
 }
+
+func (c *Decoder) decodeConstDeclaration() (*ast.ConstDeclaration, error) {
+	var err error
+	cd := &ast.ConstDeclaration{}
+
+	if cd.Name, err = c.decodeIdent(c.nextFrame()); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if cd.Value, err = c.decodeExpression(c.nextFrame()); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return cd, nil
+}
@@ -227,3 +227,17 @@ func (c *Encoder) encodeTableProperty(prop *ast.TableProperty) *Frame {
 		buffer:    w.Bytes(),
 	}
 }
+
+func (c *Encoder) encodeConstDeclaration(cd *ast.ConstDeclaration) *Frame {
+	w := encodePool.Get().(*bytes.Buffer) // nolint:errcheck
+	defer encodePool.Put(w)
+	w.Reset()
+
+	w.Write(c.encodeIdent(cd.Name).Encode())
+	w.Write(c.encodeExpression(cd.Value).Encode())
+
+	return &Frame{
+		frameType: CONST_DECLARATION,
+		buffer:    w.Bytes(),
+	}
+}
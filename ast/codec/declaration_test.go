@@ -233,6 +233,16 @@ ratecounter example {
 	})
 }
 
+func TestConstDeclaration(t *testing.T) {
+	input := `
+const TTL_LONG = 86400s;`
+
+	assertStatement(t, input, &ast.ConstDeclaration{
+		Name:  &ast.Ident{Value: "TTL_LONG"},
+		Value: &ast.RTime{Value: "86400s"},
+	})
+}
+
 func TestSubroutineDeclaration(t *testing.T) {
 	tests := []struct {
 		name   string
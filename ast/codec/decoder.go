@@ -135,6 +135,8 @@ func (c *Decoder) decode(frame *Frame) (ast.Statement, error) {
 		return c.decodeSubroutineDeclaration()
 	case TABLE_DECLARATION:
 		return c.decodeTableDeclaration()
+	case CONST_DECLARATION:
+		return c.decodeConstDeclaration()
 
 	// Statements
 	case ADD_STATEMENT:
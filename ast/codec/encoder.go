@@ -69,6 +69,8 @@ func (c *Encoder) encode(stmt ast.Statement) (*Frame, error) {
 		frame = c.encodeSubroutineDeclaration(t)
 	case *ast.TableDeclaration:
 		frame = c.encodeTableDeclaration(t)
+	case *ast.ConstDeclaration:
+		frame = c.encodeConstDeclaration(t)
 
 	// Statements
 	case *ast.AddStatement:
@@ -0,0 +1,28 @@
+package ast
+
+import (
+	"bytes"
+)
+
+// ConstDeclaration is falco extension syntax to declare named constant like "const TTL_LONG = 86400s;".
+// Constants are resolved on compile time, so declarations must be expanded before uploading to Fastly
+type ConstDeclaration struct {
+	*Meta
+	Name  *Ident
+	Value Expression
+}
+
+func (c *ConstDeclaration) ID() uint64     { return c.Meta.ID }
+func (c *ConstDeclaration) Statement()     {}
+func (c *ConstDeclaration) GetMeta() *Meta { return c.Meta }
+func (c *ConstDeclaration) String() string {
+	var buf bytes.Buffer
+
+	buf.WriteString(c.LeadingComment(lineFeed))
+	buf.WriteString("const" + paddingLeft(c.Name.String()))
+	buf.WriteString(" =" + paddingLeft(c.Value.String()) + ";")
+	buf.WriteString(c.TrailingComment(inline))
+	buf.WriteString("\n")
+
+	return buf.String()
+}
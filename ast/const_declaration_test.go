@@ -0,0 +1,24 @@
+package ast
+
+import (
+	"testing"
+)
+
+func TestConstDeclaration(t *testing.T) {
+	c := &ConstDeclaration{
+		Meta: New(T, 0, comments("// leading comment"), comments("// trailing comment")),
+		Name: &Ident{
+			Meta:  New(T, 0, comments("/* before_name */"), comments("/* after_name */")),
+			Value: "TTL_LONG",
+		},
+		Value: &RTime{
+			Meta:  New(T, 0, comments("/* before_value */"), comments("/* after_value */")),
+			Value: "86400s",
+		},
+	}
+
+	expect := `// leading comment
+const /* before_name */ TTL_LONG /* after_name */ = /* before_value */ 86400s /* after_value */; // trailing comment
+`
+	assert(t, c.String(), expect)
+}
@@ -1,6 +1,7 @@
 package ast
 
 import (
+	"fmt"
 	"strings"
 )
 
@@ -10,3 +11,19 @@ func indent(lv int) string {
 	}
 	return strings.Repeat("  ", lv)
 }
+
+// EscapeString encodes string value to be placed in double-quoted string literal.
+// Double quote, percent sign and control characters could not be written as-is,
+// so encode them as percent escape which Fastly decodes on compilation.
+func EscapeString(v string) string {
+	var b strings.Builder
+	for _, r := range v {
+		switch {
+		case r == '"', r == '%', r < 0x20, r == 0x7F:
+			fmt.Fprintf(&b, "%%%02X", r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
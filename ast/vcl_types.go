@@ -77,7 +77,7 @@ func (s *String) String() string {
 		)
 	}
 	return strings.TrimSpace(
-		fmt.Sprintf(`%s"%s"%s`, s.LeadingComment(inline), s.Value, s.TrailingComment(inline)),
+		fmt.Sprintf(`%s"%s"%s`, s.LeadingComment(inline), EscapeString(s.Value), s.TrailingComment(inline)),
 	)
 }
 
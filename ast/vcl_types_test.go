@@ -0,0 +1,25 @@
+package ast
+
+import (
+	"testing"
+)
+
+func TestStringLiteral(t *testing.T) {
+	t.Run("double-quoted string escapes special characters", func(t *testing.T) {
+		str := &String{
+			Meta:  New(T, 0),
+			Value: "say \"100%\"\n",
+		}
+		assert(t, str.String(), `"say %22100%25%22%0A"`)
+	})
+
+	t.Run("long string is written as it is", func(t *testing.T) {
+		str := &String{
+			Meta:       New(T, 0),
+			Value:      "say \"100%\"\n",
+			LongString: true,
+			Delimiter:  "QUOTE",
+		}
+		assert(t, str.String(), "{QUOTE\"say \"100%\"\n\"QUOTE}")
+	})
+}
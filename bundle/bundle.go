@@ -0,0 +1,365 @@
+package bundle
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Bundled files are stored as zip archive which is appended to the falco executable.
+// The archive is followed by the trailer, 8 bytes archive size in little endian and the magic bytes,
+// so that the bundled executable is still runnable and the archive is found from the end of file.
+const (
+	magic        = "FALCOBDL"
+	trailerSize  = 8 + len(magic)
+	manifestName = "falco-bundle.json"
+)
+
+var ErrNotBundled = errors.New("Executable does not have bundled files")
+
+// Bundle is the manifest of bundled files.
+// All paths are slash separated and relative to the project root directory
+type Bundle struct {
+	Main         string   `json:"main"`
+	Config       string   `json:"config,omitempty"`
+	IncludePaths []string `json:"include_paths"`
+	Files        []string `json:"files"`
+
+	root string
+}
+
+func New(root string) (*Bundle, error) {
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &Bundle{
+		IncludePaths: []string{},
+		Files:        []string{},
+		root:         abs,
+	}, nil
+}
+
+// Convert path to slash separated relative path from the root directory.
+// Files outside the root could not be bundled because relative paths in configuration are not resolved after extraction
+func (b *Bundle) relative(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	rel, err := filepath.Rel(b.root, abs)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	if rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", errors.Errorf("%s is outside of the project root %s", path, b.root)
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+// SetMain sets the main VCL file and adds it to the bundle
+func (b *Bundle) SetMain(file string) error {
+	rel, err := b.relative(file)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	b.Main = rel
+	return b.AddFile(file)
+}
+
+// SetConfig sets the configuration file and adds it to the bundle
+func (b *Bundle) SetConfig(file string) error {
+	rel, err := b.relative(file)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	b.Config = rel
+	return b.AddFile(file)
+}
+
+// AddIncludePath adds the include path and all VCL files under the directory
+func (b *Bundle) AddIncludePath(dir string) error {
+	rel, err := b.relative(dir)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if !slices.Contains(b.IncludePaths, rel) {
+		b.IncludePaths = append(b.IncludePaths, rel)
+	}
+	return b.AddDir(dir, ".vcl")
+}
+
+// AddFile adds a single file to the bundle
+func (b *Bundle) AddFile(file string) error {
+	rel, err := b.relative(file)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if _, err := os.Stat(file); err != nil {
+		return errors.WithStack(err)
+	}
+	if !slices.Contains(b.Files, rel) {
+		b.Files = append(b.Files, rel)
+	}
+	return nil
+}
+
+// AddDir adds files under the directory recursively.
+// If ext is not empty, only files which have the extension are added
+func (b *Bundle) AddDir(dir, ext string) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		if d.IsDir() {
+			// Skip hidden directories like .git
+			if path != dir && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if ext != "" && filepath.Ext(path) != ext {
+			return nil
+		}
+		return b.AddFile(path)
+	})
+}
+
+// Add adds the file, or all files under the directory
+func (b *Bundle) Add(path string) error {
+	stat, err := os.Stat(path)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if stat.IsDir() {
+		return b.AddDir(path, "")
+	}
+	return b.AddFile(path)
+}
+
+// Write writes the executable with bundled files to w.
+// If the executable is already bundled, existing bundled files are replaced
+func (b *Bundle) Write(w io.Writer, exe string) error {
+	fp, err := os.Open(exe)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer fp.Close()
+
+	size, _, err := archiveSection(fp)
+	if err != nil && err != ErrNotBundled {
+		return errors.WithStack(err)
+	}
+	if _, err := io.CopyN(w, fp, size); err != nil {
+		return errors.WithStack(err)
+	}
+
+	archive := &bytes.Buffer{}
+	zw := zip.NewWriter(archive)
+	manifest, err := json.Marshal(b)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if err := writeZipFile(zw, manifestName, manifest); err != nil {
+		return errors.WithStack(err)
+	}
+	for _, file := range b.Files {
+		buf, err := os.ReadFile(filepath.Join(b.root, filepath.FromSlash(file)))
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		if err := writeZipFile(zw, file, buf); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return errors.WithStack(err)
+	}
+
+	trailer := binary.LittleEndian.AppendUint64(nil, uint64(archive.Len()))
+	trailer = append(trailer, magic...)
+	if _, err := w.Write(append(archive.Bytes(), trailer...)); err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+func writeZipFile(zw *zip.Writer, name string, buf []byte) error {
+	w, err := zw.Create(name)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	_, err = w.Write(buf)
+	return errors.WithStack(err)
+}
+
+// Find the bundled archive section, returns the executable size without bundle and the archive reader.
+// The executable size is the whole file size if it is not bundled
+func archiveSection(fp *os.File) (int64, *io.SectionReader, error) {
+	stat, err := fp.Stat()
+	if err != nil {
+		return 0, nil, errors.WithStack(err)
+	}
+	size := stat.Size()
+	if size < int64(trailerSize) {
+		return size, nil, ErrNotBundled
+	}
+
+	trailer := make([]byte, trailerSize)
+	if _, err := fp.ReadAt(trailer, size-int64(trailerSize)); err != nil {
+		return 0, nil, errors.WithStack(err)
+	}
+	if string(trailer[8:]) != magic {
+		return size, nil, ErrNotBundled
+	}
+	archiveSize := int64(binary.LittleEndian.Uint64(trailer[:8]))
+	offset := size - int64(trailerSize) - archiveSize
+	if archiveSize <= 0 || offset < 0 {
+		return 0, nil, errors.New("Bundled archive is broken")
+	}
+	return offset, io.NewSectionReader(fp, offset, archiveSize), nil
+}
+
+// Extract extracts bundled files of the executable into the directory which is named by the archive hash under dir.
+// The directory is reused if it has already been extracted, otherwise files are written into the temporary directory
+// which is created exclusively and renamed to the hash directory so that the partially extracted directory is never used.
+// Returns ErrNotBundled if the executable does not have bundled files
+func Extract(exe, dir string) (*Bundle, error) {
+	fp, err := os.Open(exe)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer fp.Close()
+
+	_, section, err := archiveSection(fp)
+	if err != nil {
+		return nil, err
+	}
+
+	h := sha256.New()
+	if _, err := io.Copy(h, section); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if err := checkPrivateDir(dir); err != nil {
+		return nil, err
+	}
+	root := filepath.Join(dir, hex.EncodeToString(h.Sum(nil))[:12])
+
+	zr, err := zip.NewReader(section, section.Size())
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	b := &Bundle{root: root}
+	for _, f := range zr.File {
+		if f.Name != manifestName {
+			continue
+		}
+		buf, err := readZipFile(f)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if err := json.Unmarshal(buf, b); err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+
+	// Already extracted
+	if _, err := os.Lstat(root); err == nil {
+		return b, checkPrivateDir(root)
+	}
+
+	tmp, err := os.MkdirTemp(dir, ".extract-")
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	for _, f := range zr.File {
+		if f.Name == manifestName {
+			continue
+		}
+		if !filepath.IsLocal(f.Name) {
+			return nil, errors.Errorf("Invalid bundled file path %s", f.Name)
+		}
+		if err := extractFile(f, filepath.Join(tmp, filepath.FromSlash(f.Name))); err != nil {
+			return nil, err
+		}
+	}
+	if err := os.Rename(tmp, root); err != nil {
+		// Another process may extract the same bundle concurrently
+		if _, serr := os.Lstat(root); serr != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+	return b, checkPrivateDir(root)
+}
+
+// Write the zip file to the path exclusively, never follows an existing file or symbolic link
+func extractFile(f *zip.File, path string) error {
+	buf, err := readZipFile(f)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return errors.WithStack(err)
+	}
+	fp, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if _, err := fp.Write(buf); err != nil {
+		fp.Close()
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(fp.Close())
+}
+
+// Extracted files are trusted as bundled ones, so the directory must be a real directory
+// which is owned by the current user and is not writable by others
+func checkPrivateDir(dir string) error {
+	stat, err := os.Lstat(dir)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if !stat.IsDir() {
+		return errors.Errorf("%s is not a directory", dir)
+	}
+	if !isPrivate(stat) {
+		return errors.Errorf("%s is not owned by the current user or is writable by other users", dir)
+	}
+	return nil
+}
+
+func readZipFile(f *zip.File) ([]byte, error) {
+	r, err := f.Open()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
+
+// Root returns the project root directory, extracted directory for the extracted bundle
+func (b *Bundle) Root() string {
+	return b.root
+}
+
+// Path returns the file path of the bundled relative path in the project root
+func (b *Bundle) Path(rel string) string {
+	return filepath.Join(b.root, filepath.FromSlash(rel))
+}
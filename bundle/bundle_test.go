@@ -0,0 +1,122 @@
+package bundle
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+}
+
+func TestBundle(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "default.vcl"), `include "module";`)
+	writeFile(t, filepath.Join(root, "vcl/module.vcl"), "sub vcl_recv {}")
+	writeFile(t, filepath.Join(root, "vcl/README.md"), "not bundled")
+	writeFile(t, filepath.Join(root, "data/table.json"), `{"foo":"bar"}`)
+	writeFile(t, filepath.Join(root, "exe"), "EXECUTABLE")
+	writeFile(t, filepath.Join(root, ".falco.yaml"), "include_paths: [./vcl]")
+
+	b, err := New(root)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if err := b.SetMain(filepath.Join(root, "default.vcl")); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if err := b.SetConfig(filepath.Join(root, ".falco.yaml")); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if err := b.AddIncludePath(filepath.Join(root, "vcl")); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if err := b.Add(filepath.Join(root, "data")); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if err := b.AddFile(filepath.Join(root, "..", "outside")); err == nil {
+		t.Errorf("Expected error for the file outside of root but got nil")
+	}
+
+	// Bundle twice in order to check existing bundle is replaced
+	bundled := filepath.Join(root, "bundled")
+	for _, exe := range []string{filepath.Join(root, "exe"), bundled} {
+		out := &bytes.Buffer{}
+		if err := b.Write(out, exe); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		writeFile(t, bundled, out.String())
+	}
+
+	buf, err := os.ReadFile(bundled)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if !bytes.HasPrefix(buf, []byte("EXECUTABLE")) || bytes.Count(buf, []byte(magic)) != 1 {
+		t.Errorf("Bundled executable must contain original executable and single bundle")
+	}
+
+	cache := t.TempDir()
+	extracted, err := Extract(bundled, cache)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if diff := cmp.Diff(b, extracted, cmpopts.IgnoreUnexported(Bundle{})); diff != "" {
+		t.Errorf("Manifest mismatch, diff=%s", diff)
+	}
+	expects := map[string]string{
+		"default.vcl":     `include "module";`,
+		"vcl/module.vcl":  "sub vcl_recv {}",
+		"data/table.json": `{"foo":"bar"}`,
+		".falco.yaml":     "include_paths: [./vcl]",
+	}
+	for file, expect := range expects {
+		actual, err := os.ReadFile(filepath.Join(extracted.Root(), file))
+		if err != nil {
+			t.Errorf("Unexpected error: %s", err)
+			continue
+		}
+		if diff := cmp.Diff(expect, string(actual)); diff != "" {
+			t.Errorf("Extracted file %s mismatch, diff=%s", file, diff)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(extracted.Root(), "vcl/README.md")); err == nil {
+		t.Errorf("Non VCL file in include path must not be bundled")
+	}
+
+	// Already extracted directory is reused without writing files again
+	writeFile(t, filepath.Join(extracted.Root(), "default.vcl"), "modified")
+	reused, err := Extract(bundled, cache)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if reused.Root() != extracted.Root() {
+		t.Errorf("Extracted root mismatch, expect=%s, got=%s", extracted.Root(), reused.Root())
+	}
+	if actual, _ := os.ReadFile(reused.Path("default.vcl")); string(actual) != "modified" {
+		t.Errorf("Extracted files must not be overwritten")
+	}
+
+	// Directory which is writable by other users must be refused
+	if err := os.Chmod(extracted.Root(), 0o777); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if _, err := Extract(bundled, cache); err == nil {
+		t.Errorf("Expected error for the directory which is writable by other users but got nil")
+	}
+
+	if _, err := Extract(filepath.Join(root, "exe"), t.TempDir()); err != ErrNotBundled {
+		t.Errorf("Expected ErrNotBundled but got %v", err)
+	}
+}
@@ -0,0 +1,13 @@
+//go:build !unix
+
+package bundle
+
+import (
+	"io/fs"
+)
+
+// Ownership and permission could not be checked by the file mode on non unix platforms,
+// the user cache directory is private for each user there
+func isPrivate(stat fs.FileInfo) bool {
+	return true
+}
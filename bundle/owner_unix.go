@@ -0,0 +1,17 @@
+//go:build unix
+
+package bundle
+
+import (
+	"io/fs"
+	"os"
+	"syscall"
+)
+
+func isPrivate(stat fs.FileInfo) bool {
+	if stat.Mode().Perm()&0o022 != 0 {
+		return false
+	}
+	sys, ok := stat.Sys().(*syscall.Stat_t)
+	return ok && int(sys.Uid) == os.Getuid()
+}
@@ -0,0 +1,134 @@
+// Package canary generates canary routing VCL which splits requests to backends by percentage,
+// and verifies that actual split ratios of simulated requests match configured percentages
+package canary
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/go-yaml/yaml"
+	"github.com/pkg/errors"
+)
+
+// Prefix of the generated subroutine which sets the backend of the canary
+const SubroutinePrefix = "canary_"
+
+var validName = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
+
+// Split is the percentage of requests which are routed to the backend
+type Split struct {
+	Backend    string `yaml:"backend"`
+	Percentage int    `yaml:"percentage"`
+}
+
+// Canary is the declaration of the percentage based backend split.
+// Condition is optional VCL expression which limits requests to split like req.url.path ~ "^/api/",
+// and URL is the request URL of simulated requests on verification
+type Canary struct {
+	Name      string   `yaml:"name"`
+	Condition string   `yaml:"condition"`
+	URL       string   `yaml:"url"`
+	Splits    []*Split `yaml:"splits"`
+}
+
+type file struct {
+	Canaries []*Canary `yaml:"canaries"`
+}
+
+// Load loads canary declaration files, canary names must be unique across files
+func Load(files ...string) ([]*Canary, error) {
+	var canaries []*Canary
+	names := make(map[string]struct{})
+
+	for _, f := range files {
+		buf, err := os.ReadFile(f)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		var loaded file
+		if err := yaml.Unmarshal(buf, &loaded); err != nil {
+			return nil, errors.Wrapf(err, "failed to parse canary file %s", f)
+		}
+		for _, c := range loaded.Canaries {
+			if err := c.validate(); err != nil {
+				return nil, errors.Wrapf(err, "invalid canary file %s", f)
+			}
+			if _, ok := names[c.Name]; ok {
+				return nil, errors.Errorf("canary %s is declared multiple times", c.Name)
+			}
+			names[c.Name] = struct{}{}
+			canaries = append(canaries, c)
+		}
+	}
+	return canaries, nil
+}
+
+// Percentages must be positive and sum up to 100 so that all requests are routed to any backend
+func (c *Canary) validate() error {
+	if !validName.MatchString(c.Name) {
+		return errors.Errorf("invalid canary name %q", c.Name)
+	}
+	if len(c.Splits) < 2 {
+		return errors.Errorf("canary %s must have at least two splits", c.Name)
+	}
+	var total int
+	backends := make(map[string]struct{})
+	for _, s := range c.Splits {
+		if !validName.MatchString(s.Backend) {
+			return errors.Errorf("invalid backend name %q of canary %s", s.Backend, c.Name)
+		}
+		if _, ok := backends[s.Backend]; ok {
+			return errors.Errorf("backend %s of canary %s is declared multiple times", s.Backend, c.Name)
+		}
+		backends[s.Backend] = struct{}{}
+		if s.Percentage <= 0 || s.Percentage >= 100 {
+			return errors.Errorf("percentage of backend %s of canary %s must be between 1 and 99", s.Backend, c.Name)
+		}
+		total += s.Percentage
+	}
+	if total != 100 {
+		return errors.Errorf("percentages of canary %s must sum up to 100, got %d", c.Name, total)
+	}
+	return nil
+}
+
+// Generate generates the subroutine for each canary which sets req.backend by the random roll.
+// Call the subroutine in vcl_recv after the default backend is set
+func Generate(canaries []*Canary) string {
+	var buf strings.Builder
+	buf.WriteString("# Generated by falco canary, DO NOT EDIT\n")
+
+	for _, c := range canaries {
+		indent := "  "
+		buf.WriteString(fmt.Sprintf("\nsub %s%s {\n", SubroutinePrefix, c.Name))
+		buf.WriteString("  declare local var.roll INTEGER;\n")
+		if c.Condition != "" {
+			buf.WriteString(fmt.Sprintf("  if (%s) {\n", c.Condition))
+			indent += "  "
+		}
+		buf.WriteString(fmt.Sprintf("%sset var.roll = randomint(0, 99);\n", indent))
+
+		var threshold int
+		last := len(c.Splits) - 1
+		for i, s := range c.Splits[:last] {
+			threshold += s.Percentage
+			if i == 0 {
+				buf.WriteString(fmt.Sprintf("%sif (var.roll < %d) {\n", indent, threshold))
+			} else {
+				buf.WriteString(fmt.Sprintf("%s} else if (var.roll < %d) {\n", indent, threshold))
+			}
+			buf.WriteString(fmt.Sprintf("%s  set req.backend = %s;\n", indent, s.Backend))
+		}
+		buf.WriteString(fmt.Sprintf("%s} else {\n", indent))
+		buf.WriteString(fmt.Sprintf("%s  set req.backend = %s;\n", indent, c.Splits[last].Backend))
+		buf.WriteString(fmt.Sprintf("%s}\n", indent))
+
+		if c.Condition != "" {
+			buf.WriteString("  }\n")
+		}
+		buf.WriteString("}\n")
+	}
+	return buf.String()
+}
@@ -0,0 +1,235 @@
+package canary
+
+import (
+	"math/rand"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/ysugimoto/falco/v2/lexer"
+	"github.com/ysugimoto/falco/v2/parser"
+)
+
+func writeCanary(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "canaries.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write canary: %s", err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		isError bool
+	}{
+		{
+			name: "valid canary",
+			content: `
+canaries:
+  - name: origin
+    splits:
+      - backend: F_origin
+        percentage: 95
+      - backend: F_origin_next
+        percentage: 5
+`,
+		},
+		{
+			name: "invalid name",
+			content: `
+canaries:
+  - name: ori-gin
+    splits: [{backend: F_a, percentage: 50}, {backend: F_b, percentage: 50}]
+`,
+			isError: true,
+		},
+		{
+			name: "single split",
+			content: `
+canaries:
+  - name: origin
+    splits: [{backend: F_a, percentage: 100}]
+`,
+			isError: true,
+		},
+		{
+			name: "percentages do not sum up to 100",
+			content: `
+canaries:
+  - name: origin
+    splits: [{backend: F_a, percentage: 90}, {backend: F_b, percentage: 5}]
+`,
+			isError: true,
+		},
+		{
+			name: "zero percentage",
+			content: `
+canaries:
+  - name: origin
+    splits: [{backend: F_a, percentage: 100}, {backend: F_b, percentage: 0}]
+`,
+			isError: true,
+		},
+		{
+			name: "duplicated backend",
+			content: `
+canaries:
+  - name: origin
+    splits: [{backend: F_a, percentage: 50}, {backend: F_a, percentage: 50}]
+`,
+			isError: true,
+		},
+		{
+			name: "duplicated canary",
+			content: `
+canaries:
+  - name: origin
+    splits: [{backend: F_a, percentage: 50}, {backend: F_b, percentage: 50}]
+  - name: origin
+    splits: [{backend: F_a, percentage: 50}, {backend: F_b, percentage: 50}]
+`,
+			isError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(writeCanary(t, tt.content))
+			if tt.isError && err == nil {
+				t.Errorf("Expected error but got nil")
+			} else if !tt.isError && err != nil {
+				t.Errorf("Unexpected error: %s", err)
+			}
+		})
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	canaries := []*Canary{
+		{
+			Name: "origin",
+			Splits: []*Split{
+				{Backend: "F_origin", Percentage: 95},
+				{Backend: "F_origin_next", Percentage: 5},
+			},
+		},
+		{
+			Name:      "api",
+			Condition: `req.url.path ~ "^/api/"`,
+			Splits: []*Split{
+				{Backend: "F_api_v1", Percentage: 80},
+				{Backend: "F_api_v2", Percentage: 15},
+				{Backend: "F_api_v3", Percentage: 5},
+			},
+		},
+	}
+	expect := `# Generated by falco canary, DO NOT EDIT
+
+sub canary_origin {
+  declare local var.roll INTEGER;
+  set var.roll = randomint(0, 99);
+  if (var.roll < 95) {
+    set req.backend = F_origin;
+  } else {
+    set req.backend = F_origin_next;
+  }
+}
+
+sub canary_api {
+  declare local var.roll INTEGER;
+  if (req.url.path ~ "^/api/") {
+    set var.roll = randomint(0, 99);
+    if (var.roll < 80) {
+      set req.backend = F_api_v1;
+    } else if (var.roll < 95) {
+      set req.backend = F_api_v2;
+    } else {
+      set req.backend = F_api_v3;
+    }
+  }
+}
+`
+	generated := Generate(canaries)
+	if diff := cmp.Diff(expect, generated); diff != "" {
+		t.Errorf("Generated VCL mismatch, diff=%s", diff)
+	}
+	if _, err := parser.New(lexer.NewFromString(generated)).ParseVCL(); err != nil {
+		t.Errorf("Generated VCL must be parsed: %s", err)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	c := &Canary{
+		Name: "origin",
+		Splits: []*Split{
+			{Backend: "F_origin", Percentage: 90},
+			{Backend: "F_origin_next", Percentage: 10},
+		},
+	}
+	// Emulate the generated subroutine with the seeded roll
+	sampler := func(threshold int) Sampler {
+		return func(seed int64, req *http.Request) (string, error) {
+			if rand.New(rand.NewSource(seed)).Intn(100) < threshold { // nolint:gosec
+				return "F_origin", nil
+			}
+			return "F_origin_next", nil
+		}
+	}
+
+	t.Run("within tolerance", func(t *testing.T) {
+		result, err := Verify(c, 20000, 1, 1, sampler(90))
+		if err != nil {
+			t.Errorf("Unexpected error: %s", err)
+			return
+		}
+		if !result.Passed {
+			t.Errorf("Expected passed but failed: %+v", result.Backends)
+		}
+		if !result.Confident() {
+			t.Errorf("Expected confident with %d samples, minimum is %d", result.Samples, result.MinimumSamples)
+		}
+		if len(result.Backends) != 2 {
+			t.Errorf("Expected 2 backend results, got %d", len(result.Backends))
+		}
+	})
+
+	t.Run("out of tolerance", func(t *testing.T) {
+		result, err := Verify(c, 20000, 1, 1, sampler(80))
+		if err != nil {
+			t.Errorf("Unexpected error: %s", err)
+			return
+		}
+		if result.Passed {
+			t.Errorf("Expected failed but passed")
+		}
+	})
+
+	t.Run("undeclared backend", func(t *testing.T) {
+		result, err := Verify(c, 100, 50, 1, func(seed int64, req *http.Request) (string, error) {
+			return "F_unknown", nil
+		})
+		if err != nil {
+			t.Errorf("Unexpected error: %s", err)
+			return
+		}
+		if result.Passed {
+			t.Errorf("Expected failed but passed")
+		}
+		if len(result.Backends) != 3 {
+			t.Errorf("Expected 3 backend results, got %d", len(result.Backends))
+		}
+	})
+
+	t.Run("invalid arguments", func(t *testing.T) {
+		if _, err := Verify(c, 0, 1, 1, sampler(90)); err == nil {
+			t.Errorf("Expected error on zero samples")
+		}
+		if _, err := Verify(c, 100, 0, 1, sampler(90)); err == nil {
+			t.Errorf("Expected error on zero tolerance")
+		}
+	})
+}
@@ -0,0 +1,119 @@
+package canary
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// Z-score of 99% confidence level
+const confidenceZ = 2.576
+
+// Sampler processes the simulated request with the random seed and returns the determined backend name
+type Sampler func(seed int64, req *http.Request) (string, error)
+
+// BackendResult is the observed split of the backend, ratios and tolerance are percentage points.
+// Expected is zero for the backend which is not declared in the canary
+type BackendResult struct {
+	Backend  string  `json:"backend"`
+	Count    int     `json:"count"`
+	Expected float64 `json:"expected"`
+	Actual   float64 `json:"actual"`
+	Margin   float64 `json:"margin"`
+	Passed   bool    `json:"passed"`
+}
+
+// Result is the verification result of the canary.
+// MinimumSamples is the sample size which is required to confirm the tolerance with 99% confidence,
+// the result is not statistically confident if Samples is less than it
+type Result struct {
+	Canary         string           `json:"canary"`
+	Samples        int              `json:"samples"`
+	Tolerance      float64          `json:"tolerance"`
+	MinimumSamples int              `json:"minimum_samples"`
+	Backends       []*BackendResult `json:"backends"`
+	Passed         bool             `json:"passed"`
+}
+
+// Confident reports whether the sample size is enough to confirm the tolerance
+func (r *Result) Confident() bool {
+	return r.Samples >= r.MinimumSamples
+}
+
+// Verify samples simulated requests and confirms that actual split ratios fall within the tolerance of configured percentages.
+// Each request has the different random seed and client IP so that both random and client based splits are sampled
+func Verify(c *Canary, samples int, tolerance float64, seed int64, sample Sampler) (*Result, error) {
+	if samples <= 0 {
+		return nil, errors.Errorf("sample size must be positive, got %d", samples)
+	}
+	if tolerance <= 0 {
+		return nil, errors.Errorf("tolerance must be positive, got %f", tolerance)
+	}
+	url := c.URL
+	if url == "" {
+		url = "http://localhost/"
+	}
+
+	r := rand.New(rand.NewSource(seed)) // nolint:gosec
+	counts := make(map[string]int)
+	for i := 0; i < samples; i++ {
+		req, err := http.NewRequest(http.MethodGet, url, http.NoBody)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		ip := net.IPv4(byte(r.Intn(223)+1), byte(r.Intn(256)), byte(r.Intn(256)), byte(r.Intn(254)+1))
+		req.RemoteAddr = fmt.Sprintf("%s:%d", ip.String(), r.Intn(50000)+1024)
+
+		backend, err := sample(r.Int63(), req)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to process simulated request #%d", i+1)
+		}
+		counts[backend]++
+	}
+
+	result := &Result{
+		Canary:    c.Name,
+		Samples:   samples,
+		Tolerance: tolerance,
+		Passed:    true,
+	}
+	expected := make(map[string]float64)
+	for _, s := range c.Splits {
+		p := float64(s.Percentage) / 100
+		expected[s.Backend] = p
+		// Sample size to confirm the tolerance in the worst case of the split
+		n := int(math.Ceil(confidenceZ * confidenceZ * p * (1 - p) / math.Pow(tolerance/100, 2)))
+		result.MinimumSamples = max(result.MinimumSamples, n)
+		if _, ok := counts[s.Backend]; !ok {
+			counts[s.Backend] = 0
+		}
+	}
+
+	for backend, count := range counts {
+		p := expected[backend]
+		actual := float64(count) / float64(samples)
+		b := &BackendResult{
+			Backend:  backend,
+			Count:    count,
+			Expected: p * 100,
+			Actual:   actual * 100,
+			Margin:   confidenceZ * math.Sqrt(actual*(1-actual)/float64(samples)) * 100,
+		}
+		b.Passed = math.Abs(b.Actual-b.Expected) <= tolerance
+		if _, ok := expected[backend]; !ok {
+			// Requests must not be routed to undeclared backends
+			b.Passed = false
+		}
+		result.Passed = result.Passed && b.Passed
+		result.Backends = append(result.Backends, b)
+	}
+	sort.Slice(result.Backends, func(i, j int) bool {
+		return result.Backends[i].Backend < result.Backends[j].Backend
+	})
+	return result, nil
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/ysugimoto/falco/v2/bundle"
+	"github.com/ysugimoto/falco/v2/config"
+)
+
+// Bundle main VCL, include paths, configuration file and resource files into the copy of falco executable.
+// The project root is the directory of configuration file so that relative paths in configuration are kept,
+// or current working directory if configuration file is not found
+func runBundle(c *config.Config, main string) error {
+	if main == "" {
+		return fmt.Errorf("main VCL file is not specified")
+	}
+
+	root, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	if c.ConfigFile != "" {
+		root = filepath.Dir(c.ConfigFile)
+	}
+	b, err := bundle.New(root)
+	if err != nil {
+		return err
+	}
+
+	if err := b.SetMain(main); err != nil {
+		return err
+	}
+	// Main VCL directory is always used as include path on resolving
+	if err := b.AddDir(filepath.Dir(main), ".vcl"); err != nil {
+		return err
+	}
+	for _, p := range c.IncludePaths {
+		if err := b.AddIncludePath(p); err != nil {
+			return err
+		}
+	}
+	if c.ConfigFile != "" {
+		if err := b.SetConfig(c.ConfigFile); err != nil {
+			return err
+		}
+	}
+
+	var files []string
+	if rf := c.Simulator.ResourceFiles; rf != nil {
+		for _, m := range []map[string]string{rf.Tables, rf.Acls, rf.EdgeDictionaries} {
+			for _, file := range m {
+				files = append(files, file)
+			}
+		}
+	}
+	if c.Simulator.KeyFile != "" && c.Simulator.CertFile != "" {
+		files = append(files, c.Simulator.KeyFile, c.Simulator.CertFile)
+	}
+	files = append(files, c.Bundle.Embed...)
+	for _, file := range files {
+		if err := b.Add(file); err != nil {
+			return err
+		}
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	fp, err := os.OpenFile(c.Bundle.Output, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o755)
+	if err != nil {
+		return err
+	}
+	defer fp.Close()
+
+	if err := b.Write(fp, exe); err != nil {
+		return err
+	}
+	writeln(green, "%d files are bundled into %s", len(b.Files), c.Bundle.Output)
+	return nil
+}
+
+// Extract bundled files into the user cache directory if the executable has them.
+// Working directory is not changed so that user arguments are resolved as usual
+func extractBundle() (*bundle.Bundle, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return nil, err
+	}
+	b, err := bundle.Extract(exe, filepath.Join(dir, "falco", "bundle"))
+	if err != nil {
+		if err == bundle.ErrNotBundled {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return b, nil
+}
+
+// Create configuration for the bundled executable.
+// The configuration file found from the working directory takes precedence,
+// otherwise the bundled configuration file is used and its relative paths are resolved from the extracted root
+func bundleConfig(b *bundle.Bundle, args []string, c *config.Config) (*config.Config, error) {
+	args = bundleArgs(b, args, c.Commands)
+	if c.ConfigFile != "" || b.Config == "" {
+		return config.New(args)
+	}
+	return config.NewWithFile(args, b.Path(b.Config))
+}
+
+// Complement subcommand and main VCL with the bundled ones when they are not specified.
+// Bundled executable runs simulator by default
+func bundleArgs(b *bundle.Bundle, args []string, commands config.Commands) []string {
+	switch commands.At(0) {
+	case "":
+		args = append([]string{subcommandSimulate}, args...)
+	case subcommandSimulate, subcommandLint, subcommandStats, subcommandTest:
+		if commands.At(1) != "" {
+			return args
+		}
+	default:
+		return args
+	}
+
+	for _, p := range b.IncludePaths {
+		args = append(args, "-I", b.Path(p))
+	}
+	return append(args, b.Path(b.Main))
+}
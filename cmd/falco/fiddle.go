@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"encoding/json"
+
+	"github.com/ysugimoto/falco/v2/config"
+	"github.com/ysugimoto/falco/v2/fiddle"
+	"github.com/ysugimoto/falco/v2/resolver"
+)
+
+// Fiddle import writes the main VCL with this name into the output directory
+const fiddleMainVCL = "main.vcl"
+
+func runFiddle(ctx context.Context, c *config.Config, action, target string) error {
+	switch action {
+	case "export":
+		return runFiddleExport(ctx, c, target)
+	case "import":
+		return runFiddleImport(ctx, c, target)
+	default:
+		return fmt.Errorf("unrecognized fiddle subcommand: %s", action)
+	}
+}
+
+// Export main VCL and request definitions as fiddle JSON to stdout.
+// Request definitions file is optional, fiddle default request is used if it does not exist
+func runFiddleExport(ctx context.Context, c *config.Config, main string) error {
+	if main == "" {
+		return fmt.Errorf("main VCL file is not specified")
+	}
+	resolvers, err := resolver.NewFileResolvers(main, c.IncludePaths)
+	if err != nil {
+		return err
+	}
+
+	var requests []*fiddle.Request
+	if _, err := os.Stat(c.Fiddle.Requests); err == nil {
+		if requests, err = fiddle.ReadRequests(c.Fiddle.Requests); err != nil {
+			return fmt.Errorf("failed to read request definitions: %w", err)
+		}
+	}
+
+	f, err := NewRunner(ctx, c, nil).FiddleExport(resolvers[0], requests)
+	if err != nil {
+		if err == ErrParser {
+			return ErrExit
+		}
+		return err
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(f)
+}
+
+// Import fiddle from URL, id or exported JSON file into the output directory.
+// Existing files are not overwritten in order to protect local work
+func runFiddleImport(ctx context.Context, c *config.Config, target string) error {
+	if target == "" {
+		return fmt.Errorf("fiddle URL, id or JSON file is not specified")
+	}
+
+	var f *fiddle.Fiddle
+	if fp, err := os.Open(target); err == nil {
+		f, err = fiddle.Decode(fp)
+		fp.Close()
+		if err != nil {
+			return fmt.Errorf("failed to decode fiddle file: %w", err)
+		}
+	} else {
+		if f, err = fiddle.NewFetcher(http.DefaultClient).Fetch(ctx, target); err != nil {
+			return fmt.Errorf("failed to fetch fiddle: %w", err)
+		}
+	}
+
+	vcl, err := fiddle.Import(f)
+	if err != nil {
+		return err
+	}
+
+	outDir := c.Fiddle.OutDir
+	if outDir == "" {
+		outDir = "."
+	}
+	if err := os.MkdirAll(outDir, 0o755); err != nil {
+		return err
+	}
+	mainPath := filepath.Join(outDir, fiddleMainVCL)
+	requestsPath := filepath.Join(outDir, filepath.Base(c.Fiddle.Requests))
+	for _, path := range []string{mainPath, requestsPath} {
+		if _, err := os.Stat(path); err == nil {
+			return fmt.Errorf("%s already exists, specify another directory with --out-dir option", path)
+		}
+	}
+
+	if err := os.WriteFile(mainPath, []byte(vcl), 0o644); err != nil {
+		return err
+	}
+	if err := fiddle.WriteRequests(requestsPath, f.Requests); err != nil {
+		return err
+	}
+	writeln(green, "Imported fiddle into %s and %s", mainPath, requestsPath)
+	return nil
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"sync/atomic"
+)
+
+const (
+	healthzPath = "/healthz"
+	readyzPath  = "/readyz"
+)
+
+// healthHandler serves liveness and readiness endpoints for container orchestrators,
+// other requests are passed through to the simulator.
+// Readiness turns to unready when the server starts shutting down so that the traffic is drained before stopping
+type healthHandler struct {
+	next  http.Handler
+	ready atomic.Bool
+}
+
+func newHealthHandler(next http.Handler) *healthHandler {
+	return &healthHandler{next: next}
+}
+
+func (h *healthHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case healthzPath:
+		w.WriteHeader(http.StatusOK)
+		io.WriteString(w, "ok") // nolint:errcheck
+	case readyzPath:
+		if !h.ready.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			io.WriteString(w, "not ready") // nolint:errcheck
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		io.WriteString(w, "ok") // nolint:errcheck
+	default:
+		h.next.ServeHTTP(w, r)
+	}
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHealthHandler(t *testing.T) {
+	h := newHealthHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+
+	tests := []struct {
+		name   string
+		path   string
+		ready  bool
+		expect int
+	}{
+		{name: "healthz is always ok", path: healthzPath, expect: http.StatusOK},
+		{name: "readyz before ready", path: readyzPath, expect: http.StatusServiceUnavailable},
+		{name: "readyz after ready", path: readyzPath, ready: true, expect: http.StatusOK},
+		{name: "other path is passed to simulator", path: "/healthz/foo", ready: true, expect: http.StatusTeapot},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h.ready.Store(tt.ready)
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if w.Code != tt.expect {
+				t.Errorf("Status code mismatch, expect=%d, got=%d", tt.expect, w.Code)
+			}
+		})
+	}
+}
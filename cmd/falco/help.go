@@ -1,7 +1,10 @@
 package main
 
 import (
+	"fmt"
 	"strings"
+
+	"github.com/ysugimoto/falco/v2/migration"
 )
 
 func printHelp(cmd string) {
@@ -23,6 +26,40 @@ func printHelp(cmd string) {
 		printConsoleHelp()
 	case subcommandFormat:
 		printFormatHelp()
+	case subcommandTrace:
+		printTraceHelp()
+	case subcommandRepro:
+		printReproHelp()
+	case subcommandShadow:
+		printShadowHelp()
+	case subcommandReplay:
+		printReplayHelp()
+	case subcommandCoverage:
+		printCoverageHelp()
+	case subcommandExpand:
+		printExpandHelp()
+	case subcommandBundle:
+		printBundleHelp()
+	case subcommandInventory:
+		printInventoryHelp()
+	case subcommandMonitor:
+		printMonitorHelp()
+	case subcommandExperiment:
+		printExperimentHelp()
+	case subcommandCanary:
+		printCanaryHelp()
+	case subcommandLayer:
+		printLayerHelp()
+	case subcommandContract:
+		printContractHelp()
+	case subcommandSymbols:
+		printSymbolsHelp()
+	case subcommandFiddle:
+		printFiddleHelp()
+	case subcommandRewrite:
+		printRewriteHelp()
+	case subcommandConfig:
+		printConfigHelp()
 	default:
 		printGlobalHelp()
 	}
@@ -54,6 +91,23 @@ Subcommands:
     test      : Run local testing for provided VCLs
     console   : Run terminal console
     fmt       : Run formatter for provided VCLs
+    trace     : View recorded execution trace
+    repro     : Reproduce failed test from dumped context
+    shadow    : Compare two VCLs with the same simulator traffic
+    replay    : Replay edge logs and measure simulator fidelity
+    coverage  : Report coverage of changed lines, merge or compare coverage profiles
+    expand    : Expand named constants to upload VCLs to Fastly
+    bundle    : Build single executable simulator with VCLs and resource files
+    inventory : Report usages of Fastly builtin functions and variables
+    monitor   : Generate monitoring artifacts and suggested alerts from VCLs
+    experiment: Compile A/B experiment declarations into VCL
+    canary    : Generate canary routing VCL and verify split ratios
+    layer     : Merge tenant overlays into the base VCL and detect conflicts
+    contract  : Verify VCLs against backend contracts and generate response stubs
+    symbols   : Query declared symbols and their references with persisted index
+    fiddle    : Export VCLs to Fastly Fiddle format, or import fiddle into local files
+    rewrite   : Apply migration codemod for deprecated builtins to VCLs
+    config    : Validate configuration file or export its JSON Schema
 
 See subcommands help with:
     falco [subcommand] -h
@@ -66,6 +120,9 @@ All Flags:
     -v                 : Output lint warnings (verbose)
     -vv                : Output all lint results (very verbose)
     -json              : Output results as JSON (very verbose)
+    --log-format       : Operational log format, text or json
+    --log-level        : Operational log level, debug, info, warn or error
+    --log-output       : Operational log output, stderr, stdout or file path
 
 Simple linting example:
     falco -I . -vv /path/to/vcl/main.vcl
@@ -121,11 +178,28 @@ Flags:
     --proxy            : Enable actual proxy behavior
     -request           : Simulate request config
     -debug             : Enable debug mode
+    --trace            : Trace variable and header reads/writes
+    --explain          : Explain why the request results in HIT, MISS, PASS or HIT-FOR-PASS
+    --flow-diagram     : Output state machine flow diagram per request, mermaid or ascii
+    --record-trace     : Record execution traces to the directory
+    --policy           : Evaluate Rego policy file against execution traces
+    -w, --watch        : Reload VCLs on file change
+    --ui               : Serve web UI to inspect recent requests and compose requests at /_falco/
     --max_backends     : Override max backends limitation
     --max_acls         : Override max acls limitation
+    --strict-rtime     : Reject unit-less INTEGER or FLOAT assignment to RTIME variables
+    --strict-headers   : Reject reading headers which are never set in VCL or testing fixtures
+    --unimplemented    : Policy for unimplemented builtins, error, warn or stub
     --key              : Specify TLS server key file
     --cert             : Specify TLS cert file
     --refresh          : Refresh remote snippet cache
+    --health-check     : Serve /healthz and /readyz endpoints
+    --drain-delay      : Seconds to keep serving requests on SIGTERM before shutdown
+    --shutdown-timeout : Seconds to wait for in-flight requests on shutdown (default 5)
+    --mirror           : Mirror requests to the staging service URL and report response differences
+    --mirror-percentage: Percentage of requests to be mirrored (default 100)
+    --grpc-port        : Serve test results and lint findings over gRPC on the port
+    --grpc-host        : Address which gRPC server listens on (default 127.0.0.1)
 
 Local simulator example:
     falco simulate -I . /path/to/vcl/main.vcl
@@ -161,14 +235,40 @@ Flags:
     -h, --help         : Show this help
     -r, --remote       : Connect with Fastly API
     -f, --filter       : Override glob filter to find test files
-    -w, --watch        : Watch VCL file changes and run test
+    -w, --watch        : Watch VCL file changes and run affected tests
+    --ui               : Run test in interactive terminal dashboard
     -t, --tag          : Provide tag for testing
+    --run              : Run only test subroutines whose name matches the regular expression
+    --only-tags        : Run only test subroutines which have any of the tags
+    --skip-tags        : Skip test subroutines which have any of the tags
     -json              : Output results as JSON
     -request           : Override request config
     --timeout          : Set timeout to running test
+    -j, --jobs         : Number of test files which run concurrently, 1 as default
     --max_backends     : Override max backends limitation
     --max_acls         : Override max acls limitation
+    --strict-rtime     : Reject unit-less INTEGER or FLOAT assignment to RTIME variables
+    --strict-headers   : Reject reading headers which are never set in VCL or testing fixtures
+    --unimplemented    : Policy for unimplemented builtins, error, warn or stub
     --coverage         : Report code coverage
+    --coverage-out     : Write coverage profile to the file
+    --coverage-html    : Write annotated HTML coverage report to the directory
+    --coverage-format  : Format of --coverage-out file, json (default), json-stable, lcov or cobertura
+    --coverage-subroutines : Report coverage of each subroutine with uncovered lines
+    --coverage-threshold-statement  : Fail when statement coverage is below the percent
+    --coverage-threshold-branch     : Fail when branch coverage is below the percent
+    --coverage-threshold-subroutine : Fail when subroutine coverage is below the percent
+    --coverage-baseline     : Fail when changed lines are not covered, compared with the baseline coverage profile
+    --coverage-baseline-ref : Git reference to find changed lines for --coverage-baseline, HEAD as default
+    --record-trace     : Record execution traces of failed tests to the directory
+    --repro-dir        : Dump interpreter context of failed tests to the directory
+    --update-snapshots : Regenerate golden snapshots of testing.snapshot
+    --report           : Write test results to the file as JUnit XML
+    --format           : Output format of test results, tap is supported
+    --chaos            : Repeat @invariant tests with randomly perturbed conditions
+    --chaos-iterations : Number of chaos iterations for each invariant test, 10 as default
+    --chaos-seed       : Random seed to reproduce chaos perturbations
+    --chaos-clock-skew : Maximum clock skew of chaos perturbations, 5m as default
 
 Local testing example:
     falco test -I . -I ./tests /path/to/vcl/main.vcl
@@ -189,6 +289,9 @@ Flags:
     -json              : Output results as JSON (very verbose)
     --generated        : Lint for Fastly generated VCL
     --refresh          : Refresh remote snippet cache
+    -j, --jobs         : Number of workers to parse included modules (default: number of CPUs)
+    --no-cache         : Lint without cached results
+    --dialect          : VCL dialect, "fastly" or "varnish" (default: fastly)
 
 Simple linting with very verbose example:
     falco lint -I . -vv /path/to/vcl/main.vcl
@@ -224,3 +327,362 @@ Simple format example:
     falco fmt /path/to/vcl/main.vcl
 	`))
 }
+
+func printTraceHelp() {
+	writeln(white, strings.TrimSpace(`
+Usage:
+    falco trace view [flags] file
+
+Flags:
+    -h, --help  : Show this help
+
+Keys:
+    Right, n : Step forward
+    Left, p  : Step backward
+    Home, g  : Go to the first step
+    End, G   : Go to the last step
+    q, Esc   : Quit
+
+View recorded trace example:
+    falco trace view ./traces/default.test.vcl_my_test_RECV.trace
+	`))
+}
+
+func printReproHelp() {
+	writeln(white, strings.TrimSpace(`
+Usage:
+    falco repro [flags] file
+
+Flags:
+    -I, --include_path : Add include path
+    -h, --help         : Show this help
+    -o, --override     : Override tentative variable values
+
+Reproduce failed test example:
+    falco repro ./repro/default.test.vcl_my_test_RECV.repro.json
+	`))
+}
+
+func printShadowHelp() {
+	writeln(white, strings.TrimSpace(`
+Usage:
+    falco shadow [flags]
+
+Flags:
+    -I, --include_path : Add include path
+    -h, --help         : Show this help
+    -p, --port         : Specify server port (default 3124)
+    -a, --a            : Main VCL file which responds to the client
+    -b, --b            : Candidate VCL file which is compared with the main VCL
+
+Shadow traffic example:
+    falco shadow -I . --a ./main.vcl --b ./candidate.vcl
+	`))
+}
+
+func printReplayHelp() {
+	writeln(white, strings.TrimSpace(`
+Usage:
+    falco replay [flags] log_file [main vcl file]
+
+Flags:
+    -I, --include_path : Add include path
+    -h, --help         : Show this help
+    -json              : Output report as JSON
+    --format           : Log line format, "json" or "json-fields=field:key,..." (default json)
+    --samples          : Number of divergence samples to display (default 10)
+    --coverage-out     : Write coverage profile of replayed requests to the file
+    --compare          : Proposed VCL file to compare projected cache efficiency with
+
+Replay edge logs example:
+    falco replay -I . --format=json-fields=url:request_url,status:status,cache:fastly_info_state ./access.log ./main.vcl
+
+Compare cache efficiency example:
+    falco replay -I . --compare ./candidate.vcl ./access.log ./main.vcl
+	`))
+}
+
+func printCoverageHelp() {
+	writeln(white, strings.TrimSpace(`
+Usage:
+    falco coverage diff [flags] [git ref]
+    falco coverage merge [flags] [profile files]
+    falco coverage compare [flags] [profile A] [profile B]
+
+Flags:
+    -h, --help         : Show this help
+    -json              : Output report as JSON
+    --base             : Coverage profile file which is written by falco test --coverage-out
+    --threshold        : Fail when patch coverage percent is below the threshold
+    --output           : Write merged coverage to the file
+    --format           : Format of --output file, json (default), json-stable, lcov or cobertura
+    --html             : Write annotated HTML report of merged coverage to the directory
+
+Patch coverage example:
+    falco test -I vcl_tests ./vcl/default.vcl --coverage --coverage-out profile.cov
+    falco coverage diff --base profile.cov --threshold 80 origin/main
+
+Merge coverage example:
+    falco coverage merge --output profile.cov shard-1.cov shard-2.cov
+
+Differential coverage example:
+    falco replay --coverage-out replay.cov ./access.log ./vcl/default.vcl
+    falco coverage compare tests.cov replay.cov
+	`))
+}
+
+func printExpandHelp() {
+	writeln(white, strings.TrimSpace(`
+Usage:
+    falco expand [flags] [target files]
+
+Flags:
+    -h, --help         : Show this help
+    --out-dir          : Write expanded VCLs to the directory instead of stdout
+
+Constants are shared between all target files, declare them in any file.
+
+Expand example:
+    falco expand --out-dir ./dist ./vcl/*.vcl
+	`))
+}
+
+func printInventoryHelp() {
+	writeln(white, strings.TrimSpace(`
+Usage:
+    falco inventory [flags] [target files]
+
+Flags:
+    -h, --help         : Show this help
+    -json              : Output usages as JSON
+
+Included modules are not resolved, specify all VCL files of the codebase.
+Builtins which are not implemented in falco interpreter are flagged.
+
+Inventory example:
+    falco inventory ./vcl/*.vcl
+	`))
+}
+
+func printMonitorHelp() {
+	writeln(white, strings.TrimSpace(`
+Usage:
+    falco monitor [flags] [target files]
+
+Flags:
+    -h, --help         : Show this help
+    -json              : Output artifacts as JSON
+
+Synthetic error codes, headers which are set in VCLs, log endpoints with emitted fields and backends are reported
+with suggested alert rules, so that dashboards and alerts match what the VCLs actually do.
+Included modules are not resolved, specify all VCL files of the codebase.
+
+Monitor example:
+    falco monitor -json ./vcl/*.vcl > monitoring.json
+	`))
+}
+
+func printExperimentHelp() {
+	writeln(white, strings.TrimSpace(`
+Usage:
+    falco experiment [flags]
+
+Flags:
+    -h, --help         : Show this help
+    --experiment       : Experiment declaration file, can be specified multiple times
+    --output           : Output file path of compiled VCL, stdout as default
+
+Each experiment is compiled to "experiment_{name}" functional subroutine which returns the assigned variant,
+and "experiments_assign" subroutine sets variants of all experiments to request headers.
+Use testing.force_bucket to assign the variant deterministically in tests.
+
+Experiment example:
+    falco experiment --experiment ./experiments.yaml --output ./vcl/experiments.vcl
+	`))
+}
+
+func printCanaryHelp() {
+	writeln(white, strings.TrimSpace(`
+Usage:
+    falco canary [subcommand] [flags]
+
+Subcommands:
+    generate           : Generate canary routing VCL from declarations
+    verify [main vcl]  : Sample simulated requests and confirm split ratios within tolerance
+
+Flags:
+    -h, --help         : Show this help
+    -I, --include_path : Add include path
+    -json              : Output verification results as JSON
+    --canary           : Canary declaration file, can be specified multiple times
+    --output           : Output file path of generated VCL, stdout as default
+    --samples          : Number of simulated requests for each canary (default 10000)
+    --tolerance        : Allowed difference of split ratio in percentage points (default 1)
+
+Each canary is generated to "canary_{name}" subroutine which sets req.backend by the random roll,
+call it in vcl_recv of the main VCL to verify split ratios.
+Verification fails when any ratio is out of tolerance, or requests are routed to undeclared backends.
+
+Canary example:
+    falco canary generate --canary ./canaries.yaml --output ./vcl/canaries.vcl
+    falco canary verify --canary ./canaries.yaml --samples 20000 ./vcl/main.vcl
+	`))
+}
+
+func printLayerHelp() {
+	writeln(white, strings.TrimSpace(`
+Usage:
+    falco layer [subcommand] [flags]
+
+Subcommands:
+    check              : Detect tenant overlays which override protected behavior of the base VCL
+    merge              : Merge tenant overlays into the base VCL
+
+Flags:
+    -h, --help         : Show this help
+    -json              : Output conflicts as JSON
+    --output           : Output file path of merged VCL, stdout as default
+
+The base VCL, extension points, protected variables and tenants are configured in "layer" section of the configuration.
+Bodies of extension point subroutines in overlays are appended to the base extension point in tenant priority order,
+and guarded by the tenant condition. Merging fails when any conflict is detected.
+
+Layer example:
+    falco layer check
+    falco layer merge --output ./dist/main.vcl
+	`))
+}
+
+func printContractHelp() {
+	writeln(white, strings.TrimSpace(`
+Usage:
+    falco contract [action] [flags] [target files]
+
+Actions:
+    verify : Verify that expectations are guaranteed and VCLs read only expected backend response headers
+    stub   : Generate subroutines which mock backend responses for testing.after_fetch
+
+Flags:
+    -h, --help         : Show this help
+    --contract         : Contract file, specify multiple times to merge guarantees and expectations
+    --output           : Write generated stubs to the file instead of stdout
+    -json              : Output violations as JSON
+
+Verify example:
+    falco contract verify --contract origin.yaml --contract edge.yaml ./vcl/*.vcl
+
+Stub example:
+    falco contract stub --contract origin.yaml --contract edge.yaml --output stubs.vcl
+	`))
+}
+
+func printSymbolsHelp() {
+	writeln(white, strings.TrimSpace(`
+Usage:
+    falco symbols [flags] [target files]
+
+Flags:
+    -h, --help         : Show this help
+    --index            : Symbol index file path (default: .falco-symbols.json)
+    --name             : Show only symbols which have the name
+    -json              : Output symbols as JSON
+
+Subroutines, backends, tables, ACLs, penaltyboxes and ratecounters are indexed with
+their declarations and references. The index is persisted and only changed files are parsed again.
+Indexed files are used when target files are not specified.
+
+Symbols example:
+    falco symbols ./vcl/*.vcl
+    falco symbols --name F_origin -json
+	`))
+}
+
+func printRewriteHelp() {
+	var migrations []string
+	for _, m := range migration.All() {
+		migrations = append(migrations, fmt.Sprintf("    %-18s : %s", m.Name, m.Description))
+	}
+
+	writeln(white, strings.TrimSpace(`
+Usage:
+    falco rewrite --migrate=<name> [flags] [target files]
+
+Flags:
+    -h, --help         : Show this help
+    --migrate          : Migration name to apply
+    -w, --write        : Overwrite target files instead of printing diff
+
+Migrations:
+`+strings.Join(migrations, "\n")+`
+
+Identifiers are rewritten in place so comments and formatting are kept, strings and comments are never rewritten.
+Without -w option, unified diff of the rewrite is printed as dry-run.
+
+Rewrite example:
+    falco rewrite --migrate=geoip ./vcl/*.vcl
+    falco rewrite --migrate=geoip -w ./vcl/*.vcl
+	`))
+}
+
+func printFiddleHelp() {
+	writeln(white, strings.TrimSpace(`
+Usage:
+    falco fiddle export [flags] [main vcl file]
+    falco fiddle import [flags] [fiddle URL, id or JSON file]
+
+Flags:
+    -I, --include_path : Add include path
+    -h, --help         : Show this help
+    --requests         : Request definitions JSON file (default: requests.json)
+    --title            : Fiddle title on export
+    --out-dir          : Directory to write imported files (default: current directory)
+
+Export flattens includes, expands constants and converts backends to fiddle origins,
+then writes fiddle JSON to stdout. Lifecycle subroutine bodies are exported without #FASTLY macro.
+Import writes main.vcl and request definitions, existing files are never overwritten.
+
+Fiddle example:
+    falco fiddle export -I . --title "Redirect rules" ./default.vcl > fiddle.json
+    falco fiddle import --out-dir ./playground https://fiddle.fastly.dev/fiddle/a1b2c3d4
+	`))
+}
+
+func printConfigHelp() {
+	writeln(white, strings.TrimSpace(`
+Usage:
+    falco config schema
+    falco config validate [configuration file]
+
+Flags:
+    -h, --help         : Show this help
+
+Schema writes JSON Schema of .falco.yml to stdout so that editors provide completion and validation.
+Validate reports unknown keys, mismatched types and conflicting options with their line numbers.
+Found configuration file is validated if the file is not specified.
+
+Config example:
+    falco config schema > falco.schema.json
+    falco config validate ./.falco.yml
+	`))
+}
+
+func printBundleHelp() {
+	writeln(white, strings.TrimSpace(`
+Usage:
+    falco bundle [flags] [main vcl file]
+
+Flags:
+    -I, --include_path : Add include path
+    -h, --help         : Show this help
+    --output           : Output executable path (default: falco-bundle)
+    --embed            : Additional file or directory to bundle, could be specified multiple times
+
+Main VCL, VCLs in include paths, configuration file, simulator resource files and TLS files are bundled
+into the copy of falco executable. Paths are kept as relative from the directory of configuration file.
+Bundled executable runs simulator with bundled files when subcommand is not specified.
+
+Bundle example:
+    falco bundle -I . --embed ./fixtures --output ./falco-simulator ./default.vcl
+    ./falco-simulator -p 8080
+	`))
+}
@@ -1,13 +1,18 @@
 package main
 
 import (
+	"bytes"
+	"context"
 	"fmt"
+	"io"
+	"log/slog"
 	"math"
 	"os"
 	"os/exec"
 	"os/signal"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strings"
 	"syscall"
 	"time"
@@ -20,18 +25,33 @@ import (
 	"github.com/mattn/go-colorable"
 	"github.com/mattn/go-isatty"
 	"github.com/pkg/errors"
+	"github.com/ysugimoto/falco/v2/canary"
 	"github.com/ysugimoto/falco/v2/config"
 	"github.com/ysugimoto/falco/v2/console"
+	"github.com/ysugimoto/falco/v2/contract"
+	"github.com/ysugimoto/falco/v2/coverage"
 	"github.com/ysugimoto/falco/v2/dap"
+	"github.com/ysugimoto/falco/v2/debugger"
+	"github.com/ysugimoto/falco/v2/experiment"
+	"github.com/ysugimoto/falco/v2/formatter"
+	"github.com/ysugimoto/falco/v2/interpreter/exception"
 	ife "github.com/ysugimoto/falco/v2/interpreter/function/errors"
 	"github.com/ysugimoto/falco/v2/lexer"
+	"github.com/ysugimoto/falco/v2/logger"
+	"github.com/ysugimoto/falco/v2/migration"
+	"github.com/ysugimoto/falco/v2/parser"
+	"github.com/ysugimoto/falco/v2/replay"
+	"github.com/ysugimoto/falco/v2/repro"
 	"github.com/ysugimoto/falco/v2/resolver"
 	"github.com/ysugimoto/falco/v2/snippet"
 	"github.com/ysugimoto/falco/v2/snippet/remote"
 	"github.com/ysugimoto/falco/v2/snippet/terraform"
+	"github.com/ysugimoto/falco/v2/symbol"
+	"github.com/ysugimoto/falco/v2/synthetic"
 	"github.com/ysugimoto/falco/v2/tester"
 	"github.com/ysugimoto/falco/v2/tester/shared"
 	"github.com/ysugimoto/falco/v2/token"
+	"github.com/ysugimoto/falco/v2/trace"
 )
 
 var version string = ""
@@ -55,14 +75,31 @@ var (
 )
 
 const (
-	subcommandLint      = "lint"
-	subcommandTerraform = "terraform"
-	subcommandSimulate  = "simulate"
-	subcommandDAP       = "dap"
-	subcommandStats     = "stats"
-	subcommandTest      = "test"
-	subcommandConsole   = "console"
-	subcommandFormat    = "fmt"
+	subcommandLint       = "lint"
+	subcommandTerraform  = "terraform"
+	subcommandSimulate   = "simulate"
+	subcommandDAP        = "dap"
+	subcommandStats      = "stats"
+	subcommandTest       = "test"
+	subcommandConsole    = "console"
+	subcommandFormat     = "fmt"
+	subcommandTrace      = "trace"
+	subcommandRepro      = "repro"
+	subcommandShadow     = "shadow"
+	subcommandReplay     = "replay"
+	subcommandCoverage   = "coverage"
+	subcommandExpand     = "expand"
+	subcommandBundle     = "bundle"
+	subcommandInventory  = "inventory"
+	subcommandContract   = "contract"
+	subcommandSymbols    = "symbols"
+	subcommandFiddle     = "fiddle"
+	subcommandRewrite    = "rewrite"
+	subcommandMonitor    = "monitor"
+	subcommandExperiment = "experiment"
+	subcommandCanary     = "canary"
+	subcommandLayer      = "layer"
+	subcommandConfig     = "config"
 )
 
 // Command return code constants
@@ -97,7 +134,17 @@ func main() {
 		color.NoColor = false
 	}
 
-	c, err := config.New(os.Args[1:])
+	// If the executable has bundled files, run with them
+	args := os.Args[1:]
+	b, err := extractBundle()
+	if err != nil {
+		writeln(red, "Failed to extract bundled files: %s", err)
+		os.Exit(Fail)
+	}
+	c, err := config.New(args)
+	if err == nil && b != nil {
+		c, err = bundleConfig(b, args, c)
+	}
 	if err != nil {
 		writeln(red, "Failed to initialize config: %s", err)
 		os.Exit(Fail)
@@ -110,6 +157,25 @@ func main() {
 		os.Exit(Success)
 	}
 
+	// Set up structured logger for falco's operational logs.
+	// Log file is written without buffering so records are not lost even if the process exits via os.Exit
+	l, closer, err := logger.New(c.Logging.Format, c.Logging.Level, c.Logging.Output)
+	if err != nil {
+		writeln(red, "Failed to initialize logger: %s", err)
+		os.Exit(Fail)
+	}
+	defer closer.Close() // nolint:errcheck
+	slog.SetDefault(l)
+
+	// Root context is cancelled on receiving termination signal so that long-running operations
+	// like remote fetching, parsing and simulator server stop gracefully.
+	// Once cancelled, signal handling is restored so that next signal terminates the process immediately
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
 	var (
 		// falco could lint multiple services so resolver should be a slice
 		resolvers   []resolver.Resolver
@@ -130,7 +196,15 @@ func main() {
 	case subcommandSimulate, subcommandLint, subcommandStats, subcommandTest:
 		// "lint", "simulate", "stats", and "test" command provides single file of service,
 		// then resolvers size is always 1
-		resolvers, err = resolver.NewFileResolvers(c.Commands.At(1), c.IncludePaths)
+		if c.Commands.At(1) == "" && c.Synthetic != nil && c.Synthetic.Type != "" {
+			// If main VCL is not provided and synthetic service is configured, generate VCL from configuration
+			var sr *synthetic.Resolver
+			if sr, err = synthetic.NewResolver(c.Synthetic); err == nil {
+				resolvers = []resolver.Resolver{sr}
+			}
+		} else {
+			resolvers, err = resolver.NewFileResolvers(c.Commands.At(1), c.IncludePaths)
+		}
 		action = c.Commands.At(0)
 	case subcommandConsole:
 		if err := console.Run(c.Console.Scope); err != nil {
@@ -142,6 +216,130 @@ func main() {
 			os.Exit(Fail)
 		}
 		os.Exit(Success)
+	case subcommandTrace:
+		if err := runTrace(c.Commands.At(1), c.Commands.At(2)); err != nil {
+			writeln(red, err.Error())
+			os.Exit(Fail)
+		}
+		os.Exit(Success)
+	case subcommandRepro:
+		if err := runRepro(ctx, c, c.Commands.At(1)); err != nil {
+			if err != ErrExit {
+				writeln(red, err.Error())
+			}
+			os.Exit(Fail)
+		}
+		os.Exit(Success)
+	case subcommandShadow:
+		if err := runShadow(ctx, c); err != nil {
+			writeln(red, err.Error())
+			os.Exit(Fail)
+		}
+		os.Exit(Success)
+	case subcommandReplay:
+		if err := runReplay(ctx, c, c.Commands.At(1), c.Commands.At(2)); err != nil {
+			writeln(red, err.Error())
+			os.Exit(Fail)
+		}
+		os.Exit(Success)
+	case subcommandCoverage:
+		if err := runCoverage(c, c.Commands.At(1), c.Commands[min(2, len(c.Commands)):]); err != nil {
+			if err != ErrExit {
+				writeln(red, err.Error())
+			}
+			os.Exit(Fail)
+		}
+		os.Exit(Success)
+	case subcommandExpand:
+		if err := runExpand(ctx, c, c.Commands[1:]); err != nil {
+			if err != ErrExit {
+				writeln(red, err.Error())
+			}
+			os.Exit(Fail)
+		}
+		os.Exit(Success)
+	case subcommandInventory:
+		if err := runInventory(ctx, c, c.Commands[1:]); err != nil {
+			if err != ErrExit {
+				writeln(red, err.Error())
+			}
+			os.Exit(Fail)
+		}
+		os.Exit(Success)
+	case subcommandExperiment:
+		if err := runExperiment(c); err != nil {
+			writeln(red, err.Error())
+			os.Exit(Fail)
+		}
+		os.Exit(Success)
+	case subcommandCanary:
+		if err := runCanary(ctx, c, c.Commands.At(1), c.Commands.At(2)); err != nil {
+			if err != ErrExit {
+				writeln(red, err.Error())
+			}
+			os.Exit(Fail)
+		}
+		os.Exit(Success)
+	case subcommandLayer:
+		if err := runLayer(ctx, c, c.Commands.At(1)); err != nil {
+			if err != ErrExit {
+				writeln(red, err.Error())
+			}
+			os.Exit(Fail)
+		}
+		os.Exit(Success)
+	case subcommandMonitor:
+		if err := runMonitor(ctx, c, c.Commands[1:]); err != nil {
+			if err != ErrExit {
+				writeln(red, err.Error())
+			}
+			os.Exit(Fail)
+		}
+		os.Exit(Success)
+	case subcommandContract:
+		if err := runContract(ctx, c, c.Commands.At(1), c.Commands[min(2, len(c.Commands)):]); err != nil {
+			if err != ErrExit {
+				writeln(red, err.Error())
+			}
+			os.Exit(Fail)
+		}
+		os.Exit(Success)
+	case subcommandSymbols:
+		if err := runSymbols(ctx, c, c.Commands[1:]); err != nil {
+			if err != ErrExit {
+				writeln(red, err.Error())
+			}
+			os.Exit(Fail)
+		}
+		os.Exit(Success)
+	case subcommandRewrite:
+		if err := runRewrite(ctx, c, c.Commands[1:]); err != nil {
+			if err != ErrExit {
+				writeln(red, err.Error())
+			}
+			os.Exit(Fail)
+		}
+		os.Exit(Success)
+	case subcommandFiddle:
+		if err := runFiddle(ctx, c, c.Commands.At(1), c.Commands.At(2)); err != nil {
+			if err != ErrExit {
+				writeln(red, err.Error())
+			}
+			os.Exit(Fail)
+		}
+		os.Exit(Success)
+	case subcommandConfig:
+		if err := runConfig(c, c.Commands.At(1), c.Commands.At(2)); err != nil {
+			writeln(red, err.Error())
+			os.Exit(Fail)
+		}
+		os.Exit(Success)
+	case subcommandBundle:
+		if err := runBundle(c, c.Commands.At(1)); err != nil {
+			writeln(red, err.Error())
+			os.Exit(Fail)
+		}
+		os.Exit(Success)
 	case subcommandFormat:
 		// "fmt" command accepts multiple target files
 		resolvers, err = resolver.NewGlobResolver(c.Commands[1:]...)
@@ -198,15 +396,18 @@ func main() {
 				}
 			}
 		}
-		runner := NewRunner(c, fetcher)
+		runner := NewRunner(ctx, c, fetcher)
 
 		var exitErr error
 		switch action {
 		case subcommandTest:
-			// test can accept watch
-			if c.Testing.Watch {
+			// test can accept watch and dashboard UI
+			switch {
+			case c.Testing.UI:
+				exitErr = runTestUI(runner, v)
+			case c.Testing.Watch:
 				exitErr = watchRunTest(runner, v)
-			} else {
+			default:
 				exitErr = runTest(runner, v)
 			}
 		case subcommandSimulate:
@@ -231,6 +432,7 @@ func main() {
 }
 
 func runLint(runner *Runner, rslv resolver.Resolver) error {
+	runner.enableLintCache()
 	result, err := runner.Run(rslv)
 	if err != nil {
 		if err != ErrParser {
@@ -283,6 +485,66 @@ func runSimulate(runner *Runner, rslv resolver.Resolver) error {
 	return nil
 }
 
+func runTrace(action, file string) error {
+	if action != "view" {
+		return fmt.Errorf("unrecognized trace subcommand: %s", action)
+	}
+	if file == "" {
+		return fmt.Errorf("trace file is not specified")
+	}
+	t, err := trace.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("failed to read trace file: %w", err)
+	}
+	return debugger.NewTraceViewer(t).Run()
+}
+
+func runRepro(ctx context.Context, c *config.Config, file string) error {
+	if file == "" {
+		return fmt.Errorf("reproduction file is not specified")
+	}
+	rp, err := repro.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("failed to read reproduction file: %w", err)
+	}
+	// Include paths on recording are used in addition to the specified ones
+	for _, p := range rp.IncludePaths {
+		if !slices.Contains(c.IncludePaths, p) {
+			c.IncludePaths = append(c.IncludePaths, p)
+		}
+	}
+	c.Testing.IncludePaths = c.IncludePaths
+
+	resolvers, err := resolver.NewFileResolvers(rp.Main, c.IncludePaths)
+	if err != nil {
+		return err
+	}
+	result, err := NewRunner(ctx, c, nil).Repro(resolvers[0], rp)
+	if err != nil {
+		return fmt.Errorf("failed to reproduce test: %w", err)
+	}
+
+	var prefix string
+	if result.Group != "" {
+		prefix = result.Group + " › "
+	}
+	writeln(white, "Reproduce %s (recorded error: %s)", rp.TestFile, rp.Error)
+	if len(result.Logs) > 0 {
+		writeln(yellow, "%s[Logs]", indent(1))
+		for i := range result.Logs {
+			writeln(white, "%s%s", indent(1), result.Logs[i])
+		}
+		writeln(white, "")
+	}
+	if result.Error != nil {
+		writeln(redBold, "%s● [VCL_%s] %s%s (%dms)", indent(1), result.Scope, prefix, result.Name, result.Time)
+		writeln(red, "%s%s", indent(2), result.Error.Error())
+		return ErrExit
+	}
+	writeln(green, "%s✓ [VCL_%s] %s%s (%dms)", indent(1), result.Scope, prefix, result.Name, result.Time)
+	return nil
+}
+
 func runStats(runner *Runner, rslv resolver.Resolver) error {
 	stats, err := runner.Stats(rslv)
 	if err != nil {
@@ -324,6 +586,34 @@ func runStats(runner *Runner, rslv resolver.Resolver) error {
 	printStats(strings.Repeat("-", 80))
 	printStats("| %-22s | %51d |", "Directors", stats.Directors)
 	printStats(strings.Repeat("-", 80))
+	if len(stats.Latencies) == 0 {
+		return nil
+	}
+	printStats("| %-76s |", "Worst-case latency per route ")
+	printStats(strings.Repeat("=", 80))
+	for _, l := range stats.Latencies {
+		name := l.Name
+		if l.IsDirector {
+			name += " (director)"
+		}
+		printStats("| %-22s | %51s |", name, fmt.Sprintf(
+			"%s (%d attempts of %s + %s + %s)",
+			l.WorstCase, l.Attempts, l.ConnectTimeout, l.FirstByteTimeout, l.BetweenBytesTimeout,
+		))
+		printStats(strings.Repeat("-", 80))
+	}
+	return nil
+}
+
+func runTestUI(runner *Runner, rslv resolver.Resolver) error {
+	passed, err := runner.TestUI(rslv)
+	if err != nil {
+		writeln(red, "Failed to run test dashboard: %s", err.Error())
+		return ErrExit
+	}
+	if !passed {
+		return ErrExit
+	}
 	return nil
 }
 
@@ -341,6 +631,31 @@ func watchRunTest(runner *Runner, rslv resolver.Resolver) error {
 		runner.config.Testing.Coverage = false
 	}
 
+	// Dependency map of VCL files and test files in order to run only affected test files
+	deps, err := runner.TestDependencies(rslv)
+	if err != nil {
+		writeln(red, "Failed to resolve test dependencies: %s", err.Error())
+		return ErrExit
+	}
+	// Watch directories because editors often replace the file on save
+	watched := make(map[string]struct{})
+	watch := func(dirs ...string) error {
+		for _, dir := range dirs {
+			if _, ok := watched[dir]; ok {
+				continue
+			}
+			if err := watcher.Add(dir); err != nil {
+				return errors.WithStack(err)
+			}
+			watched[dir] = struct{}{}
+		}
+		return nil
+	}
+	if err := watch(watchDirectories(deps, rslv.IncludePaths())...); err != nil {
+		writeln(red, err.Error())
+		return ErrExit
+	}
+
 	doneCh := make(chan struct{})
 	errCh := make(chan error)
 
@@ -359,6 +674,11 @@ func watchRunTest(runner *Runner, rslv resolver.Resolver) error {
 		// Run test at least once
 		runTest(runner, rslv) // nolint:errcheck
 
+		// Saving file emits multiple events so changed files are collected until events are settled
+		changed := make(map[string]struct{})
+		debounce := time.NewTimer(0)
+		<-debounce.C
+
 		writeln(cyan, "waiting for file changes...")
 		for {
 			select {
@@ -367,12 +687,45 @@ func watchRunTest(runner *Runner, rslv resolver.Resolver) error {
 					doneCh <- struct{}{}
 					return
 				}
-				switch event.Op {
-				case fsnotify.Create, fsnotify.Rename:
-					clearTerminal()
-					runTest(runner, rslv) // nolint:errcheck
-					writeln(cyan, "waiting for file changes...")
+				if filepath.Ext(event.Name) != ".vcl" || !event.Has(fsnotify.Write|fsnotify.Create|fsnotify.Rename) {
+					continue
+				}
+				changed[event.Name] = struct{}{}
+				debounce.Reset(100 * time.Millisecond)
+			case <-debounce.C:
+				files := make([]string, 0, len(changed))
+				for file := range changed {
+					files = append(files, file)
+				}
+				changed = make(map[string]struct{})
+
+				// Include statements may be changed so dependencies are rebuilt,
+				// and test files which depended on changed files before the change are also affected
+				affected := deps.Affected(files...)
+				if next, err := runner.TestDependencies(rslv); err != nil {
+					writeln(red, "Failed to resolve test dependencies: %s", err.Error())
+				} else {
+					deps = next
+					affected = append(affected, deps.Affected(files...)...)
+					if err := watch(watchDirectories(deps, nil)...); err != nil {
+						writeln(red, err.Error())
+					}
+				}
+
+				// Removed test files could not run
+				affected = slices.DeleteFunc(affected, func(file string) bool {
+					_, err := os.Stat(file)
+					return err != nil
+				})
+				slices.Sort(affected)
+
+				clearTerminal()
+				if affected = slices.Compact(affected); len(affected) == 0 {
+					writeln(white, "No test files are affected by changes of %s", strings.Join(files, ", "))
+				} else {
+					runTest(runner, rslv, affected...) // nolint:errcheck
 				}
+				writeln(cyan, "waiting for file changes...")
 			case err, ok := <-watcher.Errors:
 				if !ok {
 					doneCh <- struct{}{}
@@ -385,19 +738,10 @@ func watchRunTest(runner *Runner, rslv resolver.Resolver) error {
 		}
 	}()
 	go func() {
-		sig := make(chan os.Signal, 1)
-		signal.Notify(sig, syscall.SIGTERM, syscall.SIGINT)
-
-		<-sig
+		<-runner.ctx.Done()
 		doneCh <- struct{}{}
 	}()
 
-	for _, p := range rslv.IncludePaths() {
-		if err := watcher.Add(p); err != nil {
-			return ErrExit
-		}
-	}
-
 	select {
 	case err := <-errCh:
 		return err
@@ -406,6 +750,16 @@ func watchRunTest(runner *Runner, rslv resolver.Resolver) error {
 	}
 }
 
+// watchDirectories returns directories which have VCL files of the include graph
+func watchDirectories(deps *tester.Dependencies, includePaths []string) []string {
+	dirs := slices.Clone(includePaths)
+	for _, file := range deps.Files() {
+		dirs = append(dirs, filepath.Dir(file))
+	}
+	slices.Sort(dirs)
+	return slices.Compact(dirs)
+}
+
 // shorthand indent making
 func indent(level int) string {
 	return strings.Repeat(" ", level*2)
@@ -428,12 +782,75 @@ func printCodeLine(lx *lexer.Lexer, tok token.Token) {
 	}
 }
 
-func runTest(runner *Runner, rslv resolver.Resolver) error {
-	factory, err := runner.Test(rslv)
+// print error of the test case with the problem line
+// Soft assertion mode reports all failures of the test case
+func printCaseError(lx *lexer.Lexer, err error) {
+	e, ok := err.(*ife.AssertionErrors)
+	if !ok {
+		printTestError(lx, err)
+		return
+	}
+	writeln(red, "%s%d failures", indent(2), len(e.Errors))
+	for i := range e.Errors {
+		writeln(white, "")
+		printTestError(lx, e.Errors[i])
+	}
+}
+
+func printTestError(lx *lexer.Lexer, err error) {
+	writeln(red, "%s%s", indent(2), err.Error())
+	switch e := err.(type) {
+	case *ife.AssertionError:
+		write(white, "%sActual Value: ", indent(2))
+		writeln(red, "%s\n", e.Actual.String())
+		printCodeLine(lx, e.Token)
+	case *ife.TestingError:
+		writeln(white, "")
+		printCodeLine(lx, e.Token)
+	case *exception.Exception:
+		// Runtime exception may occur in the main VCL or included modules
+		if e.Excerpt != nil {
+			writeln(white, "")
+			for _, line := range strings.Split(e.Excerpt.String(), "\n") {
+				writeln(white, "%s%s", indent(1), line)
+			}
+		}
+	}
+}
+
+func runTest(runner *Runner, rslv resolver.Resolver, files ...string) error {
+	if format := runner.config.Testing.Format; format != "" && format != "tap" {
+		writeln(red, "Unsupported test output format %s, expects tap", format)
+		return ErrExit
+	}
+	factory, err := runner.Test(rslv, files...)
 	if err != nil {
 		return ErrExit
 	}
 
+	if factory.Coverage != nil && runner.config.Testing.CoverageOut != "" {
+		if err := writeCoverageProfile(
+			factory.Coverage,
+			runner.config.Testing.CoverageOut,
+			runner.config.Testing.CoverageFormat,
+		); err != nil {
+			writeln(red, "Failed to write coverage profile: %s", err)
+			return ErrExit
+		}
+	}
+	if factory.Coverage != nil && runner.config.Testing.CoverageHTML != "" {
+		if err := writeCoverageHTML(factory.Coverage, runner.config.Testing.CoverageHTML); err != nil {
+			writeln(red, "Failed to write HTML coverage report: %s", err)
+			return ErrExit
+		}
+	}
+	if runner.config.Testing.Report != "" {
+		if err := writeTestReport(factory, runner.config.Testing.Report); err != nil {
+			writeln(red, "Failed to write test report: %s", err)
+			return ErrExit
+		}
+	}
+
 	if runner.config.Json {
 		enc := json.NewEncoder(os.Stdout)
 		enc.SetIndent("", "  ")
@@ -447,10 +864,19 @@ func runTest(runner *Runner, rslv resolver.Resolver) error {
 			writeln(red, err.Error())
 			return ErrExit
 		}
-		if factory.Statistics.Fails > 0 {
+		return checkTestResult(factory, runner.config.Testing)
+	}
+	if runner.config.Testing.Format == "tap" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			writeln(red, err.Error())
+			return ErrExit
+		}
+		if err := tester.NewTAPEncoder(os.Stdout, cwd).Encode(factory); err != nil {
+			writeln(red, err.Error())
 			return ErrExit
 		}
-		return nil
+		return checkTestResult(factory, runner.config.Testing)
 	}
 
 	var passedCount, failedCount, skippedCount, totalCount int
@@ -487,16 +913,7 @@ func runTest(runner *Runner, rslv resolver.Resolver) error {
 					}
 					writeln(white, "")
 				}
-				writeln(red, "%s%s", indent(2), c.Error.Error())
-				switch e := c.Error.(type) {
-				case *ife.AssertionError:
-					write(white, "%sActual Value: ", indent(2))
-					writeln(red, "%s\n", e.Actual.String())
-					printCodeLine(r.Lexer, e.Token)
-				case *ife.TestingError:
-					writeln(white, "")
-					printCodeLine(r.Lexer, e.Token)
-				}
+				printCaseError(r.Lexer, c.Error)
 				writeln(white, "")
 				failedCount++
 			default:
@@ -510,6 +927,20 @@ func runTest(runner *Runner, rslv resolver.Resolver) error {
 				}
 				passedCount++
 			}
+
+			// Subtests are listed after the test case with the nested name and their own results
+			for _, st := range c.Subtests {
+				totalCount++
+				if st.Error != nil {
+					writeln(redBold, "%s● [VCL_%s] %s%s › %s (%dms)\n", indent(1), c.Scope, prefix, c.Name, st.Name, st.Time)
+					printCaseError(r.Lexer, st.Error)
+					writeln(white, "")
+					failedCount++
+					continue
+				}
+				writeln(green, "%s✓ [VCL_%s] %s%s › %s (%dms)", indent(1), c.Scope, prefix, c.Name, st.Name, st.Time)
+				passedCount++
+			}
 		}
 	}
 
@@ -539,11 +970,26 @@ func runTest(runner *Runner, rslv resolver.Resolver) error {
 			writeln(red, err.Error())
 			return ErrExit
 		}
+		if runner.config.Testing.CoverageSubs {
+			writeln(white, "")
+			writeln(white, "Subroutine Coverage Report")
+			printSubroutineCoverageTable(factory.Coverage)
+		}
 	}
+	return checkTestResult(factory, runner.config.Testing)
+}
 
+// Testing fails when some tests have failed or coverage does not satisfy thresholds and the baseline
+func checkTestResult(factory *tester.TestFactory, c *config.TestConfig) error {
 	if factory.Statistics.Fails > 0 {
 		return ErrExit
 	}
+	if factory.Coverage != nil && !checkCoverageThreshold(factory.Coverage, c) {
+		return ErrExit
+	}
+	if factory.Coverage != nil && !checkCoverageBaseline(factory.Coverage, c) {
+		return ErrExit
+	}
 	return nil
 }
 
@@ -556,3 +1002,761 @@ func runFormat(runner *Runner, rslv resolver.Resolver) error {
 	}
 	return nil
 }
+
+func runExpand(ctx context.Context, c *config.Config, patterns []string) error {
+	// "expand" command accepts multiple target files which share constants
+	resolvers, err := resolver.NewGlobResolver(patterns...)
+	if err != nil {
+		return err
+	}
+	if len(resolvers) == 0 {
+		return fmt.Errorf("no input files specified")
+	}
+	if err := NewRunner(ctx, c, nil).Expand(resolvers); err != nil {
+		if err == ErrParser {
+			return ErrExit
+		}
+		return err
+	}
+	return nil
+}
+
+func runInventory(ctx context.Context, c *config.Config, patterns []string) error {
+	// "inventory" command accepts multiple target files in order to report usages across the codebase
+	resolvers, err := resolver.NewGlobResolver(patterns...)
+	if err != nil {
+		return err
+	}
+	if len(resolvers) == 0 {
+		return fmt.Errorf("no input files specified")
+	}
+	usages, err := NewRunner(ctx, c, nil).Inventory(resolvers)
+	if err != nil {
+		if err == ErrParser {
+			return ErrExit
+		}
+		return err
+	}
+
+	if c.Json {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(usages)
+	}
+
+	var unimplemented int
+	for _, u := range usages {
+		if u.Implemented {
+			writeln(white, "%-8s %-50s %5d", u.Kind, u.Name, u.Count)
+		} else {
+			unimplemented++
+			writeln(red, "%-8s %-50s %5d  (not implemented in falco)", u.Kind, u.Name, u.Count)
+		}
+		for _, l := range u.Locations {
+			writeln(white, "    %s:%d:%d", l.File, l.Line, l.Position)
+		}
+	}
+	writeln(white, "")
+	if unimplemented > 0 {
+		writeln(yellow, "%d builtins are used, %d of them are not implemented in falco interpreter", len(usages), unimplemented)
+	} else {
+		writeln(green, "%d builtins are used, all of them are implemented in falco interpreter", len(usages))
+	}
+	return nil
+}
+
+func runMonitor(ctx context.Context, c *config.Config, patterns []string) error {
+	// "monitor" command accepts multiple target files in order to collect artifacts across the codebase
+	resolvers, err := resolver.NewGlobResolver(patterns...)
+	if err != nil {
+		return err
+	}
+	if len(resolvers) == 0 {
+		return fmt.Errorf("no input files specified")
+	}
+	artifacts, err := NewRunner(ctx, c, nil).Monitor(resolvers)
+	if err != nil {
+		if err == ErrParser {
+			return ErrExit
+		}
+		return err
+	}
+
+	if c.Json {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(artifacts)
+	}
+
+	writeln(cyan, "Synthetic error codes:")
+	for _, e := range artifacts.ErrorCodes {
+		writeln(white, "    %d %s", e.Code, strings.Join(e.Messages, ", "))
+	}
+	writeln(cyan, "Headers:")
+	for _, h := range artifacts.Headers {
+		writeln(white, "    %s", h.Name)
+	}
+	writeln(cyan, "Log endpoints:")
+	for _, l := range artifacts.LogEndpoints {
+		name := l.Name
+		if name == "" {
+			name = "(unnamed)"
+		}
+		writeln(white, "    %s", name)
+		for _, f := range l.Fields {
+			writeln(white, "        %-30s %s", f.Name, f.Source)
+		}
+	}
+	writeln(cyan, "Backends:")
+	for _, b := range artifacts.Backends {
+		writeln(white, "    %s", b.Name)
+	}
+	writeln(cyan, "Suggested alerts:")
+	for _, a := range artifacts.Alerts {
+		writeln(white, "    [%-8s] %-40s %s", a.Severity, a.Name, a.Description)
+		writeln(white, "               signal: %s", a.Signal)
+	}
+	return nil
+}
+
+func runExperiment(c *config.Config) error {
+	if len(c.Experiment.Files) == 0 {
+		return fmt.Errorf("experiment files are not specified, use --experiment option or experiment.files in configuration")
+	}
+	experiments, err := experiment.Load(c.Experiment.Files...)
+	if err != nil {
+		return err
+	}
+
+	// Bucketing keys are embedded as VCL expressions so ensure compiled VCL is valid
+	compiled := experiment.Compile(experiments)
+	if _, err := parser.New(lexer.NewFromString(compiled)).ParseVCL(); err != nil {
+		return errors.Wrap(err, "failed to compile experiments, check bucketing keys")
+	}
+
+	if c.Experiment.Output == "" {
+		fmt.Fprint(os.Stdout, compiled)
+		return nil
+	}
+	if err := os.WriteFile(c.Experiment.Output, []byte(compiled), 0o644); err != nil {
+		return err
+	}
+	writeln(cyan, "%d experiments are compiled to %s.", len(experiments), c.Experiment.Output)
+	return nil
+}
+
+func runCanary(ctx context.Context, c *config.Config, action, mainVCL string) error {
+	if len(c.Canary.Files) == 0 {
+		return fmt.Errorf("canary files are not specified, use --canary option or canary.files in configuration")
+	}
+	canaries, err := canary.Load(c.Canary.Files...)
+	if err != nil {
+		return err
+	}
+
+	switch action {
+	case "generate":
+		// Conditions are embedded as VCL expressions so ensure generated VCL is valid
+		generated := canary.Generate(canaries)
+		if _, err := parser.New(lexer.NewFromString(generated)).ParseVCL(); err != nil {
+			return errors.Wrap(err, "failed to generate canary routing, check conditions")
+		}
+		if c.Canary.Output == "" {
+			fmt.Fprint(os.Stdout, generated)
+			return nil
+		}
+		if err := os.WriteFile(c.Canary.Output, []byte(generated), 0o644); err != nil {
+			return err
+		}
+		writeln(cyan, "%d canaries are generated to %s.", len(canaries), c.Canary.Output)
+		return nil
+	case "verify":
+		resolvers, err := resolver.NewFileResolvers(mainVCL, c.IncludePaths)
+		if err != nil {
+			return err
+		}
+		runner := NewRunner(ctx, c, nil)
+		results := make([]*canary.Result, len(canaries))
+		for i, cn := range canaries {
+			if results[i], err = runner.VerifyCanary(cn, resolvers[0]); err != nil {
+				return fmt.Errorf("failed to verify canary %s: %w", cn.Name, err)
+			}
+		}
+
+		passed := true
+		for _, r := range results {
+			passed = passed && r.Passed
+		}
+		if c.Json {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(results); err != nil {
+				return err
+			}
+		} else {
+			for _, r := range results {
+				writeln(cyan, "Canary %s: %d samples, tolerance %.2f%%", r.Canary, r.Samples, r.Tolerance)
+				for _, b := range r.Backends {
+					resultColor := green
+					if !b.Passed {
+						resultColor = red
+					}
+					writeln(resultColor, "    %-30s expected %6.2f%%, actual %6.2f%% (±%.2f%%, %d requests)",
+						b.Backend, b.Expected, b.Actual, b.Margin, b.Count)
+				}
+				if !r.Confident() {
+					writeln(yellow, "    %d samples are required to confirm the tolerance with 99%% confidence", r.MinimumSamples)
+				}
+			}
+		}
+		if !passed {
+			if !c.Json {
+				writeln(red, "Split ratios are out of tolerance")
+			}
+			return ErrExit
+		}
+		if !c.Json {
+			writeln(green, "All split ratios are within tolerance")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unrecognized canary subcommand: %s", action)
+	}
+}
+
+func runLayer(ctx context.Context, c *config.Config, action string) error {
+	l, err := NewRunner(ctx, c, nil).Layer()
+	if err != nil {
+		if err == ErrParser {
+			return ErrExit
+		}
+		return err
+	}
+
+	switch action {
+	case "check":
+		conflicts := l.Check()
+		if c.Json {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(conflicts); err != nil {
+				return err
+			}
+		} else {
+			for _, cf := range conflicts {
+				writeln(red, cf.String())
+			}
+		}
+		if len(conflicts) > 0 {
+			if !c.Json {
+				writeln(white, "")
+				writeln(red, "%d conflicts are detected in tenant overlays", len(conflicts))
+			}
+			return ErrExit
+		}
+		if !c.Json {
+			writeln(green, "%d tenant overlays do not override protected behavior of the base VCL", len(l.Tenants()))
+		}
+		return nil
+	case "merge":
+		if conflicts := l.Check(); len(conflicts) > 0 {
+			return fmt.Errorf("%d conflicts are detected in tenant overlays, run \"falco layer check\" for details", len(conflicts))
+		}
+		vcl, err := l.Merge()
+		if err != nil {
+			return err
+		}
+		merged, err := io.ReadAll(formatter.New(c.Format).Format(vcl))
+		if err != nil {
+			return err
+		}
+		if c.Layer.Output == "" {
+			fmt.Fprint(os.Stdout, string(merged))
+			return nil
+		}
+		if err := os.WriteFile(c.Layer.Output, merged, 0o644); err != nil {
+			return err
+		}
+		writeln(cyan, "%d tenant overlays are merged to %s.", len(l.Tenants()), c.Layer.Output)
+		return nil
+	default:
+		return fmt.Errorf("unrecognized layer subcommand: %s", action)
+	}
+}
+
+func runContract(ctx context.Context, c *config.Config, action string, patterns []string) error {
+	if len(c.Contract.Files) == 0 {
+		return fmt.Errorf("contract files are not specified, use --contract option or contract.files in configuration")
+	}
+	ct, err := contract.Load(c.Contract.Files...)
+	if err != nil {
+		return err
+	}
+
+	switch action {
+	case "verify":
+		// "contract verify" accepts multiple target files in order to verify header reads across the codebase
+		resolvers, err := resolver.NewGlobResolver(patterns...)
+		if err != nil {
+			return err
+		}
+		if len(resolvers) == 0 {
+			return fmt.Errorf("no input files specified")
+		}
+		violations, err := NewRunner(ctx, c, nil).VerifyContract(ct, resolvers)
+		if err != nil {
+			if err == ErrParser {
+				return ErrExit
+			}
+			return err
+		}
+		if c.Json {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(violations); err != nil {
+				return err
+			}
+		} else {
+			for _, v := range violations {
+				writeln(red, v.String())
+			}
+		}
+		if len(violations) > 0 {
+			if !c.Json {
+				writeln(white, "")
+				writeln(red, "%d contract violations are found", len(violations))
+			}
+			return ErrExit
+		}
+		if !c.Json {
+			writeln(green, "VCLs rely only on guaranteed backend responses")
+		}
+		return nil
+	case "stub":
+		stubs := ct.Stubs()
+		if c.Contract.Output == "" {
+			fmt.Fprint(os.Stdout, stubs)
+			return nil
+		}
+		if err := os.WriteFile(c.Contract.Output, []byte(stubs), 0o644); err != nil {
+			return err
+		}
+		writeln(cyan, "Contract stubs are written to %s.", c.Contract.Output)
+		return nil
+	default:
+		return fmt.Errorf("unrecognized contract subcommand: %s", action)
+	}
+}
+
+func runSymbols(ctx context.Context, c *config.Config, patterns []string) error {
+	idx, err := symbol.Load(c.Symbols.Index)
+	if err != nil {
+		return err
+	}
+	// Prune removed files and verify indexed files when target files are not specified
+	idx.Prune()
+	if len(patterns) == 0 {
+		patterns = idx.FilePaths()
+	}
+	resolvers, err := resolver.NewGlobResolver(patterns...)
+	if err != nil {
+		return err
+	}
+	if len(resolvers) == 0 {
+		return fmt.Errorf("no input files specified")
+	}
+	if err := NewRunner(ctx, c, nil).Symbols(idx, resolvers); err != nil {
+		if err == ErrParser {
+			return ErrExit
+		}
+		return err
+	}
+	if err := idx.Save(c.Symbols.Index); err != nil {
+		return err
+	}
+
+	symbols := idx.Symbols()
+	if c.Symbols.Name != "" {
+		symbols = idx.Lookup(c.Symbols.Name)
+	}
+
+	if c.Json {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(symbols)
+	}
+
+	for _, s := range symbols {
+		writeln(white, "%-12s %-50s %5d references", s.Kind, s.Name, len(s.References))
+		for _, l := range s.Declarations {
+			writeln(green, "    declared   %s:%d:%d", l.File, l.Line, l.Position)
+		}
+		for _, l := range s.References {
+			writeln(white, "    referenced %s:%d:%d", l.File, l.Line, l.Position)
+		}
+	}
+	return nil
+}
+
+// Export JSON Schema of the configuration file, or validate the configuration file strictly.
+// Found configuration file is validated if the file is not specified
+func runConfig(c *config.Config, action, file string) error {
+	switch action {
+	case "schema":
+		schema, err := config.JSONSchema()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(os.Stdout, string(schema))
+		return nil
+	case "validate":
+		if file == "" {
+			file = c.ConfigFile
+		}
+		if file == "" {
+			return fmt.Errorf("configuration file is not found")
+		}
+		if err := config.ValidateFile(file); err != nil {
+			return err
+		}
+		writeln(green, "%s is valid :sparkles:", file)
+		return nil
+	default:
+		return fmt.Errorf("unrecognized config subcommand: %s", action)
+	}
+}
+
+func runRewrite(ctx context.Context, c *config.Config, patterns []string) error {
+	if c.Rewrite.Migrate == "" {
+		return fmt.Errorf("migration is not specified, provide --migrate option")
+	}
+	m, err := migration.Get(c.Rewrite.Migrate)
+	if err != nil {
+		return err
+	}
+	// "rewrite" command accepts multiple target files for bulk migrations
+	resolvers, err := resolver.NewGlobResolver(patterns...)
+	if err != nil {
+		return err
+	}
+	if len(resolvers) == 0 {
+		return fmt.Errorf("no input files specified")
+	}
+	return NewRunner(ctx, c, nil).Rewrite(resolvers, m)
+}
+
+func runShadow(ctx context.Context, c *config.Config) error {
+	if c.Shadow.A == "" || c.Shadow.B == "" {
+		return fmt.Errorf("both --a and --b VCL files must be specified")
+	}
+	a, err := resolver.NewFileResolvers(c.Shadow.A, c.IncludePaths)
+	if err != nil {
+		return err
+	}
+	b, err := resolver.NewFileResolvers(c.Shadow.B, c.IncludePaths)
+	if err != nil {
+		return err
+	}
+	return NewRunner(ctx, c, nil).Shadow(a[0], b[0])
+}
+
+func runReplay(ctx context.Context, c *config.Config, logFile, mainVCL string) error {
+	if logFile == "" {
+		return fmt.Errorf("log file is not specified")
+	}
+
+	var rslv resolver.Resolver
+	if mainVCL == "" && c.Synthetic != nil && c.Synthetic.Type != "" {
+		sr, err := synthetic.NewResolver(c.Synthetic)
+		if err != nil {
+			return err
+		}
+		rslv = sr
+	} else {
+		resolvers, err := resolver.NewFileResolvers(mainVCL, c.IncludePaths)
+		if err != nil {
+			return err
+		}
+		rslv = resolvers[0]
+	}
+
+	report, err := NewRunner(ctx, c, nil).Replay(rslv, logFile)
+	if err != nil {
+		return fmt.Errorf("failed to replay logs: %w", err)
+	}
+
+	if c.Json {
+		return json.NewEncoder(os.Stdout).Encode(report)
+	}
+	writeln(white, "Replayed %d requests, %d matched, %d lines skipped", report.Total, report.Matched, report.Skipped)
+	for _, field := range report.DivergedFields() {
+		writeln(yellow, "%s%s diverged: %d", indent(1), field, report.Fields[field])
+	}
+	if len(report.Samples) > 0 {
+		writeln(white, "")
+		writeln(yellow, "Divergence samples:")
+		for _, d := range report.Samples {
+			writeln(white, "%s%s", indent(1), d.String())
+		}
+	}
+	if len(report.Budgets) > 0 {
+		writeln(white, "")
+		writeln(red, "Latency budget violations:")
+		for _, b := range report.Budgets {
+			writeln(white, "%s%s", indent(1), b.String())
+		}
+	}
+	writeln(white, "")
+	printCacheStats(report.Cache, report.ProposedCache)
+
+	writeln(white, "")
+	fidelityColor := green
+	if report.Matched < report.Total {
+		fidelityColor = red
+	}
+	writeln(fidelityColor, "Simulator fidelity: %.2f%%", report.Fidelity()*100)
+	return nil
+}
+
+// Print projected cache efficiency of replayed requests, side by side with the proposed VCL if compared
+func printCacheStats(current, proposed *replay.CacheStats) {
+	if proposed == nil {
+		writeln(white, "Projected cache efficiency:")
+	} else {
+		writeln(white, "Projected cache efficiency:  %12s %12s", "current", "proposed")
+	}
+	rows := []struct {
+		name  string
+		value func(s *replay.CacheStats) string
+	}{
+		{"Hit ratio", func(s *replay.CacheStats) string { return fmt.Sprintf("%.2f%%", s.HitRatio*100) }},
+		{"Origin offload", func(s *replay.CacheStats) string { return fmt.Sprintf("%.2f%%", s.OriginOffload*100) }},
+		{"Bandwidth savings", func(s *replay.CacheStats) string { return fmt.Sprintf("%.2f%%", s.BandwidthSavings*100) }},
+		{"HIT/MISS/PASS", func(s *replay.CacheStats) string { return fmt.Sprintf("%d/%d/%d", s.Hits, s.Misses, s.Passes) }},
+		{"Origin bytes", func(s *replay.CacheStats) string { return fmt.Sprint(s.OriginBytes) }},
+	}
+	for _, row := range rows {
+		if proposed == nil {
+			writeln(white, "%s%-26s %12s", indent(1), row.name, row.value(current))
+			continue
+		}
+		writeln(white, "%s%-26s %12s %12s", indent(1), row.name, row.value(current), row.value(proposed))
+	}
+}
+
+func writeCoverageProfile(c *shared.CoverageFactory, path, format string) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	switch format {
+	case "json":
+		return coverage.FromFactory(c, cwd).WriteFile(path)
+	case "json-stable":
+		return coverage.StableFromFactory(c, cwd).WriteFile(path)
+	case "lcov", "cobertura":
+		fp, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		defer fp.Close()
+		if format == "cobertura" {
+			return shared.NewCoberturaEncoder(fp, cwd).Encode(c)
+		}
+		return shared.NewLCOVEncoder(fp, cwd).Encode(c)
+	default:
+		return fmt.Errorf("unsupported coverage format %s, expects json, json-stable, lcov or cobertura", format)
+	}
+}
+
+// Write test results as JUnit XML which is rendered natively by CI systems
+func writeTestReport(factory *tester.TestFactory, path string) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	fp, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer fp.Close()
+	return tester.NewJUnitEncoder(fp, cwd).Encode(factory)
+}
+
+func writeCoverageHTML(c *shared.CoverageFactory, dir string) error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	return coverage.FromFactory(c, cwd).WriteHTML(dir, cwd)
+}
+
+func runCoverage(c *config.Config, action string, args []string) error {
+	switch action {
+	case "diff":
+		var ref string
+		if len(args) > 0 {
+			ref = args[0]
+		}
+		return runCoverageDiff(c, ref)
+	case "merge":
+		return runCoverageMerge(c, args)
+	case "compare":
+		return runCoverageCompare(c, args)
+	default:
+		return fmt.Errorf("unrecognized coverage subcommand: %s", action)
+	}
+}
+
+// Merge coverage profiles of sharded test runs and write the merged profile or report
+func runCoverageMerge(c *config.Config, files []string) error {
+	if len(files) == 0 {
+		return fmt.Errorf("coverage profiles are not specified")
+	}
+	if c.Coverage.Output == "" && c.Coverage.HTML == "" {
+		return fmt.Errorf("output is not specified, provide --output or --html option")
+	}
+
+	profiles, err := readCoverageProfiles(files)
+	if err != nil {
+		return err
+	}
+	merged := coverage.Merge(profiles...)
+	factory := merged.Factory()
+
+	if c.Coverage.Output != "" {
+		format := c.Coverage.Format
+		// Merged stable profiles are written as stable profile in order to keep structural ids
+		if merged.Stable && format == "json" {
+			format = "json-stable"
+		}
+		if err := writeCoverageProfile(factory, c.Coverage.Output, format); err != nil {
+			return fmt.Errorf("failed to write coverage profile: %w", err)
+		}
+	}
+	if c.Coverage.HTML != "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+		if err := merged.WriteHTML(c.Coverage.HTML, cwd); err != nil {
+			return fmt.Errorf("failed to write HTML coverage report: %w", err)
+		}
+	}
+
+	if c.Json {
+		return json.NewEncoder(os.Stdout).Encode(factory.Report())
+	}
+	writeln(white, "Merged %d coverage profiles", len(profiles))
+	return printCoverageTable(factory)
+}
+
+// Read coverage profiles which are combined, stable and position based profiles could not be combined
+// because markers are identified differently
+func readCoverageProfiles(files []string) ([]*coverage.Profile, error) {
+	profiles := make([]*coverage.Profile, len(files))
+	for i, file := range files {
+		p, err := coverage.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read coverage profile %s: %w", file, err)
+		}
+		if i > 0 && p.Stable != profiles[0].Stable {
+			return nil, fmt.Errorf("coverage profile %s has different format from %s, json and json-stable profiles could not be combined", file, files[0])
+		}
+		profiles[i] = p
+	}
+	return profiles, nil
+}
+
+// Compare two coverage profiles and report markers which only one of them covers
+func runCoverageCompare(c *config.Config, files []string) error {
+	if len(files) != 2 {
+		return fmt.Errorf("two coverage profiles must be specified")
+	}
+	profiles, err := readCoverageProfiles(files)
+	if err != nil {
+		return err
+	}
+	report := coverage.Compare(profiles[0], profiles[1])
+
+	if c.Json {
+		return json.NewEncoder(os.Stdout).Encode(report)
+	}
+	for i, blocks := range [][]*coverage.Block{report.OnlyA, report.OnlyB} {
+		writeln(
+			yellow, "Covered only by %s: %d statements, %d branches, %d subroutines",
+			files[i],
+			coverage.Count(blocks, shared.CoverageTypeStatement),
+			coverage.Count(blocks, shared.CoverageTypeBranch),
+			coverage.Count(blocks, shared.CoverageTypeSubroutine),
+		)
+		for _, b := range blocks {
+			writeln(white, "%s%s:%d %s (%s)", indent(1), b.File, b.Line, b.Type, strings.TrimPrefix(b.ID, b.File+":"))
+		}
+		writeln(white, "")
+	}
+	writeln(white, "%d markers are covered by both profiles, %d markers are covered by neither", report.Both, report.Neither)
+	return nil
+}
+
+func runCoverageDiff(c *config.Config, ref string) error {
+	if c.Coverage.Base == "" {
+		return fmt.Errorf("coverage profile is not specified, provide --base option")
+	}
+	profile, err := coverage.ReadFile(c.Coverage.Base)
+	if err != nil {
+		return fmt.Errorf("failed to read coverage profile: %w", err)
+	}
+
+	changed, err := gitChangedLines(ref)
+	if err != nil {
+		return err
+	}
+	report := profile.Patch(changed)
+
+	if c.Json {
+		if err := json.NewEncoder(os.Stdout).Encode(report); err != nil {
+			return err
+		}
+	} else {
+		uncovered := report.Uncovered()
+		if len(uncovered) > 0 {
+			writeln(yellow, "Changed lines which lack coverage:")
+			for _, l := range uncovered {
+				state := "not covered"
+				if l.Partial() {
+					state = fmt.Sprintf("partially covered %d/%d", l.Hits, l.Blocks)
+				}
+				writeln(white, "%s%s:%d (%s)", indent(1), l.File, l.Line, state)
+			}
+			writeln(white, "")
+		}
+		writeln(white, "%d changed lines, %d covered, %d partial, %d missed", len(report.Lines), report.Covered, report.Partial, report.Missed)
+	}
+
+	percent := report.Percent()
+	if percent < float64(c.Coverage.Threshold) {
+		writeln(red, "Patch coverage %.2f%% is below the threshold %d%%", percent, c.Coverage.Threshold)
+		return ErrExit
+	}
+	if !c.Json {
+		writeln(green, "Patch coverage: %.2f%%", percent)
+	}
+	return nil
+}
+
+// Find added or changed lines between the git reference and working tree, HEAD is used if reference is not provided
+func gitChangedLines(ref string) (coverage.ChangedLines, error) {
+	if ref == "" {
+		ref = "HEAD"
+	}
+	out, err := exec.Command("git", "diff", "--unified=0", "--no-color", "--no-ext-diff", "--relative", ref).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get git diff: %w", err)
+	}
+	changed, err := coverage.ParseDiff(bytes.NewReader(out))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse git diff: %w", err)
+	}
+	return changed, nil
+}
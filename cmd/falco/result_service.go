@@ -0,0 +1,285 @@
+package main
+
+import (
+	"context"
+	"maps"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/ysugimoto/falco/v2/config"
+	ife "github.com/ysugimoto/falco/v2/interpreter/function/errors"
+	falcov1 "github.com/ysugimoto/falco/v2/proto/falco/v1"
+	"github.com/ysugimoto/falco/v2/resolver"
+	"github.com/ysugimoto/falco/v2/tester"
+	"github.com/ysugimoto/falco/v2/tester/shared"
+	"github.com/ysugimoto/falco/v2/token"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// resultService serves test results and lint findings over gRPC streaming for programmatic consumers.
+// Each call runs on its own runner with the configuration of the daemon, the main VCL and include paths
+// are provided by the request and must be placed in the project root.
+// The configuration is a snapshot of the daemon configuration and is never modified,
+// each call modifies its own deep copy so that calls could run concurrently
+type resultService struct {
+	falcov1.UnimplementedResultServiceServer
+
+	config *config.Config
+	root   string
+}
+
+func newResultService(c *config.Config, root string) *resultService {
+	return &resultService{config: c.Clone(), root: root}
+}
+
+// serveResultService starts gRPC server of ResultService on the address in background.
+// Returned function stops the server
+func (r *Runner) serveResultService(host string, port int) (func(), error) {
+	// Project root is the directory of the configuration file, or current working directory like bundle
+	root, err := os.Getwd()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if r.config.ConfigFile != "" {
+		root = filepath.Dir(r.config.ConfigFile)
+	}
+	if root, err = realPath(root); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	lis, err := net.Listen("tcp", net.JoinHostPort(host, strconv.Itoa(port)))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	s := grpc.NewServer()
+	falcov1.RegisterResultServiceServer(s, newResultService(r.config, root))
+	go s.Serve(lis) // nolint:errcheck
+
+	writeln(green, "Result service of gRPC starts on %s", lis.Addr())
+	if ip := net.ParseIP(host); ip == nil || !ip.IsLoopback() {
+		writeln(yellow, "Result service does not authenticate clients, any client which can reach %s could run tests in %s", lis.Addr(), root)
+	}
+	return s.Stop, nil
+}
+
+// Resolve the path to the absolute path without symbolic links
+func realPath(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	return filepath.EvalSymlinks(abs)
+}
+
+// Resolve the path in the request. Relative path is resolved from the project root,
+// and the path which points outside of the project root is not permitted even through symbolic links
+func (s *resultService) projectPath(path string) (string, error) {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(s.root, path)
+	}
+	if !s.inProject(path) {
+		return "", status.Errorf(codes.PermissionDenied, "%s is outside of the project root %s", path, s.root)
+	}
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return "", status.Error(codes.InvalidArgument, err.Error())
+	}
+	if !s.inProject(resolved) {
+		return "", status.Errorf(codes.PermissionDenied, "%s is outside of the project root %s", path, s.root)
+	}
+	return resolved, nil
+}
+
+func (s *resultService) inProject(path string) bool {
+	rel, err := filepath.Rel(s.root, path)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// Create the runner for the call. Output of the runner is suppressed like JSON mode
+func (s *resultService) runner(ctx context.Context, command, main string, includePaths []string) (*Runner, resolver.Resolver, error) {
+	if main == "" {
+		return nil, nil, status.Error(codes.InvalidArgument, "main VCL file must be specified")
+	}
+	main, err := s.projectPath(main)
+	if err != nil {
+		return nil, nil, err
+	}
+	paths := make([]string, len(includePaths))
+	for i := range includePaths {
+		if paths[i], err = s.projectPath(includePaths[i]); err != nil {
+			return nil, nil, err
+		}
+	}
+	resolvers, err := resolver.NewFileResolvers(main, paths)
+	if err != nil {
+		return nil, nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	c := s.config.Clone()
+	c.Commands = config.Commands{command, main}
+	c.IncludePaths = paths
+	c.Json = true
+	c.Testing.IncludePaths = paths
+
+	return NewRunner(ctx, c, nil), resolvers[0], nil
+}
+
+func (s *resultService) RunTests(req *falcov1.RunTestsRequest, stream grpc.ServerStreamingServer[falcov1.Event]) error {
+	r, rslv, err := s.runner(stream.Context(), subcommandTest, req.GetMain(), req.GetIncludePaths())
+	if err != nil {
+		return err
+	}
+	if req.GetFilter() != "" {
+		r.config.Testing.Filter = req.GetFilter()
+	}
+	r.config.Testing.Coverage = req.GetCoverage()
+
+	// Results are sent as soon as each test file has finished.
+	// The callback is serialized by the tester so the stream is not sent concurrently
+	var sendErr error
+	r.onTestResult = func(result *tester.TestResult) {
+		if sendErr != nil {
+			return
+		}
+		sendErr = stream.Send(&falcov1.Event{
+			Result: &falcov1.Event_TestResult{TestResult: newTestResult(result)},
+		})
+	}
+
+	factory, err := r.Test(rslv)
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to run test: %s", err)
+	}
+	if sendErr != nil {
+		return sendErr
+	}
+
+	if err := stream.Send(&falcov1.Event{
+		Result: &falcov1.Event_Summary{Summary: newTestSummary(factory.Statistics)},
+	}); err != nil {
+		return err
+	}
+	if factory.Coverage != nil {
+		return stream.Send(&falcov1.Event{
+			Result: &falcov1.Event_Coverage{Coverage: newCoverage(factory.Coverage.Report())},
+		})
+	}
+	return nil
+}
+
+func (s *resultService) Lint(req *falcov1.LintRequest, stream grpc.ServerStreamingServer[falcov1.Event]) error {
+	r, rslv, err := s.runner(stream.Context(), subcommandLint, req.GetMain(), req.GetIncludePaths())
+	if err != nil {
+		return err
+	}
+
+	result, err := r.Run(rslv)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	if len(result.ParseErrors) > 0 {
+		file := slices.Sorted(maps.Keys(result.ParseErrors))[0]
+		return status.Error(codes.InvalidArgument, result.ParseErrors[file].Error())
+	}
+
+	// Findings are sent in order of files
+	for _, file := range slices.Sorted(maps.Keys(result.LintErrors)) {
+		for _, le := range result.LintErrors[file] {
+			if err := stream.Send(&falcov1.Event{
+				Result: &falcov1.Event_LintFinding{LintFinding: &falcov1.LintFinding{
+					Severity:  strings.ToUpper(string(r.severity(le))),
+					Rule:      string(le.Rule),
+					Message:   le.Message,
+					Reference: le.Reference,
+					Position:  newPosition(le.Token),
+				}},
+			}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func newPosition(t token.Token) *falcov1.Position {
+	return &falcov1.Position{
+		File:     t.File,
+		Line:     int32(t.Line),
+		Position: int32(t.Position),
+	}
+}
+
+func newTestResult(result *tester.TestResult) *falcov1.TestResult {
+	v := &falcov1.TestResult{File: result.Filename}
+	for _, c := range result.Cases {
+		v.Suites = append(v.Suites, newTestCase(c, c.Name, c.Scope))
+		// Subtests are listed after the test case with the nested name as CLI output does
+		for _, st := range c.Subtests {
+			v.Suites = append(v.Suites, newTestCase(st, c.Name+" › "+st.Name, c.Scope))
+		}
+	}
+	return v
+}
+
+func newTestCase(c *tester.TestCase, name, scope string) *falcov1.TestCase {
+	v := &falcov1.TestCase{
+		Name:        name,
+		Group:       c.Group,
+		Scope:       scope,
+		ElapsedTime: c.Time,
+		Skip:        c.Skip,
+		Logs:        c.Logs,
+	}
+
+	// In soft assertion mode, the first failure is reported
+	err := c.Error
+	if e, ok := err.(*ife.AssertionErrors); ok && len(e.Errors) > 0 {
+		err = e.Errors[0]
+	}
+	switch e := err.(type) {
+	case nil:
+	case *ife.AssertionError:
+		v.Error = e.Message
+		v.Position = newPosition(e.Token)
+	case *ife.TestingError:
+		v.Error = e.Message
+		v.Position = newPosition(e.Token)
+	default:
+		v.Error = e.Error()
+	}
+	return v
+}
+
+func newTestSummary(c *shared.Counter) *falcov1.TestSummary {
+	return &falcov1.TestSummary{
+		Asserts: int32(c.Asserts),
+		Passes:  int32(c.Passes),
+		Fails:   int32(c.Fails),
+		Skips:   int32(c.Skips),
+	}
+}
+
+func newCoverage(report *shared.CoverageReport) *falcov1.Coverage {
+	item := func(v *shared.CoverageReportItem) *falcov1.CoverageItem {
+		return &falcov1.CoverageItem{
+			Executed: v.Executed,
+			Total:    v.Total,
+			Percent:  v.Percent,
+		}
+	}
+	return &falcov1.Coverage{
+		Subroutines: item(report.Subroutines),
+		Statements:  item(report.Statements),
+		Branches:    item(report.Branches),
+	}
+}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/ysugimoto/falco/v2/config"
+	falcov1 "github.com/ysugimoto/falco/v2/proto/falco/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func newResultServiceClient(t *testing.T, root string) falcov1.ResultServiceClient {
+	t.Helper()
+	root, err := realPath(root)
+	if err != nil {
+		t.Fatalf("Unexpected resolving project root error: %s", err)
+	}
+	lis := bufconn.Listen(1024 * 1024)
+	s := grpc.NewServer()
+	falcov1.RegisterResultServiceServer(s, newResultService(&config.Config{
+		Linter:  &config.LinterConfig{},
+		Testing: &config.TestConfig{},
+	}, root))
+	go s.Serve(lis) // nolint:errcheck
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.NewClient(
+		"passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("Unexpected client creation error: %s", err)
+	}
+	t.Cleanup(func() { conn.Close() }) // nolint:errcheck
+	return falcov1.NewResultServiceClient(conn)
+}
+
+func receiveEvents(stream grpc.ServerStreamingClient[falcov1.Event]) ([]*falcov1.Event, error) {
+	var events []*falcov1.Event
+	for {
+		ev, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return events, nil
+		} else if err != nil {
+			return events, err
+		}
+		events = append(events, ev)
+	}
+}
+
+func TestResultServiceRunTests(t *testing.T) {
+	client := newResultServiceClient(t, "../..")
+
+	stream, err := client.RunTests(context.Background(), &falcov1.RunTestsRequest{
+		Main:     "examples/testing/group/group.vcl",
+		Filter:   "*group.test.vcl",
+		Coverage: true,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected RunTests error: %s", err)
+	}
+	events, err := receiveEvents(stream)
+	if err != nil {
+		t.Fatalf("Unexpected stream error: %s", err)
+	}
+	if len(events) != 3 {
+		t.Fatalf("Expected 3 events but got %d: %v", len(events), events)
+	}
+
+	result := events[0].GetTestResult()
+	if result == nil || filepath.Base(result.GetFile()) != "group.test.vcl" {
+		t.Errorf("First event should be the result of group.test.vcl, got %v", events[0])
+	}
+	for _, c := range result.GetSuites() {
+		if c.GetError() != "" {
+			t.Errorf(`Test case "%s" raises error: %s`, c.GetName(), c.GetError())
+		}
+	}
+	if summary := events[1].GetSummary(); summary == nil || summary.GetPasses() != 3 || summary.GetFails() != 0 {
+		t.Errorf("Second event should be the summary of 3 passes, got %v", events[1])
+	}
+	if coverage := events[2].GetCoverage(); coverage == nil || coverage.GetStatements().GetTotal() == 0 {
+		t.Errorf("Last event should be the coverage, got %v", events[2])
+	}
+}
+
+func TestResultServiceConcurrentCalls(t *testing.T) {
+	client := newResultServiceClient(t, "../..")
+
+	// Each call has its own configuration so that options of the call do not affect other calls
+	var wg sync.WaitGroup
+	counts := make([]int, 2)
+	errs := make([]error, 2)
+	for i, coverage := range []bool{true, false} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			stream, err := client.RunTests(context.Background(), &falcov1.RunTestsRequest{
+				Main:     "examples/testing/group/group.vcl",
+				Filter:   "*group.test.vcl",
+				Coverage: coverage,
+			})
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			events, err := receiveEvents(stream)
+			counts[i], errs[i] = len(events), err
+		}()
+	}
+	wg.Wait()
+
+	for i, expect := range []int{3, 2} {
+		if errs[i] != nil {
+			t.Errorf("Unexpected error on call %d: %s", i, errs[i])
+		} else if counts[i] != expect {
+			t.Errorf("Expected %d events on call %d but got %d", expect, i, counts[i])
+		}
+	}
+}
+
+func TestResultServiceLint(t *testing.T) {
+	dir, err := realPath(t.TempDir())
+	if err != nil {
+		t.Fatalf("Unexpected resolving temporary directory error: %s", err)
+	}
+	client := newResultServiceClient(t, dir)
+
+	t.Run("stream lint findings", func(t *testing.T) {
+		main := filepath.Join(dir, "main.vcl")
+		vcl := "acl internal {}\n\nsub vcl_recv {\n  #FASTLY recv\n}\n"
+		if err := os.WriteFile(main, []byte(vcl), 0o644); err != nil {
+			t.Fatalf("Unexpected write error: %s", err)
+		}
+		stream, err := client.Lint(context.Background(), &falcov1.LintRequest{Main: main})
+		if err != nil {
+			t.Fatalf("Unexpected Lint error: %s", err)
+		}
+		events, err := receiveEvents(stream)
+		if err != nil {
+			t.Fatalf("Unexpected stream error: %s", err)
+		}
+		var found bool
+		for _, ev := range events {
+			f := ev.GetLintFinding()
+			if f == nil || f.GetRule() != "unused/declaration" {
+				continue
+			}
+			found = true
+			if f.GetSeverity() != "WARNING" || f.GetPosition().GetLine() != 1 || f.GetPosition().GetFile() != main {
+				t.Errorf("Unexpected lint finding: %v", f)
+			}
+		}
+		if !found {
+			t.Errorf("unused/declaration finding should be streamed, got %v", events)
+		}
+	})
+
+	t.Run("parse error", func(t *testing.T) {
+		main := filepath.Join(dir, "invalid.vcl")
+		if err := os.WriteFile(main, []byte("sub vcl_recv {\n"), 0o644); err != nil {
+			t.Fatalf("Unexpected write error: %s", err)
+		}
+		stream, err := client.Lint(context.Background(), &falcov1.LintRequest{Main: main})
+		if err != nil {
+			t.Fatalf("Unexpected Lint error: %s", err)
+		}
+		if _, err := receiveEvents(stream); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("Expected InvalidArgument error but got %v", err)
+		}
+	})
+
+	t.Run("paths outside of the project root", func(t *testing.T) {
+		outside := filepath.Join(t.TempDir(), "main.vcl")
+		if err := os.WriteFile(outside, []byte("sub vcl_recv {\n  #FASTLY recv\n}\n"), 0o644); err != nil {
+			t.Fatalf("Unexpected write error: %s", err)
+		}
+		rel, err := filepath.Rel(dir, outside)
+		if err != nil {
+			t.Fatalf("Unexpected making relative path error: %s", err)
+		}
+		requests := []*falcov1.LintRequest{
+			{Main: outside},
+			{Main: rel},
+			{Main: "main.vcl", IncludePaths: []string{filepath.Dir(outside)}},
+		}
+		for _, req := range requests {
+			stream, err := client.Lint(context.Background(), req)
+			if err != nil {
+				t.Fatalf("Unexpected Lint error: %s", err)
+			}
+			if _, err := receiveEvents(stream); status.Code(err) != codes.PermissionDenied {
+				t.Errorf("Expected PermissionDenied error for %v but got %v", req, err)
+			}
+		}
+	})
+
+	t.Run("main VCL is not specified", func(t *testing.T) {
+		stream, err := client.Lint(context.Background(), &falcov1.LintRequest{})
+		if err != nil {
+			t.Fatalf("Unexpected Lint error: %s", err)
+		}
+		if _, err := receiveEvents(stream); status.Code(err) != codes.InvalidArgument {
+			t.Errorf("Expected InvalidArgument error but got %v", err)
+		}
+	})
+}
@@ -1,29 +1,64 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
 	"maps"
+	"net"
 	"net/http"
 	"os"
+	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/fatih/color"
 	"github.com/pkg/errors"
 	"github.com/ysugimoto/falco/v2/ast"
+	"github.com/ysugimoto/falco/v2/canary"
 	"github.com/ysugimoto/falco/v2/config"
+	"github.com/ysugimoto/falco/v2/constant"
+	"github.com/ysugimoto/falco/v2/contract"
+	"github.com/ysugimoto/falco/v2/dashboard"
 	"github.com/ysugimoto/falco/v2/debugger"
+	"github.com/ysugimoto/falco/v2/dialect"
+	"github.com/ysugimoto/falco/v2/fiddle"
 	"github.com/ysugimoto/falco/v2/formatter"
+	"github.com/ysugimoto/falco/v2/inspector"
 	"github.com/ysugimoto/falco/v2/interpreter"
 	icontext "github.com/ysugimoto/falco/v2/interpreter/context"
+	"github.com/ysugimoto/falco/v2/interpreter/dictionary"
+	"github.com/ysugimoto/falco/v2/interpreter/function"
+	ihttp "github.com/ysugimoto/falco/v2/interpreter/http"
+	"github.com/ysugimoto/falco/v2/interpreter/process"
+	"github.com/ysugimoto/falco/v2/interpreter/resource"
+	"github.com/ysugimoto/falco/v2/inventory"
+	"github.com/ysugimoto/falco/v2/layer"
 	"github.com/ysugimoto/falco/v2/lexer"
 	"github.com/ysugimoto/falco/v2/linter"
+	lcache "github.com/ysugimoto/falco/v2/linter/cache"
 	lcontext "github.com/ysugimoto/falco/v2/linter/context"
+	"github.com/ysugimoto/falco/v2/migration"
+	"github.com/ysugimoto/falco/v2/mirror"
+	"github.com/ysugimoto/falco/v2/monitoring"
 	"github.com/ysugimoto/falco/v2/parser"
+	"github.com/ysugimoto/falco/v2/policy"
+	"github.com/ysugimoto/falco/v2/reload"
+	"github.com/ysugimoto/falco/v2/replay"
+	"github.com/ysugimoto/falco/v2/repro"
 	"github.com/ysugimoto/falco/v2/resolver"
+	"github.com/ysugimoto/falco/v2/shadow"
 	"github.com/ysugimoto/falco/v2/snippet"
+	"github.com/ysugimoto/falco/v2/symbol"
 	"github.com/ysugimoto/falco/v2/tester"
+	tf "github.com/ysugimoto/falco/v2/tester/function"
+	"github.com/ysugimoto/falco/v2/tester/shared"
+	"github.com/ysugimoto/falco/v2/token"
 )
 
 var (
@@ -63,6 +98,9 @@ type StatsResult struct {
 	Directors   int    `json:"directors"`
 	Files       int    `json:"files"`
 	Lines       int    `json:"lines"`
+
+	// Worst-case latencies of backends and directors including restart-based retries
+	Latencies []*linter.BackendLatency `json:"latencies"`
 }
 
 type RunMode int
@@ -73,15 +111,25 @@ const (
 )
 
 type Runner struct {
+	// Root context which is cancelled on receiving termination signal
+	ctx       context.Context
 	overrides map[string]linter.Severity
 	lexers    map[string]*lexer.Lexer
 	snippets  *snippet.Snippets
 	config    *config.Config
+	lintCache *lcache.Cache
+
+	// Policy evaluator which is shared in the runner so that policies are compiled once
+	policyOnce sync.Once
+	policy     *policy.Evaluator
 
 	level       Level
 	lintErrors  map[string][]*linter.LintError
 	parseErrors map[string]*parser.ParseError
 
+	// Called every time the test file has finished
+	onTestResult func(*tester.TestResult)
+
 	// runner result fields
 	infos    int
 	warnings int
@@ -99,8 +147,9 @@ func (r *Runner) message(c *color.Color, format string, args ...any) {
 	write(c, format, args...)
 }
 
-func NewRunner(c *config.Config, fetcher snippet.Fetcher) *Runner {
+func NewRunner(ctx context.Context, c *config.Config, fetcher snippet.Fetcher) *Runner {
 	r := &Runner{
+		ctx:         ctx,
 		level:       LevelError,
 		overrides:   make(map[string]linter.Severity),
 		lexers:      make(map[string]*lexer.Lexer),
@@ -115,22 +164,22 @@ func NewRunner(c *config.Config, fetcher snippet.Fetcher) *Runner {
 		var err error
 
 		// Lookup snippets cache
-		cache := fetcher.LookupCache(c.Refresh)
+		cache := fetcher.LookupCache(ctx, c.Refresh)
 		if cache != nil {
 			snippets = cache
 			r.message(white, "Use cached remote snippets.\n")
 		} else {
-			snippets, err = snippet.Fetch(fetcher)
+			snippets, err = snippet.Fetch(ctx, fetcher)
 		}
 		if err != nil {
 			r.message(red, "%s\n", err.Error())
 		}
 		r.snippets = snippets
-		if err := r.snippets.FetchLoggingEndpoint(fetcher); err != nil {
+		if err := r.snippets.FetchLoggingEndpoint(ctx, fetcher); err != nil {
 			r.message(red, "%s\n", err.Error())
 		}
 		// ...and save cache after the constructor
-		defer fetcher.WriteCache(snippets)
+		defer fetcher.WriteCache(ctx, snippets)
 	}
 
 	// Set verbose level
@@ -160,6 +209,8 @@ func NewRunner(c *config.Config, fetcher snippet.Fetcher) *Runner {
 }
 
 func (r *Runner) Run(rslv resolver.Resolver) (*RunnerResult, error) {
+	defer r.closePolicy()
+
 	options := []lcontext.Option{lcontext.WithResolver(rslv)}
 	// If remote snippets exists, prepare parse and prepend to main VCL
 	if r.snippets != nil {
@@ -188,8 +239,70 @@ func (r *Runner) Run(rslv resolver.Resolver) (*RunnerResult, error) {
 	}, nil
 }
 
+// Enable lint result cache unless disabled.
+// JSON output contains parsed AST so that cached result could not be used
+func (r *Runner) enableLintCache() {
+	if r.config.Linter.NoCache || r.config.Json || r.policyEvaluator() != nil {
+		return
+	}
+	c, err := lcache.New(r.config.Linter.CacheDir)
+	if err != nil {
+		r.message(yellow, "Failed to prepare lint cache, lint without cache: %s\n", err)
+		return
+	}
+	r.lintCache = c
+}
+
+// Lint cache key consists of the main VCL and all configurations which affect lint results.
+// Included files are verified by the content hashes and resolved paths recorded in the cache entry
+func (r *Runner) lintCacheKey(ctx *lcontext.Context, main *resolver.VCL) string {
+	linterConfig, _ := json.Marshal(r.config.Linter) // nolint:errcheck
+	snippets, _ := json.Marshal(r.snippets)          // nolint:errcheck
+	protected := strings.Join(r.protectedHeaders(), "\n")
+	return lcache.Key(
+		[]byte(version),
+		[]byte(lcache.Fingerprint()),
+		[]byte(main.Name),
+		[]byte(main.Data),
+		[]byte(strings.Join(ctx.Resolver().IncludePaths(), "\n")),
+		linterConfig,
+		snippets,
+		[]byte(protected),
+	)
+}
+
+// Resolve include module in the same way as the linter in order to detect
+// that the cached module is shadowed by a new file which is found earlier on the include paths
+func lintCacheResolver(ctx *lcontext.Context) lcache.Resolver {
+	return func(module string) string {
+		vcl, err := ctx.Resolver().Resolve(&ast.IncludeStatement{
+			Module: &ast.String{Value: module},
+		})
+		if err != nil {
+			return ""
+		}
+		return vcl.Name
+	}
+}
+
 func (r *Runner) run(ctx *lcontext.Context, main *resolver.VCL, mode RunMode) (*VCL, error) {
-	vcl, err := r.parseVCL(main.Name, main.Data)
+	// Skip parsing and linting entirely if nothing is changed from the cached run
+	var cacheKey string
+	if r.lintCache != nil && mode&RunModeStat == 0 {
+		cacheKey = r.lintCacheKey(ctx, main)
+		if entry, ok := r.lintCache.Lookup(cacheKey, lintCacheResolver(ctx)); ok {
+			r.message(white, "Use cached lint result.\n")
+			r.restoreLexers(main, entry.Errors)
+			r.reportLintErrors(main, entry.Errors)
+			return &VCL{File: main.Name}, nil
+		}
+	}
+
+	d, err := dialect.Get(r.config.Linter.Dialect)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	vcl, err := r.parseVCL(main.Name, main.Data, d.ParserOptions()...)
 	if err != nil {
 		return nil, err
 	}
@@ -209,7 +322,7 @@ func (r *Runner) run(ctx *lcontext.Context, main *resolver.VCL, mode RunMode) (*
 		}
 	}
 
-	lt := linter.New(r.config.Linter)
+	lt := linter.New(r.config.Linter, linter.WithProtectedHeaders(r.protectedHeaders()))
 	lt.Lint(vcl, ctx)
 
 	maps.Copy(r.lexers, lt.Lexers())
@@ -236,31 +349,128 @@ func (r *Runner) run(ctx *lcontext.Context, main *resolver.VCL, mode RunMode) (*
 		return nil, ErrParser
 	}
 
-	if len(lt.Errors) > 0 {
-		for _, le := range lt.Errors {
-			// check severity with overrides
-			severity := le.Severity
-			if v, ok := r.overrides[string(le.Rule)]; ok {
-				severity = v
-			}
+	// Evaluate policies against the parsed AST if provided
+	if p := r.policyEvaluator(); p != nil {
+		violations, err := p.Evaluate(policy.KindAST, policy.NewASTInput(lt.Statements))
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		for _, v := range violations {
+			lt.Errors = append(lt.Errors, linter.FromPolicyViolation(v))
+		}
+	}
 
-			// Store all but ignored linter errors
-			if r.config.Json && severity != linter.IGNORE {
-				r.lintErrors[le.Token.File] = append(r.lintErrors[le.Token.File], le)
+	if cacheKey != "" {
+		// Fastly managed snippets are not files but they are already a part of cache key
+		files := []string{main.Name}
+		for name := range lt.Lexers() {
+			if !strings.HasPrefix(name, "snippet::") {
+				files = append(files, name)
 			}
-			r.printLinterError(r.lexers[main.Name], severity, le)
+		}
+		// Failing to store means that some of VCLs are not local files, then it could not be cached
+		if err := r.lintCache.Store(cacheKey, files, lt.Includes, lt.Errors); err != nil {
+			slog.Debug("Lint result is not cached", "error", err)
 		}
 	}
 
+	r.reportLintErrors(main, lt.Errors)
+
 	return &VCL{
 		File: main.Name,
 		AST:  vcl,
 	}, nil
 }
 
-func (r *Runner) parseVCL(name, code string) (*ast.VCL, error) {
+func (r *Runner) reportLintErrors(main *resolver.VCL, lintErrors []*linter.LintError) {
+	for _, le := range lintErrors {
+		severity := r.severity(le)
+
+		// Store all but ignored linter errors
+		if r.config.Json && severity != linter.IGNORE {
+			r.lintErrors[le.Token.File] = append(r.lintErrors[le.Token.File], le)
+		}
+		r.printLinterError(r.lexers[main.Name], severity, le)
+	}
+}
+
+// Severity of the lint error which could be overridden by the configuration
+func (r *Runner) severity(le *linter.LintError) linter.Severity {
+	if v, ok := r.overrides[string(le.Rule)]; ok {
+		return v
+	}
+	return le.Severity
+}
+
+// Cached lint result does not have lexers so tokenize the files which have errors in order to print problem lines
+func (r *Runner) restoreLexers(main *resolver.VCL, lintErrors []*linter.LintError) {
+	for _, le := range lintErrors {
+		file := le.Token.File
+		if file == "" {
+			file = main.Name
+		}
+		if _, ok := r.lexers[file]; ok {
+			continue
+		}
+
+		var code string
+		switch {
+		case file == main.Name:
+			code = main.Data
+		case strings.HasPrefix(file, "snippet::"):
+			if r.snippets == nil {
+				continue
+			}
+			snip, ok := r.snippets.IncludeSnippets[strings.TrimPrefix(file, "snippet::")]
+			if !ok {
+				continue
+			}
+			code = snip.Data
+		default:
+			buf, err := os.ReadFile(file)
+			if err != nil {
+				continue
+			}
+			code = string(buf)
+		}
+		lx := lexer.NewFromString(code, lexer.WithFile(file))
+		for lx.NextToken().Type != token.EOF {
+		}
+		lx.NewLine()
+		r.lexers[file] = lx
+	}
+}
+
+// Create policy evaluator if policy files are provided
+func (r *Runner) policyEvaluator() *policy.Evaluator {
+	r.policyOnce.Do(func() {
+		pc := r.config.Policy
+		if pc == nil || len(pc.Files) == 0 {
+			return
+		}
+		r.policy = policy.New(pc.Files, policy.WithQuery(pc.Query), policy.WithCommand(pc.Command))
+	})
+	return r.policy
+}
+
+// Wait for pending policy evaluations and stop the policy server if started
+func (r *Runner) closePolicy() {
+	if p := r.policyEvaluator(); p != nil {
+		p.Close()
+	}
+}
+
+// Header name patterns which must never be exposed to clients or logs
+func (r *Runner) protectedHeaders() []string {
+	if r.config.Policy == nil {
+		return nil
+	}
+	return r.config.Policy.ProtectedHeaders
+}
+
+func (r *Runner) parseVCL(name, code string, opts ...parser.ParserOption) (*ast.VCL, error) {
 	lx := lexer.NewFromString(code, lexer.WithFile(name))
-	p := parser.New(lx)
+	p := parser.New(lx, append([]parser.ParserOption{parser.WithContext(r.ctx)}, opts...)...)
 	vcl, err := p.ParseVCLOrSnippet()
 	if err != nil {
 		lx.NewLine()
@@ -318,7 +528,9 @@ func (r *Runner) printLinterError(lx *lexer.Lexer, severity linter.Severity, err
 	// Override lexer because error may cause in other included module
 	if err.Token.File != "" {
 		file = "in " + err.Token.File + " "
-		lx = r.lexers[err.Token.File]
+		if v, ok := r.lexers[err.Token.File]; ok {
+			lx = v
+		}
 	}
 
 	switch severity {
@@ -395,6 +607,7 @@ func (r *Runner) Stats(rslv resolver.Resolver) (*StatsResult, error) {
 		Backends:    len(ctx.Backends),
 		Acls:        len(ctx.Acls),
 		Directors:   len(ctx.Directors),
+		Latencies:   linter.EstimateLatencies(ctx),
 	}
 
 	for _, lx := range r.lexers {
@@ -405,15 +618,35 @@ func (r *Runner) Stats(rslv resolver.Resolver) (*StatsResult, error) {
 	return stats, nil
 }
 
-func (r *Runner) Simulate(rslv resolver.Resolver) error {
+func (r *Runner) simulatorOptions(rslv resolver.Resolver, isTLS bool) ([]icontext.Option, error) {
 	sc := r.config.Simulator
-	isTLS := sc.KeyFile != "" && sc.CertFile != ""
+	if sc.FlowDiagram != "" && !process.IsDiagramFormat(sc.FlowDiagram) {
+		return nil, fmt.Errorf("unsupported flow diagram format %s, must be mermaid or ascii", sc.FlowDiagram)
+	}
+	if err := r.validateUnimplemented(); err != nil {
+		return nil, err
+	}
+	if sc.RecordTrace != "" {
+		if err := os.MkdirAll(sc.RecordTrace, 0o755); err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
 	options := []icontext.Option{
 		icontext.WithResolver(rslv),
 		icontext.WithMaxBackends(r.config.OverrideMaxBackends),
 		icontext.WithMaxAcls(r.config.OverrideMaxAcls),
 		icontext.WithActualResponse(sc.IsProxyResponse),
+		icontext.WithStrictRTime(r.config.StrictRTime),
+		icontext.WithStrictHeaders(r.config.StrictHeaders),
+		icontext.WithExternalHeaders(r.config.ExternalHeaders),
+		icontext.WithUnimplemented(r.config.Unimplemented),
 		icontext.WithTLServer(isTLS),
+		// Web UI shows variable provenance and cache decision of each request
+		icontext.WithProvenance(sc.IsTrace || sc.UI),
+		icontext.WithExplain(sc.IsExplain || sc.UI),
+		icontext.WithFlowDiagram(sc.FlowDiagram),
+		icontext.WithTraceDir(sc.RecordTrace),
+		icontext.WithProtectedHeaders(r.protectedHeaders()),
 	}
 
 	if r.snippets != nil {
@@ -429,6 +662,27 @@ func (r *Runner) Simulate(rslv resolver.Resolver) error {
 	if sc.OverrideEdgeDictionaries != nil {
 		options = append(options, icontext.WithInjectEdgeDictionaries(sc.OverrideEdgeDictionaries))
 	}
+	// If policy files are provided, evaluate them against the execution trace
+	if p := r.policyEvaluator(); p != nil {
+		options = append(options, icontext.WithPolicy(p))
+	}
+	// If resource files are provided, load them and reload on change
+	if rf := sc.ResourceFiles; rf != nil && len(rf.Tables)+len(rf.Acls)+len(rf.EdgeDictionaries) > 0 {
+		store, err := resource.New(sc.ResourceFiles)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		go r.watchResources(store)
+		options = append(options, icontext.WithResources(store))
+	}
+	// If dictionary storage is provided, read edge dictionaries from it on each request
+	if ds := sc.DictionaryStorage; ds != nil && len(ds.Dictionaries) > 0 {
+		storage, err := dictionary.New(ds)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		options = append(options, icontext.WithDictionaryStorage(storage))
+	}
 
 	// Factory override variables.
 	// The order is important, should do yaml -> cli order because cli could override yaml configuration
@@ -448,6 +702,38 @@ func (r *Runner) Simulate(rslv resolver.Resolver) error {
 	if len(overrides) > 0 {
 		options = append(options, icontext.WithOverrideVariables(overrides))
 	}
+	return options, nil
+}
+
+func (r *Runner) Simulate(rslv resolver.Resolver) error {
+	defer r.closePolicy()
+
+	sc := r.config.Simulator
+	isTLS := sc.KeyFile != "" && sc.CertFile != ""
+
+	// If watch flag is on, serve the snapshot of VCLs which is swapped on file change
+	var reloader *reload.Reloader
+	if sc.Watch {
+		var err error
+		if reloader, err = reload.New(rslv, r.validateProgram); err != nil {
+			return err
+		}
+		if status := reloader.Status(); len(status.Errors) > 0 {
+			writeln(red, "VCL has problems, fix them to be reloaded:\n%s", strings.Join(status.Errors, "\n"))
+		}
+		go r.watchProgram(reloader)
+		rslv = reloader
+	}
+
+	// Mirrored traffic is compared with the actual response of the simulator
+	if r.config.Mirror.Target != "" {
+		sc.IsProxyResponse = true
+	}
+
+	options, err := r.simulatorOptions(rslv, isTLS)
+	if err != nil {
+		return err
+	}
 
 	i := interpreter.New(options...)
 
@@ -456,35 +742,385 @@ func (r *Runner) Simulate(rslv resolver.Resolver) error {
 		return debugger.New(i).Run(sc)
 	}
 
+	// Test results and lint findings are also served over gRPC while the simulator is running
+	if sc.GrpcPort > 0 {
+		stop, err := r.serveResultService(sc.GrpcHost, sc.GrpcPort)
+		if err != nil {
+			return err
+		}
+		defer stop()
+	}
+
 	// Otherwise, simply start simulator server. Serve the interpreter as the
 	// root handler directly: an http.ServeMux would path.Clean-301 requests
 	// with `//`, `/./`, or `/../`, hiding those raw paths from VCL. Real Fastly
 	// preserves them in req.url / req.url.path, so the simulator must too.
+	var handler http.Handler = i
+	if r.config.Mirror.Target != "" {
+		if handler, err = r.mirrorHandler(handler); err != nil {
+			return err
+		}
+	}
+	if sc.UI {
+		in := inspector.New(handler)
+		i.OnProcessed = in.Hook
+		handler = in
+		writeln(green, "Simulator web UI is served on http://localhost:%d%s", sc.Port, inspector.Prefix)
+	}
 	s := &http.Server{
-		Handler: i,
+		Handler: handler,
 		Addr:    fmt.Sprintf(":%d", sc.Port),
 	}
+	if reloader != nil {
+		s.Handler = withReloadStatus(handler, reloader)
+	}
 
-	var err error
 	if isTLS {
 		writeln(green, "Simulator server starts on 0.0.0.0:%d with TLS", sc.Port)
-		err = s.ListenAndServeTLS(sc.CertFile, sc.KeyFile)
+		return r.serve(s, func() error {
+			return s.ListenAndServeTLS(sc.CertFile, sc.KeyFile)
+		})
+	}
+	writeln(green, "Simulator server starts on 0.0.0.0:%d", sc.Port)
+	return r.serve(s, s.ListenAndServe)
+}
+
+// mirrorHandler wraps the simulator handler to mirror sampled requests to the staging service
+// and reports response differences between them
+func (r *Runner) mirrorHandler(next http.Handler) (http.Handler, error) {
+	mc := r.config.Mirror
+	handler, err := mirror.New(
+		next,
+		mc.Target,
+		func(req *http.Request, differences []*mirror.Difference) {
+			writeln(
+				yellow, "Difference found on %s %s (request id: %s)",
+				req.Method, req.URL.RequestURI(), req.Header.Get(ihttp.RequestIDHeader),
+			)
+			for _, d := range differences {
+				writeln(white, "  %s", d.String())
+			}
+		},
+		mirror.WithPercentage(mc.Percentage),
+		mirror.WithTimeout(time.Duration(mc.Timeout)*time.Second),
+		mirror.WithIgnoreHeaders(mc.IgnoreHeaders),
+	)
+	if err != nil {
+		return nil, err
+	}
+	writeln(cyan, "Mirror %d%% of requests to %s", mc.Percentage, mc.Target)
+	return handler, nil
+}
+
+// serve runs the server until the runner context is cancelled and then shuts it down gracefully.
+// If health check is enabled, readiness turns to unready and requests are still served for the drain delay,
+// then in-flight requests are waited up to the shutdown timeout and cancelled via their request context after that
+func (r *Runner) serve(s *http.Server, listen func() error) error {
+	sc := r.config.Simulator
+	var health *healthHandler
+	if sc.HealthCheck {
+		health = newHealthHandler(s.Handler)
+		s.Handler = health
+	}
+
+	base, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s.BaseContext = func(net.Listener) context.Context {
+		return base
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- listen()
+	}()
+	if health != nil {
+		health.ready.Store(true)
+	}
+
+	select {
+	case err := <-errCh:
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return errors.WithStack(err)
+		}
+		return nil
+	case <-r.ctx.Done():
+		if health != nil {
+			health.ready.Store(false)
+		}
+		if sc.DrainDelay > 0 {
+			slog.Info("Draining simulator server", "delay", sc.DrainDelay)
+			time.Sleep(time.Duration(sc.DrainDelay) * time.Second)
+		}
+		c, cancelShutdown := context.WithTimeout(context.Background(), time.Duration(sc.ShutdownTimeout)*time.Second)
+		defer cancelShutdown()
+		if err := s.Shutdown(c); err != nil {
+			// Cancel remaining in-flight requests
+			cancel()
+			return errors.WithStack(err)
+		}
+		return nil
+	}
+}
+
+// Shadow runs simulator server which processes every incoming request through both A and B programs
+// and reports divergences of the outcome between them
+func (r *Runner) Shadow(a, b resolver.Resolver) error {
+	defer r.closePolicy()
+
+	sc := r.config.Simulator
+	// Compare actual response of both programs
+	sc.IsProxyResponse = true
+
+	var simulators []shadow.Simulator
+	for _, rslv := range []resolver.Resolver{a, b} {
+		options, err := r.simulatorOptions(rslv, false)
+		if err != nil {
+			return err
+		}
+		simulators = append(simulators, interpreter.New(options...))
+	}
+
+	handler := shadow.New(
+		simulators[0],
+		simulators[1],
+		func(req *http.Request, divergences []*shadow.Divergence) {
+			writeln(yellow, "Divergence found on %s %s", req.Method, req.URL.String())
+			for _, d := range divergences {
+				writeln(white, "  %s", d.String())
+			}
+		},
+		shadow.WithIgnoreHeaders(r.config.Shadow.IgnoreHeaders),
+	)
+
+	s := &http.Server{
+		Handler: handler,
+		Addr:    fmt.Sprintf(":%d", sc.Port),
+	}
+	writeln(green, "Shadow simulator server starts on 0.0.0.0:%d", sc.Port)
+	return r.serve(s, s.ListenAndServe)
+}
+
+// Replay runs edge log lines through the VCL and aggregates divergences from the logged outcomes
+func (r *Runner) Replay(rslv resolver.Resolver, logFile string) (*replay.Report, error) {
+	defer r.closePolicy()
+
+	format, err := replay.ParseFormat(r.config.Replay.Format)
+	if err != nil {
+		return nil, err
+	}
+	fp, err := os.Open(logFile)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer fp.Close()
+
+	// Logged status is the actual response status so compare with it
+	r.config.Simulator.IsProxyResponse = true
+	options, err := r.simulatorOptions(rslv, false)
+	if err != nil {
+		return nil, err
+	}
+
+	// Measure coverage of replayed requests in order to compare with test coverage
+	var cv *shared.Coverage
+	if r.config.Replay.CoverageOut != "" {
+		cv = shared.NewCoverage()
+		function.Inject(tf.CoverageFunctions(cv))
+		options = append(options, icontext.WithCoverage(cv))
+	}
+
+	i := interpreter.New(options...)
+	report, err := replay.New(i, format, replay.WithSamples(r.config.Replay.Samples)).Replay(fp)
+	if err != nil {
+		return nil, err
+	}
+	if cv != nil {
+		if err := writeCoverageProfile(cv.Factory(), r.config.Replay.CoverageOut, "json"); err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+
+	// Replay the same logs through the proposed VCL with the fresh cache to compare projected cache efficiency
+	if r.config.Replay.Compare != "" {
+		proposed, err := resolver.NewFileResolvers(r.config.Replay.Compare, r.config.IncludePaths)
+		if err != nil {
+			return nil, err
+		}
+		options, err := r.simulatorOptions(proposed[0], false)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := fp.Seek(0, io.SeekStart); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		pr, err := replay.New(interpreter.New(options...), format).Replay(fp)
+		if err != nil {
+			return nil, err
+		}
+		report.ProposedCache = pr.Cache
+	}
+	return report, nil
+}
+
+func (r *Runner) watchResources(store *resource.Store) {
+	err := store.Watch(r.ctx, func(file string, err error) {
+		if err != nil {
+			writeln(red, "Failed to reload resource file %s: %s", file, err)
+			return
+		}
+		writeln(cyan, "Resource file %s is reloaded", file)
+	})
+	if err != nil {
+		writeln(red, "Failed to watch resource files: %s", err)
+	}
+}
+
+// Path of the endpoint which responds VCL reload status on watch mode
+const reloadStatusPath = "/_falco/reload"
+
+func withReloadStatus(next http.Handler, reloader *reload.Reloader) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == reloadStatusPath {
+			reloader.ServeHTTP(w, req)
+			return
+		}
+		next.ServeHTTP(w, req)
+	})
+}
+
+func (r *Runner) watchProgram(reloader *reload.Reloader) {
+	err := reloader.Watch(r.ctx, func(err error) {
+		if err != nil {
+			writeln(red, "Failed to reload VCL, previous program is still used:\n%s", err)
+			return
+		}
+		writeln(cyan, "VCL is reloaded")
+	})
+	if err != nil {
+		writeln(red, "Failed to watch VCL files: %s", err)
+	}
+}
+
+// validateProgram parses and lints the program on reloading.
+// Returns parse errors and lint errors which have error severity after applying rule overrides
+func (r *Runner) validateProgram(rslv resolver.Resolver) []string {
+	main, err := rslv.MainVCL()
+	if err != nil {
+		return []string{err.Error()}
+	}
+	vcl, err := parser.New(
+		lexer.NewFromString(main.Data, lexer.WithFile(main.Name)),
+		parser.WithContext(r.ctx),
+	).ParseVCL()
+	if err != nil {
+		return []string{err.Error()}
+	}
+
+	options := []lcontext.Option{lcontext.WithResolver(rslv)}
+	if r.snippets != nil {
+		options = append(options, lcontext.WithSnippets(r.snippets))
+	}
+	lt := linter.New(r.config.Linter, linter.WithProtectedHeaders(r.protectedHeaders()))
+	lt.Lint(vcl, lcontext.New(options...))
+	if lt.FatalError != nil {
+		return []string{lt.FatalError.Error.Error()}
+	}
+
+	var problems []string
+	for _, le := range lt.Errors {
+		if r.severity(le) == linter.ERROR {
+			problems = append(problems, le.Error())
+		}
+	}
+	return problems
+}
+
+// Test runs all test files, or only provided test files if specified
+func (r *Runner) Test(rslv resolver.Resolver, files ...string) (*tester.TestFactory, error) {
+	tc := r.config.Testing
+	if err := r.validateUnimplemented(); err != nil {
+		return nil, err
+	}
+	if tc.RecordTrace != "" {
+		if err := os.MkdirAll(tc.RecordTrace, 0o755); err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+	if tc.ReproDir != "" {
+		if err := os.MkdirAll(tc.ReproDir, 0o755); err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+
+	t := tester.New(tc, r.testOptions(rslv))
+	if r.onTestResult != nil {
+		t.OnResult(r.onTestResult)
+	}
+	if tc.Chaos {
+		if _, err := time.ParseDuration(tc.ChaosClockSkew); err != nil {
+			return nil, fmt.Errorf("invalid chaos clock skew %q: %w", tc.ChaosClockSkew, err)
+		}
+		// Seed is shown in order to reproduce perturbations of failed invariants by --chaos-seed option
+		r.message(white, "Chaos testing with seed %d\n", t.ChaosSeed())
+	}
+
+	r.message(white, "Running tests...")
+	var factory *tester.TestFactory
+	var err error
+	if len(files) > 0 {
+		factory, err = t.RunFiles(r.config.Commands.At(1), files)
 	} else {
-		writeln(green, "Simulator server starts on 0.0.0.0:%d", sc.Port)
-		err = s.ListenAndServe()
+		factory, err = t.Run(r.config.Commands.At(1))
 	}
 	if err != nil {
-		return errors.WithStack(err)
+		writeln(red, " Failed.")
+		writeln(red, "Failed to run test: %s", err.Error())
+		return nil, err
+	}
+	r.message(white, " Done.\n")
+	return factory, nil
+}
+
+// TestDependencies returns include graph of the main VCL and test files in order to find affected test files on watch mode
+func (r *Runner) TestDependencies(rslv resolver.Resolver) (*tester.Dependencies, error) {
+	return tester.New(r.config.Testing, r.testOptions(rslv)).Dependencies(r.config.Commands.At(1))
+}
+
+// TestUI runs tests in the interactive dashboard and returns true if all tests are passed when it is closed
+func (r *Runner) TestUI(rslv resolver.Resolver) (bool, error) {
+	tc := r.config.Testing
+	if err := r.validateUnimplemented(); err != nil {
+		return false, err
+	}
+	return dashboard.New(tester.New(tc, r.testOptions(rslv)), r.config.Commands.At(1)).Run()
+}
+
+func (r *Runner) validateUnimplemented() error {
+	if u := r.config.Unimplemented; u != nil {
+		switch u.Mode {
+		case "", config.UnimplementedError, config.UnimplementedWarn, config.UnimplementedStub:
+		default:
+			return fmt.Errorf("unsupported unimplemented policy %s, must be error, warn or stub", u.Mode)
+		}
 	}
 	return nil
 }
 
-func (r *Runner) Test(rslv resolver.Resolver) (*tester.TestFactory, error) {
+// Repro re-executes the failed test which is recorded in reproduction file
+func (r *Runner) Repro(rslv resolver.Resolver, rp *repro.Repro) (*tester.TestCase, error) {
+	return tester.New(r.config.Testing, r.testOptions(rslv)).Reproduce(rp)
+}
+
+func (r *Runner) testOptions(rslv resolver.Resolver) []icontext.Option {
 	tc := r.config.Testing
 	options := []icontext.Option{
 		icontext.WithResolver(rslv),
 		icontext.WithMaxBackends(r.config.OverrideMaxBackends),
 		icontext.WithMaxAcls(r.config.OverrideMaxAcls),
+		icontext.WithStrictRTime(r.config.StrictRTime),
+		icontext.WithStrictHeaders(r.config.StrictHeaders),
+		icontext.WithExternalHeaders(r.config.ExternalHeaders),
+		icontext.WithUnimplemented(r.config.Unimplemented),
 	}
 	if r.snippets != nil {
 		options = append(options, icontext.WithSnippets(r.snippets))
@@ -514,17 +1150,7 @@ func (r *Runner) Test(rslv resolver.Resolver) (*tester.TestFactory, error) {
 			overrides[key] = val
 		}
 	}
-	options = append(options, icontext.WithOverrideVariables(overrides))
-
-	r.message(white, "Running tests...")
-	factory, err := tester.New(tc, options).Run(r.config.Commands.At(1))
-	if err != nil {
-		writeln(red, " Failed.")
-		writeln(red, "Failed to run test: %s", err.Error())
-		return nil, err
-	}
-	r.message(white, " Done.\n")
-	return factory, nil
+	return append(options, icontext.WithOverrideVariables(overrides))
 }
 
 func (r *Runner) parseOverrideVariables(v string) (string, any, bool) {
@@ -577,3 +1203,229 @@ func (r *Runner) Format(rslv resolver.Resolver) error {
 	}
 	return nil
 }
+
+// Expand removes falco extension constant declarations and replaces references with the literal values
+// in order to upload VCLs to Fastly. Constants are shared between all provided files
+func (r *Runner) Expand(rslvs []resolver.Resolver) error {
+	names := make([]string, len(rslvs))
+	vcls := make([]*ast.VCL, len(rslvs))
+	for i, rslv := range rslvs {
+		main, err := rslv.MainVCL()
+		if err != nil {
+			return err
+		}
+		names[i] = main.Name
+		if vcls[i], err = r.parseVCL(main.Name, main.Data); err != nil {
+			return err
+		}
+	}
+
+	constants, err := constant.Collect(vcls...)
+	if err != nil {
+		return err
+	}
+
+	outDir := r.config.Expand.OutDir
+	if outDir != "" {
+		if err := os.MkdirAll(outDir, 0o755); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	for i, vcl := range vcls {
+		constants.Expand(vcl)
+		expanded := formatter.New(r.config.Format).Format(vcl)
+		if outDir == "" {
+			if _, err := io.Copy(os.Stdout, expanded); err != nil {
+				return err
+			}
+			continue
+		}
+
+		path := filepath.Join(outDir, filepath.Base(names[i]))
+		fp, err := os.Create(path)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		_, err = io.Copy(fp, expanded)
+		fp.Close()
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		writeln(cyan, "Expanded %s to %s.", names[i], path)
+	}
+	return nil
+}
+
+// Rewrite applies the migration codemod to VCLs.
+// Unified diff is printed to stdout on dry-run so that it could be reviewed or applied with patch command
+func (r *Runner) Rewrite(rslvs []resolver.Resolver, m *migration.Migration) error {
+	var files, changes int
+	for _, rslv := range rslvs {
+		main, err := rslv.MainVCL()
+		if err != nil {
+			return err
+		}
+		rewritten, applied := m.Apply(main.Data)
+		if len(applied) == 0 {
+			continue
+		}
+		files++
+		changes += len(applied)
+
+		if !r.config.Rewrite.Overwrite {
+			if _, err := io.WriteString(os.Stdout, migration.Diff(main.Name, main.Data, rewritten)); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := os.WriteFile(main.Name, []byte(rewritten), 0o644); err != nil {
+			return errors.WithStack(err)
+		}
+		writeln(cyan, "Rewrote %d identifiers in %s.", len(applied), main.Name)
+	}
+
+	if !r.config.Rewrite.Overwrite && changes > 0 {
+		writeln(yellow, "%d identifiers in %d files would be rewritten by %s migration, run with -w to apply.", changes, files, m.Name)
+		return nil
+	}
+	writeln(green, "%d identifiers in %d files are rewritten by %s migration.", changes, files, m.Name)
+	return nil
+}
+
+// Inventory collects usages of Fastly builtin functions and variables across all VCLs
+// Symbols updates the symbol index with the files, unchanged files are not parsed again
+func (r *Runner) Symbols(idx *symbol.Index, rslvs []resolver.Resolver) error {
+	for _, rslv := range rslvs {
+		main, err := rslv.MainVCL()
+		if err != nil {
+			return err
+		}
+		hash := symbol.Hash(main.Data)
+		if idx.IsIndexed(main.Name, hash) {
+			continue
+		}
+		vcl, err := r.parseVCL(main.Name, main.Data)
+		if err != nil {
+			return err
+		}
+		idx.Add(main.Name, hash, vcl)
+	}
+	return nil
+}
+
+func (r *Runner) Inventory(rslvs []resolver.Resolver) ([]*inventory.Usage, error) {
+	inv := inventory.New()
+	for _, rslv := range rslvs {
+		main, err := rslv.MainVCL()
+		if err != nil {
+			return nil, err
+		}
+		vcl, err := r.parseVCL(main.Name, main.Data)
+		if err != nil {
+			return nil, err
+		}
+		inv.Collect(vcl)
+	}
+	return inv.Usages(), nil
+}
+
+// Monitor collects monitoring artifacts and suggests alerts across all VCLs
+func (r *Runner) Monitor(rslvs []resolver.Resolver) (*monitoring.Artifacts, error) {
+	c := monitoring.New()
+	for _, rslv := range rslvs {
+		main, err := rslv.MainVCL()
+		if err != nil {
+			return nil, err
+		}
+		vcl, err := r.parseVCL(main.Name, main.Data)
+		if err != nil {
+			return nil, err
+		}
+		c.Collect(vcl)
+	}
+	return c.Artifacts(), nil
+}
+
+// Layer parses the base VCL and tenant overlays in the layering configuration
+func (r *Runner) Layer() (*layer.Layer, error) {
+	lc := r.config.Layer
+	if lc.Base == "" {
+		return nil, fmt.Errorf("base VCL is not specified, use layer.base in configuration")
+	}
+	parse := func(file string) (*ast.VCL, error) {
+		buf, err := os.ReadFile(file)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		return r.parseVCL(file, string(buf))
+	}
+
+	base, err := parse(lc.Base)
+	if err != nil {
+		return nil, err
+	}
+	overlays := make(map[string]*ast.VCL)
+	for _, t := range lc.Tenants {
+		for _, file := range t.Files {
+			if _, ok := overlays[file]; ok {
+				continue
+			}
+			if overlays[file], err = parse(file); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return layer.New(lc, base, overlays)
+}
+
+// VerifyCanary samples simulated requests to the main VCL and confirms that split ratios of the canary match configured percentages.
+// The main VCL must call the generated canary subroutine, and each request is processed by the fresh interpreter with the different seed
+func (r *Runner) VerifyCanary(c *canary.Canary, rslv resolver.Resolver) (*canary.Result, error) {
+	defer r.closePolicy()
+
+	options, err := r.simulatorOptions(rslv, false)
+	if err != nil {
+		return nil, err
+	}
+	cc := r.config.Canary
+	return canary.Verify(c, cc.Samples, cc.Tolerance, time.Now().UnixNano(), func(seed int64, req *http.Request) (string, error) {
+		i := interpreter.New(append(slices.Clone(options), icontext.WithSeed(seed))...)
+		plan, err := i.DryRun(req)
+		if err != nil {
+			return "", err
+		}
+		return plan.Backend, nil
+	})
+}
+
+// VerifyContract verifies that all VCLs rely only on the backend response headers which are guaranteed in the contract
+func (r *Runner) VerifyContract(c *contract.Contract, rslvs []resolver.Resolver) ([]*contract.Violation, error) {
+	vcls := make([]*ast.VCL, len(rslvs))
+	for i, rslv := range rslvs {
+		main, err := rslv.MainVCL()
+		if err != nil {
+			return nil, err
+		}
+		if vcls[i], err = r.parseVCL(main.Name, main.Data); err != nil {
+			return nil, err
+		}
+	}
+	return c.Verify(vcls...), nil
+}
+
+// FiddleExport packages the main VCL, its includes and request definitions into fiddle
+func (r *Runner) FiddleExport(rslv resolver.Resolver, requests []*fiddle.Request) (*fiddle.Fiddle, error) {
+	exporter := fiddle.NewExporter(func(v *resolver.VCL) (*ast.VCL, error) {
+		return r.parseVCL(v.Name, v.Data)
+	}, r.config.Format)
+
+	f, err := exporter.Export(rslv, requests)
+	if err != nil {
+		if errors.Cause(err) == ErrParser {
+			return nil, ErrParser
+		}
+		return nil, err
+	}
+	f.Title = r.config.Fiddle.Title
+	return f, nil
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -109,7 +110,7 @@ func TestResolveExternalWithExternalProperties(t *testing.T) {
 				VerboseWarning: true,
 			},
 		}
-		ret, err := NewRunner(c, f).Run(rslv[0])
+		ret, err := NewRunner(context.Background(), c, f).Run(rslv[0])
 		if err != nil {
 			t.Fatalf("Unexpected Run() error: %s", err)
 		}
@@ -133,7 +134,7 @@ func TestResolveExternalWithNoExternalProperties(t *testing.T) {
 			VerboseWarning: true,
 		},
 	}
-	ret, err := NewRunner(c, f).Run(rslv[0])
+	ret, err := NewRunner(context.Background(), c, f).Run(rslv[0])
 	if err != nil {
 		t.Fatalf("Unexpected Run() error: %s", err)
 	}
@@ -156,7 +157,7 @@ func TestResolveWithDuplicateDeclarations(t *testing.T) {
 			VerboseWarning: true,
 		},
 	}
-	ret, err := NewRunner(c, f).Run(rslv[0])
+	ret, err := NewRunner(context.Background(), c, f).Run(rslv[0])
 	if err != nil {
 		t.Fatalf("Unexpected Run() error: %s", err)
 	}
@@ -175,7 +176,7 @@ func TestResolveModulesWithVCLExtension(t *testing.T) {
 		},
 	}
 
-	ret, err := NewRunner(c, f).Run(rslv[0])
+	ret, err := NewRunner(context.Background(), c, f).Run(rslv[0])
 	if err != nil {
 		t.Fatalf("Unexpected Run() error: %s", err)
 	}
@@ -194,7 +195,7 @@ func TestResolveModulesWithoutVCLExtension(t *testing.T) {
 		},
 	}
 
-	ret, err := NewRunner(c, f).Run(rslv[0])
+	ret, err := NewRunner(context.Background(), c, f).Run(rslv[0])
 	if err != nil {
 		t.Fatalf("Unexpected Run() error: %s", err)
 	}
@@ -217,7 +218,7 @@ func TestTesterWithTerraform(t *testing.T) {
 		},
 	}
 
-	res, err := NewRunner(c, f).Test(rslv[0])
+	res, err := NewRunner(context.Background(), c, f).Test(rslv[0])
 	if err != nil {
 		t.Fatalf("Unexpected Run() error: %s", err)
 	}
@@ -235,7 +236,7 @@ func TestResolveModulesWithAclEntriesAndDictionaryItems(t *testing.T) {
 		},
 	}
 
-	ret, err := NewRunner(c, f).Run(rslv[0])
+	ret, err := NewRunner(context.Background(), c, f).Run(rslv[0])
 	if err != nil {
 		t.Fatalf("Unexpected Run() error: %s", err)
 	}
@@ -265,7 +266,7 @@ func TestRepositoryExamples(t *testing.T) {
 				return
 			}
 
-			ret, err := NewRunner(c, nil).Run(resolvers[0])
+			ret, err := NewRunner(context.Background(), c, nil).Run(resolvers[0])
 			if err != nil {
 				if !tt.runError {
 					t.Errorf("Unexpected runner error: %s", err)
@@ -304,7 +305,7 @@ func TestRepositoryExamplesJSONMode(t *testing.T) {
 				t.Errorf("Unexpected runner creation error: %s", err)
 				return
 			}
-			ret, err := NewRunner(c, nil).Run(resolvers[0])
+			ret, err := NewRunner(context.Background(), c, nil).Run(resolvers[0])
 			if tt.errors != 0 {
 				if err != nil {
 					t.Errorf("Unexpected error running Run(): %s", err)
@@ -393,7 +394,7 @@ func TestTester(t *testing.T) {
 			name:   "overriding variables test",
 			main:   "../../examples/testing/override_variables/override_variables.vcl",
 			filter: "*override_variables.test.vcl",
-			passes: 6,
+			passes: 12,
 		},
 		{
 			name:   "base64 functional test",
@@ -449,6 +450,24 @@ func TestTester(t *testing.T) {
 			filter: "*request_byte_reads.test.vcl",
 			passes: 2,
 		},
+		{
+			name:   "before and after hooks test",
+			main:   "../../examples/testing/hooks/hooks.vcl",
+			filter: "*hooks.test.vcl",
+			passes: 4,
+		},
+		{
+			name:   "subtest test",
+			main:   "../../examples/testing/subtest/subtest.vcl",
+			filter: "*subtest.test.vcl",
+			passes: 2,
+		},
+		{
+			name:   "call subroutine with arguments",
+			main:   "../../examples/testing/call_subroutine_args/call_subroutine_args.vcl",
+			filter: "*call_subroutine_args.test.vcl",
+			passes: 11,
+		},
 	}
 
 	for _, tt := range tests {
@@ -481,7 +500,7 @@ func TestTester(t *testing.T) {
 				t.Errorf("Unexpected runner creation error: %s", err)
 				return
 			}
-			ret, err := NewRunner(c, nil).Test(resolvers[0])
+			ret, err := NewRunner(context.Background(), c, nil).Test(resolvers[0])
 			if err != nil {
 				t.Errorf("Unexpected runner creation error: %s", err)
 				return
@@ -518,7 +537,7 @@ func TestFastlyGeneratedVCLLinting(t *testing.T) {
 		t.Errorf("Unexpected runner creation error: %s", err)
 		return
 	}
-	ret, err := NewRunner(c, nil).Run(resolvers[0])
+	ret, err := NewRunner(context.Background(), c, nil).Run(resolvers[0])
 	if err != nil {
 		t.Errorf("Unexpected linting error: %s", err)
 		return
@@ -584,7 +603,7 @@ func TestInjectDictionaryTesting(t *testing.T) {
 				t.Errorf("Unexpected runner creation error: %s", err)
 				return
 			}
-			ret, err := NewRunner(c, nil).Test(resolvers[0])
+			ret, err := NewRunner(context.Background(), c, nil).Test(resolvers[0])
 			if err != nil {
 				t.Errorf("Unexpected runner creation error: %s", err)
 			}
@@ -655,7 +674,7 @@ func TestSkippingTests(t *testing.T) {
 				t.Errorf("Unexpected runner creation error: %s", err)
 				return
 			}
-			ret, err := NewRunner(c, nil).Test(resolvers[0])
+			ret, err := NewRunner(context.Background(), c, nil).Test(resolvers[0])
 			if err != nil {
 				t.Errorf("Unexpected runner creation error: %s", err)
 			}
@@ -842,3 +861,59 @@ sub vcl_error {
 		})
 	}
 }
+
+func TestLintCache(t *testing.T) {
+	dir := t.TempDir()
+	main := filepath.Join(dir, "main.vcl")
+	buf, err := os.ReadFile("../../examples/linter/default03.vcl")
+	if err != nil {
+		t.Fatalf("Unexpected error reading example: %s", err)
+	}
+	if err := os.WriteFile(main, buf, 0o644); err != nil {
+		t.Fatalf("Unexpected error writing main VCL: %s", err)
+	}
+	c := &config.Config{
+		Linter: &config.LinterConfig{
+			CacheDir: filepath.Join(dir, "cache"),
+		},
+	}
+
+	run := func() *RunnerResult {
+		resolvers, err := resolver.NewFileResolvers(main, c.IncludePaths)
+		if err != nil {
+			t.Fatalf("Unexpected resolver creation error: %s", err)
+		}
+		runner := NewRunner(context.Background(), c, nil)
+		runner.enableLintCache()
+		ret, err := runner.Run(resolvers[0])
+		if err != nil {
+			t.Fatalf("Unexpected Run() error: %s", err)
+		}
+		return ret
+	}
+	entries := func() int {
+		files, _ := filepath.Glob(filepath.Join(dir, "cache", "*.json")) // nolint:errcheck
+		return len(files)
+	}
+
+	// Second run uses the cached result which has the same lint errors
+	for range 2 {
+		if ret := run(); ret.Infos != 2 {
+			t.Errorf("Infos expects 2, got %d", ret.Infos)
+		}
+		if n := entries(); n != 1 {
+			t.Errorf("Cache entries expects 1, got %d", n)
+		}
+	}
+
+	// Changing the main VCL creates new cache entry
+	if err := os.WriteFile(main, append(buf, []byte("\n# changed\n")...), 0o644); err != nil {
+		t.Fatalf("Unexpected error writing main VCL: %s", err)
+	}
+	if ret := run(); ret.Infos != 2 {
+		t.Errorf("Infos expects 2, got %d", ret.Infos)
+	}
+	if n := entries(); n != 2 {
+		t.Errorf("Cache entries expects 2, got %d", n)
+	}
+}
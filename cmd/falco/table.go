@@ -9,6 +9,8 @@ import (
 
 	"github.com/olekukonko/tablewriter"
 	"github.com/pkg/errors"
+	"github.com/ysugimoto/falco/v2/config"
+	"github.com/ysugimoto/falco/v2/coverage"
 	"github.com/ysugimoto/falco/v2/tester/shared"
 )
 
@@ -90,9 +92,163 @@ func printCoverageTable(c *shared.CoverageFactory) error {
 		w.Rich(coverageTable[i].rowData())
 	}
 	w.Render()
+
+	// Excluded and dead regions are listed separately because they are not counted in the table
+	cwd, err := os.Getwd()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	for _, v := range []struct {
+		title   string
+		regions []*shared.CoverageExclusion
+	}{
+		{"Excluded from coverage:", c.Exclusions},
+		{"Dead statements which can never be executed:", c.Dead},
+	} {
+		if len(v.regions) == 0 {
+			continue
+		}
+		writeln(white, v.title)
+		for _, e := range v.regions {
+			region := *e
+			if strings.EqualFold(filepath.Ext(region.File), ".vcl") {
+				if rel, err := filepath.Rel(cwd, region.File); err == nil {
+					region.File = rel
+				}
+			}
+			writeln(white, "  %s", region.String())
+		}
+	}
+
+	// MC/DC is reported only when there are compound conditions
+	if len(c.Decisions) > 0 {
+		printConditionCoverage(c.Decisions, sum.Conditions)
+	}
 	return nil
 }
 
+// Print MC/DC and conditions which are not shown to independently affect the decision outcome
+func printConditionCoverage(decisions []*shared.Decision, report *shared.CoverageReportItem) {
+	writeln(white, "Condition coverage (MC/DC): %s%% (%d/%d conditions)", printScore(report.Percent), report.Executed, report.Total)
+	if report.Executed == report.Total {
+		return
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		cwd = ""
+	}
+	writeln(white, "Conditions which are not shown to independently affect the outcome:")
+	for _, d := range decisions {
+		file := d.File
+		if rel, err := filepath.Rel(cwd, file); err == nil && filepath.IsAbs(file) {
+			file = rel
+		}
+		for i, covered := range d.Independent() {
+			if !covered {
+				writeln(white, "  %s:%d %s", file, d.Line, d.Conditions[i])
+			}
+		}
+	}
+}
+
+// Print coverage of each subroutine with uncovered lines, the worst subroutine comes first
+func printSubroutineCoverageTable(c *shared.CoverageFactory) {
+	type subroutineRow struct {
+		name      string
+		file      string
+		line      int
+		report    *shared.CoverageReport
+		uncovered []int
+	}
+
+	cwd, _ := os.Getwd() // nolint:errcheck
+	var rows []subroutineRow
+	for _, sub := range c.SplitSubroutines() {
+		file := sub.File
+		if rel, err := filepath.Rel(cwd, file); err == nil && strings.EqualFold(filepath.Ext(file), ".vcl") {
+			file = rel
+		}
+		name := sub.Name
+		if name == "" {
+			name = fmt.Sprintf("subroutine at line %d", sub.Line)
+		}
+		rows = append(rows, subroutineRow{
+			name:      name,
+			file:      file,
+			line:      sub.Line,
+			report:    sub.Report(),
+			uncovered: sub.UncoveredLines(),
+		})
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		a, b := rows[i].report, rows[j].report
+		if a.Statements.Percent != b.Statements.Percent {
+			return a.Statements.Percent < b.Statements.Percent
+		}
+		if a.Branches.Percent != b.Branches.Percent {
+			return a.Branches.Percent < b.Branches.Percent
+		}
+		if rows[i].file != rows[j].file {
+			return rows[i].file < rows[j].file
+		}
+		return rows[i].line < rows[j].line
+	})
+
+	w := tablewriter.NewWriter(os.Stdout)
+	w.SetAutoFormatHeaders(false)
+	w.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
+	w.SetHeader([]string{"Subroutine", "File", "% Stmts", "% Branch", "Uncovered Lines"})
+	w.SetColumnAlignment([]int{
+		tablewriter.ALIGN_DEFAULT,
+		tablewriter.ALIGN_DEFAULT,
+		tablewriter.ALIGN_RIGHT,
+		tablewriter.ALIGN_RIGHT,
+		tablewriter.ALIGN_DEFAULT,
+	})
+	for _, r := range rows {
+		// Subroutine which does not have branches is fully covered on branches
+		branches := r.report.Branches.Percent
+		if r.report.Branches.Total == 0 {
+			branches = 100
+		}
+		w.Rich(
+			[]string{
+				r.name,
+				r.file,
+				printScore(r.report.Statements.Percent),
+				printScore(branches),
+				formatLines(r.uncovered),
+			},
+			[]tablewriter.Colors{
+				{},
+				{},
+				getCellColor(r.report.Statements.Percent),
+				getCellColor(branches),
+				{},
+			},
+		)
+	}
+	w.Render()
+}
+
+// Format lines as ranges like "3-5, 9"
+func formatLines(lines []int) string {
+	var ranges []string
+	for i := 0; i < len(lines); i++ {
+		start := lines[i]
+		for i+1 < len(lines) && lines[i+1] == lines[i]+1 {
+			i++
+		}
+		if start == lines[i] {
+			ranges = append(ranges, fmt.Sprint(start))
+		} else {
+			ranges = append(ranges, fmt.Sprintf("%d-%d", start, lines[i]))
+		}
+	}
+	return strings.Join(ranges, ", ")
+}
+
 func transformCoverageTable(c *shared.CoverageFactory) ([]tableRow, error) {
 	fm, err := transformFileMap(c)
 	if err != nil {
@@ -107,7 +263,7 @@ func transformCoverageTable(c *shared.CoverageFactory) ([]tableRow, error) {
 			File:        file,
 			Statements:  report.Statements.Percent,
 			Branches:    report.Branches.Percent,
-			Subroutines: report.Statements.Percent,
+			Subroutines: report.Subroutines.Percent,
 		})
 	}
 
@@ -125,48 +281,140 @@ func transformFileMap(c *shared.CoverageFactory) (map[string]*shared.CoverageFac
 		return nil, errors.WithStack(err)
 	}
 
-	// Grouping by file
 	fileMap := make(map[string]*shared.CoverageFactory)
-	for id, tok := range c.NodeMap {
-		var file string
-		var err error
-
-		// If file extension is ".vcl", get fileative path
-		if strings.EqualFold(filepath.Ext(tok.File), ".vcl") {
-			file, err = filepath.Rel(cwd, tok.File)
-			if err != nil {
+	for file, factory := range c.Files() {
+		// If file extension is ".vcl", get relative path.
+		// Other cases like "snippet::xxx" - included snippets are used as it is
+		if strings.EqualFold(filepath.Ext(file), ".vcl") {
+			if file, err = filepath.Rel(cwd, file); err != nil {
 				return nil, errors.WithStack(err)
 			}
-		} else {
-			// Other cases like "snippet::xxx" - included snippets
-			file = tok.File
 		}
+		fileMap[file] = factory
+	}
 
-		if _, ok := fileMap[file]; !ok {
-			fileMap[file] = &shared.CoverageFactory{
-				Subroutines: make(shared.CoverageFactoryItem),
-				Statements:  make(shared.CoverageFactoryItem),
-				Branches:    make(shared.CoverageFactoryItem),
-			}
+	return fileMap, nil
+}
+
+// Compare coverage with the baseline profile and print statements and branches which become uncovered.
+// Returns false if changed lines have statements or branches which are not covered
+func checkCoverageBaseline(c *shared.CoverageFactory, conf *config.TestConfig) bool {
+	if conf.CoverageBaseline == "" {
+		return true
+	}
+	baseline, err := coverage.ReadFile(conf.CoverageBaseline)
+	if err != nil {
+		writeln(red, "Failed to read baseline coverage profile: %s", err)
+		return false
+	}
+	changed, err := gitChangedLines(conf.CoverageBaselineRef)
+	if err != nil {
+		writeln(red, err.Error())
+		return false
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		writeln(red, err.Error())
+		return false
+	}
+	current := coverage.FromFactory(c, cwd)
+	if baseline.Stable {
+		current = coverage.StableFromFactory(c, cwd)
+	}
+	r := coverage.Regress(baseline, current, changed)
+
+	for _, v := range []struct {
+		title  string
+		blocks []*coverage.Block
+	}{
+		{"Statements and branches which were covered in the baseline are no longer covered:", r.Uncovered},
+		{"Statements and branches on changed lines are not covered:", r.Changed},
+	} {
+		if len(v.blocks) == 0 {
+			continue
+		}
+		writeln(white, "")
+		writeln(yellow, v.title)
+		for _, b := range v.blocks {
+			writeln(white, "%s%s:%d %s", indent(1), b.File, b.Line, b.Type)
+		}
+	}
+	if r.Regressed() {
+		writeln(white, "")
+		writeln(red, "Coverage regressed on changed lines against the baseline %s", conf.CoverageBaseline)
+		return false
+	}
+	return true
+}
+
+// Check coverage thresholds and print files and subroutines which drag the coverage down.
+// Returns false if some of coverage types fall below the threshold
+func checkCoverageThreshold(c *shared.CoverageFactory, conf *config.TestConfig) bool {
+	shortfalls := c.Check(&shared.CoverageThreshold{
+		Subroutine: conf.CoverageThresholdSubroutine,
+		Statement:  conf.CoverageThresholdStatement,
+		Branch:     conf.CoverageThresholdBranch,
+	})
+	if len(shortfalls) == 0 {
+		return true
+	}
+
+	fm, err := transformFileMap(c)
+	if err != nil {
+		fm = c.Files()
+	}
+	subroutines := c.SplitSubroutines()
+	cwd, _ := os.Getwd() // nolint:errcheck
+
+	for _, s := range shortfalls {
+		writeln(white, "")
+		writeln(red, "Coverage (%s) %s%% is below the threshold %s%%", s.Type, printScore(s.Percent), printScore(s.Threshold))
+
+		// Files which fall below the threshold, the worst one comes first
+		type fileScore struct {
+			file   string
+			report *shared.CoverageReportItem
 		}
-		switch {
-		case strings.HasPrefix(id, "sub"):
-			if _, ok := fileMap[file].Subroutines[id]; !ok {
-				fileMap[file].Subroutines[id] = 0
+		var files []fileScore
+		for file, f := range fm {
+			report := f.Report().Item(s.Type)
+			if report.Total > 0 && report.Percent < s.Threshold {
+				files = append(files, fileScore{file: file, report: report})
 			}
-			fileMap[file].Subroutines[id] += c.Subroutines[id]
-		case strings.HasPrefix(id, "stmt"):
-			if _, ok := fileMap[file].Statements[id]; !ok {
-				fileMap[file].Statements[id] = 0
+		}
+		sort.Slice(files, func(i, j int) bool {
+			if files[i].report.Percent != files[j].report.Percent {
+				return files[i].report.Percent < files[j].report.Percent
 			}
-			fileMap[file].Statements[id] += c.Statements[id]
-		case strings.HasPrefix(id, "branch"):
-			if _, ok := fileMap[file].Branches[id]; !ok {
-				fileMap[file].Branches[id] = 0
+			return files[i].file < files[j].file
+		})
+
+		for _, f := range files {
+			writeln(yellow, "%s%s: %s%% (%d/%d)", indent(1), f.file, printScore(f.report.Percent), f.report.Executed, f.report.Total)
+			for _, sub := range subroutines {
+				file := sub.File
+				if rel, err := filepath.Rel(cwd, file); err == nil && strings.EqualFold(filepath.Ext(file), ".vcl") {
+					file = rel
+				}
+				if file != f.file {
+					continue
+				}
+				report := sub.Report().Item(s.Type)
+				missed := report.Total - report.Executed
+				switch {
+				case missed == 0:
+					continue
+				case s.Type == shared.CoverageTypeSubroutine:
+					writeln(white, "%ssubroutine at line %d is not executed", indent(2), sub.Line)
+				case s.Type == shared.CoverageTypeBranch:
+					writeln(white, "%ssubroutine at line %d: %d of %d branches are not executed",
+						indent(2), sub.Line, missed, report.Total)
+				default:
+					writeln(white, "%ssubroutine at line %d: %d of %d statements are not executed",
+						indent(2), sub.Line, missed, report.Total)
+				}
 			}
-			fileMap[file].Branches[id] += c.Branches[id]
 		}
 	}
-
-	return fileMap, nil
+	return false
 }
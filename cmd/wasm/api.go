@@ -172,11 +172,12 @@ func lint(_ js.Value, args []js.Value) any {
 
 	for _, le := range l.Errors {
 		lintErrors = append(lintErrors, LintError{
-			Severity: strings.ToLower(string(le.Severity)),
-			Message:  le.Message,
-			Line:     le.Token.Line,
-			Position: le.Token.Position,
-			Rule:     string(le.Rule),
+			Severity:    strings.ToLower(string(le.Severity)),
+			Message:     le.Message,
+			Line:        le.Token.Line,
+			Position:    le.Token.Position,
+			Rule:        string(le.Rule),
+			Suggestions: le.Suggestions,
 		})
 	}
 
@@ -25,11 +25,12 @@ type FormatResult struct {
 
 // LintError represents a single lint diagnostic.
 type LintError struct {
-	Severity string `json:"severity"`
-	Message  string `json:"message"`
-	Line     int    `json:"line"`
-	Position int    `json:"position"`
-	Rule     string `json:"rule,omitempty"`
+	Severity    string   `json:"severity"`
+	Message     string   `json:"message"`
+	Line        int      `json:"line"`
+	Position    int      `json:"position"`
+	Rule        string   `json:"rule,omitempty"`
+	Suggestions []string `json:"suggestions,omitempty"` // closest names of the unknown identifier for quick fixes
 }
 
 // LintResult is the response from lint().
@@ -14,13 +14,69 @@ func (c Commands) At(n int) string {
 }
 
 var needValueOptions = map[string]struct{}{
-	"-I":             {},
-	"--include_path": {},
-	"-t":             {},
-	"--transformer":  {},
-	"-f":             {},
-	"--filter":       {},
-	"--generated":    {},
+	"-I":                              {},
+	"--include_path":                  {},
+	"-t":                              {},
+	"--transformer":                   {},
+	"-f":                              {},
+	"--filter":                        {},
+	"--run":                           {},
+	"--only-tags":                     {},
+	"--skip-tags":                     {},
+	"-j":                              {},
+	"--jobs":                          {},
+	"--generated":                     {},
+	"--dialect":                       {},
+	"--policy":                        {},
+	"--unimplemented":                 {},
+	"--flow-diagram":                  {},
+	"--record-trace":                  {},
+	"--repro-dir":                     {},
+	"--report":                        {},
+	"-a":                              {},
+	"--a":                             {},
+	"-b":                              {},
+	"--b":                             {},
+	"--format":                        {},
+	"--samples":                       {},
+	"--coverage-out":                  {},
+	"--coverage-html":                 {},
+	"--coverage-format":               {},
+	"--coverage-baseline":             {},
+	"--coverage-baseline-ref":         {},
+	"--coverage-threshold-statement":  {},
+	"--coverage-threshold-branch":     {},
+	"--coverage-threshold-subroutine": {},
+	"--chaos-iterations":              {},
+	"--chaos-seed":                    {},
+	"--chaos-clock-skew":              {},
+	"--base":                          {},
+	"--threshold":                     {},
+	"--html":                          {},
+	"--out-dir":                       {},
+	"--index":                         {},
+	"--name":                          {},
+	"--contract":                      {},
+	"--compare":                       {},
+	"--experiment":                    {},
+	"--canary":                        {},
+	"--tolerance":                     {},
+	"--requests":                      {},
+	"--title":                         {},
+	"--migrate":                       {},
+	"--log-format":                    {},
+	"--log-level":                     {},
+	"--log-output":                    {},
+	"-p":                              {},
+	"--port":                          {},
+	"--output":                        {},
+	"--embed":                         {},
+	"--drain-delay":                   {},
+	"--shutdown-timeout":              {},
+	"--mirror":                        {},
+	"--mirror-percentage":             {},
+	"--grpc-host":                     {},
+	"--grpc-port":                     {},
 }
 
 func parseCommands(args []string) Commands {
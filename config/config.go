@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 
 	"github.com/pkg/errors"
 	"github.com/ysugimoto/twist"
@@ -21,14 +22,174 @@ const (
 	CommentStyleSharp = "sharp"
 )
 
+// Unimplemented builtin policy constants
+const (
+	UnimplementedError = "error"
+	UnimplementedWarn  = "warn"
+	UnimplementedStub  = "stub"
+)
+
 type OverrideBackend struct {
 	Host      string `yaml:"host"`
 	SSL       bool   `yaml:"ssl" default:"true"`
 	Unhealthy bool   `yaml:"unhealthy" default:"false"`
+	// Simulated latency until the first byte like "150ms", used for latency budget verification
+	Latency string `yaml:"latency"`
 }
 
 type EdgeDictionary map[string]string
 
+// External resource files which are watched and reloaded in simulator.
+// Each map key is the name of the table, ACL or edge dictionary and value is the JSON file path
+type ResourceFilesConfig struct {
+	Tables           map[string]string `yaml:"tables"`
+	Acls             map[string]string `yaml:"acls"`
+	EdgeDictionaries map[string]string `yaml:"edge_dictionaries"`
+}
+
+// KV storage which backs edge dictionaries in simulator.
+// Values are read on each request so that other services could mutate them while simulator is running
+type DictionaryStorageConfig struct {
+	Provider     string   `yaml:"provider"` // "redis" or "consul"
+	Address      string   `yaml:"address"`
+	Prefix       string   `yaml:"prefix"`
+	Token        string   `yaml:"token"`    // Redis password or Consul ACL token
+	Database     int      `yaml:"database"` // Redis database number
+	Dictionaries []string `yaml:"dictionaries"`
+}
+
+// Synthetic service configuration.
+// falco generates the VCL of the built-in behavior instead of reading main VCL file
+type SyntheticConfig struct {
+	Type        string                      `yaml:"type"` // "redirect" or "maintenance"
+	Redirect    *SyntheticRedirectConfig    `yaml:"redirect"`
+	Maintenance *SyntheticMaintenanceConfig `yaml:"maintenance"`
+}
+
+type SyntheticRedirectRule struct {
+	From string `yaml:"from"` // request path
+	To   string `yaml:"to"`   // redirect location
+}
+
+type SyntheticRedirectConfig struct {
+	Status        int                      `yaml:"status"`  // 301 as default
+	Default       string                   `yaml:"default"` // location for unmatched path, respond 404 if empty
+	PreserveQuery bool                     `yaml:"preserve_query"`
+	Rules         []*SyntheticRedirectRule `yaml:"rules"`
+}
+
+type SyntheticMaintenanceConfig struct {
+	Status      int      `yaml:"status"`       // 503 as default
+	ContentType string   `yaml:"content_type"` // text/html as default
+	Body        string   `yaml:"body"`
+	RetryAfter  int      `yaml:"retry_after"`
+	AllowIPs    []string `yaml:"allow_ips"` // clients which pass through to the origin
+	Origin      string   `yaml:"origin"`    // origin host (and port) for allowed clients
+}
+
+// Shadow traffic configuration
+type ShadowConfig struct {
+	A             string   `cli:"a"` // Enable only in CLI option
+	B             string   `cli:"b"` // Enable only in CLI option
+	IgnoreHeaders []string `yaml:"ignore_headers"`
+}
+
+// Mirror traffic configuration
+type MirrorConfig struct {
+	Target        string   `cli:"mirror" yaml:"target"` // URL of the staging service
+	Percentage    int      `cli:"mirror-percentage" yaml:"percentage" default:"100"`
+	Timeout       int      `yaml:"timeout" default:"10"` // Timeout seconds of the mirrored request
+	IgnoreHeaders []string `yaml:"ignore_headers"`
+}
+
+// Replay configuration
+type ReplayConfig struct {
+	Format      string `cli:"format" yaml:"format" default:"json"`
+	Samples     int    `cli:"samples" yaml:"samples" default:"10"`
+	CoverageOut string `cli:"coverage-out"` // Enable only in CLI option
+	Compare     string `cli:"compare"`      // Enable only in CLI option
+}
+
+// Coverage configuration
+type CoverageConfig struct {
+	Base      string `cli:"base"`                       // Enable only in CLI option
+	Threshold int    `cli:"threshold" yaml:"threshold"` // Minimum patch coverage percent
+
+	// Options for merging coverage profiles
+	Output string `cli:"output"`                // Enable only in CLI option
+	Format string `cli:"format" default:"json"` // Enable only in CLI option
+	HTML   string `cli:"html"`                  // Enable only in CLI option
+}
+
+// Constant expansion configuration
+type ExpandConfig struct {
+	OutDir string `cli:"out-dir"` // Enable only in CLI option
+}
+
+// Contract testing configuration
+type ContractConfig struct {
+	Files  []string `cli:"contract" yaml:"files"` // Contract files of guarantees and expectations
+	Output string   `cli:"output"`                // Enable only in CLI option
+}
+
+// A/B experiment configuration
+type ExperimentConfig struct {
+	Files  []string `cli:"experiment" yaml:"files"` // Experiment declaration files
+	Output string   `cli:"output"`                  // Enable only in CLI option
+}
+
+// Canary routing configuration
+type CanaryConfig struct {
+	Files     []string `cli:"canary" yaml:"files"`                    // Canary declaration files
+	Samples   int      `cli:"samples" yaml:"samples" default:"10000"` // Number of simulated requests on verification
+	Tolerance float64  `cli:"tolerance" yaml:"tolerance" default:"1"` // Allowed difference of split ratio in percentage points
+	Output    string   `cli:"output"`                                 // Enable only in CLI option
+}
+
+// Multi-tenant layering configuration
+type LayerConfig struct {
+	Base           string          `yaml:"base"`            // Base platform VCL file
+	Extensions     []string        `yaml:"extensions"`      // Subroutines of the base VCL which tenant overlays can extend
+	Protected      []string        `yaml:"protected"`       // Variables which tenant overlays must not modify, trailing * matches the prefix
+	AllowTerminate bool            `yaml:"allow_terminate"` // Allow return, restart and error statements in extensions
+	Tenants        []*TenantConfig `yaml:"tenants"`
+	Output         string          `cli:"output"` // Enable only in CLI option
+}
+
+type TenantConfig struct {
+	Name      string   `yaml:"name"`
+	Condition string   `yaml:"condition"` // VCL expression which matches requests of the tenant, extensions apply to all requests if empty
+	Priority  int      `yaml:"priority"`  // Lower priority is merged first
+	Files     []string `yaml:"files"`     // Overlay VCL files
+}
+
+// Symbol index configuration
+type SymbolsConfig struct {
+	Index string `cli:"index" yaml:"index" default:".falco-symbols.json"`
+	Name  string `cli:"name"` // Enable only in CLI option
+}
+
+// Codemod configuration
+type RewriteConfig struct {
+	Migrate   string `cli:"migrate"` // Enable only in CLI option
+	Overwrite bool   `cli:"w,write"` // Enable only in CLI option
+}
+
+// Fiddle import/export configuration
+type FiddleConfig struct {
+	Requests string `cli:"requests" yaml:"requests" default:"requests.json"` // Request definitions which are sent in fiddle
+	Title    string `cli:"title" yaml:"title"`
+	OutDir   string `cli:"out-dir"` // Enable only in CLI option
+}
+
+// Runtime policy for Fastly builtin functions and variables which are not implemented in the interpreter.
+// "error" fails the process, "warn" returns the zero value of the builtin type,
+// and "stub" returns the value which is provided in the stubs, keyed by function or variable name
+type UnimplementedConfig struct {
+	Mode  string         `cli:"unimplemented" yaml:"mode" default:"error"`
+	Stubs map[string]any `yaml:"stubs"`
+}
+
 // Linter configuration
 type LinterConfig struct {
 	VerboseLevel            string              `yaml:"verbose"`
@@ -37,14 +198,54 @@ type LinterConfig struct {
 	Rules                   map[string]string   `yaml:"rules"`
 	EnforceSubroutineScopes map[string][]string `yaml:"enforce_subroutine_scopes"`
 	IgnoreSubroutines       []string            `yaml:"ignore_subroutines"`
-	IsGenerated             bool                `cli:"generated"`
+	IsGenerated             bool                `cli:"generated" yaml:"generated"`
+
+	// Integer and RTIME literals in subroutines above these thresholds must be declared as named constants.
+	// Zero value disables the check
+	MagicNumberThreshold   int64  `yaml:"magic_number_threshold"`
+	MagicDurationThreshold string `yaml:"magic_duration_threshold"`
+
+	// Maximum RTIME values of backend timeouts, and client-facing latency limit which
+	// worst-case latency of restart-based retries must be within. Empty value disables the check
+	MaxConnectTimeout      string `yaml:"max_connect_timeout"`
+	MaxFirstByteTimeout    string `yaml:"max_first_byte_timeout"`
+	MaxBetweenBytesTimeout string `yaml:"max_between_bytes_timeout"`
+	ClientTimeout          string `yaml:"client_timeout"`
+
+	// Deprecation timelines of Fastly builtins which extend or override falco's ones, keyed by function or variable name
+	Deprecations map[string]*Deprecation `yaml:"deprecations"`
+	// Report usages of deprecated builtins as ERROR after their sunset date
+	ErrorAfterSunset bool `yaml:"error_after_sunset"`
+
+	// Number of workers which resolve and parse included modules concurrently. Zero value means the number of CPUs.
+	// Only parsing is parallelized, linting runs serially in the include order
+	ParseWorkers int `cli:"j,jobs" yaml:"parse_workers"`
+
+	// Lint results are cached on the local disk, user cache directory is used if CacheDir is empty
+	NoCache  bool   `cli:"no-cache" yaml:"no_cache"`
+	CacheDir string `yaml:"cache_dir"`
+
+	// VCL dialect to parse and lint, "fastly" or "varnish". Empty value means "fastly"
+	Dialect string `cli:"dialect" yaml:"dialect"`
+}
+
+// Deprecation timeline of Fastly builtin function or variable
+type Deprecation struct {
+	Sunset      string `yaml:"sunset"` // YYYY-MM-DD, empty if not announced
+	Replacement string `yaml:"replacement"`
 }
 
 // Simulator configuration
 type SimulatorConfig struct {
 	Port            int      `cli:"p,port" yaml:"port" default:"3124"`
-	IsDebug         bool     `cli:"debug"` // Enable only in CLI option
-	IsProxyResponse bool     `cli:"proxy"` // Enable only in CLI option
+	IsDebug         bool     `cli:"debug"`        // Enable only in CLI option
+	IsProxyResponse bool     `cli:"proxy"`        // Enable only in CLI option
+	IsTrace         bool     `cli:"trace"`        // Enable only in CLI option
+	IsExplain       bool     `cli:"explain"`      // Enable only in CLI option
+	FlowDiagram     string   `cli:"flow-diagram"` // Enable only in CLI option
+	RecordTrace     string   `cli:"record-trace"` // Enable only in CLI option
+	Watch           bool     `cli:"w,watch"`      // Enable only in CLI option
+	UI              bool     `cli:"ui"`           // Enable only in CLI option
 	IncludePaths    []string // Copy from root field
 
 	// HTTPS related configuration. If both fields are specified, simulator will serve with HTTPS
@@ -54,6 +255,24 @@ type SimulatorConfig struct {
 	// Inject Edge Dictionary items
 	OverrideEdgeDictionaries map[string]EdgeDictionary `yaml:"edge_dictionary"`
 
+	// Container related configuration.
+	// Serve /healthz and /readyz endpoints, and on shutting down, keep serving requests while readyz reports unready
+	// for the drain delay seconds, then wait in-flight requests up to the shutdown timeout seconds
+	HealthCheck     bool `cli:"health-check" yaml:"health_check"`
+	DrainDelay      int  `cli:"drain-delay" yaml:"drain_delay"`
+	ShutdownTimeout int  `cli:"shutdown-timeout" yaml:"shutdown_timeout" default:"5"`
+
+	// Serve ResultService of gRPC which streams test results and lint findings on the port, disabled if zero.
+	// The service is bound to the loopback address unless another address is specified explicitly
+	GrpcPort int    `cli:"grpc-port" yaml:"grpc_port"`
+	GrpcHost string `cli:"grpc-host" yaml:"grpc_host" default:"127.0.0.1"`
+
+	// Load tables, ACLs and edge dictionaries from external files and reload them on change
+	ResourceFiles *ResourceFilesConfig `yaml:"resource_files"`
+
+	// Read edge dictionaries from KV storage like Redis or Consul
+	DictionaryStorage *DictionaryStorageConfig `yaml:"dictionary_storage"`
+
 	// Override Request configuration
 	OverrideRequest *RequestConfig
 
@@ -67,14 +286,43 @@ type SimulatorConfig struct {
 
 // Testing configuration
 type TestConfig struct {
-	Timeout      int      `cli:"timeout" yaml:"timeout"`
-	Filter       string   `cli:"f,filter" default:"*.test.vcl"`
-	Tags         []string `cli:"t,tag"`
-	IncludePaths []string // Copy from root field
-	OverrideHost string   `yaml:"host" cli:"host"`
-	Watch        bool     `cli:"w,watch"`      // Enable only in CLI option
-	Coverage     bool     `cli:"coverage"`     // Enable only in CLI option
-	CoverageOut  string   `cli:"coverage-out"` // Enable only in CLI option
+	Timeout         int      `cli:"timeout" yaml:"timeout"`
+	Filter          string   `cli:"f,filter" yaml:"filter" default:"*.test.vcl"`
+	Tags            []string `cli:"t,tag"`
+	Run             string   `cli:"run"`       // Enable only in CLI option
+	OnlyTags        []string `cli:"only-tags"` // Enable only in CLI option
+	SkipTags        []string `cli:"skip-tags"` // Enable only in CLI option
+	IncludePaths    []string // Copy from root field
+	OverrideHost    string   `yaml:"host" cli:"host"`
+	Watch           bool     `cli:"w,watch" yaml:"watch"`
+	UI              bool     `cli:"ui"`                             // Enable only in CLI option
+	Coverage        bool     `cli:"coverage"`                       // Enable only in CLI option
+	CoverageOut     string   `cli:"coverage-out"`                   // Enable only in CLI option
+	CoverageHTML    string   `cli:"coverage-html"`                  // Enable only in CLI option
+	CoverageFormat  string   `cli:"coverage-format" default:"json"` // Enable only in CLI option
+	CoverageSubs    bool     `cli:"coverage-subroutines"`           // Enable only in CLI option
+	RecordTrace     string   `cli:"record-trace"`                   // Enable only in CLI option
+	ReproDir        string   `cli:"repro-dir"`                      // Enable only in CLI option
+	UpdateSnapshots bool     `cli:"update-snapshots"`               // Enable only in CLI option
+	Report          string   `cli:"report"`                         // Enable only in CLI option
+	Format          string   `cli:"format"`                         // Enable only in CLI option
+	Parallel        int      `cli:"j,jobs" yaml:"parallel"`         // Number of test files which run concurrently
+
+	// Chaos testing runs invariant test cases repeatedly with randomly perturbed conditions.
+	// Random seed is generated when ChaosSeed is zero, and clock is skewed within ChaosClockSkew
+	Chaos           bool   `cli:"chaos"` // Enable only in CLI option
+	ChaosIterations int    `cli:"chaos-iterations" yaml:"chaos_iterations" default:"10"`
+	ChaosSeed       int    `cli:"chaos-seed"` // Enable only in CLI option
+	ChaosClockSkew  string `cli:"chaos-clock-skew" yaml:"chaos_clock_skew" default:"5m"`
+
+	// Baseline coverage profile and git reference to find changed lines, falco test fails when changed lines are not covered
+	CoverageBaseline    string `cli:"coverage-baseline"`     // Enable only in CLI option
+	CoverageBaselineRef string `cli:"coverage-baseline-ref"` // Enable only in CLI option
+
+	// Minimum coverage percents, falco test fails when the coverage falls below them. Zero value disables the check
+	CoverageThresholdStatement  float64 `cli:"coverage-threshold-statement" yaml:"coverage_threshold_statement"`
+	CoverageThresholdBranch     float64 `cli:"coverage-threshold-branch" yaml:"coverage_threshold_branch"`
+	CoverageThresholdSubroutine float64 `cli:"coverage-threshold-subroutine" yaml:"coverage_threshold_subroutine"`
 
 	// Override Request configuration
 	OverrideRequest *RequestConfig
@@ -87,6 +335,29 @@ type TestConfig struct {
 	YamlOverrideVariables map[string]any `yaml:"overrides"` // from .falco.yaml
 }
 
+// Policy configuration
+type PolicyConfig struct {
+	Files   []string `cli:"policy" yaml:"files"`
+	Query   string   `yaml:"query" default:"data.falco.deny"`
+	Command string   `yaml:"command" default:"opa"`
+
+	// Header name patterns (glob) which VCL must never expose to clients or logs, like X-Internal-Auth
+	ProtectedHeaders []string `yaml:"protected_headers"`
+}
+
+// Bundle configuration
+type BundleConfig struct {
+	Output string   `cli:"output" default:"falco-bundle"` // Enable only in CLI option
+	Embed  []string `cli:"embed" yaml:"embed"`            // Additional files or directories to bundle
+}
+
+// Logging configuration for falco's own operational logs
+type LoggingConfig struct {
+	Format string `cli:"log-format" yaml:"format" default:"text"` // "text" or "json"
+	Level  string `cli:"log-level" yaml:"level" default:"info"`
+	Output string `cli:"log-output" yaml:"output"` // "stderr", "stdout" or file path, stderr as default
+}
+
 // Console configuration
 type ConsoleConfig struct {
 	// Initial scope string, for example, recv, pass, fetch, etc...
@@ -144,6 +415,17 @@ type Config struct {
 	OverrideMaxBackends int `cli:"max_backends" yaml:"max_backends"`
 	OverrideMaxAcls     int `cli:"mac_acls" yaml:"max_acls"`
 
+	// Reject unit-less INTEGER or FLOAT assignment to RTIME variables on runtime
+	StrictRTime bool `cli:"strict-rtime" yaml:"strict_rtime"`
+
+	// Reject reading headers which are never set in VCL or testing fixtures on runtime, they are likely typos.
+	// Headers which are provided externally like client request headers are allowed by ExternalHeaders (glob pattern)
+	StrictHeaders   bool     `cli:"strict-headers" yaml:"strict_headers"`
+	ExternalHeaders []string `yaml:"external_headers"`
+
+	// Runtime policy for unimplemented builtin functions and variables
+	Unimplemented *UnimplementedConfig `yaml:"unimplemented"`
+
 	// Linter configuration
 	Linter *LinterConfig `yaml:"linter"`
 	// Simulator configuration
@@ -154,18 +436,72 @@ type Config struct {
 	Console *ConsoleConfig `yaml:"console"`
 	// Format configuration
 	Format *FormatConfig `yaml:"format"`
+	// Policy configuration
+	Policy *PolicyConfig `yaml:"policy"`
+	// Synthetic service configuration
+	Synthetic *SyntheticConfig `yaml:"synthetic"`
+	// Shadow traffic configuration
+	Shadow *ShadowConfig `yaml:"shadow"`
+	// Mirror traffic configuration
+	Mirror *MirrorConfig `yaml:"mirror"`
+	// Replay configuration
+	Replay *ReplayConfig `yaml:"replay"`
+	// Coverage configuration
+	Coverage *CoverageConfig `yaml:"coverage"`
+	// Constant expansion configuration
+	Expand *ExpandConfig `yaml:"expand"`
+	// Contract testing configuration
+	Contract *ContractConfig `yaml:"contract"`
+	// A/B experiment configuration
+	Experiment *ExperimentConfig `yaml:"experiment"`
+	// Canary routing configuration
+	Canary *CanaryConfig `yaml:"canary"`
+	// Multi-tenant layering configuration
+	Layer *LayerConfig `yaml:"layer"`
+	// Symbol index configuration
+	Symbols *SymbolsConfig `yaml:"symbols"`
+	// Fiddle import/export configuration
+	Fiddle *FiddleConfig `yaml:"fiddle"`
+	// Codemod configuration
+	Rewrite *RewriteConfig `yaml:"rewrite"`
+	// Logging configuration
+	Logging *LoggingConfig `yaml:"logging"`
+	// Bundle configuration
+	Bundle *BundleConfig `yaml:"bundle"`
+
+	// Path of the loaded configuration file, empty if not found
+	ConfigFile string
 }
 
 func New(args []string) (*Config, error) {
-	var options []twist.Option
-	if file, err := findConfigFile(); err != nil {
+	file, err := findConfigFile()
+	if err != nil {
 		return nil, errors.WithStack(err)
-	} else if file != "" {
+	}
+	return newConfig(args, file, "")
+}
+
+// NewWithFile creates configuration from the specified configuration file instead of finding it from the working directory.
+// Relative paths of VCLs and resource files in the file are resolved from the directory of the file
+// so that the configuration works regardless of the working directory, e.g. extracted from the bundled executable
+func NewWithFile(args []string, file string) (*Config, error) {
+	return newConfig(args, file, filepath.Dir(file))
+}
+
+func newConfig(args []string, file, base string) (*Config, error) {
+	var options []twist.Option
+	if file != "" {
+		// "config" subcommand validates or exports schema by itself even if the file is invalid
+		if parseCommands(args).At(0) != "config" {
+			if err := ValidateFile(file); err != nil {
+				return nil, err
+			}
+		}
 		options = append(options, twist.WithYaml(file))
 	}
 
-	// finally, cascade config file -> environment -> cli option order
-	options = append(options, twist.WithEnv(), twist.WithCli(args))
+	// cascade config file -> environment -> cli option order
+	options = append(options, twist.WithEnv())
 
 	c := &Config{
 		OverrideBackends: make(map[string]*OverrideBackend),
@@ -173,7 +509,19 @@ func New(args []string) (*Config, error) {
 	if err := twist.Mix(c, options...); err != nil {
 		return nil, errors.WithStack(err)
 	}
+	// Path fields do not have env tags so they are only from the configuration file at this point
+	if base != "" {
+		c.resolvePaths(base)
+	}
+	// twist supports only explicit env tags, so FALCO_* environment variables are cascaded separately before cli
+	if err := cascadeEnv(reflect.ValueOf(c), envPrefix); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if err := twist.Mix(c, twist.WithCli(args)); err != nil {
+		return nil, errors.WithStack(err)
+	}
 	c.Commands = parseCommands(args)
+	c.ConfigFile = file
 
 	// Merge verbose level
 	switch c.Linter.VerboseLevel {
@@ -205,11 +553,96 @@ func New(args []string) (*Config, error) {
 		}
 		// Fastly generated VCL no longer has boiler-plate marco due to extracted so ignore it
 		c.Linter.Rules["subroutine/boilerplate-macro"] = "IGNORE" // TODO: use linter rule constants instead of string hard-coded
+		// Fastly generated VCL has F_ prefixed backends and Fastly declared local variables
+		c.Linter.Rules["reserved/generated-name"] = "IGNORE"
 	}
 
 	return c, nil
 }
 
+// Resolve relative paths in the configuration file from the base directory.
+// Paths from environment variables and cli options are kept as relative from the working directory
+func (c *Config) resolvePaths(base string) {
+	resolve := func(p string) string {
+		if p == "" || filepath.IsAbs(p) {
+			return p
+		}
+		return filepath.Join(base, p)
+	}
+
+	for i := range c.IncludePaths {
+		c.IncludePaths[i] = resolve(c.IncludePaths[i])
+	}
+	if c.Simulator == nil {
+		return
+	}
+	c.Simulator.KeyFile = resolve(c.Simulator.KeyFile)
+	c.Simulator.CertFile = resolve(c.Simulator.CertFile)
+	if rf := c.Simulator.ResourceFiles; rf != nil {
+		for _, m := range []map[string]string{rf.Tables, rf.Acls, rf.EdgeDictionaries} {
+			for k, v := range m {
+				m[k] = resolve(v)
+			}
+		}
+	}
+}
+
+// Clone returns the deep copy of the configuration so that the copy could be modified
+// without affecting the original configuration which may be read concurrently
+func (c *Config) Clone() *Config {
+	return deepCopy(reflect.ValueOf(c)).Interface().(*Config)
+}
+
+// Copy value recursively. Unexported struct fields are copied shallowly
+func deepCopy(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v
+		}
+		p := reflect.New(v.Elem().Type())
+		p.Elem().Set(deepCopy(v.Elem()))
+		return p
+	case reflect.Struct:
+		s := reflect.New(v.Type()).Elem()
+		s.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if s.Field(i).CanSet() {
+				s.Field(i).Set(deepCopy(v.Field(i)))
+			}
+		}
+		return s
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		s := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			s.Index(i).Set(deepCopy(v.Index(i)))
+		}
+		return s
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		m := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			m.SetMapIndex(iter.Key(), deepCopy(iter.Value()))
+		}
+		return m
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		i := reflect.New(v.Type()).Elem()
+		i.Set(deepCopy(v.Elem()))
+		return i
+	default:
+		return v
+	}
+}
+
 func findConfigFile() (string, error) {
 	// find up configuration file
 	cwd, err := os.Getwd()
@@ -13,6 +13,8 @@ func TestParseCommand(t *testing.T) {
 		"-I",
 		".",
 		"-v",
+		"--log-level",
+		"debug",
 		"foo",
 	}
 	c := parseCommands(args)
@@ -22,6 +24,65 @@ func TestParseCommand(t *testing.T) {
 	}
 }
 
+func TestParseCommandValueOptions(t *testing.T) {
+	tests := []struct {
+		args   []string
+		expect Commands
+	}{
+		{
+			args:   []string{"simulate", "--grpc-host", "0.0.0.0", "--grpc-port", "3125", "default.vcl"},
+			expect: Commands{"simulate", "default.vcl"},
+		},
+		{
+			args:   []string{"simulate", "--unimplemented", "warn", "default.vcl"},
+			expect: Commands{"simulate", "default.vcl"},
+		},
+		{
+			args:   []string{"symbols", "--index", ".falco-symbols.json", "--name", "F_origin", "default.vcl"},
+			expect: Commands{"symbols", "default.vcl"},
+		},
+		{
+			args:   []string{"lint", "--dialect", "varnish", "default.vcl"},
+			expect: Commands{"lint", "default.vcl"},
+		},
+		{
+			args:   []string{"fiddle", "export", "--requests", "requests.json", "--title", "Redirect rules", "default.vcl"},
+			expect: Commands{"fiddle", "export", "default.vcl"},
+		},
+		{
+			args:   []string{"test", "--coverage-html", "coverage.html", "default.vcl"},
+			expect: Commands{"test", "default.vcl"},
+		},
+		{
+			args:   []string{"rewrite", "--migrate", "geoip", "default.vcl"},
+			expect: Commands{"rewrite", "default.vcl"},
+		},
+		{
+			args:   []string{"test", "--report", "report.xml", "default.vcl"},
+			expect: Commands{"test", "default.vcl"},
+		},
+		{
+			args:   []string{"test", "--jobs", "4", "default.vcl"},
+			expect: Commands{"test", "default.vcl"},
+		},
+		{
+			args:   []string{"lint", "-j", "4", "default.vcl"},
+			expect: Commands{"lint", "default.vcl"},
+		},
+		{
+			args:   []string{"test", "--run", "^test_recv", "--only-tags", "smoke", "--skip-tags", "slow", "default.vcl"},
+			expect: Commands{"test", "default.vcl"},
+		},
+	}
+
+	for _, tt := range tests {
+		c := parseCommands(tt.args)
+		if diff := cmp.Diff(c, tt.expect); diff != "" {
+			t.Errorf("Unmatched parsed commands of %v, diff=%s", tt.args, diff)
+		}
+	}
+}
+
 func TestConfigFromCLI(t *testing.T) {
 	args := []string{
 		"-I",
@@ -52,6 +113,9 @@ func TestConfigFromCLI(t *testing.T) {
 		Remote:   true,
 		Json:     true,
 		Commands: Commands{"lint"},
+		Unimplemented: &UnimplementedConfig{
+			Mode: "error",
+		},
 		Linter: &LinterConfig{
 			VerboseLevel:      "",
 			VerboseWarning:    true,
@@ -59,14 +123,21 @@ func TestConfigFromCLI(t *testing.T) {
 			IgnoreSubroutines: []string{"vcl_pipe"},
 		},
 		Simulator: &SimulatorConfig{
-			Port:            3124,
-			IncludePaths:    []string{"."},
-			OverrideRequest: &RequestConfig{},
+			Port:              3124,
+			ShutdownTimeout:   5,
+			GrpcHost:          "127.0.0.1",
+			IncludePaths:      []string{"."},
+			OverrideRequest:   &RequestConfig{},
+			ResourceFiles:     &ResourceFilesConfig{},
+			DictionaryStorage: &DictionaryStorageConfig{},
 		},
 		Testing: &TestConfig{
 			Filter:          "*.test.vcl",
 			IncludePaths:    []string{"."},
 			Tags:            []string{"foo", "bar"},
+			CoverageFormat:  "json",
+			ChaosIterations: 10,
+			ChaosClockSkew:  "5m",
 			OverrideRequest: &RequestConfig{},
 		},
 		Console: &ConsoleConfig{
@@ -89,6 +160,31 @@ func TestConfigFromCLI(t *testing.T) {
 			ShouldUseUnset:             false,
 			BreakCompoundConditions:    true,
 		},
+		Policy: &PolicyConfig{
+			Query:   "data.falco.deny",
+			Command: "opa",
+		},
+		Synthetic: &SyntheticConfig{
+			Redirect:    &SyntheticRedirectConfig{},
+			Maintenance: &SyntheticMaintenanceConfig{},
+		},
+		Shadow: &ShadowConfig{},
+		Replay: &ReplayConfig{
+			Format:  "json",
+			Samples: 10,
+		},
+		Coverage:         &CoverageConfig{Format: "json"},
+		Expand:           &ExpandConfig{},
+		Contract:         &ContractConfig{},
+		Experiment:       &ExperimentConfig{},
+		Canary:           &CanaryConfig{Samples: 10000, Tolerance: 1},
+		Layer:            &LayerConfig{},
+		Symbols:          &SymbolsConfig{Index: ".falco-symbols.json"},
+		Fiddle:           &FiddleConfig{Requests: "requests.json"},
+		Rewrite:          &RewriteConfig{},
+		Logging:          &LoggingConfig{Format: "text", Level: "info"},
+		Bundle:           &BundleConfig{Output: "falco-bundle"},
+		Mirror:           &MirrorConfig{Percentage: 100, Timeout: 10},
 		OverrideBackends: make(map[string]*OverrideBackend),
 	}
 
@@ -97,6 +193,47 @@ func TestConfigFromCLI(t *testing.T) {
 	}
 }
 
+func TestJobsOption(t *testing.T) {
+	for _, args := range [][]string{
+		{"test", "-j", "4", "default.vcl"},
+		{"lint", "--jobs", "4", "default.vcl"},
+	} {
+		c, err := New(args)
+		if err != nil {
+			t.Errorf("Failed to initialize config: %s", err)
+			continue
+		}
+		// Both linter and testing share the same option to specify the concurrency
+		if c.Testing.Parallel != 4 || c.Linter.ParseWorkers != 4 {
+			t.Errorf("Unmatched jobs of %v, testing=%d, linter=%d", args, c.Testing.Parallel, c.Linter.ParseWorkers)
+		}
+		if diff := cmp.Diff(c.Commands, Commands{args[0], "default.vcl"}); diff != "" {
+			t.Errorf("Unmatched parsed commands of %v, diff=%s", args, diff)
+		}
+	}
+}
+
+func TestTagOptions(t *testing.T) {
+	c, err := New([]string{"test", "-t", "prod", "--only-tags", "slow,network", "--skip-tags", "flaky", "default.vcl"})
+	if err != nil {
+		t.Errorf("Failed to initialize config: %s", err)
+		return
+	}
+	// Environment tag and filter tags are provided independently
+	if diff := cmp.Diff(c.Testing.Tags, []string{"prod"}); diff != "" {
+		t.Errorf("Unmatched Tags field, diff=%s", diff)
+	}
+	if diff := cmp.Diff(c.Testing.OnlyTags, []string{"slow,network"}); diff != "" {
+		t.Errorf("Unmatched OnlyTags field, diff=%s", diff)
+	}
+	if diff := cmp.Diff(c.Testing.SkipTags, []string{"flaky"}); diff != "" {
+		t.Errorf("Unmatched SkipTags field, diff=%s", diff)
+	}
+	if diff := cmp.Diff(c.Commands, Commands{"test", "default.vcl"}); diff != "" {
+		t.Errorf("Unmatched parsed commands, diff=%s", diff)
+	}
+}
+
 func TestConfigFromEnv(t *testing.T) {
 	os.Setenv("FASTLY_SERVICE_ID", "example_service_id")
 	os.Setenv("FASTLY_API_KEY", "example_api_key")
@@ -112,3 +249,103 @@ func TestConfigFromEnv(t *testing.T) {
 		t.Errorf("Unmatched FastlyApiKey field, expect=%s, got=%s", "example_api_key", c.FastlyApiKey)
 	}
 }
+
+func TestConfigFromFalcoEnv(t *testing.T) {
+	t.Setenv("FALCO_SIMULATOR_PORT", "8080")
+	t.Setenv("FALCO_SIMULATOR_HEALTH_CHECK", "true")
+	t.Setenv("FALCO_LOGGING_LEVEL", "debug")
+	t.Setenv("FALCO_LOGGING_FORMAT", "json")
+	t.Setenv("FALCO_POLICY_FILES", "a.rego, b.rego")
+
+	c, err := New([]string{"--log-format", "text"})
+	if err != nil {
+		t.Fatalf("Failed to initialize config: %s", err)
+	}
+	if c.Simulator.Port != 8080 {
+		t.Errorf("Unmatched Simulator.Port field, expect=%d, got=%d", 8080, c.Simulator.Port)
+	}
+	if !c.Simulator.HealthCheck {
+		t.Errorf("Simulator.HealthCheck field must be true")
+	}
+	if c.Logging.Level != "debug" {
+		t.Errorf("Unmatched Logging.Level field, expect=%s, got=%s", "debug", c.Logging.Level)
+	}
+	// CLI argument takes precedence over environment variable
+	if c.Logging.Format != "text" {
+		t.Errorf("Unmatched Logging.Format field, expect=%s, got=%s", "text", c.Logging.Format)
+	}
+	if diff := cmp.Diff([]string{"a.rego", "b.rego"}, c.Policy.Files); diff != "" {
+		t.Errorf("Unmatched Policy.Files field, diff=%s", diff)
+	}
+
+	t.Setenv("FALCO_SIMULATOR_PORT", "foo")
+	if _, err := New([]string{}); err == nil {
+		t.Errorf("Expected error for invalid integer but got nil")
+	}
+}
+
+func TestConfigWithFile(t *testing.T) {
+	dir := t.TempDir()
+	file := dir + "/.falco.yaml"
+	content := `
+include_paths:
+  - ./vcl
+simulator:
+  key_file: /etc/falco/key.pem
+  cert_file: ./cert.pem
+  resource_files:
+    tables:
+      example: ./data/table.json
+`
+	if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	c, err := NewWithFile([]string{"simulate", "--key", "./user.pem"}, file)
+	if err != nil {
+		t.Fatalf("Failed to initialize config: %s", err)
+	}
+	if c.ConfigFile != file {
+		t.Errorf("Unmatched ConfigFile field, expect=%s, got=%s", file, c.ConfigFile)
+	}
+	if diff := cmp.Diff([]string{dir + "/vcl"}, c.IncludePaths); diff != "" {
+		t.Errorf("Unmatched IncludePaths field, diff=%s", diff)
+	}
+	// CLI argument is kept as relative from the working directory
+	if c.Simulator.KeyFile != "./user.pem" {
+		t.Errorf("Unmatched Simulator.KeyFile field, expect=%s, got=%s", "./user.pem", c.Simulator.KeyFile)
+	}
+	if c.Simulator.CertFile != dir+"/cert.pem" {
+		t.Errorf("Unmatched Simulator.CertFile field, expect=%s, got=%s", dir+"/cert.pem", c.Simulator.CertFile)
+	}
+	if diff := cmp.Diff(dir+"/data/table.json", c.Simulator.ResourceFiles.Tables["example"]); diff != "" {
+		t.Errorf("Unmatched table resource file, diff=%s", diff)
+	}
+}
+
+func TestConfigClone(t *testing.T) {
+	c := &Config{
+		IncludePaths: []string{"./vcl"},
+		Testing: &TestConfig{
+			Coverage: true,
+			YamlOverrideVariables: map[string]any{
+				"client.geo.country_code": "JP",
+			},
+		},
+		Simulator: &SimulatorConfig{},
+	}
+	cloned := c.Clone()
+	if diff := cmp.Diff(c, cloned); diff != "" {
+		t.Errorf("Cloned config mismatch, diff=%s", diff)
+	}
+
+	cloned.IncludePaths[0] = "./other"
+	cloned.Testing.Coverage = false
+	cloned.Testing.YamlOverrideVariables["client.geo.country_code"] = "US"
+	if c.IncludePaths[0] != "./vcl" || !c.Testing.Coverage || c.Testing.YamlOverrideVariables["client.geo.country_code"] != "JP" {
+		t.Errorf("Original config must not be modified through cloned config: %+v", c.Testing)
+	}
+	if cloned.Linter != nil {
+		t.Errorf("Nil field must be kept as nil")
+	}
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Prefix of environment variables which override configuration values
+const envPrefix = "FALCO_"
+
+// Override configuration values from environment variables which correspond to the yaml key path,
+// for example simulator.port is overridden by FALCO_SIMULATOR_PORT.
+// String, boolean, integer and string slice (comma separated) fields are supported,
+// map fields like edge dictionaries could not be overridden
+func cascadeEnv(v reflect.Value, prefix string) error {
+	v = reflect.Indirect(v)
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag, ok := field.Tag.Lookup("yaml")
+		if !ok || tag == "" || tag == "-" {
+			continue
+		}
+		name := prefix + strings.ToUpper(strings.Split(tag, ",")[0])
+		value := v.Field(i)
+
+		if field.Type.Kind() == reflect.Ptr && field.Type.Elem().Kind() == reflect.Struct {
+			if value.IsNil() {
+				value.Set(reflect.New(field.Type.Elem()))
+			}
+			if err := cascadeEnv(value, name+"_"); err != nil {
+				return errors.WithStack(err)
+			}
+			continue
+		}
+
+		env, ok := os.LookupEnv(name)
+		if !ok {
+			continue
+		}
+		if err := assignEnv(value, env); err != nil {
+			return errors.Errorf("Invalid value of %s: %s", name, err)
+		}
+	}
+	return nil
+}
+
+func assignEnv(value reflect.Value, env string) error {
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(env)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(env)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		value.SetBool(b)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(env, 10, 64)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		value.SetInt(n)
+	case reflect.Slice:
+		if value.Type().Elem().Kind() != reflect.String {
+			return nil
+		}
+		var items []string
+		for _, item := range strings.Split(env, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		value.Set(reflect.ValueOf(items))
+	}
+	return nil
+}
@@ -0,0 +1,420 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/ysugimoto/falco/v2/suggest"
+	"gopkg.in/yaml.v3"
+)
+
+// Allowed values of string fields keyed by configuration path.
+// They are used for both validation and exported JSON Schema
+var schemaEnums = map[string][]string{
+	"linter.verbose":                        {"warning", "info"},
+	"linter.dialect":                        {"fastly", "varnish"},
+	"unimplemented.mode":                    {UnimplementedError, UnimplementedWarn, UnimplementedStub},
+	"format.indent_style":                   {IndentStyleSpace, IndentStyleTab},
+	"format.comment_style":                  {CommentStyleNone, CommentStyleSlash, CommentStyleSharp},
+	"logging.format":                        {"text", "json"},
+	"synthetic.type":                        {"redirect", "maintenance"},
+	"simulator.dictionary_storage.provider": {"redis", "consul"},
+}
+
+// SchemaError is the error of the configuration value at the position in the configuration file
+type SchemaError struct {
+	Line    int
+	Column  int
+	Path    string
+	Message string
+}
+
+func (e *SchemaError) Error() string {
+	return fmt.Sprintf("line %d, column %d: %s: %s", e.Line, e.Column, e.Path, e.Message)
+}
+
+// SchemaErrors holds all schema errors found in the configuration file
+type SchemaErrors struct {
+	File   string
+	Errors []*SchemaError
+}
+
+func (e *SchemaErrors) Error() string {
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "Invalid configuration file %s:", e.File)
+	for i := range e.Errors {
+		buf.WriteString("\n    " + e.Errors[i].Error())
+	}
+	return buf.String()
+}
+
+// ValidateFile validates the configuration file strictly against the Config struct.
+// Unknown keys, mismatched types, invalid enum values and conflicting options are reported with their positions
+func ValidateFile(file string) error {
+	buf, err := os.ReadFile(file)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(buf, &doc); err != nil {
+		return errors.Wrapf(err, "Failed to parse configuration file %s", file)
+	}
+	// Empty file is valid
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		return nil
+	}
+
+	v := &schemaValidator{}
+	v.validate(doc.Content[0], reflect.TypeOf(Config{}), "")
+	v.validateConflicts(doc.Content[0])
+	if len(v.errors) > 0 {
+		return &SchemaErrors{File: file, Errors: v.errors}
+	}
+	return nil
+}
+
+// Field of the struct which is exposed as the key of the configuration file
+type schemaField struct {
+	name  string
+	field reflect.StructField
+}
+
+// Only fields which have yaml tag are configurable in the file, others are CLI or environment only
+func schemaFields(t reflect.Type) []schemaField {
+	var fields []schemaField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		fields = append(fields, schemaField{name: name, field: f})
+	}
+	return fields
+}
+
+func joinSchemaPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+type schemaValidator struct {
+	errors []*SchemaError
+}
+
+func (v *schemaValidator) report(n *yaml.Node, path, format string, args ...any) {
+	v.errors = append(v.errors, &SchemaError{
+		Line:    n.Line,
+		Column:  n.Column,
+		Path:    path,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+func (v *schemaValidator) validate(n *yaml.Node, t reflect.Type, path string) {
+	if n.Kind == yaml.AliasNode && n.Alias != nil {
+		n = n.Alias
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	// Null value is decoded as zero value for any types
+	if n.Kind == yaml.ScalarNode && n.ShortTag() == "!!null" {
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if n.Kind != yaml.MappingNode {
+			v.report(n, path, "must be object but got %s", nodeType(n))
+			return
+		}
+		fields := schemaFields(t)
+		names := make([]string, len(fields))
+		for i := range fields {
+			names[i] = fields[i].name
+		}
+		seen := make(map[string]struct{})
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key, value := n.Content[i], n.Content[i+1]
+			p := joinSchemaPath(path, key.Value)
+			if _, ok := seen[key.Value]; ok {
+				v.report(key, p, "duplicated key")
+				continue
+			}
+			seen[key.Value] = struct{}{}
+
+			index := -1
+			for j := range names {
+				if names[j] == key.Value {
+					index = j
+					break
+				}
+			}
+			if index == -1 {
+				message := "unknown key"
+				if s := suggest.Message(suggest.Closest(key.Value, names)); s != "" {
+					message += ", " + s
+				}
+				v.report(key, p, "%s", message)
+				continue
+			}
+			v.validate(value, fields[index].field.Type, p)
+		}
+	case reflect.Map:
+		if n.Kind != yaml.MappingNode {
+			v.report(n, path, "must be object but got %s", nodeType(n))
+			return
+		}
+		seen := make(map[string]struct{})
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key, value := n.Content[i], n.Content[i+1]
+			p := joinSchemaPath(path, key.Value)
+			if _, ok := seen[key.Value]; ok {
+				v.report(key, p, "duplicated key")
+				continue
+			}
+			seen[key.Value] = struct{}{}
+			v.validate(value, t.Elem(), p)
+		}
+	case reflect.Slice:
+		if n.Kind != yaml.SequenceNode {
+			v.report(n, path, "must be array but got %s", nodeType(n))
+			return
+		}
+		for i := range n.Content {
+			v.validate(n.Content[i], t.Elem(), fmt.Sprintf("%s[%d]", path, i))
+		}
+	case reflect.Interface:
+		// Any value is accepted
+	case reflect.String:
+		if n.Kind != yaml.ScalarNode {
+			v.report(n, path, "must be string but got %s", nodeType(n))
+			return
+		}
+		if enum, ok := schemaEnums[path]; ok && n.Value != "" && !contains(enum, n.Value) {
+			v.report(n, path, "invalid value %q, must be one of %s", n.Value, strings.Join(enum, ", "))
+		}
+	case reflect.Bool:
+		if n.Kind != yaml.ScalarNode || !isBoolNode(n) {
+			v.report(n, path, "must be boolean but got %s", nodeType(n))
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if n.Kind != yaml.ScalarNode || n.ShortTag() != "!!int" {
+			v.report(n, path, "must be integer but got %s", nodeType(n))
+		}
+	case reflect.Float32, reflect.Float64:
+		if n.Kind != yaml.ScalarNode || (n.ShortTag() != "!!int" && n.ShortTag() != "!!float") {
+			v.report(n, path, "must be number but got %s", nodeType(n))
+		}
+	}
+}
+
+// Options which conflict with each other, depend on other options or have limited range
+func (v *schemaValidator) validateConflicts(root *yaml.Node) {
+	keyFile, _ := lookupNode(root, "simulator", "key_file")
+	certFile, _ := lookupNode(root, "simulator", "cert_file")
+	if keyFile != nil && certFile == nil {
+		v.report(keyFile, "simulator.key_file", "simulator.cert_file must also be specified to serve with HTTPS")
+	} else if keyFile == nil && certFile != nil {
+		v.report(certFile, "simulator.cert_file", "simulator.key_file must also be specified to serve with HTTPS")
+	}
+
+	if key, value := lookupNode(root, "synthetic", "type"); key != nil && contains(schemaEnums["synthetic.type"], value.Value) {
+		if k, _ := lookupNode(root, "synthetic", value.Value); k == nil {
+			v.report(value, "synthetic.type", "synthetic.%s must be specified for %s type", value.Value, value.Value)
+		}
+	}
+
+	if _, noCache := lookupNode(root, "linter", "no_cache"); noCache != nil && noCache.Value == "true" {
+		if key, _ := lookupNode(root, "linter", "cache_dir"); key != nil {
+			v.report(key, "linter.cache_dir", "conflicts with linter.no_cache, lint result cache is disabled")
+		}
+	}
+
+	if key, value := lookupNode(root, "mirror", "percentage"); key != nil {
+		if p, err := strconv.Atoi(value.Value); err == nil && (p < 0 || p > 100) {
+			v.report(value, "mirror.percentage", "must be between 0 and 100 but got %d", p)
+		}
+	}
+
+	if key, value := lookupNode(root, "simulator", "dictionary_storage"); key != nil && value.Kind == yaml.MappingNode {
+		if k, _ := lookupNode(value, "provider"); k == nil {
+			v.report(key, "simulator.dictionary_storage", "provider must be specified")
+		}
+		if k, _ := lookupNode(value, "dictionaries"); k == nil {
+			v.report(key, "simulator.dictionary_storage", "dictionaries must be specified")
+		}
+	}
+}
+
+// Find the key and value node by the path of mapping keys. Null value is treated as not found
+func lookupNode(n *yaml.Node, keys ...string) (*yaml.Node, *yaml.Node) {
+	var key *yaml.Node
+	for _, k := range keys {
+		if n.Kind == yaml.AliasNode && n.Alias != nil {
+			n = n.Alias
+		}
+		if n.Kind != yaml.MappingNode {
+			return nil, nil
+		}
+		var found bool
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if n.Content[i].Value == k {
+				key, n = n.Content[i], n.Content[i+1]
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, nil
+		}
+	}
+	if n.Kind == yaml.ScalarNode && n.ShortTag() == "!!null" {
+		return nil, nil
+	}
+	return key, n
+}
+
+// Configuration file is loaded as YAML 1.1 so plain yes, no, on and off are also boolean
+func isBoolNode(n *yaml.Node) bool {
+	if n.ShortTag() == "!!bool" {
+		return true
+	}
+	if n.Style != 0 {
+		return false
+	}
+	switch strings.ToLower(n.Value) {
+	case "yes", "no", "on", "off", "y", "n":
+		return true
+	}
+	return false
+}
+
+// Type name of the node which is used in error messages
+func nodeType(n *yaml.Node) string {
+	switch n.Kind {
+	case yaml.MappingNode:
+		return "object"
+	case yaml.SequenceNode:
+		return "array"
+	}
+	switch n.ShortTag() {
+	case "!!bool":
+		return fmt.Sprintf("boolean %s", n.Value)
+	case "!!int":
+		return fmt.Sprintf("integer %s", n.Value)
+	case "!!float":
+		return fmt.Sprintf("number %s", n.Value)
+	default:
+		return fmt.Sprintf("string %q", n.Value)
+	}
+}
+
+func contains(values []string, v string) bool {
+	for i := range values {
+		if values[i] == v {
+			return true
+		}
+	}
+	return false
+}
+
+// JSONSchema returns JSON Schema (draft-07) of the configuration file.
+// Editors could provide completion and validation for .falco.yml with this schema
+func JSONSchema() ([]byte, error) {
+	schema := typeSchema(reflect.TypeOf(Config{}), "")
+	schema["$schema"] = "http://json-schema.org/draft-07/schema#"
+	schema["title"] = "falco configuration"
+	buf, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return buf, nil
+}
+
+func typeSchema(t reflect.Type, path string) map[string]any {
+	var nullable bool
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+		nullable = true
+	}
+
+	var schema map[string]any
+	switch t.Kind() {
+	case reflect.Struct:
+		properties := make(map[string]any)
+		for _, f := range schemaFields(t) {
+			p := typeSchema(f.field.Type, joinSchemaPath(path, f.name))
+			if d, ok := f.field.Tag.Lookup("default"); ok {
+				if v := defaultValue(f.field.Type, d); v != nil {
+					p["default"] = v
+				}
+			}
+			properties[f.name] = p
+		}
+		schema = map[string]any{
+			"type":                 "object",
+			"properties":           properties,
+			"additionalProperties": false,
+		}
+	case reflect.Map:
+		schema = map[string]any{
+			"type":                 "object",
+			"additionalProperties": typeSchema(t.Elem(), joinSchemaPath(path, "*")),
+		}
+	case reflect.Slice:
+		schema = map[string]any{
+			"type":  "array",
+			"items": typeSchema(t.Elem(), path),
+		}
+	case reflect.Interface:
+		return map[string]any{}
+	case reflect.String:
+		schema = map[string]any{"type": "string"}
+		if enum, ok := schemaEnums[path]; ok {
+			schema["enum"] = enum
+		}
+	case reflect.Bool:
+		schema = map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		schema = map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		schema = map[string]any{"type": "number"}
+	default:
+		return map[string]any{}
+	}
+
+	if nullable {
+		schema["type"] = []any{schema["type"], "null"}
+	}
+	return schema
+}
+
+// Convert default tag value to the typed value for JSON Schema
+func defaultValue(t reflect.Type, v string) any {
+	switch t.Kind() {
+	case reflect.String:
+		return v
+	case reflect.Bool:
+		if b, err := strconv.ParseBool(v); err == nil {
+			return b
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if i, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return i
+		}
+	case reflect.Float32, reflect.Float64:
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return f
+		}
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestValidateFile(t *testing.T) {
+	tests := []struct {
+		name   string
+		yaml   string
+		expect []*SchemaError
+	}{
+		{
+			name: "valid configuration",
+			yaml: `
+include_paths:
+  - ./vcl
+linter:
+  verbose: warning
+  generated: yes
+  rules:
+    subroutine/boilerplate-macro: IGNORE
+simulator:
+  port: 3124
+  key_file: ./key.pem
+  cert_file: ./cert.pem
+  edge_dictionary:
+    feature_flags:
+      beta: "on"
+testing:
+  filter: "*.test.vcl"
+  coverage_threshold_statement: 80
+  overrides:
+    client.geo.country_code: JP
+mirror:
+  percentage: 10
+`,
+		},
+		{
+			name: "unknown key with suggestion",
+			yaml: `
+simulator:
+  prot: 3124
+`,
+			expect: []*SchemaError{
+				{Line: 3, Column: 3, Path: "simulator.prot", Message: `unknown key, did you mean "port"?`},
+			},
+		},
+		{
+			name: "type mismatch",
+			yaml: `
+simulator:
+  port: "3124"
+testing:
+  timeout: 1.5
+include_paths: ./vcl
+format:
+  explicit_string_concat: "true"
+`,
+			expect: []*SchemaError{
+				{Line: 3, Column: 9, Path: "simulator.port", Message: `must be integer but got string "3124"`},
+				{Line: 5, Column: 12, Path: "testing.timeout", Message: "must be integer but got number 1.5"},
+				{Line: 6, Column: 16, Path: "include_paths", Message: `must be array but got string "./vcl"`},
+				{Line: 8, Column: 27, Path: "format.explicit_string_concat", Message: `must be boolean but got string "true"`},
+			},
+		},
+		{
+			name: "invalid enum value",
+			yaml: `
+format:
+  indent_style: tabs
+`,
+			expect: []*SchemaError{
+				{Line: 3, Column: 17, Path: "format.indent_style", Message: `invalid value "tabs", must be one of space, tab`},
+			},
+		},
+		{
+			name: "duplicated key",
+			yaml: `
+linter:
+  verbose: info
+  verbose: warning
+`,
+			expect: []*SchemaError{
+				{Line: 4, Column: 3, Path: "linter.verbose", Message: "duplicated key"},
+			},
+		},
+		{
+			name: "conflicting options",
+			yaml: `
+simulator:
+  key_file: ./key.pem
+linter:
+  no_cache: true
+  cache_dir: ./cache
+synthetic:
+  type: redirect
+mirror:
+  percentage: 120
+`,
+			expect: []*SchemaError{
+				{Line: 3, Column: 3, Path: "simulator.key_file", Message: "simulator.cert_file must also be specified to serve with HTTPS"},
+				{Line: 8, Column: 9, Path: "synthetic.type", Message: "synthetic.redirect must be specified for redirect type"},
+				{Line: 6, Column: 3, Path: "linter.cache_dir", Message: "conflicts with linter.no_cache, lint result cache is disabled"},
+				{Line: 10, Column: 15, Path: "mirror.percentage", Message: "must be between 0 and 100 but got 120"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), ".falco.yml")
+			if err := os.WriteFile(file, []byte(tt.yaml), 0o644); err != nil {
+				t.Fatalf("Unexpected write error: %s", err)
+			}
+			err := ValidateFile(file)
+			if tt.expect == nil {
+				if err != nil {
+					t.Errorf("Unexpected error: %s", err)
+				}
+				return
+			}
+			var se *SchemaErrors
+			if !errors.As(err, &se) {
+				t.Fatalf("Expected SchemaErrors but got %v", err)
+			}
+			if diff := cmp.Diff(tt.expect, se.Errors); diff != "" {
+				t.Errorf("Schema errors mismatch, diff=%s", diff)
+			}
+		})
+	}
+}
+
+func TestJSONSchema(t *testing.T) {
+	buf, err := JSONSchema()
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	var schema struct {
+		AdditionalProperties bool `json:"additionalProperties"`
+		Properties           map[string]struct {
+			Properties map[string]map[string]any `json:"properties"`
+		} `json:"properties"`
+	}
+	if err := json.Unmarshal(buf, &schema); err != nil {
+		t.Fatalf("Unexpected unmarshal error: %s", err)
+	}
+	if schema.AdditionalProperties {
+		t.Errorf("Unknown root keys must not be allowed")
+	}
+	if _, ok := schema.Properties["help"]; ok {
+		t.Errorf("CLI only option must not be exported")
+	}
+
+	expects := map[string]map[string]any{
+		"port":         {"type": "integer", "default": float64(3124)},
+		"indent_style": {"type": "string", "default": "space", "enum": []any{"space", "tab"}},
+	}
+	actuals := map[string]map[string]any{
+		"port":         schema.Properties["simulator"].Properties["port"],
+		"indent_style": schema.Properties["format"].Properties["indent_style"],
+	}
+	if diff := cmp.Diff(expects, actuals); diff != "" {
+		t.Errorf("JSON Schema mismatch, diff=%s", diff)
+	}
+}
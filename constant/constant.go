@@ -0,0 +1,200 @@
+// Package constant expands falco extension "const" declarations.
+// Fastly does not recognize named constants, so VCLs which use them must be expanded before uploading:
+// declarations are removed and identifiers are replaced with the literal values
+package constant
+
+import (
+	"fmt"
+
+	"github.com/ysugimoto/falco/v2/ast"
+)
+
+// Constants is the collection of declared constants keyed by name
+type Constants map[string]*ast.ConstDeclaration
+
+// Collect collects constant declarations from root statements.
+// Multiple VCLs could be passed in order to share constants between main and included VCLs
+func Collect(vcls ...*ast.VCL) (Constants, error) {
+	c := make(Constants)
+	for _, vcl := range vcls {
+		for _, stmt := range vcl.Statements {
+			decl, ok := stmt.(*ast.ConstDeclaration)
+			if !ok {
+				continue
+			}
+			if _, ok := c[decl.Name.Value]; ok {
+				return nil, fmt.Errorf(
+					`duplicate definition of const "%s" at line %d, position %d`,
+					decl.Name.Value, decl.Name.Token.Line, decl.Name.Token.Position,
+				)
+			}
+			c[decl.Name.Value] = decl
+		}
+	}
+	return c, nil
+}
+
+// Expand removes constant declarations from VCL and replaces constant references with the literal values.
+// Note that VCL is modified in place
+func (c Constants) Expand(vcl *ast.VCL) {
+	var statements []ast.Statement
+	for _, stmt := range vcl.Statements {
+		switch t := stmt.(type) {
+		case *ast.ConstDeclaration:
+			continue
+		case *ast.SubroutineDeclaration:
+			c.expandBlock(t.Block)
+		case *ast.BackendDeclaration:
+			c.expandBackendProperties(t.Properties)
+		case *ast.DirectorDeclaration:
+			c.expandDirectorProperties(t.Properties)
+		}
+		statements = append(statements, stmt)
+	}
+	vcl.Statements = statements
+}
+
+func (c Constants) expandBackendProperties(props []*ast.BackendProperty) {
+	for _, prop := range props {
+		// Probe is the nested object which has its own properties
+		if probe, ok := prop.Value.(*ast.BackendProbeObject); ok {
+			c.expandBackendProperties(probe.Values)
+			continue
+		}
+		prop.Value = c.expandExpression(prop.Value)
+	}
+}
+
+func (c Constants) expandDirectorProperties(props []ast.Expression) {
+	for _, prop := range props {
+		switch t := prop.(type) {
+		case *ast.DirectorProperty:
+			t.Value = c.expandExpression(t.Value)
+		case *ast.DirectorBackendObject:
+			for _, v := range t.Values {
+				// Backend name is not a constant reference
+				if v.Key.Value == "backend" {
+					continue
+				}
+				v.Value = c.expandExpression(v.Value)
+			}
+		}
+	}
+}
+
+func (c Constants) expandBlock(block *ast.BlockStatement) {
+	if block == nil {
+		return
+	}
+	c.expandStatements(block.Statements)
+}
+
+func (c Constants) expandStatements(statements []ast.Statement) {
+	for _, stmt := range statements {
+		c.expandStatement(stmt)
+	}
+}
+
+func (c Constants) expandStatement(stmt ast.Statement) {
+	switch t := stmt.(type) {
+	case *ast.BlockStatement:
+		c.expandBlock(t)
+	case *ast.SetStatement:
+		t.Value = c.expandExpression(t.Value)
+	case *ast.AddStatement:
+		t.Value = c.expandExpression(t.Value)
+	case *ast.IfStatement:
+		c.expandIfStatement(t)
+	case *ast.SwitchStatement:
+		t.Control.Expression = c.expandExpression(t.Control.Expression)
+		for _, cs := range t.Cases {
+			c.expandStatements(cs.Statements)
+		}
+	case *ast.ErrorStatement:
+		t.Code = c.expandExpression(t.Code)
+		t.Argument = c.expandExpression(t.Argument)
+	case *ast.LogStatement:
+		t.Value = c.expandExpression(t.Value)
+	case *ast.SyntheticStatement:
+		t.Value = c.expandExpression(t.Value)
+	case *ast.SyntheticBase64Statement:
+		t.Value = c.expandExpression(t.Value)
+	case *ast.ReturnStatement:
+		t.ReturnExpression = c.expandExpression(t.ReturnExpression)
+	case *ast.CallStatement:
+		c.expandExpressions(t.Arguments)
+	case *ast.FunctionCallStatement:
+		c.expandExpressions(t.Arguments)
+	}
+}
+
+func (c Constants) expandIfStatement(stmt *ast.IfStatement) {
+	stmt.Condition = c.expandExpression(stmt.Condition)
+	c.expandBlock(stmt.Consequence)
+	for _, another := range stmt.Another {
+		c.expandIfStatement(another)
+	}
+	if stmt.Alternative != nil {
+		c.expandBlock(stmt.Alternative.Consequence)
+	}
+}
+
+func (c Constants) expandExpressions(expressions []ast.Expression) {
+	for i := range expressions {
+		expressions[i] = c.expandExpression(expressions[i])
+	}
+}
+
+func (c Constants) expandExpression(expr ast.Expression) ast.Expression {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		if decl, ok := c[t.Value]; ok {
+			return literal(decl.Value, t.Meta)
+		}
+	case *ast.PrefixExpression:
+		t.Right = c.expandExpression(t.Right)
+	case *ast.PostfixExpression:
+		t.Left = c.expandExpression(t.Left)
+	case *ast.GroupedExpression:
+		t.Right = c.expandExpression(t.Right)
+	case *ast.InfixExpression:
+		t.Left = c.expandExpression(t.Left)
+		t.Right = c.expandExpression(t.Right)
+	case *ast.IfExpression:
+		t.Condition = c.expandExpression(t.Condition)
+		t.Consequence = c.expandExpression(t.Consequence)
+		t.Alternative = c.expandExpression(t.Alternative)
+	case *ast.FunctionCallExpression:
+		c.expandExpressions(t.Arguments)
+	}
+	return expr
+}
+
+// Create literal node from constant value with the meta of the reference in order to keep comments.
+// Negative numbers are folded into the literal because Fastly does not accept parenthesized prefix expression
+func literal(value ast.Expression, m *ast.Meta) ast.Expression {
+	switch t := value.(type) {
+	case *ast.String:
+		v := *t
+		v.Meta = m
+		return &v
+	case *ast.Integer:
+		return &ast.Integer{Meta: m, Value: t.Value}
+	case *ast.Float:
+		return &ast.Float{Meta: m, Value: t.Value}
+	case *ast.RTime:
+		return &ast.RTime{Meta: m, Value: t.Value}
+	case *ast.Boolean:
+		return &ast.Boolean{Meta: m, Value: t.Value}
+	case *ast.PrefixExpression:
+		switch r := t.Right.(type) {
+		case *ast.Integer:
+			return &ast.Integer{Meta: m, Value: -r.Value}
+		case *ast.Float:
+			return &ast.Float{Meta: m, Value: -r.Value}
+		case *ast.RTime:
+			return &ast.RTime{Meta: m, Value: "-" + r.Value}
+		}
+	}
+	return value
+}
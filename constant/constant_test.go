@@ -0,0 +1,139 @@
+package constant
+
+import (
+	"testing"
+
+	"github.com/ysugimoto/falco/v2/ast"
+	"github.com/ysugimoto/falco/v2/lexer"
+	"github.com/ysugimoto/falco/v2/parser"
+)
+
+func parse(t *testing.T, input string) *ast.VCL {
+	vcl, err := parser.New(lexer.NewFromString(input)).ParseVCL()
+	if err != nil {
+		t.Fatalf("Unexpected parse error: %s", err)
+	}
+	return vcl
+}
+
+func TestExpand(t *testing.T) {
+	vcl := parse(t, `
+const TTL_LONG = 86400s;
+const HOST = "example.com";
+const OFFSET = -10;
+
+sub vcl_fetch {
+  if (req.http.Host == HOST) {
+    set beresp.ttl = TTL_LONG;
+  } else {
+    set beresp.ttl = std.atoi(req.http.TTL) + OFFSET;
+  }
+  # header name is kept
+  set beresp.http.TTL_LONG = HOST;
+}`)
+
+	c, err := Collect(vcl)
+	if err != nil {
+		t.Fatalf("Unexpected collect error: %s", err)
+	}
+	c.Expand(vcl)
+
+	if len(vcl.Statements) != 1 {
+		t.Fatalf("Constant declarations must be removed, got %d statements", len(vcl.Statements))
+	}
+	body := vcl.Statements[0].(*ast.SubroutineDeclaration).Block.Statements
+
+	ifStmt := body[0].(*ast.IfStatement)
+	if v, ok := ifStmt.Condition.(*ast.InfixExpression).Right.(*ast.String); !ok || v.Value != "example.com" {
+		t.Errorf("Condition is not expanded: %s", ifStmt.Condition)
+	}
+	set := ifStmt.Consequence.Statements[0].(*ast.SetStatement)
+	if v, ok := set.Value.(*ast.RTime); !ok || v.Value != "86400s" {
+		t.Errorf("Set value is not expanded: %s", set.Value)
+	}
+	set = ifStmt.Alternative.Consequence.Statements[0].(*ast.SetStatement)
+	if v, ok := set.Value.(*ast.InfixExpression).Right.(*ast.Integer); !ok || v.Value != -10 {
+		t.Errorf("Negative constant is not folded: %s", set.Value)
+	}
+
+	set = body[1].(*ast.SetStatement)
+	if set.Ident.Value != "beresp.http.TTL_LONG" {
+		t.Errorf("Assignment target must not be expanded: %s", set.Ident.Value)
+	}
+	if v, ok := set.Value.(*ast.String); !ok || v.Value != "example.com" {
+		t.Errorf("Set value is not expanded: %s", set.Value)
+	}
+	if len(set.Leading) != 1 {
+		t.Errorf("Comments must be kept")
+	}
+}
+
+func TestExpandDeclarationProperties(t *testing.T) {
+	vcl := parse(t, `
+const ORIGIN_HOST = "origin.example.com";
+const CONNECT_TIMEOUT = 1s;
+const PROBE_THRESHOLD = 3;
+const WEIGHT = 10;
+
+backend F_origin {
+  .host = ORIGIN_HOST;
+  .connect_timeout = CONNECT_TIMEOUT;
+  .probe = {
+    .threshold = PROBE_THRESHOLD;
+  }
+}
+
+director example random {
+  .quorum = 50%;
+  { .backend = F_origin; .weight = WEIGHT; }
+}`)
+
+	c, err := Collect(vcl)
+	if err != nil {
+		t.Fatalf("Unexpected collect error: %s", err)
+	}
+	c.Expand(vcl)
+
+	if len(vcl.Statements) != 2 {
+		t.Fatalf("Constant declarations must be removed, got %d statements", len(vcl.Statements))
+	}
+	backend := vcl.Statements[0].(*ast.BackendDeclaration)
+	if v, ok := backend.Properties[0].Value.(*ast.String); !ok || v.Value != "origin.example.com" {
+		t.Errorf("Backend property is not expanded: %s", backend.Properties[0].Value)
+	}
+	if v, ok := backend.Properties[1].Value.(*ast.RTime); !ok || v.Value != "1s" {
+		t.Errorf("Backend property is not expanded: %s", backend.Properties[1].Value)
+	}
+	probe := backend.Properties[2].Value.(*ast.BackendProbeObject)
+	if v, ok := probe.Values[0].Value.(*ast.Integer); !ok || v.Value != 3 {
+		t.Errorf("Probe property is not expanded: %s", probe.Values[0].Value)
+	}
+
+	director := vcl.Statements[1].(*ast.DirectorDeclaration)
+	object := director.Properties[1].(*ast.DirectorBackendObject)
+	if v, ok := object.Values[0].Value.(*ast.Ident); !ok || v.Value != "F_origin" {
+		t.Errorf("Backend name must not be expanded: %s", object.Values[0].Value)
+	}
+	if v, ok := object.Values[1].Value.(*ast.Integer); !ok || v.Value != 10 {
+		t.Errorf("Director backend property is not expanded: %s", object.Values[1].Value)
+	}
+}
+
+func TestCollectAcrossVCLs(t *testing.T) {
+	main := parse(t, `const TTL_LONG = 86400s;`)
+	module := parse(t, `sub vcl_fetch { set beresp.ttl = TTL_LONG; }`)
+
+	c, err := Collect(main, module)
+	if err != nil {
+		t.Fatalf("Unexpected collect error: %s", err)
+	}
+	c.Expand(module)
+	set := module.Statements[0].(*ast.SubroutineDeclaration).Block.Statements[0].(*ast.SetStatement)
+	if v, ok := set.Value.(*ast.RTime); !ok || v.Value != "86400s" {
+		t.Errorf("Set value is not expanded: %s", set.Value)
+	}
+
+	if _, err := Collect(main, parse(t, `const TTL_LONG = 60s;`)); err == nil {
+		t.Errorf("Expected duplicate error but got nil")
+	}
+}
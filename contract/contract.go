@@ -0,0 +1,312 @@
+// Package contract verifies the VCL against contracts between the edge and origin teams.
+// Origin teams declare guarantees of backend responses, edge teams declare expectations,
+// and the VCL must rely only on the guaranteed response headers
+package contract
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-yaml/yaml"
+	"github.com/pkg/errors"
+	"github.com/ysugimoto/falco/v2/ast"
+)
+
+const responseHeaderPrefix = "beresp.http."
+
+// Guarantee is declared by the origin team, the backend always responds
+// with the status in the ranges and always contains the headers.
+// Header value is used as the example value of the generated stub
+type Guarantee struct {
+	Status  []string          `yaml:"status"`
+	Headers map[string]string `yaml:"headers"`
+}
+
+// Expectation is declared by the edge team, the VCL expects the status ranges and headers of the backend response
+type Expectation struct {
+	Status  []string `yaml:"status"`
+	Headers []string `yaml:"headers"`
+}
+
+type Backend struct {
+	Guarantees   *Guarantee   `yaml:"guarantees"`
+	Expectations *Expectation `yaml:"expectations"`
+}
+
+// Contract is the set of backend contracts keyed by the backend name
+type Contract struct {
+	Backends map[string]*Backend `yaml:"backends"`
+}
+
+// Violation is the expectation or header read in the VCL which is not guaranteed.
+// Location is present only for the header read in the VCL
+type Violation struct {
+	Backend  string `json:"backend,omitempty"`
+	Message  string `json:"message"`
+	File     string `json:"file,omitempty"`
+	Line     int    `json:"line,omitempty"`
+	Position int    `json:"position,omitempty"`
+}
+
+func (v *Violation) String() string {
+	var prefix string
+	if v.Line > 0 {
+		prefix = fmt.Sprintf("%s:%d:%d: ", v.File, v.Line, v.Position)
+	}
+	if v.Backend != "" {
+		prefix += fmt.Sprintf("backend %s: ", v.Backend)
+	}
+	return prefix + v.Message
+}
+
+// Load loads contract files and merges them into the single contract.
+// Guarantees and expectations are usually owned by different teams so they could be declared in separate files,
+// but each of them must be declared once per backend
+func Load(files ...string) (*Contract, error) {
+	c := &Contract{
+		Backends: make(map[string]*Backend),
+	}
+	for _, file := range files {
+		buf, err := os.ReadFile(file)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		var loaded Contract
+		if err := yaml.Unmarshal(buf, &loaded); err != nil {
+			return nil, errors.Wrapf(err, "failed to parse contract file %s", file)
+		}
+		for name, b := range loaded.Backends {
+			if err := c.merge(name, b); err != nil {
+				return nil, errors.Wrapf(err, "invalid contract file %s", file)
+			}
+		}
+	}
+	return c, nil
+}
+
+func (c *Contract) merge(name string, b *Backend) error {
+	if b == nil {
+		return nil
+	}
+	if b.Guarantees != nil {
+		if _, err := parseRanges(b.Guarantees.Status); err != nil {
+			return errors.Wrapf(err, "backend %s guarantees", name)
+		}
+	}
+	if b.Expectations != nil {
+		if _, err := parseRanges(b.Expectations.Status); err != nil {
+			return errors.Wrapf(err, "backend %s expectations", name)
+		}
+	}
+
+	merged, ok := c.Backends[name]
+	if !ok {
+		c.Backends[name] = b
+		return nil
+	}
+	if b.Guarantees != nil {
+		if merged.Guarantees != nil {
+			return errors.Errorf("guarantees of backend %s are declared multiple times", name)
+		}
+		merged.Guarantees = b.Guarantees
+	}
+	if b.Expectations != nil {
+		if merged.Expectations != nil {
+			return errors.Errorf("expectations of backend %s are declared multiple times", name)
+		}
+		merged.Expectations = b.Expectations
+	}
+	return nil
+}
+
+// Backend names in sorted order
+func (c *Contract) names() []string {
+	names := make([]string, 0, len(c.Backends))
+	for name := range c.Backends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Verify checks that expectations are satisfied by guarantees, and that response headers
+// which are read in the VCLs are expected for any backend.
+// Presence checks like `if (beresp.http.X)` do not rely on the header so they are not reported
+func (c *Contract) Verify(vcls ...*ast.VCL) []*Violation {
+	var violations []*Violation
+	expected := make(map[string]struct{})
+
+	for _, name := range c.names() {
+		b := c.Backends[name]
+		if b.Expectations == nil {
+			continue
+		}
+		for _, h := range b.Expectations.Headers {
+			expected[strings.ToLower(h)] = struct{}{}
+		}
+		if b.Guarantees == nil {
+			violations = append(violations, &Violation{
+				Backend: name,
+				Message: "expectations are declared but the backend has no guarantees",
+			})
+			continue
+		}
+		violations = append(violations, b.verify(name)...)
+	}
+
+	for _, vcl := range vcls {
+		for _, read := range collectReads(vcl) {
+			name := strings.TrimPrefix(read.Value, responseHeaderPrefix)
+			// Subfield access like beresp.http.Cache-Control:max-age relies on the header itself
+			if idx := strings.Index(name, ":"); idx != -1 {
+				name = name[:idx]
+			}
+			if _, ok := expected[strings.ToLower(name)]; ok {
+				continue
+			}
+			violations = append(violations, &Violation{
+				Message:  fmt.Sprintf("response header %s is read but not expected for any backend", name),
+				File:     read.Token.File,
+				Line:     read.Token.Line,
+				Position: read.Token.Position,
+			})
+		}
+	}
+	return violations
+}
+
+func (b *Backend) verify(name string) []*Violation {
+	var violations []*Violation
+
+	guaranteed := make(map[string]struct{})
+	for h := range b.Guarantees.Headers {
+		guaranteed[strings.ToLower(h)] = struct{}{}
+	}
+	for _, h := range b.Expectations.Headers {
+		if _, ok := guaranteed[strings.ToLower(h)]; !ok {
+			violations = append(violations, &Violation{
+				Backend: name,
+				Message: fmt.Sprintf("expected header %s is not guaranteed", h),
+			})
+		}
+	}
+
+	// Ranges are validated on load
+	ranges, _ := parseRanges(b.Guarantees.Status) // nolint:errcheck
+	for _, s := range b.Expectations.Status {
+		r, _ := parseRange(s) // nolint:errcheck
+		if !r.coveredBy(ranges) {
+			violations = append(violations, &Violation{
+				Backend: name,
+				Message: fmt.Sprintf("expected status %s is not guaranteed", s),
+			})
+		}
+	}
+	return violations
+}
+
+// Stubs generates VCL subroutines which mock the backend response for each expected status range.
+// Subroutines are named like "contract_F_origin_200" and set the lower bound status and expected headers
+// with the guaranteed example values, so they could be used as testing.after_fetch hooks.
+// Guarantees are used for the backend which does not declare expectations
+func (c *Contract) Stubs() string {
+	var buf strings.Builder
+	buf.WriteString("# Generated by falco contract stub, DO NOT EDIT\n")
+
+	for _, name := range c.names() {
+		b := c.Backends[name]
+		var statuses, headers []string
+		examples := make(map[string]string)
+		if b.Guarantees != nil {
+			statuses = b.Guarantees.Status
+			for h, v := range b.Guarantees.Headers {
+				headers = append(headers, h)
+				examples[strings.ToLower(h)] = v
+			}
+			sort.Strings(headers)
+		}
+		if b.Expectations != nil {
+			statuses = b.Expectations.Status
+			headers = b.Expectations.Headers
+		}
+		if len(statuses) == 0 {
+			statuses = []string{"200"}
+		}
+
+		for _, s := range statuses {
+			r, _ := parseRange(s) // nolint:errcheck
+			buf.WriteString(fmt.Sprintf("\nsub contract_%s_%d {\n", name, r.from))
+			buf.WriteString(fmt.Sprintf("  set beresp.status = %d;\n", r.from))
+			for _, h := range headers {
+				buf.WriteString(fmt.Sprintf("  set %s%s = %s;\n", responseHeaderPrefix, h, quote(examples[strings.ToLower(h)])))
+			}
+			buf.WriteString("}\n")
+		}
+	}
+	return buf.String()
+}
+
+// Quote the string literal, use long string syntax if the value contains double quote
+func quote(v string) string {
+	if strings.Contains(v, `"`) {
+		return `{"` + v + `"}`
+	}
+	return `"` + v + `"`
+}
+
+type statusRange struct {
+	from, to int
+}
+
+func (r statusRange) coveredBy(ranges []statusRange) bool {
+	for _, v := range ranges {
+		if v.from <= r.from && r.to <= v.to {
+			return true
+		}
+	}
+	return false
+}
+
+func parseRanges(specs []string) ([]statusRange, error) {
+	ranges := make([]statusRange, 0, len(specs))
+	for _, s := range specs {
+		r, err := parseRange(s)
+		if err != nil {
+			return nil, err
+		}
+		ranges = append(ranges, r)
+	}
+	return ranges, nil
+}
+
+// Parse status range like "200", "200-299" or "2xx"
+func parseRange(spec string) (statusRange, error) {
+	spec = strings.TrimSpace(spec)
+	if class, found := strings.CutSuffix(strings.ToLower(spec), "xx"); found {
+		n, err := strconv.Atoi(class)
+		if err != nil || n < 1 || n > 5 {
+			return statusRange{}, errors.Errorf("invalid status range %q", spec)
+		}
+		return statusRange{from: n * 100, to: n*100 + 99}, nil
+	}
+
+	from, to, found := strings.Cut(spec, "-")
+	if !found {
+		to = from
+	}
+	f, err := strconv.Atoi(strings.TrimSpace(from))
+	if err != nil {
+		return statusRange{}, errors.Errorf("invalid status range %q", spec)
+	}
+	t, err := strconv.Atoi(strings.TrimSpace(to))
+	if err != nil {
+		return statusRange{}, errors.Errorf("invalid status range %q", spec)
+	}
+	if f < 100 || t > 599 || f > t {
+		return statusRange{}, errors.Errorf("invalid status range %q", spec)
+	}
+	return statusRange{from: f, to: t}, nil
+}
@@ -0,0 +1,159 @@
+package contract
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/ysugimoto/falco/v2/lexer"
+	"github.com/ysugimoto/falco/v2/parser"
+)
+
+func writeContract(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write contract: %s", err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	origin := writeContract(t, "origin.yaml", `
+backends:
+  F_origin:
+    guarantees:
+      status: ["2xx", "404"]
+      headers:
+        Content-Type: text/html
+        Surrogate-Key: "page"
+`)
+	edge := writeContract(t, "edge.yaml", `
+backends:
+  F_origin:
+    expectations:
+      status: ["200-299"]
+      headers: [Content-Type]
+`)
+
+	t.Run("merge guarantees and expectations", func(t *testing.T) {
+		c, err := Load(origin, edge)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		b := c.Backends["F_origin"]
+		if b.Guarantees == nil || b.Expectations == nil {
+			t.Fatalf("Contract is not merged: %+v", b)
+		}
+		if len(c.Verify()) != 0 {
+			t.Errorf("Unexpected violations: %v", c.Verify())
+		}
+	})
+
+	t.Run("guarantees are declared twice", func(t *testing.T) {
+		if _, err := Load(origin, origin); err == nil {
+			t.Errorf("Expected error but got nil")
+		}
+	})
+
+	t.Run("invalid status range", func(t *testing.T) {
+		invalid := writeContract(t, "invalid.yaml", `
+backends:
+  F_origin:
+    guarantees:
+      status: ["299-200"]
+`)
+		if _, err := Load(invalid); err == nil {
+			t.Errorf("Expected error but got nil")
+		}
+	})
+}
+
+func TestVerify(t *testing.T) {
+	c := &Contract{
+		Backends: map[string]*Backend{
+			"F_api": {
+				Guarantees: &Guarantee{
+					Status:  []string{"200-299"},
+					Headers: map[string]string{"Content-Type": "application/json"},
+				},
+				Expectations: &Expectation{
+					Status:  []string{"2xx", "404"},
+					Headers: []string{"content-type", "X-Version"},
+				},
+			},
+			"F_static": {
+				Expectations: &Expectation{Headers: []string{"ETag"}},
+			},
+		},
+	}
+	vcl, err := parser.New(lexer.NewFromString(`
+sub vcl_fetch {
+  if (beresp.http.Surrogate-Control && !beresp.http.X-Debug) {
+    set beresp.http.Surrogate-Control = "max-age=60";
+  }
+  if (beresp.http.Cache-Control:max-age == "0") {
+    set beresp.ttl = 0s;
+  }
+  set req.http.Type = if(beresp.http.X-Cached, beresp.http.Content-Type, "none");
+  log beresp.http.ETag;
+  unset beresp.http.Set-Cookie;
+}`, lexer.WithFile("main.vcl"))).ParseVCL()
+	if err != nil {
+		t.Fatalf("Unexpected parse error: %s", err)
+	}
+
+	var actual []string
+	for _, v := range c.Verify(vcl) {
+		actual = append(actual, v.String())
+	}
+	expect := []string{
+		"backend F_api: expected header X-Version is not guaranteed",
+		"backend F_api: expected status 404 is not guaranteed",
+		"backend F_static: expectations are declared but the backend has no guarantees",
+		"main.vcl:6:7: response header Cache-Control is read but not expected for any backend",
+	}
+	if diff := cmp.Diff(expect, actual); diff != "" {
+		t.Errorf("Violations mismatch, diff=%s", diff)
+	}
+}
+
+func TestStubs(t *testing.T) {
+	c := &Contract{
+		Backends: map[string]*Backend{
+			"F_origin": {
+				Guarantees: &Guarantee{
+					Status:  []string{"2xx", "404"},
+					Headers: map[string]string{"Content-Type": "text/html", "ETag": `"abc"`},
+				},
+				Expectations: &Expectation{
+					Status:  []string{"200-299"},
+					Headers: []string{"ETag", "Content-Type"},
+				},
+			},
+			"F_legacy": {
+				Guarantees: &Guarantee{
+					Headers: map[string]string{"Server": "legacy"},
+				},
+			},
+		},
+	}
+	expect := strings.TrimLeft(`
+# Generated by falco contract stub, DO NOT EDIT
+
+sub contract_F_legacy_200 {
+  set beresp.status = 200;
+  set beresp.http.Server = "legacy";
+}
+
+sub contract_F_origin_200 {
+  set beresp.status = 200;
+  set beresp.http.ETag = {""abc""};
+  set beresp.http.Content-Type = "text/html";
+}
+`, "\n")
+	if diff := cmp.Diff(expect, c.Stubs()); diff != "" {
+		t.Errorf("Stubs mismatch, diff=%s", diff)
+	}
+}
@@ -0,0 +1,144 @@
+package contract
+
+import (
+	"strings"
+
+	"github.com/ysugimoto/falco/v2/ast"
+)
+
+// Collect backend response header reads in the VCL.
+// Assignment targets are not reads, and presence checks in conditions are skipped
+// because the VCL does not rely on the header which might not be present
+type readCollector struct {
+	reads []*ast.Ident
+}
+
+func collectReads(vcl *ast.VCL) []*ast.Ident {
+	c := &readCollector{}
+	for _, stmt := range vcl.Statements {
+		if sub, ok := stmt.(*ast.SubroutineDeclaration); ok {
+			c.collectBlock(sub.Block)
+			continue
+		}
+		c.collectStatement(stmt)
+	}
+	return c.reads
+}
+
+func (c *readCollector) collectBlock(block *ast.BlockStatement) {
+	if block == nil {
+		return
+	}
+	c.collectStatements(block.Statements)
+}
+
+func (c *readCollector) collectStatements(statements []ast.Statement) {
+	for _, stmt := range statements {
+		c.collectStatement(stmt)
+	}
+}
+
+func (c *readCollector) collectStatement(stmt ast.Statement) {
+	switch t := stmt.(type) {
+	case *ast.BlockStatement:
+		c.collectBlock(t)
+	case *ast.SetStatement:
+		c.collectExpression(t.Value)
+	case *ast.AddStatement:
+		c.collectExpression(t.Value)
+	case *ast.DeclareStatement:
+		c.collectExpression(t.Value)
+	case *ast.IfStatement:
+		c.collectIfStatement(t)
+	case *ast.SwitchStatement:
+		c.collectExpression(t.Control.Expression)
+		for _, cs := range t.Cases {
+			c.collectStatements(cs.Statements)
+		}
+	case *ast.ErrorStatement:
+		c.collectExpression(t.Code)
+		c.collectExpression(t.Argument)
+	case *ast.LogStatement:
+		c.collectExpression(t.Value)
+	case *ast.SyntheticStatement:
+		c.collectExpression(t.Value)
+	case *ast.SyntheticBase64Statement:
+		c.collectExpression(t.Value)
+	case *ast.ReturnStatement:
+		c.collectExpression(t.ReturnExpression)
+	case *ast.CallStatement:
+		c.collectExpressions(t.Arguments)
+	case *ast.FunctionCallStatement:
+		c.collectExpressions(t.Arguments)
+	}
+}
+
+func (c *readCollector) collectIfStatement(stmt *ast.IfStatement) {
+	c.collectCondition(stmt.Condition)
+	c.collectBlock(stmt.Consequence)
+	for _, another := range stmt.Another {
+		c.collectIfStatement(another)
+	}
+	if stmt.Alternative != nil {
+		c.collectBlock(stmt.Alternative.Consequence)
+	}
+}
+
+// Collect reads in the condition, bare and negated headers are presence checks
+func (c *readCollector) collectCondition(expr ast.Expression) {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		if isResponseHeader(t) {
+			return
+		}
+	case *ast.PrefixExpression:
+		if t.Operator == "!" {
+			c.collectCondition(t.Right)
+			return
+		}
+	case *ast.GroupedExpression:
+		c.collectCondition(t.Right)
+		return
+	case *ast.InfixExpression:
+		if t.Operator == "&&" || t.Operator == "||" {
+			c.collectCondition(t.Left)
+			c.collectCondition(t.Right)
+			return
+		}
+	}
+	c.collectExpression(expr)
+}
+
+func (c *readCollector) collectExpressions(expressions []ast.Expression) {
+	for i := range expressions {
+		c.collectExpression(expressions[i])
+	}
+}
+
+func (c *readCollector) collectExpression(expr ast.Expression) {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		if isResponseHeader(t) {
+			c.reads = append(c.reads, t)
+		}
+	case *ast.PrefixExpression:
+		c.collectExpression(t.Right)
+	case *ast.PostfixExpression:
+		c.collectExpression(t.Left)
+	case *ast.GroupedExpression:
+		c.collectExpression(t.Right)
+	case *ast.InfixExpression:
+		c.collectExpression(t.Left)
+		c.collectExpression(t.Right)
+	case *ast.IfExpression:
+		c.collectCondition(t.Condition)
+		c.collectExpression(t.Consequence)
+		c.collectExpression(t.Alternative)
+	case *ast.FunctionCallExpression:
+		c.collectExpressions(t.Arguments)
+	}
+}
+
+func isResponseHeader(ident *ast.Ident) bool {
+	return strings.HasPrefix(strings.ToLower(ident.Value), responseHeaderPrefix)
+}
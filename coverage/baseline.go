@@ -0,0 +1,49 @@
+package coverage
+
+import (
+	"github.com/ysugimoto/falco/v2/tester/shared"
+)
+
+// Regression is the coverage regression of the current profile against the baseline profile
+type Regression struct {
+	// Statements and branches which are covered in the baseline but not covered in the current profile
+	Uncovered []*Block `json:"uncovered"`
+	// Statements and branches on changed lines which are not covered in the current profile
+	Changed []*Block `json:"changed"`
+}
+
+// Regressed returns true if changed lines have statements or branches which are not covered
+func (r *Regression) Regressed() bool {
+	return len(r.Changed) > 0
+}
+
+// Regress reports statements and branches which become uncovered against the baseline profile like the profile of main branch.
+// Markers are identified by the file, type and marker name like Merge, so markers which are moved by the change
+// are not compared with the baseline and only reported when they are on changed lines, unless profiles are stable
+func Regress(baseline, current *Profile, changed ChangedLines) *Regression {
+	covered := make(map[string]bool)
+	for _, b := range Merge(baseline).Blocks {
+		covered[b.Type+"\x00"+b.ID] = b.Count > 0
+	}
+
+	lines := make(map[string]map[int]struct{})
+	for file, ls := range changed {
+		lines[file] = make(map[int]struct{})
+		for _, l := range ls {
+			lines[file][l] = struct{}{}
+		}
+	}
+
+	r := &Regression{}
+	for _, b := range Merge(current).Blocks {
+		if b.Count > 0 || b.Type == shared.CoverageTypeSubroutine.String() {
+			continue
+		}
+		if _, ok := lines[b.File][b.Line]; ok {
+			r.Changed = append(r.Changed, b)
+		} else if covered[b.Type+"\x00"+b.ID] {
+			r.Uncovered = append(r.Uncovered, b)
+		}
+	}
+	return r
+}
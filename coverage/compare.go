@@ -0,0 +1,72 @@
+package coverage
+
+import (
+	"github.com/ysugimoto/falco/v2/tester/shared"
+)
+
+// Comparison is the differential coverage between two profiles
+type Comparison struct {
+	// Markers which are covered only by the profile A or B
+	OnlyA []*Block `json:"only_a"`
+	OnlyB []*Block `json:"only_b"`
+	// Count of markers which are covered by both or neither profiles
+	Both    int `json:"both"`
+	Neither int `json:"neither"`
+}
+
+// Compare reports which markers only one of profiles covers, e.g. synthetic tests and replayed production logs.
+// Markers are identified by the file, type and marker name like Merge,
+// and markers which exist only in one profile are treated as not covered by another
+func Compare(a, b *Profile) *Comparison {
+	type pair struct {
+		a, b *Block
+	}
+	var keys []string
+	markers := make(map[string]*pair)
+	collect := func(p *Profile, set func(m *pair, v *Block)) {
+		for _, v := range Merge(p).Blocks {
+			key := v.Type + "\x00" + v.ID
+			m, ok := markers[key]
+			if !ok {
+				m = &pair{}
+				markers[key] = m
+				keys = append(keys, key)
+			}
+			set(m, v)
+		}
+	}
+	collect(a, func(m *pair, v *Block) { m.a = v })
+	collect(b, func(m *pair, v *Block) { m.b = v })
+
+	covered := func(v *Block) bool {
+		return v != nil && v.Count > 0
+	}
+	c := &Comparison{}
+	for _, key := range keys {
+		m := markers[key]
+		switch {
+		case covered(m.a) && covered(m.b):
+			c.Both++
+		case covered(m.a):
+			c.OnlyA = append(c.OnlyA, m.a)
+		case covered(m.b):
+			c.OnlyB = append(c.OnlyB, m.b)
+		default:
+			c.Neither++
+		}
+	}
+	sortBlocks(c.OnlyA)
+	sortBlocks(c.OnlyB)
+	return c
+}
+
+// Count returns the number of markers of the coverage type in blocks
+func Count(blocks []*Block, t shared.CoverageType) int {
+	var n int
+	for _, b := range blocks {
+		if b.Type == t.String() {
+			n++
+		}
+	}
+	return n
+}
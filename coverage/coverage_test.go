@@ -0,0 +1,354 @@
+package coverage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ysugimoto/falco/v2/tester/shared"
+	"github.com/ysugimoto/falco/v2/token"
+)
+
+func TestParseDiff(t *testing.T) {
+	diff := strings.Join([]string{
+		"diff --git a/vcl/main.vcl b/vcl/main.vcl",
+		"index 1111111..2222222 100644",
+		"--- a/vcl/main.vcl",
+		"+++ b/vcl/main.vcl",
+		"@@ -3 +3,2 @@ sub vcl_recv {",
+		"-  set req.http.Foo = \"foo\";",
+		"+  set req.http.Foo = \"bar\";",
+		"+++ this is added line",
+		"@@ -10,0 +12 @@ sub vcl_recv {",
+		"+  return(pass);",
+		"diff --git a/vcl/removed.vcl b/vcl/removed.vcl",
+		"--- a/vcl/removed.vcl",
+		"+++ /dev/null",
+		"@@ -1 +0,0 @@",
+		"-sub removed {}",
+	}, "\n")
+
+	changed, err := ParseDiff(strings.NewReader(diff))
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	lines := changed["vcl/main.vcl"]
+	if len(changed) != 1 || len(lines) != 3 || lines[0] != 3 || lines[1] != 4 || lines[2] != 12 {
+		t.Errorf("Unexpected changed lines %v", changed)
+	}
+}
+
+func TestPatch(t *testing.T) {
+	file := filepath.Join("/tmp", "vcl", "main.vcl")
+	c := &shared.CoverageFactory{
+		Subroutines: shared.CoverageFactoryItem{"sub_1_1": 1},
+		Statements:  shared.CoverageFactoryItem{"stmt_2_3": 1, "stmt_3_3": 0},
+		Branches:    shared.CoverageFactoryItem{"branch_3_3_1": 1, "branch_3_3_2": 0},
+		NodeMap: map[string]token.Token{
+			"sub_1_1":      {File: file, Line: 1, Position: 1},
+			"stmt_2_3":     {File: file, Line: 2, Position: 3},
+			"stmt_3_3":     {File: file, Line: 3, Position: 3},
+			"branch_3_3_1": {File: file, Line: 3, Position: 3},
+			"branch_3_3_2": {File: file, Line: 3, Position: 3},
+		},
+	}
+	c.Exclusions = []*shared.CoverageExclusion{{File: file, StartLine: 5, EndLine: 7}}
+	p := FromFactory(c, "/tmp")
+	if p.Blocks[0].File != "vcl/main.vcl" {
+		t.Errorf("File path should be relative, got %s", p.Blocks[0].File)
+	}
+	if len(p.Exclusions) != 1 || p.Exclusions[0].File != "vcl/main.vcl" {
+		t.Errorf("Excluded region should have relative path, got %v", p.Exclusions)
+	}
+
+	t.Run("partial", func(t *testing.T) {
+		report := p.Patch(ChangedLines{"vcl/main.vcl": {2, 3, 4}})
+		if len(report.Lines) != 2 || report.Covered != 1 || report.Partial != 1 || report.Missed != 0 {
+			t.Errorf("Unexpected report %+v", report)
+		}
+		if report.Percent() != 50 {
+			t.Errorf("Unexpected percent %f", report.Percent())
+		}
+		if u := report.Uncovered(); len(u) != 1 || u[0].Line != 3 {
+			t.Errorf("Unexpected uncovered lines %v", u)
+		}
+	})
+
+	t.Run("no executable lines", func(t *testing.T) {
+		report := p.Patch(ChangedLines{"vcl/other.vcl": {2}})
+		if len(report.Lines) != 0 || report.Percent() != 100 {
+			t.Errorf("Unexpected report %+v", report)
+		}
+	})
+}
+
+func TestWriteHTML(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "vcl"), 0o755); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	src := "sub vcl_recv {\n  set req.http.Foo = \"<foo>\";\n  if (req.http.Bar) {\n    return(pass);\n  }\n}\n"
+	if err := os.WriteFile(filepath.Join(root, "vcl", "main.vcl"), []byte(src), 0o644); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	p := &Profile{
+		Version: Version,
+		Blocks: []*Block{
+			{ID: "sub_1_1", File: "vcl/main.vcl", Line: 1, Position: 1, Type: "subroutine", Count: 1},
+			{ID: "stmt_2_3", File: "vcl/main.vcl", Line: 2, Position: 3, Type: "statement", Count: 1},
+			{ID: "stmt_3_3", File: "vcl/main.vcl", Line: 3, Position: 3, Type: "statement", Count: 1},
+			{ID: "branch_3_3_1", File: "vcl/main.vcl", Line: 3, Position: 3, Type: "branch", Count: 0},
+			{ID: "stmt_4_5", File: "vcl/main.vcl", Line: 4, Position: 5, Type: "statement", Count: 0},
+			{ID: "sub_1_1", File: "Remote.Snippet:recv", Line: 1, Position: 1, Type: "subroutine", Count: 0},
+		},
+		Exclusions: []*shared.CoverageExclusion{{File: "vcl/main.vcl", StartLine: 5, EndLine: 5}},
+	}
+
+	files, err := p.Files(root)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if len(files) != 1 {
+		t.Fatalf("Unreadable files should be skipped, got %d files", len(files))
+	}
+	f := files[0]
+	states := []string{lineCovered, lineCovered, linePartial, lineUncovered, lineExcluded, ""}
+	for i, line := range f.Lines {
+		if line.State != states[i] {
+			t.Errorf("Line %d state expects %q but got %q", line.Number, states[i], line.State)
+		}
+	}
+	if f.Percent("statement") != 66.67 || f.Percent("branch") != 0 || f.Percent("subroutine") != 100 {
+		t.Errorf("Unexpected percent statement=%f branch=%f", f.Percent("statement"), f.Percent("branch"))
+	}
+
+	dir := filepath.Join(root, "html")
+	if err := p.WriteHTML(dir, root); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	index, err := os.ReadFile(filepath.Join(dir, "index.html"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if !strings.Contains(string(index), `<a href="vcl_main.vcl.html">vcl/main.vcl</a>`) {
+		t.Errorf("Index should link to source page:\n%s", index)
+	}
+	page, err := os.ReadFile(filepath.Join(dir, "vcl_main.vcl.html"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	for _, expect := range []string{
+		`<tr id="L3" class="partial"`,
+		`<td class="branch">0/1</td>`,
+		`&#34;&lt;foo&gt;&#34;`,
+	} {
+		if !strings.Contains(string(page), expect) {
+			t.Errorf("Source page should contain %q:\n%s", expect, page)
+		}
+	}
+}
+
+func TestMerge(t *testing.T) {
+	shard1 := &Profile{
+		Version: Version,
+		Blocks: []*Block{
+			{ID: "/ci/1/vcl/main.vcl:sub_1_1", File: "vcl/main.vcl", Line: 1, Position: 1, Type: "subroutine", Count: 1},
+			{ID: "/ci/1/vcl/main.vcl:branch_3_3_1", File: "vcl/main.vcl", Line: 3, Position: 3, Type: "branch", Count: 2},
+			{ID: "/ci/1/vcl/main.vcl:branch_3_3_2", File: "vcl/main.vcl", Line: 3, Position: 3, Type: "branch", Count: 0},
+		},
+		Exclusions: []*shared.CoverageExclusion{{File: "vcl/main.vcl", StartLine: 5, EndLine: 7}},
+		Decisions: []*shared.Decision{
+			{
+				ID: "/ci/1/vcl/main.vcl:decision_3_3", File: "vcl/main.vcl", Line: 3, Position: 3,
+				Conditions: []string{"req.http.A", "req.http.B"},
+				Vectors:    map[string]uint64{"TT:T": 1},
+			},
+		},
+	}
+	shard2 := &Profile{
+		Version: Version,
+		Blocks: []*Block{
+			{ID: "/ci/2/vcl/main.vcl:sub_1_1", File: "vcl/main.vcl", Line: 1, Position: 1, Type: "subroutine", Count: 1},
+			{ID: "/ci/2/vcl/main.vcl:branch_3_3_1", File: "vcl/main.vcl", Line: 3, Position: 3, Type: "branch", Count: 0},
+			{ID: "/ci/2/vcl/main.vcl:branch_3_3_2", File: "vcl/main.vcl", Line: 3, Position: 3, Type: "branch", Count: 1},
+			{ID: "sub_1_1", File: "", Line: 1, Position: 1, Type: "subroutine", Count: 0},
+		},
+		Exclusions: []*shared.CoverageExclusion{{File: "vcl/main.vcl", StartLine: 5, EndLine: 7}},
+		Decisions: []*shared.Decision{
+			{
+				ID: "/ci/2/vcl/main.vcl:decision_3_3", File: "vcl/main.vcl", Line: 3, Position: 3,
+				Conditions: []string{"req.http.A", "req.http.B"},
+				Vectors:    map[string]uint64{"TT:T": 1, "TF:F": 1, "F-:F": 1},
+			},
+		},
+	}
+
+	merged := Merge(shard1, shard2)
+	expects := map[string]uint64{
+		"vcl/main.vcl:sub_1_1":      2,
+		"vcl/main.vcl:branch_3_3_1": 2,
+		"vcl/main.vcl:branch_3_3_2": 1,
+		"sub_1_1":                   0,
+	}
+	if len(merged.Blocks) != len(expects) {
+		t.Fatalf("Merged profile should have %d blocks, got %d", len(expects), len(merged.Blocks))
+	}
+	for _, b := range merged.Blocks {
+		if count, ok := expects[b.ID]; !ok || count != b.Count {
+			t.Errorf("Unexpected merged block %s, count=%d", b.ID, b.Count)
+		}
+	}
+	if len(merged.Exclusions) != 1 {
+		t.Errorf("Exclusions should be de-duplicated, got %d", len(merged.Exclusions))
+	}
+
+	// Branches which are executed in different shards are fully covered
+	report := merged.Factory().Report()
+	if report.Branches.Executed != 2 || report.Branches.Total != 2 {
+		t.Errorf("Unexpected branch coverage %d/%d", report.Branches.Executed, report.Branches.Total)
+	}
+	if len(merged.Decisions) != 1 || merged.Decisions[0].Vectors["TT:T"] != 2 {
+		t.Errorf("Decisions should be merged with summed vectors, got %v", merged.Decisions)
+	}
+	if conditions := report.Conditions; conditions.Executed != 2 || conditions.Total != 2 {
+		t.Errorf("Unexpected MC/DC %d/%d", conditions.Executed, conditions.Total)
+	}
+	if shard1.Blocks[0].Count != 1 || shard1.Decisions[0].Vectors["TT:T"] != 1 {
+		t.Errorf("Merge must not modify source profiles")
+	}
+}
+
+func TestStableFromFactory(t *testing.T) {
+	// The same subroutine before and after reformatting which moves markers
+	factory := func(dir string, line int) *shared.CoverageFactory {
+		file := filepath.Join(dir, "vcl", "main.vcl")
+		sub := fmt.Sprintf("%s:sub_%d_1", file, line)
+		stmt := fmt.Sprintf("%s:stmt_%d_3", file, line+1)
+		decision := fmt.Sprintf("%s:decision_%d_3", file, line+1)
+		return &shared.CoverageFactory{
+			Subroutines: shared.CoverageFactoryItem{sub: 1},
+			Statements:  shared.CoverageFactoryItem{stmt: 0},
+			Branches:    shared.CoverageFactoryItem{},
+			NodeMap: map[string]token.Token{
+				sub:  {File: file, Line: line, Position: 1},
+				stmt: {File: file, Line: line + 1, Position: 3},
+			},
+			SubroutineNames: map[string]string{sub: "vcl_recv"},
+			StableIDs: map[string]string{
+				sub:      "vcl_recv",
+				stmt:     "vcl_recv/if[0]",
+				decision: "vcl_recv/if[0]",
+			},
+			Decisions: []*shared.Decision{
+				{ID: decision, File: file, Line: line + 1, Position: 3, Vectors: map[string]uint64{"TT:T": 1}},
+			},
+		}
+	}
+	before := StableFromFactory(factory("/ci", 1), "/ci")
+	after := StableFromFactory(factory("/home", 3), "/home")
+
+	if !before.Stable {
+		t.Errorf("Profile should be marked as stable")
+	}
+	var ids []string
+	for _, b := range before.Blocks {
+		ids = append(ids, b.ID)
+	}
+	if strings.Join(ids, ",") != "vcl/main.vcl:vcl_recv,vcl/main.vcl:vcl_recv/if[0]" {
+		t.Errorf("Unexpected stable ids: %v", ids)
+	}
+	if before.Decisions[0].ID != "vcl/main.vcl:vcl_recv/if[0]" {
+		t.Errorf("Unexpected stable decision id: %s", before.Decisions[0].ID)
+	}
+
+	// Markers are identified across formatting-only changes
+	merged := Merge(before, after)
+	if !merged.Stable || len(merged.Blocks) != 2 || len(merged.Decisions) != 1 {
+		t.Fatalf("Stable profiles should be merged by structural ids, got %d blocks", len(merged.Blocks))
+	}
+	if merged.Blocks[0].Count != 2 || merged.Decisions[0].Vectors["TT:T"] != 2 {
+		t.Errorf("Counts should be summed up, got %d", merged.Blocks[0].Count)
+	}
+
+	// Structural ids are kept on writing the merged profile again
+	rewritten := StableFromFactory(merged.Factory(), "")
+	if rewritten.Blocks[1].ID != "vcl/main.vcl:vcl_recv/if[0]" {
+		t.Errorf("Unexpected rewritten stable id: %s", rewritten.Blocks[1].ID)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	tests := &Profile{
+		Version: Version,
+		Blocks: []*Block{
+			{ID: "/ci/vcl/main.vcl:sub_1_1", File: "vcl/main.vcl", Line: 1, Position: 1, Type: "subroutine", Count: 1},
+			{ID: "/ci/vcl/main.vcl:branch_3_3_1", File: "vcl/main.vcl", Line: 3, Position: 3, Type: "branch", Count: 1},
+			{ID: "/ci/vcl/main.vcl:branch_3_3_2", File: "vcl/main.vcl", Line: 3, Position: 3, Type: "branch", Count: 0},
+			{ID: "/ci/vcl/main.vcl:stmt_5_3", File: "vcl/main.vcl", Line: 5, Position: 3, Type: "statement", Count: 0},
+		},
+	}
+	logs := &Profile{
+		Version: Version,
+		Blocks: []*Block{
+			{ID: "/home/vcl/main.vcl:sub_1_1", File: "vcl/main.vcl", Line: 1, Position: 1, Type: "subroutine", Count: 10},
+			{ID: "/home/vcl/main.vcl:branch_3_3_1", File: "vcl/main.vcl", Line: 3, Position: 3, Type: "branch", Count: 0},
+			{ID: "/home/vcl/main.vcl:branch_3_3_2", File: "vcl/main.vcl", Line: 3, Position: 3, Type: "branch", Count: 10},
+			{ID: "/home/vcl/main.vcl:stmt_5_3", File: "vcl/main.vcl", Line: 5, Position: 3, Type: "statement", Count: 0},
+		},
+	}
+
+	c := Compare(tests, logs)
+	if c.Both != 1 || c.Neither != 1 {
+		t.Errorf("Unexpected both=%d, neither=%d", c.Both, c.Neither)
+	}
+	if len(c.OnlyA) != 1 || c.OnlyA[0].ID != "vcl/main.vcl:branch_3_3_1" || c.OnlyA[0].Count != 1 {
+		t.Errorf("Unexpected markers which are covered only by A: %v", c.OnlyA)
+	}
+	if len(c.OnlyB) != 1 || c.OnlyB[0].ID != "vcl/main.vcl:branch_3_3_2" || c.OnlyB[0].Count != 10 {
+		t.Errorf("Unexpected markers which are covered only by B: %v", c.OnlyB)
+	}
+	if n := Count(c.OnlyB, shared.CoverageTypeBranch); n != 1 {
+		t.Errorf("Count of branches should be 1, got %d", n)
+	}
+}
+
+func TestRegress(t *testing.T) {
+	baseline := &Profile{
+		Version: Version,
+		Blocks: []*Block{
+			{ID: "/ci/vcl/main.vcl:sub_1_1", File: "vcl/main.vcl", Line: 1, Position: 1, Type: "subroutine", Count: 1},
+			{ID: "/ci/vcl/main.vcl:branch_3_3_1", File: "vcl/main.vcl", Line: 3, Position: 3, Type: "branch", Count: 1},
+			{ID: "/ci/vcl/main.vcl:stmt_5_3", File: "vcl/main.vcl", Line: 5, Position: 3, Type: "statement", Count: 1},
+			{ID: "/ci/vcl/main.vcl:stmt_6_3", File: "vcl/main.vcl", Line: 6, Position: 3, Type: "statement", Count: 0},
+		},
+	}
+	current := &Profile{
+		Version: Version,
+		Blocks: []*Block{
+			{ID: "/home/vcl/main.vcl:sub_1_1", File: "vcl/main.vcl", Line: 1, Position: 1, Type: "subroutine", Count: 0},
+			{ID: "/home/vcl/main.vcl:branch_3_3_1", File: "vcl/main.vcl", Line: 3, Position: 3, Type: "branch", Count: 0},
+			{ID: "/home/vcl/main.vcl:stmt_5_3", File: "vcl/main.vcl", Line: 5, Position: 3, Type: "statement", Count: 1},
+			{ID: "/home/vcl/main.vcl:stmt_6_3", File: "vcl/main.vcl", Line: 6, Position: 3, Type: "statement", Count: 0},
+			{ID: "/home/vcl/main.vcl:stmt_7_3", File: "vcl/main.vcl", Line: 7, Position: 3, Type: "statement", Count: 0},
+		},
+	}
+
+	r := Regress(baseline, current, ChangedLines{"vcl/main.vcl": {7}})
+	if len(r.Uncovered) != 1 || r.Uncovered[0].ID != "vcl/main.vcl:branch_3_3_1" {
+		t.Errorf("Unexpected newly uncovered markers: %v", r.Uncovered)
+	}
+	if len(r.Changed) != 1 || r.Changed[0].ID != "vcl/main.vcl:stmt_7_3" {
+		t.Errorf("Unexpected uncovered markers on changed lines: %v", r.Changed)
+	}
+	if !r.Regressed() {
+		t.Errorf("Coverage should be regressed")
+	}
+
+	r = Regress(baseline, current, ChangedLines{})
+	if r.Regressed() {
+		t.Errorf("Coverage should not be regressed without changed lines")
+	}
+}
@@ -0,0 +1,156 @@
+package coverage
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ChangedLines is added or changed line numbers for each file
+type ChangedLines map[string][]int
+
+// ParseDiff parses unified diff output like "git diff --unified=0" and collects added or changed lines
+func ParseDiff(r io.Reader) (ChangedLines, error) {
+	changed := make(ChangedLines)
+	scanner := bufio.NewScanner(r)
+
+	var file string
+	var line int
+	// File header lines appear until the first hunk of each file
+	inHeader := true
+	for scanner.Scan() {
+		text := scanner.Text()
+		switch {
+		case strings.HasPrefix(text, "diff "):
+			inHeader = true
+		case inHeader && strings.HasPrefix(text, "+++ "):
+			file = strings.TrimPrefix(text, "+++ ")
+			if file == "/dev/null" {
+				// File is deleted
+				file = ""
+				continue
+			}
+			file = strings.TrimPrefix(file, "b/")
+		case strings.HasPrefix(text, "@@ "):
+			// Hunk header like "@@ -10,2 +12,3 @@"
+			inHeader = false
+			fields := strings.Fields(text)
+			if len(fields) < 3 || !strings.HasPrefix(fields[2], "+") {
+				return nil, errors.Errorf("invalid hunk header: %s", text)
+			}
+			start, _, _ := strings.Cut(strings.TrimPrefix(fields[2], "+"), ",")
+			v, err := strconv.Atoi(start)
+			if err != nil {
+				return nil, errors.Errorf("invalid hunk header: %s", text)
+			}
+			line = v
+		case inHeader:
+			continue
+		case strings.HasPrefix(text, "+"):
+			if file != "" {
+				changed[file] = append(changed[file], line)
+			}
+			line++
+		case strings.HasPrefix(text, " "):
+			line++
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return changed, nil
+}
+
+// LineCoverage is the coverage state of changed line
+type LineCoverage struct {
+	File   string `json:"file"`
+	Line   int    `json:"line"`
+	Hits   int    `json:"hits"`   // executed markers on the line
+	Blocks int    `json:"blocks"` // all markers on the line
+}
+
+func (l *LineCoverage) Covered() bool {
+	return l.Hits == l.Blocks
+}
+
+func (l *LineCoverage) Partial() bool {
+	return l.Hits > 0 && l.Hits < l.Blocks
+}
+
+// PatchReport is the coverage report of changed lines
+type PatchReport struct {
+	// Changed lines which have coverage markers. Lines without markers like comments are not counted
+	Lines   []*LineCoverage `json:"lines"`
+	Covered int             `json:"covered"`
+	Partial int             `json:"partial"`
+	Missed  int             `json:"missed"`
+}
+
+// Percent returns the ratio of fully covered lines, 100 if no changed lines have coverage markers
+func (r *PatchReport) Percent() float64 {
+	if len(r.Lines) == 0 {
+		return 100
+	}
+	return math.Round(float64(r.Covered)/float64(len(r.Lines))*10000) / 100
+}
+
+// Uncovered returns changed lines which are not fully covered
+func (r *PatchReport) Uncovered() []*LineCoverage {
+	var lines []*LineCoverage
+	for _, l := range r.Lines {
+		if !l.Covered() {
+			lines = append(lines, l)
+		}
+	}
+	return lines
+}
+
+// Patch calculates coverage of changed lines
+func (p *Profile) Patch(changed ChangedLines) *PatchReport {
+	// Group blocks by file and line
+	blocks := make(map[string]map[int][]*Block)
+	for _, b := range p.Blocks {
+		if _, ok := blocks[b.File]; !ok {
+			blocks[b.File] = make(map[int][]*Block)
+		}
+		blocks[b.File][b.Line] = append(blocks[b.File][b.Line], b)
+	}
+
+	report := &PatchReport{}
+	for file, lines := range changed {
+		for _, line := range lines {
+			bs, ok := blocks[file][line]
+			if !ok {
+				continue
+			}
+			lc := &LineCoverage{File: file, Line: line, Blocks: len(bs)}
+			for _, b := range bs {
+				if b.Count > 0 {
+					lc.Hits++
+				}
+			}
+			switch {
+			case lc.Covered():
+				report.Covered++
+			case lc.Partial():
+				report.Partial++
+			default:
+				report.Missed++
+			}
+			report.Lines = append(report.Lines, lc)
+		}
+	}
+
+	sort.Slice(report.Lines, func(i, j int) bool {
+		if report.Lines[i].File != report.Lines[j].File {
+			return report.Lines[i].File < report.Lines[j].File
+		}
+		return report.Lines[i].Line < report.Lines[j].Line
+	})
+	return report
+}
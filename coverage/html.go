@@ -0,0 +1,267 @@
+package coverage
+
+import (
+	"fmt"
+	"html/template"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/ysugimoto/falco/v2/tester/shared"
+)
+
+// Line state classes which are used in the HTML report
+const (
+	lineCovered   = "covered"
+	linePartial   = "partial"
+	lineUncovered = "uncovered"
+	lineExcluded  = "excluded"
+	lineDead      = "dead"
+)
+
+// SourceLine is the annotated source line of the HTML report
+type SourceLine struct {
+	Number int
+	Text   string
+	State  string // empty if the line does not have coverage markers and is not excluded
+	Blocks []*Block
+}
+
+// Title returns hit counts of markers on the line for the tooltip
+func (l *SourceLine) Title() string {
+	titles := make([]string, len(l.Blocks))
+	for i, b := range l.Blocks {
+		titles[i] = fmt.Sprintf("%s at %d:%d hit %d times", b.Type, b.Line, b.Position, b.Count)
+	}
+	return strings.Join(titles, "\n")
+}
+
+// Branches returns the number of executed branches and all branches on the line
+func (l *SourceLine) Branches() (int, int) {
+	var hits, total int
+	for _, b := range l.Blocks {
+		if b.Type != "branch" {
+			continue
+		}
+		total++
+		if b.Count > 0 {
+			hits++
+		}
+	}
+	return hits, total
+}
+
+// FileReport is the annotated source file of the HTML report
+type FileReport struct {
+	File  string
+	Page  string
+	Lines []*SourceLine
+
+	// Number of executed markers and all markers for each coverage type
+	Hits   map[string]int
+	Totals map[string]int
+}
+
+// Percent returns the ratio of executed markers of the type, 100 if the file does not have markers
+func (f *FileReport) Percent(t string) float64 {
+	if f.Totals[t] == 0 {
+		return 100
+	}
+	return math.Round(float64(f.Hits[t])/float64(f.Totals[t])*10000) / 100
+}
+
+var pageNamePattern = regexp.MustCompile(`[^0-9a-zA-Z_.-]+`)
+
+// Files annotates source files of the profile.
+// Relative paths of blocks are resolved from root directory, files which could not be read like remote snippets are skipped
+func (p *Profile) Files(root string) ([]*FileReport, error) {
+	blocks := make(map[string][]*Block)
+	for _, b := range p.Blocks {
+		blocks[b.File] = append(blocks[b.File], b)
+	}
+
+	var reports []*FileReport
+	for file, bs := range blocks {
+		path := filepath.FromSlash(file)
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(root, path)
+		}
+		src, err := os.ReadFile(path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, errors.WithStack(err)
+		}
+
+		report := &FileReport{
+			File:   file,
+			Page:   strings.Trim(pageNamePattern.ReplaceAllString(file, "_"), "_.") + ".html",
+			Hits:   make(map[string]int),
+			Totals: make(map[string]int),
+		}
+		for i, text := range strings.Split(strings.TrimRight(string(src), "\n"), "\n") {
+			report.Lines = append(report.Lines, &SourceLine{Number: i + 1, Text: text})
+		}
+		for _, b := range bs {
+			report.Totals[b.Type]++
+			if b.Count > 0 {
+				report.Hits[b.Type]++
+			}
+			if b.Line < 1 || b.Line > len(report.Lines) {
+				continue
+			}
+			line := report.Lines[b.Line-1]
+			line.Blocks = append(line.Blocks, b)
+		}
+		for _, line := range report.Lines {
+			line.State = lineState(line.Blocks)
+		}
+		for state, regions := range map[string][]*shared.CoverageExclusion{lineExcluded: p.Exclusions, lineDead: p.Dead} {
+			for _, e := range regions {
+				if e.File != file {
+					continue
+				}
+				for n := max(e.StartLine, 1); n <= min(e.EndLine, len(report.Lines)); n++ {
+					report.Lines[n-1].State = state
+				}
+			}
+		}
+		reports = append(reports, report)
+	}
+
+	sort.Slice(reports, func(i, j int) bool {
+		return reports[i].File < reports[j].File
+	})
+	return reports, nil
+}
+
+func lineState(blocks []*Block) string {
+	if len(blocks) == 0 {
+		return ""
+	}
+	var hits int
+	for _, b := range blocks {
+		if b.Count > 0 {
+			hits++
+		}
+	}
+	switch hits {
+	case len(blocks):
+		return lineCovered
+	case 0:
+		return lineUncovered
+	default:
+		return linePartial
+	}
+}
+
+// WriteHTML writes index page and annotated source pages of the profile into the directory
+func (p *Profile) WriteHTML(dir, root string) error {
+	files, err := p.Files(root)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return errors.WithStack(err)
+	}
+
+	if err := writeTemplate(filepath.Join(dir, "index.html"), indexTemplate, files); err != nil {
+		return errors.WithStack(err)
+	}
+	for _, f := range files {
+		if err := writeTemplate(filepath.Join(dir, f.Page), sourceTemplate, f); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
+}
+
+func writeTemplate(path string, tmpl *template.Template, data any) error {
+	fp, err := os.Create(path)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer fp.Close()
+
+	return errors.WithStack(tmpl.Execute(fp, data))
+}
+
+var coverageTypes = []string{"subroutine", "statement", "branch"}
+
+var templateFuncs = template.FuncMap{
+	"types": func() []string { return coverageTypes },
+	"branches": func(l *SourceLine) string {
+		hits, total := l.Branches()
+		if total == 0 {
+			return ""
+		}
+		return fmt.Sprintf("%d/%d", hits, total)
+	},
+}
+
+const htmlStyle = `
+<style>
+  body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Helvetica, Arial, sans-serif; margin: 24px; color: #24292f; }
+  table { border-collapse: collapse; }
+  th, td { padding: 4px 12px; text-align: left; }
+  .summary td, .summary th { border-bottom: 1px solid #d0d7de; }
+  .source { font-family: SFMono-Regular, Consolas, Menlo, monospace; font-size: 13px; width: 100%; }
+  .source td { padding: 0 8px; white-space: pre; }
+  .source .num, .source .branch { color: #8c959f; text-align: right; user-select: none; }
+  .covered { background: #dafbe1; }
+  .partial { background: #fff8c5; }
+  .uncovered { background: #ffebe9; }
+  .excluded { color: #8c959f; background: #f6f8fa; }
+  .dead { color: #8c959f; background: #f6f8fa; text-decoration: line-through; }
+</style>`
+
+var indexTemplate = template.Must(template.New("index").Funcs(templateFuncs).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>falco coverage report</title>` + htmlStyle + `
+</head>
+<body>
+<h1>Coverage Report</h1>
+<table class="summary">
+  <tr><th>File</th>{{ range types }}<th>{{ . }}</th>{{ end }}</tr>
+  {{ range . }}{{ $f := . }}
+  <tr>
+    <td><a href="{{ .Page }}">{{ .File }}</a></td>
+    {{ range types }}<td>{{ $f.Percent . }}% ({{ index $f.Hits . }}/{{ index $f.Totals . }})</td>{{ end }}
+  </tr>
+  {{ end }}
+</table>
+</body>
+</html>
+`))
+
+var sourceTemplate = template.Must(template.New("source").Funcs(templateFuncs).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{ .File }} - falco coverage report</title>` + htmlStyle + `
+</head>
+<body>
+<p><a href="index.html">Coverage Report</a> / {{ .File }}</p>
+<table class="summary">
+  <tr>{{ range types }}<th>{{ . }}</th>{{ end }}</tr>
+  <tr>{{ $f := . }}{{ range types }}<td>{{ $f.Percent . }}% ({{ index $f.Hits . }}/{{ index $f.Totals . }})</td>{{ end }}</tr>
+</table>
+<table class="source">
+  {{ range .Lines }}
+  <tr id="L{{ .Number }}" class="{{ .State }}" title="{{ .Title }}">
+    <td class="num">{{ .Number }}</td>
+    <td class="branch">{{ branches . }}</td>
+    <td>{{ .Text }}</td>
+  </tr>
+  {{ end }}
+</table>
+</body>
+</html>
+`))
@@ -0,0 +1,158 @@
+package coverage
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/ysugimoto/falco/v2/tester/shared"
+	"github.com/ysugimoto/falco/v2/token"
+)
+
+// Merge combines profiles of sharded test runs into the single profile.
+// Markers are identified by the file, type and marker name which consists of the position in the file,
+// so the marker which is present in multiple profiles like the same branch is counted once and its hit counts are summed up.
+// Evaluation vectors of decisions are summed up as well, so MC/DC is calculated from vectors of all runs.
+// Marker ids of the merged profile are prefixed with the relative file path because each run could have different absolute paths.
+// Stable and position based profiles have different ids so they should not be merged together
+func Merge(profiles ...*Profile) *Profile {
+	merged := &Profile{Version: Version}
+	if len(profiles) > 0 {
+		merged.Stable = profiles[0].Stable
+	}
+	blocks := make(map[string]*Block)
+	exclusions := make(map[shared.CoverageExclusion]struct{})
+	dead := make(map[shared.CoverageExclusion]struct{})
+	decisions := make(map[string]*shared.Decision)
+
+	for _, p := range profiles {
+		for _, b := range p.Blocks {
+			id := markerName(b.ID)
+			if b.File != "" {
+				id = b.File + ":" + id
+			}
+			key := b.Type + "\x00" + id
+			if v, ok := blocks[key]; ok {
+				v.Count += b.Count
+				continue
+			}
+			block := *b
+			block.ID = id
+			blocks[key] = &block
+			merged.Blocks = append(merged.Blocks, &block)
+		}
+		for _, e := range p.Exclusions {
+			if _, ok := exclusions[*e]; ok {
+				continue
+			}
+			exclusions[*e] = struct{}{}
+			v := *e
+			merged.Exclusions = append(merged.Exclusions, &v)
+		}
+		for _, e := range p.Dead {
+			if _, ok := dead[*e]; ok {
+				continue
+			}
+			dead[*e] = struct{}{}
+			v := *e
+			merged.Dead = append(merged.Dead, &v)
+		}
+		for _, d := range p.Decisions {
+			id := markerName(d.ID)
+			if d.File != "" {
+				id = d.File + ":" + id
+			}
+			v, ok := decisions[id]
+			if !ok {
+				v = &shared.Decision{
+					ID:         id,
+					File:       d.File,
+					Line:       d.Line,
+					Position:   d.Position,
+					Conditions: d.Conditions,
+					Vectors:    make(map[string]uint64),
+				}
+				decisions[id] = v
+				merged.Decisions = append(merged.Decisions, v)
+			}
+			for vector, count := range d.Vectors {
+				v.Vectors[vector] += count
+			}
+		}
+	}
+
+	sortBlocks(merged.Blocks)
+	shared.SortDecisions(merged.Decisions)
+	for _, regions := range [][]*shared.CoverageExclusion{merged.Exclusions, merged.Dead} {
+		sort.Slice(regions, func(i, j int) bool {
+			a, b := regions[i], regions[j]
+			if a.File != b.File {
+				return a.File < b.File
+			}
+			return a.StartLine < b.StartLine
+		})
+	}
+	return merged
+}
+
+// Factory converts profile to the coverage factory in order to output the report in other formats like LCOV.
+// File paths of the factory are the same as the profile which are relative to the directory where the profile is written
+func (p *Profile) Factory() *shared.CoverageFactory {
+	c := &shared.CoverageFactory{
+		Subroutines: make(shared.CoverageFactoryItem),
+		Statements:  make(shared.CoverageFactoryItem),
+		Branches:    make(shared.CoverageFactoryItem),
+		NodeMap:     make(map[string]token.Token),
+
+		SubroutineNames: make(map[string]string),
+	}
+	// Keep structural ids in order to write the stable profile again
+	if p.Stable {
+		c.StableIDs = make(map[string]string)
+	}
+	for _, b := range p.Blocks {
+		switch b.Type {
+		case shared.CoverageTypeSubroutine.String():
+			c.Subroutines[b.ID] = b.Count
+			if b.Name != "" {
+				c.SubroutineNames[b.ID] = b.Name
+			}
+		case shared.CoverageTypeStatement.String():
+			c.Statements[b.ID] = b.Count
+		case shared.CoverageTypeBranch.String():
+			c.Branches[b.ID] = b.Count
+		default:
+			continue
+		}
+		if p.Stable {
+			c.StableIDs[b.ID] = markerName(b.ID)
+		}
+		c.NodeMap[b.ID] = token.Token{
+			File:     b.File,
+			Line:     b.Line,
+			Position: b.Position,
+		}
+	}
+	for _, e := range p.Exclusions {
+		v := *e
+		c.Exclusions = append(c.Exclusions, &v)
+	}
+	for _, e := range p.Dead {
+		v := *e
+		c.Dead = append(c.Dead, &v)
+	}
+	c.Decisions = append(c.Decisions, p.Decisions...)
+	if p.Stable {
+		for _, d := range p.Decisions {
+			c.StableIDs[d.ID] = markerName(d.ID)
+		}
+	}
+	return c
+}
+
+// Marker name without the file prefix like "stmt_10_5" or "vcl_recv/set[0]"
+func markerName(id string) string {
+	if i := strings.LastIndex(id, ":"); i >= 0 {
+		return id[i+1:]
+	}
+	return id
+}
@@ -0,0 +1,171 @@
+// Package coverage provides the coverage profile file format and patch coverage calculation.
+// The profile is written by "falco test --coverage --coverage-out" and "falco coverage diff" command compares it with git diff
+package coverage
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/ysugimoto/falco/v2/tester/shared"
+)
+
+// Version of coverage profile format, increment when the format is changed incompatibly
+const Version = 1
+
+// Block is the single coverage marker in the source file
+type Block struct {
+	ID       string `json:"id"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Position int    `json:"position"`
+	Type     string `json:"type"`
+	Count    uint64 `json:"count"`
+	// Subroutine name, only present in the subroutine block
+	Name string `json:"name,omitempty"`
+}
+
+type Profile struct {
+	Version int `json:"version"`
+	// True if ids of blocks and decisions are structural ids like "main.vcl:vcl_recv/if[0]/then/set[1]"
+	// which are stable across formatting-only changes, otherwise ids consist of source positions
+	Stable bool     `json:"stable,omitempty"`
+	Blocks []*Block `json:"blocks"`
+	// Regions which are excluded from coverage by pragma comments
+	Exclusions []*shared.CoverageExclusion `json:"exclusions,omitempty"`
+	// Regions of statements which can never be executed
+	Dead []*shared.CoverageExclusion `json:"dead,omitempty"`
+	// Compound conditions with executed evaluation vectors for MC/DC
+	Decisions []*shared.Decision `json:"decisions,omitempty"`
+}
+
+// FromFactory creates profile from collected coverage.
+// VCL file paths are converted to relative path from base directory in order to compare with git diff
+func FromFactory(c *shared.CoverageFactory, base string) *Profile {
+	p := &Profile{Version: Version}
+
+	for _, v := range []struct {
+		t    shared.CoverageType
+		item shared.CoverageFactoryItem
+	}{
+		{shared.CoverageTypeSubroutine, c.Subroutines},
+		{shared.CoverageTypeStatement, c.Statements},
+		{shared.CoverageTypeBranch, c.Branches},
+	} {
+		for id, count := range v.item {
+			tok := c.NodeMap[id]
+			p.Blocks = append(p.Blocks, &Block{
+				ID:       id,
+				File:     relativePath(base, tok.File),
+				Line:     tok.Line,
+				Position: tok.Position,
+				Type:     v.t.String(),
+				Count:    count,
+				Name:     c.SubroutineNames[id],
+			})
+		}
+	}
+
+	// Sort blocks by position to make profile stable
+	sortBlocks(p.Blocks)
+
+	for _, e := range c.Exclusions {
+		p.Exclusions = append(p.Exclusions, &shared.CoverageExclusion{
+			File:      relativePath(base, e.File),
+			StartLine: e.StartLine,
+			EndLine:   e.EndLine,
+		})
+	}
+	for _, e := range c.Dead {
+		p.Dead = append(p.Dead, &shared.CoverageExclusion{
+			File:      relativePath(base, e.File),
+			StartLine: e.StartLine,
+			EndLine:   e.EndLine,
+		})
+	}
+	for _, d := range c.Decisions {
+		v := *d
+		v.File = relativePath(base, d.File)
+		p.Decisions = append(p.Decisions, &v)
+	}
+	return p
+}
+
+// StableFromFactory creates profile which is keyed by the file, subroutine name and structural path of the marker
+// instead of the source position, so that profiles remain comparable after the VCL is reformatted.
+// Markers which do not have structural ids keep their original ids
+func StableFromFactory(c *shared.CoverageFactory, base string) *Profile {
+	p := FromFactory(c, base)
+	p.Stable = true
+
+	stable := func(id, file string) string {
+		v, ok := c.StableIDs[id]
+		if !ok {
+			return id
+		}
+		if file != "" {
+			return file + ":" + v
+		}
+		return v
+	}
+	for _, b := range p.Blocks {
+		b.ID = stable(b.ID, b.File)
+	}
+	for _, d := range p.Decisions {
+		d.ID = stable(d.ID, d.File)
+	}
+	sortBlocks(p.Blocks)
+	return p
+}
+
+func sortBlocks(blocks []*Block) {
+	sort.Slice(blocks, func(i, j int) bool {
+		a, b := blocks[i], blocks[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		if a.Position != b.Position {
+			return a.Position < b.Position
+		}
+		return a.ID < b.ID
+	})
+}
+
+// Convert VCL file path to relative path from base directory if possible
+func relativePath(base, file string) string {
+	if strings.EqualFold(filepath.Ext(file), ".vcl") {
+		if rel, err := filepath.Rel(base, file); err == nil {
+			file = rel
+		}
+	}
+	return filepath.ToSlash(file)
+}
+
+func (p *Profile) WriteFile(path string) error {
+	b, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(os.WriteFile(path, b, 0o644))
+}
+
+func ReadFile(path string) (*Profile, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	var p Profile
+	if err := json.Unmarshal(b, &p); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if p.Version != Version {
+		return nil, errors.Errorf("Unsupported coverage profile version %d", p.Version)
+	}
+	return &p, nil
+}
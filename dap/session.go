@@ -6,7 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"net/http"
 	"sync"
 
@@ -293,7 +293,7 @@ func (s *session) onLaunchRequest(req *godap.LaunchRequest) error {
 		return err
 	}
 
-	log.Printf("launch request: %+v", args)
+	slog.Debug("Launch request", "main", args.MainVCL, "include_paths", args.IncludePaths)
 
 	resolvers, err := resolver.NewFileResolvers(args.MainVCL, args.IncludePaths)
 	if err != nil {
@@ -310,7 +310,7 @@ func (s *session) onLaunchRequest(req *godap.LaunchRequest) error {
 
 	s.launchServer()
 
-	log.Print("debugger launched")
+	slog.Debug("Debugger launched")
 
 	s.send(&godap.LaunchResponse{
 		Response: newResponse(req),
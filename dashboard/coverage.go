@@ -0,0 +1,53 @@
+package dashboard
+
+import (
+	"sync"
+
+	"github.com/ysugimoto/falco/v2/tester/shared"
+	"github.com/ysugimoto/falco/v2/token"
+)
+
+// coverageGauge implements shared.CoverageSink and counts instrumented and executed markers for the gauges.
+// VCL is instrumented for each test file so Setup could be called multiple times for the same marker
+type coverageGauge struct {
+	mu       sync.Mutex
+	markers  map[shared.CoverageType]map[string]struct{}
+	executed map[shared.CoverageType]int
+	onChange func()
+}
+
+func newCoverageGauge() *coverageGauge {
+	return &coverageGauge{
+		markers:  make(map[shared.CoverageType]map[string]struct{}),
+		executed: make(map[shared.CoverageType]int),
+	}
+}
+
+func (g *coverageGauge) Setup(t shared.CoverageType, key string, tok token.Token) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if _, ok := g.markers[t]; !ok {
+		g.markers[t] = make(map[string]struct{})
+	}
+	g.markers[t][key] = struct{}{}
+}
+
+// Count is accumulated so the marker is newly covered only when the count becomes 1
+func (g *coverageGauge) Mark(t shared.CoverageType, key string, count uint64) {
+	if count != 1 {
+		return
+	}
+	g.mu.Lock()
+	g.executed[t]++
+	g.mu.Unlock()
+	if g.onChange != nil {
+		g.onChange()
+	}
+}
+
+// Get returns executed and total number of markers of the type
+func (g *coverageGauge) Get(t shared.CoverageType) (int, int) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.executed[t], len(g.markers[t])
+}
@@ -0,0 +1,33 @@
+package dashboard
+
+import (
+	"testing"
+
+	"github.com/ysugimoto/falco/v2/tester/shared"
+	"github.com/ysugimoto/falco/v2/token"
+)
+
+func TestCoverageGauge(t *testing.T) {
+	g := newCoverageGauge()
+	var changed int
+	g.onChange = func() { changed++ }
+
+	// Same markers are set up again when another test file instruments the VCL
+	for i := 0; i < 2; i++ {
+		g.Setup(shared.CoverageTypeStatement, "main.vcl:stmt_1_1", token.Token{})
+		g.Setup(shared.CoverageTypeStatement, "main.vcl:stmt_2_1", token.Token{})
+		g.Setup(shared.CoverageTypeBranch, "main.vcl:branch_3_1_true", token.Token{})
+	}
+	g.Mark(shared.CoverageTypeStatement, "main.vcl:stmt_1_1", 1)
+	g.Mark(shared.CoverageTypeStatement, "main.vcl:stmt_1_1", 2)
+
+	if executed, total := g.Get(shared.CoverageTypeStatement); executed != 1 || total != 2 {
+		t.Errorf("statement gauge expects 1/2, got %d/%d", executed, total)
+	}
+	if executed, total := g.Get(shared.CoverageTypeBranch); executed != 0 || total != 1 {
+		t.Errorf("branch gauge expects 0/1, got %d/%d", executed, total)
+	}
+	if changed != 1 {
+		t.Errorf("onChange expects to be called once, got %d", changed)
+	}
+}
@@ -0,0 +1,426 @@
+// Package dashboard provides the interactive terminal UI of "falco test --ui".
+// Dashboard shows the live tree of test files and cases with pass/fail and coverage gauges,
+// and could filter cases as you type and re-run the selected test case with single keystroke
+package dashboard
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/ysugimoto/falco/v2/tester"
+	"github.com/ysugimoto/falco/v2/tester/shared"
+)
+
+const helpText = "[yellow]↑/↓[-] select  [yellow]r[-] re-run selected test  [yellow]/[-] filter  [yellow]Esc[-] clear filter or quit"
+
+// Identity of the test case in the tree, test cases which have the same name in different scopes are the single test
+type caseRef struct {
+	file  string
+	group string
+	name  string
+	scope string
+}
+
+func (r *caseRef) key() string {
+	return strings.Join([]string{r.file, r.group, r.name, r.scope}, "\x00")
+}
+
+type Dashboard struct {
+	app     *tview.Application
+	tree    *tview.TreeView
+	summary *tview.TextView
+	detail  *tview.TextView
+	filter  *tview.InputField
+	help    *tview.TextView
+
+	tester   *tester.Tester
+	main     string
+	coverage *coverageGauge
+
+	mu      sync.Mutex
+	results []*tester.TestResult
+	status  string
+	running bool
+}
+
+func New(t *tester.Tester, main string) *Dashboard {
+	d := &Dashboard{
+		app:      tview.NewApplication(),
+		tree:     tview.NewTreeView(),
+		summary:  tview.NewTextView(),
+		detail:   tview.NewTextView(),
+		filter:   tview.NewInputField(),
+		help:     tview.NewTextView(),
+		tester:   t,
+		main:     main,
+		coverage: newCoverageGauge(),
+	}
+
+	d.summary.SetDynamicColors(true)
+	d.summary.SetBorder(true)
+	d.summary.SetTitle(" falco test ")
+
+	d.tree.SetRoot(tview.NewTreeNode(main))
+	d.tree.SetBorder(true)
+	d.tree.SetTitle(" Tests ")
+	d.tree.SetChangedFunc(d.showDetail)
+
+	d.detail.SetDynamicColors(true)
+	d.detail.SetWrap(true)
+	d.detail.SetBorder(true)
+	d.detail.SetTitle(" Detail ")
+
+	d.filter.SetLabel("Filter: ")
+	d.filter.SetChangedFunc(func(text string) {
+		d.render()
+	})
+	d.filter.SetDoneFunc(func(key tcell.Key) {
+		if key == tcell.KeyEscape {
+			d.filter.SetText("")
+		}
+		d.app.SetFocus(d.tree)
+	})
+
+	d.help.SetDynamicColors(true)
+	d.help.SetText(helpText)
+
+	body := tview.NewFlex().
+		AddItem(d.tree, 0, 1, true).
+		AddItem(d.detail, 0, 1, false)
+	layout := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(d.summary, 5, 0, false).
+		AddItem(body, 0, 1, true).
+		AddItem(d.filter, 1, 0, false).
+		AddItem(d.help, 1, 0, false)
+
+	d.app.SetRoot(layout, true).SetFocus(d.tree)
+	d.app.SetInputCapture(d.keyEventHandler)
+
+	// Coverage markers are streamed from the tester to update the gauges live
+	t.AddCoverageSink(d.coverage)
+	d.coverage.onChange = func() {
+		d.app.QueueUpdateDraw(d.renderSummary)
+	}
+	return d
+}
+
+// Run starts testing in background and blocks until the dashboard is closed.
+// Returns true if all tests are passed
+func (d *Dashboard) Run() (bool, error) {
+	d.tester.OnResult(func(result *tester.TestResult) {
+		d.mu.Lock()
+		d.results = append(d.results, result)
+		d.mu.Unlock()
+		d.app.QueueUpdateDraw(d.render)
+	})
+
+	d.setRunning("Running tests...")
+	go func() {
+		_, err := d.tester.Run(d.main)
+		d.mu.Lock()
+		d.running = false
+		if err != nil {
+			d.status = "[red]Failed to run test: " + tview.Escape(err.Error()) + "[-]"
+		} else {
+			d.status = "Finished"
+		}
+		d.mu.Unlock()
+		d.app.QueueUpdateDraw(d.render)
+	}()
+
+	if err := d.app.Run(); err != nil {
+		return false, err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, r := range d.results {
+		if !r.IsPassed() {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func (d *Dashboard) setRunning(status string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.running {
+		return false
+	}
+	d.running = true
+	d.status = status
+	return true
+}
+
+func (d *Dashboard) keyEventHandler(evt *tcell.EventKey) *tcell.EventKey {
+	// Let the filter input handle keys while it is focused
+	if d.app.GetFocus() == d.filter {
+		return evt
+	}
+
+	switch {
+	case evt.Key() == tcell.KeyEscape:
+		if d.filter.GetText() != "" {
+			d.filter.SetText("")
+			return nil
+		}
+		d.app.Stop()
+		return nil
+	case evt.Rune() == 'q':
+		d.app.Stop()
+		return nil
+	case evt.Rune() == '/':
+		d.app.SetFocus(d.filter)
+		return nil
+	case evt.Rune() == 'r':
+		if node := d.tree.GetCurrentNode(); node != nil {
+			if ref, ok := node.GetReference().(*caseRef); ok {
+				d.rerun(ref)
+			}
+		}
+		return nil
+	}
+	return evt
+}
+
+// Re-run the test case in background and replace the result in the tree
+func (d *Dashboard) rerun(ref *caseRef) {
+	if !d.setRunning(fmt.Sprintf("Re-running %s...", ref.name)) {
+		return
+	}
+	d.renderSummary()
+
+	go func() {
+		result, err := d.tester.RunCase(ref.file, ref.group, ref.name)
+
+		d.mu.Lock()
+		d.running = false
+		if err != nil {
+			d.status = "[red]Failed to re-run test: " + tview.Escape(err.Error()) + "[-]"
+		} else {
+			d.status = fmt.Sprintf("Re-ran %s", ref.name)
+			d.replaceCases(ref, result.Cases)
+		}
+		d.mu.Unlock()
+		d.app.QueueUpdateDraw(func() {
+			d.render()
+			d.showDetail(d.tree.GetCurrentNode())
+		})
+	}()
+}
+
+// Replace cases which have the same name with re-ran cases, must be called with lock
+func (d *Dashboard) replaceCases(ref *caseRef, cases []*tester.TestCase) {
+	for _, r := range d.results {
+		if r.Filename != ref.file {
+			continue
+		}
+		var replaced []*tester.TestCase
+		inserted := false
+		for _, c := range r.Cases {
+			if c.Group != ref.group || c.Name != ref.name {
+				replaced = append(replaced, c)
+				continue
+			}
+			if !inserted {
+				replaced = append(replaced, cases...)
+				inserted = true
+			}
+		}
+		r.Cases = replaced
+	}
+}
+
+// Rebuild the tree with filter and keep the selected test case
+func (d *Dashboard) render() {
+	var selected string
+	if node := d.tree.GetCurrentNode(); node != nil {
+		if ref, ok := node.GetReference().(*caseRef); ok {
+			selected = ref.key()
+		}
+	}
+
+	filter := strings.ToLower(d.filter.GetText())
+	d.mu.Lock()
+	root := tview.NewTreeNode(d.main).SetSelectable(false)
+	current := root
+	for _, r := range d.results {
+		file := tview.NewTreeNode(relativePath(r.Filename)).SetSelectable(false)
+		for _, c := range r.Cases {
+			name := c.Name
+			if c.Group != "" {
+				name = c.Group + " › " + c.Name
+			}
+			if filter != "" && !strings.Contains(strings.ToLower(r.Filename+" "+name), filter) {
+				continue
+			}
+			ref := &caseRef{file: r.Filename, group: c.Group, name: c.Name, scope: c.Scope}
+			node := tview.NewTreeNode(fmt.Sprintf("%s %s [%s] (%dms)", caseMark(c), name, c.Scope, c.Time)).
+				SetReference(ref).
+				SetColor(caseColor(c))
+			file.AddChild(node)
+			if ref.key() == selected {
+				current = node
+			}
+		}
+		if len(file.GetChildren()) == 0 {
+			continue
+		}
+		if r.IsPassed() {
+			file.SetColor(tcell.ColorGreen)
+		} else {
+			file.SetColor(tcell.ColorRed)
+		}
+		root.AddChild(file)
+	}
+	d.renderSummaryLocked()
+	d.mu.Unlock()
+
+	// Changing current node calls showDetail which takes the lock
+	d.tree.SetRoot(root)
+	d.tree.SetCurrentNode(current)
+}
+
+func (d *Dashboard) renderSummary() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.renderSummaryLocked()
+}
+
+func (d *Dashboard) renderSummaryLocked() {
+	var passed, failed, skipped int
+	for _, r := range d.results {
+		for _, c := range r.Cases {
+			switch {
+			case c.Skip:
+				skipped++
+			case c.Error != nil:
+				failed++
+			default:
+				passed++
+			}
+		}
+	}
+
+	var buf strings.Builder
+	total := passed + failed
+	var ratio float64
+	if total > 0 {
+		ratio = float64(passed) / float64(total) * 100
+	}
+	buf.WriteString(fmt.Sprintf(
+		"Tests     %s [green]%d passed[-], [red]%d failed[-], [yellow]%d skipped[-]\n",
+		gauge(ratio, total > 0), passed, failed, skipped,
+	))
+	for _, t := range []shared.CoverageType{shared.CoverageTypeStatement, shared.CoverageTypeBranch, shared.CoverageTypeSubroutine} {
+		executed, total := d.coverage.Get(t)
+		var p float64
+		if total > 0 {
+			p = float64(executed) / float64(total) * 100
+		}
+		buf.WriteString(fmt.Sprintf("%-10s%s %d/%d", t.String(), gauge(p, total > 0), executed, total))
+		if t == shared.CoverageTypeStatement {
+			buf.WriteString("    " + d.status)
+		}
+		buf.WriteString("\n")
+	}
+	d.summary.SetText(strings.TrimRight(buf.String(), "\n"))
+}
+
+// Show error and logs of the selected test case
+func (d *Dashboard) showDetail(node *tview.TreeNode) {
+	if node == nil {
+		return
+	}
+	ref, ok := node.GetReference().(*caseRef)
+	if !ok {
+		d.detail.SetText("")
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, r := range d.results {
+		if r.Filename != ref.file {
+			continue
+		}
+		for _, c := range r.Cases {
+			if c.Group != ref.group || c.Name != ref.name || c.Scope != ref.scope {
+				continue
+			}
+			var buf strings.Builder
+			buf.WriteString(fmt.Sprintf("[::b]%s[::-]\n%s\n\n", tview.Escape(c.Name), tview.Escape(relativePath(r.Filename))))
+			switch {
+			case c.Skip:
+				buf.WriteString("[yellow]Skipped[-]\n")
+			case c.Error != nil:
+				buf.WriteString("[red]" + tview.Escape(c.Error.Error()) + "[-]\n")
+			default:
+				buf.WriteString("[green]Passed[-]\n")
+			}
+			if len(c.Logs) > 0 {
+				buf.WriteString("\n[yellow]Logs[-]\n")
+				for _, log := range c.Logs {
+					buf.WriteString(tview.Escape(log) + "\n")
+				}
+			}
+			d.detail.SetText(buf.String())
+			d.detail.ScrollToBeginning()
+			return
+		}
+	}
+}
+
+func caseMark(c *tester.TestCase) string {
+	switch {
+	case c.Skip:
+		return "-"
+	case c.Error != nil:
+		return "✗"
+	default:
+		return "✓"
+	}
+}
+
+func caseColor(c *tester.TestCase) tcell.Color {
+	switch {
+	case c.Skip:
+		return tcell.ColorYellow
+	case c.Error != nil:
+		return tcell.ColorRed
+	default:
+		return tcell.ColorGreen
+	}
+}
+
+// Render percent as the bar like "[green]██████[gray]░░░░[-]  60.0%"
+func gauge(percent float64, valid bool) string {
+	const width = 30
+	if !valid {
+		return "[gray]" + strings.Repeat("░", width) + "[-]      -"
+	}
+	filled := int(percent / 100 * width)
+	color := "red"
+	switch {
+	case percent >= 80:
+		color = "green"
+	case percent >= 50:
+		color = "yellow"
+	}
+	return fmt.Sprintf(
+		"[%s]%s[gray]%s[-] %5.1f%%",
+		color, strings.Repeat("█", filled), strings.Repeat("░", width-filled), percent,
+	)
+}
+
+func relativePath(file string) string {
+	if rel, err := filepath.Rel(".", file); err == nil {
+		return rel
+	}
+	return file
+}
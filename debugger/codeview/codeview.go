@@ -46,6 +46,11 @@ func (c *CodeView) SetFile(file string, line int) {
 	c.line = line
 }
 
+// SetSource stores provided source for the file instead of reading the file
+func (c *CodeView) SetSource(file, source string) {
+	c.lexerCaches[file] = c.lex(lexer.NewFromString(source, lexer.WithFile(file)))
+}
+
 func (c *CodeView) Draw(screen tcell.Screen) {
 	if c.file == "" {
 		c.Clear()
@@ -132,8 +137,10 @@ func (c *CodeView) lexFile(file string) ([]Line, error) {
 	}
 	defer fp.Close()
 
-	l := lexer.New(fp, lexer.WithFile(c.file))
+	return c.lex(lexer.New(fp, lexer.WithFile(file))), nil
+}
 
+func (c *CodeView) lex(l *lexer.Lexer) []Line {
 	var lines []Line
 	for {
 		tok := l.NextToken()
@@ -264,5 +271,5 @@ func (c *CodeView) lexFile(file string) ([]Line, error) {
 		lines = append(lines, line)
 	}
 
-	return lines, nil
+	return lines
 }
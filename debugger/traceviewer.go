@@ -0,0 +1,150 @@
+package debugger
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/ysugimoto/falco/v2/debugger/codeview"
+	"github.com/ysugimoto/falco/v2/debugger/colors"
+	"github.com/ysugimoto/falco/v2/trace"
+)
+
+// TraceViewer steps forward/backward through the recorded execution trace
+type TraceViewer struct {
+	app       *tview.Application
+	code      *codeview.CodeView
+	variables *tview.TextView
+	status    *tview.TextView
+	trace     *trace.Trace
+	index     int
+}
+
+func NewTraceViewer(t *trace.Trace) *TraceViewer {
+	code := codeview.New()
+	code.SetTitle(" VCL Trace Viewer ")
+	for file, source := range t.Sources {
+		code.SetSource(file, source)
+	}
+
+	variables := tview.NewTextView().
+		SetDynamicColors(true).
+		SetScrollable(true)
+	variables.SetBackgroundColor(colors.Background)
+	variables.SetBorder(true)
+	variables.SetTitle(" Variables ")
+
+	status := tview.NewTextView().
+		SetDynamicColors(true)
+	status.SetBackgroundColor(colors.Background)
+
+	grid := tview.NewGrid().
+		SetRows(0, 14, 1).
+		SetBorders(false).
+		SetGap(0, 0).
+		SetOffset(0, 0)
+
+	grid.AddItem(code, 0, 0, 1, 1, 0, 0, false)
+	grid.AddItem(variables, 1, 0, 1, 1, 0, 0, false)
+	grid.AddItem(status, 2, 0, 1, 1, 0, 0, false)
+	grid.SetBackgroundColor(colors.Background)
+
+	return &TraceViewer{
+		app:       tview.NewApplication().SetRoot(grid, true),
+		code:      code,
+		variables: variables,
+		status:    status,
+		trace:     t,
+	}
+}
+
+func (v *TraceViewer) keyEventHandler(evt *tcell.EventKey) *tcell.EventKey {
+	switch evt.Key() {
+	case tcell.KeyEscape:
+		v.app.Stop()
+	case tcell.KeyRight:
+		v.move(v.index + 1)
+	case tcell.KeyLeft:
+		v.move(v.index - 1)
+	case tcell.KeyHome:
+		v.move(0)
+	case tcell.KeyEnd:
+		v.move(len(v.trace.Steps) - 1)
+	case tcell.KeyRune:
+		switch evt.Rune() {
+		case 'q':
+			v.app.Stop()
+		case 'n', 'l':
+			v.move(v.index + 1)
+		case 'p', 'h':
+			v.move(v.index - 1)
+		case 'g':
+			v.move(0)
+		case 'G':
+			v.move(len(v.trace.Steps) - 1)
+		}
+	}
+	return evt
+}
+
+func (v *TraceViewer) move(index int) {
+	if index < 0 || index >= len(v.trace.Steps) {
+		return
+	}
+	v.index = index
+	v.render()
+}
+
+func (v *TraceViewer) render() {
+	step := v.trace.Steps[v.index]
+	v.code.SetFile(step.File, step.Line)
+
+	v.status.Clear()
+	fmt.Fprintf(
+		v.status,
+		"%s %s %s | %s",
+		colors.Bold(fmt.Sprintf(" Step %d/%d", v.index+1, len(v.trace.Steps))),
+		step.Scope,
+		step.Subroutine,
+		tview.Escape("[←/p] Prev | [→/n] Next | [Home/g] First | [End/G] Last | [q] Quit"),
+	)
+
+	v.variables.Clear()
+	if v.index == len(v.trace.Steps)-1 && v.trace.Error != "" {
+		fmt.Fprintln(v.variables, colors.Red(tview.Escape("Error: "+v.trace.Error)))
+	}
+	if len(step.Changes) > 0 {
+		fmt.Fprintln(v.variables, colors.Bold("Accesses in this step:"))
+		for _, c := range step.Changes {
+			fmt.Fprintf(
+				v.variables,
+				"  %s %s\n",
+				colors.Yellow(tview.Escape("["+c.Kind+"]")),
+				tview.Escape(fmt.Sprintf("%s %q", c.Name, c.Value)),
+			)
+		}
+	}
+
+	// Display variable values at the time before the step is processed
+	fmt.Fprintln(v.variables, colors.Bold("Variables before this step:"))
+	snapshot := v.trace.Snapshot(v.index)
+	names := make([]string, 0, len(snapshot))
+	for name := range snapshot {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintln(v.variables, "  "+tview.Escape(fmt.Sprintf("%s %q", name, snapshot[name])))
+	}
+	v.variables.ScrollToBeginning()
+}
+
+func (v *TraceViewer) Run() error {
+	if len(v.trace.Steps) == 0 {
+		return fmt.Errorf("trace %s does not have any steps", v.trace.Name)
+	}
+	v.app.SetInputCapture(v.keyEventHandler)
+	v.render()
+	return v.app.Run()
+}
@@ -0,0 +1,120 @@
+// Package dialect defines the VCL flavors which falco can parse and lint.
+// Fastly VCL is the primary dialect, and Varnish open-source VCL 4.x is supported in best-effort mode
+// to report Fastly specific constructs for the teams which maintain both flavors.
+package dialect
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ysugimoto/falco/v2/parser"
+)
+
+const (
+	NameFastly  = "fastly"
+	NameVarnish = "varnish"
+)
+
+// Capability is the flag set of VCL constructs which the dialect accepts
+type Capability uint32
+
+const (
+	Table Capability = 1 << iota
+	Penaltybox
+	Ratecounter
+	Director
+	Const
+	Goto
+	Switch
+	DeclareLocal
+	ErrorStatement
+	EsiStatement
+	AddStatement
+	RemoveStatement
+	SyntheticBase64
+	UserDefinedFunction
+	BoilerplateMacro
+	VersionDeclaration
+	ObjectInstantiation
+	ProbeDeclaration
+)
+
+var capabilityNames = map[Capability]string{
+	Table:               "table declaration",
+	Penaltybox:          "penaltybox declaration",
+	Ratecounter:         "ratecounter declaration",
+	Director:            "director declaration",
+	Const:               "const declaration",
+	Goto:                "goto statement",
+	Switch:              "switch statement",
+	DeclareLocal:        "declare local statement",
+	ErrorStatement:      "error statement",
+	EsiStatement:        "esi statement",
+	AddStatement:        "add statement",
+	RemoveStatement:     "remove statement",
+	SyntheticBase64:     "synthetic.base64 statement",
+	UserDefinedFunction: "subroutine parameters and return type",
+	BoilerplateMacro:    "#FASTLY boilerplate macro",
+	VersionDeclaration:  "vcl version declaration",
+	ObjectInstantiation: "new statement",
+	ProbeDeclaration:    "probe declaration",
+}
+
+func (c Capability) String() string {
+	if v, ok := capabilityNames[c]; ok {
+		return v
+	}
+	return fmt.Sprintf("capability(%d)", uint32(c))
+}
+
+type Dialect struct {
+	Name         string
+	Capabilities Capability
+	parsers      []parser.CustomParser
+}
+
+// Has returns true if the dialect accepts the construct
+func (d *Dialect) Has(c Capability) bool {
+	return d.Capabilities&c == c
+}
+
+func (d *Dialect) IsFastly() bool {
+	return d.Name == NameFastly
+}
+
+// ParserOptions returns the parser options to parse VCL written in the dialect
+func (d *Dialect) ParserOptions() []parser.ParserOption {
+	if len(d.parsers) == 0 {
+		return nil
+	}
+	return []parser.ParserOption{parser.WithCustomParser(d.parsers...)}
+}
+
+var Fastly = &Dialect{
+	Name: NameFastly,
+	Capabilities: Table | Penaltybox | Ratecounter | Director | Const | Goto | Switch | DeclareLocal |
+		ErrorStatement | EsiStatement | AddStatement | RemoveStatement | SyntheticBase64 |
+		UserDefinedFunction | BoilerplateMacro,
+}
+
+var Varnish = &Dialect{
+	Name:         NameVarnish,
+	Capabilities: VersionDeclaration | ObjectInstantiation | ProbeDeclaration,
+	parsers: []parser.CustomParser{
+		&VersionParser{},
+		&ObjectParser{},
+		&ProbeParser{},
+	},
+}
+
+// Get returns the dialect for the name, Fastly dialect is returned for empty name
+func Get(name string) (*Dialect, error) {
+	switch strings.ToLower(name) {
+	case "", NameFastly:
+		return Fastly, nil
+	case NameVarnish:
+		return Varnish, nil
+	default:
+		return nil, fmt.Errorf(`Unknown dialect "%s", expects one of "%s" or "%s"`, name, NameFastly, NameVarnish)
+	}
+}
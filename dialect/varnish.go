@@ -0,0 +1,209 @@
+package dialect
+
+import (
+	"bytes"
+
+	"github.com/pkg/errors"
+	"github.com/ysugimoto/falco/v2/ast"
+	"github.com/ysugimoto/falco/v2/parser"
+	"github.com/ysugimoto/falco/v2/token"
+)
+
+// VersionStatement is the Varnish VCL version declaration like "vcl 4.1;"
+type VersionStatement struct {
+	*ast.Meta
+	Version string
+}
+
+func (s *VersionStatement) ID() uint64 { return s.Meta.ID }
+func (s *VersionStatement) Statement() {}
+func (s *VersionStatement) Literal() string {
+	return "vcl"
+}
+func (s *VersionStatement) GetMeta() *ast.Meta {
+	return s.Meta
+}
+func (s *VersionStatement) String() string {
+	var buf bytes.Buffer
+
+	buf.WriteString(s.LeadingComment("\n"))
+	buf.WriteString("vcl " + s.Version + ";")
+	buf.WriteString(s.TrailingComment(" "))
+	buf.WriteString("\n")
+
+	return buf.String()
+}
+func (s *VersionStatement) Lint(nodeLinter func(node ast.Node)) error {
+	return nil
+}
+
+type VersionParser struct{}
+
+func (v *VersionParser) Ident() string {
+	return "vcl"
+}
+func (v *VersionParser) Token() token.TokenType {
+	return token.Custom("VCL")
+}
+func (v *VersionParser) Parse(p *parser.Parser) (ast.CustomStatement, error) {
+	stmt := &VersionStatement{
+		Meta: p.CurToken(),
+	}
+	if !p.ExpectPeek(token.FLOAT) {
+		return nil, errors.WithStack(parser.UnexpectedToken(p.PeekToken(), "FLOAT"))
+	}
+	stmt.Version = p.CurToken().Token.Literal
+
+	if !p.PeekTokenIs(token.SEMICOLON) {
+		return nil, errors.WithStack(parser.MissingSemicolon(p.CurToken()))
+	}
+	stmt.EndLine = p.CurToken().Token.Line
+	stmt.EndPosition = p.CurToken().Token.Position + len(stmt.Version) - 1
+
+	p.NextToken() // point to SEMICOLON
+	stmt.Trailing = p.Trailing()
+
+	return stmt, nil
+}
+
+// ObjectStatement is the VMOD object instantiation in vcl_init like "new vdir = directors.round_robin();"
+type ObjectStatement struct {
+	*ast.Meta
+	Name  *ast.Ident
+	Value ast.Expression
+}
+
+func (s *ObjectStatement) ID() uint64 { return s.Meta.ID }
+func (s *ObjectStatement) Statement() {}
+func (s *ObjectStatement) Literal() string {
+	return "new"
+}
+func (s *ObjectStatement) GetMeta() *ast.Meta {
+	return s.Meta
+}
+func (s *ObjectStatement) String() string {
+	var buf bytes.Buffer
+
+	buf.WriteString(s.LeadingComment("\n"))
+	buf.WriteString("new " + s.Name.String() + " = " + s.Value.String() + ";")
+	buf.WriteString(s.TrailingComment(" "))
+	buf.WriteString("\n")
+
+	return buf.String()
+}
+func (s *ObjectStatement) Lint(nodeLinter func(node ast.Node)) error {
+	return nil
+}
+
+type ObjectParser struct{}
+
+func (o *ObjectParser) Ident() string {
+	return "new"
+}
+func (o *ObjectParser) Token() token.TokenType {
+	return token.Custom("NEW")
+}
+func (o *ObjectParser) Parse(p *parser.Parser) (ast.CustomStatement, error) {
+	stmt := &ObjectStatement{
+		Meta: p.CurToken(),
+	}
+	if !p.ExpectPeek(token.IDENT) {
+		return nil, errors.WithStack(parser.UnexpectedToken(p.PeekToken(), "IDENT"))
+	}
+	stmt.Name = p.ParseIdent()
+
+	if !p.ExpectPeek(token.ASSIGN) {
+		return nil, errors.WithStack(parser.UnexpectedToken(p.PeekToken(), "ASSIGN"))
+	}
+	p.NextToken() // point to expression
+
+	value, err := p.ParseExpression(parser.LOWEST)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	stmt.Value = value
+
+	if !p.PeekTokenIs(token.SEMICOLON) {
+		return nil, errors.WithStack(parser.MissingSemicolon(p.CurToken()))
+	}
+	stmt.EndLine = value.GetMeta().EndLine
+	stmt.EndPosition = value.GetMeta().EndPosition
+
+	p.NextToken() // point to SEMICOLON
+	stmt.Trailing = p.Trailing()
+
+	return stmt, nil
+}
+
+// ProbeStatement is the named health check declaration which is referenced from backends
+type ProbeStatement struct {
+	*ast.Meta
+	Name       *ast.Ident
+	Properties []*ast.BackendProperty
+}
+
+func (d *ProbeStatement) ID() uint64 { return d.Meta.ID }
+func (d *ProbeStatement) Statement() {}
+func (d *ProbeStatement) Literal() string {
+	return "probe"
+}
+func (d *ProbeStatement) GetMeta() *ast.Meta {
+	return d.Meta
+}
+func (d *ProbeStatement) String() string {
+	var buf bytes.Buffer
+
+	buf.WriteString(d.LeadingComment("\n"))
+	buf.WriteString("probe " + d.Name.String() + " {\n")
+	for _, prop := range d.Properties {
+		buf.WriteString(prop.String() + "\n")
+	}
+	buf.WriteString(d.InfixComment("\n"))
+	buf.WriteString("}")
+	buf.WriteString(d.TrailingComment(" "))
+	buf.WriteString("\n")
+
+	return buf.String()
+}
+func (d *ProbeStatement) Lint(nodeLinter func(node ast.Node)) error {
+	return nil
+}
+
+type ProbeParser struct{}
+
+func (pp *ProbeParser) Ident() string {
+	return "probe"
+}
+func (pp *ProbeParser) Token() token.TokenType {
+	return token.Custom("PROBE")
+}
+func (pp *ProbeParser) Parse(p *parser.Parser) (ast.CustomStatement, error) {
+	decl := &ProbeStatement{
+		Meta: p.CurToken(),
+	}
+	if !p.ExpectPeek(token.IDENT) {
+		return nil, errors.WithStack(parser.UnexpectedToken(p.PeekToken(), "IDENT"))
+	}
+	decl.Name = p.ParseIdent()
+
+	if !p.ExpectPeek(token.LEFT_BRACE) {
+		return nil, errors.WithStack(parser.UnexpectedToken(p.PeekToken(), "LEFT_BRACE"))
+	}
+	parser.SwapLeadingTrailing(p.CurToken(), decl.Name.Meta)
+
+	for !p.PeekTokenIs(token.RIGHT_BRACE) {
+		prop, err := p.ParseBackendProperty()
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		decl.Properties = append(decl.Properties, prop)
+	}
+
+	parser.SwapLeadingInfix(p.PeekToken(), decl.Meta)
+	p.NextToken() // point to RIGHT_BRACE
+	decl.Trailing = p.Trailing()
+	decl.EndLine = p.CurToken().Token.Line
+	decl.EndPosition = p.CurToken().Token.Position
+
+	return decl, nil
+}
@@ -0,0 +1,110 @@
+package dialect
+
+import (
+	"testing"
+
+	"github.com/ysugimoto/falco/v2/ast"
+	"github.com/ysugimoto/falco/v2/lexer"
+	"github.com/ysugimoto/falco/v2/parser"
+)
+
+func TestParseVarnishVCL(t *testing.T) {
+	input := `vcl 4.1;
+
+import directors;
+
+probe healthcheck {
+  .url = "/health";
+  .interval = 5s;
+}
+
+backend server1 {
+  .host = "127.0.0.1";
+  .port = "8080";
+  .probe = healthcheck;
+}
+
+sub vcl_init {
+  new vdir = directors.round_robin();
+  vdir.add_backend(server1);
+}
+
+sub vcl_recv {
+  set req.backend_hint = vdir.backend();
+  if (req.method == "PURGE") {
+    return (synth(405, "Not allowed"));
+  }
+}
+`
+	vcl, err := parser.New(lexer.NewFromString(input), Varnish.ParserOptions()...).ParseVCL()
+	if err != nil {
+		t.Errorf("Unexpected parse error: %s", err)
+		return
+	}
+	if len(vcl.Statements) != 6 {
+		t.Errorf("Expect 6 statements but got %d", len(vcl.Statements))
+		return
+	}
+
+	version, ok := vcl.Statements[0].(*VersionStatement)
+	if !ok {
+		t.Errorf("Expect VersionStatement but got %T", vcl.Statements[0])
+	} else if version.Version != "4.1" {
+		t.Errorf(`Expect version "4.1" but got "%s"`, version.Version)
+	}
+
+	probe, ok := vcl.Statements[2].(*ProbeStatement)
+	if !ok {
+		t.Errorf("Expect ProbeStatement but got %T", vcl.Statements[2])
+	} else if probe.Name.Value != "healthcheck" || len(probe.Properties) != 2 {
+		t.Errorf("Unexpected probe declaration: %s", probe.String())
+	}
+
+	sub, ok := vcl.Statements[4].(*ast.SubroutineDeclaration)
+	if !ok {
+		t.Errorf("Expect SubroutineDeclaration but got %T", vcl.Statements[4])
+		return
+	}
+	object, ok := sub.Block.Statements[0].(*ObjectStatement)
+	if !ok {
+		t.Errorf("Expect ObjectStatement but got %T", sub.Block.Statements[0])
+	} else if object.Name.Value != "vdir" {
+		t.Errorf(`Expect object name "vdir" but got "%s"`, object.Name.Value)
+	}
+}
+
+func TestParseVarnishSyntaxInFastlyDialect(t *testing.T) {
+	_, err := parser.New(lexer.NewFromString("vcl 4.1;"), Fastly.ParserOptions()...).ParseVCL()
+	if err == nil {
+		t.Errorf("Expect parse error for Varnish syntax in Fastly dialect")
+	}
+}
+
+func TestGet(t *testing.T) {
+	tests := []struct {
+		name   string
+		expect *Dialect
+		err    bool
+	}{
+		{name: "", expect: Fastly},
+		{name: "fastly", expect: Fastly},
+		{name: "Varnish", expect: Varnish},
+		{name: "unknown", err: true},
+	}
+	for _, tt := range tests {
+		d, err := Get(tt.name)
+		if tt.err {
+			if err == nil {
+				t.Errorf("Expect error for %s", tt.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Unexpected error for %s: %s", tt.name, err)
+			continue
+		}
+		if d != tt.expect {
+			t.Errorf("Expect %s dialect but got %s", tt.expect.Name, d.Name)
+		}
+	}
+}
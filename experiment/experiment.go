@@ -0,0 +1,149 @@
+// Package experiment compiles edge-side A/B experiment declarations into canonical VCL.
+// Each experiment assigns the variant by consistent hashing on the bucketing key,
+// so the same key is always assigned to the same variant in both Fastly and falco simulator
+package experiment
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/go-yaml/yaml"
+	"github.com/pkg/errors"
+)
+
+const (
+	// Prefix of the functional subroutine which returns assigned variant of the experiment
+	SubroutinePrefix = "experiment_"
+	// Subroutine which assigns variants of all experiments to request headers
+	AssignSubroutine = "experiments_assign"
+)
+
+var validName = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
+
+type Variant struct {
+	Name   string `yaml:"name"`
+	Weight int    `yaml:"weight"`
+}
+
+// Experiment is the declaration of A/B experiment.
+// Key is the VCL expression of the bucketing key like req.http.Cookie:uid,
+// and the first variant is assigned when the key is empty
+type Experiment struct {
+	Name     string     `yaml:"name"`
+	Key      string     `yaml:"key"`
+	Header   string     `yaml:"header"`
+	Variants []*Variant `yaml:"variants"`
+}
+
+type file struct {
+	Experiments []*Experiment `yaml:"experiments"`
+}
+
+// Load loads experiment declaration files, experiment names must be unique across files
+func Load(files ...string) ([]*Experiment, error) {
+	var experiments []*Experiment
+	names := make(map[string]struct{})
+
+	for _, f := range files {
+		buf, err := os.ReadFile(f)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		var loaded file
+		if err := yaml.Unmarshal(buf, &loaded); err != nil {
+			return nil, errors.Wrapf(err, "failed to parse experiment file %s", f)
+		}
+		for _, e := range loaded.Experiments {
+			if err := e.validate(); err != nil {
+				return nil, errors.Wrapf(err, "invalid experiment file %s", f)
+			}
+			if _, ok := names[e.Name]; ok {
+				return nil, errors.Errorf("experiment %s is declared multiple times", e.Name)
+			}
+			names[e.Name] = struct{}{}
+			experiments = append(experiments, e)
+		}
+	}
+	return experiments, nil
+}
+
+func (e *Experiment) validate() error {
+	if !validName.MatchString(e.Name) {
+		return errors.Errorf("invalid experiment name %q", e.Name)
+	}
+	if strings.TrimSpace(e.Key) == "" {
+		return errors.Errorf("bucketing key of experiment %s is not specified", e.Name)
+	}
+	if len(e.Variants) < 2 {
+		return errors.Errorf("experiment %s must have at least two variants", e.Name)
+	}
+	variants := make(map[string]struct{})
+	for _, v := range e.Variants {
+		if v.Name == "" || strings.Contains(v.Name, `"`) {
+			return errors.Errorf("invalid variant name %q of experiment %s", v.Name, e.Name)
+		}
+		if _, ok := variants[v.Name]; ok {
+			return errors.Errorf("variant %s of experiment %s is declared multiple times", v.Name, e.Name)
+		}
+		variants[v.Name] = struct{}{}
+		if v.Weight <= 0 {
+			return errors.Errorf("weight of variant %s of experiment %s must be positive", v.Name, e.Name)
+		}
+	}
+	return nil
+}
+
+// Request header which the assigned variant is set to, X-Experiment-{name} as default
+func (e *Experiment) header() string {
+	if e.Header != "" {
+		return e.Header
+	}
+	return "X-Experiment-" + e.Name
+}
+
+// Compile generates the functional subroutine for each experiment and the subroutine which assigns all variants.
+// The bucket is calculated from the first 32 bits of SHA256 hash of the experiment name and the key,
+// so variants of different experiments are assigned independently
+func Compile(experiments []*Experiment) string {
+	var buf strings.Builder
+	buf.WriteString("# Generated by falco experiment, DO NOT EDIT\n")
+
+	for _, e := range experiments {
+		var total int
+		for _, v := range e.Variants {
+			total += v.Weight
+		}
+
+		buf.WriteString(fmt.Sprintf("\nsub %s%s STRING {\n", SubroutinePrefix, e.Name))
+		buf.WriteString("  declare local var.key STRING;\n")
+		buf.WriteString("  declare local var.bucket INTEGER;\n")
+		buf.WriteString(fmt.Sprintf("  set var.key = %s;\n", e.Key))
+		buf.WriteString("  if (std.strlen(var.key) == 0) {\n")
+		buf.WriteString(fmt.Sprintf("    return \"%s\";\n", e.Variants[0].Name))
+		buf.WriteString("  }\n")
+		buf.WriteString(fmt.Sprintf(
+			"  set var.bucket = std.strtol(substr(digest.hash_sha256(\"%s\" + var.key), 0, 8), 16);\n", e.Name+":",
+		))
+		buf.WriteString(fmt.Sprintf("  set var.bucket %%= %d;\n", total))
+
+		var threshold int
+		last := len(e.Variants) - 1
+		for _, v := range e.Variants[:last] {
+			threshold += v.Weight
+			buf.WriteString(fmt.Sprintf("  if (var.bucket < %d) {\n", threshold))
+			buf.WriteString(fmt.Sprintf("    return \"%s\";\n", v.Name))
+			buf.WriteString("  }\n")
+		}
+		buf.WriteString(fmt.Sprintf("  return \"%s\";\n", e.Variants[last].Name))
+		buf.WriteString("}\n")
+	}
+
+	buf.WriteString(fmt.Sprintf("\nsub %s {\n", AssignSubroutine))
+	for _, e := range experiments {
+		buf.WriteString(fmt.Sprintf("  set req.http.%s = %s%s();\n", e.header(), SubroutinePrefix, e.Name))
+	}
+	buf.WriteString("}\n")
+	return buf.String()
+}
@@ -0,0 +1,182 @@
+package experiment
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/ysugimoto/falco/v2/lexer"
+	"github.com/ysugimoto/falco/v2/parser"
+)
+
+func writeExperiment(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "experiments.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write experiment: %s", err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		isError bool
+	}{
+		{
+			name: "valid experiment",
+			content: `
+experiments:
+  - name: checkout
+    key: req.http.Cookie:uid
+    variants:
+      - name: control
+        weight: 90
+      - name: new_flow
+        weight: 10
+`,
+		},
+		{
+			name: "invalid name",
+			content: `
+experiments:
+  - name: check-out
+    key: req.http.Cookie:uid
+    variants: [{name: a, weight: 1}, {name: b, weight: 1}]
+`,
+			isError: true,
+		},
+		{
+			name: "key is not specified",
+			content: `
+experiments:
+  - name: checkout
+    variants: [{name: a, weight: 1}, {name: b, weight: 1}]
+`,
+			isError: true,
+		},
+		{
+			name: "single variant",
+			content: `
+experiments:
+  - name: checkout
+    key: client.identity
+    variants: [{name: a, weight: 1}]
+`,
+			isError: true,
+		},
+		{
+			name: "zero weight",
+			content: `
+experiments:
+  - name: checkout
+    key: client.identity
+    variants: [{name: a, weight: 1}, {name: b, weight: 0}]
+`,
+			isError: true,
+		},
+		{
+			name: "duplicated experiment",
+			content: `
+experiments:
+  - name: checkout
+    key: client.identity
+    variants: [{name: a, weight: 1}, {name: b, weight: 1}]
+  - name: checkout
+    key: client.identity
+    variants: [{name: a, weight: 1}, {name: b, weight: 1}]
+`,
+			isError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(writeExperiment(t, tt.content))
+			if tt.isError {
+				if err == nil {
+					t.Errorf("Expected error but got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("Unexpected error: %s", err)
+			}
+		})
+	}
+}
+
+func TestCompile(t *testing.T) {
+	experiments := []*Experiment{
+		{
+			Name: "checkout",
+			Key:  "req.http.Cookie:uid",
+			Variants: []*Variant{
+				{Name: "control", Weight: 50},
+				{Name: "new_flow", Weight: 30},
+				{Name: "legacy", Weight: 20},
+			},
+		},
+		{
+			Name:   "banner",
+			Key:    "client.identity",
+			Header: "X-Banner",
+			Variants: []*Variant{
+				{Name: "off", Weight: 1},
+				{Name: "on", Weight: 1},
+			},
+		},
+	}
+
+	expect := strings.TrimLeft(`
+# Generated by falco experiment, DO NOT EDIT
+
+sub experiment_checkout STRING {
+  declare local var.key STRING;
+  declare local var.bucket INTEGER;
+  set var.key = req.http.Cookie:uid;
+  if (std.strlen(var.key) == 0) {
+    return "control";
+  }
+  set var.bucket = std.strtol(substr(digest.hash_sha256("checkout:" + var.key), 0, 8), 16);
+  set var.bucket %= 100;
+  if (var.bucket < 50) {
+    return "control";
+  }
+  if (var.bucket < 80) {
+    return "new_flow";
+  }
+  return "legacy";
+}
+
+sub experiment_banner STRING {
+  declare local var.key STRING;
+  declare local var.bucket INTEGER;
+  set var.key = client.identity;
+  if (std.strlen(var.key) == 0) {
+    return "off";
+  }
+  set var.bucket = std.strtol(substr(digest.hash_sha256("banner:" + var.key), 0, 8), 16);
+  set var.bucket %= 2;
+  if (var.bucket < 1) {
+    return "off";
+  }
+  return "on";
+}
+
+sub experiments_assign {
+  set req.http.X-Experiment-checkout = experiment_checkout();
+  set req.http.X-Banner = experiment_banner();
+}
+`, "\n")
+
+	compiled := Compile(experiments)
+	if diff := cmp.Diff(expect, compiled); diff != "" {
+		t.Errorf("Compiled VCL mismatch, diff=%s", diff)
+	}
+	if _, err := parser.New(lexer.NewFromString(compiled)).ParseVCL(); err != nil {
+		t.Errorf("Compiled VCL could not be parsed: %s", err)
+	}
+}
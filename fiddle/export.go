@@ -0,0 +1,249 @@
+package fiddle
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/ysugimoto/falco/v2/ast"
+	"github.com/ysugimoto/falco/v2/config"
+	"github.com/ysugimoto/falco/v2/constant"
+	"github.com/ysugimoto/falco/v2/formatter"
+	"github.com/ysugimoto/falco/v2/resolver"
+)
+
+// ParseFunc parses resolved VCL, caller could report parse error in its own way
+type ParseFunc func(v *resolver.VCL) (*ast.VCL, error)
+
+var fastlyMacroPattern = regexp.MustCompile(`^\s*#\s*FASTLY\b`)
+
+type Exporter struct {
+	parse  ParseFunc
+	conf   *config.FormatConfig
+	indent string
+}
+
+func NewExporter(parse ParseFunc, conf *config.FormatConfig) *Exporter {
+	indent := strings.Repeat(" ", conf.IndentWidth)
+	if conf.IndentStyle == "tab" {
+		indent = "\t"
+	}
+	return &Exporter{
+		parse:  parse,
+		conf:   conf,
+		indent: indent,
+	}
+}
+
+// Export packages the main VCL and its includes into fiddle.
+// Includes are flattened, constants are expanded because Fastly does not recognize them,
+// and backends are converted to origins so that references are renamed to fiddle backend names
+func (e *Exporter) Export(rslv resolver.Resolver, requests []*Request) (*Fiddle, error) {
+	main, err := rslv.MainVCL()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	statements, err := flatten(rslv, e.parse, main, map[string]struct{}{})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	vcl := &ast.VCL{Statements: statements}
+
+	constants, err := constant.Collect(vcl)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	f := &Fiddle{
+		Type:     "vcl",
+		Origins:  []string{},
+		Src:      &Src{},
+		Requests: requests,
+	}
+	if len(f.Requests) == 0 {
+		f.Requests = DefaultRequests()
+	}
+
+	// Backend references are renamed through the constant expansion with fiddle backend names
+	backends := map[string]string{}
+	for _, stmt := range vcl.Statements {
+		decl, ok := stmt.(*ast.BackendDeclaration)
+		if !ok {
+			continue
+		}
+		origin, err := originURL(decl)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		name := fmt.Sprintf("%s%d", originPrefix, len(f.Origins))
+		backends[decl.Name.Value] = name
+		constants[decl.Name.Value] = &ast.ConstDeclaration{
+			Meta:  decl.Meta,
+			Name:  decl.Name,
+			Value: &ast.Ident{Meta: decl.Name.Meta, Value: name},
+		}
+		f.Origins = append(f.Origins, origin)
+	}
+	constants.Expand(vcl)
+
+	var init []string
+	for _, stmt := range vcl.Statements {
+		switch t := stmt.(type) {
+		case *ast.BackendDeclaration:
+			continue
+		case *ast.DirectorDeclaration:
+			renameDirectorBackends(t, backends)
+		case *ast.SubroutineDeclaration:
+			if scope := strings.TrimPrefix(t.Name.Value, "vcl_"); scope != t.Name.Value && isScope(scope) {
+				body, err := e.subroutineBody(t)
+				if err != nil {
+					return nil, errors.WithStack(err)
+				}
+				f.Src.Set(scope, body)
+				continue
+			}
+		}
+		formatted, err := e.format(stmt)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		init = append(init, formatted)
+	}
+	f.Src.Init = strings.Join(init, "\n\n")
+
+	return f, nil
+}
+
+// Replace include statements with the statements of included VCL recursively.
+// The same module is included only once like Fastly does
+func flatten(
+	rslv resolver.Resolver,
+	parse ParseFunc,
+	v *resolver.VCL,
+	seen map[string]struct{},
+) ([]ast.Statement, error) {
+	vcl, err := parse(v)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	var statements []ast.Statement
+	for _, stmt := range vcl.Statements {
+		include, ok := stmt.(*ast.IncludeStatement)
+		if !ok {
+			statements = append(statements, stmt)
+			continue
+		}
+		if _, ok := seen[include.Module.Value]; ok {
+			continue
+		}
+		seen[include.Module.Value] = struct{}{}
+
+		module, err := rslv.Resolve(include)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		included, err := flatten(rslv, parse, module, seen)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		statements = append(statements, included...)
+	}
+	return statements, nil
+}
+
+// Build origin URL from backend properties, fiddle only accepts host, port and TLS settings
+func originURL(decl *ast.BackendDeclaration) (string, error) {
+	var host, port string
+	var ssl bool
+	for _, prop := range decl.Properties {
+		switch prop.Key.Value {
+		case "host":
+			if v, ok := prop.Value.(*ast.String); ok {
+				host = v.Value
+			}
+		case "port":
+			if v, ok := prop.Value.(*ast.String); ok {
+				port = v.Value
+			}
+		case "ssl":
+			if v, ok := prop.Value.(*ast.Boolean); ok {
+				ssl = v.Value
+			}
+		}
+	}
+	if host == "" {
+		return "", fmt.Errorf(`Backend "%s" does not have .host property`, decl.Name.Value)
+	}
+
+	scheme := "http"
+	if ssl || port == "443" {
+		scheme = "https"
+	}
+	if port == "" || (scheme == "https" && port == "443") || (scheme == "http" && port == "80") {
+		return scheme + "://" + host, nil
+	}
+	return scheme + "://" + host + ":" + port, nil
+}
+
+func renameDirectorBackends(decl *ast.DirectorDeclaration, backends map[string]string) {
+	for _, prop := range decl.Properties {
+		obj, ok := prop.(*ast.DirectorBackendObject)
+		if !ok {
+			continue
+		}
+		for _, v := range obj.Values {
+			if v.Key.Value != "backend" {
+				continue
+			}
+			if ident, ok := v.Value.(*ast.Ident); ok {
+				if name, ok := backends[ident.Value]; ok {
+					ident.Value = name
+				}
+			}
+		}
+	}
+}
+
+func isScope(scope string) bool {
+	for _, s := range Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *Exporter) format(stmt ast.Statement) (string, error) {
+	b, err := io.ReadAll(formatter.New(e.conf).Format(&ast.VCL{
+		Statements: []ast.Statement{stmt},
+	}))
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	return strings.TrimSpace(string(b)), nil
+}
+
+// Format subroutine and take the statements without the indentation of block and #FASTLY macro
+// because fiddle puts the section into the subroutine with boilerplate
+func (e *Exporter) subroutineBody(sub *ast.SubroutineDeclaration) (string, error) {
+	formatted, err := e.format(sub)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	lines := strings.Split(formatted, "\n")
+	if len(lines) < 3 {
+		return "", nil
+	}
+
+	var body []string
+	for _, line := range lines[1 : len(lines)-1] {
+		if fastlyMacroPattern.MatchString(line) {
+			continue
+		}
+		body = append(body, strings.TrimPrefix(line, e.indent))
+	}
+	return strings.TrimSpace(strings.Join(body, "\n")), nil
+}
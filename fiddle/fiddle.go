@@ -0,0 +1,226 @@
+// Package fiddle converts local VCL project from/to Fastly Fiddle format.
+// Fiddle holds the boilerplate subroutine bodies separately and declares backends as origin URLs,
+// so export flattens the project into the sections and import restores the main VCL from them
+package fiddle
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"encoding/json"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	fiddleBaseUrl = "https://fiddle.fastly.dev"
+
+	// Fiddle names backends from the position of origins
+	originPrefix = "F_origin_"
+)
+
+// Scopes are the section names of Fiddle VCL in lifecycle order
+var Scopes = []string{"recv", "hash", "hit", "miss", "pass", "fetch", "error", "deliver", "log"}
+
+type Fiddle struct {
+	ID       string     `json:"id,omitempty"`
+	Title    string     `json:"title,omitempty"`
+	Type     string     `json:"type"`
+	Origins  []string   `json:"origins"`
+	Src      *Src       `json:"src"`
+	Requests []*Request `json:"requests"`
+}
+
+// Src is the VCL source of fiddle, init section holds declarations and user defined subroutines,
+// and others hold the body of lifecycle subroutines without #FASTLY macro
+type Src struct {
+	Init    string `json:"init"`
+	Recv    string `json:"recv"`
+	Hash    string `json:"hash"`
+	Hit     string `json:"hit"`
+	Miss    string `json:"miss"`
+	Pass    string `json:"pass"`
+	Fetch   string `json:"fetch"`
+	Error   string `json:"error"`
+	Deliver string `json:"deliver"`
+	Log     string `json:"log"`
+}
+
+// Get returns the section body of the scope
+func (s *Src) Get(scope string) string {
+	if p := s.field(scope); p != nil {
+		return *p
+	}
+	return ""
+}
+
+// Set sets the section body of the scope, unknown scope is ignored
+func (s *Src) Set(scope, body string) {
+	if p := s.field(scope); p != nil {
+		*p = body
+	}
+}
+
+func (s *Src) field(scope string) *string {
+	switch scope {
+	case "init":
+		return &s.Init
+	case "recv":
+		return &s.Recv
+	case "hash":
+		return &s.Hash
+	case "hit":
+		return &s.Hit
+	case "miss":
+		return &s.Miss
+	case "pass":
+		return &s.Pass
+	case "fetch":
+		return &s.Fetch
+	case "error":
+		return &s.Error
+	case "deliver":
+		return &s.Deliver
+	case "log":
+		return &s.Log
+	default:
+		return nil
+	}
+}
+
+// Request is the client request which fiddle sends on execution.
+// Headers are newline separated "Name: value" lines and Tests are fiddle assertion expressions
+type Request struct {
+	Method          string `json:"method"`
+	Path            string `json:"path"`
+	Headers         string `json:"headers,omitempty"`
+	Body            string `json:"body,omitempty"`
+	Tests           string `json:"tests,omitempty"`
+	EnableCluster   bool   `json:"enableCluster"`
+	EnableShield    bool   `json:"enableShield"`
+	UseFreshCache   bool   `json:"useFreshCache"`
+	FollowRedirects bool   `json:"followRedirects"`
+	ConnType        string `json:"connType,omitempty"`
+	SourceIP        string `json:"sourceIP,omitempty"`
+}
+
+// DefaultRequests is used when the project does not have request definitions
+func DefaultRequests() []*Request {
+	return []*Request{
+		{Method: http.MethodGet, Path: "/", EnableCluster: true, ConnType: "h2", SourceIP: "client"},
+	}
+}
+
+// ReadRequests reads request definitions from JSON file which is written on import
+func ReadRequests(path string) ([]*Request, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	var requests []*Request
+	if err := json.Unmarshal(b, &requests); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return requests, nil
+}
+
+// WriteRequests writes request definitions to JSON file in order to be edited and exported again
+func WriteRequests(path string, requests []*Request) error {
+	b, err := json.MarshalIndent(requests, "", "  ")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(os.WriteFile(path, b, 0o644))
+}
+
+var fiddleIdPattern = regexp.MustCompile(`^[0-9a-zA-Z]+$`)
+
+// ParseID extracts fiddle id from fiddle URL like https://fiddle.fastly.dev/fiddle/xxxxxxxx,
+// or returns the argument as-is if it is already an id
+func ParseID(v string) (string, error) {
+	id := strings.TrimRight(v, "/")
+	if idx := strings.LastIndex(id, "/fiddle/"); idx != -1 {
+		id = id[idx+len("/fiddle/"):]
+	}
+	if !fiddleIdPattern.MatchString(id) {
+		return "", fmt.Errorf(`Invalid fiddle URL or id "%s"`, v)
+	}
+	return id, nil
+}
+
+// Fetcher fetches fiddle from Fiddle server
+type Fetcher struct {
+	client  *http.Client
+	baseUrl string
+}
+
+func NewFetcher(c *http.Client) *Fetcher {
+	return &Fetcher{
+		client:  c,
+		baseUrl: fiddleBaseUrl,
+	}
+}
+
+func (f *Fetcher) Fetch(ctx context.Context, urlOrId string) (*Fiddle, error) {
+	id, err := ParseID(urlOrId)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	ctx, timeout := context.WithTimeout(ctx, 10*time.Second)
+	defer timeout()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, f.baseUrl+"/fiddle/"+id, nil)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var buf bytes.Buffer
+		if _, err := buf.ReadFrom(resp.Body); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		return nil, fmt.Errorf("Fiddle server respond not 200 code: %d\nBody: %s", resp.StatusCode, buf.String())
+	}
+	return Decode(resp.Body)
+}
+
+// Decode decodes fiddle JSON, the API response wraps fiddle with "fiddle" field
+// but exported file is not wrapped, so both forms are accepted
+func Decode(r io.Reader) (*Fiddle, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	var wrapped struct {
+		Fiddle *Fiddle `json:"fiddle"`
+	}
+	if err := json.Unmarshal(b, &wrapped); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	f := wrapped.Fiddle
+	if f == nil {
+		f = &Fiddle{}
+		if err := json.Unmarshal(b, f); err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+	if f.Src == nil {
+		return nil, errors.New("Fiddle does not have VCL source")
+	}
+	return f, nil
+}
@@ -0,0 +1,216 @@
+package fiddle
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/ysugimoto/falco/v2/ast"
+	"github.com/ysugimoto/falco/v2/config"
+	"github.com/ysugimoto/falco/v2/lexer"
+	"github.com/ysugimoto/falco/v2/parser"
+	"github.com/ysugimoto/falco/v2/resolver"
+)
+
+type testResolver struct {
+	main    string
+	modules map[string]string
+}
+
+func (r *testResolver) Name() string           { return "test" }
+func (r *testResolver) IncludePaths() []string { return []string{} }
+func (r *testResolver) MainVCL() (*resolver.VCL, error) {
+	return &resolver.VCL{Name: "main.vcl", Data: r.main}, nil
+}
+func (r *testResolver) Resolve(stmt *ast.IncludeStatement) (*resolver.VCL, error) {
+	return &resolver.VCL{Name: stmt.Module.Value + ".vcl", Data: r.modules[stmt.Module.Value]}, nil
+}
+
+func parse(v *resolver.VCL) (*ast.VCL, error) {
+	return parser.New(lexer.NewFromString(v.Data, lexer.WithFile(v.Name))).ParseVCL()
+}
+
+var testExporter = NewExporter(parse, &config.FormatConfig{
+	IndentWidth:                2,
+	IndentStyle:                "space",
+	LineWidth:                  120,
+	TrailingCommentWidth:       1,
+	ReturnStatementParenthesis: true,
+})
+
+func TestExport(t *testing.T) {
+	rslv := &testResolver{
+		main: `
+include "backends";
+const HOST = "example.com";
+
+sub vcl_recv {
+  #FASTLY recv
+  if (req.http.Host == HOST) {
+    set req.backend = origin;
+  }
+  return (lookup);
+}
+
+sub vcl_deliver {
+  #FASTLY deliver
+  set resp.http.X-Custom = "1";
+  return (deliver);
+}`,
+		modules: map[string]string{
+			"backends": `
+backend origin {
+  .host = "example.com";
+  .port = "443";
+  .ssl = true;
+}
+
+backend legacy {
+  .host = "legacy.example.com";
+  .port = "8080";
+}
+
+director pool random {
+  { .backend = legacy; .weight = 1; }
+}`,
+		},
+	}
+
+	f, err := testExporter.Export(rslv, nil)
+	if err != nil {
+		t.Fatalf("Unexpected export error: %s", err)
+	}
+	if diff := cmp.Diff([]string{"https://example.com", "http://legacy.example.com:8080"}, f.Origins); diff != "" {
+		t.Errorf("Origins mismatch, diff=%s", diff)
+	}
+	expectRecv := `if (req.http.Host == "example.com") {
+  set req.backend = F_origin_0;
+}
+return(lookup);`
+	if diff := cmp.Diff(expectRecv, f.Src.Recv); diff != "" {
+		t.Errorf("Recv section mismatch, diff=%s", diff)
+	}
+	expectDeliver := `set resp.http.X-Custom = "1";
+return(deliver);`
+	if diff := cmp.Diff(expectDeliver, f.Src.Deliver); diff != "" {
+		t.Errorf("Deliver section mismatch, diff=%s", diff)
+	}
+	if !strings.Contains(f.Src.Init, ".backend = F_origin_1;") {
+		t.Errorf("Director backend should be renamed, init=%s", f.Src.Init)
+	}
+	if strings.Contains(f.Src.Init, "const") || strings.Contains(f.Src.Init, "backend origin") {
+		t.Errorf("Constants and backends should not be exported to init section, init=%s", f.Src.Init)
+	}
+	if diff := cmp.Diff(DefaultRequests(), f.Requests); diff != "" {
+		t.Errorf("Default requests should be used, diff=%s", diff)
+	}
+}
+
+func TestExportBackendWithoutHost(t *testing.T) {
+	rslv := &testResolver{main: `backend origin { .port = "443"; }`}
+	if _, err := testExporter.Export(rslv, nil); err == nil {
+		t.Errorf("Expected error for backend without .host")
+	}
+}
+
+func TestImport(t *testing.T) {
+	f := &Fiddle{
+		ID:      "abc123",
+		Origins: []string{"https://example.com"},
+		Src: &Src{
+			Init: "table routes {\n  \"/\": \"top\",\n}",
+			Recv: "if (req.http.Foo) {\n  set req.http.Bar = \"1\";\n}",
+		},
+	}
+	vcl, err := Import(f)
+	if err != nil {
+		t.Fatalf("Unexpected import error: %s", err)
+	}
+	if _, err := parser.New(lexer.NewFromString(vcl)).ParseVCL(); err != nil {
+		t.Errorf("Imported VCL should be parsed: %s\n%s", err, vcl)
+	}
+	for _, expect := range []string{
+		"# Imported from https://fiddle.fastly.dev/fiddle/abc123",
+		"backend F_origin_0 {",
+		"  .port = \"443\";",
+		"sub vcl_recv {\n  #FASTLY recv\n  if (req.http.Foo) {\n    set req.http.Bar = \"1\";\n  }\n}",
+	} {
+		if !strings.Contains(vcl, expect) {
+			t.Errorf("Imported VCL should contain %q:\n%s", expect, vcl)
+		}
+	}
+	if strings.Contains(vcl, "sub vcl_deliver") {
+		t.Errorf("Empty section should not be generated:\n%s", vcl)
+	}
+
+	// Exporting imported VCL restores the same sections
+	exported, err := testExporter.Export(resolver.NewStaticResolver("main.vcl", vcl), nil)
+	if err != nil {
+		t.Fatalf("Unexpected export error: %s", err)
+	}
+	if diff := cmp.Diff(f.Origins, exported.Origins); diff != "" {
+		t.Errorf("Origins mismatch, diff=%s", diff)
+	}
+	if diff := cmp.Diff(f.Src.Recv, exported.Src.Recv); diff != "" {
+		t.Errorf("Recv section mismatch, diff=%s", diff)
+	}
+}
+
+func TestParseID(t *testing.T) {
+	tests := []struct {
+		input   string
+		expect  string
+		isError bool
+	}{
+		{input: "https://fiddle.fastly.dev/fiddle/a1b2c3d4", expect: "a1b2c3d4"},
+		{input: "https://fiddle.fastly.dev/fiddle/a1b2c3d4/", expect: "a1b2c3d4"},
+		{input: "a1b2c3d4", expect: "a1b2c3d4"},
+		{input: "https://fiddle.fastly.dev/fiddle/../etc", isError: true},
+	}
+	for _, tt := range tests {
+		id, err := ParseID(tt.input)
+		if tt.isError {
+			if err == nil {
+				t.Errorf("Expected error for %s", tt.input)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Unexpected error for %s: %s", tt.input, err)
+			continue
+		}
+		if id != tt.expect {
+			t.Errorf("Expect id %s but got %s", tt.expect, id)
+		}
+	}
+}
+
+func TestFetch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/fiddle/a1b2c3d4" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"fiddle":{"id":"a1b2c3d4","type":"vcl","origins":["https://example.com"],"src":{"recv":"set req.http.Foo = \"1\";"},"requests":[{"method":"GET","path":"/"}]}}`)) // nolint:errcheck
+	}))
+	defer server.Close()
+
+	fetcher := NewFetcher(server.Client())
+	fetcher.baseUrl = server.URL
+
+	f, err := fetcher.Fetch(context.Background(), "https://fiddle.fastly.dev/fiddle/a1b2c3d4")
+	if err != nil {
+		t.Fatalf("Unexpected fetch error: %s", err)
+	}
+	if f.ID != "a1b2c3d4" || f.Src.Recv != `set req.http.Foo = "1";` || len(f.Requests) != 1 {
+		t.Errorf("Unexpected fiddle: %+v", f)
+	}
+
+	if _, err := fetcher.Fetch(context.Background(), "notfound"); err == nil {
+		t.Errorf("Expected error for not found fiddle")
+	}
+}
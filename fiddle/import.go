@@ -0,0 +1,88 @@
+package fiddle
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Import restores the main VCL from fiddle.
+// Origins are declared as backends with fiddle names so that the sources are kept as-is,
+// and lifecycle subroutines are generated with #FASTLY macro for non-empty sections
+func Import(f *Fiddle) (string, error) {
+	var buf strings.Builder
+
+	if f.Title != "" {
+		buf.WriteString("# " + f.Title + "\n")
+	}
+	if f.ID != "" {
+		buf.WriteString("# Imported from " + fiddleBaseUrl + "/fiddle/" + f.ID + "\n")
+	}
+	if buf.Len() > 0 {
+		buf.WriteString("\n")
+	}
+
+	for i, origin := range f.Origins {
+		backend, err := backendDeclaration(fmt.Sprintf("%s%d", originPrefix, i), origin)
+		if err != nil {
+			return "", errors.WithStack(err)
+		}
+		buf.WriteString(backend + "\n")
+	}
+
+	if init := strings.TrimSpace(f.Src.Init); init != "" {
+		buf.WriteString(init + "\n\n")
+	}
+
+	for _, scope := range Scopes {
+		body := strings.TrimSpace(f.Src.Get(scope))
+		if body == "" {
+			continue
+		}
+		buf.WriteString("sub vcl_" + scope + " {\n")
+		buf.WriteString("  #FASTLY " + scope + "\n")
+		for _, line := range strings.Split(body, "\n") {
+			if strings.TrimSpace(line) == "" {
+				buf.WriteString("\n")
+				continue
+			}
+			buf.WriteString("  " + line + "\n")
+		}
+		buf.WriteString("}\n\n")
+	}
+
+	return strings.TrimRight(buf.String(), "\n") + "\n", nil
+}
+
+func backendDeclaration(name, origin string) (string, error) {
+	u, err := url.Parse(origin)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	if u.Hostname() == "" {
+		return "", fmt.Errorf(`Invalid origin URL "%s"`, origin)
+	}
+
+	ssl := u.Scheme == "https"
+	port := u.Port()
+	if port == "" {
+		port = "80"
+		if ssl {
+			port = "443"
+		}
+	}
+
+	var buf strings.Builder
+	buf.WriteString("backend " + name + " {\n")
+	buf.WriteString(fmt.Sprintf("  .host = \"%s\";\n", u.Hostname()))
+	buf.WriteString(fmt.Sprintf("  .port = \"%s\";\n", port))
+	if ssl {
+		buf.WriteString("  .ssl = true;\n")
+		buf.WriteString(fmt.Sprintf("  .ssl_cert_hostname = \"%s\";\n", u.Hostname()))
+		buf.WriteString(fmt.Sprintf("  .ssl_sni_hostname = \"%s\";\n", u.Hostname()))
+	}
+	buf.WriteString("}\n")
+	return buf.String(), nil
+}
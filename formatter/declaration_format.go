@@ -378,3 +378,20 @@ func (f *Formatter) formatSubroutineDeclaration(decl *ast.SubroutineDeclaration)
 		Buffer: buf.String(),
 	}
 }
+
+// Format const declaration
+func (f *Formatter) formatConstDeclaration(decl *ast.ConstDeclaration) *Declaration {
+	buf := bufferPool.Get().(*bytes.Buffer) // nolint:errcheck
+	defer bufferPool.Put(buf)
+
+	buf.Reset()
+	buf.WriteString("const " + decl.Name.String() + " = ")
+	buf.WriteString(f.formatExpression(decl.Value).ChunkedString(0, buf.Len()))
+	buf.WriteString(";")
+
+	return &Declaration{
+		Type:   Const,
+		Name:   decl.Name.Value,
+		Buffer: buf.String(),
+	}
+}
@@ -399,6 +399,16 @@ table /* before_name */ routing_table /* after_name */ BACKEND /* after_type */
   {"/from/%23test"}: {"https://example.com/to/%23sid%23"},
   {"/normal"}: {"https://example.com/normal"},
 }
+`,
+		},
+		{
+			name: "double-quoted keys and values with escaped quote",
+			input: `table messages {
+				"say%22hi%22":"%22hi%22",
+			}`,
+			expect: `table messages {
+  "say%22hi%22": "%22hi%22",
+}
 `,
 		},
 		{
@@ -537,6 +547,35 @@ ratecounter /* before_name */ requests_rate /* after_name */ {
 	}
 }
 
+func TestConstDeclarationFormat(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		expect string
+		conf   *config.FormatConfig
+	}{
+		{
+			name: "formatting with comments",
+			input: `// leading
+			const   TTL_LONG=86400s; // trailing comment`,
+			expect: `// leading
+const TTL_LONG = 86400s;  // trailing comment
+`,
+		},
+		{
+			name:   "negative number",
+			input:  `const OFFSET = -10;`,
+			expect: "const OFFSET = -10;\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert(t, tt.input, tt.expect, tt.conf)
+		})
+	}
+}
+
 func TestSubroutineDeclarationFormat(t *testing.T) {
 	tests := []struct {
 		name   string
@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/ysugimoto/falco/v2/ast"
+	"github.com/ysugimoto/falco/v2/token"
 )
 
 // Format expressions.
@@ -82,7 +83,11 @@ func (f *Formatter) formatString(expr *ast.String) string {
 		return fmt.Sprintf(`{%s"%s"%s}`, expr.Delimiter, expr.Value, expr.Delimiter)
 	}
 	// Otherwise, double-quoted string - use original token literal to preserve escapes
-	return fmt.Sprintf(`"%s"`, expr.Token.Literal)
+	if expr.Meta != nil && expr.Token.Type == token.STRING {
+		return fmt.Sprintf(`"%s"`, expr.Token.Literal)
+	}
+	// String is not built from the token like expanded constant, encode the value
+	return fmt.Sprintf(`"%s"`, ast.EscapeString(expr.Value))
 }
 
 func (f *Formatter) formatRTime(expr *ast.RTime) string {
@@ -62,6 +62,8 @@ func (f *Formatter) Format(vcl *ast.VCL) io.Reader {
 				Type:   Include,
 				Buffer: f.formatIncludeStatement(t),
 			}
+		case *ast.ConstDeclaration:
+			decl = f.formatConstDeclaration(t)
 		case *ast.AclDeclaration:
 			decl = f.formatAclDeclaration(t)
 		case *ast.BackendDeclaration:
@@ -217,6 +217,7 @@ type DeclarationType int
 const (
 	Import DeclarationType = iota + 1
 	Include
+	Const
 	Acl
 	Backend
 	Director
@@ -0,0 +1,286 @@
+// Package inspector provides the local web UI of the simulator.
+// Inspector records recent requests which are processed by the simulator with their VCL flow, variable provenance
+// and cache decision, and replays composed requests to the simulator like Fastly Fiddle
+package inspector
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/ysugimoto/falco/v2/interpreter/process"
+)
+
+// Prefix is the path prefix of the web UI, requests under the prefix are not passed to the simulator
+const Prefix = "/_falco/"
+
+// Maximum bytes of the request body which are recorded for replaying
+const maxBodyBytes = 64 * 1024
+
+var methodPattern = regexp.MustCompile(`^[A-Z]+$`)
+
+type recordKey struct{}
+
+// Record is the request which is processed by the simulator
+type Record struct {
+	ID       int               `json:"id"`
+	Time     time.Time         `json:"time"`
+	Method   string            `json:"method"`
+	URL      string            `json:"url"`
+	Headers  map[string]string `json:"headers"`
+	Body     string            `json:"body"`
+	Replayed bool              `json:"replayed"`
+
+	// Summary of the processed result
+	Status int    `json:"status"`
+	Cache  string `json:"cache,omitempty"`
+	Error  string `json:"error,omitempty"`
+
+	// Finalized process JSON which is the same as the simulator responds without proxy mode
+	Process json.RawMessage `json:"process,omitempty"`
+}
+
+// Replay is the composed request to be sent to the simulator
+type Replay struct {
+	Method  string            `json:"method"`
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers"`
+	Body    string            `json:"body"`
+}
+
+type Option func(in *Inspector)
+
+// WithLimit sets the number of recent requests to keep, 100 as default
+func WithLimit(n int) Option {
+	return func(in *Inspector) {
+		if n > 0 {
+			in.limit = n
+		}
+	}
+}
+
+type Inspector struct {
+	next  http.Handler
+	limit int
+	mux   *http.ServeMux
+
+	mu      sync.Mutex
+	records []*Record
+	nextID  int
+}
+
+// New creates inspector which serves the web UI and passes other requests to the simulator handler.
+// Hook must be set to the interpreter in order to record processed results
+func New(next http.Handler, opts ...Option) *Inspector {
+	in := &Inspector{
+		next:   next,
+		limit:  100,
+		nextID: 1,
+	}
+	for i := range opts {
+		opts[i](in)
+	}
+
+	in.mux = http.NewServeMux()
+	in.mux.HandleFunc("GET "+Prefix+"{$}", in.handlePage)
+	in.mux.HandleFunc("GET "+Prefix+"api/requests", in.handleList)
+	in.mux.HandleFunc("GET "+Prefix+"api/requests/{id}", in.handleDetail)
+	in.mux.HandleFunc("POST "+Prefix+"api/replay", in.handleReplay)
+	return in
+}
+
+// Implements http.Handler
+func (in *Inspector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if strings.HasPrefix(r.URL.Path, Prefix) {
+		in.mux.ServeHTTP(w, r)
+		return
+	}
+	if _, err := in.serve(w, r, false); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	}
+}
+
+// Hook receives the processed result from the interpreter and attaches it to the record of the request
+func (in *Inspector) Hook(r *http.Request, p *process.Process) {
+	record, ok := r.Context().Value(recordKey{}).(*Record)
+	if !ok {
+		return
+	}
+
+	out, err := p.Finalize(p.Response)
+
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	if err != nil {
+		record.Error = err.Error()
+		return
+	}
+	record.Process = out
+	if p.Response != nil {
+		record.Status = p.Response.StatusCode
+	}
+	if p.Explanation != nil {
+		record.Cache = p.Explanation.Result
+	}
+	if p.Error != nil {
+		record.Error = p.Error.Error()
+	}
+}
+
+// Record the request and pass it to the simulator
+func (in *Inspector) serve(w http.ResponseWriter, r *http.Request, replayed bool) (*Record, error) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	if len(body) > maxBodyBytes {
+		body = body[:maxBodyBytes]
+	}
+
+	headers := make(map[string]string)
+	for key, values := range r.Header {
+		headers[key] = strings.Join(values, ", ")
+	}
+	if r.Host != "" {
+		headers["Host"] = r.Host
+	}
+
+	in.mu.Lock()
+	record := &Record{
+		ID:       in.nextID,
+		Time:     time.Now(),
+		Method:   r.Method,
+		URL:      r.URL.RequestURI(),
+		Headers:  headers,
+		Body:     string(body),
+		Replayed: replayed,
+	}
+	in.nextID++
+	in.records = append(in.records, record)
+	if len(in.records) > in.limit {
+		in.records = in.records[len(in.records)-in.limit:]
+	}
+	in.mu.Unlock()
+
+	in.next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), recordKey{}, record)))
+	return record, nil
+}
+
+func (in *Inspector) find(id int) *Record {
+	for _, r := range in.records {
+		if r.ID == id {
+			return r
+		}
+	}
+	return nil
+}
+
+func (in *Inspector) handlePage(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	io.WriteString(w, pageHTML) // nolint:errcheck
+}
+
+// Respond recent requests in newest first order without process details
+func (in *Inspector) handleList(w http.ResponseWriter, r *http.Request) {
+	in.mu.Lock()
+	list := make([]Record, 0, len(in.records))
+	for _, v := range in.records {
+		summary := *v
+		summary.Process = nil
+		summary.Body = ""
+		list = append(list, summary)
+	}
+	in.mu.Unlock()
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].ID > list[j].ID
+	})
+	respondJSON(w, http.StatusOK, list)
+}
+
+func (in *Inspector) handleDetail(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "invalid request id", http.StatusBadRequest)
+		return
+	}
+
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	record := in.find(id)
+	if record == nil {
+		http.Error(w, "request is not found", http.StatusNotFound)
+		return
+	}
+	respondJSON(w, http.StatusOK, record)
+}
+
+// Send the composed request to the simulator and respond the recorded result
+func (in *Inspector) handleReplay(w http.ResponseWriter, r *http.Request) {
+	var replay Replay
+	if err := json.NewDecoder(r.Body).Decode(&replay); err != nil {
+		http.Error(w, "invalid replay request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	req, err := replay.Request()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Simulator response is discarded because the process result is recorded through the hook
+	record, err := in.serve(httptest.NewRecorder(), req, true)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	respondJSON(w, http.StatusOK, record)
+}
+
+// Request creates the server request from the composed request
+func (r *Replay) Request() (*http.Request, error) {
+	method := strings.ToUpper(strings.TrimSpace(r.Method))
+	if method == "" {
+		method = http.MethodGet
+	}
+	if !methodPattern.MatchString(method) {
+		return nil, errors.Errorf("invalid method %q", r.Method)
+	}
+	if _, err := url.ParseRequestURI(r.URL); err != nil {
+		return nil, errors.Errorf("invalid url %q, must be the path like /index.html", r.URL)
+	}
+	if strings.HasPrefix(r.URL, Prefix) {
+		return nil, errors.Errorf("url %q is reserved for the web UI", r.URL)
+	}
+
+	req := httptest.NewRequest(method, r.URL, strings.NewReader(r.Body))
+	for key, val := range r.Headers {
+		if strings.EqualFold(key, "Host") {
+			req.Host = val
+			continue
+		}
+		req.Header.Set(key, val)
+	}
+	return req, nil
+}
+
+func respondJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v) // nolint:errcheck
+}
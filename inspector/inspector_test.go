@@ -0,0 +1,117 @@
+package inspector
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	ihttp "github.com/ysugimoto/falco/v2/interpreter/http"
+	"github.com/ysugimoto/falco/v2/interpreter/process"
+)
+
+// Fake simulator which notifies the processed result like the interpreter does
+func fakeSimulator(in **Inspector) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body) // nolint:errcheck
+		p := process.New(time.Now())
+		p.Explanation = process.NewExplanation()
+		p.Explanation.Result = process.ResultPass
+		p.Response = ihttp.WrapResponse(&http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"X-Path": {r.URL.Path}},
+			Body:       io.NopCloser(bytes.NewReader(body)),
+		})
+		(*in).Hook(r, p)
+		w.WriteHeader(http.StatusOK)
+	})
+}
+
+func TestInspector(t *testing.T) {
+	var in *Inspector
+	in = New(fakeSimulator(&in), WithLimit(2))
+
+	for _, path := range []string{"/first", "/second", "/third"} {
+		req := httptest.NewRequest(http.MethodPost, path+"?q=1", strings.NewReader("body"))
+		req.Header.Set("X-Foo", "foo")
+		in.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	t.Run("list recent requests", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		in.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, Prefix+"api/requests", nil))
+		var list []*Record
+		if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if len(list) != 2 || list[0].URL != "/third?q=1" || list[1].URL != "/second?q=1" {
+			t.Fatalf("Unexpected records %+v", list)
+		}
+		if list[0].Status != http.StatusOK || list[0].Cache != process.ResultPass || list[0].Process != nil {
+			t.Errorf("Unexpected summary %+v", list[0])
+		}
+	})
+
+	t.Run("request detail", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		in.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, Prefix+"api/requests/3", nil))
+		var record Record
+		if err := json.Unmarshal(rec.Body.Bytes(), &record); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if record.Body != "body" || record.Headers["X-Foo"] != "foo" || record.Headers["Host"] != "example.com" {
+			t.Errorf("Unexpected record %+v", record)
+		}
+		if !strings.Contains(string(record.Process), `"x-path":"/third"`) {
+			t.Errorf("Process should be recorded, got %s", record.Process)
+		}
+
+		rec = httptest.NewRecorder()
+		in.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, Prefix+"api/requests/1", nil))
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("Dropped request should not be found, got %d", rec.Code)
+		}
+	})
+
+	t.Run("replay composed request", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		body := `{"method":"put","url":"/replay","headers":{"Host":"replay.example.com","X-Bar":"bar"},"body":"modified"}`
+		in.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, Prefix+"api/replay", strings.NewReader(body)))
+		var record Record
+		if err := json.Unmarshal(rec.Body.Bytes(), &record); err != nil {
+			t.Fatalf("Unexpected error: %s, body=%s", err, rec.Body.String())
+		}
+		if !record.Replayed || record.Method != http.MethodPut || record.Headers["Host"] != "replay.example.com" {
+			t.Errorf("Unexpected record %+v", record)
+		}
+		if !strings.Contains(string(record.Process), `"body_bytes":8`) {
+			t.Errorf("Replayed request should be processed, got %s", record.Process)
+		}
+	})
+
+	t.Run("reject invalid replay", func(t *testing.T) {
+		for _, body := range []string{
+			`{"method":"GET","url":"not a path"}`,
+			`{"method":"G ET","url":"/"}`,
+			`{"method":"GET","url":"/_falco/api/requests"}`,
+		} {
+			rec := httptest.NewRecorder()
+			in.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, Prefix+"api/replay", strings.NewReader(body)))
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("Expected bad request for %s, got %d", body, rec.Code)
+			}
+		}
+	})
+
+	t.Run("serve page", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		in.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, Prefix, nil))
+		if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "Request Composer") {
+			t.Errorf("Unexpected page response %d", rec.Code)
+		}
+	})
+}
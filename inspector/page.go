@@ -0,0 +1,173 @@
+package inspector
+
+// Single page of the web UI, recent requests are polled from the API and rendered by plain JavaScript
+const pageHTML = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>falco simulator</title>
+<style>
+  body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Helvetica, Arial, sans-serif; margin: 0; color: #24292f; display: flex; height: 100vh; }
+  h2 { font-size: 15px; margin: 16px 0 8px; }
+  table { border-collapse: collapse; width: 100%; font-size: 13px; }
+  th, td { padding: 3px 8px; text-align: left; border-bottom: 1px solid #eaeef2; vertical-align: top; }
+  code, pre, textarea, input { font-family: SFMono-Regular, Consolas, Menlo, monospace; font-size: 12px; }
+  pre { background: #f6f8fa; padding: 8px; overflow: auto; margin: 0; }
+  #requests { width: 360px; border-right: 1px solid #d0d7de; overflow: auto; }
+  #requests .item { padding: 8px 12px; border-bottom: 1px solid #eaeef2; cursor: pointer; font-size: 13px; }
+  #requests .item:hover, #requests .item.selected { background: #ddf4ff; }
+  #requests .meta { color: #57606a; font-size: 11px; }
+  #main { flex: 1; overflow: auto; padding: 0 16px 16px; }
+  .status { font-weight: bold; }
+  .ok { color: #1a7f37; }
+  .ng { color: #cf222e; }
+  .badge { display: inline-block; padding: 0 6px; border-radius: 8px; background: #eaeef2; font-size: 11px; margin-left: 4px; }
+  #composer textarea { width: 100%; height: 80px; box-sizing: border-box; }
+  #composer input[name=url] { width: 60%; }
+</style>
+</head>
+<body>
+<div id="requests"></div>
+<div id="main">
+  <h2>Request Composer</h2>
+  <form id="composer">
+    <input name="method" value="GET" size="8">
+    <input name="url" value="/">
+    <button type="submit">Send</button>
+    <p>Headers (one "Name: value" per line)</p>
+    <textarea name="headers">Host: localhost</textarea>
+    <p>Body</p>
+    <textarea name="body"></textarea>
+  </form>
+  <div id="detail"><p>Send requests to the simulator or compose the request to inspect how VCL processes it.</p></div>
+</div>
+<script>
+const api = "` + Prefix + `api/";
+let selected = null;
+
+function esc(v) {
+  return String(v === undefined || v === null ? "" : v).replace(/[&<>"']/g, c => ({"&": "&amp;", "<": "&lt;", ">": "&gt;", '"': "&quot;", "'": "&#39;"}[c]));
+}
+
+function where(v) {
+  return v.file ? esc(v.file) + ":" + v.line : (v.line ? "line " + v.line : "");
+}
+
+async function loadList() {
+  const res = await fetch(api + "requests");
+  const list = await res.json();
+  document.getElementById("requests").innerHTML = list.map(r =>
+    '<div class="item' + (r.id === selected ? ' selected' : '') + '" data-id="' + r.id + '">' +
+    '<span class="status ' + (r.error || r.status >= 500 ? 'ng' : 'ok') + '">' + (r.status || "-") + '</span> ' +
+    esc(r.method) + ' ' + esc(r.url) +
+    (r.cache ? '<span class="badge">' + esc(r.cache) + '</span>' : '') +
+    (r.replayed ? '<span class="badge">replayed</span>' : '') +
+    '<div class="meta">#' + r.id + ' ' + new Date(r.time).toLocaleTimeString() + '</div></div>'
+  ).join("");
+}
+
+async function show(id) {
+  selected = id;
+  const res = await fetch(api + "requests/" + id);
+  if (!res.ok) {
+    return;
+  }
+  render(await res.json());
+  loadList();
+}
+
+function render(r) {
+  const p = r.process || {};
+  // Form has own "method" property so access inputs through elements
+  const form = document.getElementById("composer").elements;
+  form["method"].value = r.method;
+  form["url"].value = r.url;
+  form["headers"].value = Object.keys(r.headers || {}).sort().map(k => k + ": " + r.headers[k]).join("\n");
+  form["body"].value = r.body || "";
+
+  let html = '<h2>#' + r.id + ' ' + esc(r.method) + ' ' + esc(r.url) + '</h2>';
+  if (r.error) {
+    html += '<pre class="ng">' + esc(r.error) + '</pre>';
+  }
+
+  html += '<h2>VCL Flow</h2><table><tr><th>Scope</th><th>Subroutine</th><th>Location</th></tr>' +
+    (p.flows || []).map(f => '<tr><td>' + esc(f.scope) + '</td><td>' + esc(f.subroutine || f.name) + '</td><td>' + where(f) + '</td></tr>').join("") +
+    '</table>';
+
+  if (p.transitions && p.transitions.length) {
+    html += '<h2>State Transitions</h2><pre>' + esc(JSON.stringify(p.transitions, null, 2)) + '</pre>';
+  }
+
+  const explain = p.explain;
+  html += '<h2>Cache Decision</h2>';
+  if (explain) {
+    html += '<p><b>' + esc(explain.result) + '</b></p><table>' +
+      (explain.reasons || []).map(v => '<tr><td>' + esc(v.message) + '</td><td>' + where(v) + '</td></tr>').join("") +
+      '</table>';
+  } else {
+    html += '<p>Not available</p>';
+  }
+
+  html += '<h2>Variable Provenance</h2><table><tr><th>Kind</th><th>Name</th><th>Value</th><th>Scope</th><th>Location</th></tr>' +
+    (p.provenance || []).map(a => '<tr><td>' + esc(a.kind) + '</td><td><code>' + esc(a.name) + '</code></td><td><code>' +
+      esc(a.value) + '</code></td><td>' + esc(a.scope) + '</td><td>' + where(a) + '</td></tr>').join("") +
+    '</table>';
+
+  if (p.budget_violations && p.budget_violations.length) {
+    html += '<h2>Latency Budget Violations</h2><table><tr><th>Route</th><th>Budget</th><th>Simulated TTFB</th><th>Location</th></tr>' +
+      p.budget_violations.map(v => '<tr><td>' + esc(v.route) + '</td><td>' + esc(v.budget) + '</td><td class="ng">' +
+        esc(v.actual) + '</td><td>' + where(v) + '</td></tr>').join("") +
+      '</table>';
+  }
+
+  if (p.logs && p.logs.length) {
+    html += '<h2>Logs</h2><pre>' + esc(p.logs.map(l => '[' + l.scope + '] ' + l.message).join("\n")) + '</pre>';
+  }
+
+  const resp = p.client_response || {};
+  html += '<h2>Response</h2><p>Status: ' + esc(resp.status_code) + ', Backend: ' + esc(p.backend || "-") +
+    ', Restarts: ' + esc(p.restarts) + ', ' + esc(p.elapsed_time_ms) + 'ms</p><table>' +
+    Object.keys(resp.headers || {}).sort().map(k => '<tr><td>' + esc(k) + '</td><td><code>' + esc(resp.headers[k]) + '</code></td></tr>').join("") +
+    '</table>';
+
+  document.getElementById("detail").innerHTML = html;
+}
+
+document.getElementById("requests").addEventListener("click", e => {
+  const item = e.target.closest(".item");
+  if (item) {
+    show(Number(item.dataset.id));
+  }
+});
+
+document.getElementById("composer").addEventListener("submit", async e => {
+  e.preventDefault();
+  const form = e.target.elements;
+  const headers = {};
+  form["headers"].value.split("\n").forEach(line => {
+    const i = line.indexOf(":");
+    if (i > 0) {
+      headers[line.slice(0, i).trim()] = line.slice(i + 1).trim();
+    }
+  });
+  const res = await fetch(api + "replay", {
+    method: "POST",
+    headers: {"Content-Type": "application/json"},
+    body: JSON.stringify({method: form["method"].value, url: form["url"].value, headers: headers, body: form["body"].value}),
+  });
+  if (!res.ok) {
+    alert(await res.text());
+    return;
+  }
+  const record = await res.json();
+  selected = record.id;
+  render(record);
+  loadList();
+});
+
+loadList();
+setInterval(loadList, 2000);
+</script>
+</body>
+</html>
+`
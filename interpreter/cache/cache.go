@@ -2,7 +2,9 @@
 package cache
 
 import (
+	ghttp "net/http"
 	"slices"
+	"strings"
 	"sync"
 	"time"
 
@@ -20,6 +22,9 @@ type CacheItem struct {
 	Hits      int
 	LastUsed  time.Duration
 
+	// Client request headers that the object was stored with, used for Vary matching
+	RequestHeader ghttp.Header
+
 	// private
 	requestedTime time.Time
 }
@@ -28,6 +33,30 @@ func (i *CacheItem) Update(d time.Duration) {
 	i.Expires = i.EntryTime.Add(d)
 }
 
+// VaryMismatch returns the first header name listed in Vary response header
+// whose value differs between the stored request and provided request headers.
+// Returns empty string if the object could be served for the request.
+func (i *CacheItem) VaryMismatch(h ghttp.Header) string {
+	if i.Response == nil || i.RequestHeader == nil {
+		return ""
+	}
+	for _, vary := range i.Response.Header.Values("Vary") {
+		for _, name := range strings.Split(vary, ",") {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				continue
+			}
+			if name == "*" {
+				return name
+			}
+			if strings.Join(i.RequestHeader.Values(name), ",") != strings.Join(h.Values(name), ",") {
+				return ghttp.CanonicalHeaderKey(name)
+			}
+		}
+	}
+	return ""
+}
+
 type Cache struct {
 	storage sync.Map
 }
@@ -41,7 +70,46 @@ func (c *Cache) Set(hash string, item *CacheItem) {
 	c.storage.Store(hash, item)
 }
 
-func (c *Cache) Get(hash string) *CacheItem {
+// Range calls fn for each cached object, iteration stops when fn returns false
+func (c *Cache) Range(fn func(hash string, item *CacheItem) bool) {
+	c.storage.Range(func(k, v any) bool {
+		hash, ok := k.(string)
+		if !ok {
+			return true
+		}
+		item, ok := v.(*CacheItem)
+		if !ok {
+			return true
+		}
+		return fn(hash, item)
+	})
+}
+
+func (c *Cache) Get(hash string, now time.Time) *CacheItem {
+	item := c.load(hash, now)
+	if item == nil {
+		return nil
+	}
+	item.hit(now)
+	return item
+}
+
+// Lookup finds cache object with considering Vary response header.
+// When the object is found but could not be served due to Vary, returns nil and mismatched header name.
+// Expiration is checked against provided time which comes from the interpreter clock
+func (c *Cache) Lookup(hash string, h ghttp.Header, now time.Time) (*CacheItem, string) {
+	item := c.load(hash, now)
+	if item == nil {
+		return nil, ""
+	}
+	if name := item.VaryMismatch(h); name != "" {
+		return nil, name
+	}
+	item.hit(now)
+	return item, ""
+}
+
+func (c *Cache) load(hash string, now time.Time) *CacheItem {
 	// Load and cast to *CacheItem
 	v, ok := c.storage.Load(hash)
 	if !ok {
@@ -52,18 +120,20 @@ func (c *Cache) Get(hash string) *CacheItem {
 		return nil
 	}
 	// Check expiration
-	if time.Now().After(item.Expires) {
+	if now.After(item.Expires) {
 		c.storage.Delete(hash)
 		return nil
 	}
-
-	// Update cache state - increment Hit count, update last used time
-	item.Hits++
-	item.LastUsed = time.Since(item.requestedTime)
-	item.requestedTime = time.Now()
 	return item
 }
 
+// Update cache state - increment Hit count, update last used time
+func (i *CacheItem) hit(now time.Time) {
+	i.Hits++
+	i.LastUsed = now.Sub(i.requestedTime)
+	i.requestedTime = now
+}
+
 // Fastly follows its own cache freshness rules
 // see: https://developer.fastly.com/learning/concepts/cache-freshness/
 var unCacheableStatusCodes = []int{200, 203, 300, 301, 302, 404, 410}
@@ -0,0 +1,66 @@
+package cache
+
+import (
+	ghttp "net/http"
+	"testing"
+	"time"
+
+	"github.com/ysugimoto/falco/v2/interpreter/http"
+)
+
+func TestCacheLookup(t *testing.T) {
+	newItem := func() *CacheItem {
+		resp := http.WrapResponse(&ghttp.Response{Header: ghttp.Header{}})
+		resp.Header.Set("Vary", "Accept-Encoding, X-Device")
+		stored := ghttp.Header{}
+		stored.Set("Accept-Encoding", "gzip")
+		stored.Set("X-Device", "mobile")
+		return &CacheItem{
+			Response:      resp,
+			Expires:       time.Now().Add(time.Hour),
+			EntryTime:     time.Now(),
+			RequestHeader: stored,
+		}
+	}
+
+	tests := []struct {
+		name   string
+		header map[string]string
+		hit    bool
+		vary   string
+	}{
+		{
+			name:   "all Vary headers match",
+			header: map[string]string{"Accept-Encoding": "gzip", "X-Device": "mobile"},
+			hit:    true,
+		},
+		{
+			name:   "Vary header mismatches",
+			header: map[string]string{"Accept-Encoding": "gzip", "X-Device": "desktop"},
+			vary:   "X-Device",
+		},
+		{
+			name:   "Vary header is missing in the request",
+			header: map[string]string{"X-Device": "mobile"},
+			vary:   "Accept-Encoding",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := New()
+			c.Set("hash", newItem())
+			h := ghttp.Header{}
+			for k, v := range tt.header {
+				h.Set(k, v)
+			}
+			item, vary := c.Lookup("hash", h, time.Now())
+			if (item != nil) != tt.hit {
+				t.Errorf("hit mismatch, expect=%t, actual=%t", tt.hit, item != nil)
+			}
+			if vary != tt.vary {
+				t.Errorf("vary mismatch, expect=%q, actual=%q", tt.vary, vary)
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package interpreter
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ysugimoto/falco/v2/ast"
+)
+
+// Find chaos annotation of the table item like `"beta": "on", # falco:chaos off|disabled`
+// and returns alternative values which are separated by "|"
+func findChaosMark(comments ast.Comments) ([]string, bool) {
+	for i := range comments {
+		l := strings.TrimLeft(comments[i].Value, " */#")
+		if spec, found := strings.CutPrefix(l, "falco:chaos"); found {
+			var alternatives []string
+			for _, v := range strings.Split(spec, "|") {
+				if v = strings.Trim(strings.TrimSpace(v), `"`); v != "" {
+					alternatives = append(alternatives, v)
+				}
+			}
+			return alternatives, true
+		}
+	}
+	return nil, false
+}
+
+// Perturb randomly perturbs conditions of the testing context for chaos testing.
+// Healthy backends become unhealthy, current time is fixed to skewed time within skew,
+// and table items which are annotated with "falco:chaos" comment are flipped to the alternative value.
+// Returns descriptions of applied perturbations and the function which restores original conditions
+func (i *Interpreter) Perturb(r *rand.Rand, skew time.Duration) ([]string, func()) {
+	var applied []string
+	var restores []func()
+
+	backends := make([]string, 0, len(i.ctx.Backends))
+	for name := range i.ctx.Backends {
+		backends = append(backends, name)
+	}
+	sort.Strings(backends)
+	for _, name := range backends {
+		b := i.ctx.Backends[name]
+		if b.Healthy == nil || !b.Healthy.Load() || r.Intn(3) != 0 {
+			continue
+		}
+		b.Healthy.Store(false)
+		restores = append(restores, func() { b.Healthy.Store(true) })
+		applied = append(applied, fmt.Sprintf("backend %s is unhealthy", name))
+	}
+
+	if skew > 0 {
+		fixed := i.ctx.FixedTime
+		d := time.Duration(r.Int63n(2*int64(skew)+1)) - skew
+		t := i.ctx.Now().Add(d)
+		i.ctx.FixedTime = &t
+		restores = append(restores, func() { i.ctx.FixedTime = fixed })
+		applied = append(applied, fmt.Sprintf("clock is skewed by %s", d))
+	}
+
+	tables := make([]string, 0, len(i.ctx.Tables))
+	for name := range i.ctx.Tables {
+		tables = append(tables, name)
+	}
+	sort.Strings(tables)
+	for _, name := range tables {
+		table := i.ctx.Tables[name]
+		for _, prop := range table.Properties {
+			alternatives, found := findChaosMark(prop.Leading)
+			if !found {
+				alternatives, found = findChaosMark(prop.Trailing)
+			}
+			if !found || r.Intn(2) != 0 {
+				continue
+			}
+			flipped, desc := flipTableValue(table, prop, alternatives, r)
+			if flipped == nil {
+				continue
+			}
+			original := prop.Value
+			prop.Value = flipped
+			restores = append(restores, func() { prop.Value = original })
+			applied = append(applied, fmt.Sprintf("table %s item %s is flipped to %s", name, prop.Key.Value, desc))
+		}
+	}
+
+	return applied, func() {
+		for j := len(restores) - 1; j >= 0; j-- {
+			restores[j]()
+		}
+	}
+}
+
+// Make flipped value of the table item. STRING and BOOL tables are supported,
+// BOOL item is negated if alternatives are not specified
+func flipTableValue(
+	table *ast.TableDeclaration,
+	prop *ast.TableProperty,
+	alternatives []string,
+	r *rand.Rand,
+) (ast.Expression, string) {
+
+	var alt string
+	if len(alternatives) > 0 {
+		alt = alternatives[r.Intn(len(alternatives))]
+	}
+
+	valueType := "STRING"
+	if table.ValueType != nil {
+		valueType = table.ValueType.Value
+	}
+	switch valueType {
+	case "STRING":
+		return &ast.String{Meta: prop.Value.GetMeta(), Value: alt}, fmt.Sprintf("%q", alt)
+	case "BOOL":
+		v, ok := prop.Value.(*ast.Boolean)
+		if !ok {
+			return nil, ""
+		}
+		flipped := !v.Value
+		if alt != "" {
+			flipped = alt == "true"
+		}
+		return &ast.Boolean{Meta: v.Meta, Value: flipped}, fmt.Sprintf("%t", flipped)
+	default:
+		return nil, ""
+	}
+}
@@ -0,0 +1,78 @@
+package interpreter
+
+import (
+	"math/rand"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ysugimoto/falco/v2/ast"
+	"github.com/ysugimoto/falco/v2/interpreter/context"
+	"github.com/ysugimoto/falco/v2/interpreter/value"
+	"github.com/ysugimoto/falco/v2/lexer"
+	"github.com/ysugimoto/falco/v2/parser"
+)
+
+func TestPerturb(t *testing.T) {
+	vcl, err := parser.New(lexer.NewFromString(`
+table flags {
+  "checkout": "on", # falco:chaos off
+  "region": "us",
+}
+table maintenance BOOL {
+  # falco:chaos
+  "api": false,
+}
+`)).ParseVCL()
+	if err != nil {
+		t.Fatalf("Unexpected parser error: %s", err)
+	}
+
+	healthy := &atomic.Bool{}
+	healthy.Store(true)
+	fixed := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	ip := &Interpreter{ctx: context.New()}
+	ip.ctx.FixedTime = &fixed
+	ip.ctx.Backends["origin"] = &value.Backend{Healthy: healthy}
+	for _, stmt := range vcl.Statements {
+		table := stmt.(*ast.TableDeclaration)
+		ip.ctx.Tables[table.Name.Value] = table
+	}
+	flags := ip.ctx.Tables["flags"]
+	maintenance := ip.ctx.Tables["maintenance"]
+
+	// Try seeds until all kinds of perturbations are applied at once
+	for seed := int64(0); seed < 100; seed++ {
+		applied, restore := ip.Perturb(rand.New(rand.NewSource(seed)), time.Minute) // nolint: gosec
+		if len(applied) < 4 {
+			restore()
+			continue
+		}
+
+		if healthy.Load() {
+			t.Errorf("Backend should be unhealthy")
+		}
+		if skew := ip.ctx.Now().Sub(fixed); skew < -time.Minute || skew > time.Minute {
+			t.Errorf("Clock skew should be within a minute, got %s", skew)
+		}
+		if v := flags.Properties[0].Value.(*ast.String).Value; v != "off" {
+			t.Errorf("Chaos item should be flipped to off, got %s", v)
+		}
+		if v := flags.Properties[1].Value.(*ast.String).Value; v != "us" {
+			t.Errorf("Item without chaos annotation should not be flipped, got %s", v)
+		}
+		if !maintenance.Properties[0].Value.(*ast.Boolean).Value {
+			t.Errorf("BOOL chaos item should be negated")
+		}
+
+		restore()
+		if !healthy.Load() || !ip.ctx.Now().Equal(fixed) {
+			t.Errorf("Backend health and clock should be restored")
+		}
+		if flags.Properties[0].Value.(*ast.String).Value != "on" || maintenance.Properties[0].Value.(*ast.Boolean).Value {
+			t.Errorf("Table items should be restored")
+		}
+		return
+	}
+	t.Errorf("All perturbations are never applied")
+}
@@ -48,7 +48,7 @@ func (i *Interpreter) ConsoleProcessInit() error {
 	i.ctx.Response = i.ctx.BackendResponse.Clone()
 	i.ctx.Object = i.ctx.BackendResponse.Clone()
 	i.ctx.Scope = context.InitScope
-	i.process = process.New()
+	i.process = process.New(i.ctx.Now())
 	i.vars = variable.NewAllScopeVariables(i.ctx)
 	return nil
 }
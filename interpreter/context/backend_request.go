@@ -0,0 +1,30 @@
+package context
+
+import (
+	"net/http"
+)
+
+// BackendRequest is the recorded request which the interpreter sends to the backend.
+// Headers are captured after all bereq manipulations in the subroutine are applied
+type BackendRequest struct {
+	Backend string
+	Method  string
+	URL     string
+	Header  http.Header
+	Body    string
+}
+
+// FindBackendRequest returns nth (1-origin) recorded request for the backend
+func (c *Context) FindBackendRequest(backend string, nth int) *BackendRequest {
+	var count int
+	for _, req := range c.BackendRequests {
+		if req.Backend != backend {
+			continue
+		}
+		count++
+		if count == nth {
+			return req
+		}
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package context
+
+import (
+	"io"
+	ghttp "net/http"
+	"strings"
+	"time"
+
+	"github.com/ysugimoto/falco/v2/interpreter/http"
+)
+
+// MockBackendResponse is the canned response which is returned instead of sending the request
+// to the backend in testing
+type MockBackendResponse struct {
+	Status  int
+	Header  ghttp.Header
+	Body    string
+	Latency time.Duration
+}
+
+// Response creates new backend response from the mock, the request is the backend request which the response replies to
+func (m *MockBackendResponse) Response(req *http.Request) *http.Response {
+	resp := &ghttp.Response{
+		StatusCode:    m.Status,
+		Status:        ghttp.StatusText(m.Status),
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        m.Header.Clone(),
+		Body:          io.NopCloser(strings.NewReader(m.Body)),
+		ContentLength: int64(len(m.Body)),
+		Close:         true,
+		Trailer:       ghttp.Header{},
+	}
+	if resp.Header == nil {
+		resp.Header = ghttp.Header{}
+	}
+	if req != nil {
+		resp.Request = req.Request
+	}
+	return http.WrapResponse(resp)
+}
+
+// FindMockBackendResponse returns the mocked response of the backend, nil if the backend is not mocked
+func (c *Context) FindMockBackendResponse(backend string) *MockBackendResponse {
+	if c.MockBackendResponses == nil {
+		return nil
+	}
+	return c.MockBackendResponses[backend]
+}
@@ -0,0 +1,134 @@
+package context
+
+import (
+	gocontext "context"
+	"io"
+	ghttp "net/http"
+	"strings"
+	"sync/atomic"
+
+	"github.com/ysugimoto/falco/v2/ast"
+	"github.com/ysugimoto/falco/v2/interpreter/http"
+	"github.com/ysugimoto/falco/v2/interpreter/value"
+)
+
+// Geo is the client geolocation which is returned from client.geo.* variables
+type Geo struct {
+	City          string
+	ContinentCode string
+	CountryCode   string
+	CountryName   string
+	PostalCode    string
+	Region        string
+	Latitude      float64
+	Longitude     float64
+}
+
+// Build creates the context which is ready to use from Go code without running the interpreter,
+// for example calling builtin functions or looking up variables directly.
+// Unlike New, all request and response fields are populated so that embedders don't need to know
+// which fields must be set to avoid nil-pointer panics. Missing values are filled with defaults:
+// GET request to http://localhost/, 200 OK response and the local backend.
+func Build(options ...Option) *Context {
+	ctx := New(options...)
+
+	if ctx.Request == nil {
+		req, _ := ghttp.NewRequestWithContext(gocontext.Background(), ghttp.MethodGet, "http://localhost/", nil) // nolint: errcheck
+		ctx.Request = http.WrapRequest(req)
+	}
+	if ctx.OriginalHost == "" {
+		ctx.OriginalHost = ctx.Request.Host
+	}
+	if ctx.Backend == nil {
+		ctx.Backend = newBuilderBackend(&ast.BackendDeclaration{
+			Name: &ast.Ident{Value: "falco_local_backend"},
+			Properties: []*ast.BackendProperty{
+				{
+					Key:   &ast.Ident{Value: "host"},
+					Value: &ast.String{Value: "localhost"},
+				},
+			},
+		})
+	}
+	if ctx.BackendRequest == nil {
+		ctx.BackendRequest = ctx.Request.Clone(gocontext.Background())
+	}
+	if ctx.BackendResponse == nil {
+		ctx.BackendResponse = http.WrapResponse(&ghttp.Response{
+			StatusCode: ghttp.StatusOK,
+			Status:     ghttp.StatusText(ghttp.StatusOK),
+			Proto:      "HTTP/1.1",
+			ProtoMajor: 1,
+			ProtoMinor: 1,
+			Header:     ghttp.Header{},
+			Body:       io.NopCloser(strings.NewReader("")),
+			Trailer:    ghttp.Header{},
+			Request:    ctx.BackendRequest.Request,
+		})
+	}
+	if ctx.Object == nil {
+		ctx.Object = ctx.BackendResponse.Clone()
+	}
+	if ctx.Response == nil {
+		ctx.Response = ctx.BackendResponse.Clone()
+	}
+	return ctx
+}
+
+func newBuilderBackend(b *ast.BackendDeclaration) *value.Backend {
+	healthy := &atomic.Bool{}
+	healthy.Store(true)
+	return &value.Backend{Value: b, Literal: true, Healthy: healthy}
+}
+
+// WithHTTPRequest sets the client request, req.* variables are read from this request
+func WithHTTPRequest(r *ghttp.Request) Option {
+	return func(c *Context) {
+		c.Request = http.WrapRequest(r)
+	}
+}
+
+// WithBackend registers the backend declaration as healthy.
+// The first registered backend is used as the default req.backend
+func WithBackend(b *ast.BackendDeclaration) Option {
+	return func(c *Context) {
+		backend := newBuilderBackend(b)
+		c.Backends[b.Name.Value] = backend
+		if c.Backend == nil {
+			c.Backend = backend
+		}
+	}
+}
+
+// WithTable registers the table declaration to be used in table.* functions
+func WithTable(t *ast.TableDeclaration) Option {
+	return func(c *Context) {
+		c.Tables[t.Name.Value] = t
+	}
+}
+
+// WithGeo sets the client geolocation, client.geo.* variables return the provided values.
+// Empty strings and zero coordinates are ignored so that those variables return the default value
+func WithGeo(g Geo) Option {
+	return func(c *Context) {
+		strs := map[string]string{
+			"client.geo.city":           g.City,
+			"client.geo.city.ascii":     g.City,
+			"client.geo.city.utf8":      g.City,
+			"client.geo.continent_code": g.ContinentCode,
+			"client.geo.country_code":   g.CountryCode,
+			"client.geo.country_name":   g.CountryName,
+			"client.geo.postal_code":    g.PostalCode,
+			"client.geo.region":         g.Region,
+		}
+		for name, v := range strs {
+			if v != "" {
+				c.OverrideVariables[name] = &value.String{Value: v}
+			}
+		}
+		if g.Latitude != 0 || g.Longitude != 0 {
+			c.OverrideVariables["client.geo.latitude"] = &value.Float{Value: g.Latitude}
+			c.OverrideVariables["client.geo.longitude"] = &value.Float{Value: g.Longitude}
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package context
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ysugimoto/falco/v2/ast"
+	"github.com/ysugimoto/falco/v2/interpreter/value"
+)
+
+func TestBuild(t *testing.T) {
+	t.Run("default values are populated", func(t *testing.T) {
+		ctx := Build()
+		if ctx.Request == nil || ctx.BackendRequest == nil || ctx.BackendResponse == nil || ctx.Object == nil || ctx.Response == nil {
+			t.Fatalf("Request and response fields must be populated")
+		}
+		if ctx.Backend == nil || !ctx.Backend.Healthy.Load() {
+			t.Errorf("Default backend must be populated as healthy")
+		}
+		if ctx.OriginalHost != "localhost" {
+			t.Errorf("OriginalHost mismatch, expect=localhost, got=%s", ctx.OriginalHost)
+		}
+	})
+
+	t.Run("options are applied", func(t *testing.T) {
+		fixed := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		ctx := Build(
+			WithHTTPRequest(httptest.NewRequest("POST", "http://example.com/foo", nil)),
+			WithBackend(&ast.BackendDeclaration{Name: &ast.Ident{Value: "origin"}}),
+			WithTable(&ast.TableDeclaration{Name: &ast.Ident{Value: "routes"}}),
+			WithFixedTime(fixed),
+			WithGeo(Geo{CountryCode: "JP", Latitude: 35.6, Longitude: 139.7}),
+		)
+		if ctx.Request.Method != "POST" || ctx.BackendRequest.URL.Path != "/foo" {
+			t.Errorf("Request is not applied")
+		}
+		if ctx.OriginalHost != "example.com" {
+			t.Errorf("OriginalHost mismatch, expect=example.com, got=%s", ctx.OriginalHost)
+		}
+		if ctx.Backend != ctx.Backends["origin"] {
+			t.Errorf("Registered backend must be used as default backend")
+		}
+		if _, ok := ctx.Tables["routes"]; !ok {
+			t.Errorf("Table is not registered")
+		}
+		if !ctx.RequestStartTime.Equal(fixed) {
+			t.Errorf("RequestStartTime mismatch, expect=%s, got=%s", fixed, ctx.RequestStartTime)
+		}
+		if v, ok := ctx.OverrideVariables["client.geo.country_code"].(*value.String); !ok || v.Value != "JP" {
+			t.Errorf("Geo country code is not applied")
+		}
+		if _, ok := ctx.OverrideVariables["client.geo.city"]; ok {
+			t.Errorf("Empty geo value must not be overridden")
+		}
+		if v, ok := ctx.OverrideVariables["client.geo.latitude"].(*value.Float); !ok || v.Value != 35.6 {
+			t.Errorf("Geo latitude is not applied")
+		}
+	})
+}
@@ -0,0 +1,56 @@
+package context
+
+import (
+	"math/rand"
+	"time"
+)
+
+// Clock provides current time for the interpreter.
+// All nondeterministic sources like current time and randomness must be retrieved through the context
+// so that testing could control them by testing.fixed_time() and testing.seed() functions
+type Clock interface {
+	Now() time.Time
+}
+
+// SystemClock is the default clock which returns actual current time
+type SystemClock struct{}
+
+func (c SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+// Now returns current time. Injected fixed time has priority over the clock
+func (c *Context) Now() time.Time {
+	if c.FixedTime != nil {
+		return *c.FixedTime
+	}
+	if c.Clock == nil {
+		return time.Now()
+	}
+	return c.Clock.Now()
+}
+
+// Rand returns random number generator, initialized with current time lazily if seed is not provided
+func (c *Context) Rand() *rand.Rand {
+	if c.random == nil {
+		c.Reseed(time.Now().UnixNano())
+	}
+	return c.random
+}
+
+// Seed resets random number generator with provided seed, the seed is kept as the one in effect
+func (c *Context) Seed(seed int64) {
+	c.Reseed(seed)
+	c.seed = &seed
+}
+
+// Reseed resets random number generator without changing the seed in effect
+func (c *Context) Reseed(seed int64) {
+	c.random = rand.New(rand.NewSource(seed)) // nolint: gosec
+}
+
+// ProvidedSeed returns the seed which is provided by option or testing.seed function,
+// nil means random number generator is seeded with current time
+func (c *Context) ProvidedSeed() *int64 {
+	return c.seed
+}
@@ -0,0 +1,41 @@
+package context
+
+import (
+	"testing"
+	"time"
+)
+
+type testClock struct {
+	now time.Time
+}
+
+func (c testClock) Now() time.Time {
+	return c.now
+}
+
+func TestContextNow(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	fixed := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	c := &Context{Clock: testClock{now: now}}
+	if actual := c.Now(); !actual.Equal(now) {
+		t.Errorf("Now() should return clock time, expect=%s, actual=%s", now, actual)
+	}
+	c.FixedTime = &fixed
+	if actual := c.Now(); !actual.Equal(fixed) {
+		t.Errorf("Now() should return fixed time, expect=%s, actual=%s", fixed, actual)
+	}
+}
+
+func TestContextSeed(t *testing.T) {
+	a := &Context{}
+	b := &Context{}
+	a.Seed(10)
+	b.Seed(10)
+
+	for range 10 {
+		if x, y := a.Rand().Int63(), b.Rand().Int63(); x != y {
+			t.Errorf("Random values should be the same for the same seed, %d != %d", x, y)
+		}
+	}
+}
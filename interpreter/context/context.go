@@ -8,8 +8,11 @@ import (
 	"github.com/ysugimoto/falco/v2/ast"
 	"github.com/ysugimoto/falco/v2/config"
 	"github.com/ysugimoto/falco/v2/interpreter/cache"
+	"github.com/ysugimoto/falco/v2/interpreter/dictionary"
 	"github.com/ysugimoto/falco/v2/interpreter/http"
+	"github.com/ysugimoto/falco/v2/interpreter/resource"
 	"github.com/ysugimoto/falco/v2/interpreter/value"
+	"github.com/ysugimoto/falco/v2/policy"
 	"github.com/ysugimoto/falco/v2/resolver"
 	"github.com/ysugimoto/falco/v2/snippet"
 	"github.com/ysugimoto/falco/v2/tester/shared"
@@ -44,6 +47,12 @@ var (
 	defaultStaleDuration, _ = time.ParseDuration("9223372036854ms") // nolint: errcheck
 )
 
+// InjectVariable provides variables which are not predefined like testing variables
+type InjectVariable interface {
+	Get(*Context, Scope, string) (value.Value, error)
+	Set(*Context, Scope, string, string, value.Value) error
+}
+
 type Context struct {
 	TLSServer           bool
 	Resolver            resolver.Resolver
@@ -54,16 +63,27 @@ type Context struct {
 	Subroutines         map[string]*ast.SubroutineDeclaration
 	Penaltyboxes        map[string]*value.Penaltybox
 	Ratecounters        map[string]*value.Ratecounter
+	Constants           map[string]*ast.ConstDeclaration
 	Gotos               map[string]*ast.GotoStatement
 	SubroutineFunctions map[string]*ast.SubroutineDeclaration
 	OriginalHost        string
 	IsActualResponse    bool
+	StrictRTime         bool
+	StrictHeaders       bool
+	// Header name patterns which are allowed to be read without setting on strict headers mode
+	ExternalHeaders []string
+	// Runtime policy for unimplemented builtin functions and variables, nil means error
+	Unimplemented *config.UnimplementedConfig
 
 	OverrideMaxBackends    int
 	OverrideMaxAcls        int
 	OverrideRequest        *config.RequestConfig
 	OverrideBackends       map[string]*config.OverrideBackend
 	InjectEdgeDictionaries map[string]config.EdgeDictionary
+	// Tables, ACLs and edge dictionaries which are loaded from external files
+	Resources *resource.Store
+	// Edge dictionaries which are read from KV storage on each request
+	DictionaryStorage *dictionary.Storage
 
 	// Mocking subroutines map
 	MockedSubroutines            map[string]*ast.SubroutineDeclaration
@@ -78,12 +98,19 @@ type Context struct {
 	RequestEndTime   time.Time
 	RequestStartTime time.Time
 	CacheHitItem     *cache.CacheItem
+	// Request ID which correlates traces, logs and backend requests of the request.
+	// X-Request-ID header of the client request is honored, otherwise fastly_info.request_id value is used
+	CorrelationID string
 
 	// RequestWorkspaceBytes tracks how much of the per-request workspace has been
 	// consumed by assembling request headers. Fastly never reclaims it within a
 	// request, not even across restarts, so this is never reset once set.
 	RequestWorkspaceBytes int
 
+	// Values which are added to req.hash in vcl_hash. req.hash holds the digest of them
+	// so they are kept to report what the cache key consists of
+	RequestHashInputs []string
+
 	// Interpreter states, following variables could be set in each subroutine directives
 	Restarts                            int
 	State                               string
@@ -159,15 +186,76 @@ type Context struct {
 	ReturnState *value.String
 	// Stored functional subroutine return value
 	TestingReturnValue value.Value
+	// Table entry of the test iteration which is generated from the table by @table annotation
+	TestingTableEntry *ast.TableProperty
 	// Injected fixed time for `now`, `now.sec`, etc
 	FixedTime *time.Time
+	// Clock for current time, SystemClock is used if nil
+	Clock Clock
 	// Count of subroutine called
 	SubroutineCalls map[string]int
+	// Subroutine names in called order
+	SubroutineCallTrace []string
+	// Recorded backend requests in sending order
+	BackendRequests []*BackendRequest
+	// Testing hook subroutines which are processed before sending backend request and after receiving backend response
+	BeforeFetchHook *ast.SubroutineDeclaration
+	AfterFetchHook  *ast.SubroutineDeclaration
 	// Injected fixed access rate
 	FixedAccessRate *float64
+	// Mocked backend responses keyed by backend name, mocked backend does not receive the request
+	MockBackendResponses map[string]*MockBackendResponse
+	// Original properties of tables which are modified by testing functions, restored before each test case
+	TableBackups map[string][]*ast.TableProperty
+	// ACL entries which are injected by testing functions keyed by ACL name, reset before each test case
+	InjectedAclEntries map[string][]*ast.AclCidr
+	// Original values of override variables which are overridden by testing functions, restored before each test case.
+	// nil value means that the variable has not been overridden
+	OverrideBackups map[string]value.Value
+	// If true, failed assertions are collected instead of aborting the test case, reset before each test case
+	SoftAssertions bool
+	// Assertion failures which are collected in soft assertion mode
+	AssertionFailures []error
+	// Results of subtests which are processed by testing.run in the test case, reset before each test case
+	Subtests []*Subtest
+	// Name of the running subtest, used as the prefix of nested subtests
+	SubtestName string
 
 	// Coverage marker pointer. not nil if testing with coverage measurement
 	Coverage *shared.Coverage
+	// Count of coverage markers which are executed in this context, used for asserting coverage in the test
+	CoverageHits map[string]uint64
+	// If true, coverage markers are instrumented only on testing process and simulator uses raw AST
+	InstrumentTestOnly bool
+
+	// Variables which are injected only to this context like testing variables.
+	// Unlike variable.Inject, contexts which run concurrently could have their own variables
+	InjectedVariable InjectVariable
+
+	// Policy evaluator for execution traces. not nil if policy files are provided
+	Policy *policy.Evaluator
+	// Header name patterns which must never be exposed to clients or logs, checked on the simulator
+	ProtectedHeaders []string
+
+	// If true, record all variable and header accesses with the statement location
+	Provenance bool
+
+	// If true, explain why the request results in the cache state
+	Explain bool
+
+	// If true, the backend request is not sent and the cache is not used, set by Interpreter.DryRun
+	DryRun bool
+
+	// Flow diagram format of the state machine transitions, mermaid or ascii. Empty means disabled
+	FlowDiagram string
+
+	// Directory to output recorded execution trace files. Empty means trace recording is disabled
+	TraceDir string
+
+	// Random number generator, use Rand() method to retrieve it
+	random *rand.Rand
+	// Seed which is provided explicitly, use ProvidedSeed() method to retrieve it
+	seed *int64
 
 	// Regex captured values like "re.group.N" and local declared variables are volatile,
 	// reset this when process is outgoing for each subroutines
@@ -202,6 +290,7 @@ func New(options ...Option) *Context {
 		Subroutines:            make(map[string]*ast.SubroutineDeclaration),
 		Penaltyboxes:           make(map[string]*value.Penaltybox),
 		Ratecounters:           make(map[string]*value.Ratecounter),
+		Constants:              make(map[string]*ast.ConstDeclaration),
 		Gotos:                  make(map[string]*ast.GotoStatement),
 		SubroutineFunctions:    make(map[string]*ast.SubroutineDeclaration),
 		OverrideBackends:       make(map[string]*config.OverrideBackend),
@@ -211,7 +300,6 @@ func New(options ...Option) *Context {
 		MockedFunctioncalSubroutines: make(map[string]*ast.SubroutineDeclaration),
 
 		CacheHitItem:                    nil,
-		RequestStartTime:                time.Now(),
 		State:                           "NONE",
 		Backend:                         nil,
 		ClientIdentity:                  nil,
@@ -237,12 +325,6 @@ func New(options ...Option) *Context {
 		ESILevel:                        &value.Integer{},
 		RequestHash:                     &value.String{},
 
-		// The format of the request ID is not documented.
-		// Observations indicate that it is a zero padded hex representation of two
-		// 64-bit values The first 64-bits are seemingly random, the nature of the
-		// apparent randomness is unknown. The second 64-bit value is always 1.
-		RequestID: &value.String{Value: fmt.Sprintf("%016x0000000000000001", rand.Int63())},
-
 		WafAnomalyScore:                     &value.Integer{},
 		WafBlocked:                          &value.Boolean{},
 		WafCounter:                          &value.Integer{},
@@ -289,6 +371,7 @@ func New(options ...Option) *Context {
 
 		RegexMatchedValues: make(map[string]*value.String),
 		SubroutineCalls:    make(map[string]int),
+		CoverageHits:       make(map[string]uint64),
 
 		OverrideVariables: make(map[string]value.Value),
 	}
@@ -298,5 +381,13 @@ func New(options ...Option) *Context {
 		options[i](ctx)
 	}
 
+	// Following values depend on the clock and random seed which may be provided via options
+	ctx.RequestStartTime = ctx.Now()
+	// The format of the request ID is not documented.
+	// Observations indicate that it is a zero padded hex representation of two
+	// 64-bit values The first 64-bits are seemingly random, the nature of the
+	// apparent randomness is unknown. The second 64-bit value is always 1.
+	ctx.RequestID = &value.String{Value: fmt.Sprintf("%016x0000000000000001", ctx.Rand().Int63())}
+
 	return ctx
 }
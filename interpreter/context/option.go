@@ -4,7 +4,11 @@ import (
 	"time"
 
 	"github.com/ysugimoto/falco/v2/config"
+	"github.com/ysugimoto/falco/v2/interpreter/dictionary"
+	fshared "github.com/ysugimoto/falco/v2/interpreter/function/shared"
+	"github.com/ysugimoto/falco/v2/interpreter/resource"
 	"github.com/ysugimoto/falco/v2/interpreter/value"
+	"github.com/ysugimoto/falco/v2/policy"
 	"github.com/ysugimoto/falco/v2/resolver"
 	"github.com/ysugimoto/falco/v2/snippet"
 	"github.com/ysugimoto/falco/v2/tester/shared"
@@ -48,8 +52,12 @@ func WithOverrideBackends(ov map[string]*config.OverrideBackend) Option {
 	}
 }
 
+// Internationalized hostname is converted to punycode like browsers send the Host header
 func WithOverrideHost(host string) Option {
 	return func(c *Context) {
+		if encoded, err := fshared.IdnaToASCII(host); err == nil {
+			host = encoded
+		}
 		c.OriginalHost = host
 	}
 }
@@ -60,6 +68,42 @@ func WithInjectEdgeDictionaries(ed map[string]config.EdgeDictionary) Option {
 	}
 }
 
+func WithResources(s *resource.Store) Option {
+	return func(c *Context) {
+		c.Resources = s
+	}
+}
+
+func WithDictionaryStorage(s *dictionary.Storage) Option {
+	return func(c *Context) {
+		c.DictionaryStorage = s
+	}
+}
+
+func WithStrictRTime(v bool) Option {
+	return func(c *Context) {
+		c.StrictRTime = v
+	}
+}
+
+func WithStrictHeaders(v bool) Option {
+	return func(c *Context) {
+		c.StrictHeaders = v
+	}
+}
+
+func WithExternalHeaders(names []string) Option {
+	return func(c *Context) {
+		c.ExternalHeaders = names
+	}
+}
+
+func WithUnimplemented(u *config.UnimplementedConfig) Option {
+	return func(c *Context) {
+		c.Unimplemented = u
+	}
+}
+
 func WithActualResponse(is bool) Option {
 	return func(c *Context) {
 		c.IsActualResponse = is
@@ -91,6 +135,20 @@ func WithCoverage(cv *shared.Coverage) Option {
 	}
 }
 
+func WithInjectedVariable(v InjectVariable) Option {
+	return func(c *Context) {
+		c.InjectedVariable = v
+	}
+}
+
+// WithInstrumentTestOnly prevents coverage instrumentation on simulator process
+// so that simulator or load testing which shares coverage options with testing is not slowed down
+func WithInstrumentTestOnly(v bool) Option {
+	return func(c *Context) {
+		c.InstrumentTestOnly = v
+	}
+}
+
 func WithTLServer(tls bool) Option {
 	return func(c *Context) {
 		c.TLSServer = tls
@@ -102,3 +160,51 @@ func WithFixedTime(t time.Time) Option {
 		c.FixedTime = &t
 	}
 }
+
+func WithClock(clock Clock) Option {
+	return func(c *Context) {
+		c.Clock = clock
+	}
+}
+
+func WithSeed(seed int64) Option {
+	return func(c *Context) {
+		c.Seed(seed)
+	}
+}
+
+func WithPolicy(p *policy.Evaluator) Option {
+	return func(c *Context) {
+		c.Policy = p
+	}
+}
+
+func WithProtectedHeaders(names []string) Option {
+	return func(c *Context) {
+		c.ProtectedHeaders = names
+	}
+}
+
+func WithProvenance(enable bool) Option {
+	return func(c *Context) {
+		c.Provenance = enable
+	}
+}
+
+func WithExplain(enable bool) Option {
+	return func(c *Context) {
+		c.Explain = enable
+	}
+}
+
+func WithFlowDiagram(format string) Option {
+	return func(c *Context) {
+		c.FlowDiagram = format
+	}
+}
+
+func WithTraceDir(dir string) Option {
+	return func(c *Context) {
+		c.TraceDir = dir
+	}
+}
@@ -0,0 +1,9 @@
+package context
+
+// Subtest is the result of the subtest which is processed by testing.run in the test case.
+// Name of the nested subtest is prefixed by the names of enclosing subtests
+type Subtest struct {
+	Name  string
+	Error error
+	Time  int64 // msec order
+}
@@ -11,20 +11,88 @@ import (
 
 var fake = &ast.Meta{Token: token.Null}
 
+// Coverage exclusion pragmas in VCL comments
+const (
+	coverageIgnore      = "falco:coverage-ignore"
+	coverageIgnoreStart = "falco:coverage-ignore-start"
+	coverageIgnoreEnd   = "falco:coverage-ignore-end"
+)
+
 // Add coverage marker to entire VCL
 // Note that our coverage measurement will ignores root declarations like backend, table, etc.
 func (i *Interpreter) instrument(vcl *ast.VCL) {
-	for _, v := range vcl.Statements {
-		if sub, ok := v.(*ast.SubroutineDeclaration); ok {
+	excluded := i.excludeStatements(vcl.Statements)
+	for j, v := range vcl.Statements {
+		if sub, ok := v.(*ast.SubroutineDeclaration); ok && !excluded[j] {
 			i.instrumentSubroutine(sub)
 		}
 	}
 }
 
+// Find statements which are excluded from coverage by pragma comments and record excluded regions.
+// "falco:coverage-ignore" in leading or trailing comments excludes the statement including its nested blocks,
+// and "falco:coverage-ignore-start" excludes following statements until "falco:coverage-ignore-end" or the end of block
+func (i *Interpreter) excludeStatements(stmts []ast.Statement) []bool {
+	excluded := make([]bool, len(stmts))
+
+	// Each pragma and each ignore-start/end range is recorded as its own region,
+	// rangeStart is the index of the first statement in the range or -1 if not in the range
+	rangeStart := -1
+	for j := range stmts {
+		meta := stmts[j].GetMeta()
+		var ignore bool
+		for _, c := range meta.Leading {
+			switch coveragePragma(c) {
+			case coverageIgnore:
+				ignore = true
+			case coverageIgnoreStart:
+				if rangeStart < 0 {
+					rangeStart = j
+				}
+			case coverageIgnoreEnd:
+				if rangeStart >= 0 && rangeStart < j {
+					i.ctx.Coverage.Exclude(stmts[rangeStart].GetMeta().Token, stmts[j-1].GetMeta().EndLine)
+				}
+				rangeStart = -1
+			}
+		}
+		for _, c := range meta.Trailing {
+			if coveragePragma(c) == coverageIgnore {
+				ignore = true
+			}
+		}
+
+		switch {
+		case rangeStart >= 0:
+			// The statement is a part of the range region even if it has its own pragma
+			excluded[j] = true
+		case ignore:
+			excluded[j] = true
+			i.ctx.Coverage.Exclude(meta.Token, meta.EndLine)
+		}
+	}
+	// Range which is not closed excludes statements until the end of block
+	if rangeStart >= 0 {
+		i.ctx.Coverage.Exclude(stmts[rangeStart].GetMeta().Token, stmts[len(stmts)-1].GetMeta().EndLine)
+	}
+
+	return excluded
+}
+
+// Get pragma name from the comment like "// falco:coverage-ignore generated code"
+func coveragePragma(c *ast.Comment) string {
+	body := strings.TrimSuffix(strings.TrimLeft(c.String(), "#/* "), "*/")
+	pragma, _, _ := strings.Cut(strings.TrimSpace(body), " ")
+	return pragma
+}
+
 // Add coverage marker to subroutine declaration
 func (i *Interpreter) instrumentSubroutine(sub *ast.SubroutineDeclaration) {
 	var statements []ast.Statement
 
+	i.coveragePaths = structuralPaths(sub)
+	defer func() { i.coveragePaths = nil }()
+
 	statements = append(statements, i.createMarker(shared.CoverageTypeSubroutine, sub))
 	statements = append(statements, i.instrumentStatements(sub.Block.Statements)...)
 	sub.Block.Statements = statements
@@ -34,14 +102,54 @@ func (i *Interpreter) instrumentSubroutine(sub *ast.SubroutineDeclaration) {
 func (i *Interpreter) instrumentStatements(stmts []ast.Statement) []ast.Statement {
 	var statements []ast.Statement
 
+	excluded := i.excludeStatements(stmts)
+	dead := i.deadStatements(stmts, excluded)
 	for j := range stmts {
-		statements = append(statements, i.instrumentStatement(stmts[j])...)
+		if !excluded[j] && !dead[j] {
+			statements = append(statements, i.instrumentStatement(stmts[j])...)
+		}
 		statements = append(statements, stmts[j])
 	}
 
 	return statements
 }
 
+// Find statements which can never be executed because they follow unconditional return, error, restart or goto statement
+// in the same block, and record dead regions. Goto destination makes following statements reachable again.
+// Dead statements are not instrumented so that they do not hold coverage below 100%
+func (i *Interpreter) deadStatements(stmts []ast.Statement, excluded []bool) []bool {
+	dead := make([]bool, len(stmts))
+
+	var unreachable bool
+	for j := range stmts {
+		switch stmts[j].(type) {
+		case *ast.GotoDestinationStatement:
+			unreachable = false
+			continue
+		}
+		dead[j] = unreachable && !excluded[j]
+		switch stmts[j].(type) {
+		case *ast.ReturnStatement, *ast.ErrorStatement, *ast.RestartStatement, *ast.GotoStatement:
+			unreachable = true
+		}
+	}
+
+	// Consecutive dead statements are recorded as single region
+	for j := 0; j < len(stmts); j++ {
+		if !dead[j] {
+			continue
+		}
+		end := j
+		for end+1 < len(stmts) && dead[end+1] {
+			end++
+		}
+		i.ctx.Coverage.MarkDead(stmts[j].GetMeta().Token, stmts[end].GetMeta().EndLine)
+		j = end
+	}
+
+	return dead
+}
+
 // Add coverage marker to single statement
 func (i *Interpreter) instrumentStatement(stmt ast.Statement) []ast.Statement {
 	var statements []ast.Statement
@@ -54,10 +162,13 @@ func (i *Interpreter) instrumentStatement(stmt ast.Statement) []ast.Statement {
 
 	case *ast.IfStatement:
 		statements = append(statements, i.createMarker(shared.CoverageTypeStatement, t))
+		statements = append(statements, i.instrumentDecision(t, t.Condition)...)
+		statements = append(statements, i.instrumentExpression(t.Condition)...)
 		i.instrumentIfStatement(t)
 
 	case *ast.SwitchStatement:
 		statements = append(statements, i.createMarker(shared.CoverageTypeStatement, t))
+		statements = append(statements, i.instrumentExpression(t.Control.Expression)...)
 		i.instrumentSwitchStatement(t)
 
 	// Instrumenting for statement with specific argument expression(s)
@@ -71,6 +182,7 @@ func (i *Interpreter) instrumentStatement(stmt ast.Statement) []ast.Statement {
 	// Instrumenting for statement with single expression
 	case *ast.SetStatement:
 		statements = append(statements, i.createMarker(shared.CoverageTypeStatement, stmt))
+		statements = append(statements, i.instrumentLogicalExpression(t.Value)...)
 		statements = append(statements, i.instrumentExpression(t.Value)...)
 	case *ast.AddStatement:
 		statements = append(statements, i.createMarker(shared.CoverageTypeStatement, stmt))
@@ -84,12 +196,21 @@ func (i *Interpreter) instrumentStatement(stmt ast.Statement) []ast.Statement {
 	case *ast.SyntheticBase64Statement:
 		statements = append(statements, i.createMarker(shared.CoverageTypeStatement, stmt))
 		statements = append(statements, i.instrumentExpression(t.Value)...)
+	case *ast.CallStatement:
+		statements = append(statements, i.createMarker(shared.CoverageTypeStatement, stmt))
+		for _, arg := range t.Arguments {
+			statements = append(statements, i.instrumentExpression(arg)...)
+		}
+	case *ast.DeclareStatement:
+		statements = append(statements, i.createMarker(shared.CoverageTypeStatement, stmt))
+		if t.Value != nil {
+			statements = append(statements, i.instrumentExpression(t.Value)...)
+		}
 
 	// Default without expression instrument
 	default:
 		// *ast.EsiStatement
 		// *ast.RestartStatement
-		// *ast.DeclareStatement
 		// *ast.UnsetStatement
 		// *ast.RemoveStatement
 		// *ast.BreakStatement
@@ -155,14 +276,14 @@ func (i *Interpreter) instrumentIfStatement(stmt *ast.IfStatement) {
 		branch++
 		a.Keyword = "if"
 		i.instrumentIfStatement(a)
+		statements := []ast.Statement{i.createMarker(shared.CoverageTypeBranch, stmt, fmt.Sprint(branch))}
+		statements = append(statements, i.instrumentDecision(a, a.Condition)...)
+		statements = append(statements, i.instrumentExpression(a.Condition)...)
 		nest.Alternative = &ast.ElseStatement{
 			Meta: fake,
 			Consequence: &ast.BlockStatement{
-				Meta: fake,
-				Statements: []ast.Statement{
-					i.createMarker(shared.CoverageTypeBranch, stmt, fmt.Sprint(branch)),
-					a,
-				},
+				Meta:       fake,
+				Statements: append(statements, a),
 			},
 		}
 		nest = a
@@ -244,9 +365,11 @@ func (i *Interpreter) instrumentErrorStatement(stmt *ast.ErrorStatement) []ast.S
 
 	statements = append(statements, i.createMarker(shared.CoverageTypeStatement, stmt))
 	if stmt.Code != nil {
+		statements = append(statements, i.instrumentLogicalExpression(stmt.Code)...)
 		statements = append(statements, i.instrumentExpression(stmt.Code)...)
 	}
 	if stmt.Argument != nil {
+		statements = append(statements, i.instrumentLogicalExpression(stmt.Argument)...)
 		statements = append(statements, i.instrumentExpression(stmt.Argument)...)
 	}
 
@@ -258,6 +381,7 @@ func (i *Interpreter) instrumentReturnStatement(stmt *ast.ReturnStatement) []ast
 
 	statements = append(statements, i.createMarker(shared.CoverageTypeStatement, stmt))
 	if stmt.ReturnExpression != nil {
+		statements = append(statements, i.instrumentLogicalExpression(stmt.ReturnExpression)...)
 		statements = append(statements, i.instrumentExpression(stmt.ReturnExpression)...)
 	}
 
@@ -276,7 +400,7 @@ func (i *Interpreter) instrumentExpression(expr ast.Expression) []ast.Statement
 		statements = append(statements, i.instrumentExpression(t.Right)...)
 	case *ast.InfixExpression:
 		statements = append(statements, i.instrumentExpression(t.Left)...)
-		statements = append(statements, i.instrumentExpression(t.Right)...)
+		statements = append(statements, i.instrumentRightOperand(t)...)
 	case *ast.PostfixExpression:
 		statements = append(statements, i.instrumentExpression(t.Left)...)
 	case *ast.PrefixExpression:
@@ -290,21 +414,27 @@ func (i *Interpreter) instrumentExpression(expr ast.Expression) []ast.Statement
 
 // Put conditions and branches instruments to if expression.
 // Note that on instrumenting, we need to cover the consequence/alternative expression.
+// Nested if expressions in the consequence or alternative are instrumented inside the branch
+// because only the chosen argument is evaluated.
 //
 // Before:
 //
-//	set req.http.Foo = if(req.http.Bar, "a", "b");
+//	set req.http.Foo = if(req.http.Bar, if(req.http.Baz, "a", "b"), "c");
 //
 // After:
 //
 //	[statement of set statement]
-//	[statement of if expression]
 //	if (req.http.Bar) {
-//	  [branch of "a"]
+//	  [branch of outer if expression_true]
+//	  if (req.http.Baz) {
+//	    [branch of inner if expression_true]
+//	  } else {
+//	    [branch of inner if expression_false]
+//	  }
 //	} else {
-//	  [branch of "b"]
+//	  [branch of outer if expression_false]
 //	}
-//	set req.http.Foo = if(req.http.Bar, "a", "b");
+//	set req.http.Foo = if(req.http.Bar, if(req.http.Baz, "a", "b"), "c");
 func (i *Interpreter) instrumentIfExpression(expr *ast.IfExpression) []ast.Statement {
 	branch := &ast.IfStatement{
 		Keyword:   "if",
@@ -312,22 +442,294 @@ func (i *Interpreter) instrumentIfExpression(expr *ast.IfExpression) []ast.State
 		Condition: expr.Condition,
 		Consequence: &ast.BlockStatement{
 			Meta: fake,
-			Statements: []ast.Statement{
-				i.createMarker(shared.CoverageTypeBranch, expr, "true"),
-			},
+			Statements: append(
+				[]ast.Statement{i.createMarker(shared.CoverageTypeBranch, expr, "true")},
+				i.instrumentExpression(expr.Consequence)...,
+			),
 		},
 		Alternative: &ast.ElseStatement{
 			Meta: fake,
 			Consequence: &ast.BlockStatement{
 				Meta: fake,
-				Statements: []ast.Statement{
-					i.createMarker(shared.CoverageTypeBranch, expr, "false"),
+				Statements: append(
+					[]ast.Statement{i.createMarker(shared.CoverageTypeBranch, expr, "false")},
+					i.instrumentExpression(expr.Alternative)...,
+				),
+			},
+		},
+	}
+
+	// Condition is always evaluated so if expressions in the condition are instrumented before the branch
+	return append(i.instrumentExpression(expr.Condition), branch)
+}
+
+// Instrument right operand of the infix expression.
+// Right operand of logical expression is evaluated only on short-circuit evaluation is not satisfied,
+// so that instruments of the operand are guarded by the left operand.
+//
+// Before:
+//
+//	set var.ok = req.http.A || if(req.http.B, true, false);
+//
+// After:
+//
+//	[statement of set statement]
+//	if (req.http.A) {
+//	} else {
+//	  [instruments of if(req.http.B, true, false)]
+//	}
+//	set var.ok = req.http.A || if(req.http.B, true, false);
+func (i *Interpreter) instrumentRightOperand(expr *ast.InfixExpression) []ast.Statement {
+	right := i.instrumentExpression(expr.Right)
+	if len(right) == 0 {
+		return nil
+	}
+
+	guard := &ast.IfStatement{
+		Keyword:   "if",
+		Meta:      fake,
+		Condition: expr.Left,
+	}
+	switch expr.Operator {
+	case "&&":
+		guard.Consequence = &ast.BlockStatement{Meta: fake, Statements: right}
+	case "||":
+		guard.Consequence = &ast.BlockStatement{Meta: fake, Statements: []ast.Statement{}}
+		guard.Alternative = &ast.ElseStatement{
+			Meta:        fake,
+			Consequence: &ast.BlockStatement{Meta: fake, Statements: right},
+		}
+	default:
+		return right
+	}
+	return []ast.Statement{guard}
+}
+
+// Put branches instruments to each operand of top-level logical expression.
+// Note that on instrumenting, we need to follow short-circuit evaluation,
+// so that the operand is instrumented only when it is evaluated.
+//
+// Before:
+//
+//	set var.ok = req.http.A && (req.http.B || req.http.C);
+//
+// After:
+//
+//	[statement of set statement]
+//	if (req.http.A) {
+//	  [branch of req.http.A_true]
+//	  if (req.http.B) {
+//	    [branch of req.http.B_true]
+//	  } else {
+//	    [branch of req.http.B_false]
+//	    if (req.http.C) {
+//	      [branch of req.http.C_true]
+//	    } else {
+//	      [branch of req.http.C_false]
+//	    }
+//	  }
+//	} else {
+//	  [branch of req.http.A_false]
+//	}
+//	set var.ok = req.http.A && (req.http.B || req.http.C);
+func (i *Interpreter) instrumentLogicalExpression(expr ast.Expression) []ast.Statement {
+	if !isLogicalExpression(expr) {
+		return nil
+	}
+	return i.instrumentCondition(expr, nil, nil)
+}
+
+// Instrument condition with statements which are processed after the condition is evaluated as true or false
+func (i *Interpreter) instrumentCondition(expr ast.Expression, onTrue, onFalse []ast.Statement) []ast.Statement {
+	switch t := expr.(type) {
+	case *ast.GroupedExpression:
+		return i.instrumentCondition(t.Right, onTrue, onFalse)
+	case *ast.InfixExpression:
+		switch t.Operator {
+		case "&&":
+			// Right operand is evaluated only when left operand is true
+			return i.instrumentCondition(t.Left, i.instrumentCondition(t.Right, onTrue, onFalse), onFalse)
+		case "||":
+			// Right operand is evaluated only when left operand is false
+			return i.instrumentCondition(t.Left, onTrue, i.instrumentCondition(t.Right, onTrue, onFalse))
+		}
+	}
+
+	return []ast.Statement{
+		&ast.IfStatement{
+			Keyword:   "if",
+			Meta:      fake,
+			Condition: expr,
+			Consequence: &ast.BlockStatement{
+				Meta: fake,
+				Statements: append(
+					[]ast.Statement{i.createMarker(shared.CoverageTypeBranch, expr, "true")},
+					onTrue...,
+				),
+			},
+			Alternative: &ast.ElseStatement{
+				Meta: fake,
+				Consequence: &ast.BlockStatement{
+					Meta: fake,
+					Statements: append(
+						[]ast.Statement{i.createMarker(shared.CoverageTypeBranch, expr, "false")},
+						onFalse...,
+					),
 				},
 			},
 		},
 	}
+}
+
+// Maximum number of conditions in the decision which is measured for MC/DC.
+// Instrumented statements grow exponentially for deeply nested conditions, so larger decisions are not measured
+const maxDecisionConditions = 8
+
+// Put MC/DC instruments to compound condition of if statement.
+// Each path of short-circuit evaluation records the evaluation vector which consists of values of conditions
+// and the decision outcome, then the report finds pairs of vectors which show the condition independently affects the outcome.
+//
+// Before:
+//
+//	if (req.http.A && (req.http.B || req.http.C)) {
+//	  ...
+//	}
+//
+// After:
+//
+//	[statement of if statement]
+//	if (req.http.A) {
+//	  if (req.http.B) {
+//	    [decision of "TT-:T"]
+//	  } else {
+//	    if (req.http.C) {
+//	      [decision of "TFT:T"]
+//	    } else {
+//	      [decision of "TFF:F"]
+//	    }
+//	  }
+//	} else {
+//	  [decision of "F--:F"]
+//	}
+//	if (req.http.A && (req.http.B || req.http.C)) {
+//	  ...
+//	}
+func (i *Interpreter) instrumentDecision(node ast.Node, expr ast.Expression) []ast.Statement {
+	if !isLogicalExpression(expr) {
+		return nil
+	}
+	conditions := collectConditions(expr)
+	if len(conditions) > maxDecisionConditions {
+		return nil
+	}
+
+	tok := node.GetMeta().Token
+	id := fmt.Sprintf("decision_%d_%d", tok.Line, tok.Position)
+	if tok.File != "" {
+		id = tok.File + ":" + id
+	}
+	decision := &shared.Decision{
+		ID:       id,
+		File:     tok.File,
+		Line:     tok.Line,
+		Position: tok.Position,
+	}
+	index := make(map[ast.Expression]int, len(conditions))
+	values := make([]byte, len(conditions))
+	for n, c := range conditions {
+		index[c] = n
+		values[n] = shared.ConditionNotEvaluated
+		decision.Conditions = append(decision.Conditions, c.String())
+	}
+	i.ctx.Coverage.SetupDecision(decision)
+	if path, ok := i.coveragePaths[node]; ok {
+		i.ctx.Coverage.SetStableID(id, path)
+	}
 
-	return []ast.Statement{branch}
+	outcome := func(v bool) func([]byte) []ast.Statement {
+		return func(values []byte) []ast.Statement {
+			return []ast.Statement{
+				coverageCall("coverage.decision", id, shared.Vector(values, v)),
+			}
+		}
+	}
+	return instrumentDecisionCondition(expr, index, values, outcome(true), outcome(false))
+}
+
+// Instrument the condition with continuations which receive the evaluation vector after the condition is evaluated
+func instrumentDecisionCondition(
+	expr ast.Expression,
+	index map[ast.Expression]int,
+	values []byte,
+	onTrue, onFalse func([]byte) []ast.Statement,
+) []ast.Statement {
+
+	switch t := expr.(type) {
+	case *ast.GroupedExpression:
+		return instrumentDecisionCondition(t.Right, index, values, onTrue, onFalse)
+	case *ast.InfixExpression:
+		switch t.Operator {
+		case "&&":
+			// Right operand is evaluated only when left operand is true
+			return instrumentDecisionCondition(t.Left, index, values, func(v []byte) []ast.Statement {
+				return instrumentDecisionCondition(t.Right, index, v, onTrue, onFalse)
+			}, onFalse)
+		case "||":
+			// Right operand is evaluated only when left operand is false
+			return instrumentDecisionCondition(t.Left, index, values, onTrue, func(v []byte) []ast.Statement {
+				return instrumentDecisionCondition(t.Right, index, v, onTrue, onFalse)
+			})
+		}
+	}
+
+	n := index[expr]
+	tv := append([]byte{}, values...)
+	tv[n] = shared.ConditionTrue
+	fv := append([]byte{}, values...)
+	fv[n] = shared.ConditionFalse
+
+	return []ast.Statement{
+		&ast.IfStatement{
+			Keyword:   "if",
+			Meta:      fake,
+			Condition: expr,
+			Consequence: &ast.BlockStatement{
+				Meta:       fake,
+				Statements: onTrue(tv),
+			},
+			Alternative: &ast.ElseStatement{
+				Meta: fake,
+				Consequence: &ast.BlockStatement{
+					Meta:       fake,
+					Statements: onFalse(fv),
+				},
+			},
+		},
+	}
+}
+
+// Collect operands of logical expression which are not logical expression in evaluation order
+func collectConditions(expr ast.Expression) []ast.Expression {
+	switch t := expr.(type) {
+	case *ast.GroupedExpression:
+		return collectConditions(t.Right)
+	case *ast.InfixExpression:
+		if t.Operator == "&&" || t.Operator == "||" {
+			return append(collectConditions(t.Left), collectConditions(t.Right)...)
+		}
+	}
+	return []ast.Expression{expr}
+}
+
+func isLogicalExpression(expr ast.Expression) bool {
+	switch t := expr.(type) {
+	case *ast.GroupedExpression:
+		return isLogicalExpression(t.Right)
+	case *ast.InfixExpression:
+		return t.Operator == "&&" || t.Operator == "||"
+	default:
+		return false
+	}
 }
 
 // Create coverage marker and put cover function into the VCL statements
@@ -340,18 +742,43 @@ func (i *Interpreter) createMarker(t shared.CoverageType, node ast.Node, suffix
 		s = "_" + strings.Join(suffix, "_")
 	}
 
+	// Identically positioned nodes could be present in different included files,
+	// so prefix the source file to the marker id in order not to collide
+	var file string
+	if tok.File != "" {
+		file = tok.File + ":"
+	}
+
 	var id string
 	switch t {
 	case shared.CoverageTypeSubroutine:
-		id = fmt.Sprintf("sub_%d_%d", tok.Line, tok.Position) + s
+		id = file + fmt.Sprintf("sub_%d_%d", tok.Line, tok.Position) + s
 		i.ctx.Coverage.SetupSubroutine(id, node)
 	case shared.CoverageTypeStatement:
-		id = fmt.Sprintf("stmt_%d_%d", tok.Line, tok.Position) + s
+		id = file + fmt.Sprintf("stmt_%d_%d", tok.Line, tok.Position) + s
 		i.ctx.Coverage.SetupStatement(id, node)
 	case shared.CoverageTypeBranch:
-		id = fmt.Sprintf("branch_%d_%d", tok.Line, tok.Position) + s
+		id = file + fmt.Sprintf("branch_%d_%d", tok.Line, tok.Position) + s
 		i.ctx.Coverage.SetupBranch(id, node)
 	}
+	if path, ok := i.coveragePaths[node]; ok {
+		i.ctx.Coverage.SetStableID(id, path+s)
+	}
+
+	return coverageCall(name, id)
+}
+
+// Create function call statement of the coverage function with string arguments
+func coverageCall(name string, args ...string) ast.Statement {
+	arguments := make([]ast.Expression, len(args))
+	for j, arg := range args {
+		arguments[j] = &ast.String{
+			Meta: &ast.Meta{
+				Token: token.Token{Type: token.STRING, Literal: arg},
+			},
+			Value: arg,
+		}
+	}
 
 	return &ast.FunctionCallStatement{
 		Meta: fake,
@@ -361,13 +788,6 @@ func (i *Interpreter) createMarker(t shared.CoverageType, node ast.Node, suffix
 			},
 			Value: name,
 		},
-		Arguments: []ast.Expression{
-			&ast.String{
-				Meta: &ast.Meta{
-					Token: token.Token{Type: token.STRING, Literal: id},
-				},
-				Value: id,
-			},
-		},
+		Arguments: arguments,
 	}
 }
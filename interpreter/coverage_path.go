@@ -0,0 +1,113 @@
+package interpreter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ysugimoto/falco/v2/ast"
+)
+
+// Build structural paths of nodes in the subroutine, which identify coverage markers independently of source positions.
+// The path consists of the subroutine name, kinds and indexes of statements in each block and roles of nested expressions
+// like "vcl_recv/if[2]/then/set[0]/value/right", so that formatting-only changes do not change paths.
+// Grouped expressions are transparent because parentheses could be changed without changing the structure
+func structuralPaths(sub *ast.SubroutineDeclaration) map[ast.Node]string {
+	paths := map[ast.Node]string{
+		sub: sub.Name.Value,
+	}
+	walkStatementPaths(paths, sub.Name.Value, sub.Block.Statements)
+	return paths
+}
+
+func walkStatementPaths(paths map[ast.Node]string, parent string, stmts []ast.Statement) {
+	for index, stmt := range stmts {
+		path := fmt.Sprintf("%s/%s[%d]", parent, statementKind(stmt), index)
+		paths[stmt] = path
+
+		switch t := stmt.(type) {
+		case *ast.BlockStatement:
+			walkStatementPaths(paths, path, t.Statements)
+		case *ast.IfStatement:
+			walkIfStatementPaths(paths, path, t)
+		case *ast.SwitchStatement:
+			walkExpressionPaths(paths, path+"/control", t.Control.Expression)
+			for j, c := range t.Cases {
+				p := fmt.Sprintf("%s/case[%d]", path, j)
+				paths[c] = p
+				walkStatementPaths(paths, p, c.Statements)
+			}
+		case *ast.SetStatement:
+			walkExpressionPaths(paths, path+"/value", t.Value)
+		case *ast.AddStatement:
+			walkExpressionPaths(paths, path+"/value", t.Value)
+		case *ast.DeclareStatement:
+			walkExpressionPaths(paths, path+"/value", t.Value)
+		case *ast.LogStatement:
+			walkExpressionPaths(paths, path+"/value", t.Value)
+		case *ast.SyntheticStatement:
+			walkExpressionPaths(paths, path+"/value", t.Value)
+		case *ast.SyntheticBase64Statement:
+			walkExpressionPaths(paths, path+"/value", t.Value)
+		case *ast.ReturnStatement:
+			walkExpressionPaths(paths, path+"/value", t.ReturnExpression)
+		case *ast.ErrorStatement:
+			walkExpressionPaths(paths, path+"/code", t.Code)
+			walkExpressionPaths(paths, path+"/argument", t.Argument)
+		case *ast.CallStatement:
+			walkArgumentPaths(paths, path, t.Arguments)
+		case *ast.FunctionCallStatement:
+			walkArgumentPaths(paths, path, t.Arguments)
+		}
+	}
+}
+
+// Else-if statements are numbered in the root if statement because they are flattened on parsing
+func walkIfStatementPaths(paths map[ast.Node]string, path string, stmt *ast.IfStatement) {
+	walkExpressionPaths(paths, path+"/cond", stmt.Condition)
+	walkStatementPaths(paths, path+"/then", stmt.Consequence.Statements)
+	for j, another := range stmt.Another {
+		p := fmt.Sprintf("%s/elseif[%d]", path, j)
+		paths[another] = p
+		walkIfStatementPaths(paths, p, another)
+	}
+	if stmt.Alternative != nil {
+		walkStatementPaths(paths, path+"/else", stmt.Alternative.Consequence.Statements)
+	}
+}
+
+func walkArgumentPaths(paths map[ast.Node]string, path string, args []ast.Expression) {
+	for j, arg := range args {
+		walkExpressionPaths(paths, fmt.Sprintf("%s/arg[%d]", path, j), arg)
+	}
+}
+
+func walkExpressionPaths(paths map[ast.Node]string, path string, expr ast.Expression) {
+	if expr == nil {
+		return
+	}
+	paths[expr] = path
+
+	switch t := expr.(type) {
+	case *ast.GroupedExpression:
+		walkExpressionPaths(paths, path, t.Right)
+	case *ast.InfixExpression:
+		walkExpressionPaths(paths, path+"/left", t.Left)
+		walkExpressionPaths(paths, path+"/right", t.Right)
+	case *ast.PrefixExpression:
+		walkExpressionPaths(paths, path+"/right", t.Right)
+	case *ast.PostfixExpression:
+		walkExpressionPaths(paths, path+"/left", t.Left)
+	case *ast.IfExpression:
+		walkExpressionPaths(paths, path+"/cond", t.Condition)
+		walkExpressionPaths(paths, path+"/then", t.Consequence)
+		walkExpressionPaths(paths, path+"/else", t.Alternative)
+	case *ast.FunctionCallExpression:
+		walkArgumentPaths(paths, path, t.Arguments)
+	}
+}
+
+// Kind of the statement like "set" for *ast.SetStatement
+func statementKind(stmt ast.Statement) string {
+	kind := strings.TrimPrefix(fmt.Sprintf("%T", stmt), "*ast.")
+	return strings.ToLower(strings.TrimSuffix(kind, "Statement"))
+}
@@ -1,14 +1,18 @@
 package interpreter
 
 import (
+	"net/http/httptest"
+	"sort"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/ysugimoto/falco/v2/ast"
 	"github.com/ysugimoto/falco/v2/interpreter/context"
+	"github.com/ysugimoto/falco/v2/interpreter/http"
 	"github.com/ysugimoto/falco/v2/lexer"
 	"github.com/ysugimoto/falco/v2/parser"
+	"github.com/ysugimoto/falco/v2/resolver"
 	"github.com/ysugimoto/falco/v2/tester/shared"
 	"github.com/ysugimoto/falco/v2/token"
 )
@@ -52,7 +56,8 @@ func assertInstrument(t *testing.T, tests testTables) {
 				t.Errorf("instrumented vcl mismatch, diff=%s", diff)
 				return
 			}
-			if diff := cmp.Diff(c.Factory(), tt.coverage); diff != "" {
+			// Structural ids are asserted in TestInstrumentStableIDs
+			if diff := cmp.Diff(c.Factory(), tt.coverage, cmpopts.IgnoreFields(shared.CoverageFactory{}, "StableIDs")); diff != "" {
 				t.Errorf("coverage state mismatch, diff=%s", diff)
 				return
 			}
@@ -89,6 +94,10 @@ sub instrument2 {
 					"sub_2_1": 0,
 					"sub_5_1": 0,
 				},
+				SubroutineNames: map[string]string{
+					"sub_2_1": "instrument1",
+					"sub_5_1": "instrument2",
+				},
 				Statements: shared.CoverageFactoryItem{
 					"stmt_3_2": 0,
 					"stmt_6_2": 0,
@@ -174,6 +183,9 @@ sub instrument {
 				Subroutines: shared.CoverageFactoryItem{
 					"sub_2_1": 0,
 				},
+				SubroutineNames: map[string]string{
+					"sub_2_1": "instrument",
+				},
 				Statements: shared.CoverageFactoryItem{
 					"stmt_3_2":  0,
 					"stmt_4_2":  0,
@@ -286,6 +298,9 @@ sub instrument {
 				Subroutines: shared.CoverageFactoryItem{
 					"sub_2_1": 0,
 				},
+				SubroutineNames: map[string]string{
+					"sub_2_1": "instrument",
+				},
 				Statements: shared.CoverageFactoryItem{
 					"stmt_3_2":  0,
 					"stmt_4_2":  0,
@@ -363,6 +378,9 @@ sub instrument {
 				Subroutines: shared.CoverageFactoryItem{
 					"sub_2_1": 0,
 				},
+				SubroutineNames: map[string]string{
+					"sub_2_1": "instrument",
+				},
 				Statements: shared.CoverageFactoryItem{
 					"stmt_3_2": 0,
 					"stmt_4_2": 0,
@@ -380,6 +398,546 @@ sub instrument {
 				},
 			},
 		},
+		{
+			name: "nested if expression is instrumented inside the branch",
+			input: `
+sub instrument {
+	declare local var.V STRING;
+	set var.V = if(req.http.Foo, if(req.http.Bar, "a", "b"), "c");
+}
+`,
+			expect: `
+sub instrument {
+	coverage.subroutine("sub_2_1");
+	coverage.statement("stmt_3_2");
+	declare local var.V STRING;
+	coverage.statement("stmt_4_2");
+	if (req.http.Foo) {
+		coverage.branch("branch_4_14_true");
+		if (req.http.Bar) {
+			coverage.branch("branch_4_31_true");
+		} else {
+			coverage.branch("branch_4_31_false");
+		}
+	} else {
+		coverage.branch("branch_4_14_false");
+	}
+	set var.V = if(req.http.Foo, if(req.http.Bar, "a", "b"), "c");
+}
+`,
+			coverage: &shared.CoverageFactory{
+				Subroutines: shared.CoverageFactoryItem{
+					"sub_2_1": 0,
+				},
+				SubroutineNames: map[string]string{
+					"sub_2_1": "instrument",
+				},
+				Statements: shared.CoverageFactoryItem{
+					"stmt_3_2": 0,
+					"stmt_4_2": 0,
+				},
+				Branches: shared.CoverageFactoryItem{
+					"branch_4_14_true":  0,
+					"branch_4_14_false": 0,
+					"branch_4_31_true":  0,
+					"branch_4_31_false": 0,
+				},
+				NodeMap: map[string]token.Token{
+					"sub_2_1":           {Type: token.SUBROUTINE, Literal: "sub", Line: 2, Position: 1},
+					"stmt_3_2":          {Type: token.DECLARE, Literal: "declare", Line: 3, Position: 2},
+					"stmt_4_2":          {Type: token.SET, Literal: "set", Line: 4, Position: 2},
+					"branch_4_14_true":  {Type: token.IF, Literal: "if", Line: 4, Position: 14},
+					"branch_4_14_false": {Type: token.IF, Literal: "if", Line: 4, Position: 14},
+					"branch_4_31_true":  {Type: token.IF, Literal: "if", Line: 4, Position: 31},
+					"branch_4_31_false": {Type: token.IF, Literal: "if", Line: 4, Position: 31},
+				},
+			},
+		},
+		{
+			name: "if expression in short-circuit operand is guarded by left operand",
+			input: `
+sub instrument {
+	declare local var.V STRING;
+	set var.V = if(req.http.Foo || if(req.http.Bar, true, false), "a", "b");
+}
+`,
+			expect: `
+sub instrument {
+	coverage.subroutine("sub_2_1");
+	coverage.statement("stmt_3_2");
+	declare local var.V STRING;
+	coverage.statement("stmt_4_2");
+	if (req.http.Foo) {
+	} else {
+		if (req.http.Bar) {
+			coverage.branch("branch_4_33_true");
+		} else {
+			coverage.branch("branch_4_33_false");
+		}
+	}
+	if (req.http.Foo || if(req.http.Bar, true, false)) {
+		coverage.branch("branch_4_14_true");
+	} else {
+		coverage.branch("branch_4_14_false");
+	}
+	set var.V = if(req.http.Foo || if(req.http.Bar, true, false), "a", "b");
+}
+`,
+			coverage: &shared.CoverageFactory{
+				Subroutines: shared.CoverageFactoryItem{
+					"sub_2_1": 0,
+				},
+				SubroutineNames: map[string]string{
+					"sub_2_1": "instrument",
+				},
+				Statements: shared.CoverageFactoryItem{
+					"stmt_3_2": 0,
+					"stmt_4_2": 0,
+				},
+				Branches: shared.CoverageFactoryItem{
+					"branch_4_14_true":  0,
+					"branch_4_14_false": 0,
+					"branch_4_33_true":  0,
+					"branch_4_33_false": 0,
+				},
+				NodeMap: map[string]token.Token{
+					"sub_2_1":           {Type: token.SUBROUTINE, Literal: "sub", Line: 2, Position: 1},
+					"stmt_3_2":          {Type: token.DECLARE, Literal: "declare", Line: 3, Position: 2},
+					"stmt_4_2":          {Type: token.SET, Literal: "set", Line: 4, Position: 2},
+					"branch_4_14_true":  {Type: token.IF, Literal: "if", Line: 4, Position: 14},
+					"branch_4_14_false": {Type: token.IF, Literal: "if", Line: 4, Position: 14},
+					"branch_4_33_true":  {Type: token.IF, Literal: "if", Line: 4, Position: 33},
+					"branch_4_33_false": {Type: token.IF, Literal: "if", Line: 4, Position: 33},
+				},
+			},
+		},
+	}
+	assertInstrument(t, tests)
+}
+
+func TestInstrumentLogicalExpression(t *testing.T) {
+	tests := testTables{
+		{
+			name: "logical expression instrumenting",
+			input: `
+sub instrument {
+	declare local var.ok BOOL;
+	set var.ok = req.http.A && (req.http.B || req.http.C);
+}
+`,
+			expect: `
+sub instrument {
+	coverage.subroutine("sub_2_1");
+	coverage.statement("stmt_3_2");
+	declare local var.ok BOOL;
+	coverage.statement("stmt_4_2");
+	if (req.http.A) {
+		coverage.branch("branch_4_15_true");
+		if (req.http.B) {
+			coverage.branch("branch_4_30_true");
+		} else {
+			coverage.branch("branch_4_30_false");
+			if (req.http.C) {
+				coverage.branch("branch_4_44_true");
+			} else {
+				coverage.branch("branch_4_44_false");
+			}
+		}
+	} else {
+		coverage.branch("branch_4_15_false");
+	}
+	set var.ok = req.http.A && (req.http.B || req.http.C);
+}
+`,
+			coverage: &shared.CoverageFactory{
+				Subroutines: shared.CoverageFactoryItem{
+					"sub_2_1": 0,
+				},
+				SubroutineNames: map[string]string{
+					"sub_2_1": "instrument",
+				},
+				Statements: shared.CoverageFactoryItem{
+					"stmt_3_2": 0,
+					"stmt_4_2": 0,
+				},
+				Branches: shared.CoverageFactoryItem{
+					"branch_4_15_true":  0,
+					"branch_4_15_false": 0,
+					"branch_4_30_true":  0,
+					"branch_4_30_false": 0,
+					"branch_4_44_true":  0,
+					"branch_4_44_false": 0,
+				},
+				NodeMap: map[string]token.Token{
+					"sub_2_1":           {Type: token.SUBROUTINE, Literal: "sub", Line: 2, Position: 1},
+					"stmt_3_2":          {Type: token.DECLARE, Literal: "declare", Line: 3, Position: 2},
+					"stmt_4_2":          {Type: token.SET, Literal: "set", Line: 4, Position: 2},
+					"branch_4_15_true":  {Type: token.IDENT, Literal: "req.http.A", Line: 4, Position: 15},
+					"branch_4_15_false": {Type: token.IDENT, Literal: "req.http.A", Line: 4, Position: 15},
+					"branch_4_30_true":  {Type: token.IDENT, Literal: "req.http.B", Line: 4, Position: 30},
+					"branch_4_30_false": {Type: token.IDENT, Literal: "req.http.B", Line: 4, Position: 30},
+					"branch_4_44_true":  {Type: token.IDENT, Literal: "req.http.C", Line: 4, Position: 44},
+					"branch_4_44_false": {Type: token.IDENT, Literal: "req.http.C", Line: 4, Position: 44},
+				},
+			},
+		},
 	}
 	assertInstrument(t, tests)
 }
+
+func TestInstrumentDecision(t *testing.T) {
+	tests := testTables{
+		{
+			name: "decision instrumenting",
+			input: `
+sub instrument {
+	if (req.http.A && (req.http.B || req.http.C)) {
+		set req.http.V = "1";
+	}
+}
+`,
+			expect: `
+sub instrument {
+	coverage.subroutine("sub_2_1");
+	coverage.statement("stmt_3_2");
+	if (req.http.A) {
+		if (req.http.B) {
+			coverage.decision("decision_3_2", "TT-:T");
+		} else {
+			if (req.http.C) {
+				coverage.decision("decision_3_2", "TFT:T");
+			} else {
+				coverage.decision("decision_3_2", "TFF:F");
+			}
+		}
+	} else {
+		coverage.decision("decision_3_2", "F--:F");
+	}
+	if (req.http.A && (req.http.B || req.http.C)) {
+		coverage.branch("branch_3_2_1");
+		coverage.statement("stmt_4_3");
+		set req.http.V = "1";
+	}
+}
+`,
+			coverage: &shared.CoverageFactory{
+				Subroutines: shared.CoverageFactoryItem{
+					"sub_2_1": 0,
+				},
+				SubroutineNames: map[string]string{
+					"sub_2_1": "instrument",
+				},
+				Statements: shared.CoverageFactoryItem{
+					"stmt_3_2": 0,
+					"stmt_4_3": 0,
+				},
+				Branches: shared.CoverageFactoryItem{
+					"branch_3_2_1": 0,
+				},
+				NodeMap: map[string]token.Token{
+					"sub_2_1":      {Type: token.SUBROUTINE, Literal: "sub", Line: 2, Position: 1},
+					"stmt_3_2":     {Type: token.IF, Literal: "if", Line: 3, Position: 2},
+					"stmt_4_3":     {Type: token.SET, Literal: "set", Line: 4, Position: 3},
+					"branch_3_2_1": {Type: token.IF, Literal: "if", Line: 3, Position: 2},
+				},
+				Decisions: []*shared.Decision{
+					{
+						ID:         "decision_3_2",
+						Line:       3,
+						Position:   2,
+						Conditions: []string{"req.http.A", "req.http.B", "req.http.C"},
+						Vectors:    map[string]uint64{},
+					},
+				},
+			},
+		},
+	}
+	assertInstrument(t, tests)
+}
+
+func TestInstrumentExclusion(t *testing.T) {
+	tests := testTables{
+		{
+			name: "exclude statements and subroutine by pragma comments",
+			input: `
+sub instrument1 {
+	set req.http.Foo = "bar"; // falco:coverage-ignore
+	// falco:coverage-ignore-start
+	set req.http.Bar = "baz";
+	if (req.http.Baz) {
+		set req.http.Qux = "1";
+	}
+	// falco:coverage-ignore-end
+	set req.http.Quux = "2";
+}
+// falco:coverage-ignore generated subroutine
+sub instrument2 {
+	set req.http.Bar = "baz";
+}
+`,
+			expect: `
+sub instrument1 {
+	coverage.subroutine("sub_2_1");
+	set req.http.Foo = "bar";
+	set req.http.Bar = "baz";
+	if (req.http.Baz) {
+		set req.http.Qux = "1";
+	}
+	coverage.statement("stmt_10_2");
+	set req.http.Quux = "2";
+}
+sub instrument2 {
+	set req.http.Bar = "baz";
+}
+`,
+			coverage: &shared.CoverageFactory{
+				Subroutines: shared.CoverageFactoryItem{
+					"sub_2_1": 0,
+				},
+				SubroutineNames: map[string]string{
+					"sub_2_1": "instrument1",
+				},
+				Statements: shared.CoverageFactoryItem{
+					"stmt_10_2": 0,
+				},
+				Branches: shared.CoverageFactoryItem{},
+				NodeMap: map[string]token.Token{
+					"sub_2_1":   {Type: token.SUBROUTINE, Literal: "sub", Line: 2, Position: 1},
+					"stmt_10_2": {Type: token.SET, Literal: "set", Line: 10, Position: 2},
+				},
+				Exclusions: []*shared.CoverageExclusion{
+					{StartLine: 3, EndLine: 3},
+					{StartLine: 5, EndLine: 8},
+					{StartLine: 13, EndLine: 15},
+				},
+			},
+		},
+		{
+			name: "adjacent pragmas and unclosed range are recorded as separate regions",
+			input: `
+sub instrument1 {
+	set req.http.Foo = "1"; // falco:coverage-ignore
+	set req.http.Bar = "2"; // falco:coverage-ignore
+	set req.http.Baz = "3";
+	// falco:coverage-ignore-start
+	set req.http.Qux = "4";
+	set req.http.Quux = "5";
+}
+`,
+			expect: `
+sub instrument1 {
+	coverage.subroutine("sub_2_1");
+	set req.http.Foo = "1";
+	set req.http.Bar = "2";
+	coverage.statement("stmt_5_2");
+	set req.http.Baz = "3";
+	set req.http.Qux = "4";
+	set req.http.Quux = "5";
+}
+`,
+			coverage: &shared.CoverageFactory{
+				Subroutines: shared.CoverageFactoryItem{
+					"sub_2_1": 0,
+				},
+				SubroutineNames: map[string]string{
+					"sub_2_1": "instrument1",
+				},
+				Statements: shared.CoverageFactoryItem{
+					"stmt_5_2": 0,
+				},
+				Branches: shared.CoverageFactoryItem{},
+				NodeMap: map[string]token.Token{
+					"sub_2_1":  {Type: token.SUBROUTINE, Literal: "sub", Line: 2, Position: 1},
+					"stmt_5_2": {Type: token.SET, Literal: "set", Line: 5, Position: 2},
+				},
+				Exclusions: []*shared.CoverageExclusion{
+					{StartLine: 3, EndLine: 3},
+					{StartLine: 4, EndLine: 4},
+					{StartLine: 7, EndLine: 8},
+				},
+			},
+		},
+	}
+	assertInstrument(t, tests)
+}
+
+func TestInstrumentDeadStatements(t *testing.T) {
+	tests := testTables{
+		{
+			name: "statements after restart and goto are not instrumented",
+			input: `
+sub instrument1 {
+	set req.http.Foo = "1";
+	restart;
+	set req.http.Bar = "2";
+	if (req.http.Baz) {
+		set req.http.Qux = "3";
+	}
+}
+sub instrument2 {
+	goto done;
+	set req.http.Foo = "1";
+	done:
+	set req.http.Bar = "2";
+}
+`,
+			expect: `
+sub instrument1 {
+	coverage.subroutine("sub_2_1");
+	coverage.statement("stmt_3_2");
+	set req.http.Foo = "1";
+	coverage.statement("stmt_4_2");
+	restart;
+	set req.http.Bar = "2";
+	if (req.http.Baz) {
+		set req.http.Qux = "3";
+	}
+}
+sub instrument2 {
+	coverage.subroutine("sub_10_1");
+	coverage.statement("stmt_11_2");
+	goto done;
+	set req.http.Foo = "1";
+	coverage.statement("stmt_13_2");
+	done:
+	coverage.statement("stmt_14_2");
+	set req.http.Bar = "2";
+}
+`,
+			coverage: &shared.CoverageFactory{
+				Subroutines: shared.CoverageFactoryItem{
+					"sub_2_1":  0,
+					"sub_10_1": 0,
+				},
+				SubroutineNames: map[string]string{
+					"sub_2_1":  "instrument1",
+					"sub_10_1": "instrument2",
+				},
+				Statements: shared.CoverageFactoryItem{
+					"stmt_3_2":  0,
+					"stmt_4_2":  0,
+					"stmt_11_2": 0,
+					"stmt_13_2": 0,
+					"stmt_14_2": 0,
+				},
+				Branches: shared.CoverageFactoryItem{},
+				NodeMap: map[string]token.Token{
+					"sub_2_1":   {Type: token.SUBROUTINE, Literal: "sub", Line: 2, Position: 1},
+					"stmt_3_2":  {Type: token.SET, Literal: "set", Line: 3, Position: 2},
+					"stmt_4_2":  {Type: token.RESTART, Literal: "restart", Line: 4, Position: 2},
+					"sub_10_1":  {Type: token.SUBROUTINE, Literal: "sub", Line: 10, Position: 1},
+					"stmt_11_2": {Type: token.GOTO, Literal: "goto", Line: 11, Position: 2},
+					"stmt_13_2": {Type: token.IDENT, Literal: "done:", Line: 13, Position: 2},
+					"stmt_14_2": {Type: token.SET, Literal: "set", Line: 14, Position: 2},
+				},
+				Dead: []*shared.CoverageExclusion{
+					{StartLine: 5, EndLine: 8},
+					{StartLine: 12, EndLine: 12},
+				},
+			},
+		},
+	}
+	assertInstrument(t, tests)
+}
+
+func TestInstrumentMultipleFiles(t *testing.T) {
+	c := shared.NewCoverage()
+	ip := &Interpreter{
+		ctx: context.New(context.WithCoverage(c)),
+	}
+	// Both files have the subroutine at the same position
+	for _, file := range []string{"main.vcl", "include.vcl"} {
+		vcl, err := parser.New(lexer.NewFromString("sub vcl_recv {}", lexer.WithFile(file))).ParseVCL()
+		if err != nil {
+			t.Fatalf("Unexpected input VCL parse error: %s", err)
+		}
+		ip.instrument(vcl)
+	}
+
+	factory := c.Factory()
+	for _, id := range []string{"main.vcl:sub_1_1", "include.vcl:sub_1_1"} {
+		if _, ok := factory.Subroutines[id]; !ok {
+			t.Errorf("Coverage marker %s is not found", id)
+		}
+	}
+	if files := factory.Files(); len(files) != 2 {
+		t.Errorf("Coverage should be attributed to 2 files, got %d", len(files))
+	}
+}
+
+func TestInstrumentStableIDs(t *testing.T) {
+	stableIDs := func(input string) map[string]string {
+		vcl, err := parser.New(lexer.NewFromString(input)).ParseVCL()
+		if err != nil {
+			t.Fatalf("Unexpected input VCL parse error: %s", err)
+		}
+		c := shared.NewCoverage()
+		ip := &Interpreter{
+			ctx: context.New(context.WithCoverage(c)),
+		}
+		ip.instrument(vcl)
+		return c.Factory().StableIDs
+	}
+
+	formatted := stableIDs(`
+sub vcl_recv {
+	if (req.http.A && req.http.B) {
+		set req.http.C = if(req.http.D, "1", "2");
+	} else if (req.http.E) {
+		esi;
+	}
+}`)
+	// Same structure with different line breaks and parentheses
+	compact := stableIDs(`sub vcl_recv { if ((req.http.A) && req.http.B) { set req.http.C = if(req.http.D, "1", "2"); }
+	elsif (req.http.E) { esi; } }`)
+
+	values := func(ids map[string]string) []string {
+		var v []string
+		for _, id := range ids {
+			v = append(v, id)
+		}
+		sort.Strings(v)
+		return v
+	}
+	expect := []string{
+		"vcl_recv",
+		"vcl_recv/if[0]",
+		"vcl_recv/if[0]",
+		"vcl_recv/if[0]/elseif[0]/then/esi[0]",
+		"vcl_recv/if[0]/elseif[0]_1",
+		"vcl_recv/if[0]/then/set[0]",
+		"vcl_recv/if[0]/then/set[0]/value_false",
+		"vcl_recv/if[0]/then/set[0]/value_true",
+		"vcl_recv/if[0]_1",
+		"vcl_recv/if[0]_2",
+	}
+	if diff := cmp.Diff(expect, values(formatted)); diff != "" {
+		t.Errorf("Stable ids mismatch, diff=%s", diff)
+	}
+	if diff := cmp.Diff(values(formatted), values(compact)); diff != "" {
+		t.Errorf("Stable ids should not be changed by formatting, diff=%s", diff)
+	}
+}
+
+func TestInstrumentTestOnly(t *testing.T) {
+	c := shared.NewCoverage()
+	ip := New(
+		context.WithResolver(resolver.NewStaticResolver("main", `sub vcl_recv { set req.http.Foo = "1"; }`)),
+		context.WithCoverage(c),
+		context.WithInstrumentTestOnly(true),
+	)
+
+	if err := ip.ProcessInit(http.WrapRequest(httptest.NewRequest("GET", "http://localhost", nil))); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if n := len(c.Factory().Statements); n != 0 {
+		t.Errorf("Simulator process should not be instrumented, got %d statement markers", n)
+	}
+
+	if err := ip.TestProcessInit(http.WrapRequest(httptest.NewRequest("GET", "http://localhost", nil))); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if n := len(c.Factory().Statements); n != 1 {
+		t.Errorf("Testing process should be instrumented, got %d statement markers", n)
+	}
+}
@@ -2,6 +2,7 @@ package interpreter
 
 import (
 	"fmt"
+	"io"
 	"os"
 
 	"github.com/ysugimoto/falco/v2/ast"
@@ -22,15 +23,26 @@ type Debugger interface {
 	Log(*ast.LogStatement, string)
 }
 
-// Default debugger, simply output message to stdout
-type DefaultDebugger struct{}
+// Default debugger, simply output message to the writer, stderr as default.
+// Messages and VCL log output are the output of the simulated VCL
+// so they are not sent to the structured logger which is used for falco's own diagnostics
+type DefaultDebugger struct {
+	Writer io.Writer
+}
+
+func (d DefaultDebugger) writer() io.Writer {
+	if d.Writer != nil {
+		return d.Writer
+	}
+	return os.Stderr
+}
 
 func (d DefaultDebugger) Run(node ast.Node) DebugState {
 	return DebugPass
 }
 func (d DefaultDebugger) Message(msg string) {
-	fmt.Fprintln(os.Stderr, msg)
+	fmt.Fprintln(d.writer(), msg)
 }
 func (d DefaultDebugger) Log(stmt *ast.LogStatement, value string) {
-	fmt.Fprintln(os.Stderr, value)
+	fmt.Fprintln(d.writer(), value)
 }
@@ -0,0 +1,92 @@
+package dictionary
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/ysugimoto/falco/v2/config"
+)
+
+const defaultConsulAddress = "http://localhost:8500"
+
+// ConsulProvider reads edge dictionary from Consul KV store.
+// Each item is stored as the key under prefix + dictionary name folder like "falco/feature_flags/new_header"
+type ConsulProvider struct {
+	address string
+	prefix  string
+	token   string
+	client  *http.Client
+}
+
+func NewConsulProvider(c *config.DictionaryStorageConfig) *ConsulProvider {
+	address := c.Address
+	if address == "" {
+		address = defaultConsulAddress
+	}
+	if !strings.Contains(address, "://") {
+		address = "http://" + address
+	}
+	return &ConsulProvider{
+		address: strings.TrimSuffix(address, "/"),
+		prefix:  c.Prefix,
+		token:   c.Token,
+		client:  http.DefaultClient,
+	}
+}
+
+// Consul KV entry, value is base64 encoded so decoded as []byte
+type consulEntry struct {
+	Key   string `json:"Key"`
+	Value []byte `json:"Value"`
+}
+
+func (c *ConsulProvider) Items(ctx context.Context, name string) (map[string]string, error) {
+	folder := c.prefix + name + "/"
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodGet,
+		c.address+"/v1/kv/"+(&url.URL{Path: folder}).EscapedPath()+"?recurse=true",
+		nil,
+	)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if c.token != "" {
+		req.Header.Set("X-Consul-Token", c.token)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		break
+	// Consul responds 404 when no keys exist under the folder
+	case http.StatusNotFound:
+		return nil, nil
+	default:
+		return nil, errors.Errorf("unexpected consul response status %d", resp.StatusCode)
+	}
+
+	var entries []consulEntry
+	if err := json.NewDecoder(resp.Body).Decode(&entries); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	items := make(map[string]string, len(entries))
+	for _, e := range entries {
+		key := strings.TrimPrefix(e.Key, folder)
+		// Skip folder itself and nested folders
+		if key == "" || strings.Contains(key, "/") {
+			continue
+		}
+		items[key] = string(e.Value)
+	}
+	return items, nil
+}
@@ -0,0 +1,64 @@
+package dictionary
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/ysugimoto/falco/v2/config"
+)
+
+// Timeout of reading all dictionaries from the storage on each request
+const loadTimeout = 3 * time.Second
+
+// Provider is KV storage which backs edge dictionaries in the simulator.
+// Implement this interface to read dictionaries from other storages
+type Provider interface {
+	// Items returns all items of the edge dictionary, nil map means the dictionary is not stored
+	Items(ctx context.Context, name string) (map[string]string, error)
+}
+
+// Storage reads configured edge dictionaries from the provider
+type Storage struct {
+	provider Provider
+	names    []string
+}
+
+func NewStorage(p Provider, names []string) *Storage {
+	return &Storage{
+		provider: p,
+		names:    names,
+	}
+}
+
+// New creates storage with the provider which is specified in configuration
+func New(c *config.DictionaryStorageConfig) (*Storage, error) {
+	var p Provider
+	switch c.Provider {
+	case "redis":
+		p = NewRedisProvider(c)
+	case "consul":
+		p = NewConsulProvider(c)
+	default:
+		return nil, errors.Errorf("unsupported dictionary storage provider %s, must be redis or consul", c.Provider)
+	}
+	return NewStorage(p, c.Dictionaries), nil
+}
+
+// Load reads all dictionaries from the provider. Dictionaries which are not stored are not contained in the result
+func (s *Storage) Load(ctx context.Context) (map[string]config.EdgeDictionary, error) {
+	ctx, cancel := context.WithTimeout(ctx, loadTimeout)
+	defer cancel()
+
+	dictionaries := make(map[string]config.EdgeDictionary, len(s.names))
+	for _, name := range s.names {
+		items, err := s.provider.Items(ctx, name)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read edge dictionary %s", name)
+		}
+		if items != nil {
+			dictionaries[name] = items
+		}
+	}
+	return dictionaries, nil
+}
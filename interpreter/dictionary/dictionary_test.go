@@ -0,0 +1,193 @@
+package dictionary
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/ysugimoto/falco/v2/config"
+)
+
+// Serve minimal RESP server which replies HGETALL from the hashes
+func serveRedis(t *testing.T, password string, hashes map[string][]string) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Unexpected listen error: %s", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				r := bufio.NewReader(conn)
+				authorized := password == ""
+				for {
+					reply, err := readRedisReply(r)
+					if err != nil {
+						return
+					}
+					args, _ := reply.([]any)
+					switch strings.ToUpper(args[0].(string)) {
+					case "AUTH":
+						if authorized = args[1] == password; !authorized {
+							fmt.Fprint(conn, "-WRONGPASS invalid password\r\n")
+							continue
+						}
+						fmt.Fprint(conn, "+OK\r\n")
+					case "HGETALL":
+						if !authorized {
+							fmt.Fprint(conn, "-NOAUTH Authentication required\r\n")
+							continue
+						}
+						fields := hashes[args[1].(string)]
+						fmt.Fprintf(conn, "*%d\r\n", len(fields))
+						for _, f := range fields {
+							fmt.Fprintf(conn, "$%d\r\n%s\r\n", len(f), f)
+						}
+					}
+				}
+			}(conn)
+		}
+	}()
+	return ln.Addr().String()
+}
+
+func TestRedisProvider(t *testing.T) {
+	addr := serveRedis(t, "secret", map[string][]string{
+		"falco:feature_flags": {"new_header", "on", "beta", ""},
+	})
+
+	tests := []struct {
+		name     string
+		password string
+		expect   map[string]config.EdgeDictionary
+		isError  bool
+	}{
+		{
+			name:     "read dictionary from hash",
+			password: "secret",
+			expect: map[string]config.EdgeDictionary{
+				"feature_flags": {"new_header": "on", "beta": ""},
+			},
+		},
+		{
+			name:     "authentication error",
+			password: "invalid",
+			isError:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := New(&config.DictionaryStorageConfig{
+				Provider:     "redis",
+				Address:      addr,
+				Prefix:       "falco:",
+				Token:        tt.password,
+				Dictionaries: []string{"feature_flags", "not_stored"},
+			})
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			actual, err := s.Load(context.Background())
+			if tt.isError {
+				if err == nil {
+					t.Errorf("Expected error but got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected load error: %s", err)
+			}
+			if diff := cmp.Diff(tt.expect, actual); diff != "" {
+				t.Errorf("Dictionaries mismatch, diff=%s", diff)
+			}
+		})
+	}
+}
+
+func TestConsulProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Consul-Token") != "token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if r.URL.Path != "/v1/kv/falco/feature_flags/" || r.URL.Query().Get("recurse") != "true" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode([]map[string]any{ // nolint:errcheck
+			{"Key": "falco/feature_flags/", "Value": nil},
+			{"Key": "falco/feature_flags/new_header", "Value": []byte("on")},
+			{"Key": "falco/feature_flags/nested/key", "Value": []byte("ignored")},
+		})
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		token   string
+		expect  map[string]config.EdgeDictionary
+		isError bool
+	}{
+		{
+			name:  "read dictionary from folder",
+			token: "token",
+			expect: map[string]config.EdgeDictionary{
+				"feature_flags": {"new_header": "on"},
+			},
+		},
+		{
+			name:    "forbidden error",
+			token:   "invalid",
+			isError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := New(&config.DictionaryStorageConfig{
+				Provider:     "consul",
+				Address:      server.URL,
+				Prefix:       "falco/",
+				Token:        tt.token,
+				Dictionaries: []string{"feature_flags", "not_stored"},
+			})
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			actual, err := s.Load(context.Background())
+			if tt.isError {
+				if err == nil {
+					t.Errorf("Expected error but got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected load error: %s", err)
+			}
+			if diff := cmp.Diff(tt.expect, actual); diff != "" {
+				t.Errorf("Dictionaries mismatch, diff=%s", diff)
+			}
+		})
+	}
+}
+
+func TestUnsupportedProvider(t *testing.T) {
+	if _, err := New(&config.DictionaryStorageConfig{Provider: "etcd"}); err == nil {
+		t.Errorf("Expected error for unsupported provider")
+	}
+}
@@ -0,0 +1,151 @@
+package dictionary
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/ysugimoto/falco/v2/config"
+)
+
+const defaultRedisAddress = "localhost:6379"
+
+// RedisProvider reads edge dictionary from Redis hash which has the key of prefix + dictionary name.
+// The provider speaks minimal RESP protocol and connects on each read because the simulator reads dictionaries once per request
+type RedisProvider struct {
+	address  string
+	prefix   string
+	password string
+	database int
+}
+
+func NewRedisProvider(c *config.DictionaryStorageConfig) *RedisProvider {
+	address := c.Address
+	if address == "" {
+		address = defaultRedisAddress
+	}
+	return &RedisProvider{
+		address:  address,
+		prefix:   c.Prefix,
+		password: c.Token,
+		database: c.Database,
+	}
+}
+
+func (r *RedisProvider) Items(ctx context.Context, name string) (map[string]string, error) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", r.address)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+
+	rw := bufio.NewReadWriter(bufio.NewReader(conn), bufio.NewWriter(conn))
+	if r.password != "" {
+		if _, err := redisCommand(rw, "AUTH", r.password); err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+	if r.database > 0 {
+		if _, err := redisCommand(rw, "SELECT", strconv.Itoa(r.database)); err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+	reply, err := redisCommand(rw, "HGETALL", r.prefix+name)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	fields, ok := reply.([]any)
+	if !ok || len(fields)%2 != 0 {
+		return nil, errors.Errorf("unexpected HGETALL reply %v", reply)
+	}
+	// Redis replies empty array for the key which does not exist
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	items := make(map[string]string, len(fields)/2)
+	for i := 0; i < len(fields); i += 2 {
+		key, _ := fields[i].(string)
+		val, _ := fields[i+1].(string)
+		items[key] = val
+	}
+	return items, nil
+}
+
+// Send command as RESP array of bulk strings and read the reply
+func redisCommand(rw *bufio.ReadWriter, args ...string) (any, error) {
+	fmt.Fprintf(rw, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(rw, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	if err := rw.Flush(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return readRedisReply(rw.Reader)
+}
+
+// Read RESP reply. Bulk strings are returned as string, arrays are returned as []any and nil bulk string is returned as nil
+func readRedisReply(r *bufio.Reader) (any, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" {
+		return nil, errors.New("empty reply")
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, errors.Errorf("redis error: %s", line[1:])
+	case ':':
+		v, err := strconv.ParseInt(line[1:], 10, 64)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		return v, nil
+	case '$':
+		size, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if size < 0 {
+			return nil, nil
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		return string(buf[:size]), nil
+	case '*':
+		size, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if size < 0 {
+			return nil, nil
+		}
+		values := make([]any, size)
+		for i := range values {
+			if values[i], err = readRedisReply(r); err != nil {
+				return nil, errors.WithStack(err)
+			}
+		}
+		return values, nil
+	default:
+		return nil, errors.Errorf("unexpected reply %s", line)
+	}
+}
@@ -5,7 +5,6 @@ import (
 	"encoding/binary"
 	"fmt"
 	"math"
-	"math/rand"
 	"slices"
 	"sort"
 	"strings"
@@ -295,9 +294,8 @@ func (i *Interpreter) directorBackendRandom(dc *value.DirectorConfig) (*value.Ba
 			}
 		}
 
-		rand.New(rand.NewSource(time.Now().Unix()))
 		lottery = lottery[0:current]
-		item := dc.Backends[lottery[rand.Intn(current)]]
+		item := dc.Backends[lottery[i.ctx.Rand().Intn(current)]]
 
 		return item.Backend, nil
 	}
@@ -0,0 +1,143 @@
+package interpreter
+
+import (
+	ghttp "net/http"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/ysugimoto/falco/v2/interpreter/http"
+	"github.com/ysugimoto/falco/v2/interpreter/process"
+	"github.com/ysugimoto/falco/v2/interpreter/value"
+)
+
+// Header mutation actions
+const (
+	HeaderSet   = "set"
+	HeaderUnset = "unset"
+)
+
+// Plan is the decision of the edge for the request which is evaluated in dry-run mode
+type Plan struct {
+	// True if the request would be sent to the backend
+	Fetch bool `json:"fetch"`
+	// True if the request bypasses the cache
+	Pass bool `json:"pass"`
+	// Determined backend name, empty if the backend is not determined
+	Backend string `json:"backend,omitempty"`
+	// Cache key which consists of values added to req.hash in vcl_hash, not the digest of them
+	CacheKey string `json:"cache_key,omitempty"`
+	// Backend request which would be sent, only present when Fetch is true
+	BackendRequest *PlanRequest `json:"backend_request,omitempty"`
+	// Request header mutations from the client request to the backend request,
+	// or to the final client request if the request is not sent to the backend
+	Headers []*HeaderMutation `json:"headers"`
+	// Status code of the locally generated response, only present when Fetch is false
+	Status int `json:"status,omitempty"`
+	// Count of restarts
+	Restarts int `json:"restarts"`
+	// State machine transitions in processed order
+	Transitions []*process.Transition `json:"transitions"`
+	// Log statements which are processed
+	Logs []*process.Log `json:"logs"`
+}
+
+// PlanRequest is the backend request which would be sent to the backend
+type PlanRequest struct {
+	Method string              `json:"method"`
+	URL    string              `json:"url"`
+	Header map[string][]string `json:"header"`
+}
+
+// HeaderMutation is the change of the request header
+type HeaderMutation struct {
+	Name   string `json:"name"`
+	Action string `json:"action"`
+	Value  string `json:"value,omitempty"`
+}
+
+// DryRun evaluates VCL against the request and returns the decision plan without any side effects.
+// The backend request is not sent, the cache is neither read nor written, and ratecounters and penaltyboxes
+// start empty and are discarded after evaluation, so the plan does not depend on previous requests.
+// DryRun is serialized per interpreter, create interpreters for each goroutine for concurrent evaluation
+func (i *Interpreter) DryRun(r *ghttp.Request) (*Plan, error) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	rateCounters, penaltyBoxes := i.rateCounters, i.penaltyBoxes
+	i.rateCounters = make(map[string]*value.Ratecounter)
+	i.penaltyBoxes = make(map[string]*value.Penaltybox)
+	defer func() {
+		i.rateCounters, i.penaltyBoxes = rateCounters, penaltyBoxes
+	}()
+
+	if err := safeProcess(func() error { return i.ProcessInit(http.WrapRequest(r.Clone(r.Context()))) }); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	i.ctx.DryRun = true
+	original := i.ctx.Request.Header.Clone()
+
+	if err := safeProcess(i.ProcessRecv); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return i.plan(original), nil
+}
+
+// Make the plan from the evaluated context
+func (i *Interpreter) plan(original ghttp.Header) *Plan {
+	p := &Plan{
+		Restarts:    i.ctx.Restarts,
+		Transitions: i.process.Transitions,
+		Logs:        i.process.Logs,
+	}
+	if i.ctx.Backend != nil {
+		p.Backend = i.ctx.Backend.String()
+	}
+	p.CacheKey = strings.Join(i.ctx.RequestHashInputs, "")
+	for _, t := range p.Transitions {
+		if t.Restart {
+			p.Pass = false
+		} else if t.To == "PASS" {
+			p.Pass = true
+		}
+	}
+
+	// Evaluation stops on FETCH in dry-run mode
+	if n := len(p.Transitions); n > 0 && p.Transitions[n-1].To == "FETCH" && i.ctx.BackendRequest != nil {
+		p.Fetch = true
+		p.BackendRequest = &PlanRequest{
+			Method: i.ctx.BackendRequest.Method,
+			URL:    i.ctx.BackendRequest.URL.String(),
+			Header: i.ctx.BackendRequest.Header.Clone(),
+		}
+		p.Headers = headerMutations(original, i.ctx.BackendRequest.Header)
+		return p
+	}
+
+	if i.ctx.Response != nil {
+		p.Status = i.ctx.Response.StatusCode
+	}
+	p.Headers = headerMutations(original, i.ctx.Request.Header)
+	return p
+}
+
+// Compare headers and returns mutations in name order
+func headerMutations(from, to ghttp.Header) []*HeaderMutation {
+	mutations := []*HeaderMutation{}
+	for name, values := range to {
+		v := strings.Join(values, ", ")
+		if prev, ok := from[name]; ok && strings.Join(prev, ", ") == v {
+			continue
+		}
+		mutations = append(mutations, &HeaderMutation{Name: name, Action: HeaderSet, Value: v})
+	}
+	for name := range from {
+		if _, ok := to[name]; !ok {
+			mutations = append(mutations, &HeaderMutation{Name: name, Action: HeaderUnset})
+		}
+	}
+	sort.Slice(mutations, func(a, b int) bool {
+		return mutations[a].Name < mutations[b].Name
+	})
+	return mutations
+}
@@ -0,0 +1,101 @@
+package interpreter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/ysugimoto/falco/v2/interpreter/context"
+	"github.com/ysugimoto/falco/v2/resolver"
+)
+
+func TestDryRun(t *testing.T) {
+	vcl := `
+backend origin {
+  .host = "origin.invalid";
+  .port = "443";
+  .ssl = true;
+}
+
+sub vcl_recv {
+  if (req.url ~ "^/blocked") {
+    error 403 "Forbidden";
+  }
+  set req.backend = origin;
+  set req.http.X-Tenant = "example";
+  unset req.http.Cookie;
+  if (req.url ~ "^/api") {
+    return (pass);
+  }
+  return (lookup);
+}
+
+sub vcl_hash {
+  set req.hash += req.url;
+  set req.hash += "#tenant";
+  return (hash);
+}
+`
+
+	t.Run("lookup request is planned to fetch without network", func(t *testing.T) {
+		ip := New(context.WithResolver(resolver.NewStaticResolver("main", vcl)))
+		req := httptest.NewRequest(http.MethodGet, "http://localhost/index.html", nil)
+		req.Header.Set("Cookie", "session=1")
+
+		plan, err := ip.DryRun(req)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if !plan.Fetch || plan.Pass {
+			t.Errorf("Request should be fetched with cache, fetch=%t pass=%t", plan.Fetch, plan.Pass)
+		}
+		if plan.Backend != "origin" {
+			t.Errorf("Backend should be origin, got %s", plan.Backend)
+		}
+		if plan.CacheKey != "/index.html#tenant" {
+			t.Errorf("Unexpected cache key %s", plan.CacheKey)
+		}
+		if plan.BackendRequest == nil || plan.BackendRequest.URL != "https://origin.invalid:443/index.html" {
+			t.Errorf("Unexpected backend request %v", plan.BackendRequest)
+		}
+		var mutations []*HeaderMutation
+		for _, m := range plan.Headers {
+			if m.Name == "Cookie" || m.Name == "X-Tenant" {
+				mutations = append(mutations, m)
+			}
+		}
+		expect := []*HeaderMutation{
+			{Name: "Cookie", Action: HeaderUnset},
+			{Name: "X-Tenant", Action: HeaderSet, Value: "example"},
+		}
+		if diff := cmp.Diff(expect, mutations); diff != "" {
+			t.Errorf("Header mutations mismatch, diff=%s", diff)
+		}
+	})
+
+	t.Run("pass request", func(t *testing.T) {
+		ip := New(context.WithResolver(resolver.NewStaticResolver("main", vcl)))
+		plan, err := ip.DryRun(httptest.NewRequest(http.MethodGet, "http://localhost/api/users", nil))
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if !plan.Fetch || !plan.Pass {
+			t.Errorf("Request should be passed to the backend, fetch=%t pass=%t", plan.Fetch, plan.Pass)
+		}
+	})
+
+	t.Run("locally generated response", func(t *testing.T) {
+		ip := New(context.WithResolver(resolver.NewStaticResolver("main", vcl)))
+		plan, err := ip.DryRun(httptest.NewRequest(http.MethodGet, "http://localhost/blocked", nil))
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if plan.Fetch || plan.BackendRequest != nil {
+			t.Errorf("Request should not be fetched")
+		}
+		if plan.Status != http.StatusForbidden {
+			t.Errorf("Status should be 403, got %d", plan.Status)
+		}
+	})
+}
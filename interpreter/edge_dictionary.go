@@ -3,6 +3,7 @@ package interpreter
 import (
 	"github.com/ysugimoto/falco/v2/ast"
 	"github.com/ysugimoto/falco/v2/config"
+	"github.com/ysugimoto/falco/v2/interpreter/exception"
 	"github.com/ysugimoto/falco/v2/token"
 )
 
@@ -32,6 +33,30 @@ func (i *Interpreter) InjectEdgeDictionaryItem(table *ast.TableDeclaration, dict
 	}
 }
 
+// Apply edge dictionaries which are read from KV storage like Redis or Consul.
+// Storage values take precedence over the declarations in VCL, configuration and resource files
+func (i *Interpreter) applyDictionaryStorage() error {
+	if i.ctx.DictionaryStorage == nil {
+		return nil
+	}
+
+	dictionaries, err := i.ctx.DictionaryStorage.Load(i.ctx.Request.Context())
+	if err != nil {
+		return exception.System("EdgeDictionary storage error: %s", err)
+	}
+	for name, dict := range dictionaries {
+		if v, ok := i.ctx.Tables[name]; ok {
+			if v.ValueType != nil && v.ValueType.Value != "STRING" {
+				return exception.System("EdgeDictionary injection error: %s value type is not STRING", v.Name.Value)
+			}
+			i.InjectEdgeDictionaryItem(v, dict)
+		} else {
+			i.ctx.Tables[name] = i.createEdgeDictionaryDeclaration(name, dict)
+		}
+	}
+	return nil
+}
+
 // Create EdgeDictionary declaration from config
 func (i *Interpreter) createEdgeDictionaryDeclaration(name string, dict config.EdgeDictionary) *ast.TableDeclaration {
 	decl := &ast.TableDeclaration{
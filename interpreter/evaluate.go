@@ -0,0 +1,145 @@
+package interpreter
+
+import (
+	"context"
+	ghttp "net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+	icontext "github.com/ysugimoto/falco/v2/interpreter/context"
+	"github.com/ysugimoto/falco/v2/interpreter/exception"
+	"github.com/ysugimoto/falco/v2/interpreter/http"
+	"github.com/ysugimoto/falco/v2/interpreter/process"
+	"github.com/ysugimoto/falco/v2/interpreter/value"
+)
+
+// SubroutineOptions configures the subroutine evaluation of ProcessSubroutine
+type SubroutineOptions struct {
+	// Context options to initialize the interpreter. Resolver option is required to load the main VCL
+	Options []icontext.Option
+	// Request to process. If nil, GET request to http://localhost/ is used
+	Request *ghttp.Request
+	// Scope to process the subroutine. If not specified, the scope is determined from
+	// the Fastly reserved subroutine name like vcl_recv, otherwise RECV scope is used
+	Scope icontext.Scope
+	// Arguments to pass to the subroutine which has parameters
+	Arguments []value.Value
+}
+
+// Change represents a variable or header which is modified by the subroutine
+type Change struct {
+	Name  string
+	Value string
+	Unset bool
+}
+
+// SubroutineResult is the result of ProcessSubroutine
+type SubroutineResult struct {
+	// Returned state like "pass" or "lookup", NONE if the subroutine does not return state
+	State State
+	// Returned value of the functional subroutine, nil for the other subroutines
+	Value value.Value
+	// Variables and headers which are modified by the subroutine in the first modified order.
+	// Local variables are not included because they are discarded after the subroutine has ended
+	Changes []*Change
+	// Runtime exception which is raised while processing the subroutine
+	Exception *exception.Exception
+	// Process record of the evaluation
+	Process *process.Process
+}
+
+// ProcessSubroutine evaluates a single subroutine in the main VCL programmatically.
+// This is useful to exercise shared VCL logic from Go tests without running the test runner.
+// Returned error reports setup problems like unknown subroutine or cancellation of ctx,
+// runtime exception is reported as SubroutineResult.Exception with the changes until raised.
+func ProcessSubroutine(ctx context.Context, name string, opts *SubroutineOptions) (*SubroutineResult, error) {
+	if opts == nil {
+		opts = &SubroutineOptions{}
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	req := opts.Request
+	if req == nil {
+		var err error
+		if req, err = ghttp.NewRequestWithContext(ctx, ghttp.MethodGet, "http://localhost/", nil); err != nil {
+			return nil, errors.WithStack(err)
+		}
+	} else {
+		// Bind the context so that processing stops when the context is cancelled
+		req = req.WithContext(ctx)
+	}
+
+	// Changes are collected from recorded accesses
+	options := append([]icontext.Option{}, opts.Options...)
+	options = append(options, icontext.WithProvenance(true))
+	i := New(options...)
+	if err := safeProcess(func() error { return i.TestProcessInit(http.WrapRequest(req)) }); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	sub, isFunctional := i.ctx.SubroutineFunctions[name]
+	if !isFunctional {
+		var ok bool
+		if sub, ok = i.ctx.Subroutines[name]; !ok {
+			return nil, errors.Errorf("Subroutine %s is not found", name)
+		}
+	}
+
+	scope := opts.Scope
+	if scope == icontext.UnknownScope {
+		scope = icontext.ScopeByString(strings.TrimPrefix(name, "vcl_"))
+		if !strings.HasPrefix(name, "vcl_") || scope == icontext.UnknownScope {
+			scope = icontext.RecvScope
+		}
+	}
+	i.SetScope(scope)
+
+	// Subroutine is processed synchronously, cancellation is checked on each statement by the interpreter
+	result := &SubroutineResult{State: NONE, Process: i.process}
+	err := safeProcess(func() (err error) {
+		if isFunctional {
+			result.Value, result.State, err = i.ProcessFunctionSubroutine(sub, DebugPass, opts.Arguments)
+		} else {
+			result.State, err = i.ProcessSubroutine(sub, DebugPass, opts.Arguments)
+		}
+		return err
+	})
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, errors.WithStack(ctxErr)
+	}
+	result.Changes = collectChanges(i.process.Accesses)
+	if err != nil {
+		// Function errors are not wrapped by exception, report them as runtime exception
+		exc, ok := errors.Cause(err).(*exception.Exception)
+		if !ok {
+			exc = exception.Runtime(nil, "%s", err.Error())
+		}
+		result.Exception = exc
+	}
+	return result, nil
+}
+
+// Collect the last modification of each variable and header from the recorded accesses
+func collectChanges(accesses []*process.Access) []*Change {
+	var changes []*Change
+	index := make(map[string]*Change)
+	for _, a := range accesses {
+		if a.Kind == process.AccessRead || strings.HasPrefix(a.Name, "var.") {
+			continue
+		}
+		c, ok := index[a.Name]
+		if !ok {
+			c = &Change{Name: a.Name}
+			index[a.Name] = c
+			changes = append(changes, c)
+		}
+		c.Unset = a.Kind == process.AccessUnset
+		c.Value = a.Value
+		if c.Unset {
+			c.Value = ""
+		}
+	}
+	return changes
+}
@@ -0,0 +1,170 @@
+package interpreter
+
+import (
+	gocontext "context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	"github.com/ysugimoto/falco/v2/interpreter/context"
+	"github.com/ysugimoto/falco/v2/interpreter/http"
+	"github.com/ysugimoto/falco/v2/interpreter/value"
+	"github.com/ysugimoto/falco/v2/resolver"
+)
+
+func TestProcessSubroutineAPI(t *testing.T) {
+	vcl := `
+sub normalize_host {
+	set req.http.Host = std.tolower(req.http.Host);
+	set req.http.X-Normalized = "1";
+	unset req.http.Cookie;
+	declare local var.tmp STRING;
+	set var.tmp = "ignored";
+}
+
+sub vcl_recv {
+	#FASTLY RECV
+	if (req.url ~ "^/admin") {
+		return(pass);
+	}
+	return(lookup);
+}
+
+sub is_admin BOOL {
+	return req.url ~ "^/admin";
+}
+
+sub greet(STRING var.name) STRING {
+	return "Hello, " + var.name;
+}
+
+sub explode {
+	set req.http.Before = "1";
+	set req.http.Foo = std.atoi("a", "b");
+}
+`
+	options := []context.Option{
+		context.WithResolver(resolver.NewStaticResolver("main", vcl)),
+	}
+
+	t.Run("changes of the subroutine", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "http://EXAMPLE.com/", nil)
+		req.Header.Set("Cookie", "foo=bar")
+		ret, err := ProcessSubroutine(gocontext.Background(), "normalize_host", &SubroutineOptions{
+			Options: options,
+			Request: req,
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		expect := []*Change{
+			{Name: "req.http.Host", Value: "example.com"},
+			{Name: "req.http.X-Normalized", Value: "1"},
+			{Name: "req.http.Cookie", Unset: true},
+		}
+		if diff := cmp.Diff(expect, ret.Changes); diff != "" {
+			t.Errorf("Changes mismatch, diff=%s", diff)
+		}
+		if ret.Exception != nil {
+			t.Errorf("Unexpected exception: %s", ret.Exception)
+		}
+	})
+
+	t.Run("returned state of the reserved subroutine", func(t *testing.T) {
+		ret, err := ProcessSubroutine(gocontext.Background(), "vcl_recv", &SubroutineOptions{
+			Options: options,
+			Request: httptest.NewRequest("GET", "http://example.com/admin", nil),
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if ret.State != PASS {
+			t.Errorf("State mismatch, expect=%s, got=%s", PASS, ret.State)
+		}
+	})
+
+	t.Run("returned value of the functional subroutine", func(t *testing.T) {
+		ret, err := ProcessSubroutine(gocontext.Background(), "greet", &SubroutineOptions{
+			Options:   options,
+			Arguments: []value.Value{&value.String{Value: "falco"}},
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if diff := cmp.Diff("Hello, falco", ret.Value.String()); diff != "" {
+			t.Errorf("Returned value mismatch, diff=%s", diff)
+		}
+	})
+
+	t.Run("runtime exception is reported with changes", func(t *testing.T) {
+		ret, err := ProcessSubroutine(gocontext.Background(), "explode", &SubroutineOptions{
+			Options: options,
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if ret.Exception == nil {
+			t.Errorf("Expected exception but got nil")
+		}
+		expect := []*Change{
+			{Name: "req.http.Before", Value: "1"},
+		}
+		if diff := cmp.Diff(expect, ret.Changes); diff != "" {
+			t.Errorf("Changes mismatch, diff=%s", diff)
+		}
+	})
+
+	t.Run("cancelled request stops processing", func(t *testing.T) {
+		c, cancel := gocontext.WithCancel(gocontext.Background())
+		cancel()
+		ip := New(options...)
+		if err := ip.TestProcessInit(http.WrapRequest(httptest.NewRequest("GET", "http://example.com/", nil).WithContext(c))); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		ip.SetScope(context.RecvScope)
+		if _, err := ip.ProcessSubroutine(ip.ctx.Subroutines["normalize_host"], DebugPass, nil); err == nil {
+			t.Errorf("Expected error but got nil")
+		}
+		if v := ip.ctx.Request.Header.Get("X-Normalized"); v != "" {
+			t.Errorf("Statement must not be processed after cancellation, got X-Normalized=%s", v)
+		}
+	})
+
+	t.Run("context is cancelled while processing", func(t *testing.T) {
+		c := &cancelAfterContext{Context: gocontext.Background(), calls: 2}
+		ret, err := ProcessSubroutine(c, "normalize_host", &SubroutineOptions{
+			Options: options,
+		})
+		if !errors.Is(err, gocontext.Canceled) {
+			t.Errorf("Expected context canceled error but got %v", err)
+		}
+		if ret != nil {
+			t.Errorf("Expected nil result but got %v", ret)
+		}
+	})
+
+	t.Run("subroutine is not found", func(t *testing.T) {
+		_, err := ProcessSubroutine(gocontext.Background(), "not_found", &SubroutineOptions{
+			Options: options,
+		})
+		if err == nil {
+			t.Errorf("Expected error but got nil")
+		}
+	})
+}
+
+// Context which is cancelled after Err() has been called the number of times,
+// it simulates the cancellation while the subroutine is processing
+type cancelAfterContext struct {
+	gocontext.Context
+	calls int
+}
+
+func (c *cancelAfterContext) Err() error {
+	if c.calls <= 0 {
+		return gocontext.Canceled
+	}
+	c.calls--
+	return nil
+}
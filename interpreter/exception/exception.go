@@ -2,6 +2,7 @@ package exception
 
 import (
 	"fmt"
+	"runtime/debug"
 	"strings"
 
 	"github.com/ysugimoto/falco/v2/ast"
@@ -19,6 +20,10 @@ type Exception struct {
 	Type    Type
 	Token   *token.Token
 	Message string
+	// Goroutine stack trace, only present when the exception is converted from panic
+	Stack string
+	// Source excerpt around the token, only present when the interpreter knows the source
+	Excerpt *Excerpt
 }
 
 func (e *Exception) Error() string {
@@ -39,6 +44,9 @@ func (e *Exception) Error() string {
 	// SystemException means problem of falco implementation
 	// Output additional message that report URL :-)
 	if e.Type == SystemType {
+		if e.Stack != "" {
+			out += "\n\n" + e.Stack
+		}
 		out += "\n\nThis exception is caused by falco interpreter."
 		out += "\nIt maybe a bug, please report to http://github.com/ysugimoto/falco"
 	}
@@ -46,6 +54,14 @@ func (e *Exception) Error() string {
 	return out
 }
 
+// Pretty returns the error message with the source excerpt for human readable output
+func (e *Exception) Pretty() string {
+	if e.Excerpt == nil {
+		return e.Error()
+	}
+	return e.Error() + "\n" + e.Excerpt.String()
+}
+
 func Runtime(t *token.Token, format string, args ...any) *Exception {
 	return &Exception{
 		Type:    RuntimeType,
@@ -61,6 +77,24 @@ func System(format string, args ...any) *Exception {
 	}
 }
 
+// Panic converts recovered panic value to SystemException with the stack trace.
+// This function must be called in the deferred function so that the stack contains the panicked frame
+func Panic(recovered any) *Exception {
+	return &Exception{
+		Type:    SystemType,
+		Message: fmt.Sprintf("Unexpected panic: %v", recovered),
+		Stack:   string(debug.Stack()),
+	}
+}
+
+// Recover recovers from panic and stores it to err as SystemException.
+// Use as deferred call with the named return value like "defer exception.Recover(&err)"
+func Recover(err *error) {
+	if r := recover(); r != nil {
+		*err = Panic(r)
+	}
+}
+
 func MaxCallStackExceeded(t *token.Token, stacks []*ast.SubroutineDeclaration) *Exception {
 	message := make([]string, len(stacks))
 	for i := range stacks {
@@ -0,0 +1,33 @@
+package exception
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRecover(t *testing.T) {
+	process := func() (err error) {
+		defer Recover(&err)
+		var m map[string]string
+		m["foo"] = "bar"
+		return nil
+	}
+
+	err := process()
+	e, ok := err.(*Exception)
+	if !ok {
+		t.Fatalf("Expected *Exception but got %T", err)
+	}
+	if e.Type != SystemType {
+		t.Errorf("Type mismatch, expect=%s, got=%s", SystemType, e.Type)
+	}
+	if !strings.Contains(e.Message, "assignment to entry in nil map") {
+		t.Errorf("Message should contain the panic value, got=%s", e.Message)
+	}
+	if !strings.Contains(e.Stack, "exception.TestRecover") {
+		t.Errorf("Stack should contain the panicked frame, got=%s", e.Stack)
+	}
+	if !strings.Contains(e.Error(), e.Stack) {
+		t.Errorf("Error message should contain the stack")
+	}
+}
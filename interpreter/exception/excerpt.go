@@ -0,0 +1,77 @@
+package exception
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ysugimoto/falco/v2/token"
+)
+
+// Number of lines which are shown before and after the line of the exception
+const excerptContextLines = 2
+
+// Excerpt is the source excerpt around the position where the exception occurs
+type Excerpt struct {
+	File     string
+	Line     int
+	Position int
+	Length   int      // Length of the caret under the token
+	Start    int      // Line number of the first line in Lines
+	Lines    []string // Source lines around the exception
+	Includes []string // Include chain like "main.vcl:10" from the main VCL to the file
+}
+
+// NewExcerpt cuts out lines around the token from the source.
+// Returns nil if the token position is out of the source
+func NewExcerpt(source string, t *token.Token, includes []string) *Excerpt {
+	lines := strings.Split(source, "\n")
+	if t == nil || t.Line < 1 || t.Line > len(lines) {
+		return nil
+	}
+	start := max(1, t.Line-excerptContextLines)
+	end := min(len(lines), t.Line+excerptContextLines)
+
+	return &Excerpt{
+		File:     t.File,
+		Line:     t.Line,
+		Position: t.Position,
+		Length:   max(1, len([]rune(t.Literal))+t.Offset),
+		Start:    start,
+		Lines:    lines[start-1 : end],
+		Includes: includes,
+	}
+}
+
+// String renders the excerpt with the caret under the error position like:
+//
+//	--> /path/to/module.vcl:10:20
+//	   |
+//	 9 |   set req.http.Foo = "foo";
+//	10 |   set req.http.Bar = var.bar;
+//	   |                      ^^^^^^^
+//	11 | }
+//	   = included from /path/to/main.vcl:3
+func (x *Excerpt) String() string {
+	width := len(fmt.Sprint(x.Start + len(x.Lines) - 1))
+	gutter := strings.Repeat(" ", width)
+
+	var buf strings.Builder
+	buf.WriteString(fmt.Sprintf("%s--> %s:%d:%d\n", gutter, x.File, x.Line, x.Position))
+	buf.WriteString(gutter + " |\n")
+	for i, line := range x.Lines {
+		n := x.Start + i
+		buf.WriteString(fmt.Sprintf("%*d | %s\n", width, n, strings.ReplaceAll(line, "\t", "    ")))
+		if n != x.Line {
+			continue
+		}
+		// Tabs before the position are expanded as well as the line
+		prefix := []rune(line)[:min(len([]rune(line)), max(0, x.Position-1))]
+		column := len(prefix) + strings.Count(string(prefix), "\t")*3
+		buf.WriteString(fmt.Sprintf("%s | %s%s\n", gutter, strings.Repeat(" ", column), strings.Repeat("^", x.Length)))
+	}
+	// Innermost includer first like a stack trace
+	for i := len(x.Includes) - 1; i >= 0; i-- {
+		buf.WriteString(fmt.Sprintf("%s = included from %s\n", gutter, x.Includes[i]))
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
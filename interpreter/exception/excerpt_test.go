@@ -0,0 +1,68 @@
+package exception
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/ysugimoto/falco/v2/token"
+)
+
+func TestExcerpt(t *testing.T) {
+	source := `sub vcl_recv {
+  set req.http.Foo = "foo";
+	set req.http.Bar = var.bar;
+  set req.http.Baz = "baz";
+}`
+
+	t.Run("render source lines around the token with caret", func(t *testing.T) {
+		x := NewExcerpt(source, &token.Token{
+			Literal:  "var.bar",
+			Line:     3,
+			Position: 21,
+			File:     "module.vcl",
+		}, []string{"main.vcl:3", "sub.vcl:10"})
+		if x == nil {
+			t.Fatalf("Excerpt should be created")
+		}
+		expect := ` --> module.vcl:3:21
+  |
+1 | sub vcl_recv {
+2 |   set req.http.Foo = "foo";
+3 |     set req.http.Bar = var.bar;
+  |                        ^^^^^^^
+4 |   set req.http.Baz = "baz";
+5 | }
+  = included from sub.vcl:10
+  = included from main.vcl:3`
+		if diff := cmp.Diff(expect, x.String()); diff != "" {
+			t.Errorf("Rendered excerpt mismatch, diff=%s", diff)
+		}
+	})
+
+	t.Run("context lines are trimmed at the beginning of the source", func(t *testing.T) {
+		x := NewExcerpt(source, &token.Token{Literal: "sub", Line: 1, Position: 1}, nil)
+		if x == nil {
+			t.Fatalf("Excerpt should be created")
+		}
+		if x.Start != 1 || len(x.Lines) != 3 {
+			t.Errorf("Unexpected lines, start=%d, lines=%d", x.Start, len(x.Lines))
+		}
+	})
+
+	t.Run("nil if the token is out of the source", func(t *testing.T) {
+		if x := NewExcerpt(source, &token.Token{Line: 10}, nil); x != nil {
+			t.Errorf("Excerpt should not be created, got=%v", x)
+		}
+	})
+}
+
+func TestPretty(t *testing.T) {
+	e := Runtime(&token.Token{Literal: "foo", Line: 1, Position: 1, File: "main.vcl"}, "Undefined variable")
+	if diff := cmp.Diff(e.Error(), e.Pretty()); diff != "" {
+		t.Errorf("Pretty should be the same as Error without excerpt, diff=%s", diff)
+	}
+	e.Excerpt = NewExcerpt("foo", e.Token, nil)
+	if diff := cmp.Diff(e.Error()+"\n"+e.Excerpt.String(), e.Pretty()); diff != "" {
+		t.Errorf("Pretty should have the excerpt, diff=%s", diff)
+	}
+}
@@ -0,0 +1,103 @@
+package interpreter
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/ysugimoto/falco/v2/token"
+)
+
+// Add explanation reason if explain is enabled
+func (i *Interpreter) explain(tok *token.Token, format string, args ...any) {
+	if i.process.Explanation == nil {
+		return
+	}
+	i.process.Explanation.Add(tok, format, args...)
+}
+
+func (i *Interpreter) explainResult(result string) {
+	if i.process.Explanation == nil {
+		return
+	}
+	i.process.Explanation.Result = result
+}
+
+// Explain the state which is returned from the state machine subroutine.
+// The location is the last processed return, error, or restart statement in the current scope
+func (i *Interpreter) explainState(name string, state State) {
+	if i.process.Explanation == nil {
+		return
+	}
+	if i.lastTransition == nil {
+		i.explain(nil, "%s moves to %s state by default", name, state)
+		return
+	}
+	i.explain(&i.lastTransition.GetMeta().Token, "%s returned %s", name, state)
+}
+
+// Returns the token of the statement which lastly wrote the variable
+func (i *Interpreter) lastWriteToken(name string) *token.Token {
+	writers := i.process.Writers(name)
+	if len(writers) == 0 {
+		return nil
+	}
+	w := writers[len(writers)-1]
+	return &token.Token{File: w.File, Line: w.Line, Position: w.Position}
+}
+
+// Explain the client request conditions that commonly cause PASS
+func (i *Interpreter) explainRequest() {
+	if i.process.Explanation == nil {
+		return
+	}
+	req := i.ctx.Request
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, "FASTLYPURGE":
+	default:
+		i.explain(nil, "Request method is %s which is not cacheable", req.Method)
+	}
+	if req.Header.Get("Cookie") != "" {
+		i.explain(nil, "Request has Cookie header")
+	}
+	if req.Header.Get("Authorization") != "" {
+		i.explain(nil, "Request has Authorization header")
+	}
+}
+
+// Explain the backend response conditions that prevent caching
+func (i *Interpreter) explainBackendResponse() {
+	if i.process.Explanation == nil {
+		return
+	}
+	resp := i.ctx.BackendResponse
+	if !i.ctx.BackendResponseCacheable.Value {
+		if tok := i.lastWriteToken("beresp.cacheable"); tok != nil {
+			i.explain(tok, "beresp.cacheable is set to false")
+		} else {
+			i.explain(nil, "Backend response status %d is not cacheable", resp.StatusCode)
+		}
+	} else if i.ctx.BackendResponseTTL.Value <= 0 {
+		if tok := i.lastWriteToken("beresp.ttl"); tok != nil {
+			i.explain(tok, "beresp.ttl is set to %s", i.ctx.BackendResponseTTL.Value)
+		} else {
+			i.explain(nil, "beresp.ttl is %s which is determined from backend response", i.ctx.BackendResponseTTL.Value)
+		}
+	}
+
+	cc := strings.ToLower(resp.Header.Get("Cache-Control"))
+	for _, directive := range []string{"private", "no-store", "no-cache"} {
+		if strings.Contains(cc, directive) {
+			i.explain(nil, "Backend response has Cache-Control: %s", directive)
+		}
+	}
+	if resp.Header.Get("Set-Cookie") != "" {
+		i.explain(nil, "Backend response has Set-Cookie header")
+	}
+}
+
+// Finalize explanation. Accesses are recorded for explanation, drop them if provenance is not enabled
+func (i *Interpreter) finalizeExplanation() {
+	if !i.ctx.Provenance {
+		i.process.Accesses = nil
+	}
+}
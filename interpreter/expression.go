@@ -1,15 +1,19 @@
 package interpreter
 
 import (
+	"bytes"
+	"maps"
 	"net"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
 	"github.com/ysugimoto/falco/v2/ast"
 	"github.com/ysugimoto/falco/v2/interpreter/exception"
-	"github.com/ysugimoto/falco/v2/interpreter/function"
 	"github.com/ysugimoto/falco/v2/interpreter/operator"
+	"github.com/ysugimoto/falco/v2/interpreter/process"
 	"github.com/ysugimoto/falco/v2/interpreter/value"
 )
 
@@ -34,18 +38,24 @@ func (i *Interpreter) IdentValue(val string, opt *ExpressionOption) (value.Value
 		return &value.Ident{Value: val, Literal: true}, nil
 	} else if _, ok := i.ctx.Ratecounters[val]; ok {
 		return &value.Ident{Value: val, Literal: true}, nil
+	} else if c, ok := i.ctx.Constants[val]; ok {
+		// Constant is evaluated as the literal value
+		return i.processExpression(c.Value, opt)
 	} else if strings.HasPrefix(val, "var.") {
 		if v, err := i.localVars.Get(val); err != nil {
-			return value.Null, errors.WithStack(err)
+			return value.Null, errors.WithStack(withSuggestion(err, val, slices.Collect(maps.Keys(i.localVars))))
 		} else {
 			return v, nil
 		}
-	} else if v, err := i.vars.Get(i.ctx.Scope, val); err != nil {
+	} else if v, err := i.getVariable(val); err != nil {
 		if opt.Condition() {
 			return value.Null, nil
-		} else {
-			return value.Null, errors.WithStack(err)
 		}
+		// Suggest closest names if the variable is unknown
+		if _, ok := builtinDefinitions().PredefinedVariableType(val); !ok {
+			err = withSuggestion(err, val, i.identCandidates())
+		}
+		return value.Null, errors.WithStack(err)
 	} else {
 		return v, nil
 	}
@@ -73,7 +83,15 @@ func (i *Interpreter) processExpression(exp ast.Expression, opt *ExpressionOptio
 	switch t := exp.(type) {
 	// Underlying VCL type expressions
 	case *ast.Ident:
-		return i.IdentValue(t.Value, opt)
+		v, err := i.IdentValue(t.Value, opt)
+		if err != nil {
+			return v, err
+		}
+		if err := i.checkStrictHeader(t, v); err != nil {
+			return value.Null, errors.WithStack(err)
+		}
+		i.recordAccess(process.AccessRead, t, "", v)
+		return v, nil
 	case *ast.IP:
 		return &value.IP{Value: net.ParseIP(t.Value), Literal: true}, nil
 	case *ast.Boolean:
@@ -139,7 +157,7 @@ func (i *Interpreter) ProcessPrefixExpression(exp *ast.PrefixExpression, opt *Ex
 	case "!":
 		switch t := v.(type) {
 		case *value.Boolean:
-			return &value.Boolean{Value: !t.Value}, nil
+			return value.NewBoolean(!t.Value), nil
 		case *value.String:
 			// If withCondition is enabled, STRING could be converted to BOOL
 			if !opt.Condition() {
@@ -148,9 +166,9 @@ func (i *Interpreter) ProcessPrefixExpression(exp *ast.PrefixExpression, opt *Ex
 				)
 			}
 			if t.IsNotSet {
-				return &value.Boolean{Value: true}, nil
+				return value.True, nil
 			}
-			return &value.Boolean{Value: false}, nil
+			return value.False, nil
 		default:
 			return value.Null, errors.WithStack(
 				exception.Runtime(&exp.GetMeta().Token, `Unexpected "!" prefix operator for %v`, v),
@@ -271,9 +289,13 @@ func (i *Interpreter) ProcessFunctionCallExpression(exp *ast.FunctionCallExpress
 	}
 
 	// Otherwise, process as builtin function
-	fn, err := function.Exists(i.ctx.Scope, exp.Function.Value)
+	fn, err := i.findFunction(exp.Function.Value)
 	if err != nil {
-		return value.Null, errors.WithStack(err)
+		v, err := i.unimplementedFunction(exp.Function.Value, err)
+		if err != nil {
+			return value.Null, errors.WithStack(err)
+		}
+		return v, nil
 	}
 	args := make([]value.Value, len(exp.Arguments))
 	for j := range exp.Arguments {
@@ -360,6 +382,13 @@ func (i *Interpreter) ProcessInfixExpression(exp *ast.InfixExpression, opt *Expr
 	return result, nil
 }
 
+// Buffers for string concatenation are reused across expressions to reduce allocations
+var concatBufferPool = sync.Pool{
+	New: func() any {
+		return new(bytes.Buffer)
+	},
+}
+
 // InfixExpression process, but special case for string concatenation.
 // string cocatenation has special type checking rule.
 // left and right expression must be following expressions:
@@ -413,7 +442,11 @@ func (i *Interpreter) ProcessStringConcatInfixExpression(exp *ast.InfixExpressio
 		return &value.String{IsNotSet: true}, nil
 	}
 
-	var rv value.Value = &value.String{}
+	buf := concatBufferPool.Get().(*bytes.Buffer)
+	defer func() {
+		buf.Reset()
+		concatBufferPool.Put(buf)
+	}()
 	var opErr error
 
 	for idx := 0; idx < len(series); idx++ {
@@ -425,7 +458,7 @@ func (i *Interpreter) ProcessStringConcatInfixExpression(exp *ast.InfixExpressio
 
 		switch s.Expression.(type) {
 		case *ast.String:
-			rv, opErr = operator.Concat(rv, cv)
+			opErr = operator.ConcatInto(buf, cv)
 			if opErr != nil {
 				return value.Null, errors.WithStack(err)
 			}
@@ -437,7 +470,7 @@ func (i *Interpreter) ProcessStringConcatInfixExpression(exp *ast.InfixExpressio
 					cv.Type(),
 				)
 			}
-			rv, opErr = operator.Concat(rv, cv)
+			opErr = operator.ConcatInto(buf, cv)
 			if opErr != nil {
 				return value.Null, errors.WithStack(err)
 			}
@@ -450,7 +483,7 @@ func (i *Interpreter) ProcessStringConcatInfixExpression(exp *ast.InfixExpressio
 					cv.Type(),
 				)
 			}
-			rv, opErr = operator.Concat(rv, cv)
+			opErr = operator.ConcatInto(buf, cv)
 			if opErr != nil {
 				return value.Null, errors.WithStack(err)
 			}
@@ -461,12 +494,12 @@ func (i *Interpreter) ProcessStringConcatInfixExpression(exp *ast.InfixExpressio
 					// notset treating for string concatenation to local variable.
 					// The local variable should be treated as empty string even value is notset
 					if str := value.Unwrap[*value.String](cv); str.IsNotSet {
-						rv, opErr = operator.Concat(rv, &value.String{IsNotSet: false})
+						opErr = operator.ConcatInto(buf, value.EmptyString)
 					} else {
-						rv, opErr = operator.Concat(rv, cv)
+						opErr = operator.ConcatInto(buf, cv)
 					}
 				} else {
-					rv, opErr = operator.Concat(rv, cv)
+					opErr = operator.ConcatInto(buf, cv)
 				}
 
 				if opErr != nil {
@@ -478,12 +511,12 @@ func (i *Interpreter) ProcessStringConcatInfixExpression(exp *ast.InfixExpressio
 					// notset treating for string concatenation to local variable.
 					// The local variable should be treated as empty string even value is notset
 					if str := value.Unwrap[*value.IP](cv); str.IsNotSet {
-						rv, opErr = operator.Concat(rv, &value.String{IsNotSet: false})
+						opErr = operator.ConcatInto(buf, value.EmptyString)
 					} else {
-						rv, opErr = operator.Concat(rv, cv)
+						opErr = operator.ConcatInto(buf, cv)
 					}
 				} else {
-					rv, opErr = operator.Concat(rv, cv)
+					opErr = operator.ConcatInto(buf, cv)
 				}
 
 				if opErr != nil {
@@ -505,7 +538,7 @@ func (i *Interpreter) ProcessStringConcatInfixExpression(exp *ast.InfixExpressio
 							return value.Null, errors.WithStack(err)
 						}
 						// String concat with left and time-calculated value (TIME type)
-						rv, opErr = operator.Concat(rv, cv)
+						opErr = operator.ConcatInto(buf, cv)
 						if opErr != nil {
 							return value.Null, errors.WithStack(err)
 						}
@@ -534,7 +567,7 @@ func (i *Interpreter) ProcessStringConcatInfixExpression(exp *ast.InfixExpressio
 					}
 				}
 			}
-			rv, opErr = operator.Concat(rv, cv)
+			opErr = operator.ConcatInto(buf, cv)
 			if opErr != nil {
 				return value.Null, errors.WithStack(err)
 			}
@@ -547,19 +580,22 @@ func (i *Interpreter) ProcessStringConcatInfixExpression(exp *ast.InfixExpressio
 		}
 	}
 
-	return rv, nil
+	return &value.String{Value: buf.String()}, nil
 }
 
 func (i *Interpreter) toSeriesExpression(expr ast.Expression) ([]*series, error) {
 	switch t := expr.(type) {
 	case *ast.Ident:
-		// If expression is ident, it must be a variable
+		// If expression is ident, it must be a variable or constant
 		// e.g req.http.Header, var.declaredVariable
+		if _, ok := i.ctx.Constants[t.Value]; ok {
+			break
+		}
 		if strings.HasPrefix(t.Value, "var.") {
 			if _, err := i.localVars.Get(t.Value); err != nil {
 				return nil, errors.WithStack(err)
 			}
-		} else if _, err := i.vars.Get(i.ctx.Scope, t.Value); err != nil {
+		} else if _, err := i.getVariable(t.Value); err != nil {
 			return nil, errors.WithStack(err)
 		}
 	case *ast.PrefixExpression:
@@ -710,3 +710,54 @@ func TestIsNotSetSeriesCheck(t *testing.T) {
 		})
 	}
 }
+
+func setupBenchmarkInterpreter() *Interpreter {
+	i := New()
+	i.ctx = context.New()
+	i.ctx.Request = http.WrapRequest(
+		httptest.NewRequest(ghttp.MethodGet, "http://localhost:3124/path", nil),
+	)
+	i.SetScope(context.RecvScope)
+	return i
+}
+
+func BenchmarkProcessStringConcat(b *testing.B) {
+	i := setupBenchmarkInterpreter()
+	meta := &ast.Meta{Token: token.Token{Type: token.PLUS}}
+	exp := &ast.InfixExpression{
+		Meta: meta,
+		Left: &ast.InfixExpression{
+			Meta:     meta,
+			Left:     &ast.String{Meta: meta, Value: "https://example.com"},
+			Operator: "+",
+			Right:    &ast.Ident{Meta: meta, Value: "req.url"},
+		},
+		Operator: "+",
+		Right:    &ast.String{Meta: meta, Value: "?from=falco"},
+	}
+
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := i.ProcessExpression(exp); err != nil {
+			b.Fatalf("Unexpected error: %s", err)
+		}
+	}
+}
+
+func BenchmarkProcessConditionExpression(b *testing.B) {
+	i := setupBenchmarkInterpreter()
+	meta := &ast.Meta{Token: token.Token{Type: token.EQUAL}}
+	exp := &ast.InfixExpression{
+		Meta:     meta,
+		Left:     &ast.Ident{Meta: meta, Value: "req.url"},
+		Operator: "==",
+		Right:    &ast.String{Meta: meta, Value: "/path"},
+	}
+
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := i.ProcessExpression(exp, ConditionExpression()); err != nil {
+			b.Fatalf("Unexpected error: %s", err)
+		}
+	}
+}
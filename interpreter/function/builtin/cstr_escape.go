@@ -27,8 +27,8 @@ func Cstr_escape_Validate(args []value.Value) error {
 }
 
 var Cstr_escape_CharacterMap = map[byte][]byte{
-	0x22: []byte("\""),
-	0x5C: []byte("\\"),
+	0x22: []byte("\\\""),
+	0x5C: []byte("\\\\"),
 	0x08: []byte("\\b"),
 	0x09: []byte("\\t"),
 	0x0A: []byte("\\n"),
@@ -53,8 +53,9 @@ func Cstr_escape(ctx *context.Context, args ...value.Value) (value.Value, error)
 			escaped = append(escaped, v...)
 			continue
 		}
-		if b < 0x1F || 0x7F < b {
-			escaped = fmt.Appendf(escaped, "\\x%x", b)
+		// Other control characters, DEL and non-ASCII bytes are escaped as two digits hex
+		if b < 0x20 || 0x7F <= b {
+			escaped = fmt.Appendf(escaped, "\\x%02x", b)
 			continue
 		}
 		escaped = append(escaped, b)
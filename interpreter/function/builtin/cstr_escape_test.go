@@ -21,7 +21,11 @@ func Test_Cstr_escape(t *testing.T) {
 	}{
 		{
 			input:  `"`,
-			expect: "\"",
+			expect: `\"`,
+		},
+		{
+			input:  `\`,
+			expect: `\\`,
 		},
 		{
 			input:  string([]byte{0x08}),
@@ -47,6 +51,22 @@ func Test_Cstr_escape(t *testing.T) {
 			input:  string([]byte{0x10}),
 			expect: "\\x10",
 		},
+		{
+			input:  string([]byte{0x01}),
+			expect: "\\x01",
+		},
+		{
+			input:  string([]byte{0x1F}),
+			expect: "\\x1f",
+		},
+		{
+			input:  string([]byte{0x7F}),
+			expect: "\\x7f",
+		},
+		{
+			input:  "é",
+			expect: "\\xc3\\xa9",
+		},
 		{
 			input:  "abc",
 			expect: "abc",
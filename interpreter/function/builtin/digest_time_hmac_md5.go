@@ -44,7 +44,7 @@ func Digest_time_hmac_md5(ctx *context.Context, args ...value.Value) (value.Valu
 	secret := value.Unwrap[*value.String](args[0])
 	interval := value.Unwrap[*value.Integer](args[1])
 	offset := value.Unwrap[*value.Integer](args[2])
-	return digest_time_hmac_md5(time.Now(), secret, interval, offset)
+	return digest_time_hmac_md5(ctx.Now(), secret, interval, offset)
 }
 
 func digest_time_hmac_md5(baseTime time.Time, secret *value.String, interval, offset *value.Integer) (value.Value, error) {
@@ -44,7 +44,7 @@ func Digest_time_hmac_sha1(ctx *context.Context, args ...value.Value) (value.Val
 	secret := value.Unwrap[*value.String](args[0])
 	interval := value.Unwrap[*value.Integer](args[1])
 	offset := value.Unwrap[*value.Integer](args[2])
-	return digest_time_hmac_sha1(time.Now(), secret, interval, offset)
+	return digest_time_hmac_sha1(ctx.Now(), secret, interval, offset)
 }
 
 func digest_time_hmac_sha1(baseTime time.Time, secret *value.String, interval, offset *value.Integer) (value.Value, error) {
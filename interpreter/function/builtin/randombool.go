@@ -3,8 +3,6 @@
 package builtin
 
 import (
-	"math/rand"
-
 	"github.com/ysugimoto/falco/v2/interpreter/context"
 	"github.com/ysugimoto/falco/v2/interpreter/function/errors"
 	"github.com/ysugimoto/falco/v2/interpreter/value"
@@ -46,7 +44,7 @@ func Randombool(ctx *context.Context, args ...value.Value) (value.Value, error)
 		return &value.Boolean{Value: true}, nil
 	}
 
-	rv := rand.Int63n(denominator.Value) + 1
+	rv := ctx.Rand().Int63n(denominator.Value) + 1
 
 	return &value.Boolean{Value: rv <= numerator.Value}, nil
 }
@@ -3,8 +3,6 @@
 package builtin
 
 import (
-	"math/rand"
-
 	"github.com/ysugimoto/falco/v2/interpreter/context"
 	"github.com/ysugimoto/falco/v2/interpreter/function/errors"
 	"github.com/ysugimoto/falco/v2/interpreter/value"
@@ -43,7 +41,7 @@ func Randomint(ctx *context.Context, args ...value.Value) (value.Value, error) {
 		return &value.Integer{Value: 0}, nil
 	}
 
-	rv := rand.Int63n(to.Value - from.Value + 1)
+	rv := ctx.Rand().Int63n(to.Value - from.Value + 1)
 
 	return &value.Integer{
 		Value: rv + from.Value,
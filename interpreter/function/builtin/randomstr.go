@@ -3,8 +3,6 @@
 package builtin
 
 import (
-	"math/rand"
-
 	"github.com/ysugimoto/falco/v2/interpreter/context"
 	"github.com/ysugimoto/falco/v2/interpreter/function/errors"
 	"github.com/ysugimoto/falco/v2/interpreter/value"
@@ -54,7 +52,7 @@ func Randomstr(ctx *context.Context, args ...value.Value) (value.Value, error) {
 	ret := make([]rune, int(length.Value))
 
 	for i := 0; i < int(length.Value); i++ {
-		ret[i] = characters[rand.Intn(len(characters))]
+		ret[i] = characters[ctx.Rand().Intn(len(characters))]
 	}
 
 	return &value.String{Value: string(ret)}, nil
@@ -66,7 +66,7 @@ func Ratelimit_check_rate(ctx *context.Context, args ...value.Value) (value.Valu
 		if !ok {
 			return nil, errors.New(Ratelimit_check_rate_Name, "Penaltybox %s is not defined", pbName)
 		}
-		pb.Add(entry, ttl)
+		pb.Add(entry, ttl, ctx.Now())
 		return &value.Boolean{Value: true}, nil
 	}
 	return &value.Boolean{Value: false}, nil
@@ -94,9 +94,9 @@ func check_ratelimit(ctx *context.Context, entry string, rcName string, delta, w
 		return false, errors.New(Ratelimit_check_rate_Name, "Ratecounter %s is not defined", rcName)
 	}
 	// Increment delta
-	rc.Increment(entry, delta)
+	rc.Increment(entry, delta, ctx.Now())
 
 	// Compare the rate and limit
-	rate := rc.Rate(entry, time.Duration(window)*time.Second)
+	rate := rc.Rate(entry, time.Duration(window)*time.Second, ctx.Now())
 	return rate > float64(limit), nil
 }
@@ -72,7 +72,7 @@ func Ratelimit_check_rates(ctx *context.Context, args ...value.Value) (value.Val
 		if !ok {
 			return nil, errors.New(Ratelimit_check_rates_Name, "Penaltybox %s is not defined", pbName)
 		}
-		pb.Add(entry, ttl)
+		pb.Add(entry, ttl, ctx.Now())
 		return &value.Boolean{Value: true}, nil
 	}
 	return &value.Boolean{Value: false}, nil
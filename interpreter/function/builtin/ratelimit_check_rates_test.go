@@ -182,7 +182,7 @@ func Test_Ratelimit_check_rates(t *testing.T) {
 					t.Errorf("Penaltybox %s not found in test setup", pbName)
 					return
 				}
-				if !pbox.Has(tt.penaltyboxHas) {
+				if !pbox.Has(tt.penaltyboxHas, time.Now()) {
 					t.Errorf("Expected entry %s to be in penaltybox, but it was not", tt.penaltyboxHas)
 				}
 			}
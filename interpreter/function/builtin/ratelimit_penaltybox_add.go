@@ -54,6 +54,6 @@ func Ratelimit_penaltybox_add(ctx *context.Context, args ...value.Value) (value.
 	if !ok {
 		return nil, errors.New(Ratelimit_penaltybox_add_Name, "Penaltybox %s is not defined", name)
 	}
-	pb.Add(entry, ttl)
+	pb.Add(entry, ttl, ctx.Now())
 	return nil, nil
 }
@@ -85,7 +85,7 @@ func Test_Ratelimit_penaltybox_add(t *testing.T) {
 			case value.IpType:
 				entry = value.Unwrap[*value.IP](tt.entry).String()
 			}
-			if !pb.Has(entry) {
+			if !pb.Has(entry, time.Now()) {
 				t.Errorf("entry %s does not exist in penaltybox", entry)
 			}
 		})
@@ -54,6 +54,6 @@ func Ratelimit_penaltybox_has(ctx *context.Context, args ...value.Value) (value.
 		return value.Null, errors.New(Ratelimit_penaltybox_add_Name, "Penaltybox %s is not defined", name)
 	}
 	return &value.Boolean{
-		Value: pb.Has(entry),
+		Value: pb.Has(entry, ctx.Now()),
 	}, nil
 }
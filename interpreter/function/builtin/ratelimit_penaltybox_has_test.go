@@ -25,6 +25,7 @@ func Test_Ratelimit_penaltybox_has(t *testing.T) {
 		isError   bool
 		expectErr string
 		sleep     time.Duration
+		after     time.Duration
 	}{
 		{
 			name:   "entry exists in penaltybox",
@@ -58,6 +59,13 @@ func Test_Ratelimit_penaltybox_has(t *testing.T) {
 			expect: false,
 			sleep:  2 * time.Second,
 		},
+		{
+			name:   "entry expired on the context clock",
+			ident:  "my_penaltybox",
+			entry:  &value.String{Value: "expired_entry"},
+			expect: false,
+			after:  2 * time.Second,
+		},
 	}
 
 	for _, tt := range tests {
@@ -65,9 +73,9 @@ func Test_Ratelimit_penaltybox_has(t *testing.T) {
 			pb := value.NewPenaltybox(&ast.PenaltyboxDeclaration{
 				Name: &ast.Ident{Value: "my_penaltybox"},
 			})
-			pb.Add("some_entry", 10*time.Second)
-			pb.Add("192.168.0.1", 10*time.Second)
-			pb.Add("expired_entry", 1*time.Second)
+			pb.Add("some_entry", 10*time.Second, time.Now())
+			pb.Add("192.168.0.1", 10*time.Second, time.Now())
+			pb.Add("expired_entry", 1*time.Second, time.Now())
 
 			if tt.sleep > 0 {
 				time.Sleep(tt.sleep)
@@ -78,6 +86,10 @@ func Test_Ratelimit_penaltybox_has(t *testing.T) {
 					"my_penaltybox": pb,
 				},
 			}
+			if tt.after > 0 {
+				now := time.Now().Add(tt.after)
+				ctx.FixedTime = &now
+			}
 			ret, err := Ratelimit_penaltybox_has(
 				ctx,
 				&value.Ident{Value: tt.ident},
@@ -64,11 +64,11 @@ func Ratelimit_ratecounter_increment(ctx *context.Context, args ...value.Value)
 		}, nil
 	}
 
-	rc.Increment(entry, increment)
+	rc.Increment(entry, increment, ctx.Now())
 
 	// Returns bucket count for recent 1 minute
 	return &value.Integer{
-		Value: rc.Bucket(entry, time.Minute),
+		Value: rc.Bucket(entry, time.Minute, ctx.Now()),
 	}, nil
 
 }
@@ -3,6 +3,7 @@ package builtin
 import (
 	"net"
 	"testing"
+	"time"
 
 	"github.com/ysugimoto/falco/v2/interpreter/context"
 	"github.com/ysugimoto/falco/v2/interpreter/value"
@@ -10,7 +11,7 @@ import (
 
 func Test_Ratelimit_ratecounter_increment(t *testing.T) {
 	rc := value.NewRatecounter(nil)
-	rc.Increment("127.0.0.1", 1)
+	rc.Increment("127.0.0.1", 1, time.Now())
 
 	tests := []struct {
 		name         string
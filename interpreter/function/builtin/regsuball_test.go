@@ -127,6 +127,46 @@ func Test_Regsuball(t *testing.T) {
 			expect:      "[foo][][] [][bar][] [][][baz]",
 			literal:     true,
 		},
+		{
+			name:        "whole match reference",
+			input:       "a1b2",
+			pattern:     `\d`,
+			replacement: `<\0>`,
+			expect:      "a<1>b<2>",
+			literal:     true,
+		},
+		{
+			name:        "escaped backslash in replacement",
+			input:       "a/b/c",
+			pattern:     "/",
+			replacement: `\\`,
+			expect:      `a\b\c`,
+			literal:     true,
+		},
+		{
+			name:        "escaped character in replacement is literal",
+			input:       "a-b",
+			pattern:     "-",
+			replacement: `\&`,
+			expect:      "a&b",
+			literal:     true,
+		},
+		{
+			name:        "trailing backslash in replacement is kept",
+			input:       "a-b",
+			pattern:     "-",
+			replacement: `\`,
+			expect:      `a\b`,
+			literal:     true,
+		},
+		{
+			name:        "dollar sign is not a group reference",
+			input:       "foo",
+			pattern:     "(o)",
+			replacement: "$1",
+			expect:      "f$1$1",
+			literal:     true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -29,87 +29,50 @@ func Std_anystr2ip_Validate(args []value.Value) error {
 	return nil
 }
 
-func Std_anystr2ip_ParseString(v string) (int64, error) {
+func Std_anystr2ip_ParseString(v string) (uint64, error) {
 	// "0" always indicates zero
 	if v == "0" {
 		return 0, nil
 	}
 
+	// Use ParseUint because sign characters like "+1" are not accepted
 	switch {
-	case strings.HasPrefix(v, "0x"): // hex
-		return strconv.ParseInt(strings.TrimPrefix(v, "0x"), 16, 64)
+	case strings.HasPrefix(v, "0x"), strings.HasPrefix(v, "0X"): // hex
+		return strconv.ParseUint(v[2:], 16, 32)
 	case strings.HasPrefix(v, "0"): // octet
-		return strconv.ParseInt(strings.TrimPrefix(v, "0"), 8, 64)
+		return strconv.ParseUint(strings.TrimPrefix(v, "0"), 8, 32)
 	default: // decimal
-		return strconv.ParseInt(v, 10, 64)
+		return strconv.ParseUint(v, 10, 32)
 	}
 }
 
 func Std_anystr2ip_ParseIpv4(addr string) (*value.IP, error) {
-	var ip int64
+	segments := strings.Split(addr, ".")
+	if len(segments) > 4 {
+		return nil, errors.New(Std_anystr2ip_Name, "Invalid IPv4 string: %s", addr)
+	}
 
-	segments := strings.SplitN(addr, ".", 4)
-	switch len(segments) {
-	case 1:
-		// first segment represetns all bits of IP (xxx.xxx.xxx.xxx)
-		v, err := Std_anystr2ip_ParseString(segments[0])
-		if err != nil {
-			return nil, errors.New(Std_anystr2ip_Name, "Failed to parse IPv4 string: %s", err.Error())
-		}
-		ip = v
-	case 2:
-		// first segment represetns first bits of IP (xxx.---.---.---)
-		v1, err := Std_anystr2ip_ParseString(segments[0])
-		if err != nil {
-			return nil, errors.New(Std_anystr2ip_Name, "Failed to parse IPv4 string: %s", err.Error())
-		}
-		// second segment represetns remainings of IPs (---.xxx.xxx.xxx)
-		v2, err := Std_anystr2ip_ParseString(segments[1])
-		if err != nil {
-			return nil, errors.New(Std_anystr2ip_Name, "Failed to parse IPv4 string: %s", err.Error())
-		}
-		ip = (v1 << 24) | v2
-	case 3:
-		// first segment represetns first bits of IP (xxx.---.---.---)
-		v1, err := Std_anystr2ip_ParseString(segments[0])
-		if err != nil {
-			return nil, errors.New(Std_anystr2ip_Name, "Failed to parse IPv4 string: %s", err.Error())
-		}
-		// second segment represetns second bits of IP (---.xxx.---.---)
-		v2, err := Std_anystr2ip_ParseString(segments[1])
+	// Like inet_aton(3), each segment represents a byte of IP from the head,
+	// and the last segment represents all remaining bytes.
+	// e.g "10.1" is 10.0.0.1, "192.168.257" is 192.168.1.1
+	var ip uint64
+	for i, segment := range segments {
+		v, err := Std_anystr2ip_ParseString(segment)
 		if err != nil {
 			return nil, errors.New(Std_anystr2ip_Name, "Failed to parse IPv4 string: %s", err.Error())
 		}
-		// thrid segment represetns remainings of IPs (---.---.xxx.xxx)
-		v3, err := Std_anystr2ip_ParseString(segments[2])
-		if err != nil {
-			return nil, errors.New(Std_anystr2ip_Name, "Failed to parse IPv4 string: %s", err.Error())
-		}
-		ip = (v1 << 24) | (v2 << 16) | v3
-	case 4:
-		// first segment represetns first bits of IP (xxx.---.---.---)
-		v1, err := Std_anystr2ip_ParseString(segments[0])
-		if err != nil {
-			return nil, errors.New(Std_anystr2ip_Name, "Failed to parse IPv4 string: %s", err.Error())
-		}
-		// second segment represetns second bits of IP (---.xxx.---.---)
-		v2, err := Std_anystr2ip_ParseString(segments[1])
-		if err != nil {
-			return nil, errors.New(Std_anystr2ip_Name, "Failed to parse IPv4 string: %s", err.Error())
+		remains := uint(4-i) * 8
+		if i == len(segments)-1 {
+			if v >= 1<<remains {
+				return nil, errors.New(Std_anystr2ip_Name, "IPv4 segment %s is out of range", segment)
+			}
+			ip |= v
+			break
 		}
-		// thrid segment represetns third bits of IP (---.---.xxx.---)
-		v3, err := Std_anystr2ip_ParseString(segments[2])
-		if err != nil {
-			return nil, errors.New(Std_anystr2ip_Name, "Failed to parse IPv4 string: %s", err.Error())
+		if v > 0xFF {
+			return nil, errors.New(Std_anystr2ip_Name, "IPv4 segment %s is out of range", segment)
 		}
-		// last segment represetns fourth bits of IP (---.---.---.xxx)
-		v4, err := Std_anystr2ip_ParseString(segments[3])
-		if err != nil {
-			return nil, errors.New(Std_anystr2ip_Name, "Failed to parse IPv4 string: %s", err.Error())
-		}
-		ip = (v1 << 24) | (v2 << 16) | (v3 << 8) | v4
-	default:
-		return nil, errors.New(Std_anystr2ip_Name, "Invalid IPv4 string: %s", addr)
+		ip |= v << (remains - 8)
 	}
 
 	return &value.IP{
@@ -41,6 +41,30 @@ func Test_Std_anystr2ip(t *testing.T) {
 			fallback: "10.0.0.0",
 			expect:   "192.0.2.1",
 		},
+		{
+			name:     "IPv4 two segments",
+			input:    "10.1",
+			fallback: "0.0.0.0",
+			expect:   "10.0.0.1",
+		},
+		{
+			name:     "IPv4 uppercase hex prefix",
+			input:    "0XC0.0.2.1",
+			fallback: "0.0.0.0",
+			expect:   "192.0.2.1",
+		},
+		{
+			name:     "IPv4 segment out of range falls back",
+			input:    "256.1.1.1",
+			fallback: "10.0.0.0",
+			expect:   "10.0.0.0",
+		},
+		{
+			name:     "IPv4 sign character falls back",
+			input:    "+1.2.3.4",
+			fallback: "10.0.0.0",
+			expect:   "10.0.0.0",
+		},
 		// Standard IPv4
 		{
 			name:     "Standard IPv4",
@@ -3,6 +3,9 @@
 package builtin
 
 import (
+	"net/http"
+	"strings"
+
 	"github.com/ysugimoto/falco/v2/interpreter/context"
 	"github.com/ysugimoto/falco/v2/interpreter/function/errors"
 	"github.com/ysugimoto/falco/v2/interpreter/value"
@@ -24,6 +27,20 @@ func Std_collect_Validate(args []value.Value) error {
 	return nil
 }
 
+// Default separator which is used when separator argument is not provided
+const Std_collect_DefaultSeparator = ", "
+
+// Fold multiple header fields of the same name into a single field joined by the separator.
+// The edge only sees the first field when reading a header, so the folding is needed
+// to read all values like std.collect(req.http.Cookie, ";")
+func Std_collect_Fold(h http.Header, name, sep string) {
+	values := h.Values(name)
+	if len(values) < 2 {
+		return
+	}
+	h.Set(name, strings.Join(values, sep))
+}
+
 // Fastly built-in function implementation of std.collect
 // Arguments may be:
 // - ID
@@ -35,31 +52,41 @@ func Std_collect(ctx *context.Context, args ...value.Value) (value.Value, error)
 		return value.Null, err
 	}
 
-	// TODO: std.collection has no effect because Golang's HTTP header is automatically collected
-	// ident := value.Unwrap[*value.Ident](args[0])
-	// sep := ","
-	// if len(args) > 1 {
-	// 	sep = value.Unwrap[*value.String](args[1]).Value
-	// }
-	//
-	// switch {
-	// case strings.HasPrefix(ident.Value, "req.http."):
-	// 	name := strings.TrimPrefix(ident.Value, "req.http.")
-	// 	value := ctx.Request.Header.Values(name)
-	// 	ctx.Request.Header.Set(name, strings.Join(value, sep))
-	// case strings.HasPrefix(ident.Value, "bereq.http"):
-	// 	name := strings.TrimPrefix(ident.Value, "bereq.http.")
-	// 	value := ctx.BackendRequest.Header.Values(name)
-	// 	ctx.BackendRequest.Header.Set(name, strings.Join(value, sep))
-	// case strings.HasPrefix(ident.Value, "beresp.http"):
-	// 	name := strings.TrimPrefix(ident.Value, "beresp.http.")
-	// 	value := ctx.BackendResponse.Header.Values(name)
-	// 	ctx.Request.Header.Set(name, strings.Join(value, sep))
-	// case strings.HasPrefix(ident.Value, "resp.http"):
-	// 	name := strings.TrimPrefix(ident.Value, "resp.http.")
-	// 	value := ctx.BackendResponse.Header.Values(name)
-	// 	ctx.BackendResponse.Header.Set(name, strings.Join(value, sep))
-	// }
+	ident := value.Unwrap[*value.Ident](args[0])
+	sep := Std_collect_DefaultSeparator
+	if len(args) > 1 {
+		sep = value.Unwrap[*value.String](args[1]).Value
+	}
+
+	scope, name, found := strings.Cut(ident.Value, ".http.")
+	if !found || name == "" || strings.Contains(name, ":") {
+		return value.Null, errors.New(Std_collect_Name, "Argument must be a header variable, %s provided", ident.Value)
+	}
+
+	switch scope {
+	case "req":
+		if ctx.Request != nil {
+			Std_collect_Fold(ctx.Request.Header, name, sep)
+		}
+	case "bereq":
+		if ctx.BackendRequest != nil {
+			Std_collect_Fold(ctx.BackendRequest.Header, name, sep)
+		}
+	case "beresp":
+		if ctx.BackendResponse != nil {
+			Std_collect_Fold(ctx.BackendResponse.Header, name, sep)
+		}
+	case "obj":
+		if ctx.Object != nil {
+			Std_collect_Fold(ctx.Object.Header, name, sep)
+		}
+	case "resp":
+		if ctx.Response != nil {
+			Std_collect_Fold(ctx.Response.Header, name, sep)
+		}
+	default:
+		return value.Null, errors.New(Std_collect_Name, "Argument must be a header variable, %s provided", ident.Value)
+	}
 
 	return value.Null, nil
 }
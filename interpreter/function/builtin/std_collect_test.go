@@ -20,28 +20,74 @@ import (
 // Reference: https://developer.fastly.com/reference/vcl/functions/miscellaneous/std-collect/
 func Test_Std_collect(t *testing.T) {
 	tests := []struct {
-		input  string
-		expect int64
+		ident   string
+		sep     *value.String
+		header  string
+		values  []string
+		expect  []string
+		isError bool
 	}{
-		{input: "req.headers", expect: 1},
+		{
+			ident:  "req.http.Cookie",
+			sep:    &value.String{Value: ";"},
+			header: "Cookie",
+			values: []string{"a=1", "b=2"},
+			expect: []string{"a=1;b=2"},
+		},
+		{
+			ident:  "req.http.Accept",
+			header: "Accept",
+			values: []string{"text/html", "application/json"},
+			expect: []string{"text/html, application/json"},
+		},
+		{
+			ident:  "req.http.Accept",
+			header: "Accept",
+			values: []string{"text/html"},
+			expect: []string{"text/html"},
+		},
+		{
+			ident:  "req.http.Foo",
+			header: "Accept",
+			values: []string{"text/html", "application/json"},
+			expect: []string{"text/html", "application/json"},
+		},
+		{
+			ident:   "req.http.Cookie:a",
+			header:  "Cookie",
+			values:  []string{"a=1"},
+			isError: true,
+		},
+		{
+			ident:   "client.ip",
+			header:  "Cookie",
+			values:  []string{"a=1"},
+			isError: true,
+		},
 	}
 
 	for i, tt := range tests {
 		req := httptest.NewRequest(ghttp.MethodGet, "https://example.com", nil)
-		req.Header.Set("Foo", "bar")
-		ret, err := Std_count(
-			&context.Context{Request: http.WrapRequest(req)},
-			&value.Ident{Value: tt.input},
-		)
+		for _, v := range tt.values {
+			req.Header.Add(tt.header, v)
+		}
+		args := []value.Value{&value.Ident{Value: tt.ident}}
+		if tt.sep != nil {
+			args = append(args, tt.sep)
+		}
+		_, err := Std_collect(&context.Context{Request: http.WrapRequest(req)}, args...)
+		if tt.isError {
+			if err == nil {
+				t.Errorf("[%d] Expected error but got nil", i)
+			}
+			continue
+		}
 		if err != nil {
 			t.Errorf("[%d] Unexpected error: %s", i, err)
+			continue
 		}
-		if ret.Type() != value.IntegerType {
-			t.Errorf("[%d] Unexpected return type, expect=INTEGER, got=%s", i, ret.Type())
-		}
-		v := value.Unwrap[*value.Integer](ret)
-		if diff := cmp.Diff(tt.expect, v.Value); diff != "" {
-			t.Errorf("[%d] Return value unmatch, diff=%s", i, diff)
+		if diff := cmp.Diff(tt.expect, req.Header.Values(tt.header)); diff != "" {
+			t.Errorf("[%d] Header values unmatch, diff=%s", i, diff)
 		}
 	}
 }
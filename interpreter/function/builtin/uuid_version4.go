@@ -29,7 +29,7 @@ func Uuid_version4(ctx *context.Context, args ...value.Value) (value.Value, erro
 		return value.Null, err
 	}
 
-	id, err := uuid.NewRandom()
+	id, err := uuid.NewRandomFromReader(ctx.Rand())
 	if err != nil {
 		return &value.String{IsNotSet: true}, errors.New(Uuid_version4_Name, "Failed to create random")
 	}
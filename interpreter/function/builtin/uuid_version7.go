@@ -29,7 +29,7 @@ func Uuid_version7(ctx *context.Context, args ...value.Value) (value.Value, erro
 		return value.Null, err
 	}
 
-	id, err := uuid.NewV7()
+	id, err := uuid.NewV7FromReader(ctx.Rand())
 	if err != nil {
 		return &value.String{IsNotSet: true}, errors.New(Uuid_version7_Name, "Failed to create uuid v7")
 	}
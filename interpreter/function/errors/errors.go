@@ -2,6 +2,7 @@ package errors
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/pkg/errors"
 
@@ -87,3 +88,16 @@ func NewAssertionError(actual value.Value, format string, args ...any) *Assertio
 func (e *AssertionError) Error() string {
 	return "Assertion Error: " + e.Message
 }
+
+// AssertionErrors is the aggregated failures of the test case in soft assertion mode
+type AssertionErrors struct {
+	Errors []error
+}
+
+func (e *AssertionErrors) Error() string {
+	messages := make([]string, len(e.Errors))
+	for i := range e.Errors {
+		messages[i] = e.Errors[i].Error()
+	}
+	return fmt.Sprintf("%d failures:\n%s", len(e.Errors), strings.Join(messages, "\n"))
+}
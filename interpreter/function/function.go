@@ -3,6 +3,7 @@ package function
 import (
 	"fmt"
 	"maps"
+	"slices"
 
 	"github.com/pkg/errors"
 	"github.com/ysugimoto/falco/v2/interpreter/context"
@@ -17,7 +18,12 @@ type Function struct {
 }
 
 func Exists(scope context.Scope, name string) (*Function, error) {
-	fn, ok := builtinFunctions[name]
+	return Find(builtinFunctions, scope, name)
+}
+
+// Find looks up the function from provided functions and checks the function could be called in the scope
+func Find(fns map[string]*Function, scope context.Scope, name string) (*Function, error) {
+	fn, ok := fns[name]
 	if !ok {
 		return nil, errors.WithStack(
 			fmt.Errorf("Function %s is not defined", name),
@@ -34,3 +40,14 @@ func Inject(fns map[string]*Function) {
 	// Always override existing functions
 	maps.Copy(builtinFunctions, fns)
 }
+
+// Names returns names of all functions which are implemented or injected
+func Names() []string {
+	return slices.Collect(maps.Keys(builtinFunctions))
+}
+
+// Implemented returns true if the builtin function is implemented in the interpreter regardless of the scope
+func Implemented(name string) bool {
+	_, ok := builtinFunctions[name]
+	return ok
+}
@@ -0,0 +1,26 @@
+package shared
+
+import (
+	"github.com/pkg/errors"
+	"golang.org/x/net/idna"
+)
+
+// IdnaToASCII converts internationalized domain name to ASCII compatible encoding
+// with the lookup profile like browsers send the Host header.
+// Labels are mapped before encoding so that uppercase and full-width characters are normalized
+func IdnaToASCII(domain string) (string, error) {
+	encoded, err := idna.Lookup.ToASCII(domain)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	return encoded, nil
+}
+
+// IdnaToUnicode converts ASCII compatible encoded domain name to unicode domain name
+func IdnaToUnicode(domain string) (string, error) {
+	decoded, err := idna.Lookup.ToUnicode(domain)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	return decoded, nil
+}
@@ -0,0 +1,104 @@
+package shared
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestIdnaToASCII(t *testing.T) {
+	tests := []struct {
+		input  string
+		expect string
+	}{
+		{
+			input:  "example.com",
+			expect: "example.com",
+		},
+		{
+			input:  "bücher.example",
+			expect: "xn--bcher-kva.example",
+		},
+		{
+			input:  "BÜCHER.example",
+			expect: "xn--bcher-kva.example",
+		},
+		{
+			input:  "日本語.jp",
+			expect: "xn--wgv71a119e.jp",
+		},
+		{
+			input:  "münchen.de",
+			expect: "xn--mnchen-3ya.de",
+		},
+		{
+			input:  "Bücher.Example",
+			expect: "xn--bcher-kva.example",
+		},
+		{
+			input:  "ＢＵＣＨＥＲ.example",
+			expect: "bucher.example",
+		},
+		{
+			input:  "ｂüｃｈｅｒ．example",
+			expect: "xn--bcher-kva.example",
+		},
+		{
+			input:  "日本語。ＪＰ",
+			expect: "xn--wgv71a119e.jp",
+		},
+	}
+
+	for _, tt := range tests {
+		actual, err := IdnaToASCII(tt.input)
+		if err != nil {
+			t.Errorf("Unexpected error: %s", err)
+			continue
+		}
+		if diff := cmp.Diff(tt.expect, actual); diff != "" {
+			t.Errorf("IdnaToASCII result mismatch for %s, diff=%s", tt.input, diff)
+		}
+	}
+}
+
+func TestIdnaToUnicode(t *testing.T) {
+	tests := []struct {
+		input  string
+		expect string
+		isErr  bool
+	}{
+		{
+			input:  "example.com",
+			expect: "example.com",
+		},
+		{
+			input:  "xn--bcher-kva.example",
+			expect: "bücher.example",
+		},
+		{
+			input:  "XN--wgv71a119e.jp",
+			expect: "日本語.jp",
+		},
+		{
+			input: "xn--bcher-!!!.example",
+			isErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		actual, err := IdnaToUnicode(tt.input)
+		if tt.isErr {
+			if err == nil {
+				t.Errorf("Expected error but got nil for %s", tt.input)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Unexpected error: %s", err)
+			continue
+		}
+		if diff := cmp.Diff(tt.expect, actual); diff != "" {
+			t.Errorf("IdnaToUnicode result mismatch for %s, diff=%s", tt.input, diff)
+		}
+	}
+}
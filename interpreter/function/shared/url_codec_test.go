@@ -59,6 +59,18 @@ func TestUrlEncode(t *testing.T) {
 			input:  "hello world",
 			expect: "hello%20world",
 		},
+		{
+			input:  "%zz",
+			expect: "%25zz",
+		},
+		{
+			input:  "100%",
+			expect: "100%25",
+		},
+		{
+			input:  string([]byte{0xFF, 0x61}),
+			expect: "%FFa",
+		},
 	}
 
 	for i, tt := range tests {
@@ -125,6 +137,18 @@ func TestUrlDecode(t *testing.T) {
 			input:  "hello%20world",
 			expect: "hello world",
 		},
+		{
+			input:  "%zz",
+			expect: "%zz",
+		},
+		{
+			input:  "100%",
+			expect: "100%",
+		},
+		{
+			input:  "%e3%81%82",
+			expect: "あ",
+		},
 	}
 
 	for i, tt := range tests {
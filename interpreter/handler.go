@@ -1,6 +1,7 @@
 package interpreter
 
 import (
+	"fmt"
 	"io"
 	ghttp "net/http"
 	"strings"
@@ -10,6 +11,7 @@ import (
 	"github.com/ysugimoto/falco/v2/interpreter/http"
 	"github.com/ysugimoto/falco/v2/interpreter/limitations"
 	"github.com/ysugimoto/falco/v2/interpreter/variable"
+	"github.com/ysugimoto/falco/v2/policy"
 )
 
 // Implements http.Handler
@@ -24,22 +26,23 @@ func (i *Interpreter) ServeHTTP(w ghttp.ResponseWriter, r *ghttp.Request) {
 	i.lock.Lock()
 	defer i.lock.Unlock()
 
-	if err := i.ProcessInit(http.WrapRequest(r)); err != nil {
+	if err := safeProcess(func() error { return i.ProcessInit(http.WrapRequest(r)) }); err != nil {
 		ghttp.Error(w, err.Error(), ghttp.StatusInternalServerError)
 		return
 	}
+	i.Debugger.Message(fmt.Sprintf("Request ID: %s", i.ctx.CorrelationID))
 
 	handleError := func(err error) {
 		// If debug is true, print with stacktrace
 		i.process.Error = err
-		if re, ok := errors.Cause(err).(*exception.Exception); ok {
-			i.Debugger.Message(re.Error())
+		if re, ok := errors.Cause(i.attachExcerpt(err)).(*exception.Exception); ok {
+			i.Debugger.Message(re.Pretty())
 		} else {
 			i.Debugger.Message(err.Error())
 		}
 	}
 
-	err := i.ProcessRecv()
+	err := safeProcess(i.ProcessRecv)
 	if err != nil {
 		handleError(err)
 	}
@@ -50,9 +53,72 @@ func (i *Interpreter) ServeHTTP(w ghttp.ResponseWriter, r *ghttp.Request) {
 		}
 	}
 
+	i.process.EndTime = i.ctx.Now().UnixMicro()
 	i.process.Restarts = i.ctx.Restarts
 	i.process.Backend = i.ctx.Backend
 
+	// Write execution trace file before accesses are dropped on finalizing explanation
+	if i.ctx.TraceDir != "" {
+		name := fmt.Sprintf("%d %s %s", i.process.StartTime, r.Method, r.URL.Path)
+		if path, err := i.WriteTrace(name); err != nil {
+			i.Debugger.Message(fmt.Sprintf("Failed to write trace file: %s", err))
+		} else {
+			i.Debugger.Message(fmt.Sprintf("Execution trace is recorded to %s", path))
+		}
+	}
+	i.finalizeExplanation()
+
+	// Output variable accesses as trace output if provenance tracking is enabled
+	if i.ctx.Provenance {
+		for _, a := range i.process.Accesses {
+			i.Debugger.Message(a.String())
+		}
+	}
+	if i.process.Explanation != nil {
+		i.Debugger.Message(i.process.Explanation.String())
+	}
+	if i.ctx.FlowDiagram != "" {
+		i.Debugger.Message(i.process.Diagram(i.ctx.FlowDiagram))
+	}
+
+	// Evaluate policies against the execution trace in the background if provided.
+	// Findings are reported separately after evaluation so that slow policies never block responses
+	if i.ctx.Policy != nil {
+		requestID := i.process.RequestID
+		debugger := i.Debugger
+		queued := i.ctx.Policy.EvaluateAsync(policy.KindTrace, i.process.TraceInput(), func(violations []*policy.Violation, err error) {
+			i.lock.Lock()
+			defer i.lock.Unlock()
+
+			if err != nil {
+				debugger.Message(fmt.Sprintf("Policy evaluation failed (request id: %s): %s", requestID, err))
+			}
+			for _, v := range violations {
+				debugger.Message(fmt.Sprintf("Policy violation (request id: %s): %s", requestID, v.Message))
+			}
+		})
+		if !queued {
+			i.Debugger.Message(fmt.Sprintf("Policy evaluation skipped (request id: %s): too many pending evaluations", requestID))
+		}
+	}
+	// Detect protected headers which are exposed to clients or logs across all code paths
+	for _, v := range i.checkProtectedHeaders(r) {
+		i.Debugger.Message(fmt.Sprintf("Protected header violation: %s", v.Message))
+		i.process.Violations = append(i.process.Violations, v)
+	}
+
+	// Verify latency budgets of the annotated routes which the request entered
+	i.process.BudgetViolations = i.process.CheckBudgets()
+	for _, v := range i.process.BudgetViolations {
+		i.Debugger.Message(fmt.Sprintf("Latency budget violation: %s", v))
+	}
+
+	// Notify processed result to the observer like the simulator web UI
+	if i.OnProcessed != nil {
+		i.process.Response = i.ctx.Response
+		i.OnProcessed(r, i.process)
+	}
+
 	switch {
 	case i.ctx.IsPurgeRequest:
 		// If the service received purge request, send accepted response
@@ -66,6 +132,13 @@ func (i *Interpreter) ServeHTTP(w ghttp.ResponseWriter, r *ghttp.Request) {
 	}
 }
 
+// Run the process with recovering panic as SystemException
+// so that unexpected failure on one request does not take down the simulator
+func safeProcess(fn func() error) (err error) {
+	defer exception.Recover(&err)
+	return fn()
+}
+
 func (i *Interpreter) sendProcessResponse(w ghttp.ResponseWriter) {
 	if i.process.Error != nil {
 		w.WriteHeader(ghttp.StatusInternalServerError)
@@ -19,6 +19,18 @@ func findProcessMark(comments ast.Comments) (string, bool) {
 	return "", false
 }
 
+// Find latency budget annotation like "# falco:budget ttfb=200ms" and returns its fields
+func findBudgetMark(comments ast.Comments) (string, bool) {
+	for i := range comments {
+		l := strings.TrimLeft(comments[i].Value, " */#")
+		if budget, found := strings.CutPrefix(l, "falco:budget"); found {
+			return strings.TrimSpace(budget), true
+		}
+	}
+
+	return "", false
+}
+
 type series struct {
 	Operator   string
 	Expression ast.Expression
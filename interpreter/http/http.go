@@ -27,6 +27,16 @@ func CreateCookie(key, value string) *http.Cookie {
 	return c
 }
 
+// ParseCookies parses cookies from a single Cookie header line.
+// http.Request.Cookies() reads all Cookie header fields but the edge only reads the first field,
+// so this function is used to follow that behavior.
+func ParseCookies(line string) []*http.Cookie {
+	h := http.Header{}
+	h.Set("Cookie", line)
+	rr := http.Request{Header: h}
+	return rr.Cookies()
+}
+
 // SendRequest sends HTTP request from Request
 func SendRequest(req *Request) (*Response, error) {
 	client := http.DefaultClient
@@ -0,0 +1,30 @@
+package http
+
+import (
+	"context"
+	"net/http"
+)
+
+// Header name of the request ID which correlates the client request, backend requests and traces end to end
+const RequestIDHeader = "X-Request-ID"
+
+type requestIDKey struct{}
+
+// WithRequestID returns the context which carries the request ID assigned before the request reaches the interpreter.
+// e.g. mirrored requests share the ID with the staging service without adding the header to the client request
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the request ID of the incoming request.
+// The ID which the client sends is honored, then the ID carried by the request context, otherwise the provided ID is used.
+// Note that the request is never modified so VCL only sees headers which the client actually sent
+func RequestID(r *http.Request, id string) string {
+	if v := r.Header.Get(RequestIDHeader); v != "" {
+		return v
+	}
+	if v, ok := r.Context().Value(requestIDKey{}).(string); ok && v != "" {
+		return v
+	}
+	return id
+}
@@ -50,6 +50,7 @@ func (i *Interpreter) includeSnippet(include *ast.IncludeStatement, isRoot bool)
 	if !ok {
 		return nil, fmt.Errorf("failed to include VCL snippets '%s'", include.Module.Value)
 	}
+	i.addSource(include.Module.Value, snip.Data, &include.GetMeta().Token)
 	if isRoot {
 		return loadRootVCL(include.Module.Value, snip.Data)
 	}
@@ -62,6 +63,7 @@ func (i *Interpreter) includeFile(include *ast.IncludeStatement, isRoot bool) ([
 		return nil, fmt.Errorf("failed to include VCL module '%s'", include.Module.Value)
 	}
 
+	i.addSource(module.Name, module.Data, &include.GetMeta().Token)
 	if isRoot {
 		return loadRootVCL(module.Name, module.Data)
 	}
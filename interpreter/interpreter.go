@@ -3,6 +3,7 @@ package interpreter
 import (
 	"fmt"
 	"io"
+	"maps"
 	ghttp "net/http"
 	"strings"
 	"sync"
@@ -14,6 +15,7 @@ import (
 	"github.com/ysugimoto/falco/v2/interpreter/cache"
 	"github.com/ysugimoto/falco/v2/interpreter/context"
 	"github.com/ysugimoto/falco/v2/interpreter/exception"
+	"github.com/ysugimoto/falco/v2/interpreter/function"
 	"github.com/ysugimoto/falco/v2/interpreter/http"
 	"github.com/ysugimoto/falco/v2/interpreter/limitations"
 	"github.com/ysugimoto/falco/v2/interpreter/process"
@@ -21,6 +23,7 @@ import (
 	"github.com/ysugimoto/falco/v2/interpreter/variable"
 	"github.com/ysugimoto/falco/v2/lexer"
 	"github.com/ysugimoto/falco/v2/parser"
+	"github.com/ysugimoto/falco/v2/token"
 )
 
 type Interpreter struct {
@@ -30,16 +33,39 @@ type Interpreter struct {
 
 	options []context.Option
 
-	ctx           *context.Context
-	process       *process.Process
-	cache         *cache.Cache
-	rateCounters  map[string]*value.Ratecounter
-	penaltyBoxes  map[string]*value.Penaltybox
-	callStack     []*ast.SubroutineDeclaration
-	Debugger      Debugger
+	ctx          *context.Context
+	process      *process.Process
+	cache        *cache.Cache
+	rateCounters map[string]*value.Ratecounter
+	penaltyBoxes map[string]*value.Penaltybox
+	callStack    []*ast.SubroutineDeclaration
+	Debugger     Debugger
+
+	// The last processed statement which changes state like return, error, and restart statement.
+	// This is used for explaining where the state is determined and reset on each scope
+	lastTransition ast.Statement
+
+	// Structural paths of nodes in the subroutine which is being instrumented for coverage
+	coveragePaths map[ast.Node]string
+
 	IdentResolver func(v string) value.Value
 
+	// Called with the incoming request and its process record after the request is processed on the simulator
+	OnProcessed func(r *ghttp.Request, p *process.Process)
+
 	TestingState State
+
+	// Testing fixtures which are restored on each test case instead of removing
+	baselineFixtures testFixtures
+
+	// Loaded VCL sources by file name to render excerpts of exceptions
+	sources map[string]*vclSource
+
+	// Functions which are injected only to this interpreter like testing functions, take precedence over builtin functions
+	functions map[string]*function.Function
+
+	// Lower-cased header names which are set or added somewhere, used on strict headers mode
+	knownHeaders map[string]struct{}
 }
 
 func New(options ...context.Option) *Interpreter {
@@ -52,12 +78,35 @@ func New(options ...context.Option) *Interpreter {
 		localVars:    variable.LocalVariables{},
 		Debugger:     DefaultDebugger{},
 		TestingState: NONE,
-		process:      process.New(),
+		process:      process.New(time.Now()),
+	}
+}
+
+// InjectFunctions injects functions which are available only in this interpreter.
+// Unlike function.Inject, interpreters which run concurrently could have their own functions
+func (i *Interpreter) InjectFunctions(fns map[string]*function.Function) {
+	if i.functions == nil {
+		i.functions = make(map[string]*function.Function)
+	}
+	maps.Copy(i.functions, fns)
+}
+
+// Find the function which is injected to this interpreter first, and then builtin function
+func (i *Interpreter) findFunction(name string) (*function.Function, error) {
+	if _, ok := i.functions[name]; ok {
+		return function.Find(i.functions, i.ctx.Scope, name)
 	}
+	return function.Exists(i.ctx.Scope, name)
+}
+
+// Process returns the process record of the last handled request
+func (i *Interpreter) Process() *process.Process {
+	return i.process
 }
 
 func (i *Interpreter) SetScope(scope context.Scope) {
 	i.ctx.Scope = scope
+	i.lastTransition = nil
 	switch scope {
 	case context.RecvScope:
 		i.vars = variable.NewRecvScopeVariables(i.ctx)
@@ -80,9 +129,26 @@ func (i *Interpreter) SetScope(scope context.Scope) {
 	}
 }
 
+// Move state machine to the next state and record the transition with the statement location which triggered it
+func (i *Interpreter) moveState(to string) {
+	i.Debugger.Message(fmt.Sprintf("Move state: %s -> %s", i.ctx.Scope, to))
+	i.process.Transitions = append(i.process.Transitions, i.newTransition(to))
+}
+
+func (i *Interpreter) newTransition(to string) *process.Transition {
+	var tok *token.Token
+	if i.lastTransition != nil {
+		tok = &i.lastTransition.GetMeta().Token
+	}
+	return process.NewTransition(i.ctx.Scope.String(), to, tok)
+}
+
 func (i *Interpreter) restart() error {
 	i.ctx.Restarts++
 	i.Debugger.Message(fmt.Sprintf("Restarted (%d) time", i.ctx.Restarts))
+	t := i.newTransition(context.RecvScope.String())
+	t.Restart = true
+	i.process.Transitions = append(i.process.Transitions, t)
 	i.ctx.BackendRequest = nil
 	i.ctx.BackendResponse = nil
 	i.ctx.Object = nil
@@ -95,6 +161,12 @@ func (i *Interpreter) restart() error {
 }
 
 func (i *Interpreter) ProcessInit(r *http.Request) error {
+	return i.processInit(r, false)
+}
+
+// Initialize context and declarations from main VCL.
+// The VCL is instrumented for coverage measurement on testing process, or on all process unless opted out
+func (i *Interpreter) processInit(r *http.Request, isTesting bool) error {
 	ctx := context.New(i.options...)
 
 	main, err := ctx.Resolver.MainVCL()
@@ -114,6 +186,7 @@ func (i *Interpreter) ProcessInit(r *http.Request) error {
 		i.Debugger.Message(err.Error())
 		return errors.WithStack(err)
 	}
+	i.addSource(main.Name, main.Data, nil)
 
 	// If remote snippets exists, prepare parse and prepend to main VCL
 	if ctx.FastlySnippets != nil {
@@ -128,12 +201,14 @@ func (i *Interpreter) ProcessInit(r *http.Request) error {
 				i.Debugger.Message(err.Error())
 				return errors.WithStack(err)
 			}
+			i.addSource(snip.Name, snip.Data, nil)
 			vcl.Statements = append(s.Statements, vcl.Statements...)
 		}
 	}
-	ctx.RequestStartTime = time.Now()
+	ctx.RequestStartTime = ctx.Now()
 	i.ctx = ctx
 	i.ctx.Request = r
+	i.ctx.CorrelationID = http.RequestID(r.Request, i.ctx.RequestID.Value)
 	r.Header.Set("Host", r.Host)
 	i.chargeInboundRequestWorkspace()
 
@@ -142,7 +217,11 @@ func (i *Interpreter) ProcessInit(r *http.Request) error {
 		i.ctx.OriginalHost = r.Host
 	}
 
-	i.process = process.New()
+	i.process = process.New(i.ctx.Now())
+	i.process.RequestID = i.ctx.CorrelationID
+	if i.ctx.Explain {
+		i.process.Explanation = process.NewExplanation()
+	}
 	i.ctx.Scope = context.InitScope
 	i.vars = variable.NewAllScopeVariables(i.ctx)
 
@@ -160,7 +239,7 @@ func (i *Interpreter) ProcessInit(r *http.Request) error {
 		return errors.WithStack(err)
 	}
 	// instrumenting if coverage measurement is enabled
-	if i.ctx.Coverage != nil {
+	if i.ctx.Coverage != nil && (isTesting || !i.ctx.InstrumentTestOnly) {
 		i.instrument(vcl)
 	}
 	if err := i.ProcessDeclarations(vcl.Statements); err != nil {
@@ -215,6 +294,7 @@ func (i *Interpreter) ProcessDeclarations(statements []ast.Statement) error {
 
 		case *ast.SubroutineDeclaration:
 			i.Debugger.Run(stmt)
+			i.CollectHeaders(t.Block.Statements)
 			if t.ReturnType != nil {
 				if _, ok := i.ctx.SubroutineFunctions[t.Name.Value]; ok {
 					return exception.Runtime(&t.Token, "Subroutine %s is duplicated", t.Name.Value)
@@ -259,6 +339,12 @@ func (i *Interpreter) ProcessDeclarations(statements []ast.Statement) error {
 				i.rateCounters[t.Name.Value] = rc
 			}
 			i.ctx.Ratecounters[t.Name.Value] = rc
+		case *ast.ConstDeclaration:
+			i.Debugger.Run(stmt)
+			if _, ok := i.ctx.Constants[t.Name.Value]; ok {
+				return exception.Runtime(&t.Token, "Const %s is duplicated", t.Name.Value)
+			}
+			i.ctx.Constants[t.Name.Value] = t
 		}
 	}
 
@@ -277,7 +363,13 @@ func (i *Interpreter) ProcessDeclarations(statements []ast.Statement) error {
 			i.ctx.Tables[name] = d
 		}
 	}
-	return nil
+
+	// Apply external resource files which may be reloaded while simulator is running
+	if err := i.applyResources(); err != nil {
+		return errors.WithStack(err)
+	}
+	// Apply edge dictionaries in KV storage which may be mutated by other services
+	return i.applyDictionaryStorage()
 }
 
 func (i *Interpreter) ProcessBackends(statements []ast.Statement) error {
@@ -340,33 +432,38 @@ func (i *Interpreter) ProcessRecv() error {
 
 	switch state {
 	case PASS:
+		i.explainResult(process.ResultPass)
+		i.explainRequest()
+		i.explainState(context.FastlyVclNameRecv, state)
 		i.ctx.State = "MISS"
-		i.Debugger.Message(fmt.Sprintf("Move state: %s -> HASH", i.ctx.Scope))
+		i.moveState("HASH")
 		if err = i.ProcessHash(); err != nil {
 			return errors.WithStack(err)
 		}
-		i.Debugger.Message(fmt.Sprintf("Move state: %s -> PASS", i.ctx.Scope))
+		i.moveState("PASS")
 		err = i.ProcessPass()
 	case ERROR:
-		i.Debugger.Message(fmt.Sprintf("Move state: %s -> ERROR", i.ctx.Scope))
+		i.explainResult(process.ResultError)
+		i.explainState(context.FastlyVclNameRecv, state)
+		i.moveState("ERROR")
 		err = i.ProcessError()
 	case RESTART:
 		err = i.restart()
 	case LOOKUP, NONE:
-		i.Debugger.Message(fmt.Sprintf("Move state: %s -> HASH", i.ctx.Scope))
+		i.moveState("HASH")
 		if err = i.ProcessHash(); err != nil {
 			return errors.WithStack(err)
 		}
-		if v := i.cache.Get(i.ctx.RequestHash.Value); v != nil {
+		if v := i.lookupCache(); v != nil {
 			i.process.Cached = true
 			i.ctx.State = "HIT"
 			i.ctx.CacheHitItem = v
 			i.ctx.Object = v.Response.Clone()
-			i.Debugger.Message(fmt.Sprintf("Move state: %s -> HIT", i.ctx.Scope))
+			i.moveState("HIT")
 			err = i.ProcessHit()
 		} else {
 			i.ctx.State = "MISS"
-			i.Debugger.Message(fmt.Sprintf("Move state: %s -> MISS", i.ctx.Scope))
+			i.moveState("MISS")
 			err = i.ProcessMiss()
 		}
 	default:
@@ -393,6 +490,7 @@ func (i *Interpreter) ProcessHash() error {
 	i.ctx.RequestHash = &value.String{
 		Value: i.ctx.Request.URL.String(),
 	}
+	i.ctx.RequestHashInputs = nil
 
 	// Simulate Fastly statement lifecycle
 	// see: https://developer.fastly.com/learning/vcl/using/#the-vcl-request-lifecycle
@@ -444,16 +542,20 @@ func (i *Interpreter) ProcessMiss() error {
 
 	switch state {
 	case DELIVER_STALE:
-		i.Debugger.Message(fmt.Sprintf("Move state: %s -> DELIVER", i.ctx.Scope))
+		i.moveState("DELIVER")
 		err = i.ProcessDeliver()
 	case PASS:
-		i.Debugger.Message(fmt.Sprintf("Move state: %s -> PASS", i.ctx.Scope))
+		i.explainResult(process.ResultPass)
+		i.explainState(context.FastlyVclNameMiss, state)
+		i.moveState("PASS")
 		err = i.ProcessPass()
 	case ERROR:
-		i.Debugger.Message(fmt.Sprintf("Move state: %s -> ERROR", i.ctx.Scope))
+		i.explainResult(process.ResultError)
+		i.explainState(context.FastlyVclNameMiss, state)
+		i.moveState("ERROR")
 		err = i.ProcessError()
 	case FETCH:
-		i.Debugger.Message(fmt.Sprintf("Move state: %s -> FETCH", i.ctx.Scope))
+		i.moveState("FETCH")
 		err = i.ProcessFetch()
 	default:
 		return exception.Runtime(
@@ -494,13 +596,17 @@ func (i *Interpreter) ProcessHit() error {
 
 	switch state {
 	case DELIVER:
-		i.Debugger.Message(fmt.Sprintf("Move state: %s -> DELIVER", i.ctx.Scope))
+		i.moveState("DELIVER")
 		err = i.ProcessDeliver()
 	case PASS:
-		i.Debugger.Message(fmt.Sprintf("Move state: %s -> PASS", i.ctx.Scope))
+		i.explainResult(process.ResultPass)
+		i.explainState(context.FastlyVclNameHit, state)
+		i.moveState("PASS")
 		err = i.ProcessPass()
 	case ERROR:
-		i.Debugger.Message(fmt.Sprintf("Move state: %s -> ERROR", i.ctx.Scope))
+		i.explainResult(process.ResultError)
+		i.explainState(context.FastlyVclNameHit, state)
+		i.moveState("ERROR")
 		err = i.ProcessError()
 	case RESTART:
 		err = i.restart()
@@ -552,10 +658,10 @@ func (i *Interpreter) ProcessPass() error {
 
 	switch state {
 	case PASS:
-		i.Debugger.Message(fmt.Sprintf("Move state: %s -> FETCH", i.ctx.Scope))
+		i.moveState("FETCH")
 		err = i.ProcessFetch()
 	case ERROR:
-		i.Debugger.Message(fmt.Sprintf("Move state: %s -> ERROR", i.ctx.Scope))
+		i.moveState("ERROR")
 		err = i.ProcessError()
 	default:
 		return exception.Runtime(
@@ -579,15 +685,29 @@ func (i *Interpreter) ProcessFetch() error {
 		return exception.System("No backend determined on FETCH")
 	}
 
+	// Evaluation ends with the backend request to be sent in dry-run mode
+	if i.ctx.DryRun {
+		i.Debugger.Message("Backend request is not sent in dry-run mode")
+		return nil
+	}
+
+	// Testing hooks could modify bereq before sending and beresp before processing vcl_fetch
+	if err := i.ProcessFetchHook(i.ctx.BeforeFetchHook); err != nil {
+		return errors.WithStack(err)
+	}
+
 	// Send request to backend
 	var err error
 	i.ctx.BackendResponse, err = i.sendBackendRequest(i.ctx.Backend)
 	if err != nil {
 		return errors.WithStack(err)
 	}
+	if err := i.ProcessFetchHook(i.ctx.AfterFetchHook); err != nil {
+		return errors.WithStack(err)
+	}
 
 	// Mark request process has ended
-	i.ctx.RequestEndTime = time.Now()
+	i.ctx.RequestEndTime = i.ctx.Now()
 
 	// Set cacheable strategy
 	isCacheable := cache.IsCacheableStatusCode(i.ctx.BackendResponse.StatusCode)
@@ -613,13 +733,14 @@ func (i *Interpreter) ProcessFetch() error {
 		}
 	}
 
+	i.explainFetch(state)
 	i.updateCache()
 	switch state {
 	case DELIVER, DELIVER_STALE, PASS, HIT_FOR_PASS:
-		i.Debugger.Message(fmt.Sprintf("Move state: %s -> DELIVER", i.ctx.Scope))
+		i.moveState("DELIVER")
 		err = i.ProcessDeliver()
 	case ERROR:
-		i.Debugger.Message(fmt.Sprintf("Move state: %s -> ERROR", i.ctx.Scope))
+		i.moveState("ERROR")
 		err = i.ProcessError()
 	case RESTART:
 		err = i.restart()
@@ -675,7 +796,7 @@ func (i *Interpreter) ProcessError() error {
 
 	switch state {
 	case DELIVER:
-		i.Debugger.Message(fmt.Sprintf("Move state: %s -> DELIVER", i.ctx.Scope))
+		i.moveState("DELIVER")
 		err = i.ProcessDeliver()
 	case RESTART:
 		err = i.restart()
@@ -706,7 +827,7 @@ func (i *Interpreter) ProcessDeliver() error {
 	// Note that these headers could be removed in vcl_deliver subroutine
 	i.ctx.Response.Header.Set("X-Served-By", cache.LocalDatacenterString)
 	i.ctx.Response.Header.Set("X-Cache", i.ctx.State)
-	i.ctx.Response.Header.Set("Date", time.Now().UTC().Format(http.TimeFormat))
+	i.ctx.Response.Header.Set("Date", i.ctx.Now().UTC().Format(http.TimeFormat))
 	i.ctx.Response.Header.Set("Server", "Falco")
 	i.ctx.Response.Header.Set("Via", "Falco")
 
@@ -760,7 +881,7 @@ func (i *Interpreter) ProcessDeliver() error {
 			)
 		}
 
-		i.Debugger.Message(fmt.Sprintf("Move state: %s -> LOG", i.ctx.Scope))
+		i.moveState("LOG")
 		err = i.ProcessLog()
 	default:
 		return exception.Runtime(&sub.GetMeta().Token,
@@ -835,12 +956,57 @@ func (i *Interpreter) updateCache() {
 	// because this value will be changed by user in vcl_fetch directive
 	if i.ctx.BackendResponseCacheable.Value {
 		if i.ctx.BackendResponseTTL.Value.Seconds() > 0 {
-			now := time.Now()
+			now := i.ctx.Now()
 			i.cache.Set(i.ctx.RequestHash.String(), &cache.CacheItem{
-				Response:  resp,
-				Expires:   now.Add(i.ctx.BackendResponseTTL.Value),
-				EntryTime: now,
+				Response:      resp,
+				Expires:       now.Add(i.ctx.BackendResponseTTL.Value),
+				EntryTime:     now,
+				RequestHeader: i.ctx.Request.Header.Clone(),
 			})
 		}
 	}
 }
+
+// Lookup cache object for the request hash.
+// Fastly always misses the cache when req.hash_always_miss is true, and object is not served when Vary header mismatched
+func (i *Interpreter) lookupCache() *cache.CacheItem {
+	if i.ctx.HashAlwaysMiss.Value {
+		i.explain(i.lastWriteToken("req.hash_always_miss"), "req.hash_always_miss is set to true")
+		return nil
+	}
+
+	// Cache is not looked up in dry-run mode because lookup updates hit count of the cache object
+	if i.ctx.DryRun {
+		return nil
+	}
+
+	item, vary := i.cache.Lookup(i.ctx.RequestHash.Value, i.ctx.Request.Header, i.ctx.Now())
+	switch {
+	case item != nil:
+		i.explainResult(process.ResultHit)
+		i.explain(nil, "Cached object found for hash %s (hits: %d)", i.ctx.RequestHash.Value, item.Hits)
+	case vary != "":
+		i.explain(nil, "Cached object found but Vary mismatch on header %s", vary)
+	default:
+		i.explain(nil, "Cached object not found for hash %s", i.ctx.RequestHash.Value)
+	}
+	return item
+}
+
+func (i *Interpreter) explainFetch(state State) {
+	if i.process.Explanation == nil {
+		return
+	}
+	switch state {
+	case PASS, HIT_FOR_PASS:
+		// PASS in vcl_fetch creates hit-for-pass object only when the request is not passed yet
+		if i.process.Explanation.Result == process.ResultMiss {
+			i.explainResult(process.ResultHitForPass)
+		}
+		i.explainState(context.FastlyVclNameFetch, state)
+	case ERROR:
+		i.explainResult(process.ResultError)
+		i.explainState(context.FastlyVclNameFetch, state)
+	}
+	i.explainBackendResponse()
+}
@@ -1,17 +1,24 @@
 package interpreter
 
 import (
+	"bytes"
 	"fmt"
 	"io"
+	"log/slog"
+	"strings"
 	"testing"
 
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/ysugimoto/falco/v2/ast"
+	"github.com/ysugimoto/falco/v2/config"
 	"github.com/ysugimoto/falco/v2/interpreter/context"
+	"github.com/ysugimoto/falco/v2/interpreter/function"
+	ihttp "github.com/ysugimoto/falco/v2/interpreter/http"
 	"github.com/ysugimoto/falco/v2/interpreter/value"
 	"github.com/ysugimoto/falco/v2/resolver"
 	"github.com/ysugimoto/falco/v2/token"
@@ -147,6 +154,32 @@ func TestProcessDeclarations(t *testing.T) {
 	}
 }
 
+func TestConstDeclaration(t *testing.T) {
+	t.Run("constants are resolved as literals", func(t *testing.T) {
+		vcl := `
+const PREFIX = "foo";
+const MAX_AGE = 3600;
+sub vcl_recv {
+	set req.http.A = PREFIX "bar";
+	set req.http.B = MAX_AGE;
+}`
+		assertInterpreter(t, vcl, context.RecvScope, map[string]value.Value{
+			"req.http.A": &value.String{Value: "foobar"},
+			"req.http.B": &value.String{Value: "3600"},
+		}, false)
+	})
+
+	t.Run("duplicated constant is error", func(t *testing.T) {
+		vcl := `
+const MAX_AGE = 3600;
+const MAX_AGE = 60;
+sub vcl_recv {
+	set req.http.B = MAX_AGE;
+}`
+		assertInterpreter(t, vcl, context.RecvScope, map[string]value.Value{}, true)
+	})
+}
+
 func TestProcessBackends(t *testing.T) {
 	t.Run("Multiple backends", func(t *testing.T) {
 		ip := New()
@@ -354,3 +387,190 @@ func TestCustomStatusTextPreserved(t *testing.T) {
 		})
 	}
 }
+
+func TestLatencyBudget(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	parsed, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("Test server URL parsing error: %s", err)
+	}
+	vcl := defaultBackend(parsed) + `
+sub vcl_recv {
+  if (req.url ~ "^/api") {
+    # falco:budget ttfb=200ms name=api
+    set req.backend = example;
+  } else {
+    # falco:budget ttfb=1s
+    set req.backend = example;
+  }
+  return (pass);
+}
+`
+
+	for path, violated := range map[string]bool{"/api": true, "/static": false} {
+		ip := New(
+			context.WithResolver(resolver.NewStaticResolver("main", vcl)),
+			context.WithOverrideBackends(map[string]*config.OverrideBackend{
+				"example": {Host: parsed.Hostname(), Latency: "250ms"},
+			}),
+		)
+		ip.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://localhost"+path, nil))
+
+		p := ip.Process()
+		if p.TTFB != 250*time.Millisecond {
+			t.Errorf("%s: simulated TTFB should be configured latency, got %s", path, p.TTFB)
+		}
+		if violated != (len(p.BudgetViolations) == 1) {
+			t.Errorf("%s: unexpected budget violations %v", path, p.BudgetViolations)
+		}
+		if violated && p.BudgetViolations[0].Route != "api" {
+			t.Errorf("%s: unexpected violated route %s", path, p.BudgetViolations[0].Route)
+		}
+	}
+}
+
+func TestInjectFunctions(t *testing.T) {
+	fn := &function.Function{
+		Scope: context.RecvScope,
+		Call: func(ctx *context.Context, args ...value.Value) (value.Value, error) {
+			return value.Null, nil
+		},
+		CanStatementCall: true,
+		IsIdentArgument: func(i int) bool {
+			return false
+		},
+	}
+	ip := New()
+	ip.ctx = context.New()
+	ip.ctx.Scope = context.RecvScope
+	ip.InjectFunctions(map[string]*function.Function{"injected.function": fn})
+
+	if actual, err := ip.findFunction("injected.function"); err != nil || actual != fn {
+		t.Errorf("Injected function should be found, err=%v", err)
+	}
+	ip.ctx.Scope = context.FetchScope
+	if _, err := ip.findFunction("injected.function"); err == nil {
+		t.Errorf("Injected function should not be called in the other scope")
+	}
+
+	// Functions are not shared with the other interpreter
+	other := New()
+	other.ctx = context.New()
+	other.ctx.Scope = context.RecvScope
+	if _, err := other.findFunction("injected.function"); err == nil {
+		t.Errorf("Injected function should not be found in the other interpreter")
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	received := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received <- r.Header.Get("X-Request-ID")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	parsed, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("Test server URL parsing error: %s", err)
+	}
+	vcl := defaultBackend(parsed) + `
+sub vcl_recv {
+  set req.http.X-Seen = if(req.http.X-Request-ID, req.http.X-Request-ID, "none");
+  unset req.http.X-Request-ID;
+  return (pass);
+}
+`
+
+	tests := []struct {
+		name      string
+		header    string
+		contextID string
+		expectID  func(ip *Interpreter) string
+		expectVCL string
+	}{
+		{
+			name: "request ID is not added to the client request",
+			expectID: func(ip *Interpreter) string {
+				return ip.ctx.RequestID.Value
+			},
+			expectVCL: "none",
+		},
+		{
+			name:   "request ID of the client request is honored",
+			header: "client-id",
+			expectID: func(ip *Interpreter) string {
+				return "client-id"
+			},
+			expectVCL: "client-id",
+		},
+		{
+			name:      "request ID in the request context is used without adding the header",
+			contextID: "mirrored-id",
+			expectID: func(ip *Interpreter) string {
+				return "mirrored-id"
+			},
+			expectVCL: "none",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ip := New(context.WithResolver(resolver.NewStaticResolver("main", vcl)))
+			req := httptest.NewRequest(http.MethodGet, "http://localhost", nil)
+			if tt.header != "" {
+				req.Header.Set("X-Request-ID", tt.header)
+			}
+			if tt.contextID != "" {
+				req = req.WithContext(ihttp.WithRequestID(req.Context(), tt.contextID))
+			}
+			ip.ServeHTTP(httptest.NewRecorder(), req)
+
+			id := tt.expectID(ip)
+			if ip.ctx.CorrelationID != id || ip.Process().RequestID != id {
+				t.Errorf("Expected request ID %s, got ctx=%s process=%s", id, ip.ctx.CorrelationID, ip.Process().RequestID)
+			}
+			if v := ip.ctx.Request.Header.Get("X-Seen"); v != tt.expectVCL {
+				t.Errorf("Expected VCL sees X-Request-ID %s, got %s", tt.expectVCL, v)
+			}
+			// VCL removed the header so the backend request must not have it
+			if v := <-received; v != "" {
+				t.Errorf("Expected backend request has no X-Request-ID, got %s", v)
+			}
+			if v := ip.ctx.BackendRequest.Header.Get("X-Request-ID"); v != "" {
+				t.Errorf("Expected bereq has no X-Request-ID, got %s", v)
+			}
+		})
+	}
+}
+
+func TestDefaultDebuggerOutput(t *testing.T) {
+	var logs bytes.Buffer
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug})))
+
+	vcl := `
+sub vcl_recv {
+  log "syslog example :: hello";
+  error 600;
+}
+`
+	var out bytes.Buffer
+	ip := New(context.WithResolver(resolver.NewStaticResolver("main", vcl)))
+	ip.Debugger = DefaultDebugger{Writer: &out}
+	ip.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://localhost", nil))
+
+	if !strings.Contains(out.String(), "syslog example :: hello\n") {
+		t.Errorf("VCL log should be written to the debugger writer, got %q", out.String())
+	}
+	if !strings.Contains(out.String(), "Request Incoming") {
+		t.Errorf("Debug message should be written to the debugger writer, got %q", out.String())
+	}
+	if logs.Len() > 0 {
+		t.Errorf("VCL output should not be sent to the structured logger, got %q", logs.String())
+	}
+}
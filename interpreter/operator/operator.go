@@ -1,6 +1,7 @@
 package operator
 
 import (
+	"bytes"
 	"fmt"
 	"net"
 	"time"
@@ -28,9 +29,9 @@ func Equal(left, right value.Value) (value.Value, error) {
 		lv := value.Unwrap[*value.Integer](left)
 		rv := value.Unwrap[*value.Integer](right)
 		if lv.IsNAN || rv.IsNAN {
-			return &value.Boolean{Value: false}, nil
+			return value.False, nil
 		}
-		return &value.Boolean{Value: lv.Value == rv.Value}, nil
+		return value.NewBoolean(lv.Value == rv.Value), nil
 	case value.FloatType:
 		if right.Type() != value.FloatType {
 			return value.Null, errors.WithStack(
@@ -40,9 +41,9 @@ func Equal(left, right value.Value) (value.Value, error) {
 		lv := value.Unwrap[*value.Float](left)
 		rv := value.Unwrap[*value.Float](right)
 		if lv.IsNAN || rv.IsNAN {
-			return &value.Boolean{Value: false}, nil
+			return value.False, nil
 		}
-		return &value.Boolean{Value: lv.Value == rv.Value}, nil
+		return value.NewBoolean(lv.Value == rv.Value), nil
 	case value.StringType:
 		if right.Type() != value.StringType {
 			return value.Null, errors.WithStack(
@@ -53,17 +54,15 @@ func Equal(left, right value.Value) (value.Value, error) {
 		rv := value.Unwrap[*value.String](right)
 		// IsNotSet string does not match all equal expression
 		if lv.IsNotSet || rv.IsNotSet {
-			return &value.Boolean{Value: false}, nil
+			return value.False, nil
 		}
-		return &value.Boolean{Value: lv.Value == rv.Value}, nil
+		return value.NewBoolean(lv.Value == rv.Value), nil
 	case value.TimeType:
 		lv := value.Unwrap[*value.Time](left)
 		switch right.Type() {
 		case value.TimeType:
 			rv := value.Unwrap[*value.Time](right)
-			return &value.Boolean{
-				Value: lv.Value.Compare(rv.Value) == 0,
-			}, nil
+			return value.NewBoolean(lv.Value.Compare(rv.Value) == 0), nil
 		default:
 			return value.Null, errors.WithStack(
 				fmt.Errorf("invalid type comparison %s and %s", left.Type(), right.Type()),
@@ -76,9 +75,7 @@ func Equal(left, right value.Value) (value.Value, error) {
 		)
 	}
 
-	return &value.Boolean{
-		Value: left.String() == right.String(),
-	}, nil
+	return value.NewBoolean(left.String() == right.String()), nil
 }
 
 func NotEqual(left, right value.Value) (value.Value, error) {
@@ -86,9 +83,7 @@ func NotEqual(left, right value.Value) (value.Value, error) {
 	if err != nil {
 		return b, err
 	}
-	return &value.Boolean{
-		Value: !value.Unwrap[*value.Boolean](b).Value,
-	}, nil
+	return value.NewBoolean(!value.Unwrap[*value.Boolean](b).Value), nil
 }
 
 func GreaterThan(left, right value.Value) (value.Value, error) {
@@ -101,18 +96,16 @@ func GreaterThan(left, right value.Value) (value.Value, error) {
 		}
 		lv := value.Unwrap[*value.Integer](left)
 		if lv.IsNAN {
-			return &value.Boolean{Value: false}, nil
+			return value.False, nil
 		}
 		switch right.Type() {
 		case value.IntegerType:
 			rv := value.Unwrap[*value.Integer](right)
 			if rv.IsNAN {
-				return &value.Boolean{Value: false}, nil
+				return value.False, nil
 			}
 
-			return &value.Boolean{
-				Value: lv.Value > rv.Value,
-			}, nil
+			return value.NewBoolean(lv.Value > rv.Value), nil
 		case value.RTimeType:
 			if right.IsLiteral() {
 				return value.Null, errors.WithStack(
@@ -121,9 +114,7 @@ func GreaterThan(left, right value.Value) (value.Value, error) {
 			}
 			rv := value.Unwrap[*value.RTime](right)
 
-			return &value.Boolean{
-				Value: lv.Value > int64(rv.Value/time.Second),
-			}, nil
+			return value.NewBoolean(lv.Value > int64(rv.Value/time.Second)), nil
 		default:
 			return value.Null, errors.WithStack(
 				fmt.Errorf("invalid type comparison %s and %s", left.Type(), right.Type()),
@@ -137,27 +128,23 @@ func GreaterThan(left, right value.Value) (value.Value, error) {
 		}
 		lv := value.Unwrap[*value.Float](left)
 		if lv.IsNAN {
-			return &value.Boolean{Value: false}, nil
+			return value.False, nil
 		}
 		switch right.Type() {
 		case value.IntegerType:
 			rv := value.Unwrap[*value.Integer](right)
 			if rv.IsNAN {
-				return &value.Boolean{Value: false}, nil
+				return value.False, nil
 			}
 
-			return &value.Boolean{
-				Value: lv.Value > float64(rv.Value),
-			}, nil
+			return value.NewBoolean(lv.Value > float64(rv.Value)), nil
 		case value.FloatType:
 			rv := value.Unwrap[*value.Float](right)
 			if rv.IsNAN {
-				return &value.Boolean{Value: false}, nil
+				return value.False, nil
 			}
 
-			return &value.Boolean{
-				Value: lv.Value > rv.Value,
-			}, nil
+			return value.NewBoolean(lv.Value > rv.Value), nil
 		case value.RTimeType:
 			if right.IsLiteral() {
 				return value.Null, errors.WithStack(
@@ -166,9 +153,7 @@ func GreaterThan(left, right value.Value) (value.Value, error) {
 			}
 			rv := value.Unwrap[*value.RTime](right)
 
-			return &value.Boolean{
-				Value: lv.Value > float64(rv.Value/time.Second),
-			}, nil
+			return value.NewBoolean(lv.Value > float64(rv.Value/time.Second)), nil
 		default:
 			return value.Null, errors.WithStack(
 				fmt.Errorf("invalid type comparison %s and %s", left.Type(), right.Type()),
@@ -190,12 +175,10 @@ func GreaterThan(left, right value.Value) (value.Value, error) {
 			}
 			rv := value.Unwrap[*value.Integer](right)
 			if rv.IsNAN {
-				return &value.Boolean{Value: false}, nil
+				return value.False, nil
 			}
 
-			return &value.Boolean{
-				Value: int64(lv.Value/time.Second) > rv.Value,
-			}, nil
+			return value.NewBoolean(int64(lv.Value/time.Second) > rv.Value), nil
 		case value.FloatType:
 			if right.IsLiteral() {
 				return value.Null, errors.WithStack(
@@ -204,18 +187,14 @@ func GreaterThan(left, right value.Value) (value.Value, error) {
 			}
 			rv := value.Unwrap[*value.Float](right)
 			if rv.IsNAN {
-				return &value.Boolean{Value: false}, nil
+				return value.False, nil
 			}
 
-			return &value.Boolean{
-				Value: float64(lv.Value/time.Second) > rv.Value,
-			}, nil
+			return value.NewBoolean(float64(lv.Value/time.Second) > rv.Value), nil
 		case value.RTimeType:
 			rv := value.Unwrap[*value.RTime](right)
 
-			return &value.Boolean{
-				Value: lv.Value > rv.Value,
-			}, nil
+			return value.NewBoolean(lv.Value > rv.Value), nil
 		default:
 			return value.Null, errors.WithStack(
 				fmt.Errorf("invalid type comparison %s and %s", left.Type(), right.Type()),
@@ -226,9 +205,7 @@ func GreaterThan(left, right value.Value) (value.Value, error) {
 		switch right.Type() {
 		case value.TimeType:
 			rv := value.Unwrap[*value.Time](right)
-			return &value.Boolean{
-				Value: lv.Value.Compare(rv.Value) > 0,
-			}, nil
+			return value.NewBoolean(lv.Value.Compare(rv.Value) > 0), nil
 		default:
 			return value.Null, errors.WithStack(
 				fmt.Errorf("invalid type comparison %s and %s", left.Type(), right.Type()),
@@ -251,18 +228,16 @@ func LessThan(left, right value.Value) (value.Value, error) {
 		}
 		lv := value.Unwrap[*value.Integer](left)
 		if lv.IsNAN {
-			return &value.Boolean{Value: false}, nil
+			return value.False, nil
 		}
 		switch right.Type() {
 		case value.IntegerType:
 			rv := value.Unwrap[*value.Integer](right)
 			if rv.IsNAN {
-				return &value.Boolean{Value: false}, nil
+				return value.False, nil
 			}
 
-			return &value.Boolean{
-				Value: lv.Value < rv.Value,
-			}, nil
+			return value.NewBoolean(lv.Value < rv.Value), nil
 		case value.RTimeType:
 			if right.IsLiteral() {
 				return value.Null, errors.WithStack(
@@ -271,9 +246,7 @@ func LessThan(left, right value.Value) (value.Value, error) {
 			}
 			rv := value.Unwrap[*value.RTime](right)
 
-			return &value.Boolean{
-				Value: lv.Value < int64(rv.Value/time.Second),
-			}, nil
+			return value.NewBoolean(lv.Value < int64(rv.Value/time.Second)), nil
 		default:
 			return value.Null, errors.WithStack(
 				fmt.Errorf("invalid type comparison %s and %s", left.Type(), right.Type()),
@@ -287,27 +260,23 @@ func LessThan(left, right value.Value) (value.Value, error) {
 		}
 		lv := value.Unwrap[*value.Float](left)
 		if lv.IsNAN {
-			return &value.Boolean{Value: false}, nil
+			return value.False, nil
 		}
 		switch right.Type() {
 		case value.IntegerType:
 			rv := value.Unwrap[*value.Integer](right)
 			if rv.IsNAN {
-				return &value.Boolean{Value: false}, nil
+				return value.False, nil
 			}
 
-			return &value.Boolean{
-				Value: lv.Value < float64(rv.Value),
-			}, nil
+			return value.NewBoolean(lv.Value < float64(rv.Value)), nil
 		case value.FloatType:
 			rv := value.Unwrap[*value.Float](right)
 			if rv.IsNAN {
-				return &value.Boolean{Value: false}, nil
+				return value.False, nil
 			}
 
-			return &value.Boolean{
-				Value: lv.Value < rv.Value,
-			}, nil
+			return value.NewBoolean(lv.Value < rv.Value), nil
 		case value.RTimeType:
 			if right.IsLiteral() {
 				return value.Null, errors.WithStack(
@@ -316,9 +285,7 @@ func LessThan(left, right value.Value) (value.Value, error) {
 			}
 			rv := value.Unwrap[*value.RTime](right)
 
-			return &value.Boolean{
-				Value: lv.Value < float64(rv.Value/time.Second),
-			}, nil
+			return value.NewBoolean(lv.Value < float64(rv.Value/time.Second)), nil
 		default:
 			return value.Null, errors.WithStack(
 				fmt.Errorf("invalid type comparison %s and %s", left.Type(), right.Type()),
@@ -340,12 +307,10 @@ func LessThan(left, right value.Value) (value.Value, error) {
 			}
 			rv := value.Unwrap[*value.Integer](right)
 			if rv.IsNAN {
-				return &value.Boolean{Value: false}, nil
+				return value.False, nil
 			}
 
-			return &value.Boolean{
-				Value: int64(lv.Value/time.Second) < rv.Value,
-			}, nil
+			return value.NewBoolean(int64(lv.Value/time.Second) < rv.Value), nil
 		case value.FloatType:
 			if right.IsLiteral() {
 				return value.Null, errors.WithStack(
@@ -354,15 +319,11 @@ func LessThan(left, right value.Value) (value.Value, error) {
 			}
 			rv := value.Unwrap[*value.Float](right)
 
-			return &value.Boolean{
-				Value: float64(lv.Value/time.Second) < rv.Value,
-			}, nil
+			return value.NewBoolean(float64(lv.Value/time.Second) < rv.Value), nil
 		case value.RTimeType:
 			rv := value.Unwrap[*value.RTime](right)
 
-			return &value.Boolean{
-				Value: lv.Value < rv.Value,
-			}, nil
+			return value.NewBoolean(lv.Value < rv.Value), nil
 		default:
 			return value.Null, errors.WithStack(
 				fmt.Errorf("invalid type comparison %s and %s", left.Type(), right.Type()),
@@ -373,9 +334,7 @@ func LessThan(left, right value.Value) (value.Value, error) {
 		switch right.Type() {
 		case value.TimeType:
 			rv := value.Unwrap[*value.Time](right)
-			return &value.Boolean{
-				Value: lv.Value.Compare(rv.Value) < 0,
-			}, nil
+			return value.NewBoolean(lv.Value.Compare(rv.Value) < 0), nil
 		default:
 			return value.Null, errors.WithStack(
 				fmt.Errorf("invalid type comparison %s and %s", left.Type(), right.Type()),
@@ -398,18 +357,16 @@ func GreaterThanEqual(left, right value.Value) (value.Value, error) {
 		}
 		lv := value.Unwrap[*value.Integer](left)
 		if lv.IsNAN {
-			return &value.Boolean{Value: false}, nil
+			return value.False, nil
 		}
 		switch right.Type() {
 		case value.IntegerType:
 			rv := value.Unwrap[*value.Integer](right)
 			if rv.IsNAN {
-				return &value.Boolean{Value: false}, nil
+				return value.False, nil
 			}
 
-			return &value.Boolean{
-				Value: lv.Value >= rv.Value,
-			}, nil
+			return value.NewBoolean(lv.Value >= rv.Value), nil
 		case value.RTimeType:
 			if right.IsLiteral() {
 				return value.Null, errors.WithStack(
@@ -418,9 +375,7 @@ func GreaterThanEqual(left, right value.Value) (value.Value, error) {
 			}
 			rv := value.Unwrap[*value.RTime](right)
 
-			return &value.Boolean{
-				Value: lv.Value >= int64(rv.Value/time.Second),
-			}, nil
+			return value.NewBoolean(lv.Value >= int64(rv.Value/time.Second)), nil
 		default:
 			return value.Null, errors.WithStack(
 				fmt.Errorf("invalid type comparison %s and %s", left.Type(), right.Type()),
@@ -434,27 +389,23 @@ func GreaterThanEqual(left, right value.Value) (value.Value, error) {
 		}
 		lv := value.Unwrap[*value.Float](left)
 		if lv.IsNAN {
-			return &value.Boolean{Value: false}, nil
+			return value.False, nil
 		}
 		switch right.Type() {
 		case value.IntegerType:
 			rv := value.Unwrap[*value.Integer](right)
 			if rv.IsNAN {
-				return &value.Boolean{Value: false}, nil
+				return value.False, nil
 			}
 
-			return &value.Boolean{
-				Value: lv.Value >= float64(rv.Value),
-			}, nil
+			return value.NewBoolean(lv.Value >= float64(rv.Value)), nil
 		case value.FloatType:
 			rv := value.Unwrap[*value.Float](right)
 			if rv.IsNAN {
-				return &value.Boolean{Value: false}, nil
+				return value.False, nil
 			}
 
-			return &value.Boolean{
-				Value: lv.Value >= rv.Value,
-			}, nil
+			return value.NewBoolean(lv.Value >= rv.Value), nil
 		case value.RTimeType:
 			if right.IsLiteral() {
 				return value.Null, errors.WithStack(
@@ -463,9 +414,7 @@ func GreaterThanEqual(left, right value.Value) (value.Value, error) {
 			}
 			rv := value.Unwrap[*value.RTime](right)
 
-			return &value.Boolean{
-				Value: lv.Value >= float64(rv.Value/time.Second),
-			}, nil
+			return value.NewBoolean(lv.Value >= float64(rv.Value/time.Second)), nil
 		default:
 			return value.Null, errors.WithStack(
 				fmt.Errorf("invalid type comparison %s and %s", left.Type(), right.Type()),
@@ -487,12 +436,10 @@ func GreaterThanEqual(left, right value.Value) (value.Value, error) {
 			}
 			rv := value.Unwrap[*value.Integer](right)
 			if rv.IsNAN {
-				return &value.Boolean{Value: false}, nil
+				return value.False, nil
 			}
 
-			return &value.Boolean{
-				Value: int64(lv.Value/time.Second) >= rv.Value,
-			}, nil
+			return value.NewBoolean(int64(lv.Value/time.Second) >= rv.Value), nil
 		case value.FloatType:
 			if right.IsLiteral() {
 				return value.Null, errors.WithStack(
@@ -501,18 +448,14 @@ func GreaterThanEqual(left, right value.Value) (value.Value, error) {
 			}
 			rv := value.Unwrap[*value.Float](right)
 			if rv.IsNAN {
-				return &value.Boolean{Value: false}, nil
+				return value.False, nil
 			}
 
-			return &value.Boolean{
-				Value: float64(lv.Value/time.Second) >= rv.Value,
-			}, nil
+			return value.NewBoolean(float64(lv.Value/time.Second) >= rv.Value), nil
 		case value.RTimeType:
 			rv := value.Unwrap[*value.RTime](right)
 
-			return &value.Boolean{
-				Value: lv.Value >= rv.Value,
-			}, nil
+			return value.NewBoolean(lv.Value >= rv.Value), nil
 		default:
 			return value.Null, errors.WithStack(
 				fmt.Errorf("invalid type comparison %s and %s", left.Type(), right.Type()),
@@ -523,9 +466,7 @@ func GreaterThanEqual(left, right value.Value) (value.Value, error) {
 		switch right.Type() {
 		case value.TimeType:
 			rv := value.Unwrap[*value.Time](right)
-			return &value.Boolean{
-				Value: lv.Value.Compare(rv.Value) >= 0,
-			}, nil
+			return value.NewBoolean(lv.Value.Compare(rv.Value) >= 0), nil
 		default:
 			return value.Null, errors.WithStack(
 				fmt.Errorf("invalid type comparison %s and %s", left.Type(), right.Type()),
@@ -548,18 +489,16 @@ func LessThanEqual(left, right value.Value) (value.Value, error) {
 		}
 		lv := value.Unwrap[*value.Integer](left)
 		if lv.IsNAN {
-			return &value.Boolean{Value: false}, nil
+			return value.False, nil
 		}
 		switch right.Type() {
 		case value.IntegerType:
 			rv := value.Unwrap[*value.Integer](right)
 			if rv.IsNAN {
-				return &value.Boolean{Value: false}, nil
+				return value.False, nil
 			}
 
-			return &value.Boolean{
-				Value: lv.Value <= rv.Value,
-			}, nil
+			return value.NewBoolean(lv.Value <= rv.Value), nil
 		case value.RTimeType:
 			if right.IsLiteral() {
 				return value.Null, errors.WithStack(
@@ -568,9 +507,7 @@ func LessThanEqual(left, right value.Value) (value.Value, error) {
 			}
 			rv := value.Unwrap[*value.RTime](right)
 
-			return &value.Boolean{
-				Value: lv.Value <= int64(rv.Value/time.Second),
-			}, nil
+			return value.NewBoolean(lv.Value <= int64(rv.Value/time.Second)), nil
 		default:
 			return value.Null, errors.WithStack(
 				fmt.Errorf("invalid type comparison %s and %s", left.Type(), right.Type()),
@@ -584,27 +521,23 @@ func LessThanEqual(left, right value.Value) (value.Value, error) {
 		}
 		lv := value.Unwrap[*value.Float](left)
 		if lv.IsNAN {
-			return &value.Boolean{Value: false}, nil
+			return value.False, nil
 		}
 		switch right.Type() {
 		case value.IntegerType:
 			rv := value.Unwrap[*value.Integer](right)
 			if rv.IsNAN {
-				return &value.Boolean{Value: false}, nil
+				return value.False, nil
 			}
 
-			return &value.Boolean{
-				Value: lv.Value <= float64(rv.Value),
-			}, nil
+			return value.NewBoolean(lv.Value <= float64(rv.Value)), nil
 		case value.FloatType:
 			rv := value.Unwrap[*value.Float](right)
 			if rv.IsNAN {
-				return &value.Boolean{Value: false}, nil
+				return value.False, nil
 			}
 
-			return &value.Boolean{
-				Value: lv.Value <= rv.Value,
-			}, nil
+			return value.NewBoolean(lv.Value <= rv.Value), nil
 		case value.RTimeType:
 			if right.IsLiteral() {
 				return value.Null, errors.WithStack(
@@ -613,9 +546,7 @@ func LessThanEqual(left, right value.Value) (value.Value, error) {
 			}
 			rv := value.Unwrap[*value.RTime](right)
 
-			return &value.Boolean{
-				Value: lv.Value <= float64(rv.Value/time.Second),
-			}, nil
+			return value.NewBoolean(lv.Value <= float64(rv.Value/time.Second)), nil
 		default:
 			return value.Null, errors.WithStack(
 				fmt.Errorf("invalid type comparison %s and %s", left.Type(), right.Type()),
@@ -637,12 +568,10 @@ func LessThanEqual(left, right value.Value) (value.Value, error) {
 			}
 			rv := value.Unwrap[*value.Integer](right)
 			if rv.IsNAN {
-				return &value.Boolean{Value: false}, nil
+				return value.False, nil
 			}
 
-			return &value.Boolean{
-				Value: int64(lv.Value/time.Second) <= rv.Value,
-			}, nil
+			return value.NewBoolean(int64(lv.Value/time.Second) <= rv.Value), nil
 		case value.FloatType:
 			if right.IsLiteral() {
 				return value.Null, errors.WithStack(
@@ -651,18 +580,14 @@ func LessThanEqual(left, right value.Value) (value.Value, error) {
 			}
 			rv := value.Unwrap[*value.Float](right)
 			if rv.IsNAN {
-				return &value.Boolean{Value: false}, nil
+				return value.False, nil
 			}
 
-			return &value.Boolean{
-				Value: float64(lv.Value/time.Second) <= rv.Value,
-			}, nil
+			return value.NewBoolean(float64(lv.Value/time.Second) <= rv.Value), nil
 		case value.RTimeType:
 			rv := value.Unwrap[*value.RTime](right)
 
-			return &value.Boolean{
-				Value: lv.Value <= rv.Value,
-			}, nil
+			return value.NewBoolean(lv.Value <= rv.Value), nil
 		default:
 			return value.Null, errors.WithStack(
 				fmt.Errorf("invalid type comparison %s and %s", left.Type(), right.Type()),
@@ -673,9 +598,7 @@ func LessThanEqual(left, right value.Value) (value.Value, error) {
 		switch right.Type() {
 		case value.TimeType:
 			rv := value.Unwrap[*value.Time](right)
-			return &value.Boolean{
-				Value: lv.Value.Compare(rv.Value) <= 0,
-			}, nil
+			return value.NewBoolean(lv.Value.Compare(rv.Value) <= 0), nil
 		default:
 			return value.Null, errors.WithStack(
 				fmt.Errorf("invalid type comparison %s and %s", left.Type(), right.Type()),
@@ -719,13 +642,13 @@ func Regex(ctx *context.Context, left, right value.Value) (value.Value, error) {
 				for j, m := range matches {
 					ctx.RegexMatchedValues[fmt.Sprint(j)] = &value.String{Value: m}
 				}
-				return &value.Boolean{Value: true}, nil
+				return value.True, nil
 			}
-			return &value.Boolean{Value: false}, nil
+			return value.False, nil
 		case value.RegexType:
 			rv := value.Unwrap[*value.Regex](right)
 			if rv.Unsatisfiable {
-				return &value.Boolean{Value: false}, nil
+				return value.False, nil
 			}
 			re, err := pcre.Compile(rv.Value)
 			if err != nil {
@@ -739,9 +662,9 @@ func Regex(ctx *context.Context, left, right value.Value) (value.Value, error) {
 				for j, m := range matches {
 					ctx.RegexMatchedValues[fmt.Sprint(j)] = &value.String{Value: m}
 				}
-				return &value.Boolean{Value: true}, nil
+				return value.True, nil
 			}
-			return &value.Boolean{Value: false}, nil
+			return value.False, nil
 		case value.AclType:
 			rv := value.Unwrap[*value.Acl](right)
 			ip := net.ParseIP(lv.Value)
@@ -750,13 +673,11 @@ func Regex(ctx *context.Context, left, right value.Value) (value.Value, error) {
 					fmt.Errorf("failed to parse IP from string %s", lv.Value),
 				)
 			}
-			res, err := matchesAcl(*rv, ip)
+			res, err := matchesAcl(ctx, *rv, ip)
 			if err != nil {
 				return value.Null, errors.WithStack(err)
 			}
-			return &value.Boolean{
-				Value: res,
-			}, nil
+			return value.NewBoolean(res), nil
 		default:
 			return value.Null, errors.WithStack(
 				fmt.Errorf("invalid type comparison %s and %s", left.Type(), right.Type()),
@@ -767,13 +688,11 @@ func Regex(ctx *context.Context, left, right value.Value) (value.Value, error) {
 		switch right.Type() {
 		case value.AclType:
 			rv := value.Unwrap[*value.Acl](right)
-			res, err := matchesAcl(*rv, lv.Value)
+			res, err := matchesAcl(ctx, *rv, lv.Value)
 			if err != nil {
 				return value.Null, errors.WithStack(err)
 			}
-			return &value.Boolean{
-				Value: res,
-			}, nil
+			return value.NewBoolean(res), nil
 		default:
 			return value.Null, errors.WithStack(
 				fmt.Errorf("invalid type comparison %s and %s", left.Type(), right.Type()),
@@ -786,7 +705,19 @@ func Regex(ctx *context.Context, left, right value.Value) (value.Value, error) {
 	}
 }
 
-func matchesAcl(acl value.Acl, ip net.IP) (bool, error) {
+func matchesAcl(ctx *context.Context, acl value.Acl, ip net.IP) (bool, error) {
+	// Entries which are injected by testing.acl_add are consulted first,
+	// negated entry excludes the IP even if declared entries contain it
+	for _, entry := range ctx.InjectedAclEntries[acl.Value.Name.Value] {
+		_, ipnet, err := net.ParseCIDR(fmt.Sprintf("%s/%d", entry.IP.Value, entry.Mask.Value))
+		if err != nil {
+			return false, fmt.Errorf("failed to parse injected CIDR %s/%d", entry.IP.Value, entry.Mask.Value)
+		}
+		if ipnet.Contains(ip) {
+			return entry.Inverse == nil || !entry.Inverse.Value, nil
+		}
+	}
+
 	for _, entry := range acl.Value.CIDRs {
 		var mask int64 = 32
 		if entry.Mask != nil {
@@ -812,9 +743,7 @@ func NotRegex(ctx *context.Context, left, right value.Value) (value.Value, error
 	if err != nil {
 		return b, err
 	}
-	return &value.Boolean{
-		Value: !value.Unwrap[*value.Boolean](b).Value,
-	}, nil
+	return value.NewBoolean(!value.Unwrap[*value.Boolean](b).Value), nil
 }
 
 func LogicalAnd(left, right value.Value) (value.Value, error) {
@@ -856,7 +785,7 @@ func LogicalAnd(left, right value.Value) (value.Value, error) {
 		)
 	}
 
-	return &value.Boolean{Value: lv && rv}, nil
+	return value.NewBoolean(lv && rv), nil
 }
 
 func LogicalOr(left, right value.Value) (value.Value, error) {
@@ -898,7 +827,7 @@ func LogicalOr(left, right value.Value) (value.Value, error) {
 		)
 	}
 
-	return &value.Boolean{Value: lv || rv}, nil
+	return value.NewBoolean(lv || rv), nil
 }
 
 func Concat(left, right value.Value) (value.Value, error) {
@@ -916,24 +845,41 @@ func Concat(left, right value.Value) (value.Value, error) {
 			)
 		}
 	}
+	if err := checkConcatRight(right); err != nil {
+		return value.Null, err
+	}
+
+	return &value.String{
+		Value: left.String() + right.String(),
+	}, nil
+}
+
+// ConcatInto appends the right value to the buffer which holds the concatenated string,
+// in order to concatenate many values without allocating intermediate STRING values
+func ConcatInto(buf *bytes.Buffer, right value.Value) error {
+	if err := checkConcatRight(right); err != nil {
+		return err
+	}
+	buf.WriteString(right.String())
+	return nil
+}
+
+func checkConcatRight(right value.Value) error {
 	switch right.Type() {
 	case value.AclType, value.IdentType:
-		return value.Null, errors.WithStack(
+		return errors.WithStack(
 			fmt.Errorf("%s type could not unse for right concatenation expression", right.Type()),
 		)
 	case value.StringType, value.BooleanType:
-		break
+		return nil
 	default:
 		if right.IsLiteral() {
-			return value.Null, errors.WithStack(
+			return errors.WithStack(
 				fmt.Errorf("%s type could not use as literal for right concatenation expression", right.Type()),
 			)
 		}
 	}
-
-	return &value.String{
-		Value: left.String() + right.String(),
-	}, nil
+	return nil
 }
 
 func TimeCalculation(left, right value.Value, operator string) (value.Value, error) {
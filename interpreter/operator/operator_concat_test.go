@@ -1,6 +1,7 @@
 package operator
 
 import (
+	"bytes"
 	"net"
 	"net/http"
 	"testing"
@@ -398,3 +399,50 @@ func TestConcatOperator(t *testing.T) {
 		}
 	})
 }
+
+func TestConcatInto(t *testing.T) {
+	tests := []struct {
+		right   value.Value
+		expect  string
+		isError bool
+	}{
+		{right: &value.String{Value: "bar"}, expect: "foobar"},
+		{right: &value.String{Value: "bar", Literal: true}, expect: "foobar"},
+		{right: &value.Boolean{Value: true, Literal: true}, expect: "foo1"},
+		{right: &value.Integer{Value: 10}, expect: "foo10"},
+		{right: &value.Integer{Value: 10, Literal: true}, isError: true},
+		{right: &value.Ident{Value: "foo"}, isError: true},
+	}
+
+	for i, tt := range tests {
+		var buf bytes.Buffer
+		buf.WriteString("foo")
+		err := ConcatInto(&buf, tt.right)
+		if tt.isError {
+			if err == nil {
+				t.Errorf("Index %d: expects error but nil", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Index %d: Unexpected error %s", i, err)
+			continue
+		}
+		if buf.String() != tt.expect {
+			t.Errorf("Index %d: expect value %s, got %s", i, tt.expect, buf.String())
+		}
+	}
+
+	t.Run("no allocation for STRING values", func(t *testing.T) {
+		var buf bytes.Buffer
+		buf.Grow(64)
+		right := &value.String{Value: "foo"}
+		allocs := testing.AllocsPerRun(10, func() {
+			buf.Reset()
+			ConcatInto(&buf, right) // nolint:errcheck
+		})
+		if allocs != 0 {
+			t.Errorf("Concatenation must not allocate, got %.0f allocations", allocs)
+		}
+	})
+}
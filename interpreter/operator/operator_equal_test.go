@@ -392,3 +392,14 @@ func TestEqualOperator(t *testing.T) {
 		}
 	})
 }
+
+func TestEqualOperatorAllocations(t *testing.T) {
+	left := &value.Integer{Value: 10}
+	right := &value.Integer{Value: 10, Literal: true}
+	allocs := testing.AllocsPerRun(100, func() {
+		Equal(left, right) // nolint:errcheck
+	})
+	if allocs != 0 {
+		t.Errorf("Comparison result must be interned, got %.0f allocations", allocs)
+	}
+}
@@ -898,4 +898,53 @@ func TestRegexOperator(t *testing.T) {
 			})
 		}
 	})
+
+	t.Run("injected ACL entries are consulted first", func(t *testing.T) {
+		acl := &ast.AclDeclaration{
+			Name: &ast.Ident{Value: "example"},
+			CIDRs: []*ast.AclCidr{
+				{
+					Inverse: &ast.Boolean{Value: false},
+					IP:      &ast.IP{Value: "127.0.0.0"},
+					Mask:    &ast.Integer{Value: 16},
+				},
+			},
+		}
+		ctx := &context.Context{
+			RegexMatchedValues: make(map[string]*value.String),
+			InjectedAclEntries: map[string][]*ast.AclCidr{
+				"example": {
+					{
+						Inverse: &ast.Boolean{Value: true},
+						IP:      &ast.IP{Value: "127.0.0.5"},
+						Mask:    &ast.Integer{Value: 32},
+					},
+					{
+						Inverse: &ast.Boolean{Value: false},
+						IP:      &ast.IP{Value: "203.0.113.0"},
+						Mask:    &ast.Integer{Value: 24},
+					},
+				},
+			},
+		}
+		tests := []struct {
+			ip     string
+			expect bool
+		}{
+			{ip: "127.0.0.1", expect: true},
+			{ip: "127.0.0.5", expect: false},
+			{ip: "203.0.113.10", expect: true},
+			{ip: "192.0.2.1", expect: false},
+		}
+		for _, tt := range tests {
+			v, err := Regex(ctx, &value.IP{Value: net.ParseIP(tt.ip)}, &value.Acl{Value: acl})
+			if err != nil {
+				t.Errorf("%s: Unexpected error %s", tt.ip, err)
+				continue
+			}
+			if b := value.Unwrap[*value.Boolean](v); b.Value != tt.expect {
+				t.Errorf("%s: expects %t, got %t", tt.ip, tt.expect, b.Value)
+			}
+		}
+	})
 }
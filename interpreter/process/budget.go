@@ -0,0 +1,93 @@
+package process
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/ysugimoto/falco/v2/token"
+)
+
+// Budget is the latency budget of the route which is annotated like "# falco:budget ttfb=200ms name=api"
+type Budget struct {
+	Route string        `json:"route"`
+	File  string        `json:"file,omitempty"`
+	Line  int           `json:"line"`
+	TTFB  time.Duration `json:"-"`
+}
+
+// BudgetViolation is the route which simulated TTFB exceeds its budget
+type BudgetViolation struct {
+	Route  string `json:"route"`
+	File   string `json:"file,omitempty"`
+	Line   int    `json:"line"`
+	Budget string `json:"budget"`
+	Actual string `json:"actual"`
+}
+
+func (v *BudgetViolation) String() string {
+	return fmt.Sprintf("route %s exceeds TTFB budget %s, simulated TTFB is %s", v.Route, v.Budget, v.Actual)
+}
+
+// ParseBudget parses key=value pairs of the budget annotation.
+// "ttfb" is required and "name" is optional, the route is named by the annotated location if name is not specified
+func ParseBudget(spec string, tok token.Token) (*Budget, error) {
+	b := &Budget{
+		File: tok.File,
+		Line: tok.Line,
+	}
+	for _, field := range strings.Fields(spec) {
+		key, val, found := strings.Cut(field, "=")
+		if !found {
+			return nil, errors.Errorf("invalid budget field %q, expects key=value", field)
+		}
+		switch key {
+		case "ttfb":
+			d, err := time.ParseDuration(val)
+			if err != nil {
+				return nil, errors.Errorf("invalid ttfb budget %q: %s", val, err)
+			}
+			b.TTFB = d
+		case "name":
+			b.Route = val
+		default:
+			return nil, errors.Errorf("unknown budget field %q", key)
+		}
+	}
+	if b.TTFB <= 0 {
+		return nil, errors.New("ttfb budget must be specified")
+	}
+	if b.Route == "" {
+		if b.File != "" {
+			b.Route = fmt.Sprintf("%s:%d", b.File, b.Line)
+		} else {
+			b.Route = fmt.Sprintf("line %d", b.Line)
+		}
+	}
+	return b, nil
+}
+
+// CheckBudgets compares simulated TTFB with budgets of entered routes.
+// The route which is entered multiple times, e.g on restart, is checked once
+func (p *Process) CheckBudgets() []*BudgetViolation {
+	var violations []*BudgetViolation
+	seen := make(map[string]struct{})
+	for _, b := range p.Budgets {
+		if _, ok := seen[b.Route]; ok {
+			continue
+		}
+		seen[b.Route] = struct{}{}
+		if p.TTFB <= b.TTFB {
+			continue
+		}
+		violations = append(violations, &BudgetViolation{
+			Route:  b.Route,
+			File:   b.File,
+			Line:   b.Line,
+			Budget: b.TTFB.String(),
+			Actual: p.TTFB.String(),
+		})
+	}
+	return violations
+}
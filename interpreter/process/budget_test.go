@@ -0,0 +1,61 @@
+package process
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ysugimoto/falco/v2/token"
+)
+
+func TestParseBudget(t *testing.T) {
+	tok := token.Token{File: "main.vcl", Line: 12}
+	tests := []struct {
+		spec   string
+		route  string
+		ttfb   time.Duration
+		hasErr bool
+	}{
+		{spec: "ttfb=200ms", route: "main.vcl:12", ttfb: 200 * time.Millisecond},
+		{spec: "ttfb=1s name=api", route: "api", ttfb: time.Second},
+		{spec: "name=api", hasErr: true},
+		{spec: "ttfb=fast", hasErr: true},
+		{spec: "ttfb", hasErr: true},
+		{spec: "ttfb=200ms p99=1s", hasErr: true},
+	}
+
+	for _, tt := range tests {
+		b, err := ParseBudget(tt.spec, tok)
+		if tt.hasErr {
+			if err == nil {
+				t.Errorf("%s: expected error but got nil", tt.spec)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", tt.spec, err)
+			continue
+		}
+		if b.Route != tt.route || b.TTFB != tt.ttfb {
+			t.Errorf("%s: unexpected budget route=%s ttfb=%s", tt.spec, b.Route, b.TTFB)
+		}
+	}
+}
+
+func TestCheckBudgets(t *testing.T) {
+	p := New(time.Now())
+	p.TTFB = 300 * time.Millisecond
+	p.Budgets = []*Budget{
+		{Route: "api", Line: 3, TTFB: 200 * time.Millisecond},
+		{Route: "static", Line: 8, TTFB: time.Second},
+		// Entered again on restart
+		{Route: "api", Line: 3, TTFB: 200 * time.Millisecond},
+	}
+
+	violations := p.CheckBudgets()
+	if len(violations) != 1 {
+		t.Fatalf("Expected 1 violation, got %d", len(violations))
+	}
+	if v := violations[0]; v.Route != "api" || v.Budget != "200ms" || v.Actual != "300ms" {
+		t.Errorf("Unexpected violation %s", v)
+	}
+}
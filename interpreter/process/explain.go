@@ -0,0 +1,69 @@
+package process
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/ysugimoto/falco/v2/token"
+)
+
+// Cache results of the explanation
+const (
+	ResultHit        = "HIT"
+	ResultMiss       = "MISS"
+	ResultPass       = "PASS"
+	ResultHitForPass = "HIT-FOR-PASS"
+	ResultError      = "ERROR"
+)
+
+type Reason struct {
+	Message  string `json:"message"`
+	File     string `json:"file,omitempty"`
+	Line     int    `json:"line,omitempty"`
+	Position int    `json:"position,omitempty"`
+}
+
+// Explanation describes why the request results in the cache state.
+// Reasons are added in processed order so the explanation could be read from top to bottom
+type Explanation struct {
+	Result  string    `json:"result"`
+	Reasons []*Reason `json:"reasons"`
+}
+
+func NewExplanation() *Explanation {
+	return &Explanation{
+		Result:  ResultMiss,
+		Reasons: []*Reason{},
+	}
+}
+
+// Add reason with the location, tok could be nil when the reason does not relate to any statement
+func (e *Explanation) Add(tok *token.Token, format string, args ...any) {
+	r := &Reason{
+		Message: fmt.Sprintf(format, args...),
+	}
+	if tok != nil {
+		r.File = tok.File
+		r.Line = tok.Line
+		r.Position = tok.Position
+	}
+	e.Reasons = append(e.Reasons, r)
+}
+
+func (e *Explanation) String() string {
+	var buf bytes.Buffer
+
+	buf.WriteString("Result: " + e.Result + "\n")
+	for _, r := range e.Reasons {
+		buf.WriteString("  - " + r.Message)
+		if r.Line > 0 {
+			file := r.File
+			if file == "" {
+				file = "-"
+			}
+			buf.WriteString(fmt.Sprintf(" at %s:%d:%d", file, r.Line, r.Position))
+		}
+		buf.WriteString("\n")
+	}
+	return buf.String()
+}
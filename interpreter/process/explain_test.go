@@ -0,0 +1,24 @@
+package process
+
+import (
+	"testing"
+
+	"github.com/ysugimoto/falco/v2/token"
+)
+
+func TestExplanationString(t *testing.T) {
+	e := NewExplanation()
+	e.Result = ResultHitForPass
+	e.Add(nil, "Cached object not found for hash %s", "/localhost")
+	e.Add(&token.Token{File: "main.vcl", Line: 20, Position: 5}, "%s returned %s", "vcl_fetch", "PASS")
+	e.Add(&token.Token{Line: 3, Position: 1}, "beresp.ttl is set to %s", "0s")
+
+	expect := `Result: HIT-FOR-PASS
+  - Cached object not found for hash /localhost
+  - vcl_fetch returned PASS at main.vcl:20:5
+  - beresp.ttl is set to 0s at -:3:1
+`
+	if actual := e.String(); actual != expect {
+		t.Errorf("String() mismatch, expect=%q, actual=%q", expect, actual)
+	}
+}
@@ -11,6 +11,8 @@ type Log struct {
 	Line     int    `json:"line"`
 	Position int    `json:"position"`
 	Message  string `json:"message"`
+	// Request ID to correlate logs of multiple requests
+	RequestID string `json:"request_id,omitempty"`
 }
 
 func NewLog(l *ast.LogStatement, scope context.Scope, message string) *Log {
@@ -9,6 +9,7 @@ import (
 
 	"github.com/ysugimoto/falco/v2/interpreter/http"
 	"github.com/ysugimoto/falco/v2/interpreter/value"
+	"github.com/ysugimoto/falco/v2/policy"
 )
 
 type Process struct {
@@ -18,15 +19,47 @@ type Process struct {
 	Backend   *value.Backend
 	Cached    bool
 	Error     error
-	StartTime int64
+	StartTime int64 // unix time microsecond
+	EndTime   int64 // unix time microsecond, set when the request has been processed
 	Response  *http.Response
+	RequestID string
+
+	// Protected header violations which are found synchronously after this execution.
+	// Trace policy violations are evaluated in the background and reported via debug messages, so they are not contained
+	Violations []*policy.Violation
+
+	// Variable and header accesses, recorded only when provenance tracking is enabled
+	Accesses []*Access
+
+	// Explanation of the cache result, not nil only when explain is enabled
+	Explanation *Explanation
+
+	// State machine transitions in processed order
+	Transitions []*Transition
+
+	// Processed statements, recorded only when trace recording is enabled
+	Steps []*Step
+
+	// Unimplemented builtin functions and variables which are fallen back by the unimplemented policy
+	Unimplemented []string
+
+	// Latency budgets of the annotated routes which the request entered
+	Budgets []*Budget
+
+	// Simulated time to first byte which is accumulated from backend latencies
+	TTFB time.Duration
+
+	// Routes which simulated TTFB exceeds the budget
+	BudgetViolations []*BudgetViolation
 }
 
-func New() *Process {
+// Create process which starts at provided time, the time should come from the interpreter clock
+func New(now time.Time) *Process {
 	return &Process{
-		Flows:     []*Flow{},
-		Logs:      []*Log{},
-		StartTime: time.Now().UnixMicro(),
+		Flows:       []*Flow{},
+		Logs:        []*Log{},
+		Transitions: []*Transition{},
+		StartTime:   now.UnixMicro(),
 	}
 }
 
@@ -64,29 +97,48 @@ func (p *Process) Finalize(resp *http.Response) ([]byte, error) {
 		errMsg = p.Error.Error()
 	}
 
+	var elapsed int64
+	if p.EndTime > 0 {
+		elapsed = p.EndTime - p.StartTime
+	}
+
 	return json.MarshalIndent(struct {
-		Flows          []*Flow `json:"flows"`
-		Logs           []*Log  `json:"logs"`
-		Restarts       int     `json:"restarts"`
-		Backend        string  `json:"backend"`
-		Cached         bool    `json:"cached"`
-		ElapsedTimeUs  int64   `json:"elapsed_time_us"`
-		ElapsedTimeMs  int64   `json:"elapsed_time_ms"`
-		Error          string  `json:"error,omitempty"`
+		RequestID      string              `json:"request_id,omitempty"`
+		Flows          []*Flow             `json:"flows"`
+		Transitions    []*Transition       `json:"transitions"`
+		Logs           []*Log              `json:"logs"`
+		Restarts       int                 `json:"restarts"`
+		Backend        string              `json:"backend"`
+		Cached         bool                `json:"cached"`
+		ElapsedTimeUs  int64               `json:"elapsed_time_us"`
+		ElapsedTimeMs  int64               `json:"elapsed_time_ms"`
+		Error          string              `json:"error,omitempty"`
+		Violations     []*policy.Violation `json:"violations,omitempty"`
+		Provenance     []*Access           `json:"provenance,omitempty"`
+		Explain        *Explanation        `json:"explain,omitempty"`
+		Unimplemented  []string            `json:"unimplemented,omitempty"`
+		Budgets        []*BudgetViolation  `json:"budget_violations,omitempty"`
 		ClientResponse struct {
 			StatusCode    int               `json:"status_code"`
 			ResponseBytes int               `json:"body_bytes"`
 			Headers       map[string]string `json:"headers"`
 		} `json:"client_response"`
 	}{
+		RequestID:     p.RequestID,
 		Flows:         p.Flows,
+		Transitions:   p.Transitions,
 		Logs:          p.Logs,
 		Restarts:      p.Restarts,
 		Backend:       backend,
 		Cached:        false,
-		ElapsedTimeUs: time.Now().UnixMicro() - p.StartTime,
-		ElapsedTimeMs: time.Now().UnixMilli() - (p.StartTime / 1000),
+		ElapsedTimeUs: elapsed,
+		ElapsedTimeMs: elapsed / 1000,
 		Error:         errMsg,
+		Violations:    p.Violations,
+		Provenance:    p.Accesses,
+		Explain:       p.Explanation,
+		Unimplemented: p.Unimplemented,
+		Budgets:       p.BudgetViolations,
 		ClientResponse: struct {
 			StatusCode    int               `json:"status_code"`
 			ResponseBytes int               `json:"body_bytes"`
@@ -98,3 +150,29 @@ func (p *Process) Finalize(resp *http.Response) ([]byte, error) {
 		},
 	}, "", "  ")
 }
+
+// TraceInput is the input document for evaluating policies against the execution trace
+type TraceInput struct {
+	RequestID string  `json:"request_id,omitempty"`
+	Flows     []*Flow `json:"flows"`
+	Logs      []*Log  `json:"logs"`
+	Restarts  int     `json:"restarts"`
+	Backend   string  `json:"backend"`
+	Error     string  `json:"error,omitempty"`
+}
+
+func (p *Process) TraceInput() *TraceInput {
+	input := &TraceInput{
+		RequestID: p.RequestID,
+		Flows:     p.Flows,
+		Logs:      p.Logs,
+		Restarts:  p.Restarts,
+	}
+	if p.Backend != nil {
+		input.Backend = p.Backend.String()
+	}
+	if p.Error != nil {
+		input.Error = p.Error.Error()
+	}
+	return input
+}
@@ -3,6 +3,7 @@ package process
 import (
 	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/ysugimoto/falco/v2/ast"
 	"github.com/ysugimoto/falco/v2/interpreter/value"
@@ -43,7 +44,7 @@ func TestFinalizeBackendName(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			p := New()
+			p := New(time.Now())
 			p.Backend = tt.backend
 
 			b, err := p.Finalize(nil)
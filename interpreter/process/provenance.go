@@ -0,0 +1,69 @@
+package process
+
+import (
+	"fmt"
+
+	"github.com/ysugimoto/falco/v2/interpreter/context"
+	"github.com/ysugimoto/falco/v2/token"
+)
+
+type AccessKind string
+
+const (
+	AccessRead  AccessKind = "read"
+	AccessWrite AccessKind = "write"
+	AccessUnset AccessKind = "unset"
+)
+
+// Access represents a variable or header access with the statement location.
+// These are recorded in order so the last write of the name answers "what set this value?"
+type Access struct {
+	Kind       AccessKind `json:"kind"`
+	Name       string     `json:"name"`
+	Operator   string     `json:"operator,omitempty"`
+	Value      string     `json:"value"`
+	Scope      string     `json:"scope"`
+	Subroutine string     `json:"subroutine,omitempty"`
+	File       string     `json:"file"`
+	Line       int        `json:"line"`
+	Position   int        `json:"position"`
+}
+
+func NewAccess(kind AccessKind, name, val string, scope context.Scope, tok token.Token) *Access {
+	return &Access{
+		Kind:     kind,
+		Name:     name,
+		Value:    val,
+		Scope:    scope.String(),
+		File:     tok.File,
+		Line:     tok.Line,
+		Position: tok.Position,
+	}
+}
+
+func (a *Access) String() string {
+	var operator string
+	if a.Operator != "" && a.Operator != "=" {
+		operator = " " + a.Operator
+	}
+	file := a.File
+	if file == "" {
+		file = "-"
+	}
+	return fmt.Sprintf(
+		"[%s] %s%s %q (%s) at %s:%d:%d",
+		a.Kind, a.Name, operator, a.Value, a.Scope, file, a.Line, a.Position,
+	)
+}
+
+// Find accesses which wrote the value for the name, returns in executed order
+func (p *Process) Writers(name string) []*Access {
+	var accesses []*Access
+	for _, a := range p.Accesses {
+		if a.Name != name || a.Kind == AccessRead {
+			continue
+		}
+		accesses = append(accesses, a)
+	}
+	return accesses
+}
@@ -0,0 +1,65 @@
+package process
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ysugimoto/falco/v2/interpreter/context"
+	"github.com/ysugimoto/falco/v2/token"
+)
+
+func TestAccessString(t *testing.T) {
+	tok := token.Token{File: "main.vcl", Line: 10, Position: 3}
+	tests := []struct {
+		name   string
+		access *Access
+		expect string
+	}{
+		{
+			name:   "write access",
+			access: NewAccess(AccessWrite, "req.http.Foo", "bar", context.RecvScope, tok),
+			expect: `[write] req.http.Foo "bar" (RECV) at main.vcl:10:3`,
+		},
+		{
+			name: "write access with compound operator",
+			access: func() *Access {
+				a := NewAccess(AccessWrite, "var.count", "2", context.FetchScope, tok)
+				a.Operator = "+="
+				return a
+			}(),
+			expect: `[write] var.count += "2" (FETCH) at main.vcl:10:3`,
+		},
+		{
+			name:   "access without file",
+			access: NewAccess(AccessRead, "req.url", "/", context.RecvScope, token.Token{Line: 1, Position: 1}),
+			expect: `[read] req.url "/" (RECV) at -:1:1`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if actual := tt.access.String(); actual != tt.expect {
+				t.Errorf("String() mismatch, expect=%s, actual=%s", tt.expect, actual)
+			}
+		})
+	}
+}
+
+func TestProcessWriters(t *testing.T) {
+	p := New(time.Now())
+	p.Accesses = []*Access{
+		NewAccess(AccessWrite, "req.http.Foo", "a", context.RecvScope, token.Token{Line: 1}),
+		NewAccess(AccessRead, "req.http.Foo", "a", context.RecvScope, token.Token{Line: 2}),
+		NewAccess(AccessWrite, "req.http.Bar", "b", context.RecvScope, token.Token{Line: 3}),
+		NewAccess(AccessUnset, "req.http.Foo", "NULL", context.RecvScope, token.Token{Line: 4}),
+	}
+
+	writers := p.Writers("req.http.Foo")
+	if len(writers) != 2 {
+		t.Errorf("Writers must return 2 accesses, got %d", len(writers))
+		return
+	}
+	if writers[0].Line != 1 || writers[1].Line != 4 {
+		t.Errorf("Writers must be returned in executed order, got lines %d, %d", writers[0].Line, writers[1].Line)
+	}
+}
@@ -0,0 +1,64 @@
+package process
+
+import (
+	"github.com/ysugimoto/falco/v2/token"
+	"github.com/ysugimoto/falco/v2/trace"
+)
+
+// Step represents single statement execution, recorded only when trace recording is enabled
+type Step struct {
+	Scope      string
+	Subroutine string
+	File       string
+	Line       int
+	Position   int
+
+	// Index of accesses which are recorded while processing this step
+	accessIndex int
+}
+
+func (p *Process) AddStep(scope, subroutine string, tok token.Token) {
+	p.Steps = append(p.Steps, &Step{
+		Scope:       scope,
+		Subroutine:  subroutine,
+		File:        tok.File,
+		Line:        tok.Line,
+		Position:    tok.Position,
+		accessIndex: len(p.Accesses),
+	})
+}
+
+// Trace converts recorded steps and accesses to the trace.
+// Accesses are assigned to the step which is processing when the access is made
+func (p *Process) Trace(name string) *trace.Trace {
+	t := trace.New(name)
+	t.RequestID = p.RequestID
+	if p.Error != nil {
+		t.Error = p.Error.Error()
+	}
+
+	for i, s := range p.Steps {
+		end := len(p.Accesses)
+		if i+1 < len(p.Steps) {
+			end = p.Steps[i+1].accessIndex
+		}
+		step := &trace.Step{
+			Scope:      s.Scope,
+			Subroutine: s.Subroutine,
+			File:       s.File,
+			Line:       s.Line,
+			Position:   s.Position,
+		}
+		for _, a := range p.Accesses[s.accessIndex:end] {
+			step.Changes = append(step.Changes, &trace.Change{
+				Kind:  string(a.Kind),
+				Name:  a.Name,
+				Value: a.Value,
+			})
+		}
+		t.Steps = append(t.Steps, step)
+	}
+	t.LoadSources()
+
+	return t
+}
@@ -0,0 +1,37 @@
+package process
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ysugimoto/falco/v2/interpreter/context"
+	"github.com/ysugimoto/falco/v2/token"
+)
+
+func TestProcessTrace(t *testing.T) {
+	p := New(time.Now())
+	tok := token.Token{Line: 1, Position: 1}
+
+	p.AddStep("RECV", "vcl_recv", tok)
+	p.Accesses = append(p.Accesses, NewAccess(AccessRead, "req.url", "/", context.RecvScope, tok))
+	p.AddStep("RECV", "vcl_recv", tok)
+	p.AddStep("RECV", "vcl_recv", tok)
+	p.Accesses = append(
+		p.Accesses,
+		NewAccess(AccessWrite, "req.http.Foo", "bar", context.RecvScope, tok),
+		NewAccess(AccessUnset, "req.http.Cookie", "", context.RecvScope, tok),
+	)
+
+	tr := p.Trace("GET /")
+	if len(tr.Steps) != 3 {
+		t.Fatalf("Steps count mismatch, expect=3, actual=%d", len(tr.Steps))
+	}
+	for i, expect := range []int{1, 0, 2} {
+		if actual := len(tr.Steps[i].Changes); actual != expect {
+			t.Errorf("Changes count of step %d mismatch, expect=%d, actual=%d", i, expect, actual)
+		}
+	}
+	if tr.Steps[2].Changes[1].Kind != "unset" {
+		t.Errorf("Change kind mismatch, expect=unset, actual=%s", tr.Steps[2].Changes[1].Kind)
+	}
+}
@@ -0,0 +1,109 @@
+package process
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/ysugimoto/falco/v2/token"
+)
+
+// Flow diagram formats
+const (
+	DiagramMermaid = "mermaid"
+	DiagramASCII   = "ascii"
+)
+
+// Transition represents state machine transition with the statement location which triggered it.
+// Location is empty when the state is moved by default e.g subroutine does not have return statement
+type Transition struct {
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Restart  bool   `json:"restart,omitempty"`
+	File     string `json:"file,omitempty"`
+	Line     int    `json:"line,omitempty"`
+	Position int    `json:"position,omitempty"`
+}
+
+func NewTransition(from, to string, tok *token.Token) *Transition {
+	t := &Transition{
+		From: from,
+		To:   to,
+	}
+	if tok != nil {
+		t.File = tok.File
+		t.Line = tok.Line
+		t.Position = tok.Position
+	}
+	return t
+}
+
+func (t *Transition) label() string {
+	var label []string
+	if t.Restart {
+		label = append(label, "restart")
+	}
+	if t.Line > 0 {
+		file := t.File
+		if file == "" {
+			file = "-"
+		}
+		label = append(label, fmt.Sprintf("%s:%d:%d", file, t.Line, t.Position))
+	}
+	return strings.Join(label, " ")
+}
+
+// CacheDecision returns the last cache lookup result of the request, HIT, MISS or PASS.
+// Empty string is returned when the request does not reach to the cache lookup e.g. error in RECV
+func (p *Process) CacheDecision() string {
+	for i := len(p.Transitions) - 1; i >= 0; i-- {
+		switch to := p.Transitions[i].To; to {
+		case "HIT", "MISS", "PASS":
+			return to
+		}
+	}
+	return ""
+}
+
+func IsDiagramFormat(format string) bool {
+	return format == DiagramMermaid || format == DiagramASCII
+}
+
+// Diagram renders actual state machine path of the request in the provided format.
+// Transitions are numbered in processed order because the same state could appear multiple times on restart
+func (p *Process) Diagram(format string) string {
+	if format == DiagramMermaid {
+		return p.mermaid()
+	}
+	return p.ascii()
+}
+
+func (p *Process) mermaid() string {
+	var buf bytes.Buffer
+
+	buf.WriteString("flowchart TD\n")
+	for i, t := range p.Transitions {
+		label := fmt.Sprint(i + 1)
+		if l := t.label(); l != "" {
+			label += ". " + l
+		}
+		buf.WriteString(fmt.Sprintf("  %s -->|\"%s\"| %s\n", t.From, label, t.To))
+	}
+	return buf.String()
+}
+
+func (p *Process) ascii() string {
+	var buf bytes.Buffer
+
+	for i, t := range p.Transitions {
+		if i == 0 {
+			buf.WriteString(t.From + "\n")
+		}
+		buf.WriteString("  |")
+		if l := t.label(); l != "" {
+			buf.WriteString(" " + l)
+		}
+		buf.WriteString("\n  v\n" + t.To + "\n")
+	}
+	return buf.String()
+}
@@ -0,0 +1,73 @@
+package process
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ysugimoto/falco/v2/token"
+)
+
+func TestDiagram(t *testing.T) {
+	p := New(time.Now())
+	p.Transitions = []*Transition{
+		NewTransition("RECV", "HASH", nil),
+		NewTransition("HASH", "MISS", &token.Token{File: "main.vcl", Line: 20, Position: 3}),
+		func() *Transition {
+			t := NewTransition("MISS", "RECV", &token.Token{File: "main.vcl", Line: 30, Position: 5})
+			t.Restart = true
+			return t
+		}(),
+	}
+
+	tests := []struct {
+		format string
+		expect string
+	}{
+		{
+			format: DiagramMermaid,
+			expect: `flowchart TD
+  RECV -->|"1"| HASH
+  HASH -->|"2. main.vcl:20:3"| MISS
+  MISS -->|"3. restart main.vcl:30:5"| RECV
+`,
+		},
+		{
+			format: DiagramASCII,
+			expect: `RECV
+  |
+  v
+HASH
+  | main.vcl:20:3
+  v
+MISS
+  | restart main.vcl:30:5
+  v
+RECV
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			if actual := p.Diagram(tt.format); actual != tt.expect {
+				t.Errorf("Diagram mismatch, expect=%q, actual=%q", tt.expect, actual)
+			}
+		})
+	}
+}
+
+func TestCacheDecision(t *testing.T) {
+	p := New(time.Now())
+	if v := p.CacheDecision(); v != "" {
+		t.Errorf("Expected empty cache decision but got %s", v)
+	}
+	p.Transitions = []*Transition{
+		NewTransition("RECV", "HASH", nil),
+		NewTransition("HASH", "MISS", nil),
+		NewTransition("MISS", "FETCH", nil),
+		NewTransition("FETCH", "DELIVER", nil),
+	}
+	if v := p.CacheDecision(); v != "MISS" {
+		t.Errorf("Expected MISS but got %s", v)
+	}
+}
@@ -0,0 +1,111 @@
+package interpreter
+
+import (
+	"fmt"
+	ghttp "net/http"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/ysugimoto/falco/v2/policy"
+)
+
+// Rule name of the violation which is reported when protected header is exposed on the simulator
+const protectedHeaderRule = "protected-header"
+
+// checkProtectedHeaders detects protected headers which are exposed to clients or logs.
+// This is checked after the request has been processed, so the final response and all logs are verified
+// regardless of code paths like error and restart flows
+func (i *Interpreter) checkProtectedHeaders(r *ghttp.Request) []*policy.Violation {
+	if len(i.ctx.ProtectedHeaders) == 0 {
+		return nil
+	}
+
+	// Collect values of the protected headers which the request has, or which are sent to and received from the origin
+	secrets := make(map[string]string)
+	sources := []ghttp.Header{r.Header}
+	if i.ctx.Request != nil {
+		sources = append(sources, i.ctx.Request.Header)
+	}
+	if i.ctx.BackendRequest != nil {
+		sources = append(sources, i.ctx.BackendRequest.Header)
+	}
+	if i.ctx.BackendResponse != nil {
+		sources = append(sources, i.ctx.BackendResponse.Header)
+	}
+	for _, h := range sources {
+		for key, values := range h {
+			if !i.isProtectedHeader(key) {
+				continue
+			}
+			for _, v := range values {
+				if v != "" {
+					secrets[v] = key
+				}
+			}
+		}
+	}
+
+	var violations []*policy.Violation
+	if i.ctx.Response != nil {
+		keys := make([]string, 0, len(i.ctx.Response.Header))
+		for key := range i.ctx.Response.Header {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			if i.isProtectedHeader(key) {
+				violations = append(violations, &policy.Violation{
+					Rule:     protectedHeaderRule,
+					Severity: "ERROR",
+					Message:  fmt.Sprintf("Protected header %s is exposed to clients in the response", key),
+				})
+				continue
+			}
+			for _, v := range i.ctx.Response.Header.Values(key) {
+				if header, ok := containsSecret(v, secrets); ok {
+					violations = append(violations, &policy.Violation{
+						Rule:     protectedHeaderRule,
+						Severity: "ERROR",
+						Message:  fmt.Sprintf("Value of protected header %s is exposed to clients via response header %s", header, key),
+					})
+				}
+			}
+		}
+	}
+
+	for _, log := range i.process.Logs {
+		if header, ok := containsSecret(log.Message, secrets); ok {
+			violations = append(violations, &policy.Violation{
+				Rule:     protectedHeaderRule,
+				Severity: "ERROR",
+				Message:  fmt.Sprintf("Value of protected header %s is exposed to logs", header),
+				File:     log.File,
+				Line:     log.Line,
+				Position: log.Position,
+			})
+		}
+	}
+
+	return violations
+}
+
+func (i *Interpreter) isProtectedHeader(name string) bool {
+	for _, pattern := range i.ctx.ProtectedHeaders {
+		if matched, _ := path.Match(strings.ToLower(pattern), strings.ToLower(name)); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// Find the protected header whose value is contained in the text
+func containsSecret(text string, secrets map[string]string) (string, bool) {
+	for secret, header := range secrets {
+		if strings.Contains(text, secret) {
+			return header, true
+		}
+	}
+	return "", false
+}
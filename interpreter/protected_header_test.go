@@ -0,0 +1,78 @@
+package interpreter
+
+import (
+	ghttp "net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/ysugimoto/falco/v2/interpreter/context"
+	"github.com/ysugimoto/falco/v2/interpreter/http"
+	"github.com/ysugimoto/falco/v2/interpreter/process"
+	"github.com/ysugimoto/falco/v2/resolver"
+)
+
+func TestCheckProtectedHeaders(t *testing.T) {
+	req := httptest.NewRequest(ghttp.MethodGet, "http://localhost", nil)
+	req.Header.Set("X-Internal-Auth", "s3cr3t")
+
+	ip := New()
+	ip.ctx = context.New(context.WithProtectedHeaders([]string{"x-internal-*"}))
+	ip.ctx.Request = http.WrapRequest(req)
+	ip.ctx.Response = http.WrapResponse(&ghttp.Response{
+		StatusCode: 200,
+		Header: ghttp.Header{
+			"X-Internal-Token": {"token"},
+			"X-Debug":          {"auth=s3cr3t"},
+			"Content-Type":     {"text/plain"},
+		},
+	})
+	ip.process.Logs = []*process.Log{
+		{File: "main.vcl", Line: 10, Position: 3, Message: "request logged"},
+		{File: "main.vcl", Line: 11, Position: 3, Message: "auth s3cr3t"},
+	}
+
+	var actual []string
+	for _, v := range ip.checkProtectedHeaders(req) {
+		actual = append(actual, v.Message)
+	}
+	expect := []string{
+		"Value of protected header X-Internal-Auth is exposed to clients via response header X-Debug",
+		"Protected header X-Internal-Token is exposed to clients in the response",
+		"Value of protected header X-Internal-Auth is exposed to logs",
+	}
+	if diff := cmp.Diff(expect, actual); diff != "" {
+		t.Errorf("Violations mismatch, diff=%s", diff)
+	}
+}
+
+func TestProtectedHeaderViolationsInProcess(t *testing.T) {
+	vcl := `
+sub vcl_recv {
+  #FASTLY RECV
+  error 600;
+}
+
+sub vcl_error {
+  #FASTLY ERROR
+  set obj.status = 200;
+  set obj.http.X-Internal-Token = "token";
+  return (deliver);
+}
+`
+	ip := New(
+		context.WithResolver(resolver.NewStaticResolver("main", vcl)),
+		context.WithProtectedHeaders([]string{"x-internal-*"}),
+	)
+	ip.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(ghttp.MethodGet, "http://localhost", nil))
+
+	// Protected header violations are found synchronously so they are contained in the process
+	var actual []string
+	for _, v := range ip.Process().Violations {
+		actual = append(actual, v.Message)
+	}
+	expect := []string{"Protected header X-Internal-Token is exposed to clients in the response"}
+	if diff := cmp.Diff(expect, actual); diff != "" {
+		t.Errorf("Violations mismatch, diff=%s", diff)
+	}
+}
@@ -0,0 +1,57 @@
+package interpreter
+
+import (
+	"strings"
+
+	"github.com/ysugimoto/falco/v2/ast"
+	"github.com/ysugimoto/falco/v2/interpreter/process"
+	"github.com/ysugimoto/falco/v2/interpreter/value"
+)
+
+// Explanation and trace recording also use accesses to find the location where the variable is set
+func (i *Interpreter) isRecordingAccess() bool {
+	// Context may not be initialized when the statement is processed directly like unit testing
+	if i.ctx == nil {
+		return false
+	}
+	return i.ctx.Provenance || i.ctx.Explain || i.ctx.TraceDir != ""
+}
+
+// Record variable or header access for provenance tracking.
+// Idents that do not have a namespace like backend or acl name are not variable so we ignore them
+func (i *Interpreter) recordAccess(kind process.AccessKind, ident *ast.Ident, operator string, val value.Value) {
+	if !i.isRecordingAccess() || !strings.Contains(ident.Value, ".") {
+		return
+	}
+
+	var v string
+	if val != nil {
+		v = val.String()
+	}
+	access := process.NewAccess(kind, ident.Value, v, i.ctx.Scope, ident.GetMeta().Token)
+	access.Operator = operator
+	if len(i.callStack) > 0 {
+		access.Subroutine = i.callStack[len(i.callStack)-1].Name.Value
+	}
+	i.process.Accesses = append(i.process.Accesses, access)
+}
+
+// Record variable or header write access with the assembled value.
+// The assembled value may differ from right-hand side value for compound operators like "+="
+func (i *Interpreter) recordWrite(ident *ast.Ident, operator string) {
+	if !i.isRecordingAccess() {
+		return
+	}
+
+	var val value.Value
+	var err error
+	if isLocalVariableIdent(ident) {
+		val, err = i.localVars.Get(ident.Value)
+	} else {
+		val, err = i.vars.Get(i.ctx.Scope, ident.Value)
+	}
+	if err != nil {
+		val = value.Null
+	}
+	i.recordAccess(process.AccessWrite, ident, operator, val)
+}
@@ -25,14 +25,14 @@ func TestRateLimiter(t *testing.T) {
 		withServer(t, vcl, func(ip *Interpreter) {
 			sendRequest(t, ip)
 			rc1 := ip.ctx.Ratecounters["rate_counter"]
-			rate1 := rc1.Rate("group", 1*time.Minute)
+			rate1 := rc1.Rate("group", 1*time.Minute, time.Now())
 			pb1 := ip.ctx.Penaltyboxes["p_box"]
-			pen1 := pb1.Has("group")
+			pen1 := pb1.Has("group", time.Now())
 			sendRequest(t, ip)
 			rc2 := ip.ctx.Ratecounters["rate_counter"]
-			rate2 := rc2.Rate("group", 1*time.Minute)
+			rate2 := rc2.Rate("group", 1*time.Minute, time.Now())
 			pb2 := ip.ctx.Penaltyboxes["p_box"]
-			pen2 := pb2.Has("group")
+			pen2 := pb2.Has("group", time.Now())
 			if rc1 != rc2 {
 				t.Errorf("rate_counter instance is not persisted between requests got %p, want %p", rc2, rc1)
 				t.FailNow()
@@ -0,0 +1,333 @@
+package interpreter
+
+import (
+	"bytes"
+	"io"
+	"net"
+	ghttp "net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/ysugimoto/falco/v2/ast"
+	"github.com/ysugimoto/falco/v2/interpreter/cache"
+	"github.com/ysugimoto/falco/v2/interpreter/http"
+	"github.com/ysugimoto/falco/v2/interpreter/value"
+	"github.com/ysugimoto/falco/v2/interpreter/variable"
+	"github.com/ysugimoto/falco/v2/repro"
+)
+
+// Snapshot captures the context state in order to reproduce the following execution.
+// Random number generator is reseeded with recorded seed so that random values are also reproducible.
+// The seed which is provided by testing.seed function is recorded as it is, new seed is generated only when it is not provided
+func (i *Interpreter) Snapshot() (*repro.Repro, error) {
+	seed := time.Now().UnixNano()
+	if provided := i.ctx.ProvidedSeed(); provided != nil {
+		seed = *provided
+	}
+	i.ctx.Reseed(seed)
+
+	r := &repro.Repro{
+		Version:   repro.Version,
+		Seed:      seed,
+		FixedTime: i.ctx.FixedTime,
+	}
+
+	var err error
+	if i.ctx.Request != nil {
+		if r.Request, err = snapshotRequest(i.ctx.Request); err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+	if i.ctx.BackendRequest != nil {
+		if r.BackendRequest, err = snapshotRequest(i.ctx.BackendRequest); err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+	if i.ctx.BackendResponse != nil {
+		if r.BackendResponse, err = snapshotResponse(i.ctx.BackendResponse); err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+	if i.ctx.Response != nil {
+		if r.Response, err = snapshotResponse(i.ctx.Response); err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+
+	for from, to := range i.ctx.MockedSubroutines {
+		r.Mocks = append(r.Mocks, &repro.Mock{From: from, To: to.Name.Value})
+	}
+	for from, to := range i.ctx.MockedFunctioncalSubroutines {
+		r.Mocks = append(r.Mocks, &repro.Mock{From: from, To: to.Name.Value, Functional: true})
+	}
+
+	r.LocalVariables = snapshotVariables(i.localVars)
+	r.OverrideVariables = snapshotVariables(i.ctx.OverrideVariables)
+	if r.Cache, err = snapshotCache(i.cache); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return r, nil
+}
+
+// Restore context state from the snapshot.
+// Mock subroutines are declared in testing VCL so the caller needs to provide lookup function for them
+func (i *Interpreter) Restore(r *repro.Repro, lookup func(name string) *ast.SubroutineDeclaration) error {
+	i.ctx.Seed(r.Seed)
+	i.ctx.FixedTime = r.FixedTime
+
+	var err error
+	if r.Request != nil {
+		if i.ctx.Request, err = restoreRequest(r.Request); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	if r.BackendRequest != nil {
+		if i.ctx.BackendRequest, err = restoreRequest(r.BackendRequest); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	if r.BackendResponse != nil {
+		i.ctx.BackendResponse = restoreResponse(r.BackendResponse)
+	}
+	if r.Response != nil {
+		i.ctx.Response = restoreResponse(r.Response)
+	}
+
+	for _, m := range r.Mocks {
+		sub := lookup(m.To)
+		if sub == nil {
+			return errors.Errorf("Mock subroutine %s is not found", m.To)
+		}
+		if m.Functional {
+			i.ctx.MockedFunctioncalSubroutines[m.From] = sub
+		} else {
+			i.ctx.MockedSubroutines[m.From] = sub
+		}
+	}
+
+	if len(r.LocalVariables) > 0 {
+		i.localVars = variable.LocalVariables{}
+	}
+	for name, v := range r.LocalVariables {
+		if i.localVars[name], err = i.restoreValue(v); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	for name, v := range r.OverrideVariables {
+		if i.ctx.OverrideVariables[name], err = i.restoreValue(v); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	for _, c := range r.Cache {
+		if c.Response == nil {
+			continue
+		}
+		i.cache.Set(c.Hash, &cache.CacheItem{
+			Response:      restoreResponse(c.Response),
+			RequestHeader: c.RequestHeader,
+			EntryTime:     c.EntryTime,
+			Expires:       c.Expires,
+			Hits:          c.Hits,
+		})
+	}
+
+	return nil
+}
+
+// Take snapshot of variables, the value which could not be restored from the string like ACL is skipped
+func snapshotVariables(vars map[string]value.Value) map[string]*repro.Variable {
+	if len(vars) == 0 {
+		return nil
+	}
+	ret := make(map[string]*repro.Variable)
+	for name, v := range vars {
+		if sv := snapshotValue(v); sv != nil {
+			ret[name] = sv
+		}
+	}
+	return ret
+}
+
+func snapshotValue(v value.Value) *repro.Variable {
+	sv := &repro.Variable{Type: string(v.Type())}
+	switch t := v.(type) {
+	case *value.String:
+		sv.Value, sv.NotSet = t.Value, t.IsNotSet
+	case *value.Integer:
+		sv.Value, sv.NotSet = strconv.FormatInt(t.Value, 10), t.IsNotSet
+	case *value.Float:
+		sv.Value, sv.NotSet = strconv.FormatFloat(t.Value, 'g', -1, 64), t.IsNotSet
+	case *value.Boolean:
+		sv.Value, sv.NotSet = strconv.FormatBool(t.Value), t.IsNotSet
+	case *value.RTime:
+		sv.Value, sv.NotSet = t.Value.String(), t.IsNotSet
+	case *value.Time:
+		sv.Value, sv.NotSet = t.Value.Format(time.RFC3339Nano), t.IsNotSet
+	case *value.IP:
+		if t.Value != nil {
+			sv.Value = t.Value.String()
+		}
+		sv.NotSet = t.IsNotSet
+	case *value.Backend:
+		// Backend is restored from the declaration of the same name
+		if t.Value == nil && t.Director == nil {
+			return nil
+		}
+		sv.Value = t.String()
+	default:
+		return nil
+	}
+	return sv
+}
+
+func (i *Interpreter) restoreValue(v *repro.Variable) (value.Value, error) {
+	switch value.Type(v.Type) {
+	case value.StringType:
+		return &value.String{Value: v.Value, IsNotSet: v.NotSet}, nil
+	case value.IntegerType:
+		n, err := strconv.ParseInt(v.Value, 10, 64)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		return &value.Integer{Value: n, IsNotSet: v.NotSet}, nil
+	case value.FloatType:
+		f, err := strconv.ParseFloat(v.Value, 64)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		return &value.Float{Value: f, IsNotSet: v.NotSet}, nil
+	case value.BooleanType:
+		b, err := strconv.ParseBool(v.Value)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		return &value.Boolean{Value: b, IsNotSet: v.NotSet}, nil
+	case value.RTimeType:
+		d, err := time.ParseDuration(v.Value)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		return &value.RTime{Value: d, IsNotSet: v.NotSet}, nil
+	case value.TimeType:
+		t, err := time.Parse(time.RFC3339Nano, v.Value)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		return &value.Time{Value: t, IsNotSet: v.NotSet}, nil
+	case value.IpType:
+		return &value.IP{Value: net.ParseIP(v.Value), IsNotSet: v.NotSet}, nil
+	case value.BackendType:
+		if b, ok := i.ctx.Backends[v.Value]; ok {
+			return b, nil
+		}
+		return nil, errors.Errorf("Backend %s is not found", v.Value)
+	}
+	return nil, errors.Errorf("Unsupported variable type %s", v.Type)
+}
+
+// Take snapshot of cached objects in order of the hash
+func snapshotCache(c *cache.Cache) ([]*repro.CacheItem, error) {
+	var items []*repro.CacheItem
+	var err error
+	c.Range(func(hash string, item *cache.CacheItem) bool {
+		if item.Response == nil {
+			return true
+		}
+		var resp *repro.HTTP
+		if resp, err = snapshotResponse(item.Response); err != nil {
+			return false
+		}
+		items = append(items, &repro.CacheItem{
+			Hash:          hash,
+			Response:      resp,
+			RequestHeader: item.RequestHeader,
+			EntryTime:     item.EntryTime,
+			Expires:       item.Expires,
+			Hits:          item.Hits,
+		})
+		return true
+	})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	sort.Slice(items, func(a, b int) bool {
+		return items[a].Hash < items[b].Hash
+	})
+	return items, nil
+}
+
+// Read body and rewind it for the following process
+func readBody(body io.ReadCloser) (string, io.ReadCloser, error) {
+	if body == nil || body == ghttp.NoBody {
+		return "", body, nil
+	}
+	var buf bytes.Buffer
+	if _, err := buf.ReadFrom(body); err != nil {
+		return "", nil, errors.WithStack(err)
+	}
+	return buf.String(), io.NopCloser(bytes.NewReader(buf.Bytes())), nil
+}
+
+func snapshotRequest(req *http.Request) (*repro.HTTP, error) {
+	body, rewound, err := readBody(req.Body)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	req.Body = rewound
+
+	return &repro.HTTP{
+		Method:     req.Method,
+		URL:        req.URL.String(),
+		RemoteAddr: req.RemoteAddr,
+		Header:     req.Header.Clone(),
+		Body:       body,
+	}, nil
+}
+
+func snapshotResponse(resp *http.Response) (*repro.HTTP, error) {
+	body, rewound, err := readBody(resp.Body)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	resp.Body = rewound
+
+	return &repro.HTTP{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header.Clone(),
+		Body:       body,
+	}, nil
+}
+
+func restoreRequest(h *repro.HTTP) (*http.Request, error) {
+	req, err := http.NewRequest(h.Method, h.URL, strings.NewReader(h.Body))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	req.RemoteAddr = h.RemoteAddr
+	if h.Header != nil {
+		req.Header = h.Header.Clone()
+	}
+	// Keep Host header as the request host like ProcessInit does
+	if host := req.Header.Get("Host"); host != "" {
+		req.Host = host
+	}
+	return req, nil
+}
+
+func restoreResponse(h *repro.HTTP) *http.Response {
+	header := ghttp.Header{}
+	if h.Header != nil {
+		header = h.Header.Clone()
+	}
+	return http.WrapResponse(&ghttp.Response{
+		StatusCode:    h.StatusCode,
+		Status:        ghttp.StatusText(h.StatusCode),
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(h.Body)),
+		ContentLength: int64(len(h.Body)),
+	})
+}
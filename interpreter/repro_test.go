@@ -0,0 +1,130 @@
+package interpreter
+
+import (
+	"math/rand"
+	ghttp "net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ysugimoto/falco/v2/interpreter/cache"
+	"github.com/ysugimoto/falco/v2/interpreter/context"
+	"github.com/ysugimoto/falco/v2/interpreter/http"
+	"github.com/ysugimoto/falco/v2/interpreter/value"
+	"github.com/ysugimoto/falco/v2/resolver"
+)
+
+func TestSnapshotAndRestore(t *testing.T) {
+	vcl := `
+backend origin {
+  .host = "example.com";
+}
+sub vcl_recv {
+  #FASTLY RECV
+}
+`
+	newInterpreter := func() *Interpreter {
+		ip := New(context.WithResolver(resolver.NewStaticResolver("main", vcl)))
+		if err := ip.TestProcessInit(http.WrapRequest(httptest.NewRequest("GET", "http://localhost", nil))); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		return ip
+	}
+
+	ip := newInterpreter()
+	ip.localVars["var.count"] = &value.Integer{Value: 10}
+	ip.localVars["var.ttl"] = &value.RTime{Value: 5 * time.Second}
+	ip.localVars["var.backend"] = ip.ctx.Backends["origin"]
+	ip.localVars["var.unset"] = &value.String{IsNotSet: true}
+	ip.ctx.OverrideVariables["client.geo.country_code"] = &value.String{Value: "JP"}
+	expires := time.Now().Add(time.Hour).Truncate(time.Second)
+	ip.cache.Set("hash", &cache.CacheItem{
+		Response: http.WrapResponse(&ghttp.Response{
+			StatusCode: ghttp.StatusOK,
+			Header:     ghttp.Header{"X-Cached": {"1"}},
+			Body:       ghttp.NoBody,
+		}),
+		EntryTime: expires.Add(-time.Hour),
+		Expires:   expires,
+		Hits:      2,
+	})
+
+	r, err := ip.Snapshot()
+	if err != nil {
+		t.Fatalf("Unexpected snapshot error: %s", err)
+	}
+
+	restored := newInterpreter()
+	if err := restored.Restore(r, nil); err != nil {
+		t.Fatalf("Unexpected restore error: %s", err)
+	}
+
+	if v, ok := restored.localVars["var.count"].(*value.Integer); !ok || v.Value != 10 {
+		t.Errorf("Local INTEGER variable is not restored, got %v", restored.localVars["var.count"])
+	}
+	if v, ok := restored.localVars["var.ttl"].(*value.RTime); !ok || v.Value != 5*time.Second {
+		t.Errorf("Local RTIME variable is not restored, got %v", restored.localVars["var.ttl"])
+	}
+	if v, ok := restored.localVars["var.backend"].(*value.Backend); !ok || v != restored.ctx.Backends["origin"] {
+		t.Errorf("Local BACKEND variable is not restored, got %v", restored.localVars["var.backend"])
+	}
+	if v, ok := restored.localVars["var.unset"].(*value.String); !ok || !v.IsNotSet {
+		t.Errorf("Local not set STRING variable is not restored, got %v", restored.localVars["var.unset"])
+	}
+	if v, ok := restored.ctx.OverrideVariables["client.geo.country_code"].(*value.String); !ok || v.Value != "JP" {
+		t.Errorf("Override variable is not restored, got %v", restored.ctx.OverrideVariables["client.geo.country_code"])
+	}
+	item := restored.cache.Get("hash", expires.Add(-time.Minute))
+	if item == nil {
+		t.Fatalf("Cached object is not restored")
+	}
+	if item.Hits != 3 || !item.Expires.Equal(expires) || item.Response.Header.Get("X-Cached") != "1" {
+		t.Errorf("Cached object mismatch, hits=%d, expires=%s, header=%v", item.Hits, item.Expires, item.Response.Header)
+	}
+}
+
+func TestSnapshotSeed(t *testing.T) {
+	vcl := `
+sub vcl_recv {
+  #FASTLY RECV
+}
+`
+	t.Run("provided seed is recorded", func(t *testing.T) {
+		ip := New(context.WithResolver(resolver.NewStaticResolver("main", vcl)), context.WithSeed(42))
+		if err := ip.TestProcessInit(http.WrapRequest(httptest.NewRequest("GET", "http://localhost", nil))); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		// Advance the generator like random functions in before_all hook
+		ip.ctx.Rand().Int63()
+
+		r, err := ip.Snapshot()
+		if err != nil {
+			t.Fatalf("Unexpected snapshot error: %s", err)
+		}
+		if r.Seed != 42 {
+			t.Errorf("Provided seed must be recorded, expect=42, actual=%d", r.Seed)
+		}
+		expect := rand.New(rand.NewSource(42)).Int63() // nolint: gosec
+		if actual := ip.ctx.Rand().Int63(); actual != expect {
+			t.Errorf("Random number generator must be reseeded, expect=%d, actual=%d", expect, actual)
+		}
+	})
+
+	t.Run("seed is generated when not provided", func(t *testing.T) {
+		ip := New(context.WithResolver(resolver.NewStaticResolver("main", vcl)))
+		if err := ip.TestProcessInit(http.WrapRequest(httptest.NewRequest("GET", "http://localhost", nil))); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		r, err := ip.Snapshot()
+		if err != nil {
+			t.Fatalf("Unexpected snapshot error: %s", err)
+		}
+		expect := rand.New(rand.NewSource(r.Seed)).Int63() // nolint: gosec
+		if actual := ip.ctx.Rand().Int63(); actual != expect {
+			t.Errorf("Random number generator must be reseeded, expect=%d, actual=%d", expect, actual)
+		}
+		if ip.ctx.ProvidedSeed() != nil {
+			t.Errorf("Generated seed must not be treated as provided one")
+		}
+	})
+}
@@ -0,0 +1,138 @@
+package interpreter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/ysugimoto/falco/v2/ast"
+	"github.com/ysugimoto/falco/v2/interpreter/exception"
+	"github.com/ysugimoto/falco/v2/interpreter/value"
+	"github.com/ysugimoto/falco/v2/lexer"
+	"github.com/ysugimoto/falco/v2/parser"
+)
+
+const resourceFileName = "Resource.Injected"
+
+// Apply tables, ACLs and edge dictionaries which are loaded from external resource files.
+// Resource files take precedence over the declarations in VCL because they are updated independently of VCL deployment
+func (i *Interpreter) applyResources() error {
+	if i.ctx.Resources == nil {
+		return nil
+	}
+
+	for name, dict := range i.ctx.Resources.EdgeDictionaries() {
+		if v, ok := i.ctx.Tables[name]; ok {
+			if v.ValueType != nil && v.ValueType.Value != "STRING" {
+				return exception.System("EdgeDictionary injection error: %s value type is not STRING", v.Name.Value)
+			}
+			i.InjectEdgeDictionaryItem(v, dict)
+		} else {
+			i.ctx.Tables[name] = i.createEdgeDictionaryDeclaration(name, dict)
+		}
+	}
+
+	for name, items := range i.ctx.Resources.Tables() {
+		valueType := "STRING"
+		if v, ok := i.ctx.Tables[name]; ok && v.ValueType != nil {
+			valueType = v.ValueType.Value
+		}
+		table, err := i.createResourceTable(name, valueType, items)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		i.ctx.Tables[name] = table
+	}
+
+	for name, entries := range i.ctx.Resources.Acls() {
+		acl, err := createResourceAcl(name, entries)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		i.ctx.Acls[name] = &value.Acl{Value: acl, Literal: true}
+	}
+	return nil
+}
+
+// Create table declaration from resource file items.
+// STRING values are injected as it is, and other typed values are parsed as VCL literal
+func (i *Interpreter) createResourceTable(name, valueType string, items map[string]any) (*ast.TableDeclaration, error) {
+	if valueType == "STRING" {
+		dict := make(map[string]string, len(items))
+		for key, val := range items {
+			dict[key] = fmt.Sprint(val)
+		}
+		return i.createEdgeDictionaryDeclaration(name, dict), nil
+	}
+
+	keys := make([]string, 0, len(items))
+	for key := range items {
+		if strings.Contains(key, `"`) {
+			return nil, exception.System("Resource table %s has invalid key %s", name, key)
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "table %s %s {\n", name, valueType)
+	for _, key := range keys {
+		fmt.Fprintf(&buf, "  \"%s\": %v,\n", key, items[key])
+	}
+	buf.WriteString("}\n")
+
+	decl, err := parseResourceDeclaration(buf.String())
+	if err != nil {
+		return nil, exception.System("Resource table %s parse error: %s", name, err)
+	}
+	table, ok := decl.(*ast.TableDeclaration)
+	if !ok {
+		return nil, exception.System("Resource table %s is not a table declaration", name)
+	}
+	return table, nil
+}
+
+// Create ACL declaration from resource file entries like "192.0.2.0/24" or "!192.0.2.1"
+func createResourceAcl(name string, entries []string) (*ast.AclDeclaration, error) {
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "acl %s {\n", name)
+	for _, entry := range entries {
+		var inverse string
+		if strings.HasPrefix(entry, "!") {
+			inverse = "!"
+			entry = strings.TrimPrefix(entry, "!")
+		}
+		ip, mask, hasMask := strings.Cut(strings.TrimSpace(entry), "/")
+		if strings.Contains(ip, `"`) {
+			return nil, exception.System("Resource ACL %s has invalid entry %s", name, entry)
+		}
+		if hasMask {
+			fmt.Fprintf(&buf, "  %s\"%s\"/%s;\n", inverse, ip, mask)
+		} else {
+			fmt.Fprintf(&buf, "  %s\"%s\";\n", inverse, ip)
+		}
+	}
+	buf.WriteString("}\n")
+
+	decl, err := parseResourceDeclaration(buf.String())
+	if err != nil {
+		return nil, exception.System("Resource ACL %s parse error: %s", name, err)
+	}
+	acl, ok := decl.(*ast.AclDeclaration)
+	if !ok {
+		return nil, exception.System("Resource ACL %s is not an ACL declaration", name)
+	}
+	return acl, nil
+}
+
+func parseResourceDeclaration(src string) (ast.Statement, error) {
+	vcl, err := parser.New(lexer.NewFromString(src, lexer.WithFile(resourceFileName))).ParseVCL()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if len(vcl.Statements) != 1 {
+		return nil, errors.New("unexpected declarations")
+	}
+	return vcl.Statements[0], nil
+}
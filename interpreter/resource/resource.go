@@ -0,0 +1,161 @@
+package resource
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"maps"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/pkg/errors"
+	"github.com/ysugimoto/falco/v2/config"
+)
+
+// Store holds tables, ACLs and edge dictionaries which are loaded from external files.
+// The interpreter reads them on each request so the updated files are reflected without restarting simulator,
+// mirroring that Fastly could update them independently of VCL deployment
+type Store struct {
+	files *config.ResourceFilesConfig
+
+	mu           sync.RWMutex
+	tables       map[string]map[string]any
+	acls         map[string][]string
+	dictionaries map[string]config.EdgeDictionary
+}
+
+// New creates store and loads all resource files
+func New(files *config.ResourceFilesConfig) (*Store, error) {
+	s := &Store{
+		files: files,
+	}
+	if err := s.Load(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return s, nil
+}
+
+// Load reads all resource files. If some file has an error, previous loaded values are kept
+func (s *Store) Load() error {
+	tables := make(map[string]map[string]any)
+	for name, file := range s.files.Tables {
+		var items map[string]any
+		if err := readJSON(file, &items); err != nil {
+			return errors.Wrapf(err, "failed to load table %s", name)
+		}
+		tables[name] = items
+	}
+
+	acls := make(map[string][]string)
+	for name, file := range s.files.Acls {
+		var entries []string
+		if err := readJSON(file, &entries); err != nil {
+			return errors.Wrapf(err, "failed to load ACL %s", name)
+		}
+		acls[name] = entries
+	}
+
+	dictionaries := make(map[string]config.EdgeDictionary)
+	for name, file := range s.files.EdgeDictionaries {
+		var items config.EdgeDictionary
+		if err := readJSON(file, &items); err != nil {
+			return errors.Wrapf(err, "failed to load edge dictionary %s", name)
+		}
+		dictionaries[name] = items
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tables = tables
+	s.acls = acls
+	s.dictionaries = dictionaries
+	return nil
+}
+
+// Table items are decoded as json.Number for numeric values in order to keep the original representation
+func readJSON(file string, v any) error {
+	buf, err := os.ReadFile(file)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(buf))
+	dec.UseNumber()
+	if err := dec.Decode(v); err != nil {
+		return errors.Wrapf(err, "invalid JSON in %s", file)
+	}
+	return nil
+}
+
+func (s *Store) Tables() map[string]map[string]any {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return maps.Clone(s.tables)
+}
+
+func (s *Store) Acls() map[string][]string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return maps.Clone(s.acls)
+}
+
+func (s *Store) EdgeDictionaries() map[string]config.EdgeDictionary {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return maps.Clone(s.dictionaries)
+}
+
+// Files returns absolute paths of all resource files
+func (s *Store) Files() []string {
+	var files []string
+	for _, m := range []map[string]string{s.files.Tables, s.files.Acls, s.files.EdgeDictionaries} {
+		for _, file := range m {
+			if abs, err := filepath.Abs(file); err == nil {
+				files = append(files, abs)
+			}
+		}
+	}
+	return files
+}
+
+// Watch reloads resource files when some of them is changed until the context is canceled.
+// Parent directories are watched because editors may replace the file by renaming
+func (s *Store) Watch(ctx context.Context, onReload func(file string, err error)) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer watcher.Close()
+
+	files := make(map[string]struct{})
+	for _, file := range s.Files() {
+		files[file] = struct{}{}
+		if err := watcher.Add(filepath.Dir(file)); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if _, ok := files[event.Name]; !ok {
+				continue
+			}
+			if !event.Has(fsnotify.Write) && !event.Has(fsnotify.Create) && !event.Has(fsnotify.Rename) {
+				continue
+			}
+			onReload(event.Name, s.Load())
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			return errors.WithStack(err)
+		}
+	}
+}
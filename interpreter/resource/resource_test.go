@@ -0,0 +1,70 @@
+package resource
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ysugimoto/falco/v2/config"
+)
+
+func writeJSON(t *testing.T, file string, v any) {
+	t.Helper()
+	buf, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("Unexpected marshal error: %s", err)
+	}
+	if err := os.WriteFile(file, buf, 0o644); err != nil {
+		t.Fatalf("Unexpected write error: %s", err)
+	}
+}
+
+func TestStoreLoad(t *testing.T) {
+	dir := t.TempDir()
+	tableFile := filepath.Join(dir, "table.json")
+	aclFile := filepath.Join(dir, "acl.json")
+	dictFile := filepath.Join(dir, "dict.json")
+	writeJSON(t, tableFile, map[string]any{"foo": 10})
+	writeJSON(t, aclFile, []string{"192.0.2.0/24", "!192.0.2.1"})
+	writeJSON(t, dictFile, map[string]string{"key": "value"})
+
+	s, err := New(&config.ResourceFilesConfig{
+		Tables:           map[string]string{"example_table": tableFile},
+		Acls:             map[string]string{"example_acl": aclFile},
+		EdgeDictionaries: map[string]string{"example_dict": dictFile},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected load error: %s", err)
+	}
+
+	if v := s.Tables()["example_table"]["foo"]; v != json.Number("10") {
+		t.Errorf("Unexpected table value: %v", v)
+	}
+	if v := s.Acls()["example_acl"]; len(v) != 2 || v[1] != "!192.0.2.1" {
+		t.Errorf("Unexpected ACL entries: %v", v)
+	}
+	if v := s.EdgeDictionaries()["example_dict"]["key"]; v != "value" {
+		t.Errorf("Unexpected edge dictionary value: %s", v)
+	}
+
+	// Reload updated file
+	writeJSON(t, dictFile, map[string]string{"key": "updated"})
+	if err := s.Load(); err != nil {
+		t.Fatalf("Unexpected reload error: %s", err)
+	}
+	if v := s.EdgeDictionaries()["example_dict"]["key"]; v != "updated" {
+		t.Errorf("Edge dictionary is not reloaded: %s", v)
+	}
+
+	// Invalid file keeps previous values
+	if err := os.WriteFile(dictFile, []byte("{"), 0o644); err != nil {
+		t.Fatalf("Unexpected write error: %s", err)
+	}
+	if err := s.Load(); err == nil {
+		t.Errorf("Expected reload error but nil")
+	}
+	if v := s.EdgeDictionaries()["example_dict"]["key"]; v != "updated" {
+		t.Errorf("Previous edge dictionary value should be kept: %s", v)
+	}
+}
@@ -0,0 +1,43 @@
+package interpreter
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestCreateResourceTable(t *testing.T) {
+	ip := New()
+	table, err := ip.createResourceTable("example", "INTEGER", map[string]any{
+		"foo": json.Number("10"),
+		"bar": json.Number("20"),
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if len(table.Properties) != 2 {
+		t.Fatalf("Expected 2 properties but got %d", len(table.Properties))
+	}
+	if v := table.Properties[0].Key.Value; v != "bar" {
+		t.Errorf("Unexpected first key: %s", v)
+	}
+
+	if _, err := ip.createResourceTable("example", "INTEGER", map[string]any{"foo": "not integer"}); err == nil {
+		t.Errorf("Expected parse error but nil")
+	}
+}
+
+func TestCreateResourceAcl(t *testing.T) {
+	acl, err := createResourceAcl("example", []string{"192.0.2.0/24", "!192.0.2.1"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if len(acl.CIDRs) != 2 {
+		t.Fatalf("Expected 2 entries but got %d", len(acl.CIDRs))
+	}
+	if acl.CIDRs[0].Mask == nil || acl.CIDRs[0].Mask.Value != 24 {
+		t.Errorf("Unexpected mask of first entry")
+	}
+	if acl.CIDRs[1].Inverse == nil || !acl.CIDRs[1].Inverse.Value {
+		t.Errorf("Second entry should be inverse")
+	}
+}
@@ -0,0 +1,62 @@
+package interpreter
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/ysugimoto/falco/v2/ast"
+	"github.com/ysugimoto/falco/v2/interpreter/exception"
+	fe "github.com/ysugimoto/falco/v2/interpreter/function/errors"
+	"github.com/ysugimoto/falco/v2/token"
+)
+
+// VCL source which is loaded by the interpreter to render excerpts of exceptions
+type vclSource struct {
+	data     string
+	includes []string
+}
+
+// AddSource registers the VCL source which is not loaded via the resolver like testing VCL,
+// so that exceptions which occur in the source are rendered with the excerpt
+func (i *Interpreter) AddSource(name, data string) {
+	i.addSource(name, data, nil)
+}
+
+// Register the source with the include statement token which includes it
+func (i *Interpreter) addSource(name, data string, includer *token.Token) {
+	if i.sources == nil {
+		i.sources = make(map[string]*vclSource)
+	}
+	src := &vclSource{data: data}
+	if includer != nil {
+		if parent, ok := i.sources[includer.File]; ok {
+			src.includes = append(src.includes, parent.includes...)
+		}
+		src.includes = append(src.includes, fmt.Sprintf("%s:%d", includer.File, includer.Line))
+	}
+	i.sources[name] = src
+}
+
+// attachExcerpt attaches the source excerpt to the exception if the source of the token is registered.
+// The error is returned as it is to be used like "return i.attachExcerpt(err)"
+func (i *Interpreter) attachExcerpt(err error) error {
+	e, ok := errors.Cause(err).(*exception.Exception)
+	if !ok || e.Token == nil || e.Excerpt != nil {
+		return err
+	}
+	if src, ok := i.sources[e.Token.File]; ok {
+		e.Excerpt = exception.NewExcerpt(src.data, e.Token, src.includes)
+	}
+	return err
+}
+
+// locateError converts the error which does not have its location like undefined variable or argument count mismatch
+// to the runtime exception at the statement, so that the excerpt could be attached to it.
+// Exceptions and testing errors which already have the location are returned as they are
+func locateError(stmt ast.Statement, err error) error {
+	switch errors.Cause(err).(type) {
+	case *exception.Exception, *fe.TestingError, *fe.AssertionError, *fe.AssertionErrors:
+		return err
+	}
+	return exception.Runtime(&stmt.GetMeta().Token, "%s", err.Error())
+}
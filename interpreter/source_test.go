@@ -0,0 +1,91 @@
+package interpreter
+
+import (
+	ghttp "net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/ysugimoto/falco/v2/interpreter/context"
+	"github.com/ysugimoto/falco/v2/interpreter/exception"
+	"github.com/ysugimoto/falco/v2/interpreter/http"
+	"github.com/ysugimoto/falco/v2/resolver"
+)
+
+func TestAttachExcerpt(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"main.vcl": `include "module";
+
+sub vcl_recv {
+  #FASTLY recv
+  call module_recv;
+  return(lookup);
+}
+`,
+		"module.vcl": `sub module_recv {
+  set req.http.Foo = "foo";
+  call module_auth;
+}
+`,
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o644); err != nil {
+			t.Fatalf("Failed to write %s: %s", name, err)
+		}
+	}
+	resolvers, err := resolver.NewFileResolvers(filepath.Join(dir, "main.vcl"), []string{dir})
+	if err != nil {
+		t.Fatalf("Unexpected resolver error: %s", err)
+	}
+
+	ip := New(context.WithResolver(resolvers[0]))
+	req := httptest.NewRequest(ghttp.MethodGet, "http://localhost", nil)
+	if err := ip.ProcessInit(http.WrapRequest(req)); err != nil {
+		t.Fatalf("Unexpected init error: %s", err)
+	}
+	err = ip.attachExcerpt(ip.ProcessRecv())
+	e, ok := errors.Cause(err).(*exception.Exception)
+	if !ok {
+		t.Fatalf("Expected exception but got %v", err)
+	}
+	if e.Excerpt == nil {
+		t.Fatalf("Excerpt should be attached")
+	}
+	if e.Excerpt.Line != 3 || !strings.HasSuffix(e.Excerpt.File, "module.vcl") {
+		t.Errorf("Unexpected excerpt position, file=%s, line=%d", e.Excerpt.File, e.Excerpt.Line)
+	}
+	if len(e.Excerpt.Includes) != 1 || !strings.HasSuffix(e.Excerpt.Includes[0], "main.vcl:1") {
+		t.Errorf("Unexpected include chain: %v", e.Excerpt.Includes)
+	}
+	if !strings.Contains(e.Pretty(), "call module_auth;\n") {
+		t.Errorf("Pretty output should contain the source line, got=%s", e.Pretty())
+	}
+}
+
+func TestLocateError(t *testing.T) {
+	vcl := `sub vcl_recv {
+  #FASTLY recv
+  set req.http.Foo = var.undefined;
+}
+`
+	ip := New(context.WithResolver(resolver.NewStaticResolver("main", vcl)))
+	req := httptest.NewRequest(ghttp.MethodGet, "http://localhost", nil)
+	if err := ip.ProcessInit(http.WrapRequest(req)); err != nil {
+		t.Fatalf("Unexpected init error: %s", err)
+	}
+	err := ip.attachExcerpt(ip.ProcessRecv())
+	e, ok := errors.Cause(err).(*exception.Exception)
+	if !ok {
+		t.Fatalf("Expected exception but got %v", err)
+	}
+	if e.Token == nil || e.Token.Line != 3 {
+		t.Errorf("Exception should be located at the statement, got=%v", e.Token)
+	}
+	if e.Excerpt == nil {
+		t.Errorf("Excerpt should be attached")
+	}
+}
@@ -2,6 +2,8 @@ package interpreter
 
 import (
 	"io"
+	"maps"
+	"slices"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -9,7 +11,6 @@ import (
 	"github.com/ysugimoto/falco/v2/interpreter/assign"
 	"github.com/ysugimoto/falco/v2/interpreter/context"
 	"github.com/ysugimoto/falco/v2/interpreter/exception"
-	"github.com/ysugimoto/falco/v2/interpreter/function"
 	fe "github.com/ysugimoto/falco/v2/interpreter/function/errors"
 	"github.com/ysugimoto/falco/v2/interpreter/limitations"
 	"github.com/ysugimoto/falco/v2/interpreter/operator"
@@ -39,10 +40,18 @@ func (i *Interpreter) ProcessBlockStatement(
 	var debugState = ds
 
 	for _, stmt := range statements {
+		// Stop processing when the client request is cancelled or its deadline is exceeded
+		if i.ctx.Request != nil {
+			if err := i.ctx.Request.Context().Err(); err != nil {
+				return value.Null, NONE, debugState, exception.Runtime(&stmt.GetMeta().Token, "Request is cancelled: %s", err)
+			}
+		}
+
 		// Call debugger
 		if debugState != DebugStepOut {
 			debugState = i.Debugger.Run(stmt)
 		}
+		i.recordStep(stmt)
 
 		// Find process marker and add flow if found
 		if name, found := findProcessMark(stmt.GetMeta().Leading); found {
@@ -52,6 +61,15 @@ func (i *Interpreter) ProcessBlockStatement(
 			)
 		}
 
+		// Find latency budget marker and record the route which the request enters
+		if spec, found := findBudgetMark(stmt.GetMeta().Leading); found {
+			budget, err := process.ParseBudget(spec, stmt.GetMeta().Token)
+			if err != nil {
+				return value.Null, NONE, debugState, exception.Runtime(&stmt.GetMeta().Token, "Invalid latency budget annotation: %s", err)
+			}
+			i.process.Budgets = append(i.process.Budgets, budget)
+		}
+
 		switch t := stmt.(type) {
 		// Common logic statements (nothing to change state)
 		case *ast.DeclareStatement:
@@ -156,6 +174,7 @@ func (i *Interpreter) ProcessBlockStatement(
 			}
 
 			// restart statement force change state to RESTART
+			i.lastTransition = t
 			return value.Null, RESTART, DebugPass, nil
 
 		case *ast.ReturnStatement:
@@ -170,6 +189,7 @@ func (i *Interpreter) ProcessBlockStatement(
 			// When return statement is processed, return its state immediately
 			state := i.ProcessReturnStatement(t)
 			i.ctx.ReturnStatementCalled = true
+			i.lastTransition = t
 			return value.Null, state, DebugPass, nil
 
 		case *ast.ErrorStatement:
@@ -180,6 +200,7 @@ func (i *Interpreter) ProcessBlockStatement(
 			}
 
 			// restart statement force change state to ERROR
+			i.lastTransition = t
 			if err := i.ProcessErrorStatement(t); err != nil {
 				return value.Null, ERROR, DebugPass, errors.WithStack(err)
 			}
@@ -207,7 +228,7 @@ func (i *Interpreter) ProcessBlockStatement(
 			}
 		}
 		if err != nil {
-			return value.Null, INTERNAL_ERROR, DebugPass, errors.WithStack(err)
+			return value.Null, INTERNAL_ERROR, DebugPass, errors.WithStack(locateError(stmt, err))
 		}
 	}
 	return value.Null, NONE, DebugPass, nil
@@ -228,6 +249,7 @@ func (i *Interpreter) ProcessDeclareStatement(stmt *ast.DeclareStatement) error
 		if err = i.localVars.Set(stmt.Name.Value, "=", v); err != nil {
 			return errors.WithStack(err)
 		}
+		i.recordWrite(stmt.Name, "=")
 	}
 	return nil
 }
@@ -265,10 +287,14 @@ func (i *Interpreter) ProcessSetStatement(stmt *ast.SetStatement) error {
 	if err != nil {
 		return errors.WithStack(err)
 	}
+	if err := i.checkStrictRTime(stmt, left, right); err != nil {
+		return errors.WithStack(err)
+	}
 
 	if err := i.vars.Set(i.ctx.Scope, stmt.Ident.Value, stmt.Operator.Operator, right); err != nil {
 		return errors.WithStack(err)
 	}
+	i.recordWrite(stmt.Ident, stmt.Operator.Operator)
 
 	// A `set` assembles the full new header value, which for a compound operator
 	// such as `+=` differs from the right-hand side, so charge the stored value.
@@ -333,14 +359,36 @@ func (i *Interpreter) ProcessSetStatementLocalVariable(stmt *ast.SetStatement) e
 	if err != nil {
 		return errors.WithStack(err)
 	}
+	if err := i.checkStrictRTime(stmt, left, right); err != nil {
+		return errors.WithStack(err)
+	}
 
 	if err := i.localVars.Set(stmt.Ident.Value, stmt.Operator.Operator, right); err != nil {
 		return errors.WithStack(err)
 	}
+	i.recordWrite(stmt.Ident, stmt.Operator.Operator)
 
 	return nil
 }
 
+// checkStrictRTime rejects assigning unit-less INTEGER or FLOAT value to RTIME variable on strict mode.
+// Literals are always rejected but Fastly implicitly treats INTEGER variable as seconds,
+// so "set beresp.ttl = var.seconds;" silently works and it is easy to be confused with milliseconds.
+func (i *Interpreter) checkStrictRTime(stmt *ast.SetStatement, left, right value.Value) error {
+	if !i.ctx.StrictRTime || stmt.Operator.Operator != "=" || left.Type() != value.RTimeType {
+		return nil
+	}
+	switch right.Type() {
+	case value.IntegerType, value.FloatType:
+		return exception.Runtime(
+			&stmt.GetMeta().Token,
+			"Unit-less %s value could not be assigned to RTIME variable %s on strict mode",
+			right.Type(), stmt.Ident.Value,
+		)
+	}
+	return nil
+}
+
 func (i *Interpreter) ProcessAddStatement(stmt *ast.AddStatement) error {
 	// Add statement could use only for HTTP headers.
 	// https://developer.fastly.com/reference/vcl/statements/add/
@@ -367,6 +415,7 @@ func (i *Interpreter) ProcessAddStatement(stmt *ast.AddStatement) error {
 	if err := i.vars.Add(i.ctx.Scope, stmt.Ident.Value, right); err != nil {
 		return exception.Runtime(&stmt.GetMeta().Token, "%s", err.Error())
 	}
+	i.recordAccess(process.AccessWrite, stmt.Ident, "add", right)
 	// `add` appends a fresh header line, so charge the value being added.
 	if isRequestHeaderIdent(stmt.Ident) {
 		if err := i.accountRequestWorkspace(stmt.Ident, right); err != nil {
@@ -387,6 +436,7 @@ func (i *Interpreter) ProcessUnsetStatement(stmt *ast.UnsetStatement) error {
 	if err != nil {
 		return exception.Runtime(&stmt.GetMeta().Token, "%s", err.Error())
 	}
+	i.recordAccess(process.AccessUnset, stmt.Ident, "", value.Null)
 	return nil
 }
 
@@ -402,6 +452,7 @@ func (i *Interpreter) ProcessRemoveStatement(stmt *ast.RemoveStatement) error {
 	if err != nil {
 		return exception.Runtime(&stmt.GetMeta().Token, "%s", err.Error())
 	}
+	i.recordAccess(process.AccessUnset, stmt.Ident, "", value.Null)
 	return nil
 }
 
@@ -442,8 +493,8 @@ func (i *Interpreter) ProcessCallStatement(stmt *ast.CallStatement, ds DebugStat
 	} else {
 		return NONE, exception.Runtime(
 			&stmt.GetMeta().Token,
-			"Calling subroutine %s is not defined",
-			name,
+			"Calling subroutine %s is not defined%s",
+			name, didYouMean(name, slices.Collect(maps.Keys(i.ctx.Subroutines))),
 		)
 	}
 	if state == BARE_RETURN {
@@ -507,7 +558,9 @@ func (i *Interpreter) ProcessLogStatement(stmt *ast.LogStatement) error {
 		)
 	}
 
-	i.process.Logs = append(i.process.Logs, process.NewLog(stmt, i.ctx.Scope, line))
+	l := process.NewLog(stmt, i.ctx.Scope, line)
+	l.RequestID = i.ctx.CorrelationID
+	i.process.Logs = append(i.process.Logs, l)
 	i.Debugger.Log(stmt, line)
 	return nil
 }
@@ -549,9 +602,12 @@ func (i *Interpreter) ProcessFunctionCallStatement(stmt *ast.FunctionCallStateme
 	}
 
 	// Builtin function will not change any state
-	fn, err := function.Exists(i.ctx.Scope, stmt.Function.Value)
+	fn, err := i.findFunction(stmt.Function.Value)
 	if err != nil {
-		return NONE, exception.Runtime(&stmt.GetMeta().Token, "%s", err.Error())
+		if _, err := i.unimplementedFunction(stmt.Function.Value, err); err != nil {
+			return NONE, exception.Runtime(&stmt.GetMeta().Token, "%s", err.Error())
+		}
+		return NONE, nil
 	}
 	// Check the function can call in statement (means a function that returns VOID type can call)
 	if !fn.CanStatementCall {
@@ -593,10 +649,18 @@ func (i *Interpreter) ProcessFunctionCallStatement(stmt *ast.FunctionCallStateme
 		switch t := err.(type) {
 		case *fe.AssertionError:
 			t.Token = stmt.GetMeta().Token
+			// Continue the test case in soft assertion mode, failures are reported at the end of the testing subroutine
+			if i.ctx.SoftAssertions {
+				i.ctx.AssertionFailures = append(i.ctx.AssertionFailures, t)
+				return NONE, nil
+			}
 			return NONE, errors.WithStack(t)
 		case *fe.TestingError:
 			t.Token = stmt.GetMeta().Token
 			return NONE, errors.WithStack(t)
+		case *exception.Exception:
+			// Exception which occurs in the subroutine called by testing.call_subroutine already has its location
+			return NONE, errors.WithStack(t)
 		default:
 			return NONE, exception.Runtime(&stmt.GetMeta().Token, "%s", err.Error())
 		}
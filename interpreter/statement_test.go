@@ -259,6 +259,42 @@ func TestSetStatement(t *testing.T) {
 	}
 }
 
+func TestSetStatementStrictRTime(t *testing.T) {
+	tests := []struct {
+		name    string
+		strict  bool
+		value   ast.Expression
+		isError bool
+	}{
+		{name: "integer is allowed by default", value: &ast.Ident{Value: "var.seconds"}},
+		{name: "rtime is allowed on strict mode", strict: true, value: &ast.RTime{Value: "3600s"}},
+		{name: "integer is rejected on strict mode", strict: true, value: &ast.Ident{Value: "var.seconds"}, isError: true},
+		{name: "float is rejected on strict mode", strict: true, value: &ast.Ident{Value: "var.ratio"}, isError: true},
+	}
+
+	for _, tt := range tests {
+		ip := New(nil)
+		for name, typ := range map[string]string{"var.ttl": "RTIME", "var.seconds": "INTEGER", "var.ratio": "FLOAT"} {
+			if err := ip.localVars.Declare(name, typ); err != nil {
+				t.Errorf("%s: unexpected error returned: %s", tt.name, err)
+			}
+		}
+		ip.ctx = context.New(context.WithStrictRTime(tt.strict))
+		ip.SetScope(context.RecvScope)
+		err := ip.ProcessSetStatement(&ast.SetStatement{
+			Meta:     &ast.Meta{},
+			Ident:    &ast.Ident{Value: "var.ttl"},
+			Operator: &ast.Operator{Operator: "="},
+			Value:    tt.value,
+		})
+		if tt.isError && err == nil {
+			t.Errorf("%s: expected error but got nil", tt.name)
+		} else if !tt.isError && err != nil {
+			t.Errorf("%s: unexpected error returned: %s", tt.name, err)
+		}
+	}
+}
+
 func TestBlockStatement(t *testing.T) {
 	tests := []struct {
 		name           string
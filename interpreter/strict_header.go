@@ -0,0 +1,96 @@
+package interpreter
+
+import (
+	"path"
+	"strings"
+
+	"github.com/ysugimoto/falco/v2/ast"
+	"github.com/ysugimoto/falco/v2/interpreter/exception"
+	"github.com/ysugimoto/falco/v2/interpreter/value"
+)
+
+// CollectHeaders registers headers which are set or added in the statements like testing VCL,
+// so that strict headers mode does not reject reading them
+func (i *Interpreter) CollectHeaders(statements []ast.Statement) {
+	if i.knownHeaders == nil {
+		i.knownHeaders = make(map[string]struct{})
+	}
+	for _, stmt := range statements {
+		switch t := stmt.(type) {
+		case *ast.SetStatement:
+			i.addKnownHeader(t.Ident.Value)
+		case *ast.AddStatement:
+			i.addKnownHeader(t.Ident.Value)
+		case *ast.FunctionCallStatement:
+			// header.set(req, "X-Foo", "value") also sets the header if the name is literal
+			if t.Function.Value == "header.set" && len(t.Arguments) > 1 {
+				if name, ok := t.Arguments[1].(*ast.String); ok {
+					i.knownHeaders[strings.ToLower(name.Value)] = struct{}{}
+				}
+			}
+		case *ast.BlockStatement:
+			i.CollectHeaders(t.Statements)
+		case *ast.IfStatement:
+			i.CollectHeaders(t.Consequence.Statements)
+			for _, another := range t.Another {
+				i.CollectHeaders(another.Consequence.Statements)
+			}
+			if t.Alternative != nil {
+				i.CollectHeaders(t.Alternative.Consequence.Statements)
+			}
+		case *ast.SwitchStatement:
+			for _, c := range t.Cases {
+				i.CollectHeaders(c.Statements)
+			}
+		}
+	}
+}
+
+// Header is known regardless of the prefix because it is propagated like req.http.* to bereq.http.*
+func (i *Interpreter) addKnownHeader(name string) {
+	if header, ok := headerName(name); ok {
+		i.knownHeaders[header] = struct{}{}
+	}
+}
+
+// checkStrictHeader rejects reading the header which is not present and never set anywhere in VCL or testing fixtures.
+// Reading such a header silently evaluates as not set, so that typos like req.http.X-Forwared-For are hard to notice
+func (i *Interpreter) checkStrictHeader(ident *ast.Ident, v value.Value) error {
+	if !i.ctx.StrictHeaders {
+		return nil
+	}
+	header, ok := headerName(ident.Value)
+	if !ok {
+		return nil
+	}
+	if s, ok := v.(*value.String); !ok || !s.IsNotSet {
+		return nil
+	}
+	if _, ok := i.knownHeaders[header]; ok {
+		return nil
+	}
+	for _, pattern := range i.ctx.ExternalHeaders {
+		if matched, _ := path.Match(strings.ToLower(pattern), header); matched {
+			return nil
+		}
+	}
+	return exception.Runtime(
+		&ident.GetMeta().Token,
+		"Header %s is read but never set in VCL or testing fixtures on strict headers mode. "+
+			"Add it to external_headers if the header is provided externally",
+		ident.Value,
+	)
+}
+
+// Extract lower-cased header name from the variable name like req.http.Cookie:foo
+func headerName(name string) (string, bool) {
+	idx := strings.Index(name, ".http.")
+	if idx == -1 {
+		return "", false
+	}
+	header := name[idx+len(".http."):]
+	if colon := strings.Index(header, ":"); colon != -1 {
+		header = header[:colon]
+	}
+	return strings.ToLower(header), true
+}
@@ -0,0 +1,76 @@
+package interpreter
+
+import (
+	ghttp "net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ysugimoto/falco/v2/ast"
+	"github.com/ysugimoto/falco/v2/interpreter/context"
+	"github.com/ysugimoto/falco/v2/interpreter/http"
+)
+
+func TestStrictHeaders(t *testing.T) {
+	// req.http.X-Foo is set in VCL, and bereq.http.X-Bar is set via header.set function
+	statements := []ast.Statement{
+		&ast.IfStatement{
+			Condition: &ast.Boolean{Value: true},
+			Consequence: &ast.BlockStatement{
+				Statements: []ast.Statement{
+					&ast.SetStatement{
+						Ident:    &ast.Ident{Value: "req.http.X-Foo"},
+						Operator: &ast.Operator{Operator: "="},
+						Value:    &ast.String{Value: "foo"},
+					},
+				},
+			},
+		},
+		&ast.FunctionCallStatement{
+			Function: &ast.Ident{Value: "header.set"},
+			Arguments: []ast.Expression{
+				&ast.Ident{Value: "bereq"},
+				&ast.String{Value: "X-Bar"},
+				&ast.String{Value: "bar"},
+			},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		strict   bool
+		external []string
+		ident    string
+		isError  bool
+	}{
+		{name: "unknown header is allowed by default", ident: "req.http.X-Forwared-For"},
+		{name: "unknown header is rejected on strict mode", strict: true, ident: "req.http.X-Forwared-For", isError: true},
+		{name: "present header is allowed on strict mode", strict: true, ident: "req.http.User-Agent"},
+		{name: "header set in VCL is allowed on strict mode", strict: true, ident: "req.http.x-foo"},
+		{name: "header set by function is allowed on strict mode", strict: true, ident: "req.http.X-Bar"},
+		{name: "subfield of known header is allowed on strict mode", strict: true, ident: "req.http.X-Foo:baz"},
+		{name: "external header is allowed on strict mode", strict: true, external: []string{"Fastly-*"}, ident: "req.http.Fastly-Client-IP"},
+		{name: "non-header variable is not checked", strict: true, ident: "req.url"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ip := New()
+			ip.CollectHeaders(statements)
+			ip.ctx = context.New(
+				context.WithStrictHeaders(tt.strict),
+				context.WithExternalHeaders(tt.external),
+			)
+			req := httptest.NewRequest(ghttp.MethodGet, "http://localhost:3124", nil)
+			req.Header.Set("User-Agent", "falco")
+			ip.ctx.Request = http.WrapRequest(req)
+			ip.SetScope(context.RecvScope)
+
+			_, err := ip.ProcessExpression(&ast.Ident{Meta: &ast.Meta{}, Value: tt.ident})
+			if tt.isError && err == nil {
+				t.Errorf("expected error but got nil")
+			} else if !tt.isError && err != nil {
+				t.Errorf("unexpected error returned: %s", err)
+			}
+		})
+	}
+}
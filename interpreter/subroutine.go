@@ -37,6 +37,7 @@ func (i *Interpreter) ProcessSubroutine(sub *ast.SubroutineDeclaration, ds Debug
 
 	// Push this subroutine to callstacks
 	i.callStack = append(i.callStack, sub)
+	i.ctx.SubroutineCallTrace = append(i.ctx.SubroutineCallTrace, sub.Name.Value)
 	// If expected stack count is exceeded, raise an error
 	if len(i.callStack) > maxCallStackExceedCount {
 		return NONE, errors.WithStack(exception.MaxCallStackExceeded(&sub.GetMeta().Token, i.callStack))
@@ -82,6 +83,7 @@ func (i *Interpreter) ProcessFunctionSubroutine(sub *ast.SubroutineDeclaration,
 
 	// Push this subroutine to callstacks
 	i.callStack = append(i.callStack, sub)
+	i.ctx.SubroutineCallTrace = append(i.ctx.SubroutineCallTrace, sub.Name.Value)
 	// If expected stack count is exceeded, raise an error
 	if len(i.callStack) > maxCallStackExceedCount {
 		return value.Null, NONE, errors.WithStack(exception.MaxCallStackExceeded(&sub.GetMeta().Token, i.callStack))
@@ -103,6 +105,7 @@ func (i *Interpreter) ProcessFunctionSubroutine(sub *ast.SubroutineDeclaration,
 		if debugState != DebugStepOut {
 			debugState = i.Debugger.Run(stmt)
 		}
+		i.recordStep(stmt)
 
 		// Find process marker and add flow if found
 		if name, found := findProcessMark(stmt.GetMeta().Leading); found {
@@ -348,7 +351,9 @@ func (i *Interpreter) validateAndSetParameters(sub *ast.SubroutineDeclaration, a
 				err.Error(),
 			)
 		}
-		i.localVars[param.Name.Value] = converted
+		// Parameter is assignable storage of the subroutine so bind the copy,
+		// otherwise assignment changes the argument variable of the caller or interned values like value.True
+		i.localVars[param.Name.Value] = converted.Copy()
 	}
 
 	return nil
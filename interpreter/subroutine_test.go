@@ -264,6 +264,42 @@ func TestFunctionSubroutine(t *testing.T) {
 	}
 }
 
+func TestFunctionSubroutineParameterIsCopied(t *testing.T) {
+	vcl := `
+	sub f(BOOL var.p) BOOL {
+		set var.p = false;
+		return var.p;
+	}
+
+	sub g(STRING var.s) STRING {
+		set var.s = "changed";
+		return var.s;
+	}
+
+	sub vcl_recv {
+		declare local var.origin STRING;
+		set var.origin = "origin";
+		set req.http.X-Param = f(req.url == "");
+		set req.http.X-String = g(var.origin);
+		set req.http.X-Origin = var.origin;
+		if (req.url == "") {
+			set req.http.X-After = "matched";
+		}
+	}
+	`
+	assertInterpreter(t, vcl, context.RecvScope, map[string]value.Value{
+		"req.http.X-Param":  &value.String{Value: "0"},
+		"req.http.X-String": &value.String{Value: "changed"},
+		"req.http.X-Origin": &value.String{Value: "origin"},
+		"req.http.X-After":  &value.String{Value: "matched"},
+	}, false)
+
+	// Interned boolean values must not be changed through the parameter
+	if !value.True.Value || value.False.Value {
+		t.Errorf("Interned boolean values are changed, True=%t, False=%t", value.True.Value, value.False.Value)
+	}
+}
+
 func TestMaxCallStackExceeded(t *testing.T) {
 	tests := []struct {
 		name string
@@ -0,0 +1,48 @@
+package interpreter
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/ysugimoto/falco/v2/interpreter/function"
+	"github.com/ysugimoto/falco/v2/suggest"
+)
+
+// didYouMean returns "did you mean" suggestion of the closest names to be appended to the error message,
+// or empty string if there is no close name
+func didYouMean(name string, candidates []string) string {
+	message := suggest.Message(suggest.Closest(name, candidates))
+	if message == "" {
+		return ""
+	}
+	return ", " + message
+}
+
+// withSuggestion appends "did you mean" suggestion to the error of the unknown name
+func withSuggestion(err error, name string, candidates []string) error {
+	message := didYouMean(name, candidates)
+	if message == "" {
+		return err
+	}
+	return fmt.Errorf("%w%s", err, message)
+}
+
+// identCandidates returns names which could be referenced as the identifier
+// like predefined variables, backends, acls and tables
+func (i *Interpreter) identCandidates() []string {
+	names := builtinDefinitions().VariableNames()
+	names = slices.AppendSeq(names, maps.Keys(i.ctx.Backends))
+	names = slices.AppendSeq(names, maps.Keys(i.ctx.Acls))
+	names = slices.AppendSeq(names, maps.Keys(i.ctx.Tables))
+	names = slices.AppendSeq(names, maps.Keys(i.ctx.Penaltyboxes))
+	names = slices.AppendSeq(names, maps.Keys(i.ctx.Ratecounters))
+	names = slices.AppendSeq(names, maps.Keys(i.ctx.Constants))
+	return names
+}
+
+// functionCandidates returns names of functions which are implemented, injected to the interpreter or defined as Fastly builtin
+func (i *Interpreter) functionCandidates() []string {
+	names := append(function.Names(), builtinDefinitions().FunctionNames()...)
+	return slices.AppendSeq(names, maps.Keys(i.functions))
+}
@@ -0,0 +1,62 @@
+package interpreter
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ysugimoto/falco/v2/ast"
+	"github.com/ysugimoto/falco/v2/interpreter/context"
+	"github.com/ysugimoto/falco/v2/interpreter/http"
+	"github.com/ysugimoto/falco/v2/interpreter/value"
+)
+
+func TestSuggestClosestNames(t *testing.T) {
+	tests := []struct {
+		name   string
+		exp    ast.Expression
+		expect string
+	}{
+		{
+			name:   "predefined variable",
+			exp:    &ast.Ident{Value: "req.restrats"},
+			expect: `did you mean "req.restarts"?`,
+		},
+		{
+			name:   "backend",
+			exp:    &ast.Ident{Value: "F_orign"},
+			expect: `did you mean "F_origin"?`,
+		},
+		{
+			name:   "local variable",
+			exp:    &ast.Ident{Value: "var.countr"},
+			expect: `did you mean "var.counter"?`,
+		},
+		{
+			name: "function",
+			exp: &ast.FunctionCallExpression{
+				Function:  &ast.Ident{Value: "std.strln"},
+				Arguments: []ast.Expression{&ast.String{Value: "foo"}},
+			},
+			expect: `did you mean "std.strlen"?`,
+		},
+	}
+
+	for _, tt := range tests {
+		ip := New()
+		ip.ctx = context.New()
+		// Variable lookup falls back to the request variables so the context must have the request like runtime
+		ip.ctx.Request = http.WrapRequest(httptest.NewRequest("GET", "http://localhost", nil))
+		ip.ctx.Backends["F_origin"] = &value.Backend{Value: &ast.BackendDeclaration{Name: &ast.Ident{Value: "F_origin"}}}
+		ip.localVars["var.counter"] = &value.Integer{}
+		ip.SetScope(context.RecvScope)
+		_, err := ip.ProcessExpression(tt.exp)
+		if err == nil {
+			t.Errorf("%s: expected error but got nil", tt.name)
+			continue
+		}
+		if !strings.Contains(err.Error(), tt.expect) {
+			t.Errorf("%s: error should contain %s, got %s", tt.name, tt.expect, err)
+		}
+	}
+}
@@ -3,12 +3,17 @@ package interpreter
 import (
 	"context"
 	"io"
+	"maps"
 	ghttp "net/http"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/ysugimoto/falco/v2/ast"
 	icontext "github.com/ysugimoto/falco/v2/interpreter/context"
+	"github.com/ysugimoto/falco/v2/interpreter/exception"
+	fe "github.com/ysugimoto/falco/v2/interpreter/function/errors"
 	"github.com/ysugimoto/falco/v2/interpreter/http"
 	"github.com/ysugimoto/falco/v2/interpreter/value"
 )
@@ -17,7 +22,7 @@ const testBackendResponseBody = "falco_test_response"
 
 func (i *Interpreter) TestProcessInit(r *http.Request) error {
 	var err error
-	if err = i.ProcessInit(r); err != nil {
+	if err = i.processInit(r, true); err != nil {
 		return errors.WithStack(err)
 	}
 
@@ -66,10 +71,219 @@ func (i *Interpreter) TestProcessInit(r *http.Request) error {
 	return nil
 }
 
-func (i *Interpreter) ProcessTestSubroutine(scope icontext.Scope, sub *ast.SubroutineDeclaration) error {
+func (i *Interpreter) ProcessTestSubroutine(
+	scope icontext.Scope,
+	sub *ast.SubroutineDeclaration,
+	args ...ast.Expression,
+) (err error) {
+
+	// Report panic as failure of the testing subroutine, not whole testing process
+	defer exception.Recover(&err)
+
 	i.SetScope(scope)
-	if _, err := i.ProcessSubroutine(sub, DebugPass, nil); err != nil {
+	// Arguments of the parameterized test are evaluated in the testing scope
+	values := make([]value.Value, len(args))
+	for j := range args {
+		if values[j], err = i.ProcessExpression(args[j]); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	if _, err = i.ProcessSubroutine(sub, DebugPass, values); err != nil {
+		err = i.attachExcerpt(err)
+	}
+	// Assertion failures which are collected in soft assertion mode are reported together,
+	// and the error which aborts the subroutine is reported as the last failure
+	if failures := i.ctx.AssertionFailures; len(failures) > 0 {
+		i.ctx.AssertionFailures = nil
+		if err != nil {
+			failures = append(failures, errors.Cause(err))
+		}
+		return errors.WithStack(&fe.AssertionErrors{Errors: failures})
+	}
+	return errors.WithStack(err)
+}
+
+// ProcessSubtest processes the subtest subroutine in the scope of the running test case and records the result.
+// The subtest shares the state which is set up by the test case, but failures of the subtest are recorded independently
+// and not propagated to the test case. Returns true if the subtest has passed
+func (i *Interpreter) ProcessSubtest(name string, sub *ast.SubroutineDeclaration) bool {
+	parent := i.ctx.SubtestName
+	if parent != "" {
+		name = parent + " › " + name
+	}
+	// Record the result before processing so that nested subtests are listed after the enclosing one
+	result := &icontext.Subtest{Name: name}
+	i.ctx.Subtests = append(i.ctx.Subtests, result)
+
+	// Soft assertion failures of the test case should not be reported as failures of the subtest
+	failures := i.ctx.AssertionFailures
+	i.ctx.AssertionFailures = nil
+	i.ctx.SubtestName = name
+	defer func() {
+		i.ctx.AssertionFailures = failures
+		i.ctx.SubtestName = parent
+	}()
+
+	start := time.Now()
+	err := i.ProcessTestSubroutine(i.ctx.Scope, sub)
+	result.Error = errors.Cause(err)
+	result.Time = time.Since(start).Milliseconds()
+	return err == nil
+}
+
+// Subtests returns results of subtests which are processed in the test case
+func (i *Interpreter) Subtests() []*icontext.Subtest {
+	return i.ctx.Subtests
+}
+
+// ResetSubtests removes results of subtests which are processed in the previous test case
+func (i *Interpreter) ResetSubtests() {
+	i.ctx.Subtests = nil
+	i.ctx.SubtestName = ""
+}
+
+// ResetSubroutineCalls removes counts and trace of subroutine calls which are processed in the previous test case
+func (i *Interpreter) ResetSubroutineCalls() {
+	i.ctx.SubroutineCalls = make(map[string]int)
+	i.ctx.SubroutineCallTrace = nil
+}
+
+// ResetBackendRequests removes backend requests which are recorded in the previous test case
+func (i *Interpreter) ResetBackendRequests() {
+	i.ctx.BackendRequests = nil
+}
+
+// ResetCoverageHits clears coverage markers which are executed in the previous test case
+// so that assert.covered and assert.branch_covered only see the hits of the current one
+func (i *Interpreter) ResetCoverageHits() {
+	i.ctx.CoverageHits = make(map[string]uint64)
+}
+
+// ProcessFetchHook processes the testing hook subroutine registered by testing.before_fetch or testing.after_fetch.
+// The hook is processed in FETCH scope so that both bereq and beresp could be modified between state transitions
+func (i *Interpreter) ProcessFetchHook(hook *ast.SubroutineDeclaration) (err error) {
+	if hook == nil {
+		return nil
+	}
+	defer exception.Recover(&err)
+
+	scope := i.ctx.Scope
+	i.SetScope(icontext.FetchScope)
+	defer i.SetScope(scope)
+
+	if _, err := i.ProcessSubroutine(hook, DebugPass, nil); err != nil {
 		return errors.WithStack(err)
 	}
 	return nil
 }
+
+// ProcessMockBackendResponse replaces the backend response with the mock of the current backend if declared.
+// The testing process does not send the backend request so this is called before vcl_fetch is processed
+func (i *Interpreter) ProcessMockBackendResponse() {
+	if i.ctx.Backend == nil || i.ctx.Backend.Value == nil {
+		return
+	}
+	mock := i.ctx.FindMockBackendResponse(i.ctx.Backend.Value.Name.Value)
+	if mock == nil {
+		return
+	}
+	i.ctx.BackendResponse = mock.Response(i.ctx.BackendRequest)
+	i.process.TTFB += mock.Latency
+}
+
+// RestoreTestTables restores table properties which are modified by testing.table_set or testing.table_merge,
+// so that injected values are scoped to the test case
+func (i *Interpreter) RestoreTestTables() {
+	for name, props := range i.ctx.TableBackups {
+		if table, ok := i.ctx.Tables[name]; ok {
+			table.Properties = props
+		}
+	}
+	i.ctx.TableBackups = nil
+}
+
+// ResetInjectedAcls removes ACL entries which are injected by testing.acl_add after fixtures are committed
+func (i *Interpreter) ResetInjectedAcls() {
+	i.ctx.InjectedAclEntries = nil
+	for name, entries := range i.baselineFixtures.acls {
+		if i.ctx.InjectedAclEntries == nil {
+			i.ctx.InjectedAclEntries = make(map[string][]*ast.AclCidr)
+		}
+		// Copy entries not to be appended to the baseline
+		i.ctx.InjectedAclEntries[name] = slices.Clone(entries)
+	}
+}
+
+// RestoreOverrideVariables restores override variables which are modified by testing.override_variable,
+// so that overridden values are scoped to the test case
+func (i *Interpreter) RestoreOverrideVariables() {
+	for name, v := range i.ctx.OverrideBackups {
+		if v == nil {
+			delete(i.ctx.OverrideVariables, name)
+			continue
+		}
+		i.ctx.OverrideVariables[name] = v
+	}
+	i.ctx.OverrideBackups = nil
+}
+
+// ResetMockBackendResponses removes mocked backend responses which are declared by testing.mock_backend_response
+// after fixtures are committed
+func (i *Interpreter) ResetMockBackendResponses() {
+	i.ctx.MockBackendResponses = maps.Clone(i.baselineFixtures.mocks)
+}
+
+// ResetFetchHooks removes fetch hooks which are registered by testing.before_fetch or testing.after_fetch
+// after fixtures are committed
+func (i *Interpreter) ResetFetchHooks() {
+	i.ctx.BeforeFetchHook = i.baselineFixtures.beforeFetch
+	i.ctx.AfterFetchHook = i.baselineFixtures.afterFetch
+}
+
+// ResetSoftAssertions disables soft assertion mode which is enabled by testing.soft_assertions
+func (i *Interpreter) ResetSoftAssertions() {
+	i.ctx.SoftAssertions = false
+	i.ctx.AssertionFailures = nil
+}
+
+// Testing fixtures which are set up before test cases like before_all hook in the test file
+type testFixtures struct {
+	acls        map[string][]*ast.AclCidr
+	mocks       map[string]*icontext.MockBackendResponse
+	beforeFetch *ast.SubroutineDeclaration
+	afterFetch  *ast.SubroutineDeclaration
+}
+
+// CommitTestFixtures makes tables, ACL entries, variables, mocked backend responses and fetch hooks which are injected so far the baseline of following test cases,
+// RestoreTestTables, ResetInjectedAcls, RestoreOverrideVariables, ResetMockBackendResponses and ResetFetchHooks restore them to the committed state
+func (i *Interpreter) CommitTestFixtures() {
+	i.ctx.TableBackups = nil
+	i.ctx.OverrideBackups = nil
+	i.baselineFixtures = testFixtures{
+		acls:        maps.Clone(i.ctx.InjectedAclEntries),
+		mocks:       maps.Clone(i.ctx.MockBackendResponses),
+		beforeFetch: i.ctx.BeforeFetchHook,
+		afterFetch:  i.ctx.AfterFetchHook,
+	}
+}
+
+// TestVariableType returns the type of the predefined variable in the current scope,
+// testing functions use it to validate the value which overrides the variable
+func (i *Interpreter) TestVariableType(name string) (value.Type, error) {
+	v, err := i.vars.Get(i.ctx.Scope, name)
+	if err != nil {
+		return value.NullType, errors.WithStack(err)
+	}
+	return v.Type(), nil
+}
+
+// TestTable returns the table which is declared in the main VCL
+func (i *Interpreter) TestTable(name string) (*ast.TableDeclaration, bool) {
+	table, ok := i.ctx.Tables[name]
+	return table, ok
+}
+
+// SetTestTableEntry sets the table entry which is referred by testing.table_key and testing.table_value
+func (i *Interpreter) SetTestTableEntry(entry *ast.TableProperty) {
+	i.ctx.TestingTableEntry = entry
+}
@@ -0,0 +1,41 @@
+package interpreter
+
+import (
+	"path/filepath"
+
+	"github.com/ysugimoto/falco/v2/ast"
+	"github.com/ysugimoto/falco/v2/trace"
+)
+
+// Record processing statement as a step of the execution trace
+func (i *Interpreter) recordStep(stmt ast.Statement) {
+	if i.ctx.TraceDir == "" {
+		return
+	}
+	var sub string
+	if len(i.callStack) > 0 {
+		sub = i.callStack[len(i.callStack)-1].Name.Value
+	}
+	i.process.AddStep(i.ctx.Scope.String(), sub, stmt.GetMeta().Token)
+}
+
+// Trace returns recorded execution trace with provided name
+func (i *Interpreter) Trace(name string) *trace.Trace {
+	return i.process.Trace(name)
+}
+
+// ResetTrace clears recorded steps and accesses.
+// Testing runs multiple subroutines in the same process so need to reset for each test
+func (i *Interpreter) ResetTrace() {
+	i.process.Steps = nil
+	i.process.Accesses = nil
+}
+
+// Write execution trace file to the trace directory
+func (i *Interpreter) WriteTrace(name string) (string, error) {
+	path := filepath.Join(i.ctx.TraceDir, trace.FileName(name))
+	if err := i.Trace(name).WriteFile(path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
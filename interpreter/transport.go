@@ -8,10 +8,10 @@ import (
 	"encoding/base64"
 	"fmt"
 	"io"
+	"log/slog"
 	"time"
 
 	"github.com/gobwas/glob"
-	"github.com/k0kubun/pp"
 	"github.com/pkg/errors"
 	"github.com/ysugimoto/falco/v2/ast"
 	"github.com/ysugimoto/falco/v2/config"
@@ -134,7 +134,7 @@ func (i *Interpreter) createBackendRequest(ctx *icontext.Context, backend *value
 func (i *Interpreter) getOriginHostHeader(backend *value.Backend, defaultHost string) (*string, error) {
 	// Check backend is dynamic
 	if v, err := i.getBackendProperty(backend.Value.Properties, "dynamic"); err != nil {
-		pp.Println("dynamic get error")
+		slog.Debug("Failed to get backend dynamic property", "backend", backend.Value.Name.Value, "error", err)
 		return nil, errors.WithStack(err)
 	} else if v != nil && v.Type() == value.BooleanType {
 		// If backend is dynamic, lookup .host_header field value
@@ -175,6 +175,10 @@ func (i *Interpreter) sendBackendRequest(backend *value.Backend) (*http.Response
 	ctx, to := context.WithTimeout(i.ctx.Request.Context(), timeout)
 	defer to()
 
+	// Record backend request before sending in order to be able to assert it in testing
+	if err := i.RecordBackendRequest(); err != nil {
+		return nil, errors.WithStack(err)
+	}
 	req := i.ctx.BackendRequest.Clone(ctx)
 
 	// Check Fastly limitations
@@ -185,18 +189,46 @@ func (i *Interpreter) sendBackendRequest(backend *value.Backend) (*http.Response
 	// Debug message
 	var suffix string
 	// nolint:errcheck
-	if overrideBackend, _ := getOverrideBackend(i.ctx, backend.Value.Name.Value); overrideBackend != nil {
+	overrideBackend, _ := getOverrideBackend(i.ctx, backend.Value.Name.Value)
+	if overrideBackend != nil {
 		suffix = " (overridden by config)"
 	}
+	mock := i.ctx.FindMockBackendResponse(backend.Value.Name.Value)
+	if mock != nil {
+		suffix = " (mocked by testing.mock_backend_response)"
+	}
 	i.Debugger.Message(
 		fmt.Sprintf("Fetching backend (%s) %s%s", backend.Value.Name.Value, req.URL.String(), suffix),
 	)
 
+	// Mocked backend responds the canned response without sending the request
+	if mock != nil {
+		i.process.TTFB += mock.Latency
+		i.Debugger.Message(
+			fmt.Sprintf("Backend (%s) responds status code %d", backend.Value.Name.Value, mock.Status),
+		)
+		return mock.Response(req), nil
+	}
+
+	start := time.Now()
 	resp, err := http.SendRequest(req)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
 
+	// Accumulate simulated TTFB for latency budget verification.
+	// Configured backend latency is used if present, otherwise actual elapsed time is used
+	latency := time.Since(start)
+	if overrideBackend != nil && overrideBackend.Latency != "" {
+		latency, err = time.ParseDuration(overrideBackend.Latency)
+		if err != nil {
+			return nil, errors.WithStack(
+				exception.System("Invalid latency %q of override backend %s: %s", overrideBackend.Latency, backend.Value.Name.Value, err),
+			)
+		}
+	}
+	i.process.TTFB += latency
+
 	// Debug message
 	i.Debugger.Message(
 		fmt.Sprintf("Backend (%s) responds status code %d", backend.Value.Name.Value, resp.StatusCode),
@@ -212,6 +244,27 @@ func (i *Interpreter) sendBackendRequest(backend *value.Backend) (*http.Response
 	return resp, nil
 }
 
+// RecordBackendRequest records current bereq as the request which is sent to the current backend
+func (i *Interpreter) RecordBackendRequest() error {
+	if i.ctx.BackendRequest == nil || i.ctx.Backend == nil {
+		return nil
+	}
+	body, rewound, err := readBody(i.ctx.BackendRequest.Body)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	i.ctx.BackendRequest.Body = rewound
+
+	i.ctx.BackendRequests = append(i.ctx.BackendRequests, &icontext.BackendRequest{
+		Backend: i.ctx.Backend.String(),
+		Method:  i.ctx.BackendRequest.Method,
+		URL:     i.ctx.BackendRequest.URL.String(),
+		Header:  i.ctx.BackendRequest.Header.Clone(),
+		Body:    body,
+	})
+	return nil
+}
+
 func (i *Interpreter) getBackendProperty(props []*ast.BackendProperty, key string) (value.Value, error) {
 	var prop ast.Expression
 	for _, v := range props {
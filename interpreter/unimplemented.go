@@ -0,0 +1,158 @@
+package interpreter
+
+import (
+	"fmt"
+	"net"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/ysugimoto/falco/v2/config"
+	"github.com/ysugimoto/falco/v2/interpreter/function"
+	"github.com/ysugimoto/falco/v2/interpreter/value"
+	"github.com/ysugimoto/falco/v2/interpreter/variable"
+	lcontext "github.com/ysugimoto/falco/v2/linter/context"
+	"github.com/ysugimoto/falco/v2/linter/types"
+)
+
+// Fastly builtin definitions of the linter are used to know the type of unimplemented builtins
+var builtinDefinitions = sync.OnceValue(func() *lcontext.Context {
+	return lcontext.New()
+})
+
+// getVariable gets the predefined variable value,
+// the unimplemented variable is treated following the unimplemented policy.
+// The override variable takes precedence when it has the type of the variable,
+// even if the variable does not look up overrides by itself like fastly.ff.visits_this_service
+func (i *Interpreter) getVariable(name string) (value.Value, error) {
+	v, err := i.vars.Get(i.ctx.Scope, name)
+	if err == nil {
+		if o, ok := i.ctx.OverrideVariables[name]; ok && o.Type() == v.Type() {
+			return o, nil
+		}
+		return v, nil
+	}
+	if !errors.Is(err, variable.ErrUndefinedVariable) {
+		return v, err
+	}
+	t, ok := builtinDefinitions().PredefinedVariableType(name)
+	if !ok {
+		return v, err
+	}
+	return i.unimplemented(name, t, err)
+}
+
+// unimplementedFunction returns the value of the Fastly builtin function which is not implemented in the interpreter
+// following the unimplemented policy. The original error is returned if the function is not Fastly builtin one
+func (i *Interpreter) unimplementedFunction(name string, err error) (value.Value, error) {
+	if _, ok := i.functions[name]; ok || function.Implemented(name) {
+		return value.Null, err
+	}
+	t, ok := builtinDefinitions().BuiltinFunctionReturnType(name)
+	if !ok {
+		return value.Null, withSuggestion(err, name, i.functionCandidates())
+	}
+	return i.unimplemented(name, t, err)
+}
+
+func (i *Interpreter) unimplemented(name string, t types.Type, err error) (value.Value, error) {
+	policy := i.ctx.Unimplemented
+	if policy == nil {
+		return value.Null, err
+	}
+
+	var v value.Value
+	switch policy.Mode {
+	case config.UnimplementedWarn:
+		v = zeroValue(t)
+	case config.UnimplementedStub:
+		stub, ok := policy.Stubs[name]
+		if !ok {
+			return value.Null, err
+		}
+		sv, serr := stubValue(t, stub)
+		if serr != nil {
+			return value.Null, errors.WithStack(fmt.Errorf("Invalid stub value for %s: %w", name, serr))
+		}
+		v = sv
+	default:
+		return value.Null, err
+	}
+
+	i.Debugger.Message(fmt.Sprintf("%s is not implemented, returns %s on %s policy", name, v.String(), policy.Mode))
+	if !slices.Contains(i.process.Unimplemented, name) {
+		i.process.Unimplemented = append(i.process.Unimplemented, name)
+	}
+	return v, nil
+}
+
+// zeroValue returns zero value of the builtin type, VOID and unsupported types are treated as NULL
+func zeroValue(t types.Type) value.Value {
+	switch t {
+	case types.StringType:
+		return &value.String{}
+	case types.IntegerType:
+		return &value.Integer{}
+	case types.FloatType:
+		return &value.Float{}
+	case types.BoolType:
+		return &value.Boolean{}
+	case types.RTimeType:
+		return &value.RTime{}
+	case types.TimeType:
+		return &value.Time{}
+	case types.IPType:
+		return &value.IP{Value: net.IPv4zero}
+	default:
+		return value.Null
+	}
+}
+
+// stubValue converts configured stub value to the builtin type
+func stubValue(t types.Type, stub any) (value.Value, error) {
+	s := fmt.Sprint(stub)
+	switch t {
+	case types.StringType:
+		return &value.String{Value: s}, nil
+	case types.IntegerType:
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		return &value.Integer{Value: n}, nil
+	case types.FloatType:
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, err
+		}
+		return &value.Float{Value: f}, nil
+	case types.BoolType:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return nil, err
+		}
+		return &value.Boolean{Value: b}, nil
+	case types.RTimeType:
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return nil, err
+		}
+		return &value.RTime{Value: d}, nil
+	case types.TimeType:
+		tm, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return nil, err
+		}
+		return &value.Time{Value: tm}, nil
+	case types.IPType:
+		ip := net.ParseIP(s)
+		if ip == nil {
+			return nil, fmt.Errorf("%s is not an IP address", s)
+		}
+		return &value.IP{Value: ip}, nil
+	default:
+		return nil, fmt.Errorf("%s type could not be stubbed", t)
+	}
+}
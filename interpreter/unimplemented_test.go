@@ -0,0 +1,91 @@
+package interpreter
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ysugimoto/falco/v2/ast"
+	"github.com/ysugimoto/falco/v2/config"
+	"github.com/ysugimoto/falco/v2/interpreter/context"
+	"github.com/ysugimoto/falco/v2/interpreter/http"
+	"github.com/ysugimoto/falco/v2/interpreter/value"
+)
+
+func TestUnimplementedPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  *config.UnimplementedConfig
+		ident   string
+		expect  value.Value
+		isError bool
+	}{
+		{name: "error by default", ident: "geoip.country_code", isError: true},
+		{
+			name:    "error policy",
+			policy:  &config.UnimplementedConfig{Mode: config.UnimplementedError},
+			ident:   "geoip.country_code",
+			isError: true,
+		},
+		{
+			name:   "warn policy returns zero value",
+			policy: &config.UnimplementedConfig{Mode: config.UnimplementedWarn},
+			ident:  "geoip.latitude",
+			expect: &value.Float{},
+		},
+		{
+			name: "stub policy returns stub value",
+			policy: &config.UnimplementedConfig{
+				Mode:  config.UnimplementedStub,
+				Stubs: map[string]any{"geoip.latitude": 35.6},
+			},
+			ident:  "geoip.latitude",
+			expect: &value.Float{Value: 35.6},
+		},
+		{
+			name: "stub policy without stub value",
+			policy: &config.UnimplementedConfig{
+				Mode:  config.UnimplementedStub,
+				Stubs: map[string]any{"geoip.latitude": 35.6},
+			},
+			ident:   "geoip.country_code",
+			isError: true,
+		},
+		{
+			name: "invalid stub value",
+			policy: &config.UnimplementedConfig{
+				Mode:  config.UnimplementedStub,
+				Stubs: map[string]any{"geoip.latitude": "north"},
+			},
+			ident:   "geoip.latitude",
+			isError: true,
+		},
+		{
+			name:    "undefined variable is not affected",
+			policy:  &config.UnimplementedConfig{Mode: config.UnimplementedWarn},
+			ident:   "req.undefined",
+			isError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		ip := New()
+		ip.ctx = context.New(context.WithUnimplemented(tt.policy))
+		ip.ctx.Request = http.WrapRequest(httptest.NewRequest("GET", "http://localhost", nil))
+		ip.SetScope(context.RecvScope)
+		v, err := ip.ProcessExpression(&ast.Ident{Value: tt.ident})
+		if tt.isError {
+			if err == nil {
+				t.Errorf("%s: expected error but got nil", tt.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error returned: %s", tt.name, err)
+			continue
+		}
+		assertValue(t, tt.name, tt.expect, v)
+		if len(ip.process.Unimplemented) != 1 || ip.process.Unimplemented[0] != tt.ident {
+			t.Errorf("%s: unimplemented builtin is not recorded, got=%v", tt.name, ip.process.Unimplemented)
+		}
+	}
+}
@@ -43,14 +43,6 @@ func calculateRateWithTime(now int64, entries []rateEntry, window time.Duration)
 	return math.Floor(float64(total) / float64(windowSec))
 }
 
-func calculateBucket(entries []rateEntry, window time.Duration) int64 {
-	return calculateBucketWithTime(time.Now().UnixMilli(), entries, window)
-}
-
-func calculateRate(entries []rateEntry, window time.Duration) float64 {
-	return calculateRateWithTime(time.Now().UnixMilli(), entries, window)
-}
-
 // Ratecounter represents ratecounter declaration with holding client map
 type Ratecounter struct {
 	Decl *ast.RatecounterDeclaration
@@ -77,22 +69,22 @@ func NewRatecounter(decl *ast.RatecounterDeclaration) *Ratecounter {
 	}
 }
 
-// Increment() increments access entry manually.
+// Increment() increments access entry manually at provided time.
 // This function should be called via ratelimit.ratecounter_increment() VCL function
-func (r *Ratecounter) Increment(entry string, delta int64) {
+func (r *Ratecounter) Increment(entry string, delta int64, now time.Time) {
 	if _, ok := r.Clients[entry]; !ok {
 		r.Clients[entry] = []rateEntry{}
 	}
 	r.Clients[entry] = append(r.Clients[entry], rateEntry{
 		Count:     delta,
-		Timestamp: time.Now().UnixMilli(),
+		Timestamp: now.UnixMilli(),
 	})
 	r.LastIncremented = &entry
 }
 
-// Bucket() returns access count for provided window.
+// Bucket() returns access count for provided window until provided time.
 // This function will be called for specific variables like ratecounter.{NAME}.bucket.10s
-func (r *Ratecounter) Bucket(entry string, window time.Duration) int64 {
+func (r *Ratecounter) Bucket(entry string, window time.Duration, now time.Time) int64 {
 	if r.LastIncremented == nil {
 		return 0
 	}
@@ -100,12 +92,12 @@ func (r *Ratecounter) Bucket(entry string, window time.Duration) int64 {
 	if !ok {
 		return 0
 	}
-	return calculateBucket(entries, window)
+	return calculateBucketWithTime(now.UnixMilli(), entries, window)
 }
 
-// Rate() returns access rate for provided window.
+// Rate() returns access rate for provided window until provided time.
 // This function will be called for specific variables like ratecounter.{NAME}.rate.1s
-func (r *Ratecounter) Rate(entry string, window time.Duration) float64 {
+func (r *Ratecounter) Rate(entry string, window time.Duration, now time.Time) float64 {
 	if r.LastIncremented == nil {
 		return 0
 	}
@@ -113,7 +105,7 @@ func (r *Ratecounter) Rate(entry string, window time.Duration) float64 {
 	if !ok {
 		return 0
 	}
-	return calculateRate(entries, window)
+	return calculateRateWithTime(now.UnixMilli(), entries, window)
 }
 
 // Penaltybox implementation
@@ -131,12 +123,12 @@ func NewPenaltybox(decl *ast.PenaltyboxDeclaration) *Penaltybox {
 }
 
 // Add() is operation of ratelimit.penaltybox_add() function
-func (p *Penaltybox) Add(entry string, ttl time.Duration) {
-	p.Clients.Store(entry, time.Now().Add(ttl))
+func (p *Penaltybox) Add(entry string, ttl time.Duration, now time.Time) {
+	p.Clients.Store(entry, now.Add(ttl))
 }
 
 // Has() is operation of ratelimit.penaltybox_has() function
-func (p *Penaltybox) Has(entry string) bool {
+func (p *Penaltybox) Has(entry string, now time.Time) bool {
 	// Load the entry
 	v, ok := p.Clients.Load(entry)
 	if !ok {
@@ -150,7 +142,7 @@ func (p *Penaltybox) Has(entry string) bool {
 		return false
 	}
 	// Check expiration
-	if expire.Before(time.Now()) {
+	if expire.Before(now) {
 		p.Clients.Delete(entry)
 		return false
 	}
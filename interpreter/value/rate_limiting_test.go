@@ -82,23 +82,23 @@ func TestPenaltybox(t *testing.T) {
 	anotherClient := "192.168.0.1"
 
 	// Test Add and Has
-	pb.Add(client, 1*time.Second)
-	if !pb.Has(client) {
+	pb.Add(client, 1*time.Second, time.Now())
+	if !pb.Has(client, time.Now()) {
 		t.Errorf("Expected client to be in penaltybox, but wasn't")
 	}
-	if pb.Has(anotherClient) {
+	if pb.Has(anotherClient, time.Now()) {
 		t.Errorf("Expected anotherClient not to be in penaltybox, but was")
 	}
 
 	// Test expiration
 	time.Sleep(1 * time.Second)
-	if pb.Has(client) {
+	if pb.Has(client, time.Now()) {
 		t.Errorf("Expected client to be expired from penaltybox, but wasn't")
 	}
 
 	// Test invalid entry type
 	pb.Clients.Store("invalid", "not a time object")
-	if pb.Has("invalid") {
+	if pb.Has("invalid", time.Now()) {
 		t.Errorf("Expected invalid entry to be handled and return false, but returned true")
 	}
 	if _, ok := pb.Clients.Load("invalid"); ok {
@@ -116,25 +116,25 @@ func TestRatecounter(t *testing.T) {
 	client := "127.0.0.1"
 
 	// Before calling any function, bucket and rate should be 0
-	if bucket := rc.Bucket(client, 10*time.Second); bucket != 0 {
+	if bucket := rc.Bucket(client, 10*time.Second, time.Now()); bucket != 0 {
 		t.Errorf("Expected initial bucket to be 0, got %d", bucket)
 	}
-	if rate := rc.Rate(client, 1*time.Second); rate != 0 {
+	if rate := rc.Rate(client, 1*time.Second, time.Now()); rate != 0 {
 		t.Errorf("Expected initial rate to be 0, got %f", rate)
 	}
 
 	// After increment, IsAccessible should be true
-	rc.Increment(client, 1)
+	rc.Increment(client, 1, time.Now())
 	if *rc.LastIncremented != client {
 		t.Errorf("Expected LastIncremented to be initialized with string value after Increment, but was false")
 	}
 
 	// After increment, bucket and rate should be accessible
-	if bucket := rc.Bucket(client, 10*time.Second); bucket == 0 {
+	if bucket := rc.Bucket(client, 10*time.Second, time.Now()); bucket == 0 {
 		t.Errorf("Expected bucket to be non-zero after Increment, but was 0")
 	}
 	// Rate is calculated on completed 10-second periods, so the rate will be 0 just after increment.
-	if rate := rc.Rate(client, 10*time.Second); rate != 0 {
+	if rate := rc.Rate(client, 10*time.Second, time.Now()); rate != 0 {
 		t.Errorf("Expected rate to be 0 right after increment, but was %f", rate)
 	}
 }
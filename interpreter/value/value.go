@@ -158,6 +158,22 @@ type Boolean struct {
 	IsNotSet bool
 }
 
+// Interned values which are shared to reduce allocations on hot paths like operator results.
+// These values must be treated as read-only, never assign to them in place
+var (
+	True        = &Boolean{Value: true}
+	False       = &Boolean{Value: false}
+	EmptyString = &String{}
+)
+
+// NewBoolean returns the interned boolean value
+func NewBoolean(b bool) *Boolean {
+	if b {
+		return True
+	}
+	return False
+}
+
 func (v *Boolean) String() string {
 	if v.Value {
 		return "1"
@@ -581,11 +581,8 @@ func (v *AllScopeVariables) Get(s context.Scope, name string) (value.Value, erro
 	case LF:
 		return &value.String{Value: "\n"}, nil
 	case NOW_SEC:
-		// For testing - if fixed time is injected, return it
-		if v.ctx.FixedTime != nil {
-			return &value.String{Value: fmt.Sprint(v.ctx.FixedTime.Unix())}, nil
-		}
-		return &value.String{Value: fmt.Sprint(time.Now().Unix())}, nil
+		// For testing - if fixed time is injected, context returns it
+		return &value.String{Value: fmt.Sprint(v.ctx.Now().Unix())}, nil
 	case REQ_BODY:
 		if v := lookupOverride(v.ctx, name); v != nil {
 			return v, nil
@@ -788,11 +785,8 @@ func (v *AllScopeVariables) Get(s context.Scope, name string) (value.Value, erro
 			Value: fmt.Sprint(v.ctx.RequestStartTime.UnixMicro() % 1000000),
 		}, nil
 	case NOW:
-		// For testing - if fixed time is injected, return it
-		if v.ctx.FixedTime != nil {
-			return value.NewTime(*v.ctx.FixedTime), nil
-		}
-		return value.NewTime(time.Now()), nil
+		// For testing - if fixed time is injected, context returns it
+		return value.NewTime(v.ctx.Now()), nil
 	case TIME_START:
 		return value.NewTime(v.ctx.RequestStartTime), nil
 	// https://github.com/ysugimoto/falco/issues/427
@@ -816,14 +810,14 @@ func (v *AllScopeVariables) Get(s context.Scope, name string) (value.Value, erro
 		return val, nil
 	}
 
-	if injectedVariable != nil {
-		if val, err := injectedVariable.Get(v.ctx, s, name); err == nil {
+	if injected := findInjectedVariable(v.ctx); injected != nil {
+		if val, err := injected.Get(v.ctx, s, name); err == nil {
 			return val, nil
 		}
 	}
 
 	return value.Null, errors.WithStack(fmt.Errorf(
-		"undefined variable %s", name,
+		"%w %s", ErrUndefinedVariable, name,
 	))
 }
 
@@ -1000,8 +994,8 @@ func (v *AllScopeVariables) Set(s context.Scope, name, operator string, val valu
 		return assignRequestHeaderValue(v.ctx.Request, match[1], operator, val)
 	}
 
-	if injectedVariable != nil {
-		if err := injectedVariable.Set(v.ctx, s, name, operator, val); err == nil {
+	if injected := findInjectedVariable(v.ctx); injected != nil {
+		if err := injected.Set(v.ctx, s, name, operator, val); err == nil {
 			return nil
 		}
 	}
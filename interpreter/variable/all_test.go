@@ -542,3 +542,42 @@ func TestGetFromRegex(t *testing.T) {
 		})
 	}
 }
+
+type stubInjectVariable struct {
+	values map[string]value.Value
+}
+
+func (v *stubInjectVariable) Get(ctx *context.Context, scope context.Scope, name string) (value.Value, error) {
+	if val, ok := v.values[name]; ok {
+		return val, nil
+	}
+	return nil, ErrUndefinedVariable
+}
+
+func (v *stubInjectVariable) Set(ctx *context.Context, scope context.Scope, name, operator string, val value.Value) error {
+	return ErrUndefinedVariable
+}
+
+func TestContextInjectedVariable(t *testing.T) {
+	vars := &AllScopeVariables{
+		ctx: &context.Context{
+			Request: http.WrapRequest(&ghttp.Request{Header: ghttp.Header{}}),
+			InjectedVariable: &stubInjectVariable{
+				values: map[string]value.Value{"testing.foo": &value.String{Value: "foo"}},
+			},
+		},
+	}
+	val, err := vars.Get(context.RecvScope, "testing.foo")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if diff := cmp.Diff(val, &value.String{Value: "foo"}); diff != "" {
+		t.Errorf("Return value unmatch, diff: %s", diff)
+	}
+
+	// Other contexts do not see the variable which is injected to the context
+	vars.ctx = &context.Context{Request: http.WrapRequest(&ghttp.Request{Header: ghttp.Header{}})}
+	if _, err := vars.Get(context.RecvScope, "testing.foo"); err == nil {
+		t.Errorf("Expected undefined variable error but got nil")
+	}
+}
@@ -86,6 +86,7 @@ func (v *HashScopeVariables) Set(s context.Scope, name, operator string, val val
 		if err := doUpdateHash(v.ctx.RequestHash, operator, val); err != nil {
 			return errors.WithStack(err)
 		}
+		v.ctx.RequestHashInputs = append(v.ctx.RequestHashInputs, val.String())
 		return nil
 	}
 	// If not found, pass to all scope value
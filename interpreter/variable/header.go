@@ -1,6 +1,7 @@
 package variable
 
 import (
+	"log/slog"
 	"net/textproto"
 	"strings"
 
@@ -66,9 +67,11 @@ func getRequestHeaderValue(r *http.Request, name string) *value.String {
 		return &value.String{Value: v}
 	}
 
-	// Request header can modify cookie, then we need to retrieve value from Cookie pointer
+	// Request header can modify cookie, then we need to retrieve value from Cookie pointer.
+	// Note that only the first Cookie field is read like the edge does,
+	// multiple Cookie fields need to be folded by std.collect in advance
 	if strings.EqualFold(name, "cookie") {
-		for _, c := range r.Cookies() {
+		for _, c := range http.ParseCookies(v) {
 			if c.Name == key {
 				return &value.String{Value: c.Value}
 			}
@@ -95,6 +98,16 @@ func getResponseHeaderValue(r *http.Response, name string) *value.String {
 	return GetField(v, key, ",")
 }
 
+// Fastly truncates header values at newlines.
+// Newline in the value could be CR/LF injection from client data so warn it
+func truncateHeaderValue(name, val string) string {
+	truncated, _, found := strings.Cut(val, "\n")
+	if found {
+		slog.Warn("Header value is truncated at newline, it may be CR/LF injection", "header", name)
+	}
+	return truncated
+}
+
 func setRequestHeaderValue(r *http.Request, name string, val value.Value) {
 	name, key, found := strings.Cut(name, ":")
 	if !found {
@@ -105,9 +118,7 @@ func setRequestHeaderValue(r *http.Request, name string, val value.Value) {
 			return
 		}
 
-		// Fastly truncates header values at newlines.
-		sVal, _, _ := strings.Cut(val.String(), "\n")
-		r.Header.Set(name, sVal)
+		r.Header.Set(name, truncateHeaderValue(name, val.String()))
 		r.Assign(name)
 		return
 	}
@@ -133,9 +144,7 @@ func setResponseHeaderValue(r *http.Response, name string, val value.Value) {
 			return
 		}
 
-		// Fastly truncates header values at newlines.
-		sVal, _, _ := strings.Cut(val.String(), "\n")
-		r.Header.Set(name, sVal)
+		r.Header.Set(name, truncateHeaderValue(name, val.String()))
 		r.Assign(name)
 		return
 	}
@@ -349,3 +349,30 @@ func TestRemoveCookieByName(t *testing.T) {
 		}
 	}
 }
+
+func TestGetRequestHeaderValueMultipleFields(t *testing.T) {
+	tests := []struct {
+		name   string
+		expect *value.String
+	}{
+		{name: "Cookie", expect: &value.String{Value: "foo=bar"}},
+		{name: "Cookie:foo", expect: &value.String{Value: "bar"}},
+		{name: "Cookie:cat", expect: &value.String{IsNotSet: true}},
+		{name: "Text", expect: &value.String{Value: "lorem=ipsum"}},
+		{name: "Text:dolor", expect: &value.String{IsNotSet: true}},
+	}
+	req := http.WrapRequest(
+		httptest.NewRequest(ghttp.MethodGet, "http://localhost", nil),
+	)
+	req.Header.Add("Cookie", "foo=bar")
+	req.Header.Add("Cookie", "cat=meow")
+	req.Header.Add("Text", "lorem=ipsum")
+	req.Header.Add("Text", "dolor=sit")
+
+	for _, tt := range tests {
+		ret := getRequestHeaderValue(req, tt.name)
+		if diff := cmp.Diff(ret, tt.expect); diff != "" {
+			t.Errorf("Return value unmatch for %s, diff=%s", tt.name, diff)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package variable
+
+import (
+	"errors"
+	ghttp "net/http"
+
+	"github.com/ysugimoto/falco/v2/interpreter/context"
+	"github.com/ysugimoto/falco/v2/interpreter/http"
+)
+
+var ErrUndefinedVariable = errors.New("undefined variable")
+
+// Implemented returns true if the predefined variable could be read by the interpreter in any scope.
+// Variables are probed with the context which has only HTTP request and responses,
+// so the panic caused by lacking other states also means the variable is implemented
+func Implemented(name string) bool {
+	ctx := context.New()
+	req, _ := ghttp.NewRequest(ghttp.MethodGet, "http://localhost/", nil) // nolint:errcheck
+	ctx.Request = http.WrapRequest(req)
+	ctx.BackendRequest = http.WrapRequest(req.Clone(req.Context()))
+	ctx.BackendResponse = http.WrapResponse(&ghttp.Response{StatusCode: ghttp.StatusOK, Header: ghttp.Header{}})
+	ctx.Object = http.WrapResponse(&ghttp.Response{StatusCode: ghttp.StatusOK, Header: ghttp.Header{}})
+	ctx.Response = http.WrapResponse(&ghttp.Response{StatusCode: ghttp.StatusOK, Header: ghttp.Header{}})
+	scopes := []struct {
+		scope context.Scope
+		vars  Variable
+	}{
+		{context.RecvScope, NewRecvScopeVariables(ctx)},
+		{context.HashScope, NewHashScopeVariables(ctx)},
+		{context.HitScope, NewHitScopeVariables(ctx)},
+		{context.MissScope, NewMissScopeVariables(ctx)},
+		{context.PassScope, NewPassScopeVariables(ctx)},
+		{context.FetchScope, NewFetchScopeVariables(ctx)},
+		{context.DeliverScope, NewDeliverScopeVariables(ctx)},
+		{context.ErrorScope, NewErrorScopeVariables(ctx)},
+		{context.LogScope, NewLogScopeVariables(ctx)},
+	}
+	for _, s := range scopes {
+		if probe(s.vars, s.scope, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func probe(vars Variable, scope context.Scope, name string) (implemented bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			implemented = true
+		}
+	}()
+	_, err := vars.Get(scope, name)
+	return !errors.Is(err, ErrUndefinedVariable)
+}
@@ -0,0 +1,20 @@
+package variable
+
+import "testing"
+
+func TestImplemented(t *testing.T) {
+	tests := map[string]bool{
+		"req.url":             true,
+		"req.http.Foo":        true,
+		"beresp.status":       true,
+		"resp.http.Foo":       true,
+		"re.group.1":          true,
+		"undefined.variable":  false,
+		"req.undefined_field": false,
+	}
+	for name, expect := range tests {
+		if got := Implemented(name); got != expect {
+			t.Errorf("Implemented(%s) mismatch, expect=%t, got=%t", name, expect, got)
+		}
+	}
+}
@@ -2,20 +2,20 @@ package variable
 
 import (
 	"github.com/ysugimoto/falco/v2/interpreter/context"
-	"github.com/ysugimoto/falco/v2/interpreter/value"
 )
 
-type InjectVariable interface {
-	Get(*context.Context, context.Scope, string) (value.Value, error)
-	Set(*context.Context, context.Scope, string, string, value.Value) error
-
-	// Currently no need
-	// Add(*context.Context, context.Scope, string, value.Value) error
-	// Unset(*context.Context, context.Scope, string) error
-}
+type InjectVariable = context.InjectVariable
 
 var injectedVariable InjectVariable
 
 func Inject(v InjectVariable) {
 	injectedVariable = v
 }
+
+// Find the variable which is injected to the context first, and then globally injected one
+func findInjectedVariable(ctx *context.Context) InjectVariable {
+	if ctx.InjectedVariable != nil {
+		return ctx.InjectedVariable
+	}
+	return injectedVariable
+}
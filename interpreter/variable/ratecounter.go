@@ -43,7 +43,7 @@ func getRateCounterBucketValue(ctx *context.Context, rc *value.Ratecounter, clie
 	}
 
 	return &value.Integer{
-		Value: rc.Bucket(*client, duration),
+		Value: rc.Bucket(*client, duration, ctx.Now()),
 	}, nil
 }
 
@@ -72,6 +72,6 @@ func getRateCounterRateValue(ctx *context.Context, rc *value.Ratecounter, client
 	}
 
 	return &value.Float{
-		Value: rc.Rate(*client, duration),
+		Value: rc.Rate(*client, duration, ctx.Now()),
 	}, nil
 }
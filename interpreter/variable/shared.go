@@ -180,12 +180,12 @@ func GetTCPInfoVariable(ctx *context.Context, name string) (value.Value, error)
 		if v := lookupOverride(ctx, name); v != nil {
 			return v, nil
 		}
-		return value.NewTime(time.Now().Add(-24 * time.Hour)), nil
+		return value.NewTime(ctx.Now().Add(-24 * time.Hour)), nil
 	case TLS_CLIENT_CERTIFICATE_NOT_AFTER:
 		if v := lookupOverride(ctx, name); v != nil {
 			return v, nil
 		}
-		return value.NewTime(time.Now().Add(-24 * time.Hour).Add(24 * time.Hour * 365)), nil
+		return value.NewTime(ctx.Now().Add(-24 * time.Hour).Add(24 * time.Hour * 365)), nil
 	}
 
 	return nil, nil
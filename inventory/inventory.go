@@ -0,0 +1,199 @@
+// Package inventory reports usages of Fastly builtin functions and predefined variables across VCLs,
+// and flags ones which falco interpreter does not implement yet
+package inventory
+
+import (
+	"sort"
+
+	"github.com/ysugimoto/falco/v2/ast"
+	"github.com/ysugimoto/falco/v2/interpreter/function"
+	"github.com/ysugimoto/falco/v2/interpreter/variable"
+	"github.com/ysugimoto/falco/v2/linter/context"
+)
+
+const (
+	KindFunction = "function"
+	KindVariable = "variable"
+)
+
+type Location struct {
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Position int    `json:"position"`
+}
+
+// Usage is the usage of builtin function or predefined variable.
+// Dynamic part of the variable name is replaced with "*", e.g. req.http.*
+type Usage struct {
+	Name        string      `json:"name"`
+	Kind        string      `json:"kind"`
+	Implemented bool        `json:"implemented"`
+	Count       int         `json:"count"`
+	Locations   []*Location `json:"locations"`
+}
+
+type Inventory struct {
+	ctx    *context.Context
+	usages map[string]*Usage
+}
+
+func New() *Inventory {
+	return &Inventory{
+		ctx:    context.New(),
+		usages: make(map[string]*Usage),
+	}
+}
+
+// Usages returns collected usages sorted by kind and name
+func (inv *Inventory) Usages() []*Usage {
+	usages := make([]*Usage, 0, len(inv.usages))
+	for _, u := range inv.usages {
+		usages = append(usages, u)
+	}
+	sort.Slice(usages, func(i, j int) bool {
+		if usages[i].Kind != usages[j].Kind {
+			return usages[i].Kind < usages[j].Kind
+		}
+		return usages[i].Name < usages[j].Name
+	})
+	return usages
+}
+
+// Collect collects usages in subroutines of the VCL, or root statements of the snippet.
+// Included modules are not resolved, so all VCL files should be passed to collect usages across the codebase
+func (inv *Inventory) Collect(vcl *ast.VCL) {
+	for _, stmt := range vcl.Statements {
+		if sub, ok := stmt.(*ast.SubroutineDeclaration); ok {
+			inv.collectBlock(sub.Block)
+			continue
+		}
+		inv.collectStatement(stmt)
+	}
+}
+
+func (inv *Inventory) add(kind, name string, ident *ast.Ident, implemented func(string) bool) {
+	key := kind + ":" + name
+	u, ok := inv.usages[key]
+	if !ok {
+		u = &Usage{Name: name, Kind: kind}
+		inv.usages[key] = u
+	}
+	// Probe by the name in the source because dynamic part of the variable name might be meaningful
+	if !u.Implemented {
+		u.Implemented = implemented(ident.Value)
+	}
+	u.Count++
+	u.Locations = append(u.Locations, &Location{
+		File:     ident.Token.File,
+		Line:     ident.Token.Line,
+		Position: ident.Token.Position,
+	})
+}
+
+func (inv *Inventory) collectIdent(ident *ast.Ident) {
+	if ident == nil {
+		return
+	}
+	if name, ok := inv.ctx.PredefinedVariable(ident.Value); ok {
+		inv.add(KindVariable, name, ident, variable.Implemented)
+	}
+}
+
+func (inv *Inventory) collectFunction(ident *ast.Ident) {
+	if inv.ctx.IsBuiltinFunction(ident.Value) {
+		inv.add(KindFunction, ident.Value, ident, function.Implemented)
+	}
+}
+
+func (inv *Inventory) collectBlock(block *ast.BlockStatement) {
+	if block == nil {
+		return
+	}
+	inv.collectStatements(block.Statements)
+}
+
+func (inv *Inventory) collectStatements(statements []ast.Statement) {
+	for _, stmt := range statements {
+		inv.collectStatement(stmt)
+	}
+}
+
+func (inv *Inventory) collectStatement(stmt ast.Statement) {
+	switch t := stmt.(type) {
+	case *ast.BlockStatement:
+		inv.collectBlock(t)
+	case *ast.SetStatement:
+		inv.collectIdent(t.Ident)
+		inv.collectExpression(t.Value)
+	case *ast.AddStatement:
+		inv.collectIdent(t.Ident)
+		inv.collectExpression(t.Value)
+	case *ast.UnsetStatement:
+		inv.collectIdent(t.Ident)
+	case *ast.RemoveStatement:
+		inv.collectIdent(t.Ident)
+	case *ast.IfStatement:
+		inv.collectIfStatement(t)
+	case *ast.SwitchStatement:
+		inv.collectExpression(t.Control.Expression)
+		for _, cs := range t.Cases {
+			inv.collectStatements(cs.Statements)
+		}
+	case *ast.ErrorStatement:
+		inv.collectExpression(t.Code)
+		inv.collectExpression(t.Argument)
+	case *ast.LogStatement:
+		inv.collectExpression(t.Value)
+	case *ast.SyntheticStatement:
+		inv.collectExpression(t.Value)
+	case *ast.SyntheticBase64Statement:
+		inv.collectExpression(t.Value)
+	case *ast.ReturnStatement:
+		inv.collectExpression(t.ReturnExpression)
+	case *ast.CallStatement:
+		inv.collectExpressions(t.Arguments)
+	case *ast.FunctionCallStatement:
+		inv.collectFunction(t.Function)
+		inv.collectExpressions(t.Arguments)
+	}
+}
+
+func (inv *Inventory) collectIfStatement(stmt *ast.IfStatement) {
+	inv.collectExpression(stmt.Condition)
+	inv.collectBlock(stmt.Consequence)
+	for _, another := range stmt.Another {
+		inv.collectIfStatement(another)
+	}
+	if stmt.Alternative != nil {
+		inv.collectBlock(stmt.Alternative.Consequence)
+	}
+}
+
+func (inv *Inventory) collectExpressions(expressions []ast.Expression) {
+	for i := range expressions {
+		inv.collectExpression(expressions[i])
+	}
+}
+
+func (inv *Inventory) collectExpression(expr ast.Expression) {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		inv.collectIdent(t)
+	case *ast.PrefixExpression:
+		inv.collectExpression(t.Right)
+	case *ast.PostfixExpression:
+		inv.collectExpression(t.Left)
+	case *ast.GroupedExpression:
+		inv.collectExpression(t.Right)
+	case *ast.InfixExpression:
+		inv.collectExpression(t.Left)
+		inv.collectExpression(t.Right)
+	case *ast.IfExpression:
+		inv.collectExpression(t.Condition)
+		inv.collectExpression(t.Consequence)
+		inv.collectExpression(t.Alternative)
+	case *ast.FunctionCallExpression:
+		inv.collectFunction(t.Function)
+		inv.collectExpressions(t.Arguments)
+	}
+}
@@ -0,0 +1,67 @@
+package inventory
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/ysugimoto/falco/v2/lexer"
+	"github.com/ysugimoto/falco/v2/parser"
+)
+
+func TestInventory(t *testing.T) {
+	inv := New()
+	for name, input := range map[string]string{
+		"main.vcl": `
+sub vcl_recv {
+  #FASTLY recv
+  set req.http.Country = geoip.country_code;
+  if (req.http.Host ~ "example") {
+    set req.url = querystring.sort(req.url);
+  }
+  call custom;
+}`,
+		"custom.vcl": `
+sub custom {
+  declare local var.n INTEGER;
+  set var.n = std.atoi(req.http.N);
+  std.collect(req.http.Cookie);
+}`,
+	} {
+		vcl, err := parser.New(lexer.NewFromString(input, lexer.WithFile(name))).ParseVCL()
+		if err != nil {
+			t.Fatalf("Unexpected parse error: %s", err)
+		}
+		inv.Collect(vcl)
+	}
+
+	type summary struct {
+		Name        string
+		Kind        string
+		Implemented bool
+		Count       int
+	}
+	var actual []summary
+	for _, u := range inv.Usages() {
+		actual = append(actual, summary{u.Name, u.Kind, u.Implemented, u.Count})
+	}
+	expect := []summary{
+		{"querystring.sort", KindFunction, true, 1},
+		{"std.atoi", KindFunction, true, 1},
+		{"std.collect", KindFunction, true, 1},
+		{"geoip.country_code", KindVariable, false, 1},
+		{"req.http.*", KindVariable, true, 4},
+		{"req.url", KindVariable, true, 2},
+	}
+	if diff := cmp.Diff(expect, actual); diff != "" {
+		t.Errorf("Usages mismatch, diff=%s", diff)
+	}
+
+	for _, u := range inv.Usages() {
+		if u.Name != "querystring.sort" {
+			continue
+		}
+		if diff := cmp.Diff([]*Location{{File: "main.vcl", Line: 6, Position: 19}}, u.Locations); diff != "" {
+			t.Errorf("Locations mismatch, diff=%s", diff)
+		}
+	}
+}
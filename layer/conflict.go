@@ -0,0 +1,228 @@
+package layer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ysugimoto/falco/v2/ast"
+)
+
+// Conflict rules
+const (
+	// Overlay declares the subroutine of the base VCL which is not an extension point
+	RuleSubroutineOverride = "subroutine-override"
+	// Overlay declares the same name as the base VCL or other tenants
+	RuleDeclarationConflict = "declaration-conflict"
+	// Overlay modifies the protected variable
+	RuleProtectedVariable = "protected-variable"
+	// Extension terminates the state of the base VCL or skips following tenants
+	RuleTerminatingStatement = "terminating-statement"
+)
+
+// Conflict is the overlay statement which overrides protected behavior of the base VCL
+type Conflict struct {
+	Tenant   string `json:"tenant"`
+	Rule     string `json:"rule"`
+	Message  string `json:"message"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Position int    `json:"position"`
+}
+
+func (c *Conflict) String() string {
+	return fmt.Sprintf("%s:%d:%d: tenant %s: %s (%s)", c.File, c.Line, c.Position, c.Tenant, c.Message, c.Rule)
+}
+
+type declared struct {
+	tenant string
+	file   string
+}
+
+// Check analyzes all tenant overlays and returns conflicts in tenant order
+func (l *Layer) Check() []*Conflict {
+	var conflicts []*Conflict
+
+	names := make(map[string]*declared)
+	for _, stmt := range l.base.Statements {
+		if kind, name := declarationName(stmt); name != "" {
+			names[kind+" "+name] = &declared{}
+		}
+	}
+
+	for _, t := range l.tenants {
+		for _, o := range t.Overlays {
+			c := &checker{layer: l, tenant: t, overlay: o}
+			for _, stmt := range o.VCL.Statements {
+				kind, name := declarationName(stmt)
+				if name == "" {
+					continue
+				}
+				if sub, ok := stmt.(*ast.SubroutineDeclaration); ok {
+					c.checkSubroutine(sub)
+					if l.extensions[name] != nil {
+						continue
+					}
+				}
+
+				key := kind + " " + name
+				prev, ok := names[key]
+				switch {
+				case !ok:
+					names[key] = &declared{tenant: t.Name, file: o.File}
+				case kind == "subroutine" && prev.tenant == "":
+					c.add(stmt, RuleSubroutineOverride, "subroutine %s of the base VCL is not an extension point", name)
+				case prev.tenant == "":
+					c.add(stmt, RuleDeclarationConflict, "%s %s is already declared in the base VCL", kind, name)
+				default:
+					c.add(stmt, RuleDeclarationConflict, "%s %s is already declared by tenant %s in %s", kind, name, prev.tenant, prev.file)
+				}
+			}
+			conflicts = append(conflicts, c.conflicts...)
+		}
+	}
+	return conflicts
+}
+
+// Backends and directors share the same namespace
+func declarationName(stmt ast.Statement) (string, string) {
+	switch t := stmt.(type) {
+	case *ast.AclDeclaration:
+		return "acl", t.Name.Value
+	case *ast.BackendDeclaration:
+		return "backend", t.Name.Value
+	case *ast.DirectorDeclaration:
+		return "backend", t.Name.Value
+	case *ast.TableDeclaration:
+		return "table", t.Name.Value
+	case *ast.PenaltyboxDeclaration:
+		return "penaltybox", t.Name.Value
+	case *ast.RatecounterDeclaration:
+		return "ratecounter", t.Name.Value
+	case *ast.ConstDeclaration:
+		return "const", t.Name.Value
+	case *ast.SubroutineDeclaration:
+		return "subroutine", t.Name.Value
+	}
+	return "", ""
+}
+
+type checker struct {
+	layer     *Layer
+	tenant    *Tenant
+	overlay   *Overlay
+	conflicts []*Conflict
+}
+
+func (c *checker) add(node ast.Node, rule, format string, args ...any) {
+	tok := node.GetMeta().Token
+	c.conflicts = append(c.conflicts, &Conflict{
+		Tenant:   c.tenant.Name,
+		Rule:     rule,
+		Message:  fmt.Sprintf(format, args...),
+		File:     c.overlay.File,
+		Line:     tok.Line,
+		Position: tok.Position,
+	})
+}
+
+func (c *checker) checkSubroutine(sub *ast.SubroutineDeclaration) {
+	// Terminating statements are checked only in extensions because other subroutines are called explicitly
+	extension := c.layer.extensions[sub.Name.Value] != nil
+	if extension && sub.ReturnType != nil {
+		c.add(sub, RuleSubroutineOverride, "extension point %s must not be a functional subroutine", sub.Name.Value)
+	}
+	c.checkStatements(sub.Block.Statements, extension && !c.layer.allowTerminate)
+}
+
+func (c *checker) checkStatements(statements []ast.Statement, terminate bool) {
+	for _, stmt := range statements {
+		c.checkStatement(stmt, terminate)
+	}
+}
+
+func (c *checker) checkStatement(stmt ast.Statement, terminate bool) {
+	switch t := stmt.(type) {
+	case *ast.BlockStatement:
+		c.checkStatements(t.Statements, terminate)
+	case *ast.SetStatement:
+		c.checkVariable(t, t.Ident.Value)
+	case *ast.AddStatement:
+		c.checkVariable(t, t.Ident.Value)
+	case *ast.UnsetStatement:
+		c.checkVariable(t, t.Ident.Value)
+	case *ast.RemoveStatement:
+		c.checkVariable(t, t.Ident.Value)
+	case *ast.FunctionCallStatement:
+		c.checkHeaderFunction(t)
+	case *ast.IfStatement:
+		c.checkIfStatement(t, terminate)
+	case *ast.SwitchStatement:
+		for _, cs := range t.Cases {
+			c.checkStatements(cs.Statements, terminate)
+		}
+	case *ast.ReturnStatement:
+		if terminate {
+			c.add(t, RuleTerminatingStatement, "return statement in the extension skips the base VCL or following tenants")
+		}
+	case *ast.RestartStatement:
+		if terminate {
+			c.add(t, RuleTerminatingStatement, "restart statement in the extension terminates the base VCL")
+		}
+	case *ast.ErrorStatement:
+		if terminate {
+			c.add(t, RuleTerminatingStatement, "error statement in the extension terminates the base VCL")
+		}
+	}
+}
+
+func (c *checker) checkIfStatement(stmt *ast.IfStatement, terminate bool) {
+	c.checkStatements(stmt.Consequence.Statements, terminate)
+	for _, another := range stmt.Another {
+		c.checkIfStatement(another, terminate)
+	}
+	if stmt.Alternative != nil {
+		c.checkStatements(stmt.Alternative.Consequence.Statements, terminate)
+	}
+}
+
+// header.set and header.unset modify the header by the name in the string literal
+func (c *checker) checkHeaderFunction(stmt *ast.FunctionCallStatement) {
+	switch stmt.Function.Value {
+	case "header.set", "header.unset":
+	default:
+		return
+	}
+	if len(stmt.Arguments) < 2 {
+		return
+	}
+	scope, ok := stmt.Arguments[0].(*ast.Ident)
+	if !ok {
+		return
+	}
+	name, ok := stmt.Arguments[1].(*ast.String)
+	if !ok {
+		return
+	}
+	c.checkVariable(stmt, scope.Value+".http."+name.Value)
+}
+
+func (c *checker) checkVariable(node ast.Node, name string) {
+	// Subfield of the header like req.http.Cookie:session modifies the header itself
+	if idx := strings.Index(name, ":"); idx > 0 {
+		name = name[:idx]
+	}
+	for _, p := range c.layer.protected {
+		if matchVariable(p, name) {
+			c.add(node, RuleProtectedVariable, "%s is protected by the base VCL", name)
+			return
+		}
+	}
+}
+
+// Variable names are matched case-insensitively because header names are case-insensitive
+func matchVariable(pattern, name string) bool {
+	if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+		return len(name) >= len(prefix) && strings.EqualFold(name[:len(prefix)], prefix)
+	}
+	return strings.EqualFold(pattern, name)
+}
@@ -0,0 +1,174 @@
+// Package layer merges the base platform VCL with per-tenant overlay VCLs,
+// and detects overlays which override protected behavior of the base VCL.
+// Tenant overlays extend the base VCL only through extension point subroutines which are declared in the configuration
+package layer
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/ysugimoto/falco/v2/ast"
+	"github.com/ysugimoto/falco/v2/config"
+	"github.com/ysugimoto/falco/v2/lexer"
+	"github.com/ysugimoto/falco/v2/parser"
+)
+
+var validName = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
+
+// Overlay is the parsed overlay file of the tenant
+type Overlay struct {
+	File string
+	VCL  *ast.VCL
+}
+
+// Tenant is the set of overlays which are applied to requests matching the condition
+type Tenant struct {
+	Name      string
+	Condition string
+	Priority  int
+	Overlays  []*Overlay
+}
+
+type Layer struct {
+	base           *ast.VCL
+	extensions     map[string]*ast.SubroutineDeclaration
+	protected      []string
+	allowTerminate bool
+	tenants        []*Tenant
+}
+
+// New creates the layer from the configuration, overlays are parsed VCLs keyed by file path in the configuration.
+// Tenants are sorted by priority, tenants with the same priority keep the declared order
+func New(c *config.LayerConfig, base *ast.VCL, overlays map[string]*ast.VCL) (*Layer, error) {
+	l := &Layer{
+		base:           base,
+		extensions:     make(map[string]*ast.SubroutineDeclaration),
+		protected:      c.Protected,
+		allowTerminate: c.AllowTerminate,
+	}
+
+	subroutines := make(map[string]*ast.SubroutineDeclaration)
+	for _, stmt := range base.Statements {
+		if sub, ok := stmt.(*ast.SubroutineDeclaration); ok {
+			subroutines[sub.Name.Value] = sub
+		}
+	}
+	for _, name := range c.Extensions {
+		sub, ok := subroutines[name]
+		if !ok {
+			return nil, errors.Errorf("extension point %s is not declared in the base VCL", name)
+		}
+		if sub.ReturnType != nil {
+			return nil, errors.Errorf("extension point %s must not be a functional subroutine", name)
+		}
+		l.extensions[name] = sub
+	}
+
+	names := make(map[string]struct{})
+	for _, tc := range c.Tenants {
+		if !validName.MatchString(tc.Name) {
+			return nil, errors.Errorf("invalid tenant name %q", tc.Name)
+		}
+		if _, ok := names[tc.Name]; ok {
+			return nil, errors.Errorf("tenant %s is declared multiple times", tc.Name)
+		}
+		names[tc.Name] = struct{}{}
+		if len(tc.Files) == 0 {
+			return nil, errors.Errorf("tenant %s has no overlay files", tc.Name)
+		}
+
+		t := &Tenant{
+			Name:      tc.Name,
+			Condition: strings.TrimSpace(tc.Condition),
+			Priority:  tc.Priority,
+		}
+		for _, file := range tc.Files {
+			vcl, ok := overlays[file]
+			if !ok {
+				return nil, errors.Errorf("overlay %s of tenant %s is not loaded", file, tc.Name)
+			}
+			t.Overlays = append(t.Overlays, &Overlay{File: file, VCL: vcl})
+		}
+		l.tenants = append(l.tenants, t)
+	}
+	sort.SliceStable(l.tenants, func(i, j int) bool {
+		return l.tenants[i].Priority < l.tenants[j].Priority
+	})
+
+	return l, nil
+}
+
+// Tenants returns tenants in merged order
+func (l *Layer) Tenants() []*Tenant {
+	return l.tenants
+}
+
+// Merge merges tenant overlays into the base VCL, conflicts must be resolved before merging.
+// Bodies of extension point subroutines in overlays are appended to the base extension point in tenant order
+// and guarded by the tenant condition, and other declarations in overlays are appended to the end of the base VCL
+func (l *Layer) Merge() (*ast.VCL, error) {
+	if conflicts := l.Check(); len(conflicts) > 0 {
+		return nil, errors.Errorf("%d conflicts are detected, tenant overlays could not be merged", len(conflicts))
+	}
+
+	var decls []string
+	for _, stmt := range l.base.Statements {
+		if sub, ok := stmt.(*ast.SubroutineDeclaration); ok && l.extensions[sub.Name.Value] != nil {
+			decls = append(decls, l.mergeExtension(sub))
+			continue
+		}
+		decls = append(decls, strings.TrimRight(stmt.String(), "\n"))
+	}
+
+	for _, t := range l.tenants {
+		for _, o := range t.Overlays {
+			for _, stmt := range o.VCL.Statements {
+				if sub, ok := stmt.(*ast.SubroutineDeclaration); ok && l.extensions[sub.Name.Value] != nil {
+					continue
+				}
+				decls = append(decls, strings.TrimRight(stmt.String(), "\n"))
+			}
+		}
+	}
+
+	// Tenant conditions are embedded as VCL expressions so ensure merged VCL is valid
+	vcl, err := parser.New(lexer.NewFromString(strings.Join(decls, "\n\n") + "\n")).ParseVCL()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to merge tenant overlays, check tenant conditions")
+	}
+	return vcl, nil
+}
+
+func (l *Layer) mergeExtension(base *ast.SubroutineDeclaration) string {
+	var buf strings.Builder
+	buf.WriteString(fmt.Sprintf("%ssub %s {\n", base.LeadingComment("\n"), base.Name.Value))
+	for _, stmt := range base.Block.Statements {
+		buf.WriteString(stmt.String())
+	}
+
+	for _, t := range l.tenants {
+		for _, o := range t.Overlays {
+			for _, stmt := range o.VCL.Statements {
+				sub, ok := stmt.(*ast.SubroutineDeclaration)
+				if !ok || sub.Name.Value != base.Name.Value {
+					continue
+				}
+				buf.WriteString(fmt.Sprintf("# tenant %s: %s\n", t.Name, o.File))
+				if t.Condition != "" {
+					buf.WriteString(fmt.Sprintf("if (%s) {\n", t.Condition))
+				}
+				for _, s := range sub.Block.Statements {
+					buf.WriteString(s.String())
+				}
+				if t.Condition != "" {
+					buf.WriteString("}\n")
+				}
+			}
+		}
+	}
+	buf.WriteString("}")
+	return buf.String()
+}
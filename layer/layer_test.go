@@ -0,0 +1,260 @@
+package layer
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/ysugimoto/falco/v2/ast"
+	"github.com/ysugimoto/falco/v2/config"
+	"github.com/ysugimoto/falco/v2/formatter"
+	"github.com/ysugimoto/falco/v2/lexer"
+	"github.com/ysugimoto/falco/v2/parser"
+)
+
+const baseVCL = `
+backend F_platform {
+  .host = "platform.example.com";
+}
+
+sub tenant_recv {
+  set req.http.X-Tenant-Extension = "1";
+}
+
+sub vcl_recv {
+  #FASTLY recv
+  set req.backend = F_platform;
+  call tenant_recv;
+  return (lookup);
+}
+`
+
+func parse(t *testing.T, vcl string) *ast.VCL {
+	v, err := parser.New(lexer.NewFromString(vcl)).ParseVCL()
+	if err != nil {
+		t.Fatalf("Failed to parse VCL: %s", err)
+	}
+	return v
+}
+
+func newLayer(t *testing.T, c *config.LayerConfig, overlays map[string]string) *Layer {
+	parsed := make(map[string]*ast.VCL)
+	for file, vcl := range overlays {
+		parsed[file] = parse(t, vcl)
+	}
+	l, err := New(c, parse(t, baseVCL), parsed)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	return l
+}
+
+func TestNew(t *testing.T) {
+	base := parse(t, baseVCL)
+	tests := []struct {
+		name   string
+		config *config.LayerConfig
+	}{
+		{
+			name:   "undeclared extension point",
+			config: &config.LayerConfig{Extensions: []string{"tenant_deliver"}},
+		},
+		{
+			name: "invalid tenant name",
+			config: &config.LayerConfig{
+				Tenants: []*config.TenantConfig{{Name: "brand-a", Files: []string{"a.vcl"}}},
+			},
+		},
+		{
+			name: "duplicated tenant",
+			config: &config.LayerConfig{
+				Tenants: []*config.TenantConfig{
+					{Name: "brand_a", Files: []string{"a.vcl"}},
+					{Name: "brand_a", Files: []string{"a.vcl"}},
+				},
+			},
+		},
+		{
+			name: "overlay is not loaded",
+			config: &config.LayerConfig{
+				Tenants: []*config.TenantConfig{{Name: "brand_a", Files: []string{"b.vcl"}}},
+			},
+		},
+	}
+
+	overlays := map[string]*ast.VCL{"a.vcl": parse(t, "")}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New(tt.config, base, overlays); err == nil {
+				t.Errorf("Expected error but got nil")
+			}
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	c := &config.LayerConfig{
+		Extensions: []string{"tenant_recv"},
+		Protected:  []string{"req.backend", "req.http.X-Platform-*"},
+		Tenants: []*config.TenantConfig{
+			{Name: "brand_a", Files: []string{"brand_a.vcl"}},
+			{Name: "brand_b", Files: []string{"brand_b.vcl"}},
+		},
+	}
+	l := newLayer(t, c, map[string]string{
+		"brand_a.vcl": `
+backend F_brand {
+  .host = "a.example.com";
+}
+
+sub tenant_recv {
+  set req.http.x-platform-auth = "skip";
+  if (req.url ~ "^/admin") {
+    return (pass);
+  }
+}
+`,
+		"brand_b.vcl": `
+backend F_brand {
+  .host = "b.example.com";
+}
+
+sub vcl_recv {
+  header.unset(req, "X-Platform-Token");
+}
+
+sub tenant_helper {
+  set req.backend = F_brand;
+  return;
+}
+`,
+	})
+
+	type conflict struct {
+		Tenant string
+		Rule   string
+		Line   int
+	}
+	var actual []conflict
+	for _, c := range l.Check() {
+		actual = append(actual, conflict{c.Tenant, c.Rule, c.Line})
+	}
+	expect := []conflict{
+		{"brand_a", RuleProtectedVariable, 7},
+		{"brand_a", RuleTerminatingStatement, 9},
+		{"brand_b", RuleDeclarationConflict, 2},
+		{"brand_b", RuleProtectedVariable, 7},
+		{"brand_b", RuleSubroutineOverride, 6},
+		{"brand_b", RuleProtectedVariable, 11},
+	}
+	if diff := cmp.Diff(expect, actual); diff != "" {
+		t.Errorf("Conflicts mismatch, diff=%s", diff)
+	}
+
+	t.Run("allow terminate", func(t *testing.T) {
+		c.AllowTerminate = true
+		defer func() { c.AllowTerminate = false }()
+		l := newLayer(t, c, map[string]string{
+			"brand_a.vcl": `sub tenant_recv { error 601; }`,
+			"brand_b.vcl": `sub tenant_recv { restart; }`,
+		})
+		if conflicts := l.Check(); len(conflicts) > 0 {
+			t.Errorf("Expected no conflicts, got %d", len(conflicts))
+		}
+	})
+}
+
+func TestMerge(t *testing.T) {
+	c := &config.LayerConfig{
+		Extensions: []string{"tenant_recv"},
+		Tenants: []*config.TenantConfig{
+			{Name: "brand_b", Condition: `req.http.Host == "b.example.com"`, Priority: 20, Files: []string{"brand_b.vcl"}},
+			{Name: "brand_a", Condition: `req.http.Host == "a.example.com"`, Priority: 10, Files: []string{"brand_a.vcl"}},
+			{Name: "shared", Priority: 30, Files: []string{"shared.vcl"}},
+		},
+	}
+	l := newLayer(t, c, map[string]string{
+		"brand_a.vcl": `
+backend F_brand_a {
+  .host = "a.example.com";
+}
+
+sub tenant_recv {
+  set req.backend = F_brand_a;
+}
+`,
+		"brand_b.vcl": `
+sub tenant_recv {
+  set req.http.X-Brand = "b";
+}
+`,
+		"shared.vcl": `
+sub tenant_recv {
+  set req.http.X-Shared = "1";
+}
+`,
+	})
+
+	vcl, err := l.Merge()
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+		return
+	}
+	buf, _ := io.ReadAll(formatter.New(&config.FormatConfig{IndentWidth: 2, IndentStyle: "space", LineWidth: 120, ReturnStatementParenthesis: true}).Format(vcl))
+
+	expect := `backend F_platform {
+  .host = "platform.example.com";
+}
+
+sub tenant_recv {
+  set req.http.X-Tenant-Extension = "1";
+  # tenant brand_a: brand_a.vcl
+  if (req.http.Host == "a.example.com") {
+    set req.backend = F_brand_a;
+  }
+  # tenant brand_b: brand_b.vcl
+  if (req.http.Host == "b.example.com") {
+    set req.http.X-Brand = "b";
+  }
+  # tenant shared: shared.vcl
+  set req.http.X-Shared = "1";
+}
+
+sub vcl_recv {
+#FASTLY recv
+  set req.backend = F_platform;
+  call tenant_recv;
+  return(lookup);
+}
+
+backend F_brand_a {
+  .host = "a.example.com";
+}
+`
+	if diff := cmp.Diff(expect, string(bytes.TrimLeft(buf, "\n"))); diff != "" {
+		t.Errorf("Merged VCL mismatch, diff=%s", diff)
+	}
+
+	t.Run("invalid condition", func(t *testing.T) {
+		c.Tenants[0].Condition = `req.http.Host ==`
+		defer func() { c.Tenants[0].Condition = `req.http.Host == "b.example.com"` }()
+		if _, err := newLayer(t, c, map[string]string{
+			"brand_a.vcl": `sub tenant_recv { set req.http.X-Brand = "a"; }`,
+			"brand_b.vcl": `sub tenant_recv { set req.http.X-Brand = "b"; }`,
+			"shared.vcl":  `sub tenant_recv { set req.http.X-Shared = "1"; }`,
+		}).Merge(); err == nil {
+			t.Errorf("Expected error but got nil")
+		}
+	})
+
+	t.Run("conflicts are detected", func(t *testing.T) {
+		if _, err := newLayer(t, c, map[string]string{
+			"brand_a.vcl": `backend F_platform { .host = "a.example.com"; }`,
+			"brand_b.vcl": ``,
+			"shared.vcl":  ``,
+		}).Merge(); err == nil {
+			t.Errorf("Expected error but got nil")
+		}
+	})
+}
@@ -1,35 +1,42 @@
 package lexer
 
 import (
-	"bufio"
-	"bytes"
 	"io"
 	"maps"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/ysugimoto/falco/v2/token"
 )
 
+// Lexer operates on the whole source string with byte offsets,
+// so token literals and lines are sliced from the source without allocating new strings.
 type Lexer struct {
-	r      *bufio.Reader
-	char   rune
-	line   int
-	index  int
-	buffer *bytes.Buffer
-	stack  []string
-	file   string
-	peeks  []token.Token
-	isEOF  bool
+	src       string
+	pos       int // byte offset of the next character
+	cur       int // byte offset of the current character
+	lineStart int // byte offset of the current line
+	char      rune
+	line      int
+	index     int
+	stack     []string
+	file      string
+	peeks     []token.Token
+	isEOF     bool
 
 	customs map[string]token.TokenType
 }
 
 func New(r io.Reader, opts ...OptionFunc) *Lexer {
+	b, _ := io.ReadAll(r) // nolint:errcheck
+	return NewFromString(string(b), opts...)
+}
+
+func NewFromString(input string, opts ...OptionFunc) *Lexer {
 	o := collect(opts)
 	l := &Lexer{
-		r:       bufio.NewReader(r),
+		src:     input,
 		line:    1,
-		buffer:  new(bytes.Buffer),
 		stack:   make([]string, 0, 512),
 		peeks:   make([]token.Token, 0, 8),
 		file:    o.Filename,
@@ -39,64 +46,56 @@ func New(r io.Reader, opts ...OptionFunc) *Lexer {
 	return l
 }
 
-func NewFromString(input string, opts ...OptionFunc) *Lexer {
-	return New(strings.NewReader(input), opts...)
-}
-
 func (l *Lexer) RegisterCustomTokens(tokenMap map[string]token.TokenType) {
 	maps.Copy(l.customs, tokenMap)
 }
 
 func (l *Lexer) skipBytes(n int) {
-	discarded, err := l.r.Discard(n)
-	if err != nil {
+	discarded := n
+	if remains := len(l.src) - l.pos; remains < n {
+		discarded = remains
 		l.char = 0x00
 	}
+	l.pos += discarded
 	l.index += discarded
 }
 
 func (l *Lexer) readChar() {
-	r, _, err := l.r.ReadRune()
-	if err != nil {
+	if l.pos >= len(l.src) {
 		l.char = 0x00
+		l.cur = len(l.src)
 		l.index += 1
 		return
 	}
 	if l.char == 0x0A { // LF
 		l.NewLine()
 	}
+	r, size := utf8.DecodeRuneInString(l.src[l.pos:])
+	l.cur = l.pos
+	l.pos += size
 	l.index += 1
 	l.char = r
-	l.buffer.WriteRune(r)
 }
 
 func (l *Lexer) peekChar() rune {
-	b, err := l.r.Peek(1)
-	if err != nil {
+	if l.pos >= len(l.src) {
 		return 0x00
 	}
-	return rune(b[0])
+	return rune(l.src[l.pos])
 }
 
 func (l *Lexer) peekUntil(cond func(b byte) bool) (string, error) {
-	var peekBytes int
-	for {
-		r, err := l.r.Peek(peekBytes + 1)
-		if err != nil {
-			return "", err
-		}
-
-		peekBytes++
-
-		if cond(r[peekBytes-1]) {
-			return string(r), nil
+	for i := l.pos; i < len(l.src); i++ {
+		if cond(l.src[i]) {
+			return l.src[l.pos : i+1], nil
 		}
 	}
+	return "", io.EOF
 }
 
 func (l *Lexer) NewLine() {
-	l.stack = append(l.stack, strings.TrimRight(l.buffer.String(), "\n"))
-	l.buffer = new(bytes.Buffer)
+	l.stack = append(l.stack, strings.TrimRight(l.src[l.lineStart:l.pos], "\n"))
+	l.lineStart = l.pos
 	l.index = 0
 	l.line++
 }
@@ -117,7 +116,10 @@ func (l *Lexer) PeekToken() token.Token {
 		return l.peeks[0]
 	}
 	t := l.NextToken()
-	l.peeks = append([]token.Token{t}, l.peeks...)
+	// Prepend the token in place, NextToken may push following tokens
+	l.peeks = append(l.peeks, token.Token{})
+	copy(l.peeks[1:], l.peeks)
+	l.peeks[0] = t
 	return t
 }
 
@@ -127,7 +129,8 @@ func (l *Lexer) NextToken() token.Token {
 
 	// if peek stack exists, dequeue from it
 	if len(l.peeks) > 0 {
-		t, l.peeks = l.peeks[0], l.peeks[1:]
+		t = l.peeks[0]
+		l.peeks = l.peeks[:copy(l.peeks, l.peeks[1:])]
 		return t
 	}
 
@@ -138,18 +141,18 @@ func (l *Lexer) NextToken() token.Token {
 	case '=':
 		if l.peekChar() == '=' {
 			l.readChar()
-			t = newToken(token.EQUAL, l.char, line, index)
+			t = l.newToken(token.EQUAL, line, index)
 			t.Literal = "=="
 		} else {
-			t = newToken(token.ASSIGN, l.char, line, index)
+			t = l.newToken(token.ASSIGN, line, index)
 		}
 	case '-':
 		if l.peekChar() == '=' {
 			l.readChar()
-			t = newToken(token.SUBTRACTION, l.char, line, index)
+			t = l.newToken(token.SUBTRACTION, line, index)
 			t.Literal = "-="
 		} else {
-			t = newToken(token.MINUS, l.char, line, index)
+			t = l.newToken(token.MINUS, line, index)
 		}
 	case '{':
 		// Fastly VCL allows bracket enclosed strings like {" foobar "}, along
@@ -162,60 +165,74 @@ func (l *Lexer) NextToken() token.Token {
 		})
 
 		if err != nil || delimiter[len(delimiter)-1] != '"' {
-			t = newToken(token.LEFT_BRACE, l.char, line, index)
+			t = l.newToken(token.LEFT_BRACE, line, index)
 			break
 		}
 
-		t = newToken(token.OPEN_LONG_STRING, l.char, line, index)
+		t = l.newToken(token.OPEN_LONG_STRING, line, index)
 		t.Literal = delimiter[:len(delimiter)-1]
 
 		l.skipBytes(len(delimiter))
 
-		st := newToken(token.STRING, l.char, l.line, l.index)
-		st.Literal = l.readBracketString(delimiter[:len(delimiter)-1])
+		st := l.newToken(token.STRING, l.line, l.index)
+		literal, terminated := l.readBracketString(delimiter[:len(delimiter)-1])
+		if !terminated {
+			// Closing delimiter is not found until EOF
+			t = l.newToken(token.ILLEGAL, line, index)
+			t.Literal = "{" + delimiter + literal
+			break
+		}
+		st.Literal = literal
 		st.Offset = 2 + len(delimiter)*2
 		l.pushToken(st)
 
-		ct := newToken(token.CLOSE_LONG_STRING, l.char, l.line, l.index)
+		ct := l.newToken(token.CLOSE_LONG_STRING, l.line, l.index)
 		ct.Literal = delimiter[:len(delimiter)-1]
 		l.pushToken(ct)
 	case '}':
-		t = newToken(token.RIGHT_BRACE, l.char, line, index)
+		t = l.newToken(token.RIGHT_BRACE, line, index)
 	case '(':
-		t = newToken(token.LEFT_PAREN, l.char, line, index)
+		t = l.newToken(token.LEFT_PAREN, line, index)
 	case ')':
-		t = newToken(token.RIGHT_PAREN, l.char, line, index)
+		t = l.newToken(token.RIGHT_PAREN, line, index)
 	case '[':
-		t = newToken(token.LEFT_BRACKET, l.char, line, index)
+		t = l.newToken(token.LEFT_BRACKET, line, index)
 	case ']':
-		t = newToken(token.RIGHT_BRACKET, l.char, line, index)
+		t = l.newToken(token.RIGHT_BRACKET, line, index)
 	case '"':
-		t = newToken(token.STRING, l.char, line, index)
-		t.Literal = l.readString()
+		literal, terminated := l.readString()
+		if !terminated {
+			// Line feed or EOF appears before closing double quote
+			t = l.newToken(token.ILLEGAL, line, index)
+			t.Literal = `"` + literal
+			break
+		}
+		t = l.newToken(token.STRING, line, index)
+		t.Literal = literal
 		t.Offset = 2 // a couple of "
 	case ';':
-		t = newToken(token.SEMICOLON, l.char, line, index)
+		t = l.newToken(token.SEMICOLON, line, index)
 	case '.':
-		t = newToken(token.DOT, l.char, line, index)
+		t = l.newToken(token.DOT, line, index)
 	case ',':
-		t = newToken(token.COMMA, l.char, line, index)
+		t = l.newToken(token.COMMA, line, index)
 	case '/':
 		switch l.peekChar() {
 		case '=':
 			l.readChar()
-			t = newToken(token.DIVISION, l.char, line, index)
+			t = l.newToken(token.DIVISION, line, index)
 			t.Literal = "/="
 		case '/':
-			t = newToken(token.COMMENT, l.char, line, index)
+			t = l.newToken(token.COMMENT, line, index)
 			t.Literal = l.readEOL()
 		case '*': // "/*"
-			t = newToken(token.COMMENT, l.char, line, index)
+			t = l.newToken(token.COMMENT, line, index)
 			t.Literal = l.readMultiComment()
 		default:
-			t = newToken(token.SLASH, l.char, line, index)
+			t = l.newToken(token.SLASH, line, index)
 		}
 	case '#':
-		t = newToken(token.COMMENT, l.char, line, index)
+		t = l.newToken(token.COMMENT, line, index)
 		t.Literal = l.readEOL()
 	case '|':
 		switch l.peekChar() {
@@ -223,15 +240,15 @@ func (l *Lexer) NextToken() token.Token {
 			l.readChar()
 			if l.peekChar() == '=' { // "||="
 				l.readChar()
-				t = newToken(token.LOGICAL_OR, l.char, line, index)
+				t = l.newToken(token.LOGICAL_OR, line, index)
 				t.Literal = "||="
 			} else { // "||"
-				t = newToken(token.OR, l.char, line, index)
+				t = l.newToken(token.OR, line, index)
 				t.Literal = "||"
 			}
 		case '=': // "|="
 			l.readChar()
-			t = newToken(token.BITWISE_OR, l.char, line, index)
+			t = l.newToken(token.BITWISE_OR, line, index)
 			t.Literal = "|="
 		}
 	case '&':
@@ -240,32 +257,32 @@ func (l *Lexer) NextToken() token.Token {
 			l.readChar()
 			if l.peekChar() == '=' { // "&&="
 				l.readChar()
-				t = newToken(token.LOGICAL_AND, l.char, line, index)
+				t = l.newToken(token.LOGICAL_AND, line, index)
 				t.Literal = "&&="
 			} else { // "&&"
-				t = newToken(token.AND, l.char, line, index)
+				t = l.newToken(token.AND, line, index)
 				t.Literal = "&&"
 			}
 		case '=': // "&="
 			l.readChar()
-			t = newToken(token.BITWISE_AND, l.char, line, index)
+			t = l.newToken(token.BITWISE_AND, line, index)
 			t.Literal = "&="
 		}
 	case '^':
 		if l.peekChar() == '=' { // "^="
 			l.readChar()
-			t = newToken(token.BITWISE_XOR, l.char, line, index)
+			t = l.newToken(token.BITWISE_XOR, line, index)
 			t.Literal = "^="
 		}
 	case '+':
 		if l.peekChar() == '=' {
 			l.readChar()
-			t = newToken(token.ADDITION, l.char, line, index)
+			t = l.newToken(token.ADDITION, line, index)
 			t.Literal = "+="
 		} else {
 			// NOTE: The "+" character is not used for arithmetic operator in VCL,
 			// just use for explicit string concatenation.
-			t = newToken(token.PLUS, l.char, line, index)
+			t = l.newToken(token.PLUS, line, index)
 		}
 	case '>':
 		switch l.peekChar() {
@@ -273,15 +290,15 @@ func (l *Lexer) NextToken() token.Token {
 			l.readChar()
 			if l.peekChar() == '=' { // ">>="
 				l.readChar()
-				t = newToken(token.RIGHT_SHIFT, l.char, line, index)
+				t = l.newToken(token.RIGHT_SHIFT, line, index)
 				t.Literal = ">>="
 			}
 		case '=': // ">="
 			l.readChar()
-			t = newToken(token.GREATER_THAN_EQUAL, l.char, line, index)
+			t = l.newToken(token.GREATER_THAN_EQUAL, line, index)
 			t.Literal = ">="
 		default:
-			t = newToken(token.GREATER_THAN, l.char, line, index)
+			t = l.newToken(token.GREATER_THAN, line, index)
 		}
 	case '<':
 		switch l.peekChar() {
@@ -289,46 +306,46 @@ func (l *Lexer) NextToken() token.Token {
 			l.readChar()
 			if l.peekChar() == '=' { // "<<="
 				l.readChar()
-				t = newToken(token.LEFT_SHIFT, l.char, line, index)
+				t = l.newToken(token.LEFT_SHIFT, line, index)
 				t.Literal = "<<="
 			}
 		case '=': // ">="
 			l.readChar()
-			t = newToken(token.LESS_THAN_EQUAL, l.char, line, index)
+			t = l.newToken(token.LESS_THAN_EQUAL, line, index)
 			t.Literal = "<="
 		default:
-			t = newToken(token.LESS_THAN, l.char, line, index)
+			t = l.newToken(token.LESS_THAN, line, index)
 		}
 	case '%':
 		index := l.index
 		if l.peekChar() == '=' { // "%="
 			l.readChar()
-			t = newToken(token.REMAINDER, l.char, line, index)
+			t = l.newToken(token.REMAINDER, line, index)
 			t.Literal = "%="
 		} else {
-			t = newToken(token.PERCENT, l.char, line, index)
+			t = l.newToken(token.PERCENT, line, index)
 		}
 	case ':':
-		t = newToken(token.COLON, l.char, line, index)
+		t = l.newToken(token.COLON, line, index)
 	case '~':
-		t = newToken(token.REGEX_MATCH, l.char, line, index)
+		t = l.newToken(token.REGEX_MATCH, line, index)
 	case '!':
 		switch l.peekChar() {
 		case '=': // "!="
 			l.readChar()
-			t = newToken(token.NOT_EQUAL, l.char, line, index)
+			t = l.newToken(token.NOT_EQUAL, line, index)
 			t.Literal = "!="
 		case '~': // "!~"
 			l.readChar()
-			t = newToken(token.NOT_REGEX_MATCH, l.char, line, index)
+			t = l.newToken(token.NOT_REGEX_MATCH, line, index)
 			t.Literal = "!~"
 		default:
-			t = newToken(token.NOT, l.char, line, index)
+			t = l.newToken(token.NOT, line, index)
 		}
 	case '*':
 		if l.peekChar() == '=' { // "*="
 			l.readChar()
-			t = newToken(token.MULTIPLICATION, l.char, line, index)
+			t = l.newToken(token.MULTIPLICATION, line, index)
 			t.Literal = "*="
 		}
 	case 0x00: // EOF
@@ -341,27 +358,28 @@ func (l *Lexer) NextToken() token.Token {
 			l.isEOF = true
 		}
 	case 0x0A: // '\n'
-		t = newToken(token.LF, l.char, line, index)
+		t = l.newToken(token.LF, line, index)
 	default:
 		// Fastly control syntaxes
+		start := l.cur
 		if l.char == 0x43 || l.char == 0x57 { // "C" or "W"
-			c := l.char
 			if l.peekChar() == '!' { // "C!" or "W!"
 				l.readChar()
-				t = newToken(token.FASTLY_CONTROL, l.char, line, index)
-				t.Literal = string(c) + "!"
+				t = l.newToken(token.FASTLY_CONTROL, line, index)
+				t.Literal = l.src[start:l.pos]
 				break
 			}
 		}
 
 		switch {
 		case isLetter(l.char):
-			literal := l.readIdentifier()
+			l.readIdentifier()
+			literal := l.src[start:l.cur]
 
 			// Switch's default case keyword needs special handling due to the header
 			// field access syntax.
 			if literal == "default" {
-				t = newToken(token.DEFAULT, l.char, line, index)
+				t = l.newToken(token.DEFAULT, line, index)
 				t.Literal = literal
 				t.File = l.file
 				return t
@@ -371,15 +389,15 @@ func (l *Lexer) NextToken() token.Token {
 			// in order to lex digit contained identifier like "version4", "req.http.Cookie:session" string.
 			// For asterisk ('*'), support wildcard prefix match for unset/remove statement.
 			for l.char == '-' || l.char == '.' || l.char == ':' || l.char == '*' || isDigit(l.char) {
-				literal += string(l.char)
 				l.readChar()
-				literal += l.readIdentifier()
+				l.readIdentifier()
 			}
+			literal = l.src[start:l.cur]
 
 			switch literal {
 			case "rol":
 				if l.char == '=' { // "rol="
-					t = newToken(token.LEFT_ROTATE, l.char, line, index)
+					t = l.newToken(token.LEFT_ROTATE, line, index)
 					t.Literal = "rol="
 				} else {
 					t.Literal = literal
@@ -391,7 +409,7 @@ func (l *Lexer) NextToken() token.Token {
 				}
 			case "ror":
 				if l.char == '=' { // "ror="
-					t = newToken(token.RIGHT_ROTATE, l.char, line, index)
+					t = l.newToken(token.RIGHT_ROTATE, line, index)
 					t.Literal = "ror="
 				} else {
 					t.Literal = literal
@@ -424,28 +442,28 @@ func (l *Lexer) NextToken() token.Token {
 			case 'm':
 				if l.peekChar() == 's' { // "ms"
 					l.readChar()
-					t = newToken(token.RTIME, l.char, line, index)
-					t.Literal = num + "ms" // millisecond
+					t = l.newToken(token.RTIME, line, index)
+					t.Literal = l.src[start:l.pos] // millisecond
 				} else {
-					t = newToken(token.RTIME, l.char, line, index)
-					t.Literal = num + "m" // month
+					t = l.newToken(token.RTIME, line, index)
+					t.Literal = l.src[start:l.pos] // month
 				}
 			case 's', 'h', 'd', 'y': // second, hour, day, year
-				t = newToken(token.RTIME, l.char, line, index)
-				t.Literal = num + string(l.char)
+				t = l.newToken(token.RTIME, line, index)
+				t.Literal = l.src[start:l.pos]
 			default:
 				// If literal contains ".", token should be FLOAT
 				if strings.Count(num, ".") == 1 {
-					t = newToken(token.FLOAT, l.char, line, index)
+					t = l.newToken(token.FLOAT, line, index)
 				} else {
-					t = newToken(token.INT, l.char, line, index)
+					t = l.newToken(token.INT, line, index)
 				}
 				t.Literal = num
 				t.File = l.file
 				return t
 			}
 		default:
-			t = newToken(token.ILLEGAL, l.char, line, index)
+			t = l.newToken(token.ILLEGAL, line, index)
 		}
 	}
 
@@ -482,10 +500,11 @@ func isLongStringDelimiter(r rune) bool {
 	return (r != '.' && (isLetter(r) || isDigit(r)))
 }
 
-func newToken(tokenType token.TokenType, literal rune, line, index int) token.Token {
+// newToken creates the token which has the current character as literal
+func (l *Lexer) newToken(tokenType token.TokenType, line, index int) token.Token {
 	return token.Token{
 		Type:     tokenType,
-		Literal:  string(literal),
+		Literal:  l.src[l.cur:l.pos],
 		Line:     line,
 		Position: index,
 	}
@@ -553,11 +553,14 @@ aabbccddIieEffggHHhEXAMPLEPUBLICKEY
 }
 
 func TestLexerLine(t *testing.T) {
-	t.Run("include LF in string literal", func(t *testing.T) {
+	t.Run("LF in double-quoted string is unterminated", func(t *testing.T) {
 		input := `"foo
 bar"`
 		expects := []token.Token{
-			{Type: token.STRING, Literal: "foo\nbar", Line: 1, Position: 1},
+			{Type: token.ILLEGAL, Literal: `"foo`, Line: 1, Position: 1},
+			{Type: token.LF, Literal: "\n", Line: 1, Position: 5},
+			{Type: token.IDENT, Literal: "bar", Line: 2, Position: 1},
+			{Type: token.ILLEGAL, Literal: `"`, Line: 2, Position: 4},
 			{Type: token.EOF, Literal: "", Line: 2, Position: 5},
 		}
 
@@ -694,6 +697,62 @@ func TestEscapeSequence(t *testing.T) {
 	}
 }
 
+func TestUnterminatedString(t *testing.T) {
+	tests := []struct {
+		input   string
+		expects []token.Token
+	}{
+		{
+			input: "set req.http.A = \"foo;",
+			expects: []token.Token{
+				{Type: token.SET, Literal: "set", Line: 1, Position: 1},
+				{Type: token.IDENT, Literal: "req.http.A", Line: 1, Position: 5},
+				{Type: token.ASSIGN, Literal: "=", Line: 1, Position: 16},
+				{Type: token.ILLEGAL, Literal: `"foo;`, Line: 1, Position: 18},
+				{Type: token.EOF, Literal: "", Line: 1, Position: 23},
+			},
+		},
+		{
+			// Missing closing quote must not be closed by the quote in following lines
+			input: "set req.http.A = \"foo;\nset req.http.B = \"bar\";",
+			expects: []token.Token{
+				{Type: token.SET, Literal: "set", Line: 1, Position: 1},
+				{Type: token.IDENT, Literal: "req.http.A", Line: 1, Position: 5},
+				{Type: token.ASSIGN, Literal: "=", Line: 1, Position: 16},
+				{Type: token.ILLEGAL, Literal: `"foo;`, Line: 1, Position: 18},
+				{Type: token.LF, Literal: "\n", Line: 1, Position: 23},
+				{Type: token.SET, Literal: "set", Line: 2, Position: 1},
+				{Type: token.IDENT, Literal: "req.http.B", Line: 2, Position: 5},
+				{Type: token.ASSIGN, Literal: "=", Line: 2, Position: 16},
+				{Type: token.STRING, Literal: "bar", Line: 2, Position: 18},
+				{Type: token.SEMICOLON, Literal: ";", Line: 2, Position: 23},
+				{Type: token.EOF, Literal: "", Line: 2, Position: 24},
+			},
+		},
+		{
+			input: "set req.http.B = {JSON\"abc\"}",
+			expects: []token.Token{
+				{Type: token.SET, Literal: "set", Line: 1, Position: 1},
+				{Type: token.IDENT, Literal: "req.http.B", Line: 1, Position: 5},
+				{Type: token.ASSIGN, Literal: "=", Line: 1, Position: 16},
+				{Type: token.ILLEGAL, Literal: `{JSON"abc"}`, Line: 1, Position: 18},
+				{Type: token.EOF, Literal: "", Line: 1, Position: 30},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		l := NewFromString(tt.input)
+		for i, expect := range tt.expects {
+			tok := l.NextToken()
+
+			if diff := cmp.Diff(expect, tok, cmpopts.IgnoreFields(token.Token{}, "Offset")); diff != "" {
+				t.Errorf(`Tests[%d] failed, diff= %s`, i, diff)
+			}
+		}
+	}
+}
+
 func TestPeekToken(t *testing.T) {
 	input := `set var.expires`
 	l := NewFromString(input)
@@ -794,3 +853,21 @@ func TestFastlyControlSyntaxes(t *testing.T) {
 		}
 	})
 }
+
+func TestLexerAllocations(t *testing.T) {
+	input := `sub vcl_recv {
+  # comment
+  set req.http.Foo = "bar" + req.http.Baz {"long string"} 10ms;
+}`
+	construct := testing.AllocsPerRun(100, func() {
+		NewFromString(input)
+	})
+	tokenize := testing.AllocsPerRun(100, func() {
+		l := NewFromString(input)
+		for l.NextToken().Type != token.EOF {
+		}
+	})
+	if tokenize != construct {
+		t.Errorf("Tokenizing must not allocate, got %.0f allocations", tokenize-construct)
+	}
+}
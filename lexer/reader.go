@@ -1,107 +1,79 @@
 package lexer
 
 import (
-	"bytes"
-	"sync"
+	"strings"
 )
 
-var pool = sync.Pool{
-	New: func() any {
-		return new(bytes.Buffer)
-	},
-}
-
-func (l *Lexer) readString() string {
-	buf := pool.Get().(*bytes.Buffer) // nolint:errcheck
-	defer pool.Put(buf)
-	buf.Reset()
-
-	l.readChar()
-	for l.char != '"' && l.char != 0x00 {
-		buf.WriteRune(l.char)
+// Read double-quoted string and report whether the string is terminated.
+// Double-quoted string could not contain line feed, so the string is unterminated
+// when line feed or EOF appears before closing double quote. Line feed is not consumed
+func (l *Lexer) readString() (string, bool) {
+	start := l.pos
+	for {
+		switch l.peekChar() {
+		case '"':
+			l.readChar()
+			return l.src[start:l.cur], true
+		case '\n', 0x00:
+			return l.src[start:l.pos], false
+		}
 		l.readChar()
 	}
-
-	return buf.String()
 }
 
-func (l *Lexer) readBracketString(delimiter string) string {
-	var rs []rune
-	end := []byte(delimiter + "}")
+// Read long string until closing "delimiter} appears and report whether the string is terminated.
+func (l *Lexer) readBracketString(delimiter string) (string, bool) {
+	end := delimiter + "}"
 	l.readChar()
+	start := l.cur
 	for l.char != 0x00 {
-		if l.char == '"' {
-			n, err := l.r.Peek(len(end))
-			if err != nil {
-				break
-			}
-
-			if bytes.Equal(end, n) {
-				l.skipBytes(len(end))
-				break
-			}
+		if l.char == '"' && strings.HasPrefix(l.src[l.pos:], end) {
+			literal := l.src[start:l.cur]
+			l.skipBytes(len(end))
+			return literal, true
 		}
-		rs = append(rs, l.char)
 		l.readChar()
 	}
 
-	return string(rs)
+	return l.src[start:l.cur], false
 }
 
 func (l *Lexer) readNumber() string {
-	buf := pool.Get().(*bytes.Buffer) // nolint:errcheck
-	defer pool.Put(buf)
-	buf.Reset()
-
+	start := l.cur
 	for isDigit(l.char) {
-		buf.WriteRune(l.char)
 		l.readChar()
 	}
-	return buf.String()
+	return l.src[start:l.cur]
 }
 
 func (l *Lexer) readEOL() string {
-	buf := pool.Get().(*bytes.Buffer) // nolint:errcheck
-	defer pool.Put(buf)
-	buf.Reset()
-
+	start := l.cur
 	for {
-		buf.WriteRune(l.char)
 		if l.peekChar() == 0x00 || l.peekChar() == '\n' {
 			break
 		}
 		l.readChar()
 	}
-	return buf.String()
+	return l.src[start:l.pos]
 }
 
 func (l *Lexer) readMultiComment() string {
-	buf := pool.Get().(*bytes.Buffer) // nolint:errcheck
-	defer pool.Put(buf)
-	buf.Reset()
-
+	start := l.cur
 	for l.char != 0x00 {
 		if l.char == '*' && l.peekChar() == '/' {
-			buf.WriteRune(l.char)
 			l.readChar()
-			buf.WriteRune(l.char)
-			break
+			return l.src[start:l.pos]
 		}
-		buf.WriteRune(l.char)
 		l.readChar()
 	}
 
-	return buf.String()
+	return l.src[start:l.cur]
 }
 
 func (l *Lexer) readIdentifier() string {
-	buf := pool.Get().(*bytes.Buffer) // nolint:errcheck
-	defer pool.Put(buf)
-	buf.Reset()
-
+	start := l.cur
 	for isLetter(l.char) {
-		buf.WriteRune(l.char)
 		l.readChar()
 	}
-	return buf.String()
+	return l.src[start:l.cur]
 }
@@ -0,0 +1,163 @@
+package lexer
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/ysugimoto/falco/v2/token"
+)
+
+// SemanticClass is the classification of the token for syntax highlighting,
+// values are named after the semantic token types of Language Server Protocol as much as possible
+type SemanticClass string
+
+const (
+	ClassKeyword     SemanticClass = "keyword"     // sub, set, if, return, etc
+	ClassType        SemanticClass = "type"        // STRING, INTEGER, etc in declarations
+	ClassVariable    SemanticClass = "variable"    // req.http.Host, var.foo, etc
+	ClassProperty    SemanticClass = "property"    // .host in declarations
+	ClassFunction    SemanticClass = "function"    // std.strlen, subroutine name of sub and call
+	ClassIdentifier  SemanticClass = "identifier"  // backend, acl, table name, return state, etc
+	ClassString      SemanticClass = "string"      // "foo", {"foo"}
+	ClassNumber      SemanticClass = "number"      // 10, 1.5
+	ClassDuration    SemanticClass = "duration"    // 10s, 1.5m
+	ClassBoolean     SemanticClass = "boolean"     // true, false
+	ClassOperator    SemanticClass = "operator"    // ==, +=, &&, etc
+	ClassComment     SemanticClass = "comment"     // #, //, /* */
+	ClassPunctuation SemanticClass = "punctuation" // {, }, (, ), ;, etc
+	ClassControl     SemanticClass = "control"     // C! and W! which are generated by Fastly
+	ClassInvalid     SemanticClass = "invalid"     // illegal character or unterminated string
+)
+
+// Type names which appear in declare statement and functional subroutine declaration
+var semanticTypes = []string{
+	"ACL", "BACKEND", "BOOL", "FLOAT", "HASH", "ID", "INTEGER", "IP", "RTIME", "STRING", "TIME", "VOID",
+}
+
+// SemanticToken is the classified token with its range in the source.
+// Line and Position are 1-based, EndLine and EndPosition point to the next character of the token
+type SemanticToken struct {
+	Class       SemanticClass
+	Literal     string
+	Namespace   string // first segment of the variable name like "req" or "var", only set for variables
+	Line        int
+	Position    int
+	EndLine     int
+	EndPosition int
+}
+
+// SemanticTokens tokenizes the input and classifies tokens for syntax highlighting.
+// Linefeeds are not included, and bracket enclosed string like {"foo"} is yielded as single string token
+func SemanticTokens(input string, opts ...OptionFunc) []SemanticToken {
+	l := NewFromString(input, opts...)
+
+	// Collect all tokens at first because identifiers are classified by the following token
+	var raw []token.Token
+	var tokens []SemanticToken
+	for {
+		t := l.NextToken()
+		if t.Type == token.EOF {
+			break
+		}
+		if t.Type == token.LF {
+			continue
+		}
+		// Lexer has already read until the end of the token including following long string tokens
+		st := SemanticToken{
+			Literal:     t.Literal,
+			Line:        t.Line,
+			Position:    t.Position,
+			EndLine:     l.line,
+			EndPosition: l.index,
+		}
+		if t.Type == token.OPEN_LONG_STRING {
+			var literal strings.Builder
+			for len(l.peeks) > 0 {
+				if nt := l.NextToken(); nt.Type == token.STRING {
+					literal.WriteString(nt.Literal)
+				}
+			}
+			st.Literal = literal.String()
+			t.Type = token.STRING
+		}
+		raw = append(raw, t)
+		tokens = append(tokens, st)
+	}
+
+	for i, t := range raw {
+		var prev, next token.TokenType
+		if i > 0 {
+			prev = raw[i-1].Type
+		}
+		if i < len(raw)-1 {
+			next = raw[i+1].Type
+		}
+		tokens[i].Class = l.classify(t, prev, next)
+		if tokens[i].Class == ClassVariable {
+			tokens[i].Namespace, _, _ = strings.Cut(t.Literal, ".")
+		}
+	}
+	return tokens
+}
+
+// nolint: gocyclo
+func (l *Lexer) classify(t token.Token, prev, next token.TokenType) SemanticClass {
+	switch t.Type {
+	case token.ACL, token.BACKEND, token.DIRECTOR, token.TABLE, token.SUBROUTINE,
+		token.ADD, token.CALL, token.DECLARE, token.ERROR, token.ESI,
+		token.INCLUDE, token.IMPORT, token.LOG, token.REMOVE, token.RESTART,
+		token.RETURN, token.SET, token.SYNTHETIC, token.SYNTHETIC_BASE64, token.UNSET,
+		token.IF, token.ELSE, token.ELSEIF, token.ELSIF,
+		token.PENALTYBOX, token.RATECOUNTER, token.GOTO,
+		token.SWITCH, token.CASE, token.DEFAULT, token.BREAK, token.FALLTHROUGH,
+		token.PRAGMA, token.CONST:
+		return ClassKeyword
+	case token.STRING, token.OPEN_LONG_STRING, token.CLOSE_LONG_STRING:
+		return ClassString
+	case token.INT, token.FLOAT:
+		return ClassNumber
+	case token.RTIME:
+		return ClassDuration
+	case token.TRUE, token.FALSE:
+		return ClassBoolean
+	case token.COMMENT:
+		return ClassComment
+	case token.FASTLY_CONTROL:
+		return ClassControl
+	case token.ILLEGAL:
+		return ClassInvalid
+	case token.LEFT_BRACE, token.RIGHT_BRACE, token.LEFT_PAREN, token.RIGHT_PAREN,
+		token.LEFT_BRACKET, token.RIGHT_BRACKET, token.COMMA, token.SEMICOLON,
+		token.DOT, token.COLON:
+		return ClassPunctuation
+	case token.IDENT:
+		return classifyIdent(t, prev, next)
+	}
+
+	// Custom tokens like "describe" in testing VCL are keywords
+	for _, v := range l.customs {
+		if v == t.Type {
+			return ClassKeyword
+		}
+	}
+	return ClassOperator
+}
+
+func classifyIdent(t token.Token, prev, next token.TokenType) SemanticClass {
+	switch prev {
+	case token.DOT:
+		return ClassProperty
+	case token.SUBROUTINE, token.CALL:
+		return ClassFunction
+	}
+	if next == token.LEFT_PAREN {
+		return ClassFunction
+	}
+	if slices.Contains(semanticTypes, t.Literal) {
+		return ClassType
+	}
+	if strings.Contains(t.Literal, ".") {
+		return ClassVariable
+	}
+	return ClassIdentifier
+}
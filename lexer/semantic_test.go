@@ -0,0 +1,89 @@
+package lexer
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/ysugimoto/falco/v2/token"
+)
+
+func TestSemanticTokens(t *testing.T) {
+	input := `backend F_origin {
+  .host = "example.com";
+}
+
+sub vcl_recv {
+  declare local var.ttl RTIME;
+  set var.ttl = 10s;
+  set req.http.X-Body = {"a"b"};
+  if (std.strlen(req.url) > 1) {
+    call check;
+  }
+  /* multi
+     line */
+  return (lookup);
+}
+`
+	expect := []SemanticToken{
+		{Class: ClassKeyword, Literal: "backend", Line: 1, Position: 1, EndLine: 1, EndPosition: 8},
+		{Class: ClassIdentifier, Literal: "F_origin", Line: 1, Position: 9, EndLine: 1, EndPosition: 17},
+		{Class: ClassPunctuation, Literal: "{", Line: 1, Position: 18, EndLine: 1, EndPosition: 19},
+		{Class: ClassPunctuation, Literal: ".", Line: 2, Position: 3, EndLine: 2, EndPosition: 4},
+		{Class: ClassProperty, Literal: "host", Line: 2, Position: 4, EndLine: 2, EndPosition: 8},
+		{Class: ClassOperator, Literal: "=", Line: 2, Position: 9, EndLine: 2, EndPosition: 10},
+		{Class: ClassString, Literal: "example.com", Line: 2, Position: 11, EndLine: 2, EndPosition: 24},
+		{Class: ClassPunctuation, Literal: ";", Line: 2, Position: 24, EndLine: 2, EndPosition: 25},
+		{Class: ClassPunctuation, Literal: "}", Line: 3, Position: 1, EndLine: 3, EndPosition: 2},
+		{Class: ClassKeyword, Literal: "sub", Line: 5, Position: 1, EndLine: 5, EndPosition: 4},
+		{Class: ClassFunction, Literal: "vcl_recv", Line: 5, Position: 5, EndLine: 5, EndPosition: 13},
+		{Class: ClassPunctuation, Literal: "{", Line: 5, Position: 14, EndLine: 5, EndPosition: 15},
+		{Class: ClassKeyword, Literal: "declare", Line: 6, Position: 3, EndLine: 6, EndPosition: 10},
+		{Class: ClassIdentifier, Literal: "local", Line: 6, Position: 11, EndLine: 6, EndPosition: 16},
+		{Class: ClassVariable, Literal: "var.ttl", Namespace: "var", Line: 6, Position: 17, EndLine: 6, EndPosition: 24},
+		{Class: ClassType, Literal: "RTIME", Line: 6, Position: 25, EndLine: 6, EndPosition: 30},
+		{Class: ClassPunctuation, Literal: ";", Line: 6, Position: 30, EndLine: 6, EndPosition: 31},
+		{Class: ClassKeyword, Literal: "set", Line: 7, Position: 3, EndLine: 7, EndPosition: 6},
+		{Class: ClassVariable, Literal: "var.ttl", Namespace: "var", Line: 7, Position: 7, EndLine: 7, EndPosition: 14},
+		{Class: ClassOperator, Literal: "=", Line: 7, Position: 15, EndLine: 7, EndPosition: 16},
+		{Class: ClassDuration, Literal: "10s", Line: 7, Position: 17, EndLine: 7, EndPosition: 20},
+		{Class: ClassPunctuation, Literal: ";", Line: 7, Position: 20, EndLine: 7, EndPosition: 21},
+		{Class: ClassKeyword, Literal: "set", Line: 8, Position: 3, EndLine: 8, EndPosition: 6},
+		{Class: ClassVariable, Literal: "req.http.X-Body", Namespace: "req", Line: 8, Position: 7, EndLine: 8, EndPosition: 22},
+		{Class: ClassOperator, Literal: "=", Line: 8, Position: 23, EndLine: 8, EndPosition: 24},
+		{Class: ClassString, Literal: `a"b`, Line: 8, Position: 25, EndLine: 8, EndPosition: 32},
+		{Class: ClassPunctuation, Literal: ";", Line: 8, Position: 32, EndLine: 8, EndPosition: 33},
+		{Class: ClassKeyword, Literal: "if", Line: 9, Position: 3, EndLine: 9, EndPosition: 5},
+		{Class: ClassPunctuation, Literal: "(", Line: 9, Position: 6, EndLine: 9, EndPosition: 7},
+		{Class: ClassFunction, Literal: "std.strlen", Line: 9, Position: 7, EndLine: 9, EndPosition: 17},
+		{Class: ClassPunctuation, Literal: "(", Line: 9, Position: 17, EndLine: 9, EndPosition: 18},
+		{Class: ClassVariable, Literal: "req.url", Namespace: "req", Line: 9, Position: 18, EndLine: 9, EndPosition: 25},
+		{Class: ClassPunctuation, Literal: ")", Line: 9, Position: 25, EndLine: 9, EndPosition: 26},
+		{Class: ClassOperator, Literal: ">", Line: 9, Position: 27, EndLine: 9, EndPosition: 28},
+		{Class: ClassNumber, Literal: "1", Line: 9, Position: 29, EndLine: 9, EndPosition: 30},
+		{Class: ClassPunctuation, Literal: ")", Line: 9, Position: 30, EndLine: 9, EndPosition: 31},
+		{Class: ClassPunctuation, Literal: "{", Line: 9, Position: 32, EndLine: 9, EndPosition: 33},
+		{Class: ClassKeyword, Literal: "call", Line: 10, Position: 5, EndLine: 10, EndPosition: 9},
+		{Class: ClassFunction, Literal: "check", Line: 10, Position: 10, EndLine: 10, EndPosition: 15},
+		{Class: ClassPunctuation, Literal: ";", Line: 10, Position: 15, EndLine: 10, EndPosition: 16},
+		{Class: ClassPunctuation, Literal: "}", Line: 11, Position: 3, EndLine: 11, EndPosition: 4},
+		{Class: ClassComment, Literal: "/* multi\n     line */", Line: 12, Position: 3, EndLine: 13, EndPosition: 13},
+		{Class: ClassKeyword, Literal: "return", Line: 14, Position: 3, EndLine: 14, EndPosition: 9},
+		{Class: ClassPunctuation, Literal: "(", Line: 14, Position: 10, EndLine: 14, EndPosition: 11},
+		{Class: ClassIdentifier, Literal: "lookup", Line: 14, Position: 11, EndLine: 14, EndPosition: 17},
+		{Class: ClassPunctuation, Literal: ")", Line: 14, Position: 17, EndLine: 14, EndPosition: 18},
+		{Class: ClassPunctuation, Literal: ";", Line: 14, Position: 18, EndLine: 14, EndPosition: 19},
+		{Class: ClassPunctuation, Literal: "}", Line: 15, Position: 1, EndLine: 15, EndPosition: 2},
+	}
+	if diff := cmp.Diff(expect, SemanticTokens(input)); diff != "" {
+		t.Errorf("Semantic tokens mismatch, diff=%s", diff)
+	}
+
+	t.Run("custom tokens are keywords", func(t *testing.T) {
+		tokens := SemanticTokens(`describe foo {}`, WithCustomTokens(map[string]token.TokenType{
+			"describe": token.Custom("DESCRIBE"),
+		}))
+		if tokens[0].Class != ClassKeyword {
+			t.Errorf("Expected keyword, got %s", tokens[0].Class)
+		}
+	})
+}
@@ -0,0 +1,150 @@
+// Falco's lint cache stores lint results on the local disk.
+// An entry is keyed by the content hash of the main VCL and configurations,
+// and records the content hashes of included files to be invalidated when any of them is changed.
+// Include statements are also re-resolved on lookup because a new file which is found earlier
+// on the include paths could shadow the cached module without changing any recorded files.
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"github.com/ysugimoto/falco/v2/linter"
+)
+
+type Entry struct {
+	Files    map[string]string   `json:"files"`    // file path -> content hash
+	Includes map[string]string   `json:"includes"` // include module name -> resolved file path, empty if not resolved
+	Errors   []*linter.LintError `json:"errors"`
+}
+
+// Resolver resolves the include module name to the file path, returns empty string if not resolved
+type Resolver func(module string) string
+
+type Cache struct {
+	dir string
+}
+
+// Create lint cache on specified directory, user cache directory is used if empty
+func New(dir string) (*Cache, error) {
+	if dir == "" {
+		d, err := os.UserCacheDir()
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		dir = filepath.Join(d, "falco", "lint")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &Cache{dir: dir}, nil
+}
+
+// Key returns the hash of contents which affect lint results
+func Key(contents ...[]byte) string {
+	h := sha256.New()
+	for i := range contents {
+		// Write length prefix to distinguish boundaries of contents
+		binary.Write(h, binary.BigEndian, uint64(len(contents[i]))) // nolint:errcheck
+		h.Write(contents[i])
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Fingerprint returns the identifier of running falco binary.
+// Rule sets are changed by upgrading falco so the fingerprint should be a part of cache key
+func Fingerprint() string {
+	exe, err := os.Executable()
+	if err != nil {
+		return ""
+	}
+	stat, err := os.Stat(exe)
+	if err != nil {
+		return exe
+	}
+	return fmt.Sprintf("%s:%d:%d", exe, stat.Size(), stat.ModTime().UnixNano())
+}
+
+func hashFile(file string) (string, error) {
+	buf, err := os.ReadFile(file)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	sum := sha256.Sum256(buf)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func (c *Cache) path(key string) string {
+	return filepath.Join(c.dir, key+".json")
+}
+
+// Lookup returns cached entry for the key.
+// Entry is treated as missed if any of recorded files has been changed or removed,
+// or any of include modules is resolved to the different file from the recorded one
+func (c *Cache) Lookup(key string, resolve Resolver) (*Entry, bool) {
+	buf, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return nil, false
+	}
+	var entry Entry
+	if err := json.Unmarshal(buf, &entry); err != nil {
+		return nil, false
+	}
+	for file, hash := range entry.Files {
+		if h, err := hashFile(file); err != nil || h != hash {
+			return nil, false
+		}
+	}
+	for module, file := range entry.Includes {
+		if resolve(module) != file {
+			return nil, false
+		}
+	}
+	return &entry, true
+}
+
+// Store lint errors for the key with content hashes of the files and resolved files of include modules
+func (c *Cache) Store(key string, files []string, includes map[string]string, lintErrors []*linter.LintError) error {
+	entry := &Entry{
+		Files:    make(map[string]string, len(files)),
+		Includes: includes,
+		Errors:   lintErrors,
+	}
+	for _, file := range files {
+		h, err := hashFile(file)
+		if err != nil {
+			return err
+		}
+		entry.Files[file] = h
+	}
+
+	buf, err := json.Marshal(entry)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	// Write to temporary file and rename it in order not to read incomplete entry from other process
+	fp, err := os.CreateTemp(c.dir, key+"-*.tmp")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer os.Remove(fp.Name())
+
+	if _, err := fp.Write(buf); err != nil {
+		fp.Close()
+		return errors.WithStack(err)
+	}
+	if err := fp.Close(); err != nil {
+		return errors.WithStack(err)
+	}
+	if err := os.Rename(fp.Name(), c.path(key)); err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
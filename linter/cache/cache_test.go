@@ -0,0 +1,100 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/ysugimoto/falco/v2/linter"
+	"github.com/ysugimoto/falco/v2/token"
+)
+
+func TestKey(t *testing.T) {
+	if Key([]byte("ab"), []byte("c")) == Key([]byte("a"), []byte("bc")) {
+		t.Errorf("Key must distinguish boundaries of contents")
+	}
+	if Key([]byte("a")) != Key([]byte("a")) {
+		t.Errorf("Key must be stable for the same contents")
+	}
+}
+
+func TestCache(t *testing.T) {
+	dir := t.TempDir()
+	c, err := New(filepath.Join(dir, "cache"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	include := filepath.Join(dir, "include.vcl")
+	if err := os.WriteFile(include, []byte("sub foo {}"), 0o644); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	lintErrors := []*linter.LintError{
+		{
+			Severity: linter.WARNING,
+			Token:    token.Token{Type: token.IDENT, Literal: "foo", Line: 1, Position: 5, File: include},
+			Message:  "Subroutine foo is unused",
+			Rule:     linter.UNUSED_DECLARATION,
+		},
+	}
+
+	// Resolve include module from the include paths in order like file resolver
+	includePaths := []string{filepath.Join(dir, "first"), dir}
+	resolve := func(module string) string {
+		for _, p := range includePaths {
+			file := filepath.Join(p, module+".vcl")
+			if _, err := os.Stat(file); err == nil {
+				return file
+			}
+		}
+		return ""
+	}
+
+	if _, ok := c.Lookup("key", resolve); ok {
+		t.Errorf("Lookup must miss before storing")
+	}
+	if err := c.Store("key", []string{include}, map[string]string{"include": include}, lintErrors); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	t.Run("hit", func(t *testing.T) {
+		entry, ok := c.Lookup("key", resolve)
+		if !ok {
+			t.Fatalf("Lookup must hit after storing")
+		}
+		if diff := cmp.Diff(lintErrors, entry.Errors); diff != "" {
+			t.Errorf("Cached errors mismatch, diff=%s", diff)
+		}
+	})
+
+	t.Run("miss when included file is shadowed", func(t *testing.T) {
+		shadow := filepath.Join(includePaths[0], "include.vcl")
+		if err := os.MkdirAll(includePaths[0], 0o755); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if err := os.WriteFile(shadow, []byte("sub foo {}"), 0o644); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		defer os.Remove(shadow)
+
+		if _, ok := c.Lookup("key", resolve); ok {
+			t.Errorf("Lookup must miss after the new file shadows included file")
+		}
+	})
+
+	t.Run("miss when included file is changed", func(t *testing.T) {
+		if err := os.WriteFile(include, []byte("sub bar {}"), 0o644); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if _, ok := c.Lookup("key", resolve); ok {
+			t.Errorf("Lookup must miss after changing included file")
+		}
+	})
+
+	t.Run("could not store not existing file", func(t *testing.T) {
+		if err := c.Store("other", []string{"snippet"}, nil, lintErrors); err == nil {
+			t.Errorf("Expects error but nil")
+		}
+	})
+}
@@ -2,6 +2,8 @@ package context
 
 import (
 	"fmt"
+	"maps"
+	"slices"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -64,10 +66,13 @@ type Context struct {
 	Subroutines       map[string]*types.Subroutine
 	Penaltyboxes      map[string]*types.Penaltybox
 	Ratecounters      map[string]*types.Ratecounter
+	Constants         map[string]*types.Const
 	Gotos             map[string]*types.Goto
 	GotoDestinations  map[string]struct{}
 	Identifiers       map[string]struct{}
 	RegexVariables    map[string]int
+	CollectedHeaders  map[string]struct{}
+	DeclaredLocals    map[string]struct{}
 	ReturnType        *types.Type
 	CurrentSubroutine *ast.SubroutineDeclaration
 }
@@ -83,9 +88,12 @@ func New(opts ...Option) *Context {
 		Subroutines:      make(map[string]*types.Subroutine),
 		Penaltyboxes:     make(map[string]*types.Penaltybox),
 		Ratecounters:     make(map[string]*types.Ratecounter),
+		Constants:        make(map[string]*types.Const),
 		Gotos:            make(map[string]*types.Goto),
 		GotoDestinations: make(map[string]struct{}),
 		RegexVariables:   newRegexMatchedValues(),
+		CollectedHeaders: make(map[string]struct{}),
+		DeclaredLocals:   make(map[string]struct{}),
 		Identifiers:      builtinIdentifiers(),
 		functions:        builtinFunctions(),
 		Variables:        predefinedVariables(),
@@ -135,6 +143,7 @@ func (c *Context) Restore() *Context {
 
 	// clear local variables
 	delete(c.Variables, "var")
+	c.DeclaredLocals = make(map[string]struct{})
 	// clear local goto definitions
 	c.Gotos = make(map[string]*types.Goto)
 
@@ -388,6 +397,16 @@ func (c *Context) AddRatecounter(name string, ratecounter *types.Ratecounter) er
 	return nil
 }
 
+func (c *Context) AddConst(name string, constant *types.Const) error {
+	// check existence
+	if _, duplicated := c.Constants[name]; duplicated {
+		return fmt.Errorf(`duplicate definition of const "%s"`, name)
+	} else {
+		c.Constants[name] = constant
+	}
+	return nil
+}
+
 func (c *Context) AddGoto(name string, newGoto *types.Goto) error {
 	// append colon to the goto name to be able to identify it when it is been used.
 	name += ":"
@@ -529,7 +548,9 @@ func (c *Context) Set(name string) (types.Type, error) {
 
 func (c *Context) Declare(name string, valueType types.Type, m *ast.Meta) error {
 	if _, err := c.Get(name); err == nil {
-		// If error is nil, variable already defined
+		// If error is nil, variable already defined.
+		// Mark as declared to avoid reporting cascading errors on this variable
+		c.DeclaredLocals[name] = struct{}{}
 		return fmt.Errorf(`variable "%s" is already declared`, name)
 	}
 
@@ -570,10 +591,24 @@ func (c *Context) Declare(name string, valueType types.Type, m *ast.Meta) error
 		Unset:  false,
 		Scopes: c.curMode,
 	}
+	c.DeclaredLocals[name] = struct{}{}
 
 	return nil
 }
 
+// IsDeclaredLocal returns true if the local variable is declared on every control flow path
+// to the current statement. Locals are declared when declare statement is executed,
+// so the variable which is declared only in a branch could not be used after the branch.
+func (c *Context) IsDeclaredLocal(name string) bool {
+	_, ok := c.DeclaredLocals[name]
+	return ok
+}
+
+// CopyDeclaredLocals returns copy of declared local variables to lint branch statements
+func (c *Context) CopyDeclaredLocals() map[string]struct{} {
+	return maps.Clone(c.DeclaredLocals)
+}
+
 func (c *Context) Unset(name string) error {
 	first, remains := splitName(name)
 
@@ -656,6 +691,153 @@ func (c *Context) GetFunction(name string) (*BuiltinFunction, error) {
 	return obj.Value, nil
 }
 
+// PredefinedVariable finds the predefined variable definition which matches the name regardless of the scope,
+// and returns its name. Dynamic part like the header name is replaced with "*", e.g. "req.http.Host" returns "req.http.*"
+func (c *Context) PredefinedVariable(name string) (string, bool) {
+	canonical, _, ok := c.lookupPredefinedVariable(name)
+	return canonical, ok
+}
+
+// PredefinedVariableType returns the type of the predefined variable regardless of the scope
+func (c *Context) PredefinedVariableType(name string) (types.Type, bool) {
+	_, accessor, ok := c.lookupPredefinedVariable(name)
+	if !ok {
+		return types.NeverType, false
+	}
+	return accessor.Get, true
+}
+
+func (c *Context) lookupPredefinedVariable(name string) (string, *Accessor, bool) {
+	first, remains := splitName(name)
+	if first == "re" {
+		if len(remains) == 2 && remains[0] == "group" {
+			return "re.group.*", &Accessor{Get: types.StringType}, true
+		}
+		return "", nil, false
+	}
+
+	obj, ok := c.Variables[first]
+	if !ok {
+		return "", nil, false
+	}
+	canonical := []string{first}
+	for _, key := range remains {
+		if v, ok := obj.Items[key]; ok {
+			obj = v
+			canonical = append(canonical, key)
+		} else if v, ok := obj.Items["%any%"]; ok {
+			obj = v
+			canonical = append(canonical, "*")
+		} else {
+			return "", nil, false
+		}
+	}
+	if obj == nil || obj.Value == nil {
+		return "", nil, false
+	}
+	return strings.Join(canonical, "."), obj.Value, true
+}
+
+// IsBuiltinFunction returns true if the name is Fastly builtin function regardless of the scope
+func (c *Context) IsBuiltinFunction(name string) bool {
+	return c.lookupBuiltinFunction(name) != nil
+}
+
+// IsBuiltinNamespace returns true if the name is the root of Fastly builtin functions or predefined variables
+// like "std" or "req", or the builtin function which does not have the namespace like "urlencode".
+// User defined functions and falco specific testing functions are not treated as builtin
+func (c *Context) IsBuiltinNamespace(name string) bool {
+	if _, ok := c.Variables[name]; ok {
+		return true
+	}
+	if name == "testing" {
+		return false
+	}
+	fn, ok := c.functions[name]
+	if !ok {
+		return false
+	}
+	return fn.Value == nil || !fn.Value.IsUserDefinedFunction
+}
+
+// BuiltinFunctionReturnType returns the return type of the builtin function regardless of the scope
+func (c *Context) BuiltinFunctionReturnType(name string) (types.Type, bool) {
+	fn := c.lookupBuiltinFunction(name)
+	if fn == nil {
+		return types.NeverType, false
+	}
+	return fn.Return, true
+}
+
+func (c *Context) lookupBuiltinFunction(name string) *BuiltinFunction {
+	first, remains := splitName(name)
+	obj, ok := c.functions[first]
+	if !ok {
+		return nil
+	}
+	for _, key := range remains {
+		if obj, ok = obj.Items[key]; !ok {
+			return nil
+		}
+	}
+	if obj == nil {
+		return nil
+	}
+	return obj.Value
+}
+
+// VariableNames returns names of all predefined and declared local variables regardless of the scope.
+// Variables which have the dynamic part like "req.http.*" are not included
+func (c *Context) VariableNames() []string {
+	var names []string
+	var walk func(prefix string, obj *Object)
+	walk = func(prefix string, obj *Object) {
+		if obj.Value != nil {
+			names = append(names, prefix)
+		}
+		for key, v := range obj.Items {
+			if key != "%any%" {
+				walk(prefix+"."+key, v)
+			}
+		}
+	}
+	for key, obj := range c.Variables {
+		walk(key, obj)
+	}
+	return names
+}
+
+// FunctionNames returns names of all builtin and user defined functions regardless of the scope
+func (c *Context) FunctionNames() []string {
+	var names []string
+	var walk func(prefix string, spec *FunctionSpec)
+	walk = func(prefix string, spec *FunctionSpec) {
+		if spec.Value != nil {
+			names = append(names, prefix)
+		}
+		for key, v := range spec.Items {
+			walk(prefix+"."+key, v)
+		}
+	}
+	for key, spec := range c.functions {
+		walk(key, spec)
+	}
+	return names
+}
+
+// DeclarationNames returns names of declarations which could be referenced as the identifier
+// like backends, acls and tables
+func (c *Context) DeclarationNames() []string {
+	var names []string
+	names = slices.AppendSeq(names, maps.Keys(c.Backends))
+	names = slices.AppendSeq(names, maps.Keys(c.Acls))
+	names = slices.AppendSeq(names, maps.Keys(c.Tables))
+	names = slices.AppendSeq(names, maps.Keys(c.Penaltyboxes))
+	names = slices.AppendSeq(names, maps.Keys(c.Ratecounters))
+	names = slices.AppendSeq(names, maps.Keys(c.Constants))
+	return names
+}
+
 func splitName(name string) (string, []string) {
 	var first string
 	var remains []string
@@ -1,6 +1,8 @@
 package context
 
 import (
+	"slices"
+	"strings"
 	"testing"
 
 	"github.com/ysugimoto/falco/v2/ast"
@@ -213,3 +215,76 @@ func TestSplitName(t *testing.T) {
 		}
 	})
 }
+
+func TestPredefinedVariable(t *testing.T) {
+	ctx := New()
+	tests := []struct {
+		name   string
+		expect string
+		ok     bool
+	}{
+		{name: "req.url", expect: "req.url", ok: true},
+		{name: "req.http.Host", expect: "req.http.*", ok: true},
+		{name: "req.http.Cookie:session", expect: "req.http.*", ok: true},
+		{name: "ratecounter.rc.bucket.10s", expect: "ratecounter.*.bucket.10s", ok: true},
+		{name: "re.group.1", expect: "re.group.*", ok: true},
+		{name: "req.undefined", ok: false},
+		{name: "var.foo", ok: false},
+		{name: "F_origin", ok: false},
+	}
+	for _, tt := range tests {
+		name, ok := ctx.PredefinedVariable(tt.name)
+		if ok != tt.ok || name != tt.expect {
+			t.Errorf("PredefinedVariable(%s) mismatch, expect=%s,%t, got=%s,%t", tt.name, tt.expect, tt.ok, name, ok)
+		}
+	}
+
+	if !ctx.IsBuiltinFunction("std.itoa") || ctx.IsBuiltinFunction("std.undefined") || ctx.IsBuiltinFunction("std") {
+		t.Errorf("IsBuiltinFunction returns unexpected result")
+	}
+}
+
+func TestBuiltinTypes(t *testing.T) {
+	ctx := New()
+	if v, ok := ctx.PredefinedVariableType("req.http.Host"); !ok || v != types.StringType {
+		t.Errorf("PredefinedVariableType(req.http.Host) mismatch, got=%s,%t", v, ok)
+	}
+	if v, ok := ctx.PredefinedVariableType("geoip.latitude"); !ok || v != types.FloatType {
+		t.Errorf("PredefinedVariableType(geoip.latitude) mismatch, got=%s,%t", v, ok)
+	}
+	if _, ok := ctx.PredefinedVariableType("req.undefined"); ok {
+		t.Errorf("PredefinedVariableType(req.undefined) must not be found")
+	}
+	if v, ok := ctx.BuiltinFunctionReturnType("std.atoi"); !ok || v != types.IntegerType {
+		t.Errorf("BuiltinFunctionReturnType(std.atoi) mismatch, got=%s,%t", v, ok)
+	}
+	if _, ok := ctx.BuiltinFunctionReturnType("std.undefined"); ok {
+		t.Errorf("BuiltinFunctionReturnType(std.undefined) must not be found")
+	}
+}
+
+func TestNames(t *testing.T) {
+	ctx := New()
+	if err := ctx.Declare("var.counter", types.IntegerType, nil); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if err := ctx.AddBackend("F_origin", &types.Backend{}); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	variables := ctx.VariableNames()
+	for _, name := range []string{"req.url", "client.geo.country_code", "var.counter"} {
+		if !slices.Contains(variables, name) {
+			t.Errorf("VariableNames must contain %s", name)
+		}
+	}
+	if slices.ContainsFunc(variables, func(v string) bool { return strings.Contains(v, "%any%") }) {
+		t.Errorf("VariableNames must not contain dynamic variables")
+	}
+	if functions := ctx.FunctionNames(); !slices.Contains(functions, "std.strlen") {
+		t.Errorf("FunctionNames must contain std.strlen")
+	}
+	if declarations := ctx.DeclarationNames(); !slices.Contains(declarations, "F_origin") {
+		t.Errorf("DeclarationNames must contain F_origin")
+	}
+}
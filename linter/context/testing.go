@@ -9,9 +9,14 @@ import (
 // TestingCallSubroutineName is the fully-qualified name used in VCL test files.
 const TestingCallSubroutineName = "testing.call_subroutine"
 
+// TestingCallSubroutineInScopeName is the fully-qualified name of the variant
+// which takes the scope name as the required second argument.
+const TestingCallSubroutineInScopeName = "testing.call_subroutine_in_scope"
+
 // testingFunctions returns BuiltinFunction specs for the "testing.*" namespace.
 // These specs are registered unconditionally so that GetFunction resolves
-// "testing.call_subroutine" without an "undefined function" error.
+// "testing.call_subroutine" and "testing.call_subroutine_in_scope" without an
+// "undefined function" error.
 //
 // The Arguments list is left nil here because the variadic extra arguments
 // (forwarded to the target subroutine) are validated via special-case logic
@@ -31,6 +36,14 @@ func testingFunctions() Functions {
 						Scopes:    allScopes,
 					},
 				},
+				"call_subroutine_in_scope": {
+					Items: map[string]*FunctionSpec{},
+					Value: &BuiltinFunction{
+						Arguments: nil,
+						Return:    types.NeverType,
+						Scopes:    allScopes,
+					},
+				},
 			},
 		},
 	}
@@ -2,7 +2,9 @@ package linter
 
 import (
 	"fmt"
+	"maps"
 	"net"
+	"slices"
 
 	"github.com/ysugimoto/falco/v2/ast"
 	"github.com/ysugimoto/falco/v2/linter/context"
@@ -14,6 +16,7 @@ func (l *Linter) lintAclDeclaration(decl *ast.AclDeclaration, ctx *context.Conte
 	if !isValidName(decl.Name.Value) {
 		l.Error(InvalidName(decl.Name.GetMeta(), decl.Name.Value, "acl").Match(ACL_SYNTAX))
 	}
+	l.lintReservedName(decl.Name, "acl", ctx)
 
 	// CIDRs validity
 	for _, cidr := range decl.CIDRs {
@@ -47,11 +50,13 @@ func (l *Linter) lintBackendDeclaration(decl *ast.BackendDeclaration, ctx *conte
 	if !isValidName(decl.Name.Value) {
 		l.Error(InvalidName(decl.Name.GetMeta(), decl.Name.Value, "backend").Match(BACKEND_SYNTAX))
 	}
+	l.lintReservedName(decl.Name, "backend", ctx)
 
 	// lint property definitions
 	for i := range decl.Properties {
 		l.lintBackendProperty(decl.Properties[i], ctx)
 	}
+	l.lintBackendTimeouts(decl)
 
 	// Check ignored UNUSED_DECLARATION rule and mark as used
 	if l.ignore.IsEnable(UNUSED_DECLARATION) {
@@ -104,8 +109,19 @@ func (l *Linter) lintBackendProperty(prop *ast.BackendProperty, ctx *context.Con
 			return
 		}
 		vt := l.lint(prop.Value, ctx)
-		if kt != vt {
+		switch {
+		case kt == types.RTimeType && expectType(vt, types.IntegerType, types.FloatType):
+			l.Error(MissingDurationUnit(
+				prop.Value.GetMeta(), prop.Key.Value, prop.Value.GetMeta().Token.Literal,
+			).Match(RTIME_MISSING_UNIT))
+		case kt != vt:
 			l.Error(InvalidType(prop.Value.GetMeta(), prop.Key.Value, kt, vt).Match(BACKEND_SYNTAX))
+		case kt == types.RTimeType:
+			if v, ok := prop.Value.(*ast.RTime); ok {
+				if suggest, ok := suggestDurationUnit(prop.Key.Value, v.Value); ok {
+					l.Error(SuspiciousDurationUnit(v.GetMeta(), prop.Key.Value, v.Value, suggest).Match(RTIME_SUSPICIOUS_UNIT))
+				}
+			}
 		}
 
 		// share_key must consist of alphanumeric or ASCII characters
@@ -130,6 +146,7 @@ func (l *Linter) lintDirectorDeclaration(decl *ast.DirectorDeclaration, ctx *con
 	if !isValidName(decl.Name.Value) {
 		l.Error(InvalidName(decl.Name.GetMeta(), decl.Name.Value, "director").Match(DIRECTOR_SYNTAX))
 	}
+	l.lintReservedName(decl.Name, "director", ctx)
 
 	l.lintDirectorProperty(decl, ctx)
 
@@ -182,6 +199,7 @@ func (l *Linter) lintDirectorProperty(decl *ast.DirectorDeclaration, ctx *contex
 							Token:    v.Token,
 							Message:  fmt.Sprintf("Backend %s is not declared", ident.Value),
 						}
+						err = err.Suggest(ident.Value, slices.Collect(maps.Keys(ctx.Backends)))
 						l.Error(err.Match(BACKEND_NOTFOUND))
 					}
 				} else {
@@ -241,6 +259,7 @@ func (l *Linter) lintTableDeclaration(decl *ast.TableDeclaration, ctx *context.C
 	if !isValidName(decl.Name.Value) {
 		l.Error(InvalidName(decl.Name.GetMeta(), decl.Name.Value, "table").Match(TABLE_SYNTAX))
 	}
+	l.lintReservedName(decl.Name, "table", ctx)
 
 	// table value type
 	var valueType types.Type
@@ -272,7 +291,7 @@ func (l *Linter) lintTableProperty(prop *ast.TableProperty, tableType types.Type
 			return
 		}
 		if a, ok := ctx.Acls[ident.Value]; !ok {
-			l.Error(UndefinedAcl(ident.GetMeta(), ident.Value))
+			l.Error(UndefinedAcl(ident.GetMeta(), ident.Value).Suggest(ident.Value, slices.Collect(maps.Keys(ctx.Acls))))
 		} else {
 			a.IsUsed = true
 		}
@@ -283,7 +302,7 @@ func (l *Linter) lintTableProperty(prop *ast.TableProperty, tableType types.Type
 			return
 		}
 		if b, ok := ctx.Backends[ident.Value]; !ok {
-			l.Error(UndefinedBackend(ident.GetMeta(), ident.Value))
+			l.Error(UndefinedBackend(ident.GetMeta(), ident.Value).Suggest(ident.Value, slices.Collect(maps.Keys(ctx.Backends))))
 		} else {
 			b.IsUsed = true
 		}
@@ -311,6 +330,7 @@ func (l *Linter) lintSubRoutineDeclaration(decl *ast.SubroutineDeclaration, ctx
 	if !isValidName(decl.Name.Value) {
 		l.Error(InvalidName(decl.Name.GetMeta(), decl.Name.Value, "sub").Match(SUBROUTINE_SYNTAX))
 	}
+	l.lintReservedName(decl.Name, "sub", ctx)
 	// vcl_pipe lifecycle subroutine is reserved in Fastly generated VCL.
 	// When the user specify this name of subroutine, Fastly prevents to generated their own vcl_pipe subroutine.
 	// It causes unexpected behavior so falco should report it to not to break original behavior (switch to pipe-mode in Varnish).
@@ -419,11 +439,12 @@ func (l *Linter) lintSubRoutineDeclaration(decl *ast.SubroutineDeclaration, ctx
 	return types.NeverType
 }
 
-func (l *Linter) lintPenaltyboxDeclaration(decl *ast.PenaltyboxDeclaration) types.Type {
+func (l *Linter) lintPenaltyboxDeclaration(decl *ast.PenaltyboxDeclaration, ctx *context.Context) types.Type {
 	// validate penaltybox name
 	if !isValidName(decl.Name.Value) {
 		l.Error(InvalidName(decl.Name.GetMeta(), decl.Name.Value, "penaltybox").Match(PENALTYBOX_SYNTAX))
 	}
+	l.lintReservedName(decl.Name, "penaltybox", ctx)
 
 	if len(decl.Block.Statements) > 0 {
 		l.Error(NonEmptyPenaltyboxBlock(decl.GetMeta(), decl.Name.Value).Match(PENALTYBOX_NONEMPTY_BLOCK))
@@ -432,11 +453,12 @@ func (l *Linter) lintPenaltyboxDeclaration(decl *ast.PenaltyboxDeclaration) type
 	return types.NeverType
 }
 
-func (l *Linter) lintRatecounterDeclaration(decl *ast.RatecounterDeclaration) types.Type {
+func (l *Linter) lintRatecounterDeclaration(decl *ast.RatecounterDeclaration, ctx *context.Context) types.Type {
 	// validate ratecounter name
 	if !isValidName(decl.Name.Value) {
 		l.Error(InvalidName(decl.Name.GetMeta(), decl.Name.Value, "ratecounter").Match(RATECOUNTER_SYNTAX))
 	}
+	l.lintReservedName(decl.Name, "ratecounter", ctx)
 
 	if len(decl.Block.Statements) > 0 {
 		l.Error(NonEmptyRatecounterBlock(decl.GetMeta(), decl.Name.Value).Match(RATECOUNTER_NONEMPTY_BLOCK))
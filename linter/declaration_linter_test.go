@@ -3,6 +3,11 @@ package linter
 import (
 	"fmt"
 	"testing"
+
+	"github.com/ysugimoto/falco/v2/config"
+	"github.com/ysugimoto/falco/v2/lexer"
+	"github.com/ysugimoto/falco/v2/linter/context"
+	"github.com/ysugimoto/falco/v2/parser"
 )
 
 func TestLintAclDeclaration(t *testing.T) {
@@ -143,6 +148,24 @@ backend foo {
 }`
 		assertError(t, input)
 	})
+
+	t.Run("timeout without unit suffix", func(t *testing.T) {
+		input := `
+backend foo {
+  .host = "example.com";
+  .connect_timeout = 1000;
+}`
+		assertError(t, input)
+	})
+
+	t.Run("timeout with suspicious unit", func(t *testing.T) {
+		input := `
+backend foo {
+  .host = "example.com";
+  .first_byte_timeout = 15000s;
+}`
+		assertErrorWithSeverity(t, input, WARNING)
+	})
 }
 
 func TestLintTableDeclaration(t *testing.T) {
@@ -586,6 +609,93 @@ sub test_sub{
 	})
 }
 
+func TestLintConstDeclaration(t *testing.T) {
+	t.Run("pass", func(t *testing.T) {
+		input := `
+const TTL_LONG = 86400s;
+sub vcl_fetch {
+	#FASTLY FETCH
+	set beresp.ttl = TTL_LONG;
+}
+`
+		assertNoError(t, input)
+	})
+
+	t.Run("constant type is the literal type", func(t *testing.T) {
+		input := `
+const HOST = "example.com";
+sub vcl_fetch {
+	#FASTLY FETCH
+	set beresp.ttl = HOST;
+}
+`
+		assertError(t, input)
+	})
+
+	t.Run("constant could not be used in first expression of condition", func(t *testing.T) {
+		input := `
+const HOST = "example.com";
+sub vcl_recv {
+	#FASTLY RECV
+	if (HOST == req.http.Host) {
+		esi;
+	}
+}
+`
+		assertError(t, input)
+	})
+
+	t.Run("duplicate const declared", func(t *testing.T) {
+		input := `
+const TTL_LONG = 86400s;
+const TTL_LONG = 3600s;
+`
+		assertError(t, input)
+	})
+
+	t.Run("magic number", func(t *testing.T) {
+		conf := &config.LinterConfig{
+			MagicNumberThreshold:   1000,
+			MagicDurationThreshold: "1h",
+		}
+		tests := []struct {
+			input string
+			count int
+		}{
+			{input: `set beresp.ttl = 86400s;`, count: 1},
+			{input: `set beresp.ttl = 1d;`, count: 1},
+			{input: `set beresp.ttl = 3600s;`, count: 0},
+			{input: `set beresp.ttl = TTL_LONG;`, count: 0},
+			{input: `set beresp.http.Foo = std.itoa(86400);`, count: 1},
+			{input: `set beresp.http.Foo = std.itoa(200);`, count: 0},
+		}
+
+		for _, tt := range tests {
+			vcl, err := parser.New(lexer.NewFromString(`
+const TTL_LONG = 86400s;
+sub vcl_fetch {
+	#FASTLY FETCH
+	` + tt.input + `
+}`)).ParseVCL()
+			if err != nil {
+				t.Errorf("unexpected parser error: %s", err)
+				continue
+			}
+			l := New(conf)
+			l.lint(vcl, context.New())
+			var count int
+			for _, e := range l.Errors {
+				if e.Rule == MAGIC_NUMBER {
+					count++
+				}
+			}
+			if count != tt.count {
+				t.Errorf("%s: expect %d magic number errors but got %d", tt.input, tt.count, count)
+			}
+		}
+	})
+}
+
 func TestFastlyBoilerPlateMacro(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -0,0 +1,75 @@
+package linter
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ysugimoto/falco/v2/ast"
+	"github.com/ysugimoto/falco/v2/config"
+)
+
+// Layout of the sunset date
+const sunsetLayout = "2006-01-02"
+
+// Deprecation timelines of Fastly builtin functions and variables.
+// Variables which are marked as deprecated in the predefined definitions are reported even if they are not listed here.
+// Key which ends with ".*" matches all names under the prefix, and replacement is resolved in the same way.
+// Sunset dates are left empty until Fastly announces them, users can set them via linter.deprecations configuration.
+var deprecations = map[string]*config.Deprecation{
+	"boltsort.sort": {Replacement: "querystring.sort"},
+	"geoip.*":       {Replacement: "client.geo.*"},
+}
+
+// Find deprecation timeline of the name. Configured timeline takes precedence over falco's one
+func (l *Linter) deprecation(name string) *config.Deprecation {
+	for _, defs := range []map[string]*config.Deprecation{l.conf.Deprecations, deprecations} {
+		if d, ok := defs[name]; ok {
+			return d
+		}
+		for key, d := range defs {
+			prefix, ok := strings.CutSuffix(key, "*")
+			if !ok || !strings.HasPrefix(name, prefix) {
+				continue
+			}
+			resolved := *d
+			if r, ok := strings.CutSuffix(d.Replacement, "*"); ok {
+				resolved.Replacement = r + strings.TrimPrefix(name, prefix)
+			}
+			return &resolved
+		}
+	}
+	return nil
+}
+
+// lintDeprecation reports the usage of deprecated builtin with its timeline.
+// The flagged argument indicates the variable is marked as deprecated in the predefined definitions
+func (l *Linter) lintDeprecation(kind, name string, m *ast.Meta, flagged bool) {
+	d := l.deprecation(name)
+	if d == nil {
+		if flagged {
+			l.Error(DeprecatedVariable(name, m).Match(DEPRECATED))
+		}
+		return
+	}
+
+	var sunset time.Time
+	if d.Sunset != "" {
+		var err error
+		if sunset, err = time.Parse(sunsetLayout, d.Sunset); err != nil {
+			l.Error(fmt.Errorf("invalid sunset date %q of %s: %w", d.Sunset, name, err))
+			return
+		}
+	}
+
+	err := DeprecatedBuiltin(kind, name, d, m)
+	if !sunset.IsZero() && time.Now().After(sunset) {
+		err.Message = strings.Replace(err.Message, "will be sunset", "was sunset", 1)
+		if l.conf.ErrorAfterSunset {
+			err.Severity = ERROR
+			l.Error(err.Match(DEPRECATED_SUNSET))
+			return
+		}
+	}
+	l.Error(err.Match(DEPRECATED))
+}
@@ -0,0 +1,95 @@
+package linter
+
+import (
+	"testing"
+
+	"github.com/ysugimoto/falco/v2/config"
+	"github.com/ysugimoto/falco/v2/lexer"
+	"github.com/ysugimoto/falco/v2/linter/context"
+	"github.com/ysugimoto/falco/v2/parser"
+)
+
+func TestDeprecationTimeline(t *testing.T) {
+	deprecations := map[string]*config.Deprecation{
+		"geoip.city":            {Sunset: "2000-01-01", Replacement: "client.geo.city"},
+		"client.display.height": {Sunset: "2999-12-31"},
+	}
+
+	tests := []struct {
+		name             string
+		input            string
+		errorAfterSunset bool
+		severity         Severity
+		rule             Rule
+		message          string
+	}{
+		{
+			name:     "builtin replacement of function",
+			input:    `set req.url = boltsort.sort(req.url);`,
+			severity: WARNING,
+			rule:     DEPRECATED,
+			message:  `Function "boltsort.sort" is deprecated, use "querystring.sort" instead`,
+		},
+		{
+			name:     "builtin replacement is resolved by prefix",
+			input:    `set req.http.Foo = geoip.country_code;`,
+			severity: WARNING,
+			rule:     DEPRECATED,
+			message:  `Variable "geoip.country_code" is deprecated, use "client.geo.country_code" instead`,
+		},
+		{
+			name:     "predefined deprecated variable with configured sunset",
+			input:    `set req.http.Foo = client.display.height;`,
+			severity: WARNING,
+			rule:     DEPRECATED,
+			message:  `Variable "client.display.height" is deprecated and will be sunset on 2999-12-31`,
+		},
+		{
+			name:     "passed sunset is warning as default",
+			input:    `set req.http.Foo = geoip.city;`,
+			severity: WARNING,
+			rule:     DEPRECATED,
+			message:  `Variable "geoip.city" is deprecated and was sunset on 2000-01-01, use "client.geo.city" instead`,
+		},
+		{
+			name:             "passed sunset becomes error",
+			input:            `set req.http.Foo = geoip.city;`,
+			errorAfterSunset: true,
+			severity:         ERROR,
+			rule:             DEPRECATED_SUNSET,
+			message:          `Variable "geoip.city" is deprecated and was sunset on 2000-01-01, use "client.geo.city" instead`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vcl, err := parser.New(lexer.NewFromString(`
+sub vcl_recv {
+  #FASTLY recv
+  ` + tt.input + `
+}`)).ParseVCL()
+			if err != nil {
+				t.Fatalf("unexpected parser error: %s", err)
+			}
+
+			l := New(&config.LinterConfig{
+				Deprecations:     deprecations,
+				ErrorAfterSunset: tt.errorAfterSunset,
+			})
+			l.lint(vcl, context.New())
+			if len(l.Errors) != 1 {
+				t.Fatalf("Expect one lint error but got %d", len(l.Errors))
+			}
+			e := l.Errors[0]
+			if e.Severity != tt.severity {
+				t.Errorf("Severity mismatch, expect=%s, got=%s", tt.severity, e.Severity)
+			}
+			if e.Rule != tt.rule {
+				t.Errorf("Rule mismatch, expect=%s, got=%s", tt.rule, e.Rule)
+			}
+			if e.Message != tt.message {
+				t.Errorf("Message mismatch, expect=%s, got=%s", tt.message, e.Message)
+			}
+		})
+	}
+}
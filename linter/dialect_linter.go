@@ -0,0 +1,448 @@
+package linter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ysugimoto/falco/v2/ast"
+	"github.com/ysugimoto/falco/v2/dialect"
+	"github.com/ysugimoto/falco/v2/linter/context"
+)
+
+// Varnish 4.x lifecycle subroutines
+var varnishSubroutines = map[string]struct{}{
+	"vcl_init":             {},
+	"vcl_fini":             {},
+	"vcl_recv":             {},
+	"vcl_pipe":             {},
+	"vcl_pass":             {},
+	"vcl_hash":             {},
+	"vcl_purge":            {},
+	"vcl_hit":              {},
+	"vcl_miss":             {},
+	"vcl_deliver":          {},
+	"vcl_synth":            {},
+	"vcl_backend_fetch":    {},
+	"vcl_backend_response": {},
+	"vcl_backend_error":    {},
+}
+
+// Fastly lifecycle subroutines which do not exist in Varnish 4.x, value is the replacement
+var fastlyOnlySubroutines = map[string]string{
+	"vcl_fetch": "vcl_backend_response",
+	"vcl_error": "vcl_synth or vcl_backend_error",
+	"vcl_log":   "",
+}
+
+// Builtin functions of Varnish which can be called without importing VMODs
+var varnishFunctions = map[string]struct{}{
+	"regsub":    {},
+	"regsuball": {},
+	"ban":       {},
+	"hash_data": {},
+	"synthetic": {},
+	"synth":     {},
+	"rollback":  {},
+}
+
+// Fastly return states which do not exist in Varnish 4.x, value is the replacement
+var fastlyOnlyReturnStates = map[string]string{
+	"lookup":        "hash",
+	"deliver_stale": "",
+}
+
+// Fastly specific variable namespaces
+var fastlyOnlyVariableRoots = map[string]struct{}{
+	"fastly":            {},
+	"fastly_info":       {},
+	"geoip":             {},
+	"waf":               {},
+	"segmented_caching": {},
+	"quic":              {},
+	"transport":         {},
+	"workspace":         {},
+	"tls":               {},
+	"esi":               {},
+	"math":              {},
+	"ratecounter":       {},
+}
+
+// Fastly specific variables in the namespaces which Varnish also has, value is the replacement
+var fastlyOnlyVariables = map[string]string{
+	"req.backend":       "req.backend_hint",
+	"client.geo":        "",
+	"client.as":         "",
+	"client.socket":     "",
+	"server.datacenter": "",
+	"server.region":     "",
+}
+
+var supportedVarnishVersions = map[string]struct{}{
+	"4.0": {},
+	"4.1": {},
+}
+
+// dialectLinter lints VCL which is written in non-Fastly dialect on best-effort.
+// Type checking is not run because the variables and functions of the dialect are different from Fastly,
+// and it reports Fastly specific constructs as dialect errors instead
+type dialectLinter struct {
+	*Linter
+	dialect     *dialect.Dialect
+	vmods       map[string]struct{}
+	objects     map[string]struct{}
+	subroutines map[string]struct{}
+	included    map[string]struct{}
+}
+
+func (l *Linter) dialect() *dialect.Dialect {
+	if l.conf == nil {
+		return dialect.Fastly
+	}
+	d, err := dialect.Get(l.conf.Dialect)
+	if err != nil {
+		return dialect.Fastly
+	}
+	return d
+}
+
+func (l *Linter) lintDialect(node ast.Node, ctx *context.Context, d *dialect.Dialect) {
+	vcl, ok := node.(*ast.VCL)
+	if !ok {
+		return
+	}
+
+	dl := &dialectLinter{
+		Linter:      l,
+		dialect:     d,
+		vmods:       make(map[string]struct{}),
+		objects:     make(map[string]struct{}),
+		subroutines: make(map[string]struct{}),
+		included:    make(map[string]struct{}),
+	}
+	statements := dl.resolveIncludes(vcl.Statements, ctx)
+	if l.FatalError != nil {
+		return
+	}
+	dl.collect(statements)
+
+	var version bool
+	for _, stmt := range statements {
+		if _, ok := stmt.(*dialect.VersionStatement); ok {
+			version = true
+		}
+		l.ignore.SetupStatement(stmt.GetMeta())
+		dl.lintDeclaration(stmt, ctx)
+		l.ignore.TeardownStatement(stmt.GetMeta())
+	}
+
+	if !version && d.Has(dialect.VersionDeclaration) && len(vcl.Statements) > 0 {
+		l.Error(&LintError{
+			Severity: ERROR,
+			Token:    vcl.Statements[0].GetMeta().Token,
+			Message:  `VCL version declaration like "vcl 4.1;" is required at the top of VCL`,
+			Rule:     DIALECT_VERSION,
+		})
+	}
+}
+
+// resolveIncludes expands include statements with the modules which are parsed in the dialect
+func (dl *dialectLinter) resolveIncludes(statements []ast.Statement, ctx *context.Context) []ast.Statement {
+	var resolved []ast.Statement
+	for _, stmt := range statements {
+		include, ok := stmt.(*ast.IncludeStatement)
+		if !ok {
+			resolved = append(resolved, stmt)
+			continue
+		}
+		if _, ok := dl.included[include.Module.Value]; ok {
+			continue
+		}
+		dl.included[include.Module.Value] = struct{}{}
+
+		module, err := ctx.Resolver().Resolve(include)
+		if err != nil {
+			dl.Includes[include.Module.Value] = ""
+			dl.Error(&LintError{
+				Severity: ERROR,
+				Token:    include.GetMeta().Token,
+				Message:  err.Error(),
+				Rule:     INCLUDE_STATEMENT_MODULE_NOT_FOUND,
+			})
+			continue
+		}
+		dl.Includes[include.Module.Value] = module.Name
+		m := dl.loadModule(parseModule(module.Name, module.Data, dl.dialect.ParserOptions()...))
+		if dl.FatalError != nil {
+			return resolved
+		}
+		resolved = append(resolved, dl.resolveIncludes(m, ctx)...)
+	}
+	return resolved
+}
+
+// collect declared names which are referenced before the declaration
+func (dl *dialectLinter) collect(statements []ast.Statement) {
+	for _, stmt := range statements {
+		switch t := stmt.(type) {
+		case *ast.ImportStatement:
+			dl.vmods[t.Name.Value] = struct{}{}
+		case *ast.SubroutineDeclaration:
+			dl.subroutines[t.Name.Value] = struct{}{}
+			dl.collectObjects(t.Block.Statements)
+		}
+	}
+}
+
+func (dl *dialectLinter) collectObjects(statements []ast.Statement) {
+	for _, stmt := range statements {
+		switch t := stmt.(type) {
+		case *dialect.ObjectStatement:
+			dl.objects[t.Name.Value] = struct{}{}
+		case *ast.BlockStatement:
+			dl.collectObjects(t.Statements)
+		}
+	}
+}
+
+func (dl *dialectLinter) unsupported(node ast.Node, c dialect.Capability) {
+	if dl.dialect.Has(c) {
+		return
+	}
+	dl.Error(&LintError{
+		Severity: ERROR,
+		Token:    node.GetMeta().Token,
+		Message:  fmt.Sprintf("%s is not supported in %s VCL", capitalize(c.String()), dl.dialect.Name),
+		Rule:     DIALECT_UNSUPPORTED_SYNTAX,
+	})
+}
+
+func (dl *dialectLinter) unsupportedBuiltin(node ast.Node, kind, name, replacement string) {
+	message := fmt.Sprintf(`Fastly %s "%s" is not available in %s VCL`, kind, name, dl.dialect.Name)
+	if replacement != "" {
+		message += fmt.Sprintf(`, use "%s" instead`, replacement)
+	}
+	dl.Error(&LintError{
+		Severity: ERROR,
+		Token:    node.GetMeta().Token,
+		Message:  message,
+		Rule:     DIALECT_UNSUPPORTED_BUILTIN,
+	})
+}
+
+func (dl *dialectLinter) lintDeclaration(stmt ast.Statement, ctx *context.Context) {
+	switch t := stmt.(type) {
+	case *dialect.VersionStatement:
+		if _, ok := supportedVarnishVersions[t.Version]; !ok {
+			dl.Error(&LintError{
+				Severity: ERROR,
+				Token:    t.GetMeta().Token,
+				Message:  fmt.Sprintf(`Unsupported VCL version "%s"`, t.Version),
+				Rule:     DIALECT_VERSION,
+			})
+		}
+	case *ast.TableDeclaration:
+		dl.unsupported(t, dialect.Table)
+	case *ast.PenaltyboxDeclaration:
+		dl.unsupported(t, dialect.Penaltybox)
+	case *ast.RatecounterDeclaration:
+		dl.unsupported(t, dialect.Ratecounter)
+	case *ast.DirectorDeclaration:
+		dl.unsupported(t, dialect.Director)
+	case *ast.ConstDeclaration:
+		dl.unsupported(t, dialect.Const)
+	case *ast.SubroutineDeclaration:
+		dl.lintSubroutine(t, ctx)
+	}
+}
+
+func (dl *dialectLinter) lintSubroutine(decl *ast.SubroutineDeclaration, ctx *context.Context) {
+	if len(decl.Parameters) > 0 || decl.ReturnType != nil {
+		dl.unsupported(decl, dialect.UserDefinedFunction)
+	}
+
+	if strings.HasPrefix(decl.Name.Value, "vcl_") {
+		if _, ok := varnishSubroutines[decl.Name.Value]; !ok {
+			dl.unsupportedBuiltin(decl.Name, "subroutine", decl.Name.Value, fastlyOnlySubroutines[decl.Name.Value])
+		}
+	}
+
+	if hasAnyFastlyBoilerPlateMacro(decl) {
+		dl.unsupported(decl, dialect.BoilerplateMacro)
+	}
+	dl.lintStatements(decl.Block.Statements, ctx)
+}
+
+func hasAnyFastlyBoilerPlateMacro(decl *ast.SubroutineDeclaration) bool {
+	comments := decl.Block.Infix
+	for _, stmt := range decl.Block.Statements {
+		comments = append(comments, stmt.GetMeta().Leading...)
+	}
+	for _, c := range comments {
+		if strings.HasPrefix(c.String(), "#FASTLY ") {
+			return true
+		}
+	}
+	return false
+}
+
+func (dl *dialectLinter) lintStatements(statements []ast.Statement, ctx *context.Context) {
+	for _, stmt := range statements {
+		dl.ignore.SetupStatement(stmt.GetMeta())
+		dl.lintStatement(stmt, ctx)
+		dl.ignore.TeardownStatement(stmt.GetMeta())
+	}
+}
+
+// nolint: gocyclo
+func (dl *dialectLinter) lintStatement(stmt ast.Statement, ctx *context.Context) {
+	switch t := stmt.(type) {
+	case *ast.BlockStatement:
+		dl.lintStatements(t.Statements, ctx)
+	case *ast.DeclareStatement:
+		dl.unsupported(t, dialect.DeclareLocal)
+	case *ast.ErrorStatement:
+		dl.unsupported(t, dialect.ErrorStatement)
+	case *ast.EsiStatement:
+		dl.unsupported(t, dialect.EsiStatement)
+	case *ast.AddStatement:
+		dl.unsupported(t, dialect.AddStatement)
+	case *ast.RemoveStatement:
+		dl.unsupported(t, dialect.RemoveStatement)
+	case *ast.SyntheticBase64Statement:
+		dl.unsupported(t, dialect.SyntheticBase64)
+	case *ast.GotoStatement:
+		dl.unsupported(t, dialect.Goto)
+	case *ast.GotoDestinationStatement:
+		dl.unsupported(t, dialect.Goto)
+	case *ast.SwitchStatement:
+		dl.unsupported(t, dialect.Switch)
+	case *ast.SetStatement:
+		dl.lintVariable(t.Ident, ctx)
+		dl.lintExpression(t.Value, ctx)
+	case *ast.UnsetStatement:
+		dl.lintVariable(t.Ident, ctx)
+	case *ast.IfStatement:
+		dl.lintIfStatement(t, ctx)
+	case *ast.CallStatement:
+		if _, ok := dl.subroutines[t.Subroutine.Value]; !ok {
+			dl.Error(&LintError{
+				Severity: ERROR,
+				Token:    t.Subroutine.GetMeta().Token,
+				Message:  fmt.Sprintf(`Subroutine "%s" is not defined`, t.Subroutine.Value),
+				Rule:     CALL_STATEMENT_SUBROUTINE_NOTFOUND,
+			})
+		}
+	case *ast.FunctionCallStatement:
+		dl.lintFunction(t.Function, ctx)
+		dl.lintExpressions(t.Arguments, ctx)
+	case *ast.ReturnStatement:
+		if ident, ok := t.ReturnExpression.(*ast.Ident); ok {
+			if replacement, ok := fastlyOnlyReturnStates[ident.Value]; ok {
+				dl.unsupportedBuiltin(ident, "return state", ident.Value, replacement)
+			}
+			break
+		}
+		dl.lintExpression(t.ReturnExpression, ctx)
+	case *ast.LogStatement:
+		dl.lintExpression(t.Value, ctx)
+	case *ast.SyntheticStatement:
+		dl.lintExpression(t.Value, ctx)
+	case *dialect.ObjectStatement:
+		dl.lintExpression(t.Value, ctx)
+	}
+}
+
+func (dl *dialectLinter) lintIfStatement(stmt *ast.IfStatement, ctx *context.Context) {
+	dl.lintExpression(stmt.Condition, ctx)
+	dl.lintStatements(stmt.Consequence.Statements, ctx)
+	for _, another := range stmt.Another {
+		dl.lintIfStatement(another, ctx)
+	}
+	if stmt.Alternative != nil {
+		dl.lintStatements(stmt.Alternative.Consequence.Statements, ctx)
+	}
+}
+
+func (dl *dialectLinter) lintExpressions(expressions []ast.Expression, ctx *context.Context) {
+	for i := range expressions {
+		dl.lintExpression(expressions[i], ctx)
+	}
+}
+
+func (dl *dialectLinter) lintExpression(expr ast.Expression, ctx *context.Context) {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		dl.lintVariable(t, ctx)
+	case *ast.PrefixExpression:
+		dl.lintExpression(t.Right, ctx)
+	case *ast.PostfixExpression:
+		dl.lintExpression(t.Left, ctx)
+	case *ast.GroupedExpression:
+		dl.lintExpression(t.Right, ctx)
+	case *ast.InfixExpression:
+		dl.lintExpression(t.Left, ctx)
+		dl.lintExpression(t.Right, ctx)
+	case *ast.IfExpression:
+		dl.lintExpression(t.Condition, ctx)
+		dl.lintExpression(t.Consequence, ctx)
+		dl.lintExpression(t.Alternative, ctx)
+	case *ast.FunctionCallExpression:
+		dl.lintFunction(t.Function, ctx)
+		dl.lintExpressions(t.Arguments, ctx)
+	}
+}
+
+func (dl *dialectLinter) lintVariable(ident *ast.Ident, ctx *context.Context) {
+	root, _, found := strings.Cut(ident.Value, ".")
+	if !found {
+		return
+	}
+	if _, ok := fastlyOnlyVariableRoots[root]; ok {
+		dl.unsupportedBuiltin(ident, "variable", ident.Value, "")
+		return
+	}
+	for name, replacement := range fastlyOnlyVariables {
+		if ident.Value == name || strings.HasPrefix(ident.Value, name+".") {
+			dl.unsupportedBuiltin(ident, "variable", ident.Value, replacement)
+			return
+		}
+	}
+}
+
+// lintFunction checks the function is provided by Varnish, imported VMOD or VMOD object
+func (dl *dialectLinter) lintFunction(ident *ast.Ident, ctx *context.Context) {
+	namespace, _, found := strings.Cut(ident.Value, ".")
+	if !found {
+		if _, ok := varnishFunctions[ident.Value]; ok {
+			return
+		}
+		if ctx.IsBuiltinFunction(ident.Value) {
+			dl.unsupportedBuiltin(ident, "function", ident.Value, "")
+		}
+		return
+	}
+
+	if _, ok := dl.vmods[namespace]; ok {
+		return
+	}
+	if _, ok := dl.objects[namespace]; ok {
+		return
+	}
+	message := fmt.Sprintf(`VMOD "%s" is not imported for function "%s"`, namespace, ident.Value)
+	if ctx.IsBuiltinFunction(ident.Value) {
+		message += fmt.Sprintf(", Fastly builtin function is not available in %s VCL", dl.dialect.Name)
+	}
+	dl.Error(&LintError{
+		Severity: ERROR,
+		Token:    ident.GetMeta().Token,
+		Message:  message,
+		Rule:     DIALECT_UNSUPPORTED_BUILTIN,
+	})
+}
+
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
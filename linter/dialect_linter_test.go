@@ -0,0 +1,105 @@
+package linter
+
+import (
+	"testing"
+
+	"github.com/ysugimoto/falco/v2/config"
+	"github.com/ysugimoto/falco/v2/dialect"
+	"github.com/ysugimoto/falco/v2/lexer"
+	"github.com/ysugimoto/falco/v2/linter/context"
+	"github.com/ysugimoto/falco/v2/parser"
+)
+
+func lintVarnish(t *testing.T, input string) []*LintError {
+	vcl, err := parser.New(lexer.NewFromString(input), dialect.Varnish.ParserOptions()...).ParseVCL()
+	if err != nil {
+		t.Errorf("unexpected parser error: %s", err)
+		t.FailNow()
+	}
+	l := New(&config.LinterConfig{Dialect: dialect.NameVarnish})
+	l.Lint(vcl, context.New())
+	if l.FatalError != nil {
+		t.Errorf("Fatal error: %s", l.FatalError.Error)
+	}
+	return l.Errors
+}
+
+func TestLintVarnishDialect(t *testing.T) {
+	t.Run("pass", func(t *testing.T) {
+		errs := lintVarnish(t, `
+vcl 4.1;
+
+import std;
+import directors;
+
+probe healthcheck {
+  .url = "/health";
+}
+
+backend server1 {
+  .host = "127.0.0.1";
+  .probe = healthcheck;
+}
+
+sub vcl_init {
+  new vdir = directors.round_robin();
+  vdir.add_backend(server1);
+}
+
+sub normalize {
+  set req.url = std.tolower(regsub(req.url, "\?.*$", ""));
+}
+
+sub vcl_recv {
+  call normalize;
+  set req.backend_hint = vdir.backend();
+  if (req.method == "PURGE") {
+    return (synth(405, "Not allowed"));
+  }
+  hash_data(req.url);
+  return (hash);
+}
+
+sub vcl_backend_response {
+  set beresp.ttl = 1h;
+}
+`)
+		if len(errs) > 0 {
+			t.Errorf("Unexpected lint errors: %v", errs)
+		}
+	})
+
+	tests := []struct {
+		name  string
+		input string
+		rule  Rule
+	}{
+		{name: "missing version", input: `sub vcl_recv {}`, rule: DIALECT_VERSION},
+		{name: "unsupported version", input: `vcl 3.0;`, rule: DIALECT_VERSION},
+		{name: "table", input: "vcl 4.1;\ntable t {}", rule: DIALECT_UNSUPPORTED_SYNTAX},
+		{name: "declare local", input: "vcl 4.1;\nsub vcl_recv { declare local var.v STRING; }", rule: DIALECT_UNSUPPORTED_SYNTAX},
+		{name: "error statement", input: "vcl 4.1;\nsub vcl_recv { error 404; }", rule: DIALECT_UNSUPPORTED_SYNTAX},
+		{name: "boilerplate macro", input: "vcl 4.1;\nsub vcl_recv {\n#FASTLY recv\n}", rule: DIALECT_UNSUPPORTED_SYNTAX},
+		{name: "user defined function", input: "vcl 4.1;\nsub f STRING { return \"v\"; }", rule: DIALECT_UNSUPPORTED_SYNTAX},
+		{name: "fastly subroutine", input: "vcl 4.1;\nsub vcl_fetch {}", rule: DIALECT_UNSUPPORTED_BUILTIN},
+		{name: "fastly variable", input: "vcl 4.1;\nsub vcl_recv { set req.http.DC = server.datacenter; }", rule: DIALECT_UNSUPPORTED_BUILTIN},
+		{name: "fastly variable namespace", input: "vcl 4.1;\nsub vcl_recv { set req.http.V = fastly.ff.visits_this_service; }", rule: DIALECT_UNSUPPORTED_BUILTIN},
+		{name: "fastly return state", input: "vcl 4.1;\nsub vcl_recv { return (lookup); }", rule: DIALECT_UNSUPPORTED_BUILTIN},
+		{name: "fastly function", input: "vcl 4.1;\nsub vcl_recv { set req.http.V = urlencode(req.url); }", rule: DIALECT_UNSUPPORTED_BUILTIN},
+		{name: "not imported vmod", input: "vcl 4.1;\nsub vcl_recv { set req.url = std.tolower(req.url); }", rule: DIALECT_UNSUPPORTED_BUILTIN},
+		{name: "undefined subroutine", input: "vcl 4.1;\nsub vcl_recv { call missing; }", rule: CALL_STATEMENT_SUBROUTINE_NOTFOUND},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := lintVarnish(t, tt.input)
+			if len(errs) != 1 {
+				t.Errorf("Expect one lint error but got %d: %v", len(errs), errs)
+				return
+			}
+			if errs[0].Rule != tt.rule {
+				t.Errorf("Expect rule %s but got %s", tt.rule, errs[0].Rule)
+			}
+		})
+	}
+}
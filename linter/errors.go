@@ -5,9 +5,13 @@ import (
 	"strings"
 
 	"github.com/ysugimoto/falco/v2/ast"
+	"github.com/ysugimoto/falco/v2/config"
 	"github.com/ysugimoto/falco/v2/lexer"
+	"github.com/ysugimoto/falco/v2/linter/context"
 	"github.com/ysugimoto/falco/v2/linter/types"
 	"github.com/ysugimoto/falco/v2/plugin"
+	"github.com/ysugimoto/falco/v2/policy"
+	"github.com/ysugimoto/falco/v2/suggest"
 	"github.com/ysugimoto/falco/v2/token"
 )
 
@@ -26,6 +30,9 @@ type LintError struct {
 	Message   string
 	Reference string
 	Rule      Rule
+
+	// Closest names of the unknown identifier, which could be offered as quick fixes
+	Suggestions []string
 }
 
 func (l *LintError) Match(r Rule) *LintError {
@@ -39,6 +46,16 @@ func (e *LintError) Ref(url string) *LintError {
 	return e
 }
 
+// Suggest finds closest names of the unknown identifier from candidates,
+// and appends "did you mean" suggestion to the message
+func (e *LintError) Suggest(name string, candidates []string) *LintError {
+	e.Suggestions = suggest.Closest(name, candidates)
+	if message := suggest.Message(e.Suggestions); message != "" {
+		e.Message += ", " + message
+	}
+	return e
+}
+
 func (e *LintError) Error() string {
 	var rule, ref, file string
 
@@ -59,6 +76,19 @@ func (e *LintError) Error() string {
 	return msg
 }
 
+// RecoveredPanic reports the panic which is recovered on linting.
+// The panic is caused by the linter itself, not the linting VCL
+func RecoveredPanic(recovered any) *LintError {
+	return &LintError{
+		Severity: ERROR,
+		Token:    token.Null,
+		Message: fmt.Sprintf(
+			"Linter panicked unexpectedly: %v. Linting is aborted and unused declarations are not checked, please report the issue to falco with the VCL",
+			recovered,
+		),
+	}
+}
+
 func InvalidName(m *ast.Meta, name, ident string) *LintError {
 	return &LintError{
 		Severity: ERROR,
@@ -93,6 +123,34 @@ func UndefinedVariable(m *ast.Meta, name string) *LintError {
 	}
 }
 
+// variableError converts the variable access error to the lint error,
+// closest variable names are suggested if the variable is unknown
+func variableError(ident *ast.Ident, err error, ctx *context.Context) *LintError {
+	e := &LintError{
+		Severity: ERROR,
+		Token:    ident.GetMeta().Token,
+		Message:  err.Error(),
+	}
+	if _, ok := ctx.PredefinedVariable(ident.Value); !ok {
+		e = e.Suggest(ident.Value, ctx.VariableNames())
+	}
+	return e
+}
+
+// functionError converts the function lookup error to the lint error,
+// closest function names are suggested if the function is unknown
+func functionError(ident *ast.Ident, err error, ctx *context.Context) *LintError {
+	e := &LintError{
+		Severity: ERROR,
+		Token:    ident.GetMeta().Token,
+		Message:  err.Error(),
+	}
+	if !ctx.IsBuiltinFunction(ident.Value) {
+		e = e.Suggest(ident.Value, ctx.FunctionNames())
+	}
+	return e
+}
+
 func UndefinedAcl(m *ast.Meta, name string) *LintError {
 	return &LintError{
 		Severity: ERROR,
@@ -313,6 +371,92 @@ func UnusedDeclaration(m *ast.Meta, name, declType string) *LintError {
 	}
 }
 
+func MagicNumber(m *ast.Meta, literal string) *LintError {
+	return &LintError{
+		Severity: ERROR,
+		Token:    m.Token,
+		Message: fmt.Sprintf(
+			`Magic number %s should be declared as named constant like "const NAME = %s;"`,
+			literal, literal,
+		),
+	}
+}
+
+func MissingDurationUnit(m *ast.Meta, name, literal string) *LintError {
+	return &LintError{
+		Severity: ERROR,
+		Token:    m.Token,
+		Message: fmt.Sprintf(
+			`%s is RTIME but literal %s has no unit suffix, did you mean "%ss"?`,
+			name, literal, literal,
+		),
+	}
+}
+
+func SuspiciousDurationUnit(m *ast.Meta, name, literal, suggest string) *LintError {
+	return &LintError{
+		Severity: WARNING,
+		Token:    m.Token,
+		Message: fmt.Sprintf(
+			`Duration %s for %s looks suspicious, did you mean "%s"?`,
+			literal, name, suggest,
+		),
+	}
+}
+
+func SuspiciousSizeLiteral(m *ast.Meta, name, literal string) *LintError {
+	return &LintError{
+		Severity: WARNING,
+		Token:    m.Token,
+		Message: fmt.Sprintf(
+			`%s is measured in bytes but compared with %s, the unit may be confused`,
+			name, literal,
+		),
+	}
+}
+
+func NullEscape(m *ast.Meta) *LintError {
+	return &LintError{
+		Severity: WARNING,
+		Token:    m.Token,
+		Message:  "String contains NUL escape, Fastly truncates the string at the escape and following characters are dropped",
+	}
+}
+
+func UndecodedEscape(m *ast.Meta, escape string) *LintError {
+	return &LintError{
+		Severity: INFO,
+		Token:    m.Token,
+		Message: fmt.Sprintf(
+			`Percent escape %s is not decoded in long string, use double-quoted string if you expect the decoded character`,
+			escape,
+		),
+	}
+}
+
+func ConditionalDeclaration(m *ast.Meta, name string) *LintError {
+	return &LintError{
+		Severity: ERROR,
+		Token:    m.Token,
+		Message: fmt.Sprintf(
+			`Variable "%s" may not be declared on every control flow path, declare it before the branch`,
+			name,
+		),
+	}
+}
+
+func HeaderCollectRequired(m *ast.Meta, name string) *LintError {
+	header, _, _ := strings.Cut(name, ":")
+	return &LintError{
+		Severity: INFO,
+		Token:    m.Token,
+		Message: fmt.Sprintf(
+			`%s only reads the first field of multiple %s headers, call std.collect(%s) before accessing the subfield`,
+			name, header, header,
+		),
+	}
+}
+
 func UnusedExternalDeclaration(name, declType string) *LintError {
 	return &LintError{
 		Severity: WARNING,
@@ -424,6 +568,21 @@ func DeprecatedVariable(name string, m *ast.Meta) *LintError {
 	}
 }
 
+func DeprecatedBuiltin(kind, name string, d *config.Deprecation, m *ast.Meta) *LintError {
+	message := fmt.Sprintf(`%s "%s" is deprecated`, kind, name)
+	if d.Sunset != "" {
+		message += " and will be sunset on " + d.Sunset
+	}
+	if d.Replacement != "" {
+		message += fmt.Sprintf(`, use "%s" instead`, d.Replacement)
+	}
+	return &LintError{
+		Severity: WARNING,
+		Token:    m.Token,
+		Message:  message,
+	}
+}
+
 func UncapturedRegexVariable(name string, m *ast.Meta) *LintError {
 	err := &LintError{
 		Severity: WARNING,
@@ -478,3 +637,28 @@ type FatalError struct {
 	Lexer *lexer.Lexer
 	Error error
 }
+
+func FromPolicyViolation(v *policy.Violation) *LintError {
+	e := &LintError{
+		Severity: ERROR,
+		Token: token.Token{
+			File:     v.File,
+			Line:     v.Line,
+			Position: v.Position,
+		},
+		Message: v.Message,
+		Rule:    POLICY_VIOLATION,
+	}
+	if v.Rule != "" {
+		e.Rule = Rule(POLICY_VIOLATION + "/" + v.Rule)
+	}
+
+	// Convert severity, default is ERROR
+	switch strings.ToUpper(v.Severity) {
+	case "WARNING":
+		e.Severity = WARNING
+	case "INFO":
+		e.Severity = INFO
+	}
+	return e
+}
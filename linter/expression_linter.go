@@ -26,11 +26,27 @@ func (l *Linter) lintBoolean(exp *ast.Boolean) types.Type {
 	return types.BoolType
 }
 
-func (l *Linter) lintInteger(exp *ast.Integer) types.Type {
+func (l *Linter) lintInteger(exp *ast.Integer, ctx *context.Context) types.Type {
+	// Literals in declarations like backend are not a target of magic number
+	if ctx.CurrentSubroutine != nil {
+		if threshold := l.conf.MagicNumberThreshold; threshold > 0 && exp.Value > threshold {
+			l.Error(MagicNumber(exp.GetMeta(), fmt.Sprint(exp.Value)).Match(MAGIC_NUMBER))
+		}
+	}
 	return types.IntegerType
 }
 
 func (l *Linter) lintString(exp *ast.String) types.Type {
+	if exp.LongString {
+		// Long string is used as it is, percent escapes are not decoded
+		if escape := percentEscapeRegex.FindString(exp.Value); escape != "" {
+			l.Error(UndecodedEscape(exp.GetMeta(), escape).Match(STRING_UNDECODED_ESCAPE))
+		}
+	} else {
+		if nullEscapeRegex.MatchString(exp.Token.Literal) {
+			l.Error(NullEscape(exp.GetMeta()).Match(STRING_NULL_ESCAPE))
+		}
+	}
 	return types.StringType
 }
 
@@ -38,7 +54,17 @@ func (l *Linter) lintFloat(exp *ast.Float) types.Type {
 	return types.FloatType
 }
 
-func (l *Linter) lintRTime(exp *ast.RTime) types.Type {
+func (l *Linter) lintRTime(exp *ast.RTime, ctx *context.Context) types.Type {
+	if ctx.CurrentSubroutine != nil && l.conf.MagicDurationThreshold != "" {
+		threshold, err := parseRTime(l.conf.MagicDurationThreshold)
+		if err != nil {
+			l.Error(fmt.Errorf("invalid magic_duration_threshold %q: %w", l.conf.MagicDurationThreshold, err))
+			return types.RTimeType
+		}
+		if v, err := parseRTime(exp.Value); err == nil && v > threshold {
+			l.Error(MagicNumber(exp.GetMeta(), exp.Value).Match(MAGIC_NUMBER))
+		}
+	}
 	return types.RTimeType
 }
 
@@ -49,7 +75,15 @@ func (l *Linter) lintPrefixExpression(exp *ast.PrefixExpression, ctx *context.Co
 	}
 	switch exp.Operator {
 	case "!":
-		// The bang operator case is pre-checked in isValidConditionExpression and isValidStatmentExpression
+		// The bang operator case is pre-checked in isValidConditionExpression and isValidStatmentExpression.
+		// STRING is coerced to BOOL by its set state, other types raise runtime exception
+		if !expectType(right, types.StringType, types.BoolType) {
+			l.Error(InvalidTypeExpression(
+				exp.GetMeta(), right, types.StringType, types.BoolType,
+			).Match(OPERATOR_CONDITIONAL))
+			// Avoid reporting cascading errors in outer expression
+			return types.BoolType
+		}
 		return right
 	case "-":
 		if !expectType(right, types.IntegerType, types.FloatType, types.RTimeType) {
@@ -279,6 +313,11 @@ func (l *Linter) lintInfixExpression(exp *ast.InfixExpression, ctx *context.Cont
 		default:
 			l.Error(InvalidTypeExpression(exp.GetMeta(), left, types.IntegerType, types.FloatType, types.RTimeType).Match(OPERATOR_CONDITIONAL))
 		}
+		if name, ok := isByteSizeVariable(exp.Left); ok && isSuspiciousSizeLiteral(exp.Right) {
+			l.Error(SuspiciousSizeLiteral(exp.Right.GetMeta(), name, exp.Right.GetMeta().Token.Literal).Match(SIZE_SUSPICIOUS_LITERAL))
+		} else if name, ok := isByteSizeVariable(exp.Right); ok && isSuspiciousSizeLiteral(exp.Left) {
+			l.Error(SuspiciousSizeLiteral(exp.Left.GetMeta(), name, exp.Left.GetMeta().Token.Literal).Match(SIZE_SUSPICIOUS_LITERAL))
+		}
 		return types.BoolType
 	case "~", "!~":
 		// Regex operator could compare only STRING, IP or ACL type
@@ -318,7 +357,18 @@ func (l *Linter) lintInfixExpression(exp *ast.InfixExpression, ctx *context.Cont
 		}
 		return types.BoolType
 	case "&&", "||":
-		// AND / OR operator compares left and right with truthy or falsy
+		// AND / OR operator compares left and right with truthy or falsy.
+		// Only BOOL and STRING, which is coerced to BOOL by its set state, could be compared
+		if !expectType(left, types.StringType, types.BoolType) {
+			l.Error(InvalidTypeExpression(
+				exp.Left.GetMeta(), left, types.StringType, types.BoolType,
+			).Match(OPERATOR_CONDITIONAL))
+		}
+		if !expectType(right, types.StringType, types.BoolType) {
+			l.Error(InvalidTypeExpression(
+				exp.Right.GetMeta(), right, types.StringType, types.BoolType,
+			).Match(OPERATOR_CONDITIONAL))
+		}
 		return types.BoolType
 	default:
 		return types.NeverType
@@ -397,18 +447,16 @@ func (l *Linter) lintFunctionCallExpression(exp *ast.FunctionCallExpression, ctx
 
 	fn, err := ctx.GetFunction(exp.Function.Value)
 	if err != nil {
-		l.Error(&LintError{
-			Severity: ERROR,
-			Token:    exp.Function.GetMeta().Token,
-			Message:  err.Error(),
-		})
+		l.Error(functionError(exp.Function, err, ctx))
 		return types.NeverType
 	}
+	l.lintDeprecation("Function", exp.Function.Value, exp.Function.GetMeta(), false)
 
 	// testing.call_subroutine has a dynamic signature whose extra arguments
 	// depend on the target subroutine's parameter list. Validate it separately,
 	// mirroring the statement-call path.
-	if exp.Function.Value == context.TestingCallSubroutineName {
+	switch exp.Function.Value {
+	case context.TestingCallSubroutineName, context.TestingCallSubroutineInScopeName:
 		return l.lintTestingCallSubroutine(exp.Function, exp.Arguments, ctx)
 	}
 
@@ -705,3 +705,80 @@ sub vcl_recv {
 		assertNoError(t, input)
 	})
 }
+
+func TestLintStringEscape(t *testing.T) {
+	tests := []struct {
+		input string
+		rule  Rule
+		count int
+	}{
+		{input: `"foo%00bar"`, rule: STRING_NULL_ESCAPE, count: 1},
+		{input: `"foo%u0000bar"`, rule: STRING_NULL_ESCAPE, count: 1},
+		{input: `"foo%u{0}bar"`, rule: STRING_NULL_ESCAPE, count: 1},
+		{input: `"foo%2500bar"`, rule: STRING_NULL_ESCAPE, count: 0},
+		{input: `{"foo%00bar"}`, rule: STRING_NULL_ESCAPE, count: 0},
+		{input: `{"foo%20bar"}`, rule: STRING_UNDECODED_ESCAPE, count: 1},
+		{input: `{JSON"foo%u{1F600}bar"JSON}`, rule: STRING_UNDECODED_ESCAPE, count: 1},
+		{input: `{"width: 100%;"}`, rule: STRING_UNDECODED_ESCAPE, count: 0},
+		{input: `"foo%20bar"`, rule: STRING_UNDECODED_ESCAPE, count: 0},
+	}
+
+	for _, tt := range tests {
+		vcl, err := parser.New(lexer.NewFromString(`
+sub vcl_recv {
+	#FASTLY RECV
+	set req.http.Foo = ` + tt.input + `;
+}`)).ParseVCL()
+		if err != nil {
+			t.Errorf("unexpected parser error: %s", err)
+			continue
+		}
+		l := New(testConfig)
+		l.lint(vcl, context.New())
+		var count int
+		for _, e := range l.Errors {
+			if e.Rule == tt.rule {
+				count++
+			}
+		}
+		if count != tt.count {
+			t.Errorf("%s: expect %d %s errors but got %d", tt.input, tt.count, tt.rule, count)
+		}
+	}
+}
+
+func TestLintLogicalOperandType(t *testing.T) {
+	tests := []struct {
+		input string
+		count int
+	}{
+		{input: `if (req.http.Foo && req.is_ssl) {}`, count: 0},
+		{input: `if (!req.http.Foo || req.url ~ "^/foo") {}`, count: 0},
+		{input: `if (req.restarts && req.is_ssl) {}`, count: 1},
+		{input: `if (req.is_ssl || req.restarts) {}`, count: 1},
+		{input: `if (!req.restarts && req.is_ssl) {}`, count: 1},
+	}
+
+	for _, tt := range tests {
+		vcl, err := parser.New(lexer.NewFromString(`
+sub vcl_recv {
+	#FASTLY RECV
+	` + tt.input + `
+}`)).ParseVCL()
+		if err != nil {
+			t.Errorf("unexpected parser error: %s", err)
+			continue
+		}
+		l := New(testConfig)
+		l.lint(vcl, context.New())
+		var count int
+		for _, e := range l.Errors {
+			if e.Rule == OPERATOR_CONDITIONAL {
+				count++
+			}
+		}
+		if count != tt.count {
+			t.Errorf("%s: expect %d %s errors but got %d", tt.input, tt.count, OPERATOR_CONDITIONAL, count)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package linter
 import (
 	"fmt"
 	"slices"
+	"strings"
 
 	"github.com/ysugimoto/falco/v2/ast"
 	"github.com/ysugimoto/falco/v2/linter/context"
@@ -114,6 +115,12 @@ func (l *Linter) lintFunctionArguments(fn *context.BuiltinFunction, calledFn fun
 	}
 
 	// Special cases
+	if calledFn.name == "std.collect" {
+		// Mark header is collected in order to suppress subfield access hint
+		if ident, ok := calledFn.arguments[0].(*ast.Ident); ok {
+			ctx.CollectedHeaders[strings.ToLower(ident.Value)] = struct{}{}
+		}
+	}
 	if calledFn.name == "regsub" || calledFn.name == "regsuball" {
 		if !isTypeLiteral(calledFn.arguments[1]) {
 			l.Error(&LintError{
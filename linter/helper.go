@@ -6,6 +6,7 @@ import (
 	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/ysugimoto/falco/v2/ast"
 	"github.com/ysugimoto/falco/v2/linter/context"
@@ -384,12 +385,18 @@ func isValidVariableNameWithWildcard(name string) bool {
 // if (!"example") { ... }       // -> invalid, could not use with string literal
 //
 // So we'd check validity following function.
-func isValidConditionExpression(cond ast.Expression) error {
+//
+// Constants are also forbidden because they are expanded to the literal before uploading.
+func isValidConditionExpression(cond ast.Expression, constants map[string]*types.Const) error {
 	switch t := cond.(type) {
 	case *ast.PrefixExpression:
-		return isValidConditionExpression(t.Right)
+		return isValidConditionExpression(t.Right, constants)
 	case *ast.InfixExpression:
-		return isValidConditionExpression(t.Left)
+		return isValidConditionExpression(t.Left, constants)
+	case *ast.Ident:
+		if _, ok := constants[t.Value]; ok {
+			return fmt.Errorf("could not specify constant in first expression")
+		}
 	default:
 		if isLiteralExpression(cond) {
 			return fmt.Errorf("could not specify literal in first expression")
@@ -446,6 +453,40 @@ func isValidReturnExpression(exp ast.Expression) error {
 	return nil
 }
 
+// Returns true if the statements never reach the end of the block
+// because the last statement moves the control flow to the other place
+func isTerminatedStatements(stmts []ast.Statement) bool {
+	if len(stmts) == 0 {
+		return false
+	}
+	switch stmts[len(stmts)-1].(type) {
+	case *ast.ReturnStatement, *ast.ErrorStatement, *ast.RestartStatement, *ast.GotoStatement:
+		return true
+	}
+	return false
+}
+
+// Returns local variables which are declared in all of branches
+func intersectDeclaredLocals(branches []map[string]struct{}) map[string]struct{} {
+	intersect := make(map[string]struct{})
+	if len(branches) == 0 {
+		return intersect
+	}
+	for name := range branches[0] {
+		found := true
+		for _, b := range branches[1:] {
+			if _, ok := b[name]; !ok {
+				found = false
+				break
+			}
+		}
+		if found {
+			intersect[name] = struct{}{}
+		}
+	}
+	return intersect
+}
+
 // Push regex captured variable to the context if needed
 func pushRegexGroupVars(exp ast.Expression, ctx *context.Context) {
 	switch t := exp.(type) {
@@ -935,3 +976,76 @@ func toSeriesExpressions(expr ast.Expression, ctx *context.Context) ([]*Series,
 		{Expression: expr},
 	}, nil
 }
+
+// Parse RTIME literal value to duration.
+// Go's time.ParseDuration does not recognize day and year units so we need to convert them
+func parseRTime(v string) (time.Duration, error) {
+	switch {
+	case strings.HasSuffix(v, "d"):
+		d, err := time.ParseDuration(strings.TrimSuffix(v, "d") + "h")
+		return d * 24, err
+	case strings.HasSuffix(v, "y"):
+		d, err := time.ParseDuration(strings.TrimSuffix(v, "y") + "h")
+		return d * 24 * 365, err
+	default:
+		return time.ParseDuration(v)
+	}
+}
+
+// Find the intended RTIME literal when the unit looks wrong for the variable.
+// Timeouts are counted in milliseconds so 1000s or longer is likely a typo of "ms",
+// and TTL-like variables are counted in seconds so "ms" is likely a typo of "s".
+func suggestDurationUnit(name, literal string) (string, bool) {
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		name = name[i+1:]
+	}
+
+	switch name {
+	case "ttl", "grace", "stale_while_revalidate", "stale_if_error":
+		if strings.HasSuffix(literal, "ms") {
+			return strings.TrimSuffix(literal, "ms") + "s", true
+		}
+		return "", false
+	}
+
+	if !strings.HasSuffix(name, "timeout") {
+		return "", false
+	}
+	if strings.HasSuffix(literal, "ms") || !strings.HasSuffix(literal, "s") {
+		return "", false
+	}
+	if v, err := parseRTime(literal); err == nil && v >= 1000*time.Second {
+		return strings.TrimSuffix(literal, "s") + "ms", true
+	}
+	return "", false
+}
+
+// Variables which are counted in bytes like req.body_bytes_read
+func isByteSizeVariable(exp ast.Expression) (string, bool) {
+	ident, ok := exp.(*ast.Ident)
+	if !ok {
+		return "", false
+	}
+	if !strings.Contains(ident.Value, "bytes") || strings.HasSuffix(ident.Value, "timeout") {
+		return "", false
+	}
+	return ident.Value, true
+}
+
+// Byte size limit is rarely compared with a duration or a value less than 1KB,
+// these literals are likely written as KB or MB
+func isSuspiciousSizeLiteral(exp ast.Expression) bool {
+	switch t := exp.(type) {
+	case *ast.Integer:
+		return t.Value > 0 && t.Value < 1024
+	case *ast.RTime:
+		return true
+	}
+	return false
+}
+
+// Percent escapes which Fastly decodes in double-quoted string like %20, %u0020 and %u{20}
+var percentEscapeRegex = regexp.MustCompile(`%(?:[0-9a-fA-F]{2}|u[0-9a-fA-F]{4}|u\{[0-9a-fA-F]{1,6}\})`)
+
+// Percent escapes which represent NUL character
+var nullEscapeRegex = regexp.MustCompile(`%(?:00|u0000|u\{0{1,6}\})`)
@@ -0,0 +1,290 @@
+package linter
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ysugimoto/falco/v2/ast"
+	"github.com/ysugimoto/falco/v2/linter/context"
+)
+
+// Fastly default timeouts which are applied when the backend does not declare them
+var defaultBackendTimeouts = map[string]time.Duration{
+	"connect_timeout":       time.Second,
+	"first_byte_timeout":    15 * time.Second,
+	"between_bytes_timeout": 10 * time.Second,
+}
+
+// Fastly restarts the request at most 3 times
+const maxRestarts = 3
+
+// BackendLatency is the worst-case latency of the route to the backend or director.
+// Each attempt could wait for connect_timeout, first_byte_timeout and single between_bytes_timeout,
+// and the request is attempted again on restart
+type BackendLatency struct {
+	Name                string
+	IsDirector          bool
+	ConnectTimeout      time.Duration
+	FirstByteTimeout    time.Duration
+	BetweenBytesTimeout time.Duration
+	Attempts            int
+	WorstCase           time.Duration
+
+	decl ast.Node
+}
+
+func (b *BackendLatency) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Name                string `json:"name"`
+		IsDirector          bool   `json:"is_director"`
+		ConnectTimeout      string `json:"connect_timeout"`
+		FirstByteTimeout    string `json:"first_byte_timeout"`
+		BetweenBytesTimeout string `json:"between_bytes_timeout"`
+		Attempts            int    `json:"attempts"`
+		WorstCase           string `json:"worst_case"`
+	}{
+		Name:                b.Name,
+		IsDirector:          b.IsDirector,
+		ConnectTimeout:      b.ConnectTimeout.String(),
+		FirstByteTimeout:    b.FirstByteTimeout.String(),
+		BetweenBytesTimeout: b.BetweenBytesTimeout.String(),
+		Attempts:            b.Attempts,
+		WorstCase:           b.WorstCase.String(),
+	})
+}
+
+// Get declared timeouts of the backend, Fastly defaults are used for undeclared or non-literal ones
+func backendTimeouts(decl *ast.BackendDeclaration) map[string]time.Duration {
+	timeouts := make(map[string]time.Duration)
+	for key, v := range defaultBackendTimeouts {
+		timeouts[key] = v
+	}
+	for _, prop := range decl.Properties {
+		if _, ok := timeouts[prop.Key.Value]; !ok {
+			continue
+		}
+		if v, ok := prop.Value.(*ast.RTime); ok {
+			if d, err := parseRTime(v.Value); err == nil {
+				timeouts[prop.Key.Value] = d
+			}
+		}
+	}
+	return timeouts
+}
+
+// EstimateLatencies computes worst-case latencies of all backends and directors in linted VCL.
+// The number of attempts is 1 if VCL never restarts, otherwise it is bounded by "req.restarts < N" conditions
+// or Fastly restart limit. Latency of the director is the worst one of its backends
+func EstimateLatencies(ctx *context.Context) []*BackendLatency {
+	attempts := 1 + estimateRestarts(ctx)
+
+	backends := make(map[string]*BackendLatency)
+	for name, b := range ctx.Backends {
+		if b.BackendDecl == nil {
+			continue
+		}
+		timeouts := backendTimeouts(b.BackendDecl)
+		latency := &BackendLatency{
+			Name:                name,
+			ConnectTimeout:      timeouts["connect_timeout"],
+			FirstByteTimeout:    timeouts["first_byte_timeout"],
+			BetweenBytesTimeout: timeouts["between_bytes_timeout"],
+			Attempts:            attempts,
+			decl:                b.BackendDecl,
+		}
+		latency.WorstCase = time.Duration(attempts) * (latency.ConnectTimeout + latency.FirstByteTimeout + latency.BetweenBytesTimeout)
+		backends[name] = latency
+	}
+
+	var latencies []*BackendLatency
+	for _, v := range backends {
+		latencies = append(latencies, v)
+	}
+	for name, d := range ctx.Directors {
+		latency := &BackendLatency{
+			Name:       name,
+			IsDirector: true,
+			Attempts:   attempts,
+			decl:       d.Decl,
+		}
+		for _, prop := range d.Decl.Properties {
+			obj, ok := prop.(*ast.DirectorBackendObject)
+			if !ok {
+				continue
+			}
+			for _, v := range obj.Values {
+				ident, ok := v.Value.(*ast.Ident)
+				if v.Key.Value != "backend" || !ok {
+					continue
+				}
+				if b, ok := backends[ident.Value]; ok && b.WorstCase > latency.WorstCase {
+					latency.ConnectTimeout = b.ConnectTimeout
+					latency.FirstByteTimeout = b.FirstByteTimeout
+					latency.BetweenBytesTimeout = b.BetweenBytesTimeout
+					latency.WorstCase = b.WorstCase
+				}
+			}
+		}
+		latencies = append(latencies, latency)
+	}
+
+	sort.Slice(latencies, func(i, j int) bool {
+		return latencies[i].Name < latencies[j].Name
+	})
+	return latencies
+}
+
+// Estimate maximum number of restarts from restart statements and req.restarts conditions in subroutines
+func estimateRestarts(ctx *context.Context) int {
+	var hasRestart bool
+	bound := -1
+	for _, sub := range ctx.Subroutines {
+		if sub.Decl == nil {
+			continue
+		}
+		walkRestarts(sub.Decl.Block.Statements, &hasRestart, &bound)
+	}
+	switch {
+	case !hasRestart:
+		return 0
+	case bound < 0 || bound > maxRestarts:
+		return maxRestarts
+	default:
+		return bound
+	}
+}
+
+func walkRestarts(statements []ast.Statement, hasRestart *bool, bound *int) {
+	for _, stmt := range statements {
+		switch t := stmt.(type) {
+		case *ast.RestartStatement:
+			*hasRestart = true
+		case *ast.BlockStatement:
+			walkRestarts(t.Statements, hasRestart, bound)
+		case *ast.IfStatement:
+			for _, s := range append([]*ast.IfStatement{t}, t.Another...) {
+				restartsBound(s.Condition, bound)
+				walkRestarts(s.Consequence.Statements, hasRestart, bound)
+			}
+			if t.Alternative != nil {
+				walkRestarts(t.Alternative.Consequence.Statements, hasRestart, bound)
+			}
+		case *ast.SwitchStatement:
+			for _, c := range t.Cases {
+				walkRestarts(c.Statements, hasRestart, bound)
+			}
+		}
+	}
+}
+
+// Find "req.restarts < N" or "req.restarts <= N" condition and keep the largest bound
+func restartsBound(expr ast.Expression, bound *int) {
+	switch t := expr.(type) {
+	case *ast.GroupedExpression:
+		restartsBound(t.Right, bound)
+	case *ast.PrefixExpression:
+		restartsBound(t.Right, bound)
+	case *ast.InfixExpression:
+		left, ok := t.Left.(*ast.Ident)
+		right, isInt := t.Right.(*ast.Integer)
+		if !ok || !isInt || left.Value != "req.restarts" {
+			restartsBound(t.Left, bound)
+			restartsBound(t.Right, bound)
+			return
+		}
+		n := int(right.Value)
+		switch t.Operator {
+		case "<":
+		case "<=":
+			n++
+		default:
+			return
+		}
+		if n > *bound {
+			*bound = n
+		}
+	}
+}
+
+// Validate declared timeouts of the backend against each other and configured maximums
+func (l *Linter) lintBackendTimeouts(decl *ast.BackendDeclaration) {
+	timeouts := backendTimeouts(decl)
+	props := make(map[string]*ast.BackendProperty)
+	for _, prop := range decl.Properties {
+		props[prop.Key.Value] = prop
+	}
+	report := func(key, message string) {
+		tok := decl.Name.GetMeta().Token
+		if prop, ok := props[key]; ok {
+			tok = prop.Value.GetMeta().Token
+		}
+		l.Error((&LintError{
+			Severity: WARNING,
+			Token:    tok,
+			Message:  message,
+		}).Match(BACKEND_TIMEOUT))
+	}
+
+	for _, v := range []struct {
+		key     string
+		maximum string
+		option  string
+	}{
+		{"connect_timeout", l.conf.MaxConnectTimeout, "max_connect_timeout"},
+		{"first_byte_timeout", l.conf.MaxFirstByteTimeout, "max_first_byte_timeout"},
+		{"between_bytes_timeout", l.conf.MaxBetweenBytesTimeout, "max_between_bytes_timeout"},
+	} {
+		if v.maximum == "" {
+			continue
+		}
+		maximum, err := parseRTime(v.maximum)
+		if err != nil {
+			l.Error(fmt.Errorf("invalid %s %q: %w", v.option, v.maximum, err))
+			continue
+		}
+		if timeouts[v.key] > maximum {
+			report(v.key, fmt.Sprintf(
+				"%s %s of backend %s exceeds configured maximum %s",
+				v.key, timeouts[v.key], decl.Name.Value, maximum,
+			))
+		}
+	}
+
+	// Connection should be established and response body should keep flowing faster than the first byte arrives
+	first := timeouts["first_byte_timeout"]
+	for _, key := range []string{"connect_timeout", "between_bytes_timeout"} {
+		if timeouts[key] > first {
+			report(key, fmt.Sprintf(
+				"%s %s of backend %s is longer than first_byte_timeout %s",
+				key, timeouts[key], decl.Name.Value, first,
+			))
+		}
+	}
+}
+
+// Report the route whose worst-case latency including restart-based retries exceeds configured client-facing limit
+func (l *Linter) lintRetryLatency(ctx *context.Context) {
+	if l.conf.ClientTimeout == "" {
+		return
+	}
+	limit, err := parseRTime(l.conf.ClientTimeout)
+	if err != nil {
+		l.Error(fmt.Errorf("invalid client_timeout %q: %w", l.conf.ClientTimeout, err))
+		return
+	}
+	for _, latency := range EstimateLatencies(ctx) {
+		if latency.WorstCase <= limit {
+			continue
+		}
+		l.Error((&LintError{
+			Severity: WARNING,
+			Token:    latency.decl.GetMeta().Token,
+			Message: fmt.Sprintf(
+				"Worst-case latency %s of %s with %d attempts exceeds client timeout %s, reduce timeouts or restarts",
+				latency.WorstCase, latency.Name, latency.Attempts, limit,
+			),
+		}).Match(BACKEND_RETRY_LATENCY))
+	}
+}
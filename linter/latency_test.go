@@ -0,0 +1,153 @@
+package linter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ysugimoto/falco/v2/config"
+	"github.com/ysugimoto/falco/v2/lexer"
+	"github.com/ysugimoto/falco/v2/linter/context"
+	"github.com/ysugimoto/falco/v2/parser"
+)
+
+func TestLintBackendTimeouts(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		conf  *config.LinterConfig
+		rule  Rule
+		count int
+	}{
+		{
+			name:  "default timeouts",
+			input: `backend F_origin { .host = "example.com"; }`,
+			conf:  &config.LinterConfig{MaxFirstByteTimeout: "30s"},
+			rule:  BACKEND_TIMEOUT,
+			count: 0,
+		},
+		{
+			name:  "exceeds maximum",
+			input: `backend F_origin { .host = "example.com"; .first_byte_timeout = 60s; .between_bytes_timeout = 5s; }`,
+			conf:  &config.LinterConfig{MaxFirstByteTimeout: "30s", MaxBetweenBytesTimeout: "10s"},
+			rule:  BACKEND_TIMEOUT,
+			count: 1,
+		},
+		{
+			name:  "default timeout exceeds maximum",
+			input: `backend F_origin { .host = "example.com"; }`,
+			conf:  &config.LinterConfig{MaxFirstByteTimeout: "10s"},
+			rule:  BACKEND_TIMEOUT,
+			count: 1,
+		},
+		{
+			name:  "connect and between bytes timeouts are longer than first byte timeout",
+			input: `backend F_origin { .host = "example.com"; .connect_timeout = 5s; .first_byte_timeout = 3s; .between_bytes_timeout = 5s; }`,
+			conf:  &config.LinterConfig{},
+			rule:  BACKEND_TIMEOUT,
+			count: 2,
+		},
+		{
+			name: "retries exceed client timeout",
+			input: `backend F_origin { .host = "example.com"; }
+sub vcl_recv {
+	#FASTLY recv
+	set req.backend = F_origin;
+}
+sub vcl_deliver {
+	#FASTLY deliver
+	if (resp.status >= 500 && req.restarts < 3) {
+		restart;
+	}
+}`,
+			conf:  &config.LinterConfig{ClientTimeout: "60s"},
+			rule:  BACKEND_RETRY_LATENCY,
+			count: 1,
+		},
+		{
+			name: "retries are within client timeout",
+			input: `backend F_origin { .host = "example.com"; .first_byte_timeout = 5s; .between_bytes_timeout = 5s; }
+sub vcl_recv {
+	#FASTLY recv
+	set req.backend = F_origin;
+}
+sub vcl_deliver {
+	#FASTLY deliver
+	if (resp.status >= 500 && req.restarts < 1) {
+		restart;
+	}
+}`,
+			conf:  &config.LinterConfig{ClientTimeout: "60s"},
+			rule:  BACKEND_RETRY_LATENCY,
+			count: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vcl, err := parser.New(lexer.NewFromString(tt.input)).ParseVCL()
+			if err != nil {
+				t.Errorf("unexpected parser error: %s", err)
+				return
+			}
+			l := New(tt.conf)
+			l.Lint(vcl, context.New())
+			var count int
+			for _, e := range l.Errors {
+				if e.Rule != tt.rule {
+					continue
+				}
+				count++
+				if e.Severity != WARNING {
+					t.Errorf("Severity expects WARNING but got %s with: %s", e.Severity, e)
+				}
+			}
+			if count != tt.count {
+				t.Errorf("Expect %d %s errors but got %d: %v", tt.count, tt.rule, count, l.Errors)
+			}
+		})
+	}
+}
+
+func TestEstimateLatencies(t *testing.T) {
+	input := `
+backend F_primary { .host = "primary.example.com"; .connect_timeout = 2s; .first_byte_timeout = 20s; }
+backend F_secondary { .host = "secondary.example.com"; .first_byte_timeout = 5s; .between_bytes_timeout = 5s; }
+director D_origins random {
+	{ .backend = F_primary; .weight = 1; }
+	{ .backend = F_secondary; .weight = 1; }
+}
+sub vcl_recv {
+	#FASTLY recv
+	set req.backend = D_origins;
+}
+sub vcl_fetch {
+	#FASTLY fetch
+	if (beresp.status == 503 && req.restarts <= 1) {
+		restart;
+	}
+}`
+	vcl, err := parser.New(lexer.NewFromString(input)).ParseVCL()
+	if err != nil {
+		t.Fatalf("unexpected parser error: %s", err)
+	}
+	ctx := context.New()
+	New(testConfig).Lint(vcl, ctx)
+
+	expects := map[string]time.Duration{
+		"D_origins":   3 * 32 * time.Second,
+		"F_primary":   3 * 32 * time.Second,
+		"F_secondary": 3 * 11 * time.Second,
+	}
+	latencies := EstimateLatencies(ctx)
+	if len(latencies) != len(expects) {
+		t.Fatalf("Expect %d latencies but got %d", len(expects), len(latencies))
+	}
+	for _, l := range latencies {
+		if l.Attempts != 3 {
+			t.Errorf("Attempts of %s expects 3 but got %d", l.Name, l.Attempts)
+		}
+		if l.WorstCase != expects[l.Name] {
+			t.Errorf("Worst-case latency of %s expects %s but got %s", l.Name, expects[l.Name], l.WorstCase)
+		}
+	}
+}
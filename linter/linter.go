@@ -18,16 +18,26 @@ import (
 type Linter struct {
 	Errors     []*LintError
 	FatalError *FatalError
-	lexers     map[string]*lexer.Lexer
-	ignore     *ignore
-	conf       *config.LinterConfig
+	// Root statements whose include statements are resolved, set after linting main VCL
+	Statements []ast.Statement
+	// Resolved file names of included modules, empty if the module could not be resolved
+	Includes map[string]string
+	lexers   map[string]*lexer.Lexer
+	modules  map[string]*parsedModule
+	ignore   *ignore
+	conf     *config.LinterConfig
+
+	// Header name patterns which must never be exposed to clients or logs
+	protectedHeaders []string
 }
 
 func New(c *config.LinterConfig, opts ...optionFunc) *Linter {
 	l := &Linter{
-		lexers: make(map[string]*lexer.Lexer),
-		ignore: &ignore{},
-		conf:   c,
+		Includes: make(map[string]string),
+		lexers:   make(map[string]*lexer.Lexer),
+		modules:  make(map[string]*parsedModule),
+		ignore:   &ignore{},
+		conf:     c,
 	}
 	for i := range opts {
 		opts[i](l)
@@ -59,6 +69,19 @@ func (l *Linter) Lint(node ast.Node, ctx *context.Context) types.Type {
 	if ctx == nil {
 		ctx = context.New()
 	}
+	// Report panic as lint error for the linting VCL, unused checks are skipped
+	// because declaration usages could not be collected completely
+	defer func() {
+		if r := recover(); r != nil {
+			l.Error(RecoveredPanic(r))
+		}
+	}()
+
+	// VCL which is written in other dialect is linted on best-effort without Fastly type checking
+	if d := l.dialect(); !d.IsFastly() {
+		l.lintDialect(node, ctx, d)
+		return types.NeverType
+	}
 
 	l.lint(node, ctx)
 
@@ -70,6 +93,10 @@ func (l *Linter) Lint(node ast.Node, ctx *context.Context) types.Type {
 	l.lintUnusedGotos(ctx)
 	l.lintUnusedPenaltyboxes(ctx)
 	l.lintUnusedRatecounters(ctx)
+	l.lintUnusedConstants(ctx)
+
+	// Restart-based retries could be estimated after all subroutines have been linted
+	l.lintRetryLatency(ctx)
 
 	return types.NeverType
 }
@@ -155,6 +182,15 @@ func (l *Linter) lintUnusedRatecounters(ctx *context.Context) {
 	}
 }
 
+func (l *Linter) lintUnusedConstants(ctx *context.Context) {
+	for _, c := range ctx.Constants {
+		if c.IsUsed {
+			continue
+		}
+		l.Error(UnusedDeclaration(c.Decl.GetMeta(), c.Decl.Name.Value, "const").Match(UNUSED_DECLARATION))
+	}
+}
+
 func (l *Linter) lintUnusedVariables(ctx *context.Context) {
 	v, ok := ctx.Variables["var"]
 	if !ok {
@@ -203,9 +239,12 @@ func (l *Linter) lint(node ast.Node, ctx *context.Context) types.Type {
 	case *ast.SubroutineDeclaration:
 		return l.lintSubRoutineDeclaration(t, ctx)
 	case *ast.PenaltyboxDeclaration:
-		return l.lintPenaltyboxDeclaration(t)
+		return l.lintPenaltyboxDeclaration(t, ctx)
 	case *ast.RatecounterDeclaration:
-		return l.lintRatecounterDeclaration(t)
+		return l.lintRatecounterDeclaration(t, ctx)
+	case *ast.ConstDeclaration:
+		// Constant value is linted on factoring root declarations
+		return types.NeverType
 
 	// Statements
 	case *ast.BlockStatement:
@@ -259,13 +298,13 @@ func (l *Linter) lint(node ast.Node, ctx *context.Context) types.Type {
 	case *ast.Boolean:
 		return l.lintBoolean(t)
 	case *ast.Integer:
-		return l.lintInteger(t)
+		return l.lintInteger(t, ctx)
 	case *ast.String:
 		return l.lintString(t)
 	case *ast.Float:
 		return l.lintFloat(t)
 	case *ast.RTime:
-		return l.lintRTime(t)
+		return l.lintRTime(t, ctx)
 	case *ast.PrefixExpression:
 		return l.lintPrefixExpression(t, ctx)
 	case *ast.PostfixExpression:
@@ -304,8 +343,10 @@ func (l *Linter) lintVCL(vcl *ast.VCL, ctx *context.Context) types.Type {
 		return l.lintSnippetVCL(vcl, ctx)
 	}
 
-	// Resolve module, snippet inclusion
+	// Parse included modules concurrently before resolving, then resolve module, snippet inclusion
+	l.preloadModules(vcl.Statements, ctx)
 	statements := l.resolveIncludeStatements(vcl.Statements, ctx, true)
+	l.Statements = statements
 
 	// https://github.com/ysugimoto/falco/issues/50
 	// To support subroutine hoisting, add root statements to context firstly and lint each statements after that.
@@ -377,18 +418,20 @@ func (l *Linter) loadSnippetVCL(file, content string) []ast.Statement {
 }
 
 func (l *Linter) loadVCL(file, content string) []ast.Statement {
-	lx := lexer.NewFromString(content, lexer.WithFile(file))
-	l.lexers[file] = lx
-	vcl, err := parser.New(lx).ParseVCL()
-	if err != nil {
-		lx.NewLine()
+	return l.loadModule(parseModule(file, content))
+}
+
+func (l *Linter) loadModule(m *parsedModule) []ast.Statement {
+	l.lexers[m.name] = m.lexer
+	if m.err != nil {
+		m.lexer.NewLine()
 		l.FatalError = &FatalError{
-			Lexer: lx,
-			Error: errors.Cause(err),
+			Lexer: m.lexer,
+			Error: errors.Cause(m.err),
 		}
 		return []ast.Statement{}
 	}
-	return vcl.Statements
+	return m.statements
 }
 
 func (l *Linter) resolveIncludeStatements(statements []ast.Statement, ctx *context.Context, isRoot bool) []ast.Statement {
@@ -450,8 +493,17 @@ func (l *Linter) resolveFileInclusion(
 ) []ast.Statement {
 
 	var statements []ast.Statement
+	if isRoot {
+		if m := l.takeModule(include); m != nil {
+			ctx.Restore()
+			l.Includes[include.Module.Value] = m.name
+			return l.resolveIncludeStatements(l.loadModule(m), ctx, isRoot)
+		}
+	}
+
 	module, err := ctx.Restore().Resolver().Resolve(include)
 	if err != nil {
+		l.Includes[include.Module.Value] = ""
 		e := &LintError{
 			Severity: ERROR,
 			Token:    include.GetMeta().Token,
@@ -460,6 +512,7 @@ func (l *Linter) resolveFileInclusion(
 		l.Error(e.Match(INCLUDE_STATEMENT_MODULE_LOAD_FAILED))
 		return statements
 	}
+	l.Includes[include.Module.Value] = module.Name
 
 	if isRoot {
 		statements = l.loadVCL(module.Name, module.Data)
@@ -590,6 +643,17 @@ func (l *Linter) factoryRootDeclarations(statements []ast.Statement, ctx *contex
 				l.Error(e.Match(PENALTYBOX_DUPLICATED))
 			}
 			factory = append(factory, stmt)
+		case *ast.ConstDeclaration:
+			c := &types.Const{Decl: t, ValueType: l.lint(t.Value, ctx)}
+			if err := ctx.AddConst(t.Name.Value, c); err != nil {
+				e := &LintError{
+					Severity: ERROR,
+					Token:    t.Name.GetMeta().Token,
+					Message:  err.Error(),
+				}
+				l.Error(e.Match(CONST_DUPLICATED))
+			}
+			factory = append(factory, stmt)
 		case *ast.RatecounterDeclaration:
 			if err := ctx.AddRatecounter(t.Name.Value, &types.Ratecounter{Decl: t}); err != nil {
 				e := &LintError{
@@ -2,8 +2,10 @@ package linter
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/pkg/errors"
 	"github.com/ysugimoto/falco/v2/ast"
 	"github.com/ysugimoto/falco/v2/config"
@@ -627,6 +629,73 @@ sub vcl_recv {
 	assertNoError(t, input, context.WithResolver(mock))
 }
 
+func TestResolveIncludeStatementsInParallel(t *testing.T) {
+	mock := &mockResolver{
+		dependency: map[string]string{},
+	}
+	var main strings.Builder
+	for i := range 32 {
+		// Each module includes nested module which is resolved on next level
+		mock.dependency[fmt.Sprintf("deps%02d", i)] = fmt.Sprintf(`
+include "nested%02d";
+
+//@recv
+sub deps%02d {
+	call nested%02d;
+}
+`, i, i, i)
+		mock.dependency[fmt.Sprintf("nested%02d", i)] = fmt.Sprintf(`
+//@recv
+sub nested%02d {
+	set req.http.Foo = "bar";
+}
+`, i)
+		fmt.Fprintf(&main, "include \"deps%02d\";\n", i)
+	}
+	main.WriteString("sub vcl_recv {\n#FASTLY RECV\n")
+	for i := range 32 {
+		fmt.Fprintf(&main, "call deps%02d;\n", i)
+	}
+	main.WriteString("}\n")
+
+	t.Run("included modules are merged into context", func(t *testing.T) {
+		vcl, err := parser.New(lexer.NewFromString(main.String())).ParseVCL()
+		if err != nil {
+			t.Errorf("unexpected parser error: %s", err)
+			t.FailNow()
+		}
+		l := New(&config.LinterConfig{ParseWorkers: 4})
+		l.lint(vcl, context.New(context.WithResolver(mock)))
+		if len(l.Errors) > 0 {
+			t.Errorf("Lint error: %s", l.Errors)
+		}
+		if l.FatalError != nil {
+			t.Errorf("Fatal error: %s", l.FatalError.Error)
+		}
+		if len(l.Lexers()) != 64 {
+			t.Errorf("Expect 64 lexers but got %d", len(l.Lexers()))
+		}
+	})
+
+	t.Run("parse error in nested module is fatal", func(t *testing.T) {
+		mock.dependency["nested10"] = `sub nested10 {`
+		vcl, err := parser.New(lexer.NewFromString(main.String())).ParseVCL()
+		if err != nil {
+			t.Errorf("unexpected parser error: %s", err)
+			t.FailNow()
+		}
+		l := New(&config.LinterConfig{ParseWorkers: 4})
+		l.lint(vcl, context.New(context.WithResolver(mock)))
+		if l.FatalError == nil {
+			t.Errorf("Expect fatal error but nil")
+			t.FailNow()
+		}
+		if l.FatalError.Lexer != l.Lexers()["nested10.vcl"] {
+			t.Errorf("Fatal error must be reported on nested10.vcl")
+		}
+	})
+}
+
 func TestResolveIncludeStateInIfStatement(t *testing.T) {
 	mock := &mockResolver{
 		dependency: map[string]string{
@@ -1025,3 +1094,97 @@ sub vcl_fetch {
 		assertNoError(t, input)
 	})
 }
+
+func TestSuggestClosestNames(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		expect []string
+	}{
+		{
+			name: "predefined variable",
+			input: `
+sub vcl_recv {
+  #FASTLY recv
+  if (req.restrats > 0) {
+    esi;
+  }
+}`,
+			expect: []string{"req.restarts"},
+		},
+		{
+			name: "backend",
+			input: `
+backend F_origin {
+  .host = "example.com";
+}
+
+sub vcl_recv {
+  #FASTLY recv
+  set req.backend = F_orign;
+}`,
+			expect: []string{"F_origin"},
+		},
+		{
+			name: "function",
+			input: `
+sub vcl_recv {
+  #FASTLY recv
+  set req.http.Length = std.strln(req.url);
+}`,
+			expect: []string{"std.strlen"},
+		},
+		{
+			name: "subroutine",
+			input: `
+sub check_request {
+  esi;
+}
+
+sub vcl_recv {
+  #FASTLY recv
+  call chek_request;
+}`,
+			expect: []string{"check_request"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vcl, err := parser.New(lexer.NewFromString(tt.input)).ParseVCL()
+			if err != nil {
+				t.Fatalf("unexpected parser error: %s", err)
+			}
+			l := New(testConfig)
+			l.lint(vcl, context.New())
+			for _, e := range l.Errors {
+				if len(e.Suggestions) == 0 {
+					continue
+				}
+				if diff := cmp.Diff(tt.expect, e.Suggestions); diff != "" {
+					t.Errorf("Suggestions mismatch, diff=%s", diff)
+				}
+				if !strings.Contains(e.Message, "did you mean") {
+					t.Errorf("Message should contain suggestion: %s", e.Message)
+				}
+				return
+			}
+			t.Errorf("Expected error with suggestions, got %v", l.Errors)
+		})
+	}
+}
+
+func TestLintRecoverPanic(t *testing.T) {
+	l := New(testConfig)
+	// Subroutine declaration without name could not be made by the parser so the linter panics
+	l.Lint(&ast.VCL{
+		Statements: []ast.Statement{&ast.SubroutineDeclaration{Meta: &ast.Meta{}}},
+	}, context.New())
+
+	if len(l.Errors) != 1 {
+		t.Fatalf("Expected one lint error but got %d", len(l.Errors))
+	}
+	if !strings.HasPrefix(l.Errors[0].Message, "Linter panicked unexpectedly") {
+		t.Errorf("Expected recovered panic error but got %s", l.Errors[0].Message)
+	}
+}
@@ -0,0 +1,110 @@
+package linter
+
+import (
+	"runtime"
+	"strings"
+
+	"github.com/ysugimoto/falco/v2/ast"
+	"github.com/ysugimoto/falco/v2/lexer"
+	"github.com/ysugimoto/falco/v2/linter/context"
+	"github.com/ysugimoto/falco/v2/parser"
+	"github.com/ysugimoto/falco/v2/resolver"
+	"golang.org/x/sync/errgroup"
+)
+
+// Parsed result of included module
+type parsedModule struct {
+	name       string
+	lexer      *lexer.Lexer
+	statements []ast.Statement
+	err        error
+}
+
+func parseModule(file, content string, opts ...parser.ParserOption) *parsedModule {
+	lx := lexer.NewFromString(content, lexer.WithFile(file))
+	vcl, err := parser.New(lx, opts...).ParseVCL()
+	if err != nil {
+		return &parsedModule{name: file, lexer: lx, err: err}
+	}
+	return &parsedModule{name: file, lexer: lx, statements: vcl.Statements}
+}
+
+// preloadModules resolves and parses all modules which are included on root concurrently.
+// Files are independent each other on parsing so that modules are parsed by bounded workers level by level.
+// Note that only parsing runs in parallel, the linter still lints parsed modules serially in the include order
+// because declarations of earlier modules must be visible in later ones.
+// Only include statements at the top level of each file are preloaded, others are parsed on linting as usual.
+// Failed resolution is not stored in order to report the error at the include statement as usual.
+func (l *Linter) preloadModules(statements []ast.Statement, ctx *context.Context) {
+	rslv := ctx.Resolver()
+	seen := make(map[string]struct{})
+	pending := collectIncludes(statements, seen)
+
+	for len(pending) > 0 {
+		parsed := make([]*parsedModule, len(pending))
+
+		var eg errgroup.Group
+		eg.SetLimit(l.parseWorkers())
+		for i := range pending {
+			eg.Go(func() error {
+				parsed[i] = resolveModule(rslv, pending[i])
+				return nil
+			})
+		}
+		eg.Wait() // nolint:errcheck
+
+		var next []*ast.IncludeStatement
+		for i := range parsed {
+			if parsed[i] == nil {
+				continue
+			}
+			l.modules[pending[i].Module.Value] = parsed[i]
+			next = append(next, collectIncludes(parsed[i].statements, seen)...)
+		}
+		pending = next
+	}
+}
+
+func resolveModule(rslv resolver.Resolver, include *ast.IncludeStatement) *parsedModule {
+	vcl, err := rslv.Resolve(include)
+	if err != nil {
+		return nil
+	}
+	return parseModule(vcl.Name, vcl.Data)
+}
+
+// collectIncludes collects module include statements which have not been seen yet.
+// Fastly managed snippets are excluded because they are already fetched
+func collectIncludes(statements []ast.Statement, seen map[string]struct{}) []*ast.IncludeStatement {
+	var includes []*ast.IncludeStatement
+	for _, stmt := range statements {
+		include, ok := stmt.(*ast.IncludeStatement)
+		if !ok || strings.HasPrefix(include.Module.Value, "snippet::") {
+			continue
+		}
+		if _, ok := seen[include.Module.Value]; ok {
+			continue
+		}
+		seen[include.Module.Value] = struct{}{}
+		includes = append(includes, include)
+	}
+	return includes
+}
+
+// takeModule returns preloaded module for the include statement.
+// Preloaded module is used only once because the same module included twice must have different AST nodes
+func (l *Linter) takeModule(include *ast.IncludeStatement) *parsedModule {
+	m, ok := l.modules[include.Module.Value]
+	if !ok {
+		return nil
+	}
+	delete(l.modules, include.Module.Value)
+	return m
+}
+
+func (l *Linter) parseWorkers() int {
+	if l.conf != nil && l.conf.ParseWorkers > 0 {
+		return l.conf.ParseWorkers
+	}
+	return runtime.NumCPU()
+}
@@ -0,0 +1,106 @@
+package linter
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/ysugimoto/falco/v2/ast"
+)
+
+// Variable prefixes which are sent to clients as response headers
+var clientVisiblePrefixes = []string{"resp.http.", "obj.http."}
+
+// WithProtectedHeaders specifies header name patterns which must never be exposed to clients or logs
+func WithProtectedHeaders(names []string) optionFunc {
+	return func(l *Linter) {
+		l.protectedHeaders = names
+	}
+}
+
+// lintProtectedHeaderAssignment reports assignments which expose protected headers to clients:
+// the protected header is set to the response, or the value of the protected header is assigned to the response header
+func (l *Linter) lintProtectedHeaderAssignment(ident *ast.Ident, value ast.Expression) {
+	if len(l.protectedHeaders) == 0 || !isClientVisibleVariable(ident.Value) {
+		return
+	}
+
+	if header, ok := l.protectedHeader(ident.Value); ok {
+		l.Error((&LintError{
+			Severity: ERROR,
+			Token:    ident.GetMeta().Token,
+			Message:  fmt.Sprintf("Protected header %s must not be exposed to clients via %s", header, ident.Value),
+		}).Match(HEADER_PROTECTED_EXPOSURE))
+		return
+	}
+	l.lintProtectedHeaderValue(value, "clients via "+ident.Value)
+}
+
+// lintProtectedHeaderValue reports the expression which refers protected headers is written to the sink
+// like log, synthetic response and error response
+func (l *Linter) lintProtectedHeaderValue(value ast.Expression, sink string) {
+	if len(l.protectedHeaders) == 0 || value == nil {
+		return
+	}
+	for _, ident := range collectIdents(value) {
+		header, ok := l.protectedHeader(ident.Value)
+		if !ok {
+			continue
+		}
+		l.Error((&LintError{
+			Severity: ERROR,
+			Token:    ident.GetMeta().Token,
+			Message:  fmt.Sprintf("Value of protected header %s must not be exposed to %s", header, sink),
+		}).Match(HEADER_PROTECTED_EXPOSURE))
+	}
+}
+
+// Find protected header name which matches the variable name like req.http.X-Internal-Auth
+func (l *Linter) protectedHeader(name string) (string, bool) {
+	idx := strings.Index(name, ".http.")
+	if idx == -1 {
+		return "", false
+	}
+	header := name[idx+len(".http."):]
+	if colon := strings.Index(header, ":"); colon != -1 {
+		header = header[:colon]
+	}
+	for _, pattern := range l.protectedHeaders {
+		if matched, _ := path.Match(strings.ToLower(pattern), strings.ToLower(header)); matched {
+			return header, true
+		}
+	}
+	return "", false
+}
+
+func isClientVisibleVariable(name string) bool {
+	for _, prefix := range clientVisiblePrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Collect identifiers which are referred in the expression recursively
+func collectIdents(expr ast.Expression) []*ast.Ident {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return []*ast.Ident{t}
+	case *ast.InfixExpression:
+		return append(collectIdents(t.Left), collectIdents(t.Right)...)
+	case *ast.PrefixExpression:
+		return collectIdents(t.Right)
+	case *ast.GroupedExpression:
+		return collectIdents(t.Right)
+	case *ast.IfExpression:
+		return append(collectIdents(t.Consequence), collectIdents(t.Alternative)...)
+	case *ast.FunctionCallExpression:
+		var idents []*ast.Ident
+		for _, arg := range t.Arguments {
+			idents = append(idents, collectIdents(arg)...)
+		}
+		return idents
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package linter
+
+import (
+	"testing"
+
+	"github.com/ysugimoto/falco/v2/lexer"
+	"github.com/ysugimoto/falco/v2/linter/context"
+	"github.com/ysugimoto/falco/v2/parser"
+)
+
+func TestLintProtectedHeaders(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		count int
+	}{
+		{
+			name:  "protected header is set to response",
+			input: `sub vcl_deliver { set resp.http.X-Internal-Auth = "secret"; }`,
+			count: 1,
+		},
+		{
+			name:  "protected header value is assigned to response header",
+			input: `sub vcl_deliver { set resp.http.X-Debug = "auth=" req.http.x-internal-auth; }`,
+			count: 1,
+		},
+		{
+			name:  "protected header value is added to error object",
+			input: `sub vcl_error { add obj.http.X-Debug = std.tolower(req.http.X-Internal-Token); }`,
+			count: 1,
+		},
+		{
+			name:  "protected header value is logged",
+			input: `sub vcl_log { log "syslog " req.service_id " logger :: " req.http.X-Internal-Auth; }`,
+			count: 1,
+		},
+		{
+			name:  "protected header value is written to synthetic response",
+			input: `sub vcl_error { synthetic req.http.X-Internal-Auth; }`,
+			count: 1,
+		},
+		{
+			name:  "protected header value is used for error response",
+			input: `sub vcl_recv { error 601 req.http.X-Internal-Auth; }`,
+			count: 1,
+		},
+		{
+			name:  "protected header is sent to origin",
+			input: `sub vcl_miss { set bereq.http.X-Internal-Auth = req.http.X-Internal-Auth; }`,
+			count: 0,
+		},
+		{
+			name:  "protected header is used in condition",
+			input: `sub vcl_deliver { if (req.http.X-Internal-Auth) { set resp.http.X-Authorized = "1"; } }`,
+			count: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vcl, err := parser.New(lexer.NewFromString(tt.input)).ParseVCL()
+			if err != nil {
+				t.Errorf("unexpected parser error: %s", err)
+				return
+			}
+			l := New(testConfig, WithProtectedHeaders([]string{"X-Internal-Auth", "X-Internal-Token"}))
+			l.Lint(vcl, context.New())
+			var count int
+			for _, e := range l.Errors {
+				if e.Rule == HEADER_PROTECTED_EXPOSURE {
+					count++
+				}
+			}
+			if count != tt.count {
+				t.Errorf("Expect %d %s errors but got %d: %v", tt.count, HEADER_PROTECTED_EXPOSURE, count, l.Errors)
+			}
+		})
+	}
+}
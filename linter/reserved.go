@@ -0,0 +1,86 @@
+package linter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ysugimoto/falco/v2/ast"
+	"github.com/ysugimoto/falco/v2/linter/context"
+)
+
+// Name prefixes which Fastly uses for the declarations on generating VCL from service configuration.
+// For example, backend which is added via API or UI is rendered as "backend F_<name>"
+var fastlyGeneratedPrefixes = []string{"F_", "T_"}
+
+// lintReservedName reports the declaration name which collides with Fastly builtin namespaces or
+// names that Fastly generated VCL emits. These collisions pass the compilation on falco
+// but fail on deploying to Fastly, or break the generated VCL silently.
+func (l *Linter) lintReservedName(ident *ast.Ident, kind string, ctx *context.Context) {
+	name := ident.Value
+
+	// Declarations which are rendered from remote snippets are generated by Fastly
+	if strings.HasPrefix(ident.GetMeta().Token.File, "Remote.") {
+		return
+	}
+
+	if kind != "declare local" && ctx.IsBuiltinNamespace(name) {
+		l.Error((&LintError{
+			Severity: ERROR,
+			Token:    ident.GetMeta().Token,
+			Message: fmt.Sprintf(
+				`The %s name "%s" collides with Fastly builtin namespace, Fastly rejects it on deploy`,
+				kind, name,
+			),
+		}).Match(RESERVED_BUILTIN_NAME))
+		return
+	}
+
+	switch kind {
+	case "declare local":
+		// Fastly generated VCL declares local variables like var.fastly_req_do_shield in lifecycle subroutines
+		if strings.HasPrefix(name, "var.fastly_") {
+			l.Error((&LintError{
+				Severity: WARNING,
+				Token:    ident.GetMeta().Token,
+				Message: fmt.Sprintf(
+					`Local variable "%s" may collide with the variable which is declared by Fastly generated VCL`,
+					name,
+				),
+			}).Match(RESERVED_GENERATED_NAME))
+		}
+		return
+	case "sub":
+		// "vcl_" prefix is reserved for the lifecycle subroutines
+		if strings.HasPrefix(name, "vcl_") && !context.IsFastlySubroutine(name) {
+			l.Error((&LintError{
+				Severity: WARNING,
+				Token:    ident.GetMeta().Token,
+				Message: fmt.Sprintf(
+					`Subroutine "%s" has "vcl_" prefix which is reserved for Fastly lifecycle subroutines`,
+					name,
+				),
+			}).Match(RESERVED_GENERATED_NAME))
+			return
+		}
+	case "backend", "director":
+		// Custom VCL conventionally declares "F_" prefixed backends and directors
+		// to refer the ones which are configured on the service, so they are not reported
+		return
+	}
+
+	for _, prefix := range fastlyGeneratedPrefixes {
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		l.Error((&LintError{
+			Severity: INFO,
+			Token:    ident.GetMeta().Token,
+			Message: fmt.Sprintf(
+				`The %s name "%s" has "%s" prefix which Fastly uses for generated declarations, `+
+					"it may collide with the declaration which Fastly generates",
+				kind, name, prefix,
+			),
+		}).Match(RESERVED_GENERATED_NAME))
+		return
+	}
+}
@@ -0,0 +1,78 @@
+package linter
+
+import (
+	"testing"
+
+	"github.com/ysugimoto/falco/v2/lexer"
+	"github.com/ysugimoto/falco/v2/linter/context"
+	"github.com/ysugimoto/falco/v2/parser"
+)
+
+func TestLintReservedName(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		rule     Rule
+		severity Severity
+		count    int
+	}{
+		{name: "acl", input: `acl client {}`, rule: RESERVED_BUILTIN_NAME, severity: ERROR, count: 1},
+		{name: "backend", input: `backend req {}`, rule: RESERVED_BUILTIN_NAME, severity: ERROR, count: 1},
+		{name: "table", input: `table std {}`, rule: RESERVED_BUILTIN_NAME, severity: ERROR, count: 1},
+		{name: "penaltybox", input: `penaltybox math {}`, rule: RESERVED_BUILTIN_NAME, severity: ERROR, count: 1},
+		{name: "ratecounter", input: `ratecounter geoip {}`, rule: RESERVED_BUILTIN_NAME, severity: ERROR, count: 1},
+		{name: "function without namespace", input: `table urlencode {}`, rule: RESERVED_BUILTIN_NAME, severity: ERROR, count: 1},
+		{name: "subroutine", input: `sub beresp {}`, rule: RESERVED_BUILTIN_NAME, severity: ERROR, count: 1},
+		{name: "user defined function", input: `sub get_value STRING { return "v"; }`, rule: RESERVED_BUILTIN_NAME, count: 0},
+		{name: "testing namespace", input: `table testing {}`, rule: RESERVED_BUILTIN_NAME, count: 0},
+		{name: "contains builtin name", input: `table std_values {}`, rule: RESERVED_BUILTIN_NAME, count: 0},
+		{name: "F_ prefixed backend", input: `backend F_origin {}`, rule: RESERVED_GENERATED_NAME, count: 0},
+		{name: "F_ prefixed director", input: "backend F_origin {}\ndirector F_pool random { { .backend = F_origin; .weight = 1; } }", rule: RESERVED_GENERATED_NAME, count: 0},
+		{name: "F_ prefixed acl", input: `acl F_internal {}`, rule: RESERVED_GENERATED_NAME, severity: INFO, count: 1},
+		{name: "T_ prefixed table", input: `table T_values {}`, rule: RESERVED_GENERATED_NAME, severity: INFO, count: 1},
+		{name: "vcl_ prefixed subroutine", input: `sub vcl_custom {}`, rule: RESERVED_GENERATED_NAME, severity: WARNING, count: 1},
+		{name: "lifecycle subroutine", input: "sub vcl_recv {\n#FASTLY recv\n}", rule: RESERVED_GENERATED_NAME, count: 0},
+		{name: "fastly local variable", input: `sub foo { declare local var.fastly_req_do_shield BOOL; }`, rule: RESERVED_GENERATED_NAME, severity: WARNING, count: 1},
+		{name: "local variable", input: `sub foo { declare local var.req BOOL; }`, rule: RESERVED_BUILTIN_NAME, count: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vcl, err := parser.New(lexer.NewFromString(tt.input)).ParseVCL()
+			if err != nil {
+				t.Errorf("unexpected parser error: %s", err)
+				return
+			}
+			l := New(testConfig)
+			l.Lint(vcl, context.New())
+			var count int
+			for _, e := range l.Errors {
+				if e.Rule != tt.rule {
+					continue
+				}
+				count++
+				if e.Severity != tt.severity {
+					t.Errorf("Severity expects %s but got %s with: %s", tt.severity, e.Severity, e)
+				}
+			}
+			if count != tt.count {
+				t.Errorf("Expect %d %s errors but got %d: %v", tt.count, tt.rule, count, l.Errors)
+			}
+		})
+	}
+
+	t.Run("skip remote snippets", func(t *testing.T) {
+		vcl, err := parser.New(lexer.NewFromString(`table F_origin {}`, lexer.WithFile("Remote.Table:origin"))).ParseVCL()
+		if err != nil {
+			t.Errorf("unexpected parser error: %s", err)
+			return
+		}
+		l := New(testConfig)
+		l.Lint(vcl, context.New())
+		for _, e := range l.Errors {
+			if e.Rule == RESERVED_GENERATED_NAME {
+				t.Errorf("Unexpected lint error for remote snippet: %s", e)
+			}
+		}
+	})
+}
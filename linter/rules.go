@@ -16,6 +16,8 @@ const (
 	BACKEND_DUPLICATED                   = "backend/duplicated"
 	BACKEND_NOTFOUND                     = "backend/notfound"
 	BACKEND_PROBER_CONFIGURATION         = "backend/prober-configuration"
+	BACKEND_TIMEOUT                      = "backend/timeout"
+	BACKEND_RETRY_LATENCY                = "backend/retry-latency"
 	DIRECTOR_SYNTAX                      = "director/syntax"
 	DIRECTOR_DUPLICATED                  = "director/duplicated"
 	DIRECTOR_PROPS_RANDOM                = "director/props-random"
@@ -40,9 +42,22 @@ const (
 	RATECOUNTER_SYNTAX                   = "ratecounter/syntax"
 	RATECOUNTER_DUPLICATED               = "ratecounter/duplicated"
 	RATECOUNTER_NONEMPTY_BLOCK           = "ratecounter/nonempty-block"
+	CONST_DUPLICATED                     = "const/duplicated"
+	MAGIC_NUMBER                         = "const/magic-number"
+	RTIME_MISSING_UNIT                   = "rtime/missing-unit"
+	RTIME_SUSPICIOUS_UNIT                = "rtime/suspicious-unit"
+	SIZE_SUSPICIOUS_LITERAL              = "size/suspicious-literal"
+	STRING_NULL_ESCAPE                   = "string/null-escape"
+	STRING_UNDECODED_ESCAPE              = "string/undecoded-escape"
+	HEADER_COLLECT_REQUIRED              = "header/collect-required"
+	HEADER_CRLF_INJECTION                = "header/crlf-injection"
+	HEADER_DUPLICATE_FRAMING             = "header/duplicate-framing"
+	HEADER_CONFLICTING_FRAMING           = "header/conflicting-framing"
+	HEADER_PROTECTED_EXPOSURE            = "header/protected-exposure"
 	DECLARE_STATEMENT_SYNTAX             = "declare-statement/syntax"
 	DECLARE_STATEMENT_INVALID_TYPE       = "declare-statement/invalid-type"
 	DECLARE_STATEMENT_DUPLICATED         = "declare-statement/duplicated"
+	DECLARE_STATEMENT_CONDITIONAL        = "declare-statement/conditional"
 	SET_STATEMENT_SYNTAX                 = "set-statement/syntax"
 	OPERATOR_ASSIGNMENT                  = "operator/assignment"
 	UNSET_STATEMENT_SYNTAX               = "unset-statement/syntax"
@@ -72,9 +87,16 @@ const (
 	FORBIDDEN_BACKWARD_JUMP              = "goto/forbidden-backward-jump"
 	TIME_CALCULATION                     = "operator/time-calculation"
 	DEPRECATED                           = "deprecated"
+	DEPRECATED_SUNSET                    = "deprecated/sunset"
 	UNCAPTURED_REGEX_VARIABLE            = "regex/uncaptured-variable"
 	OVERWRITE_VARY                       = "set-statement/overwrite-vary"
 	REGEX_URL_EXTENSION                  = "regex/url-extension"
+	RESERVED_BUILTIN_NAME                = "reserved/builtin-name"
+	RESERVED_GENERATED_NAME              = "reserved/generated-name"
+	DIALECT_UNSUPPORTED_SYNTAX           = "dialect/unsupported-syntax"
+	DIALECT_UNSUPPORTED_BUILTIN          = "dialect/unsupported-builtin"
+	DIALECT_VERSION                      = "dialect/version"
+	POLICY_VIOLATION                     = "policy"
 )
 
 var references = map[Rule]string{
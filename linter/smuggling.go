@@ -0,0 +1,190 @@
+package linter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/ysugimoto/falco/v2/ast"
+	"github.com/ysugimoto/falco/v2/linter/context"
+)
+
+// Variable prefixes which hold data that the client controls
+var clientControlledPrefixes = []string{
+	"req.http.", "req.url", "req.body", "bereq.http.", "bereq.url",
+}
+
+// Functions which decode the argument so CR/LF characters could appear in the result, e.g. "%0D%0A" in the URL
+var decodingFunctions = map[string]struct{}{
+	"urldecode":                     {},
+	"digest.base64_decode":          {},
+	"digest.base64url_decode":       {},
+	"digest.base64url_nopad_decode": {},
+}
+
+// Functions which keep CR/LF characters of the first argument in the result.
+// Other functions like regsuball or urlencode are treated as sanitizers
+var passthroughFunctions = map[string]struct{}{
+	"std.tolower":      {},
+	"std.toupper":      {},
+	"std.strrev":       {},
+	"substr":           {},
+	"subfield":         {},
+	"regsub":           {},
+	"querystring.get":  {},
+	"querystring.sort": {},
+}
+
+// Headers which frame the message body, manipulating them could cause request or response smuggling
+var framingHeaderPattern = regexp.MustCompile(`(?i)^(bereq|beresp|resp|obj)\.http\.(content-length|transfer-encoding)$`)
+
+// lintResponseSplitting reports header assignments which could split or smuggle HTTP messages:
+// decoded client data is assigned without removing CR/LF, or message framing headers are duplicated or conflicted.
+// Note that framing headers of req.http are protected and already reported, so they are not checked here
+func (l *Linter) lintResponseSplitting(ident *ast.Ident, value ast.Expression, isAdd bool, ctx *context.Context) {
+	name := ident.Value
+	if !strings.Contains(name, ".http.") {
+		return
+	}
+
+	if isDecodedClientData(value) {
+		l.Error((&LintError{
+			Severity: ERROR,
+			Token:    value.GetMeta().Token,
+			Message: fmt.Sprintf(
+				"Decoded client data is assigned to %s, CR/LF characters in the value may split the HTTP message. "+
+					`Remove them like regsuball(value, "[\r\n]", "") before assigning`,
+				name,
+			),
+		}).Match(HEADER_CRLF_INJECTION))
+	}
+
+	match := framingHeaderPattern.FindStringSubmatch(name)
+	if match == nil {
+		return
+	}
+	if isAdd {
+		l.Error((&LintError{
+			Severity: ERROR,
+			Token:    ident.GetMeta().Token,
+			Message: fmt.Sprintf(
+				"Add statement creates duplicate %s header which may lead to request or response smuggling",
+				match[2],
+			),
+		}).Match(HEADER_DUPLICATE_FRAMING))
+	}
+	if containsClientData(value) {
+		l.Error((&LintError{
+			Severity: ERROR,
+			Token:    value.GetMeta().Token,
+			Message: fmt.Sprintf(
+				"%s is built from client data, the client could control message framing of %s",
+				name, match[1],
+			),
+		}).Match(HEADER_CONFLICTING_FRAMING))
+	}
+
+	// Content-Length and Transfer-Encoding must not be manipulated together for the same message.
+	// Report on Content-Length assignment in order to report the conflict once
+	if ctx.CurrentSubroutine == nil || !strings.EqualFold(match[2], "content-length") {
+		return
+	}
+	for _, assigned := range collectHeaderAssignments(ctx.CurrentSubroutine.Block.Statements) {
+		if strings.EqualFold(assigned, match[1]+".http.transfer-encoding") {
+			l.Error((&LintError{
+				Severity: ERROR,
+				Token:    ident.GetMeta().Token,
+				Message: fmt.Sprintf(
+					"Both Content-Length and Transfer-Encoding of %s are manipulated in subroutine %s, conflicting framing may lead to smuggling",
+					match[1], ctx.CurrentSubroutine.Name.Value,
+				),
+			}).Match(HEADER_CONFLICTING_FRAMING))
+			return
+		}
+	}
+}
+
+// Collect variable names which are set or added in the statements recursively
+func collectHeaderAssignments(statements []ast.Statement) []string {
+	var names []string
+	for _, stmt := range statements {
+		switch t := stmt.(type) {
+		case *ast.SetStatement:
+			names = append(names, t.Ident.Value)
+		case *ast.AddStatement:
+			names = append(names, t.Ident.Value)
+		case *ast.BlockStatement:
+			names = append(names, collectHeaderAssignments(t.Statements)...)
+		case *ast.IfStatement:
+			names = append(names, collectHeaderAssignments(t.Consequence.Statements)...)
+			for _, another := range t.Another {
+				names = append(names, collectHeaderAssignments(another.Consequence.Statements)...)
+			}
+			if t.Alternative != nil {
+				names = append(names, collectHeaderAssignments(t.Alternative.Consequence.Statements)...)
+			}
+		case *ast.SwitchStatement:
+			for _, c := range t.Cases {
+				names = append(names, collectHeaderAssignments(c.Statements)...)
+			}
+		}
+	}
+	return names
+}
+
+func isClientControlledVariable(name string) bool {
+	for _, prefix := range clientControlledPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsClientData returns true if the expression refers client controlled variables
+func containsClientData(expr ast.Expression) bool {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return isClientControlledVariable(t.Value)
+	case *ast.InfixExpression:
+		return containsClientData(t.Left) || containsClientData(t.Right)
+	case *ast.PrefixExpression:
+		return containsClientData(t.Right)
+	case *ast.GroupedExpression:
+		return containsClientData(t.Right)
+	case *ast.IfExpression:
+		return containsClientData(t.Consequence) || containsClientData(t.Alternative)
+	case *ast.FunctionCallExpression:
+		for _, arg := range t.Arguments {
+			if containsClientData(arg) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isDecodedClientData returns true if the expression has client data which is decoded and not sanitized
+func isDecodedClientData(expr ast.Expression) bool {
+	switch t := expr.(type) {
+	case *ast.InfixExpression:
+		return isDecodedClientData(t.Left) || isDecodedClientData(t.Right)
+	case *ast.PrefixExpression:
+		return isDecodedClientData(t.Right)
+	case *ast.GroupedExpression:
+		return isDecodedClientData(t.Right)
+	case *ast.IfExpression:
+		return isDecodedClientData(t.Consequence) || isDecodedClientData(t.Alternative)
+	case *ast.FunctionCallExpression:
+		if len(t.Arguments) == 0 {
+			return false
+		}
+		if _, ok := decodingFunctions[t.Function.Value]; ok {
+			return containsClientData(t.Arguments[0]) || isDecodedClientData(t.Arguments[0])
+		}
+		if _, ok := passthroughFunctions[t.Function.Value]; ok {
+			return isDecodedClientData(t.Arguments[0])
+		}
+	}
+	return false
+}
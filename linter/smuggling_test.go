@@ -0,0 +1,106 @@
+package linter
+
+import (
+	"testing"
+
+	"github.com/ysugimoto/falco/v2/lexer"
+	"github.com/ysugimoto/falco/v2/linter/context"
+	"github.com/ysugimoto/falco/v2/parser"
+)
+
+func TestLintResponseSplitting(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		rule  Rule
+		count int
+	}{
+		{
+			name:  "decoded url is assigned to header",
+			input: `sub vcl_deliver { set resp.http.X-Path = urldecode(req.url); }`,
+			rule:  HEADER_CRLF_INJECTION,
+			count: 1,
+		},
+		{
+			name:  "decoded header is concatenated",
+			input: `sub vcl_recv { set req.http.X-Token = "Bearer " std.tolower(digest.base64_decode(req.http.Authorization)); }`,
+			rule:  HEADER_CRLF_INJECTION,
+			count: 1,
+		},
+		{
+			name:  "decoded value is sanitized",
+			input: `sub vcl_deliver { set resp.http.X-Path = regsuball(urldecode(req.url), "[\r\n]", ""); }`,
+			rule:  HEADER_CRLF_INJECTION,
+			count: 0,
+		},
+		{
+			name:  "raw client data is assigned",
+			input: `sub vcl_deliver { set resp.http.X-Path = req.url; }`,
+			rule:  HEADER_CRLF_INJECTION,
+			count: 0,
+		},
+		{
+			name:  "add Content-Length",
+			input: `sub vcl_fetch { add beresp.http.Content-Length = "1"; }`,
+			rule:  HEADER_DUPLICATE_FRAMING,
+			count: 1,
+		},
+		{
+			name:  "add Transfer-Encoding",
+			input: `sub vcl_deliver { add resp.http.transfer-encoding = "chunked"; }`,
+			rule:  HEADER_DUPLICATE_FRAMING,
+			count: 1,
+		},
+		{
+			name:  "Content-Length from client data",
+			input: `sub vcl_miss { set bereq.http.Content-Length = req.http.X-Length; }`,
+			rule:  HEADER_CONFLICTING_FRAMING,
+			count: 1,
+		},
+		{
+			name: "Content-Length and Transfer-Encoding are set",
+			input: `sub vcl_deliver {
+	set resp.http.Content-Length = "10";
+	if (req.http.X-Chunked) {
+		set resp.http.Transfer-Encoding = "chunked";
+	}
+}`,
+			rule:  HEADER_CONFLICTING_FRAMING,
+			count: 1,
+		},
+		{
+			name: "Content-Length and Transfer-Encoding of another message",
+			input: `sub vcl_miss {
+	set bereq.http.Content-Length = "10";
+	unset bereq.http.Transfer-Encoding;
+}`,
+			rule:  HEADER_CONFLICTING_FRAMING,
+			count: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vcl, err := parser.New(lexer.NewFromString(tt.input)).ParseVCL()
+			if err != nil {
+				t.Errorf("unexpected parser error: %s", err)
+				return
+			}
+			l := New(testConfig)
+			l.Lint(vcl, context.New())
+			var count int
+			for _, e := range l.Errors {
+				if e.Rule != tt.rule {
+					continue
+				}
+				count++
+				if e.Severity != ERROR {
+					t.Errorf("Severity expects ERROR but got %s with: %s", e.Severity, e)
+				}
+			}
+			if count != tt.count {
+				t.Errorf("Expect %d %s errors but got %d: %v", tt.count, tt.rule, count, l.Errors)
+			}
+		})
+	}
+}
@@ -2,6 +2,8 @@ package linter
 
 import (
 	"fmt"
+	"maps"
+	"slices"
 	"strings"
 
 	"github.com/ysugimoto/falco/v2/ast"
@@ -84,6 +86,7 @@ func (l *Linter) lintDeclareStatement(stmt *ast.DeclareStatement, ctx *context.C
 	if !isValidVariableName(stmt.Name.Value) {
 		l.Error(InvalidName(stmt.Name.GetMeta(), stmt.Name.Value, "declare local").Match(DECLARE_STATEMENT_SYNTAX))
 	}
+	l.lintReservedName(stmt.Name, "declare local", ctx)
 	// user defined variable must start with "var."
 	if !strings.HasPrefix(stmt.Name.Value, "var.") {
 		err := &LintError{
@@ -156,12 +159,11 @@ func (l *Linter) lintSetStatement(stmt *ast.SetStatement, ctx *context.Context)
 
 	left, err := ctx.Set(stmt.Ident.Value)
 	if err != nil {
-		err := &LintError{
-			Severity: ERROR,
-			Token:    stmt.Ident.GetMeta().Token,
-			Message:  err.Error(),
-		}
-		l.Error(err)
+		l.Error(variableError(stmt.Ident, err, ctx))
+	} else if strings.HasPrefix(stmt.Ident.Value, "var.") && !ctx.IsDeclaredLocal(stmt.Ident.Value) {
+		l.Error(ConditionalDeclaration(stmt.Ident.GetMeta(), stmt.Ident.Value).Match(DECLARE_STATEMENT_CONDITIONAL))
+	} else {
+		l.lintDeprecation("Variable", stmt.Ident.Value, stmt.Ident.GetMeta(), false)
 	}
 
 	if err := isValidStatementExpression(left, stmt.Value); err != nil {
@@ -174,6 +176,8 @@ func (l *Linter) lintSetStatement(stmt *ast.SetStatement, ctx *context.Context)
 	}
 
 	right := l.lint(stmt.Value, ctx)
+	l.lintResponseSplitting(stmt.Ident, stmt.Value, false, ctx)
+	l.lintProtectedHeaderAssignment(stmt.Ident, stmt.Value)
 
 	// Fastly has various assignment operators and required correspond types for each operator
 	// https://developer.fastly.com/reference/vcl/operators/#assignment-operators
@@ -193,6 +197,17 @@ func (l *Linter) lintSetStatement(stmt *ast.SetStatement, ctx *context.Context)
 	case "||=", "&&=":
 		l.lintLogicalOperator(stmt.Operator, left, right)
 	default: // "="
+		if left == types.RTimeType && expectType(right, types.IntegerType, types.FloatType) && isLiteralExpression(stmt.Value) {
+			l.Error(MissingDurationUnit(
+				stmt.Value.GetMeta(), stmt.Ident.Value, stmt.Value.GetMeta().Token.Literal,
+			).Match(RTIME_MISSING_UNIT))
+			break
+		}
+		if v, ok := stmt.Value.(*ast.RTime); ok && left == types.RTimeType {
+			if suggest, ok := suggestDurationUnit(stmt.Ident.Value, v.Value); ok {
+				l.Error(SuspiciousDurationUnit(v.GetMeta(), stmt.Ident.Value, v.Value, suggest).Match(RTIME_SUSPICIOUS_UNIT))
+			}
+		}
 		l.lintAssignOperator(stmt.Operator, stmt.Ident.Value, left, right, isLiteralExpression(stmt.Value))
 	}
 
@@ -210,11 +225,7 @@ func (l *Linter) lintUnsetStatement(stmt *ast.UnsetStatement, ctx *context.Conte
 	}
 
 	if err := ctx.Unset(stmt.Ident.Value); err != nil {
-		l.Error(&LintError{
-			Severity: ERROR,
-			Token:    stmt.Ident.GetMeta().Token,
-			Message:  err.Error(),
-		})
+		l.Error(variableError(stmt.Ident, err, ctx))
 	}
 
 	return types.NeverType
@@ -231,27 +242,41 @@ func (l *Linter) lintRemoveStatement(stmt *ast.RemoveStatement, ctx *context.Con
 	}
 
 	if err := ctx.Unset(stmt.Ident.Value); err != nil {
-		l.Error(&LintError{
-			Severity: ERROR,
-			Token:    stmt.Ident.GetMeta().Token,
-			Message:  err.Error(),
-		})
+		l.Error(variableError(stmt.Ident, err, ctx))
 	}
 
 	return types.NeverType
 }
 
 func (l *Linter) lintIfStatement(stmt *ast.IfStatement, ctx *context.Context) types.Type {
+	// Local variables declared in a branch are available after the statement
+	// only when all of reachable branches declare them
+	before := ctx.CopyDeclaredLocals()
+	var branches []map[string]struct{}
+	lintBranch := func(block *ast.BlockStatement) {
+		l.lint(block, ctx)
+		if !isTerminatedStatements(block.Statements) {
+			branches = append(branches, ctx.DeclaredLocals)
+		}
+		ctx.DeclaredLocals = maps.Clone(before)
+	}
+
 	l.lintIfCondition(stmt.Condition, ctx)
-	l.lint(stmt.Consequence, ctx)
+	lintBranch(stmt.Consequence)
 
 	for _, a := range stmt.Another {
 		l.lintIfCondition(a.Condition, ctx)
-		l.lint(a.Consequence, ctx)
+		lintBranch(a.Consequence)
 	}
 
 	if stmt.Alternative != nil {
-		l.lint(stmt.Alternative.Consequence, ctx)
+		lintBranch(stmt.Alternative.Consequence)
+	} else {
+		branches = append(branches, before)
+	}
+
+	if len(branches) > 0 {
+		ctx.DeclaredLocals = intersectDeclaredLocals(branches)
 	}
 
 	return types.NeverType
@@ -265,7 +290,7 @@ func (l *Linter) lintIfCondition(cond ast.Expression, ctx *context.Context) {
 	// if ("foobar") { ... }                       // -> invalid, string literal in condition expression could not use
 	// if (req.http.Host == "example.com") { ... } // -> valid, left expression is identity
 	// if ("example.com" == req.http.Host) { ... } // -> invalid(!), left expression is string literal... messy X(
-	if err := isValidConditionExpression(cond); err != nil {
+	if err := isValidConditionExpression(cond, ctx.Constants); err != nil {
 		err := &LintError{
 			Severity: ERROR,
 			Token:    cond.GetMeta().Token,
@@ -292,11 +317,7 @@ func (l *Linter) lintSwitchStatement(stmt *ast.SwitchStatement, ctx *context.Con
 	if c, ok := stmt.Control.Expression.(*ast.FunctionCallExpression); ok {
 		fn, err := ctx.GetFunction(c.Function.Value)
 		if err != nil {
-			l.Error(&LintError{
-				Severity: ERROR,
-				Token:    c.Function.Token,
-				Message:  err.Error(),
-			})
+			l.Error(functionError(c.Function, err, ctx))
 		} else if fn.IsUserDefinedFunction && !expectType(fn.Return, types.StringType) {
 			// Fastly VCL only permits user defined functions that return STRING in a
 			// switch control. Built-in function return values will be coerced into a
@@ -309,7 +330,15 @@ func (l *Linter) lintSwitchStatement(stmt *ast.SwitchStatement, ctx *context.Con
 		}
 	}
 
+	// Each case could be entered from the switch control directly,
+	// so that lint declared local variables like if statement branches
+	before := ctx.CopyDeclaredLocals()
+	var branches []map[string]struct{}
+	var hasDefault bool
 	for _, c := range stmt.Cases {
+		if c.Test == nil {
+			hasDefault = true
+		}
 		for _, s := range c.Statements {
 			switch s.(type) {
 			case *ast.BreakStatement, *ast.FallthroughStatement:
@@ -318,6 +347,21 @@ func (l *Linter) lintSwitchStatement(stmt *ast.SwitchStatement, ctx *context.Con
 				l.lint(s, ctx)
 			}
 		}
+		// Case statements end with break or fallthrough, check the statement before it
+		body := c.Statements
+		if len(body) > 0 {
+			body = body[:len(body)-1]
+		}
+		if !c.Fallthrough && !isTerminatedStatements(body) {
+			branches = append(branches, ctx.DeclaredLocals)
+		}
+		ctx.DeclaredLocals = maps.Clone(before)
+	}
+	if !hasDefault {
+		branches = append(branches, before)
+	}
+	if len(branches) > 0 {
+		ctx.DeclaredLocals = intersectDeclaredLocals(branches)
 	}
 
 	return types.NeverType
@@ -371,9 +415,7 @@ func (l *Linter) lintAddStatement(stmt *ast.AddStatement, ctx *context.Context)
 	left, err := ctx.Get(stmt.Ident.Value)
 	if err != nil {
 		if err == context.ErrDeprecated {
-			l.Error(DeprecatedVariable(
-				stmt.Ident.Value, stmt.Ident.GetMeta(),
-			).Match(DEPRECATED))
+			l.lintDeprecation("Variable", stmt.Ident.Value, stmt.Ident.GetMeta(), true)
 		} else {
 			l.Error(&LintError{
 				Severity: ERROR,
@@ -393,6 +435,8 @@ func (l *Linter) lintAddStatement(stmt *ast.AddStatement, ctx *context.Context)
 	}
 
 	right := l.lint(stmt.Value, ctx)
+	l.lintResponseSplitting(stmt.Ident, stmt.Value, true, ctx)
+	l.lintProtectedHeaderAssignment(stmt.Ident, stmt.Value)
 
 	// Commonly, add statement operator must be "="
 	if stmt.Operator.Operator != "=" {
@@ -412,7 +456,11 @@ func (l *Linter) lintCallStatement(stmt *ast.CallStatement, ctx *context.Context
 	// Note that this linter analyze up to down,
 	// so all call target subroutine must be defined before call it.
 	if s, ok := ctx.Subroutines[stmt.Subroutine.Value]; !ok {
-		l.Error(UndefinedSubroutine(stmt.GetMeta(), stmt.Subroutine.Value).Match(CALL_STATEMENT_SUBROUTINE_NOTFOUND))
+		l.Error(
+			UndefinedSubroutine(stmt.GetMeta(), stmt.Subroutine.Value).
+				Suggest(stmt.Subroutine.Value, slices.Collect(maps.Keys(ctx.Subroutines))).
+				Match(CALL_STATEMENT_SUBROUTINE_NOTFOUND),
+		)
 	} else {
 		s.IsUsed = true
 
@@ -483,6 +531,8 @@ func (l *Linter) lintErrorStatement(stmt *ast.ErrorStatement, ctx *context.Conte
 		code := l.lint(t, ctx)
 		l.Error(InvalidType(t.GetMeta(), "error code", types.IntegerType, code))
 	}
+	// Error response message is sent to clients
+	l.lintProtectedHeaderValue(stmt.Argument, "clients via error response")
 
 	return types.NeverType
 }
@@ -503,6 +553,7 @@ func (l *Linter) lintLogStatement(stmt *ast.LogStatement, ctx *context.Context)
 	}
 
 	l.lint(stmt.Value, ctx)
+	l.lintProtectedHeaderValue(stmt.Value, "logs")
 	return types.NeverType
 }
 
@@ -625,6 +676,7 @@ func (l *Linter) lintSyntheticStatement(stmt *ast.SyntheticStatement, ctx *conte
 	}
 
 	l.lint(stmt.Value, ctx)
+	l.lintProtectedHeaderValue(stmt.Value, "clients via synthetic response")
 	return types.NeverType
 }
 
@@ -634,7 +686,7 @@ func (l *Linter) lintIdent(exp *ast.Ident, ctx *context.Context) types.Type {
 		switch err {
 		case context.ErrDeprecated:
 			// If error is deprecation error, report error but return value type
-			l.Error(DeprecatedVariable(exp.Value, exp.GetMeta()))
+			l.lintDeprecation("Variable", exp.Value, exp.GetMeta(), true)
 			return v
 		case context.ErrUncapturedRegexVariable:
 			// If error is uncaptured regex variable error, report error as WARNING severity
@@ -672,16 +724,38 @@ func (l *Linter) lintIdent(exp *ast.Ident, ctx *context.Context) types.Type {
 			rc.IsUsed = true
 			// Fastly treats these variables as type IDs
 			return types.IDType
+		} else if c, ok := ctx.Constants[exp.Value]; ok {
+			// mark constant is used
+			c.IsUsed = true
+			return c.ValueType
 		} else if _, ok := ctx.Identifiers[exp.Value]; ok {
 			return types.IDType
 		}
 
-		// Convert to lint error
-		l.Error(&LintError{
+		// Convert to lint error, suggest closest names if the variable is unknown
+		e := &LintError{
 			Severity: ERROR,
 			Token:    exp.GetMeta().Token,
 			Message:  err.Error(),
-		})
+		}
+		if _, ok := ctx.PredefinedVariable(exp.Value); !ok {
+			e = e.Suggest(exp.Value, append(ctx.VariableNames(), ctx.DeclarationNames()...))
+		}
+		l.Error(e)
+		return v
+	}
+
+	if strings.HasPrefix(exp.Value, "var.") && !ctx.IsDeclaredLocal(exp.Value) {
+		l.Error(ConditionalDeclaration(exp.GetMeta(), exp.Value).Match(DECLARE_STATEMENT_CONDITIONAL))
+	}
+	l.lintDeprecation("Variable", exp.Value, exp.GetMeta(), false)
+
+	// Client may send the same request header multiple times (e.g. Cookie on HTTP/2)
+	// but subfield access only reads the first field unless std.collect is called
+	if header, _, found := strings.Cut(exp.Value, ":"); found && strings.HasPrefix(header, "req.http.") {
+		if _, ok := ctx.CollectedHeaders[strings.ToLower(header)]; !ok {
+			l.Error(HeaderCollectRequired(exp.GetMeta(), exp.Value).Match(HEADER_COLLECT_REQUIRED))
+		}
 	}
 	return v
 }
@@ -699,6 +773,7 @@ func (l *Linter) lintSyntheticBase64Statement(stmt *ast.SyntheticBase64Statement
 
 	// TODO: check decodable string
 	l.lint(stmt.Value, ctx)
+	l.lintProtectedHeaderValue(stmt.Value, "clients via synthetic response")
 
 	return types.NeverType
 }
@@ -706,13 +781,10 @@ func (l *Linter) lintSyntheticBase64Statement(stmt *ast.SyntheticBase64Statement
 func (l *Linter) lintFunctionCallStatement(exp *ast.FunctionCallStatement, ctx *context.Context) types.Type {
 	fn, err := ctx.GetFunction(exp.Function.Value)
 	if err != nil {
-		l.Error(&LintError{
-			Severity: ERROR,
-			Token:    exp.Function.GetMeta().Token,
-			Message:  err.Error(),
-		})
+		l.Error(functionError(exp.Function, err, ctx))
 		return types.NeverType
 	}
+	l.lintDeprecation("Function", exp.Function.Value, exp.Function.GetMeta(), false)
 
 	if fn.Return != types.NeverType {
 		l.Error(&LintError{
@@ -725,7 +797,8 @@ func (l *Linter) lintFunctionCallStatement(exp *ast.FunctionCallStatement, ctx *
 
 	// testing.call_subroutine has variadic extra arguments whose types depend
 	// on the target subroutine's parameter list. Validate it separately.
-	if exp.Function.Value == context.TestingCallSubroutineName {
+	switch exp.Function.Value {
+	case context.TestingCallSubroutineName, context.TestingCallSubroutineInScopeName:
 		return l.lintTestingCallSubroutine(exp.Function, exp.Arguments, ctx)
 	}
 
@@ -738,7 +811,8 @@ func (l *Linter) lintFunctionCallStatement(exp *ast.FunctionCallStatement, ctx *
 }
 
 // lintTestingCallSubroutine applies dedicated validation for
-// testing.call_subroutine(name STRING, arg1, arg2, ...).
+// testing.call_subroutine(name STRING, [scope STRING,] arg1, arg2, ...) and
+// testing.call_subroutine_in_scope(name STRING, scope STRING, arg1, arg2, ...).
 func (l *Linter) lintTestingCallSubroutine(
 	fn *ast.Ident,
 	args []ast.Expression,
@@ -754,7 +828,7 @@ func (l *Linter) lintTestingCallSubroutine(
 			Token:    tok,
 			Message: fmt.Sprintf(
 				"function %s requires at least one STRING argument (subroutine name)",
-				context.TestingCallSubroutineName,
+				fn.Value,
 			),
 		})
 		return types.NeverType
@@ -768,7 +842,7 @@ func (l *Linter) lintTestingCallSubroutine(
 			Token:    args[0].GetMeta().Token,
 			Message: fmt.Sprintf(
 				"function %s: first argument must be STRING (subroutine name), got %s",
-				context.TestingCallSubroutineName, firstType,
+				fn.Value, firstType,
 			),
 		})
 	}
@@ -788,7 +862,7 @@ func (l *Linter) lintTestingCallSubroutine(
 			Token:    args[0].GetMeta().Token,
 			Message: fmt.Sprintf(
 				"function %s: subroutine %q is not defined",
-				context.TestingCallSubroutineName, subName,
+				fn.Value, subName,
 			),
 		})
 		return types.NeverType
@@ -796,6 +870,36 @@ func (l *Linter) lintTestingCallSubroutine(
 
 	params := sub.Decl.Parameters
 	extraArgs := args[1:]
+	// Position of the first subroutine argument, used in error messages
+	position := 2
+
+	// Scope name is the required second argument of testing.call_subroutine_in_scope,
+	// e.g. testing.call_subroutine_in_scope("normalize_path", "RECV").
+	// testing.call_subroutine also accepts it when one more argument than the parameters is passed,
+	// e.g. testing.call_subroutine("normalize_path", "RECV").
+	if fn.Value == context.TestingCallSubroutineInScopeName || len(extraArgs) == len(params)+1 {
+		if len(extraArgs) == 0 {
+			l.Error(&LintError{
+				Severity: ERROR,
+				Token:    tok,
+				Message:  fmt.Sprintf("function %s requires scope name as second argument", fn.Value),
+			})
+			return types.NeverType
+		}
+		if s, ok := extraArgs[0].(*ast.String); !ok || annotationToScope(s.Value) == 0 {
+			l.Error(&LintError{
+				Severity: ERROR,
+				Token:    extraArgs[0].GetMeta().Token,
+				Message: fmt.Sprintf(
+					"function %s: second argument must be a STRING literal of scope name like \"RECV\"",
+					fn.Value,
+				),
+			})
+			return types.NeverType
+		}
+		extraArgs = extraArgs[1:]
+		position++
+	}
 
 	if len(extraArgs) != len(params) {
 		l.Error(&LintError{
@@ -803,7 +907,7 @@ func (l *Linter) lintTestingCallSubroutine(
 			Token:    tok,
 			Message: fmt.Sprintf(
 				"function %s: subroutine %q expects %d argument(s), got %d",
-				context.TestingCallSubroutineName, subName, len(params), len(extraArgs),
+				fn.Value, subName, len(params), len(extraArgs),
 			),
 		})
 		return types.NeverType
@@ -825,7 +929,7 @@ func (l *Linter) lintTestingCallSubroutine(
 					Message: fmt.Sprintf(
 						"function %s: argument %d type mismatch: "+
 							"subroutine %q parameter %q expects %s, got %s",
-						context.TestingCallSubroutineName, i+2,
+						fn.Value, i+position,
 						subName, param.Name.Value, expectedType, actualType,
 					),
 				})
@@ -837,7 +941,7 @@ func (l *Linter) lintTestingCallSubroutine(
 				Message: fmt.Sprintf(
 					"function %s: argument %d type mismatch: "+
 						"subroutine %q parameter %q expects %s, got %s",
-					context.TestingCallSubroutineName, i+2,
+					fn.Value, i+position,
 					subName, param.Name.Value, expectedType, actualType,
 				),
 			})
@@ -991,4 +991,222 @@ sub test_x {
 }`
 		assertError(t, input)
 	})
+
+	t.Run("scope argument before subroutine arguments", func(t *testing.T) {
+		input := `
+// @scope: fetch
+sub takes_int(INTEGER var.n) STRING {
+  return std.itoa(var.n);
+}
+// @scope: recv
+sub test_x {
+  declare local var.label STRING;
+  set var.label = testing.call_subroutine_in_scope("takes_int", "FETCH", 1);
+}`
+		assertNoError(t, input)
+	})
+
+	t.Run("scope argument by the number of arguments", func(t *testing.T) {
+		input := `
+// @scope: recv
+sub normalize_path {
+  set req.url = "/";
+}
+// @scope: recv
+sub takes_string(STRING var.s) STRING {
+  return var.s;
+}
+// @scope: recv
+sub test_x {
+  declare local var.label STRING;
+  testing.call_subroutine("normalize_path", "RECV");
+  set var.label = testing.call_subroutine("takes_string", "RECV", "x");
+  set var.label = testing.call_subroutine("takes_string", "RECV");
+}`
+		assertNoError(t, input)
+	})
+
+	t.Run("extra argument is not a scope", func(t *testing.T) {
+		input := `
+// @scope: recv
+sub takes_string(STRING var.s) STRING {
+  return var.s;
+}
+// @scope: recv
+sub test_x {
+  declare local var.label STRING;
+  set var.label = testing.call_subroutine("takes_string", "x", "RECV");
+}`
+		assertError(t, input)
+	})
+
+	t.Run("missing scope argument", func(t *testing.T) {
+		input := `
+// @scope: recv
+sub normalize_path {
+  set req.url = "/";
+}
+// @scope: recv
+sub test_x {
+  testing.call_subroutine_in_scope("normalize_path");
+}`
+		assertError(t, input)
+	})
+
+	t.Run("invalid scope argument", func(t *testing.T) {
+		input := `
+// @scope: recv
+sub normalize_path {
+  set req.url = "/";
+}
+// @scope: recv
+sub test_x {
+  testing.call_subroutine_in_scope("normalize_path", "UNKNOWN");
+}`
+		assertError(t, input)
+	})
+}
+
+func TestLintUnitLiteral(t *testing.T) {
+	tests := []struct {
+		scope string
+		input string
+		rule  Rule
+		count int
+	}{
+		{scope: "fetch", input: `set beresp.ttl = 3600;`, rule: RTIME_MISSING_UNIT, count: 1},
+		{scope: "fetch", input: `set beresp.ttl = 1.5;`, rule: RTIME_MISSING_UNIT, count: 1},
+		{scope: "fetch", input: `set beresp.ttl = 3600s;`, rule: RTIME_MISSING_UNIT, count: 0},
+		{scope: "fetch", input: `set beresp.ttl = std.atoi("3600");`, rule: RTIME_MISSING_UNIT, count: 0},
+		{scope: "fetch", input: `set beresp.ttl = 3600ms;`, rule: RTIME_SUSPICIOUS_UNIT, count: 1},
+		{scope: "fetch", input: `set beresp.stale_if_error = 86400ms;`, rule: RTIME_SUSPICIOUS_UNIT, count: 1},
+		{scope: "fetch", input: `set beresp.ttl = 3600s;`, rule: RTIME_SUSPICIOUS_UNIT, count: 0},
+		{scope: "miss", input: `set bereq.first_byte_timeout = 15000s;`, rule: RTIME_SUSPICIOUS_UNIT, count: 1},
+		{scope: "miss", input: `set bereq.first_byte_timeout = 15s;`, rule: RTIME_SUSPICIOUS_UNIT, count: 0},
+		{scope: "miss", input: `set bereq.first_byte_timeout = 15000ms;`, rule: RTIME_SUSPICIOUS_UNIT, count: 0},
+		{scope: "deliver", input: `if (req.body_bytes_read > 10) {}`, rule: SIZE_SUSPICIOUS_LITERAL, count: 1},
+		{scope: "deliver", input: `if (req.body_bytes_read > 10s) {}`, rule: SIZE_SUSPICIOUS_LITERAL, count: 1},
+		{scope: "deliver", input: `if (10 < req.body_bytes_read) {}`, rule: SIZE_SUSPICIOUS_LITERAL, count: 1},
+		{scope: "deliver", input: `if (req.body_bytes_read > 0) {}`, rule: SIZE_SUSPICIOUS_LITERAL, count: 0},
+		{scope: "deliver", input: `if (req.body_bytes_read > 10485760) {}`, rule: SIZE_SUSPICIOUS_LITERAL, count: 0},
+	}
+
+	for _, tt := range tests {
+		vcl, err := parser.New(lexer.NewFromString(`
+sub vcl_` + tt.scope + ` {
+	#FASTLY ` + strings.ToUpper(tt.scope) + `
+	` + tt.input + `
+}`)).ParseVCL()
+		if err != nil {
+			t.Errorf("unexpected parser error: %s", err)
+			continue
+		}
+		l := New(testConfig)
+		l.lint(vcl, context.New())
+		var count int
+		for _, e := range l.Errors {
+			if e.Rule == tt.rule {
+				count++
+			}
+		}
+		if count != tt.count {
+			t.Errorf("%s: expect %d %s errors but got %d", tt.input, tt.count, tt.rule, count)
+		}
+	}
+}
+
+func TestLintHeaderCollect(t *testing.T) {
+	tests := []struct {
+		input string
+		count int
+	}{
+		{input: `set req.http.Foo = req.http.Cookie:session;`, count: 1},
+		{input: `std.collect(req.http.Cookie, ";"); set req.http.Foo = req.http.Cookie:session;`, count: 0},
+		{input: `std.collect(req.http.cookie, ";"); set req.http.Foo = req.http.Cookie:session;`, count: 0},
+		{input: `std.collect(req.http.Accept); set req.http.Foo = req.http.Cookie:session;`, count: 1},
+		{input: `set req.http.Foo = req.http.Cookie;`, count: 0},
+		{input: `set req.http.Cookie:session = "foo";`, count: 0},
+	}
+
+	for _, tt := range tests {
+		vcl, err := parser.New(lexer.NewFromString(`
+sub vcl_recv {
+	#FASTLY RECV
+	` + tt.input + `
+}`)).ParseVCL()
+		if err != nil {
+			t.Errorf("unexpected parser error: %s", err)
+			continue
+		}
+		l := New(testConfig)
+		l.lint(vcl, context.New())
+		var count int
+		for _, e := range l.Errors {
+			if e.Rule == HEADER_COLLECT_REQUIRED {
+				count++
+			}
+		}
+		if count != tt.count {
+			t.Errorf("%s: expect %d %s errors but got %d", tt.input, tt.count, HEADER_COLLECT_REQUIRED, count)
+		}
+	}
+}
+
+func TestLintConditionalDeclaration(t *testing.T) {
+	tests := []struct {
+		input string
+		count int
+	}{
+		{
+			input: `declare local var.S STRING; if (req.http.Foo) { set var.S = "foo"; } set req.http.Bar = var.S;`,
+			count: 0,
+		},
+		{
+			input: `if (req.http.Foo) { declare local var.S STRING; set var.S = "foo"; } set req.http.Bar = var.S;`,
+			count: 1,
+		},
+		{
+			input: `if (req.http.Foo) { declare local var.S STRING; } set var.S = "foo";`,
+			count: 1,
+		},
+		{
+			input: `if (req.http.Foo) { declare local var.S STRING; } else { error 404; } set var.S = "foo";`,
+			count: 0,
+		},
+		{
+			input: `if (req.http.Foo) { declare local var.S STRING; } else if (req.http.Bar) { return(pass); } set var.S = "foo";`,
+			count: 1,
+		},
+		{
+			input: `switch (req.http.Foo) { case "a": declare local var.S STRING; break; default: error 404; break; } set var.S = "foo";`,
+			count: 0,
+		},
+		{
+			input: `switch (req.http.Foo) { case "a": declare local var.S STRING; break; } set var.S = "foo";`,
+			count: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		vcl, err := parser.New(lexer.NewFromString(`
+sub vcl_recv {
+	#FASTLY RECV
+	` + tt.input + `
+}`)).ParseVCL()
+		if err != nil {
+			t.Errorf("unexpected parser error: %s", err)
+			continue
+		}
+		l := New(testConfig)
+		l.lint(vcl, context.New())
+		var count int
+		for _, e := range l.Errors {
+			if e.Rule == DECLARE_STATEMENT_CONDITIONAL {
+				count++
+			}
+		}
+		if count != tt.count {
+			t.Errorf("%s: expect %d %s errors but got %d", tt.input, tt.count, DECLARE_STATEMENT_CONDITIONAL, count)
+		}
+	}
 }
@@ -279,6 +279,16 @@ func (rc *Ratecounter) Type() Type         { return RatecounterType }
 func (rc *Ratecounter) Token() token.Token { return rc.Decl.Token }
 func (rc *Ratecounter) String() string     { return rc.Decl.String() }
 
+type Const struct {
+	Decl      *ast.ConstDeclaration
+	ValueType Type
+	IsUsed    bool // mark this constant is referenced at least once
+}
+
+func (c *Const) Type() Type         { return c.ValueType }
+func (c *Const) Token() token.Token { return c.Decl.Token }
+func (c *Const) String() string     { return c.Decl.String() }
+
 type Goto struct {
 	Decl   *ast.GotoStatement
 	IsUsed bool // mark this goto is called at least once
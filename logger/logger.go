@@ -0,0 +1,82 @@
+package logger
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Supported log formats
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Special output names which are not treated as file path
+const (
+	OutputStderr = "stderr"
+	OutputStdout = "stdout"
+)
+
+type nopCloser struct{}
+
+func (nopCloser) Close() error { return nil }
+
+// New creates leveled structured logger for falco's own operational logs.
+// Output accepts "stderr", "stdout" or file path, the file is opened in append mode.
+// Returned io.Closer must be closed after the logger is no longer used
+func New(format, level, output string) (*slog.Logger, io.Closer, error) {
+	lv, err := ParseLevel(level)
+	if err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+
+	var w io.Writer
+	var closer io.Closer = nopCloser{}
+	switch output {
+	case "", OutputStderr:
+		w = os.Stderr
+	case OutputStdout:
+		w = os.Stdout
+	default:
+		fp, err := os.OpenFile(output, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, nil, errors.WithStack(err)
+		}
+		w, closer = fp, fp
+	}
+
+	handler, err := newHandler(format, w, &slog.HandlerOptions{Level: lv})
+	if err != nil {
+		closer.Close() // nolint:errcheck
+		return nil, nil, errors.WithStack(err)
+	}
+	return slog.New(handler), closer, nil
+}
+
+// ParseLevel parses level string like "debug", "info", "warn" or "error" case-insensitively.
+// Empty string is treated as "info"
+func ParseLevel(level string) (slog.Level, error) {
+	var lv slog.Level
+	if level == "" {
+		return slog.LevelInfo, nil
+	}
+	if err := lv.UnmarshalText([]byte(strings.ToUpper(level))); err != nil {
+		return lv, errors.Errorf("Invalid log level %s, debug, info, warn or error is valid", level)
+	}
+	return lv, nil
+}
+
+func newHandler(format string, w io.Writer, opts *slog.HandlerOptions) (slog.Handler, error) {
+	switch format {
+	case "", FormatText:
+		return slog.NewTextHandler(w, opts), nil
+	case FormatJSON:
+		return slog.NewJSONHandler(w, opts), nil
+	default:
+		return nil, errors.Errorf("Invalid log format %s, text or json is valid", format)
+	}
+}
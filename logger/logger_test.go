@@ -0,0 +1,84 @@
+package logger
+
+import (
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		level   string
+		expect  slog.Level
+		isError bool
+	}{
+		{level: "", expect: slog.LevelInfo},
+		{level: "debug", expect: slog.LevelDebug},
+		{level: "WARN", expect: slog.LevelWarn},
+		{level: "error", expect: slog.LevelError},
+		{level: "verbose", isError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.level, func(t *testing.T) {
+			lv, err := ParseLevel(tt.level)
+			if tt.isError {
+				if err == nil {
+					t.Errorf("Expected error but got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("Unexpected error: %s", err)
+				return
+			}
+			if diff := cmp.Diff(tt.expect, lv); diff != "" {
+				t.Errorf("Level mismatch, diff=%s", diff)
+			}
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	t.Run("json logs are written to the file with level filter", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "falco.log")
+		l, closer, err := New(FormatJSON, "warn", file)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		l.Info("ignored")
+		l.Warn("fetch failed", "service", "foo")
+		if err := closer.Close(); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+
+		buf, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		lines := strings.Split(strings.TrimSpace(string(buf)), "\n")
+		if len(lines) != 1 {
+			t.Fatalf("Expected 1 line but got %d", len(lines))
+		}
+		var record map[string]any
+		if err := json.Unmarshal([]byte(lines[0]), &record); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		for key, expect := range map[string]string{"level": "WARN", "msg": "fetch failed", "service": "foo"} {
+			if diff := cmp.Diff(expect, record[key]); diff != "" {
+				t.Errorf("Field %s mismatch, diff=%s", key, diff)
+			}
+		}
+	})
+
+	t.Run("invalid format is error", func(t *testing.T) {
+		if _, _, err := New("xml", "info", ""); err == nil {
+			t.Errorf("Expected error but got nil")
+		}
+	})
+}
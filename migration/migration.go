@@ -0,0 +1,194 @@
+// Package migration provides codemods for known migration patterns of VCL builtins.
+// Codemods rewrite identifiers in place on the source text, so comments and formatting are kept as they are
+package migration
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ysugimoto/falco/v2/lexer"
+	"github.com/ysugimoto/falco/v2/token"
+)
+
+type Migration struct {
+	Name        string
+	Description string
+	// Renames of builtin variables and functions.
+	// Key which ends with ".*" renames all names under the prefix, and replacement must end with ".*" as well
+	Renames map[string]string
+}
+
+var migrations = []*Migration{
+	{
+		Name:        "method",
+		Description: "Replace legacy req.request and bereq.request with req.method and bereq.method",
+		Renames: map[string]string{
+			"req.request":   "req.method",
+			"bereq.request": "bereq.method",
+		},
+	},
+	{
+		Name:        "geoip",
+		Description: "Replace deprecated geoip.* variables with client.geo.* variables",
+		Renames: map[string]string{
+			"geoip.*": "client.geo.*",
+		},
+	},
+	{
+		Name:        "boltsort",
+		Description: "Replace deprecated boltsort.sort function with querystring.sort",
+		Renames: map[string]string{
+			"boltsort.sort": "querystring.sort",
+		},
+	},
+	{
+		Name:        "varnish4",
+		Description: "Rename Varnish 3 variables to Varnish 4 ones like req.backend_hint, for VCLs linted with varnish dialect",
+		Renames: map[string]string{
+			"req.backend":     "req.backend_hint",
+			"req.request":     "req.method",
+			"bereq.request":   "bereq.method",
+			"beresp.response": "beresp.reason",
+			"resp.response":   "resp.reason",
+			"obj.response":    "obj.reason",
+		},
+	},
+}
+
+// All returns all available migrations
+func All() []*Migration {
+	return migrations
+}
+
+// Get returns the migration for the name
+func Get(name string) (*Migration, error) {
+	names := make([]string, len(migrations))
+	for i, m := range migrations {
+		if m.Name == name {
+			return m, nil
+		}
+		names[i] = m.Name
+	}
+	return nil, fmt.Errorf(`Unknown migration "%s", expects one of %s`, name, strings.Join(names, ", "))
+}
+
+// Rename returns new name of the identifier, second value is false if the identifier is not migrated
+func (m *Migration) Rename(name string) (string, bool) {
+	if v, ok := m.Renames[name]; ok {
+		return v, true
+	}
+	for key, v := range m.Renames {
+		prefix, ok := strings.CutSuffix(key, "*")
+		if !ok || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		return strings.TrimSuffix(v, "*") + strings.TrimPrefix(name, prefix), true
+	}
+	return "", false
+}
+
+// Change is the single identifier rewrite
+type Change struct {
+	Line     int
+	Position int
+	From     string
+	To       string
+}
+
+func (c *Change) String() string {
+	return fmt.Sprintf("%d:%d %s -> %s", c.Line, c.Position, c.From, c.To)
+}
+
+// Apply rewrites identifiers in the source and returns rewritten source with changes.
+// Only identifier tokens are rewritten so that the same text in strings and comments is kept
+func (m *Migration) Apply(src string) (string, []*Change) {
+	var changes []*Change
+	l := lexer.NewFromString(src)
+	for {
+		tok := l.NextToken()
+		if tok.Type == token.EOF {
+			break
+		}
+		if tok.Type != token.IDENT {
+			continue
+		}
+		if to, ok := m.Rename(tok.Literal); ok {
+			changes = append(changes, &Change{
+				Line:     tok.Line,
+				Position: tok.Position,
+				From:     tok.Literal,
+				To:       to,
+			})
+		}
+	}
+	if len(changes) == 0 {
+		return src, nil
+	}
+
+	lines := strings.Split(src, "\n")
+	// Rewrite from the end of line in order to keep positions of preceding changes
+	sort.SliceStable(changes, func(i, j int) bool {
+		if changes[i].Line != changes[j].Line {
+			return changes[i].Line < changes[j].Line
+		}
+		return changes[i].Position > changes[j].Position
+	})
+	var applied []*Change
+	for _, c := range changes {
+		line := []rune(lines[c.Line-1])
+		start := c.Position - 1
+		end := start + len([]rune(c.From))
+		// Token position is not trustworthy after some special tokens, skip rewriting if it does not point to the identifier
+		if start < 0 || end > len(line) || string(line[start:end]) != c.From {
+			continue
+		}
+		lines[c.Line-1] = string(line[:start]) + c.To + string(line[end:])
+		applied = append(applied, c)
+	}
+
+	// Report changes in source order
+	sort.SliceStable(applied, func(i, j int) bool {
+		if applied[i].Line != applied[j].Line {
+			return applied[i].Line < applied[j].Line
+		}
+		return applied[i].Position < applied[j].Position
+	})
+	return strings.Join(lines, "\n"), applied
+}
+
+// Diff returns unified diff of the rewrite without context lines like "diff --unified=0".
+// Codemods never add or remove lines, so the lines at the same number are compared
+func Diff(file, before, after string) string {
+	a := strings.Split(before, "\n")
+	b := strings.Split(after, "\n")
+
+	var buf strings.Builder
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] == b[i] {
+			continue
+		}
+		// Group consecutive changed lines into a hunk
+		j := i
+		for j < len(a) && j < len(b) && a[j] != b[j] {
+			j++
+		}
+		if buf.Len() == 0 {
+			buf.WriteString("--- a/" + file + "\n")
+			buf.WriteString("+++ b/" + file + "\n")
+		}
+		if j-i == 1 {
+			buf.WriteString(fmt.Sprintf("@@ -%d +%d @@\n", i+1, i+1))
+		} else {
+			buf.WriteString(fmt.Sprintf("@@ -%d,%d +%d,%d @@\n", i+1, j-i, i+1, j-i))
+		}
+		for k := i; k < j; k++ {
+			buf.WriteString("-" + a[k] + "\n")
+		}
+		for k := i; k < j; k++ {
+			buf.WriteString("+" + b[k] + "\n")
+		}
+		i = j
+	}
+	return buf.String()
+}
@@ -0,0 +1,109 @@
+package migration
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name      string
+		migration string
+		input     string
+		expect    string
+		changes   int
+	}{
+		{
+			name:      "method",
+			migration: "method",
+			input: `sub vcl_recv {
+  # req.request is kept in comment
+  if (req.request == "GET" && req.http.X-Request) {
+    set req.http.Method = "req.request " req.request;
+  }
+}`,
+			expect: `sub vcl_recv {
+  # req.request is kept in comment
+  if (req.method == "GET" && req.http.X-Request) {
+    set req.http.Method = "req.request " req.method;
+  }
+}`,
+			changes: 2,
+		},
+		{
+			name:      "geoip wildcard",
+			migration: "geoip",
+			input:     `sub vcl_recv { set req.http.Geo = geoip.country_code "/" geoip.city; }`,
+			expect:    `sub vcl_recv { set req.http.Geo = client.geo.country_code "/" client.geo.city; }`,
+			changes:   2,
+		},
+		{
+			name:      "boltsort",
+			migration: "boltsort",
+			input:     "sub vcl_recv {\n  set req.url = boltsort.sort(req.url);\n}",
+			expect:    "sub vcl_recv {\n  set req.url = querystring.sort(req.url);\n}",
+			changes:   1,
+		},
+		{
+			name:      "varnish4",
+			migration: "varnish4",
+			input:     "sub vcl_recv {\n  set req.backend = default;\n  set req.backend.healthy = true;\n}",
+			expect:    "sub vcl_recv {\n  set req.backend_hint = default;\n  set req.backend.healthy = true;\n}",
+			changes:   1,
+		},
+		{
+			name:      "multibyte characters",
+			migration: "method",
+			input:     "sub vcl_recv {\n  set req.http.X = \"日本語\"; set req.http.Y = req.request;\n}",
+			expect:    "sub vcl_recv {\n  set req.http.X = \"日本語\"; set req.http.Y = req.method;\n}",
+			changes:   1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := Get(tt.migration)
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			actual, changes := m.Apply(tt.input)
+			if diff := cmp.Diff(tt.expect, actual); diff != "" {
+				t.Errorf("Rewrite mismatch, diff=%s", diff)
+			}
+			if len(changes) != tt.changes {
+				t.Errorf("Expect %d changes but got %d: %v", tt.changes, len(changes), changes)
+			}
+		})
+	}
+}
+
+func TestGetUnknownMigration(t *testing.T) {
+	if _, err := Get("unknown"); err == nil {
+		t.Errorf("Expected error for unknown migration")
+	}
+}
+
+func TestDiff(t *testing.T) {
+	before := "sub vcl_recv {\n  set req.http.A = req.request;\n  set req.http.B = req.request;\n  return(lookup);\n  set req.http.C = req.request;\n}"
+	m, _ := Get("method")
+	after, _ := m.Apply(before)
+
+	expect := `--- a/main.vcl
++++ b/main.vcl
+@@ -2,2 +2,2 @@
+-  set req.http.A = req.request;
+-  set req.http.B = req.request;
++  set req.http.A = req.method;
++  set req.http.B = req.method;
+@@ -5 +5 @@
+-  set req.http.C = req.request;
++  set req.http.C = req.method;
+`
+	if diff := cmp.Diff(expect, Diff("main.vcl", before, after)); diff != "" {
+		t.Errorf("Diff mismatch, diff=%s", diff)
+	}
+	if Diff("main.vcl", before, before) != "" {
+		t.Errorf("Diff should be empty for unchanged source")
+	}
+}
@@ -0,0 +1,234 @@
+package mirror
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	ihttp "github.com/ysugimoto/falco/v2/interpreter/http"
+)
+
+// Headers which are added by Fastly edge or always differ between requests so they are not compared as default
+var defaultIgnoreHeaders = []string{
+	"Date", "Age", "Via", "X-Timer", "X-Served-By", "X-Cache", "X-Cache-Hits", "Fastly-Debug-Digest", "Content-Length",
+}
+
+// Hop-by-hop headers which must not be forwarded to the staging service
+var hopHeaders = []string{
+	"Connection", "Keep-Alive", "Proxy-Connection", "Te", "Trailer", "Transfer-Encoding", "Upgrade",
+}
+
+// Maximum number of mirrored requests in flight. Requests over the limit are not mirrored
+const maxInflight = 16
+
+// Difference is the difference of the response between simulator and staging service
+type Difference struct {
+	Field     string
+	Simulator string
+	Staging   string
+}
+
+func (d *Difference) String() string {
+	return fmt.Sprintf("%s: simulator=%q, staging=%q", d.Field, d.Simulator, d.Staging)
+}
+
+// Reporter receives the differences of the mirrored request. Called only when differences are found
+type Reporter func(r *http.Request, differences []*Difference)
+
+type Option func(h *Handler)
+
+// WithPercentage sets the percentage of requests to be mirrored, 100 as default
+func WithPercentage(p int) Option {
+	return func(h *Handler) {
+		h.percentage = p
+	}
+}
+
+// WithTimeout sets the timeout of the mirrored request, 10 seconds as default
+func WithTimeout(d time.Duration) Option {
+	return func(h *Handler) {
+		h.client.Timeout = d
+	}
+}
+
+func WithIgnoreHeaders(headers []string) Option {
+	return func(h *Handler) {
+		for _, name := range headers {
+			h.ignoreHeaders[http.CanonicalHeaderKey(name)] = struct{}{}
+		}
+	}
+}
+
+// Handler responds the simulator response to the client, and asynchronously sends the same request
+// to the staging service in order to report differences of the response
+type Handler struct {
+	next          http.Handler
+	target        *url.URL
+	report        Reporter
+	percentage    int
+	ignoreHeaders map[string]struct{}
+	client        *http.Client
+	inflight      chan struct{}
+
+	// Decide whether the request is mirrored, replaceable in testing
+	sample func() bool
+}
+
+func New(next http.Handler, target string, report Reporter, opts ...Option) (*Handler, error) {
+	u, err := url.Parse(target)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, errors.Errorf("Mirror target must be http or https URL, got %s", target)
+	}
+
+	h := &Handler{
+		next:          next,
+		target:        u,
+		report:        report,
+		percentage:    100,
+		ignoreHeaders: make(map[string]struct{}),
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+			// Compare the redirect response itself
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		inflight: make(chan struct{}, maxInflight),
+	}
+	for _, name := range append(defaultIgnoreHeaders, hopHeaders...) {
+		h.ignoreHeaders[name] = struct{}{}
+	}
+	for i := range opts {
+		opts[i](h)
+	}
+	h.sample = func() bool {
+		return rand.IntN(100) < h.percentage // nolint:gosec
+	}
+	return h, nil
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// Both simulator and staging service must receive the same request body
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	r.Body.Close()
+
+	// Both simulator and staging service use the same request ID to correlate them end to end.
+	// The client request is not modified, the ID is passed to the simulator through the context
+	// and sent to the staging service as the header of the mirrored request
+	id := ihttp.RequestID(r, uuid.NewString())
+	req := r.Clone(ihttp.WithRequestID(r.Context(), id))
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+	h.next.ServeHTTP(rec, req)
+
+	for key, values := range rec.Header() {
+		for _, v := range values {
+			w.Header().Add(key, v)
+		}
+	}
+	w.WriteHeader(rec.Code)
+	w.Write(rec.Body.Bytes()) // nolint:errcheck
+
+	if !h.sample() {
+		return
+	}
+	select {
+	case h.inflight <- struct{}{}:
+	default:
+		slog.Warn("Too many mirrored requests in flight, skip mirroring", "url", r.URL.String())
+		return
+	}
+
+	// Mirrored request must not be cancelled when the client request has finished
+	mirrored := h.mirrorRequest(r.WithContext(context.WithoutCancel(r.Context())), body, id)
+	go func() {
+		defer func() { <-h.inflight }()
+		h.mirror(mirrored, rec.Result())
+	}()
+}
+
+// Factory the request to the staging service. The path and query are kept and the host is replaced by the target.
+// The request ID is sent as the header so the staging service could find the request in logs
+func (h *Handler) mirrorRequest(r *http.Request, body []byte, id string) *http.Request {
+	req := r.Clone(r.Context())
+	req.RequestURI = ""
+	req.URL.Scheme = h.target.Scheme
+	req.URL.Host = h.target.Host
+	req.Host = h.target.Host
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.ContentLength = int64(len(body))
+	for _, name := range hopHeaders {
+		req.Header.Del(name)
+	}
+	req.Header.Set(ihttp.RequestIDHeader, id)
+	return req
+}
+
+func (h *Handler) mirror(req *http.Request, simulated *http.Response) {
+	resp, err := h.client.Do(req)
+	if err != nil {
+		slog.Warn("Failed to mirror request", "url", req.URL.String(), "error", err)
+		return
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body) // nolint:errcheck
+
+	if differences := h.Compare(simulated, resp); len(differences) > 0 && h.report != nil {
+		h.report(req, differences)
+	}
+}
+
+// Compare returns differences of status and headers between simulator and staging responses
+func (h *Handler) Compare(simulated, staging *http.Response) []*Difference {
+	var differences []*Difference
+	add := func(field, vs, vt string) {
+		if vs != vt {
+			differences = append(differences, &Difference{Field: field, Simulator: vs, Staging: vt})
+		}
+	}
+
+	add("status", strconv.Itoa(simulated.StatusCode), strconv.Itoa(staging.StatusCode))
+
+	names := make(map[string]struct{})
+	for name := range simulated.Header {
+		names[name] = struct{}{}
+	}
+	for name := range staging.Header {
+		names[name] = struct{}{}
+	}
+	keys := make([]string, 0, len(names))
+	for name := range names {
+		if _, ok := h.ignoreHeaders[name]; ok {
+			continue
+		}
+		keys = append(keys, name)
+	}
+	sort.Strings(keys)
+	for _, name := range keys {
+		add(
+			"header "+name,
+			strings.Join(simulated.Header.Values(name), ", "),
+			strings.Join(staging.Header.Values(name), ", "),
+		)
+	}
+	return differences
+}
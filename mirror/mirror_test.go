@@ -0,0 +1,107 @@
+package mirror
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	ihttp "github.com/ysugimoto/falco/v2/interpreter/http"
+)
+
+func TestMirrorHandler(t *testing.T) {
+	received := make(chan string, 1)
+	receivedID := make(chan string, 1)
+	staging := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body) // nolint:errcheck
+		received <- r.Method + " " + r.URL.RequestURI() + " " + string(body)
+		receivedID <- r.Header.Get("X-Request-ID")
+		w.Header().Set("X-Foo", "staging")
+		w.Header().Set("X-Served-By", "cache-tyo")
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer staging.Close()
+
+	var simulatedID, simulatedHeader string
+	simulator := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		simulatedID = ihttp.RequestID(r, "")
+		simulatedHeader = r.Header.Get("X-Request-ID")
+		w.Header().Set("X-Foo", "simulator")
+		w.WriteHeader(http.StatusOK)
+		io.WriteString(w, "simulated") // nolint:errcheck
+	})
+
+	reported := make(chan []*Difference, 1)
+	h, err := New(simulator, staging.URL, func(r *http.Request, d []*Difference) {
+		reported <- d
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "http://example.com/foo?bar=baz", strings.NewReader("body")))
+	if w.Code != http.StatusOK || w.Body.String() != "simulated" {
+		t.Errorf("Simulator response must be responded to the client, code=%d, body=%s", w.Code, w.Body.String())
+	}
+
+	select {
+	case req := <-received:
+		if diff := cmp.Diff("POST /foo?bar=baz body", req); diff != "" {
+			t.Errorf("Mirrored request mismatch, diff=%s", diff)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Request is not mirrored")
+	}
+	if id := <-receivedID; simulatedID == "" || id != simulatedID {
+		t.Errorf("Request ID must be the same, simulator=%q, staging=%q", simulatedID, id)
+	}
+	if simulatedHeader != "" {
+		t.Errorf("Client request must not be modified, got X-Request-ID %q", simulatedHeader)
+	}
+
+	select {
+	case d := <-reported:
+		expect := []*Difference{
+			{Field: "status", Simulator: "200", Staging: "404"},
+			{Field: "header X-Foo", Simulator: "simulator", Staging: "staging"},
+		}
+		if diff := cmp.Diff(expect, d); diff != "" {
+			t.Errorf("Differences mismatch, diff=%s", diff)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Differences are not reported")
+	}
+}
+
+func TestMirrorHandlerSampling(t *testing.T) {
+	mirrored := make(chan struct{}, 1)
+	staging := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mirrored <- struct{}{}
+	}))
+	defer staging.Close()
+
+	simulator := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	h, err := New(simulator, staging.URL, nil, WithPercentage(0))
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	for range 10 {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	}
+
+	select {
+	case <-mirrored:
+		t.Errorf("Request must not be mirrored with 0 percentage")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestNewInvalidTarget(t *testing.T) {
+	if _, err := New(http.NotFoundHandler(), "staging.example.com", nil); err == nil {
+		t.Errorf("Expected error for the target without scheme but got nil")
+	}
+}
@@ -0,0 +1,84 @@
+package monitoring
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	SeverityCritical = "critical"
+	SeverityWarning  = "warning"
+	SeverityInfo     = "info"
+)
+
+// Alert is the suggested alert rule. Signal describes what to observe in the backend independent form,
+// observability teams translate it into the query of their monitoring system
+type Alert struct {
+	Name        string `json:"name"`
+	Severity    string `json:"severity"`
+	Signal      string `json:"signal"`
+	Description string `json:"description"`
+}
+
+// Suggest alerts from collected artifacts:
+//   - error rate and health of each backend
+//   - rate of synthetic error responses, severity depends on the status class
+//   - volume of each logging endpoint
+func suggestAlerts(a *Artifacts) []*Alert {
+	var alerts []*Alert
+
+	for _, b := range a.Backends {
+		alerts = append(alerts, &Alert{
+			Name:        fmt.Sprintf("backend_%s_error_rate", b.Name),
+			Severity:    SeverityCritical,
+			Signal:      fmt.Sprintf("ratio of 5xx responses from backend %s", b.Name),
+			Description: fmt.Sprintf("Backend %s responds server errors more than the SLO allows", b.Name),
+		})
+		if b.Probe {
+			alerts = append(alerts, &Alert{
+				Name:        fmt.Sprintf("backend_%s_unhealthy", b.Name),
+				Severity:    SeverityCritical,
+				Signal:      fmt.Sprintf("backend.%s.healthy is false", b.Name),
+				Description: fmt.Sprintf("Health check of backend %s is failing", b.Name),
+			})
+		}
+	}
+
+	for _, e := range a.ErrorCodes {
+		var severity, description string
+		switch {
+		case e.Code >= 500 && e.Code < 600:
+			severity = SeverityCritical
+			description = fmt.Sprintf("VCL responds synthetic server error %d", e.Code)
+		case e.Code >= 400 && e.Code < 500:
+			severity = SeverityWarning
+			description = fmt.Sprintf("VCL responds synthetic client error %d", e.Code)
+		default:
+			// Non-standard codes like 601 are usually translated in vcl_error, e.g. redirects
+			severity = SeverityInfo
+			description = fmt.Sprintf("VCL raises internal error %d", e.Code)
+		}
+		if len(e.Messages) > 0 {
+			description += fmt.Sprintf(" (%s)", strings.Join(e.Messages, ", "))
+		}
+		alerts = append(alerts, &Alert{
+			Name:        fmt.Sprintf("synthetic_error_%d_rate", e.Code),
+			Severity:    severity,
+			Signal:      fmt.Sprintf("rate of error %d statements", e.Code),
+			Description: description,
+		})
+	}
+
+	for _, l := range a.LogEndpoints {
+		if l.Name == "" {
+			continue
+		}
+		alerts = append(alerts, &Alert{
+			Name:        fmt.Sprintf("log_%s_volume", l.Name),
+			Severity:    SeverityWarning,
+			Signal:      fmt.Sprintf("count of log lines received by endpoint %s", l.Name),
+			Description: fmt.Sprintf("Logging endpoint %s stops receiving %d fields", l.Name, len(l.Fields)),
+		})
+	}
+	return alerts
+}
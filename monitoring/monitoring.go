@@ -0,0 +1,314 @@
+// Package monitoring scaffolds observability artifacts from static analysis of VCLs.
+// It reports synthetic error codes, custom headers, log fields and backends which the configuration actually uses,
+// and suggests alert rules so that dashboards and alerts match what the VCL does
+package monitoring
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ysugimoto/falco/v2/ast"
+)
+
+// Log statement which is sent to the named logging endpoint is formatted like
+// `log "syslog " req.service_id " endpoint :: " ...`
+var syslogPrefix = regexp.MustCompile(`^syslog\s+\S+\s+(.+?)\s*$`)
+
+// Field key which precedes the value in the log format like `"url":"` or `url=`
+var fieldKey = regexp.MustCompile(`([\w.-]+)"?\s*[:=]\s*"?$`)
+
+type Location struct {
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Position int    `json:"position"`
+}
+
+// ErrorCode is the status code of the synthetic response which is raised by error statement
+type ErrorCode struct {
+	Code      int64       `json:"code"`
+	Messages  []string    `json:"messages,omitempty"`
+	Locations []*Location `json:"locations"`
+}
+
+// Header is the header which is set or added in the VCL like resp.http.X-Cache
+type Header struct {
+	Name      string      `json:"name"`
+	Locations []*Location `json:"locations"`
+}
+
+// LogField is the field which is emitted to the logging endpoint.
+// Name is the key in the log format if found, otherwise the same as Source
+type LogField struct {
+	Name   string `json:"name"`
+	Source string `json:"source"`
+}
+
+// LogEndpoint is the logging endpoint and fields which are emitted to it.
+// Name is empty for log statements which do not specify the endpoint
+type LogEndpoint struct {
+	Name      string      `json:"name"`
+	Fields    []*LogField `json:"fields"`
+	Locations []*Location `json:"locations"`
+}
+
+type Backend struct {
+	Name     string    `json:"name"`
+	Probe    bool      `json:"probe"`
+	Location *Location `json:"location"`
+}
+
+// Artifacts are collected monitoring artifacts and suggested alerts, all of them are sorted by name
+type Artifacts struct {
+	ErrorCodes   []*ErrorCode   `json:"error_codes"`
+	Headers      []*Header      `json:"headers"`
+	LogEndpoints []*LogEndpoint `json:"log_endpoints"`
+	Backends     []*Backend     `json:"backends"`
+	Alerts       []*Alert       `json:"alerts"`
+}
+
+type Collector struct {
+	errorCodes   map[int64]*ErrorCode
+	headers      map[string]*Header
+	logEndpoints map[string]*LogEndpoint
+	backends     map[string]*Backend
+}
+
+func New() *Collector {
+	return &Collector{
+		errorCodes:   make(map[int64]*ErrorCode),
+		headers:      make(map[string]*Header),
+		logEndpoints: make(map[string]*LogEndpoint),
+		backends:     make(map[string]*Backend),
+	}
+}
+
+func location(meta *ast.Meta) *Location {
+	return &Location{
+		File:     meta.Token.File,
+		Line:     meta.Token.Line,
+		Position: meta.Token.Position,
+	}
+}
+
+// Collect collects artifacts in declarations and subroutines of the VCL, or root statements of the snippet.
+// Included modules are not resolved, so all VCL files should be passed to collect artifacts across the codebase
+func (c *Collector) Collect(vcl *ast.VCL) {
+	for _, stmt := range vcl.Statements {
+		switch t := stmt.(type) {
+		case *ast.SubroutineDeclaration:
+			c.collectBlock(t.Block)
+		case *ast.BackendDeclaration:
+			c.collectBackend(t)
+		default:
+			c.collectStatement(stmt)
+		}
+	}
+}
+
+// Artifacts returns collected artifacts with suggested alerts
+func (c *Collector) Artifacts() *Artifacts {
+	a := &Artifacts{
+		ErrorCodes:   make([]*ErrorCode, 0, len(c.errorCodes)),
+		Headers:      make([]*Header, 0, len(c.headers)),
+		LogEndpoints: make([]*LogEndpoint, 0, len(c.logEndpoints)),
+		Backends:     make([]*Backend, 0, len(c.backends)),
+	}
+	for _, v := range c.errorCodes {
+		a.ErrorCodes = append(a.ErrorCodes, v)
+	}
+	sort.Slice(a.ErrorCodes, func(i, j int) bool {
+		return a.ErrorCodes[i].Code < a.ErrorCodes[j].Code
+	})
+	for _, v := range c.headers {
+		a.Headers = append(a.Headers, v)
+	}
+	sort.Slice(a.Headers, func(i, j int) bool {
+		return a.Headers[i].Name < a.Headers[j].Name
+	})
+	for _, v := range c.logEndpoints {
+		a.LogEndpoints = append(a.LogEndpoints, v)
+	}
+	sort.Slice(a.LogEndpoints, func(i, j int) bool {
+		return a.LogEndpoints[i].Name < a.LogEndpoints[j].Name
+	})
+	for _, v := range c.backends {
+		a.Backends = append(a.Backends, v)
+	}
+	sort.Slice(a.Backends, func(i, j int) bool {
+		return a.Backends[i].Name < a.Backends[j].Name
+	})
+	a.Alerts = suggestAlerts(a)
+	return a
+}
+
+func (c *Collector) collectBackend(decl *ast.BackendDeclaration) {
+	b := &Backend{
+		Name:     decl.Name.Value,
+		Location: location(decl.Meta),
+	}
+	for _, prop := range decl.Properties {
+		if prop.Key.Value == "probe" {
+			b.Probe = true
+		}
+	}
+	c.backends[b.Name] = b
+}
+
+func (c *Collector) collectError(stmt *ast.ErrorStatement) {
+	code, ok := stmt.Code.(*ast.Integer)
+	if !ok {
+		return
+	}
+	e, ok := c.errorCodes[code.Value]
+	if !ok {
+		e = &ErrorCode{Code: code.Value}
+		c.errorCodes[code.Value] = e
+	}
+	if msg, ok := stmt.Argument.(*ast.String); ok && msg.Value != "" {
+		var found bool
+		for _, m := range e.Messages {
+			found = found || m == msg.Value
+		}
+		if !found {
+			e.Messages = append(e.Messages, msg.Value)
+		}
+	}
+	e.Locations = append(e.Locations, location(stmt.Meta))
+}
+
+func (c *Collector) collectHeader(ident *ast.Ident) {
+	if !strings.Contains(ident.Value, ".http.") {
+		return
+	}
+	name := ident.Value
+	// Subfield assignment like resp.http.Cache-Control:max-age modifies the header itself
+	if idx := strings.Index(name, ":"); idx != -1 {
+		name = name[:idx]
+	}
+	h, ok := c.headers[name]
+	if !ok {
+		h = &Header{Name: name}
+		c.headers[name] = h
+	}
+	h.Locations = append(h.Locations, location(ident.Meta))
+}
+
+func (c *Collector) collectLog(stmt *ast.LogStatement) {
+	operands := flattenConcat(stmt.Value)
+
+	// Find the endpoint name from the format before "::" separator
+	var head strings.Builder
+	message := -1
+	for i, op := range operands {
+		s, ok := op.(*ast.String)
+		if !ok {
+			// Placeholder of the dynamic value like req.service_id
+			head.WriteString("*")
+			continue
+		}
+		if idx := strings.Index(s.Value, "::"); idx != -1 {
+			head.WriteString(s.Value[:idx])
+			message = i
+			break
+		}
+		head.WriteString(s.Value)
+	}
+
+	var name string
+	if message != -1 {
+		if m := syslogPrefix.FindStringSubmatch(head.String()); m != nil {
+			name = m[1]
+		}
+	}
+	e, ok := c.logEndpoints[name]
+	if !ok {
+		e = &LogEndpoint{Name: name}
+		c.logEndpoints[name] = e
+	}
+	e.Locations = append(e.Locations, location(stmt.Meta))
+
+	var preceding string
+	for i := message + 1; i < len(operands); i++ {
+		if s, ok := operands[i].(*ast.String); ok {
+			preceding = s.Value
+			continue
+		}
+		e.addField(operands[i], preceding)
+		preceding = ""
+	}
+}
+
+func (e *LogEndpoint) addField(expr ast.Expression, preceding string) {
+	source := expr.String()
+	if ident, ok := expr.(*ast.Ident); ok {
+		source = ident.Value
+	}
+	name := source
+	if m := fieldKey.FindStringSubmatch(preceding); m != nil {
+		name = m[1]
+	}
+	for _, f := range e.Fields {
+		if f.Name == name && f.Source == source {
+			return
+		}
+	}
+	e.Fields = append(e.Fields, &LogField{Name: name, Source: source})
+}
+
+// Flatten string concatenation into operands
+func flattenConcat(expr ast.Expression) []ast.Expression {
+	switch t := expr.(type) {
+	case *ast.InfixExpression:
+		if t.Operator == "+" {
+			return append(flattenConcat(t.Left), flattenConcat(t.Right)...)
+		}
+	case *ast.GroupedExpression:
+		return flattenConcat(t.Right)
+	}
+	return []ast.Expression{expr}
+}
+
+func (c *Collector) collectBlock(block *ast.BlockStatement) {
+	if block == nil {
+		return
+	}
+	c.collectStatements(block.Statements)
+}
+
+func (c *Collector) collectStatements(statements []ast.Statement) {
+	for _, stmt := range statements {
+		c.collectStatement(stmt)
+	}
+}
+
+func (c *Collector) collectStatement(stmt ast.Statement) {
+	switch t := stmt.(type) {
+	case *ast.BlockStatement:
+		c.collectBlock(t)
+	case *ast.SetStatement:
+		c.collectHeader(t.Ident)
+	case *ast.AddStatement:
+		c.collectHeader(t.Ident)
+	case *ast.IfStatement:
+		c.collectIfStatement(t)
+	case *ast.SwitchStatement:
+		for _, cs := range t.Cases {
+			c.collectStatements(cs.Statements)
+		}
+	case *ast.ErrorStatement:
+		c.collectError(t)
+	case *ast.LogStatement:
+		c.collectLog(t)
+	}
+}
+
+func (c *Collector) collectIfStatement(stmt *ast.IfStatement) {
+	c.collectBlock(stmt.Consequence)
+	for _, another := range stmt.Another {
+		c.collectIfStatement(another)
+	}
+	if stmt.Alternative != nil {
+		c.collectBlock(stmt.Alternative.Consequence)
+	}
+}
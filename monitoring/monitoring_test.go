@@ -0,0 +1,114 @@
+package monitoring
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/ysugimoto/falco/v2/lexer"
+	"github.com/ysugimoto/falco/v2/parser"
+)
+
+func TestCollect(t *testing.T) {
+	c := New()
+	for name, input := range map[string]string{
+		"main.vcl": `
+backend F_origin {
+  .host = "example.com";
+  .probe = {
+    .request = "GET / HTTP/1.1";
+  }
+}
+
+sub vcl_recv {
+  #FASTLY recv
+  if (req.url.path == "/old") {
+    error 601 "Moved";
+  } else if (!req.http.Authorization) {
+    error 401 "Unauthorized";
+  }
+  set req.http.X-Request-Start = now;
+}
+
+sub vcl_deliver {
+  #FASTLY deliver
+  set resp.http.X-Cache = if(fastly_info.state ~ "HIT", "HIT", "MISS");
+  add resp.http.Server-Timing:edge = "1";
+  log "syslog " req.service_id " access-log :: " {"{"url":""} req.url {"","status":"} resp.status {"}"};
+}`,
+		"error.vcl": `
+sub vcl_error {
+  if (obj.status == 601) {
+    set obj.status = 301;
+    set obj.http.Location = "/new";
+    return (deliver);
+  }
+  error 503 "Service Unavailable";
+  log "status=" obj.status;
+}`,
+	} {
+		vcl, err := parser.New(lexer.NewFromString(input, lexer.WithFile(name))).ParseVCL()
+		if err != nil {
+			t.Fatalf("Unexpected parse error: %s", err)
+		}
+		c.Collect(vcl)
+	}
+
+	a := c.Artifacts()
+	ignore := cmpopts.IgnoreFields(ErrorCode{}, "Locations")
+
+	expectErrors := []*ErrorCode{
+		{Code: 401, Messages: []string{"Unauthorized"}},
+		{Code: 503, Messages: []string{"Service Unavailable"}},
+		{Code: 601, Messages: []string{"Moved"}},
+	}
+	if diff := cmp.Diff(expectErrors, a.ErrorCodes, ignore); diff != "" {
+		t.Errorf("Error codes mismatch, diff=%s", diff)
+	}
+
+	var headers []string
+	for _, h := range a.Headers {
+		headers = append(headers, h.Name)
+	}
+	expectHeaders := []string{"obj.http.Location", "req.http.X-Request-Start", "resp.http.Server-Timing", "resp.http.X-Cache"}
+	if diff := cmp.Diff(expectHeaders, headers); diff != "" {
+		t.Errorf("Headers mismatch, diff=%s", diff)
+	}
+
+	expectEndpoints := []*LogEndpoint{
+		{
+			Name:   "",
+			Fields: []*LogField{{Name: "status", Source: "obj.status"}},
+		},
+		{
+			Name: "access-log",
+			Fields: []*LogField{
+				{Name: "url", Source: "req.url"},
+				{Name: "status", Source: "resp.status"},
+			},
+		},
+	}
+	if diff := cmp.Diff(expectEndpoints, a.LogEndpoints, cmpopts.IgnoreFields(LogEndpoint{}, "Locations")); diff != "" {
+		t.Errorf("Log endpoints mismatch, diff=%s", diff)
+	}
+
+	if diff := cmp.Diff([]*Backend{{Name: "F_origin", Probe: true}}, a.Backends, cmpopts.IgnoreFields(Backend{}, "Location")); diff != "" {
+		t.Errorf("Backends mismatch, diff=%s", diff)
+	}
+
+	var alerts []string
+	for _, v := range a.Alerts {
+		alerts = append(alerts, v.Severity+" "+v.Name)
+	}
+	expectAlerts := []string{
+		"critical backend_F_origin_error_rate",
+		"critical backend_F_origin_unhealthy",
+		"warning synthetic_error_401_rate",
+		"critical synthetic_error_503_rate",
+		"info synthetic_error_601_rate",
+		"warning log_access-log_volume",
+	}
+	if diff := cmp.Diff(expectAlerts, alerts); diff != "" {
+		t.Errorf("Alerts mismatch, diff=%s", diff)
+	}
+}
@@ -157,7 +157,10 @@ func (p *Parser) ParseBackendProperty() (*ast.BackendProperty, error) {
 		Meta: p.curToken,
 	}
 
-	if !p.ExpectPeek(token.IDENT) {
+	// Custom keyword like "probe" could be used as the property name
+	if _, ok := p.customParsers[p.peekToken.Token.Type]; ok {
+		p.NextToken()
+	} else if !p.ExpectPeek(token.IDENT) {
 		return nil, errors.WithStack(UnexpectedToken(p.peekToken, "IDENT"))
 	}
 	prop.Key = p.ParseIdent()
@@ -629,3 +632,59 @@ func (p *Parser) ParseRatecounterDeclaration() (*ast.RatecounterDeclaration, err
 
 	return r, nil
 }
+
+// ParseConstDeclaration parses falco extension syntax "const NAME = literal;"
+func (p *Parser) ParseConstDeclaration() (*ast.ConstDeclaration, error) {
+	c := &ast.ConstDeclaration{
+		Meta: p.curToken,
+	}
+
+	if !p.ExpectPeek(token.IDENT) {
+		return nil, errors.WithStack(UnexpectedToken(p.peekToken, "IDENT"))
+	}
+	c.Name = p.ParseIdent()
+
+	if !p.ExpectPeek(token.ASSIGN) {
+		return nil, errors.WithStack(UnexpectedToken(p.peekToken, "ASSIGN"))
+	}
+	SwapLeadingTrailing(p.curToken, c.Name.Meta)
+	p.NextToken() // point to value expression start
+
+	exp, err := p.ParseExpression(LOWEST)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if !isConstValue(exp) {
+		return nil, errors.WithStack(InvalidConstValue(exp.GetMeta()))
+	}
+	c.Value = exp
+
+	if !p.PeekTokenIs(token.SEMICOLON) {
+		return nil, errors.WithStack(MissingSemicolon(p.curToken))
+	}
+	c.EndLine = exp.GetMeta().EndLine
+	c.EndPosition = exp.GetMeta().EndPosition
+
+	p.NextToken() // point to SEMICOLON
+	SwapLeadingTrailing(p.curToken, c.Value.GetMeta())
+	c.Trailing = p.Trailing()
+
+	return c, nil
+}
+
+// Constant value accepts only literals, and negative numeric literals
+func isConstValue(exp ast.Expression) bool {
+	switch t := exp.(type) {
+	case *ast.String, *ast.Integer, *ast.Float, *ast.RTime, *ast.Boolean:
+		return true
+	case *ast.PrefixExpression:
+		if t.Operator != "-" {
+			return false
+		}
+		switch t.Right.(type) {
+		case *ast.Integer, *ast.Float, *ast.RTime:
+			return true
+		}
+	}
+	return false
+}
@@ -2611,3 +2611,93 @@ ratecounter ip_ratecounter {
 	}
 	assert(t, vcl, expect)
 }
+
+func TestParseConstDeclaration(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		input := `// Const Leading comment
+const TTL_LONG = 86400s; // Const Trailing comment`
+		expect := &ast.VCL{
+			Statements: []ast.Statement{
+				&ast.ConstDeclaration{
+					Meta: &ast.Meta{
+						Token: token.Token{
+							Type:     token.CONST,
+							Literal:  "const",
+							Line:     2,
+							Position: 1,
+						},
+						Leading:            comments("// Const Leading comment"),
+						Trailing:           comments("// Const Trailing comment"),
+						Infix:              comments(),
+						Nest:               0,
+						PreviousEmptyLines: 0,
+						EndLine:            2,
+						EndPosition:        23,
+					},
+					Name: &ast.Ident{
+						Meta: &ast.Meta{
+							Token: token.Token{
+								Type:     token.IDENT,
+								Literal:  "TTL_LONG",
+								Line:     2,
+								Position: 7,
+							},
+							Leading:            comments(),
+							Trailing:           comments(),
+							Infix:              comments(),
+							Nest:               0,
+							PreviousEmptyLines: 0,
+							EndLine:            2,
+							EndPosition:        14,
+						},
+						Value: "TTL_LONG",
+					},
+					Value: &ast.RTime{
+						Meta: &ast.Meta{
+							Token: token.Token{
+								Type:     token.RTIME,
+								Literal:  "86400s",
+								Line:     2,
+								Position: 18,
+							},
+							Leading:            comments(),
+							Trailing:           comments(),
+							Infix:              comments(),
+							Nest:               0,
+							PreviousEmptyLines: 0,
+							EndLine:            2,
+							EndPosition:        23,
+						},
+						Value: "86400s",
+					},
+				},
+			},
+		}
+		vcl, err := New(lexer.NewFromString(input)).ParseVCL()
+		if err != nil {
+			t.Errorf("%+v\n", err)
+		}
+		assert(t, vcl, expect)
+	})
+
+	t.Run("negative number", func(t *testing.T) {
+		_, err := New(lexer.NewFromString(`const OFFSET = -10;`)).ParseVCL()
+		if err != nil {
+			t.Errorf("%+v\n", err)
+		}
+	})
+
+	t.Run("non-literal value is error", func(t *testing.T) {
+		_, err := New(lexer.NewFromString(`const HOST = req.http.Host;`)).ParseVCL()
+		if err == nil {
+			t.Errorf("Expected error but got nil")
+		}
+	})
+
+	t.Run("missing semicolon", func(t *testing.T) {
+		_, err := New(lexer.NewFromString(`const TTL_LONG = 86400s`)).ParseVCL()
+		if err == nil {
+			t.Errorf("Expected error but got nil")
+		}
+	})
+}
@@ -108,3 +108,23 @@ func LongStringDelimiterMismatch(m *ast.Meta, openDelim, closeDelim string) *Par
 		Message: fmt.Sprintf("Long String delimiter mismatch. open=%s, close=%s", openDelim, closeDelim),
 	}
 }
+
+func UnterminatedString(m *ast.Meta) *ParseError {
+	message := "Unterminated string, closing double quote is not found before the end of line"
+	if strings.HasPrefix(m.Token.Literal, "{") {
+		delimiter := strings.TrimPrefix(m.Token.Literal, "{")
+		delimiter = delimiter[:strings.Index(delimiter, `"`)]
+		message = fmt.Sprintf(`Unterminated long string, closing "%s} is not found`, delimiter)
+	}
+	return &ParseError{
+		Token:   m.Token,
+		Message: message,
+	}
+}
+
+func InvalidConstValue(m *ast.Meta) *ParseError {
+	return &ParseError{
+		Token:   m.Token,
+		Message: "Constant value must be a literal: " + m.Token.Literal,
+	}
+}
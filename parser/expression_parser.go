@@ -70,6 +70,9 @@ func (p *Parser) ParseExpression(precedence int) (ast.Expression, error) {
 	// ) { ... }
 	prefix, ok := p.prefixParsers[p.curToken.Token.Type]
 	if !ok {
+		if isUnterminatedString(p.curToken.Token) {
+			return nil, errors.WithStack(UnterminatedString(p.curToken))
+		}
 		return nil, errors.WithStack(UndefinedPrefix(p.curToken))
 	}
 
@@ -73,3 +73,11 @@ func isGotoDestination(t token.Token) bool {
 
 	return len(components) == 2
 }
+
+// Lexer reports unterminated string as ILLEGAL token which starts with string opening characters
+func isUnterminatedString(t token.Token) bool {
+	if t.Type != token.ILLEGAL {
+		return false
+	}
+	return strings.HasPrefix(t.Literal, `"`) || (strings.HasPrefix(t.Literal, "{") && strings.Contains(t.Literal, `"`))
+}
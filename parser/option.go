@@ -1,5 +1,7 @@
 package parser
 
+import "context"
+
 type ParserOption func(p *Parser)
 
 func WithCustomParser(cps ...CustomParser) ParserOption {
@@ -9,3 +11,11 @@ func WithCustomParser(cps ...CustomParser) ParserOption {
 		}
 	}
 }
+
+// WithContext makes the parser stop parsing when the context is cancelled.
+// The cancellation is checked between top-level declarations
+func WithContext(ctx context.Context) ParserOption {
+	return func(p *Parser) {
+		p.ctx = ctx
+	}
+}
@@ -1,6 +1,8 @@
 package parser
 
 import (
+	"context"
+
 	"github.com/pkg/errors"
 	"github.com/ysugimoto/falco/v2/ast"
 	"github.com/ysugimoto/falco/v2/token"
@@ -53,12 +55,14 @@ type Tokenizer interface {
 }
 
 type Parser struct {
-	tk Tokenizer
+	tk  Tokenizer
+	ctx context.Context
 
 	prevToken *ast.Meta
 	curToken  *ast.Meta
 	peekToken *ast.Meta
 	level     int
+	metas     ast.MetaAllocator
 
 	prefixParsers  map[token.TokenType]prefixParser
 	infixParsers   map[token.TokenType]infixParser
@@ -143,7 +147,7 @@ func (p *Parser) ReadPeek() {
 			}
 			continue
 		}
-		meta := ast.New(t, p.level, leading)
+		meta := p.metas.New(t, p.level, leading)
 		meta.PreviousEmptyLines = previousEmptyLines
 		p.peekToken = meta
 		break
@@ -215,7 +219,7 @@ func (p *Parser) isDeclarationToken(t token.TokenType) bool {
 	switch t {
 	case token.ACL, token.IMPORT, token.INCLUDE, token.BACKEND,
 		token.DIRECTOR, token.TABLE, token.SUBROUTINE,
-		token.PENALTYBOX, token.RATECOUNTER:
+		token.PENALTYBOX, token.RATECOUNTER, token.CONST:
 		return true
 	default:
 		// Check custom parsers
@@ -251,6 +255,11 @@ func (p *Parser) ParseVCL() (*ast.VCL, error) {
 	vcl := &ast.VCL{}
 
 	for !p.CurTokenIs(token.EOF) {
+		if p.ctx != nil {
+			if err := p.ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
 		stmt, err := p.Parse()
 		if err != nil {
 			return nil, err
@@ -285,6 +294,8 @@ func (p *Parser) Parse() (ast.Statement, error) {
 		stmt, err = p.ParsePenaltyboxDeclaration()
 	case token.RATECOUNTER:
 		stmt, err = p.ParseRatecounterDeclaration()
+	case token.CONST:
+		stmt, err = p.ParseConstDeclaration()
 	default:
 		if custom, ok := p.customParsers[p.curToken.Token.Type]; ok {
 			stmt, err = custom.Parse(p)
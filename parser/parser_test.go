@@ -1,6 +1,9 @@
 package parser
 
 import (
+	"context"
+	"errors"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -102,6 +105,37 @@ sub vcl_recv {
 	}
 }
 
+func TestUnterminatedString(t *testing.T) {
+	tests := []struct {
+		input  string
+		expect string
+	}{
+		{
+			input:  "sub vcl_recv {\n\tset req.http.A = \"foo;\n}",
+			expect: "Unterminated string",
+		},
+		{
+			input:  "sub vcl_recv {\n\tset req.http.A = \"foo;\n\tset req.http.B = \"bar\";\n}",
+			expect: "Unterminated string",
+		},
+		{
+			input:  "sub vcl_recv {\n\tset req.http.A = {JSON\"foo\"bar\"};\n}",
+			expect: `Unterminated long string, closing "JSON} is not found`,
+		},
+	}
+
+	for _, tt := range tests {
+		_, err := New(lexer.NewFromString(tt.input)).ParseVCL()
+		if err == nil {
+			t.Errorf("expects error but got nil")
+			continue
+		}
+		if !strings.Contains(err.Error(), tt.expect) {
+			t.Errorf("expects error contains %s but got %s", tt.expect, err)
+		}
+	}
+}
+
 func TestStringLiteralEscapes(t *testing.T) {
 	// % escapes are only expanded in double-quote strings.
 	input := `
@@ -1305,6 +1339,15 @@ set req.http.baz = "qux";`,
 			isSnippet: false,
 			wantErr:   false,
 		},
+		{
+			name: "regular VCL with const",
+			input: `const TTL_LONG = 86400s;
+sub vcl_fetch {
+	set beresp.ttl = TTL_LONG;
+}`,
+			isSnippet: false,
+			wantErr:   false,
+		},
 		{
 			name:      "empty file",
 			input:     ``,
@@ -1366,3 +1409,21 @@ func TestIsDeclarationToken(t *testing.T) {
 		})
 	}
 }
+
+func TestParseVCLWithCancelledContext(t *testing.T) {
+	input := `
+sub vcl_recv {
+	set req.http.Foo = "bar";
+}`
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := New(lexer.NewFromString(input), WithContext(ctx)).ParseVCL()
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context canceled error, got=%v", err)
+	}
+
+	if _, err := New(lexer.NewFromString(input), WithContext(context.Background())).ParseVCL(); err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+}
@@ -51,7 +51,7 @@ func (p *Parser) ParseLongString() (*ast.String, error) {
 	}
 	// Check open and close delimiter string is the same
 	if delimiter != p.peekToken.Token.Literal {
-		return nil, errors.WithStack(UnexpectedToken(p.peekToken, token.CLOSE_LONG_STRING))
+		return nil, errors.WithStack(LongStringDelimiterMismatch(p.peekToken, delimiter, p.peekToken.Token.Literal))
 	}
 
 	str.GetMeta().Leading = openToken.Leading
@@ -19,6 +19,7 @@ type LintStatement interface {
 		*ast.SubroutineDeclaration |
 		*ast.PenaltyboxDeclaration |
 		*ast.RatecounterDeclaration |
+		*ast.ConstDeclaration |
 		*ast.BlockStatement |
 		*ast.ImportStatement |
 		*ast.IncludeStatement |
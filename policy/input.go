@@ -0,0 +1,219 @@
+package policy
+
+import (
+	"github.com/ysugimoto/falco/v2/ast"
+	"github.com/ysugimoto/falco/v2/token"
+)
+
+// Reference represents the statement that is related to policy evaluation
+type Reference struct {
+	Name     string `json:"name"`
+	Value    string `json:"value,omitempty"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Position int    `json:"position"`
+}
+
+func newReference(name, value string, tok token.Token) *Reference {
+	return &Reference{
+		Name:     name,
+		Value:    value,
+		File:     tok.File,
+		Line:     tok.Line,
+		Position: tok.Position,
+	}
+}
+
+type Subroutine struct {
+	*Reference
+	Calls         []*Reference `json:"calls"`
+	Returns       []*Reference `json:"returns"`
+	Sets          []*Reference `json:"sets"`
+	Unsets        []*Reference `json:"unsets"`
+	Adds          []*Reference `json:"adds"`
+	Removes       []*Reference `json:"removes"`
+	Declares      []*Reference `json:"declares"`
+	Errors        []*Reference `json:"errors"`
+	Restarts      []*Reference `json:"restarts"`
+	Logs          []*Reference `json:"logs"`
+	Synthetics    []*Reference `json:"synthetics"`
+	FunctionCalls []*Reference `json:"function_calls"`
+	Gotos         []*Reference `json:"gotos"`
+	Includes      []*Reference `json:"includes"`
+	Conditions    []*Reference `json:"conditions"`
+}
+
+// Declaration is the reference of the declaration which has properties like backend and director.
+// Property values are the VCL expressions as they are written
+type Declaration struct {
+	*Reference
+	Properties map[string]string `json:"properties"`
+	Backends   []string          `json:"backends,omitempty"`
+}
+
+// ASTInput is the input document of KindAST.
+// We don't pass the raw AST to the policy because it is too complicated to write Rego,
+// instead summarize the declarations and statements which are commonly used for governance.
+// The summary is built from root statements whose include statements are resolved,
+// so declarations in included modules are also contained
+type ASTInput struct {
+	Backends     []*Declaration `json:"backends"`
+	Directors    []*Declaration `json:"directors"`
+	Acls         []*Reference   `json:"acls"`
+	Tables       []*Reference   `json:"tables"`
+	Penaltyboxes []*Reference   `json:"penaltyboxes"`
+	Ratecounters []*Reference   `json:"ratecounters"`
+	Subroutines  []*Subroutine  `json:"subroutines"`
+}
+
+func NewASTInput(statements []ast.Statement) *ASTInput {
+	input := &ASTInput{
+		Backends:     []*Declaration{},
+		Directors:    []*Declaration{},
+		Acls:         []*Reference{},
+		Tables:       []*Reference{},
+		Penaltyboxes: []*Reference{},
+		Ratecounters: []*Reference{},
+		Subroutines:  []*Subroutine{},
+	}
+
+	for _, stmt := range statements {
+		switch t := stmt.(type) {
+		case *ast.BackendDeclaration:
+			decl := &Declaration{
+				Reference:  newReference(t.Name.Value, "", t.GetMeta().Token),
+				Properties: map[string]string{},
+			}
+			for _, prop := range t.Properties {
+				decl.Properties[prop.Key.Value] = prop.Value.String()
+			}
+			input.Backends = append(input.Backends, decl)
+		case *ast.DirectorDeclaration:
+			var kind string
+			if t.DirectorType != nil {
+				kind = t.DirectorType.Value
+			}
+			decl := &Declaration{
+				Reference:  newReference(t.Name.Value, kind, t.GetMeta().Token),
+				Properties: map[string]string{},
+				Backends:   []string{},
+			}
+			for _, prop := range t.Properties {
+				switch p := prop.(type) {
+				case *ast.DirectorProperty:
+					decl.Properties[p.Key.Value] = p.Value.String()
+				case *ast.DirectorBackendObject:
+					for _, v := range p.Values {
+						if v.Key.Value == "backend" {
+							decl.Backends = append(decl.Backends, v.Value.String())
+						}
+					}
+				}
+			}
+			input.Directors = append(input.Directors, decl)
+		case *ast.AclDeclaration:
+			input.Acls = append(input.Acls, newReference(t.Name.Value, "", t.GetMeta().Token))
+		case *ast.TableDeclaration:
+			var valueType string
+			if t.ValueType != nil {
+				valueType = t.ValueType.Value
+			}
+			input.Tables = append(input.Tables, newReference(t.Name.Value, valueType, t.GetMeta().Token))
+		case *ast.PenaltyboxDeclaration:
+			input.Penaltyboxes = append(input.Penaltyboxes, newReference(t.Name.Value, "", t.GetMeta().Token))
+		case *ast.RatecounterDeclaration:
+			input.Ratecounters = append(input.Ratecounters, newReference(t.Name.Value, "", t.GetMeta().Token))
+		case *ast.SubroutineDeclaration:
+			var returnType string
+			if t.ReturnType != nil {
+				returnType = t.ReturnType.Value
+			}
+			sub := &Subroutine{
+				Reference:     newReference(t.Name.Value, returnType, t.GetMeta().Token),
+				Calls:         []*Reference{},
+				Returns:       []*Reference{},
+				Sets:          []*Reference{},
+				Unsets:        []*Reference{},
+				Adds:          []*Reference{},
+				Removes:       []*Reference{},
+				Declares:      []*Reference{},
+				Errors:        []*Reference{},
+				Restarts:      []*Reference{},
+				Logs:          []*Reference{},
+				Synthetics:    []*Reference{},
+				FunctionCalls: []*Reference{},
+				Gotos:         []*Reference{},
+				Includes:      []*Reference{},
+				Conditions:    []*Reference{},
+			}
+			sub.collect(t.Block.Statements)
+			input.Subroutines = append(input.Subroutines, sub)
+		}
+	}
+
+	return input
+}
+
+// Collect statements recursively
+func (s *Subroutine) collect(statements []ast.Statement) {
+	for _, stmt := range statements {
+		tok := stmt.GetMeta().Token
+		switch t := stmt.(type) {
+		case *ast.BlockStatement:
+			s.collect(t.Statements)
+		case *ast.IfStatement:
+			s.Conditions = append(s.Conditions, newReference("if", t.Condition.String(), tok))
+			s.collect(t.Consequence.Statements)
+			for _, a := range t.Another {
+				s.Conditions = append(s.Conditions, newReference("else if", a.Condition.String(), a.GetMeta().Token))
+				s.collect(a.Consequence.Statements)
+			}
+			if t.Alternative != nil {
+				s.collect(t.Alternative.Consequence.Statements)
+			}
+		case *ast.SwitchStatement:
+			s.Conditions = append(s.Conditions, newReference("switch", t.Control.String(), tok))
+			for _, c := range t.Cases {
+				s.collect(c.Statements)
+			}
+		case *ast.CallStatement:
+			s.Calls = append(s.Calls, newReference(t.Subroutine.Value, "", tok))
+		case *ast.ReturnStatement:
+			var state string
+			if t.ReturnExpression != nil {
+				state = t.ReturnExpression.String()
+			}
+			s.Returns = append(s.Returns, newReference("return", state, tok))
+		case *ast.SetStatement:
+			s.Sets = append(s.Sets, newReference(t.Ident.Value, t.Value.String(), tok))
+		case *ast.UnsetStatement:
+			s.Unsets = append(s.Unsets, newReference(t.Ident.Value, "", tok))
+		case *ast.AddStatement:
+			s.Adds = append(s.Adds, newReference(t.Ident.Value, t.Value.String(), tok))
+		case *ast.RemoveStatement:
+			s.Removes = append(s.Removes, newReference(t.Ident.Value, "", tok))
+		case *ast.DeclareStatement:
+			s.Declares = append(s.Declares, newReference(t.Name.Value, t.ValueType.Value, tok))
+		case *ast.ErrorStatement:
+			var code string
+			if t.Code != nil {
+				code = t.Code.String()
+			}
+			s.Errors = append(s.Errors, newReference("error", code, tok))
+		case *ast.RestartStatement:
+			s.Restarts = append(s.Restarts, newReference("restart", "", tok))
+		case *ast.LogStatement:
+			s.Logs = append(s.Logs, newReference("log", t.Value.String(), tok))
+		case *ast.SyntheticStatement:
+			s.Synthetics = append(s.Synthetics, newReference("synthetic", t.Value.String(), tok))
+		case *ast.SyntheticBase64Statement:
+			s.Synthetics = append(s.Synthetics, newReference("synthetic.base64", t.Value.String(), tok))
+		case *ast.FunctionCallStatement:
+			s.FunctionCalls = append(s.FunctionCalls, newReference(t.Function.Value, "", tok))
+		case *ast.GotoStatement:
+			s.Gotos = append(s.Gotos, newReference(t.Destination.Value, "", tok))
+		case *ast.IncludeStatement:
+			s.Includes = append(s.Includes, newReference(t.Module.Value, "", tok))
+		}
+	}
+}
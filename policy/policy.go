@@ -0,0 +1,347 @@
+package policy
+
+import (
+	"bytes"
+	gocontext "context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Policy evaluation target kinds. Rego policies could distinguish the input document by "input.kind" field
+const (
+	KindAST   = "ast"
+	KindTrace = "trace"
+)
+
+const (
+	defaultCommand = "opa"
+	defaultQuery   = "data.falco.deny"
+	defaultTimeout = 10 * time.Second
+
+	// Number of documents which could be pending on background evaluation
+	defaultQueueSize = 256
+)
+
+// Violation represents a single policy violation that is reported from Rego policy.
+// The policy could return simple string message or object that has following fields
+type Violation struct {
+	Rule     string `json:"rule"`
+	Message  string `json:"msg"`
+	Severity string `json:"severity"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Position int    `json:"position"`
+}
+
+func (v *Violation) UnmarshalJSON(b []byte) error {
+	// Accept plain string message like `deny contains "message"`
+	var msg string
+	if err := json.Unmarshal(b, &msg); err == nil {
+		v.Message = msg
+		return nil
+	}
+
+	type alias Violation
+	var a alias
+	if err := json.Unmarshal(b, &a); err != nil {
+		return errors.WithStack(err)
+	}
+	*v = Violation(a)
+	return nil
+}
+
+type Option func(e *Evaluator)
+
+// Specify opa command path, default is "opa" which is looked up from PATH
+func WithCommand(cmd string) Option {
+	return func(e *Evaluator) {
+		e.command = cmd
+	}
+}
+
+// Specify Rego query to collect violations, default is "data.falco.deny".
+// The query must be a reference to the document under data because it is evaluated through the Data API
+func WithQuery(query string) Option {
+	return func(e *Evaluator) {
+		e.query = query
+	}
+}
+
+func WithTimeout(timeout time.Duration) Option {
+	return func(e *Evaluator) {
+		e.timeout = timeout
+	}
+}
+
+// Evaluator evaluates Rego policies via OPA command against the input document.
+// We don't embed OPA as library because it has huge dependencies,
+// so users need to install opa command like custom linter plugins.
+// Policies are compiled once by "opa run --server" process which is started on the first evaluation
+// and is kept running until Close is called, then each evaluation is a single request to the Data API.
+type Evaluator struct {
+	files   []string
+	command string
+	query   string
+	timeout time.Duration
+	client  *http.Client
+
+	mu     sync.Mutex
+	server *server
+
+	// Background evaluation queue, started on the first EvaluateAsync call
+	queueOnce sync.Once
+	queue     chan *asyncJob
+	drained   chan struct{}
+	closeOnce sync.Once
+}
+
+func New(files []string, opts ...Option) *Evaluator {
+	e := &Evaluator{
+		files:   files,
+		command: defaultCommand,
+		query:   defaultQuery,
+		timeout: defaultTimeout,
+		client:  &http.Client{},
+	}
+	for i := range opts {
+		opts[i](e)
+	}
+	return e
+}
+
+// Input document which is passed to the policy as "input"
+type document struct {
+	Kind  string `json:"kind"`
+	Input any    `json:"document"`
+}
+
+// Evaluate policies with input document and returns found violations
+func (e *Evaluator) Evaluate(kind string, input any) ([]*Violation, error) {
+	body, err := json.Marshal(struct {
+		Input document `json:"input"`
+	}{
+		Input: document{Kind: kind, Input: input},
+	})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return e.evaluate(body)
+}
+
+func (e *Evaluator) evaluate(body []byte) ([]*Violation, error) {
+	path, err := dataPath(e.query)
+	if err != nil {
+		return nil, err
+	}
+	s, err := e.start()
+	if err != nil {
+		return nil, err
+	}
+
+	c, cancel := gocontext.WithTimeout(gocontext.Background(), e.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(c, http.MethodPost, s.url+"/v1/data/"+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to evaluate policies: %w", err)
+	}
+	defer resp.Body.Close()
+
+	out, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Failed to evaluate policies: %s", bytes.TrimSpace(out))
+	}
+	return parseResult(out)
+}
+
+// Convert the query like "data.falco.deny" to the Data API path "falco/deny"
+func dataPath(query string) (string, error) {
+	path, ok := strings.CutPrefix(query, "data.")
+	if !ok || path == "" {
+		return "", fmt.Errorf(`Policy query must be a reference under data like "data.falco.deny", got "%s"`, query)
+	}
+	return strings.ReplaceAll(path, ".", "/"), nil
+}
+
+// Parse Data API response.
+// The response is formatted like {"result": [...]}, result field is omitted if the query is undefined
+func parseResult(out []byte) ([]*Violation, error) {
+	var result struct {
+		Result json.RawMessage `json:"result"`
+	}
+	if err := json.Unmarshal(out, &result); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if len(result.Result) == 0 {
+		return nil, nil
+	}
+
+	var violations []*Violation
+	if err := json.Unmarshal(result.Result, &violations); err != nil {
+		return nil, fmt.Errorf("Policy query must return a set of violations: %w", err)
+	}
+	return violations, nil
+}
+
+// Running "opa run --server" process
+type server struct {
+	cmd    *exec.Cmd
+	url    string
+	stderr *bytes.Buffer // must be read after done is closed
+	done   chan struct{}
+}
+
+// Start OPA server if not running and wait until policies are loaded.
+// The server is started again if the process has exited unexpectedly
+func (e *Evaluator) start() (*server, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.server != nil {
+		select {
+		case <-e.server.done:
+		default:
+			return e.server, nil
+		}
+	}
+
+	bin, err := exec.LookPath(e.command)
+	if err != nil {
+		return nil, fmt.Errorf(`Policy command "%s" not found in your environment`, e.command)
+	}
+	addr, err := freeAddress()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	args := []string{"run", "--server", "--addr", addr, "--log-level", "error"}
+	args = append(args, e.files...)
+	s := &server{
+		cmd:    exec.Command(bin, args...),
+		url:    "http://" + addr,
+		stderr: &bytes.Buffer{},
+		done:   make(chan struct{}),
+	}
+	s.cmd.Stderr = s.stderr
+	if err := s.cmd.Start(); err != nil {
+		return nil, fmt.Errorf("Failed to start policy server: %w", err)
+	}
+	go func() {
+		s.cmd.Wait() // nolint:errcheck
+		close(s.done)
+	}()
+
+	// OPA server responds health check after policies are compiled
+	deadline := time.Now().Add(e.timeout)
+	for {
+		select {
+		case <-s.done:
+			return nil, fmt.Errorf("Failed to start policy server: %s", bytes.TrimSpace(s.stderr.Bytes()))
+		default:
+		}
+		if resp, err := e.client.Get(s.url + "/health"); err == nil {
+			resp.Body.Close()
+			if resp.StatusCode == http.StatusOK {
+				break
+			}
+		}
+		if time.Now().After(deadline) {
+			s.stop()
+			return nil, fmt.Errorf("Policy server is not ready in %s", e.timeout)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	e.server = s
+	return s, nil
+}
+
+func (s *server) stop() {
+	s.cmd.Process.Kill() // nolint:errcheck
+	<-s.done
+}
+
+// Find the free port on loopback interface for OPA server
+func freeAddress() (string, error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	defer ln.Close()
+	return ln.Addr().String(), nil
+}
+
+type asyncJob struct {
+	body   []byte
+	report func([]*Violation, error)
+}
+
+// EvaluateAsync evaluates policies in the background so that the caller is never blocked by slow policies.
+// The input is encoded immediately, then evaluated in the enqueued order and the result is passed to report.
+// Returns false if the document is dropped because too many documents are pending
+func (e *Evaluator) EvaluateAsync(kind string, input any, report func([]*Violation, error)) bool {
+	body, err := json.Marshal(struct {
+		Input document `json:"input"`
+	}{
+		Input: document{Kind: kind, Input: input},
+	})
+	if err != nil {
+		report(nil, errors.WithStack(err))
+		return true
+	}
+
+	e.queueOnce.Do(func() {
+		e.queue = make(chan *asyncJob, defaultQueueSize)
+		e.drained = make(chan struct{})
+		go func() {
+			defer close(e.drained)
+			for job := range e.queue {
+				job.report(e.evaluate(job.body))
+			}
+		}()
+	})
+
+	select {
+	case e.queue <- &asyncJob{body: body, report: report}:
+		return true
+	default:
+		return false
+	}
+}
+
+// Close waits for pending background evaluations and stops OPA server.
+// Evaluator must not be used after closed
+func (e *Evaluator) Close() {
+	e.closeOnce.Do(func() {
+		// Prevent starting the background queue after closed
+		e.queueOnce.Do(func() {})
+		if e.queue != nil {
+			close(e.queue)
+			<-e.drained
+		}
+
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		if e.server != nil {
+			e.server.stop()
+			e.server = nil
+		}
+	})
+}
@@ -0,0 +1,182 @@
+package policy
+
+import (
+	"encoding/json"
+	"flag"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/ysugimoto/falco/v2/lexer"
+	"github.com/ysugimoto/falco/v2/parser"
+)
+
+func TestParseResult(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		expect []*Violation
+	}{
+		{
+			name:   "empty result",
+			output: `{}`,
+			expect: nil,
+		},
+		{
+			name:   "string violations",
+			output: `{"result":["backend F_origin must not be used"]}`,
+			expect: []*Violation{
+				{Message: "backend F_origin must not be used"},
+			},
+		},
+		{
+			name:   "object violations",
+			output: `{"result":[{"msg":"auth is skipped","rule":"auth","line":10,"position":3,"file":"main.vcl"}]}`,
+			expect: []*Violation{
+				{Message: "auth is skipped", Rule: "auth", Line: 10, Position: 3, File: "main.vcl"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := parseResult([]byte(tt.output))
+			if err != nil {
+				t.Errorf("Unexpected error: %s", err)
+				return
+			}
+			if diff := cmp.Diff(tt.expect, actual); diff != "" {
+				t.Errorf("Violations mismatch, diff=%s", diff)
+			}
+		})
+	}
+}
+
+func TestNewASTInput(t *testing.T) {
+	vcl, err := parser.New(lexer.NewFromString(`
+backend F_origin {
+  .host = "example.com";
+  .ssl = true;
+}
+
+sub vcl_recv {
+  if (req.http.Authorization) {
+    call auth;
+  } else {
+    set req.http.X-Anonymous = "1";
+  }
+  return (pass);
+}
+`)).ParseVCL()
+	if err != nil {
+		t.Errorf("Unexpected parse error: %s", err)
+		return
+	}
+
+	input := NewASTInput(vcl.Statements)
+	if len(input.Backends) != 1 || input.Backends[0].Name != "F_origin" {
+		t.Errorf("Backend must be collected, got %v", input.Backends)
+	} else if diff := cmp.Diff(map[string]string{"host": `"example.com"`, "ssl": "true"}, input.Backends[0].Properties); diff != "" {
+		t.Errorf("Backend properties mismatch, diff=%s", diff)
+	}
+	if len(input.Subroutines) != 1 {
+		t.Errorf("Subroutine must be collected, got %v", input.Subroutines)
+		return
+	}
+	sub := input.Subroutines[0]
+	if len(sub.Calls) != 1 || sub.Calls[0].Name != "auth" || sub.Calls[0].Line != 9 {
+		t.Errorf("Call statement in nested block must be collected, got %v", sub.Calls)
+	}
+	if len(sub.Sets) != 1 || sub.Sets[0].Name != "req.http.X-Anonymous" {
+		t.Errorf("Set statement in else block must be collected, got %v", sub.Sets)
+	}
+	if len(sub.Returns) != 1 || sub.Returns[0].Value != "pass" {
+		t.Errorf("Return statement must be collected, got %v", sub.Returns)
+	}
+	if len(sub.Conditions) != 1 || sub.Conditions[0].Value != "req.http.Authorization" {
+		t.Errorf("If condition must be collected, got %v", sub.Conditions)
+	}
+}
+
+// The test binary behaves as "opa run --server" when the environment variable is set,
+// which responds the message of the input document as the violation
+func TestMain(m *testing.M) {
+	if os.Getenv("FALCO_POLICY_TEST_SERVER") == "" {
+		os.Exit(m.Run())
+	}
+
+	fs := flag.NewFlagSet("opa", flag.ExitOnError)
+	addr := fs.String("addr", "", "")
+	fs.Bool("server", false, "")
+	fs.String("log-level", "", "")
+	fs.Parse(os.Args[2:]) // nolint:errcheck
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /health", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("POST /v1/data/falco/deny", func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Input document `json:"input"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)     // nolint:errcheck
+		json.NewEncoder(w).Encode(map[string]any{ // nolint:errcheck
+			"result": []string{body.Input.Kind + ":" + body.Input.Input.(string)},
+		})
+	})
+	http.ListenAndServe(*addr, mux) // nolint:errcheck
+	os.Exit(1)
+}
+
+func TestEvaluator(t *testing.T) {
+	t.Setenv("FALCO_POLICY_TEST_SERVER", "1")
+	e := New([]string{"policy.rego"}, WithCommand(os.Args[0]), WithTimeout(5*time.Second))
+	defer e.Close()
+
+	// Server is started once and reused on each evaluation
+	for range 2 {
+		violations, err := e.Evaluate(KindAST, "main")
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if diff := cmp.Diff([]*Violation{{Message: "ast:main"}}, violations); diff != "" {
+			t.Errorf("Violations mismatch, diff=%s", diff)
+		}
+	}
+	server := e.server
+
+	reported := make(chan string, 2)
+	for _, input := range []string{"first", "second"} {
+		ok := e.EvaluateAsync(KindTrace, input, func(violations []*Violation, err error) {
+			if err != nil {
+				reported <- err.Error()
+				return
+			}
+			reported <- violations[0].Message
+		})
+		if !ok {
+			t.Errorf("Document must be enqueued")
+		}
+	}
+	for _, expect := range []string{"trace:first", "trace:second"} {
+		if actual := <-reported; actual != expect {
+			t.Errorf("Reported violation mismatch, expect=%s, got=%s", expect, actual)
+		}
+	}
+	if e.server != server {
+		t.Errorf("Server must be reused for background evaluation")
+	}
+
+	e.Close()
+	select {
+	case <-server.done:
+	default:
+		t.Errorf("Server must be stopped on close")
+	}
+}
+
+func TestInvalidQuery(t *testing.T) {
+	e := New([]string{"policy.rego"}, WithQuery("falco.deny"))
+	if _, err := e.Evaluate(KindAST, "main"); err == nil {
+		t.Errorf("Expected error for the query which is not under data but got nil")
+	}
+}
@@ -0,0 +1,270 @@
+package reload
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/pkg/errors"
+	"github.com/ysugimoto/falco/v2/ast"
+	"github.com/ysugimoto/falco/v2/resolver"
+)
+
+// Editors may write the file several times on saving, wait for a while until events are settled
+const debounce = 100 * time.Millisecond
+
+// Validator parses and lints the program which is read from the resolver, and returns found problems
+type Validator func(rslv resolver.Resolver) []string
+
+// Status is the result of the latest reload, which is served on the status endpoint
+type Status struct {
+	// Number of reloads which are applied, 0 means the initial program
+	Version  int       `json:"version"`
+	LoadedAt time.Time `json:"loaded_at"`
+	Files    []string  `json:"files"`
+	// Problems of the latest reload. If not empty, the previous program is still used
+	Errors   []string   `json:"errors"`
+	FailedAt *time.Time `json:"failed_at,omitempty"`
+}
+
+// Reloader is the resolver which serves the snapshot of the main VCL and its include graph.
+// The snapshot is swapped atomically only when the re-parsed and re-linted program is valid,
+// so the simulator keeps serving the previous program while the files are being edited
+type Reloader struct {
+	origin   resolver.Resolver
+	validate Validator
+
+	mu       sync.RWMutex
+	snapshot *snapshot
+	valid    bool
+	status   Status
+}
+
+func New(origin resolver.Resolver, validate Validator) (*Reloader, error) {
+	r := &Reloader{
+		origin:   origin,
+		validate: validate,
+		status:   Status{Files: []string{}, Errors: []string{}},
+	}
+	// Initial program is always used even if it has problems, errors are reported on processing requests
+	if err := r.Reload(); err != nil && r.snapshot == nil {
+		return nil, errors.WithStack(err)
+	}
+	return r, nil
+}
+
+// Reload reads and validates the program from origin resolver, and swaps the snapshot if it is valid.
+// When the current program also has problems, the new one is used because it is the closest to the user's intention
+func (r *Reloader) Reload() error {
+	rec := &snapshot{
+		origin:   r.origin,
+		includes: make(map[string]*resolver.VCL),
+		record:   true,
+	}
+	var problems []string
+	if main, err := r.origin.MainVCL(); err != nil {
+		problems = append(problems, err.Error())
+	} else {
+		rec.main = main
+		problems = r.validate(rec)
+	}
+	rec.record = false
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	if len(problems) > 0 {
+		r.status.Errors = problems
+		r.status.FailedAt = &now
+		if rec.main == nil || (r.snapshot != nil && r.valid) {
+			return errors.New(strings.Join(problems, "\n"))
+		}
+	} else {
+		r.status.Errors = []string{}
+		r.status.FailedAt = nil
+	}
+
+	if r.snapshot != nil {
+		r.status.Version++
+	}
+	r.snapshot = rec
+	r.valid = len(problems) == 0
+	r.status.LoadedAt = now
+	r.status.Files = rec.files()
+
+	if len(problems) > 0 {
+		return errors.New(strings.Join(problems, "\n"))
+	}
+	return nil
+}
+
+func (r *Reloader) current() *snapshot {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.snapshot
+}
+
+// Status returns the copy of the latest reload status
+func (r *Reloader) Status() Status {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	s := r.status
+	s.Files = slices.Clone(s.Files)
+	s.Errors = slices.Clone(s.Errors)
+	return s
+}
+
+// Implements resolver.Resolver
+func (r *Reloader) MainVCL() (*resolver.VCL, error) {
+	return r.current().MainVCL()
+}
+
+func (r *Reloader) Resolve(stmt *ast.IncludeStatement) (*resolver.VCL, error) {
+	return r.current().Resolve(stmt)
+}
+
+func (r *Reloader) Name() string {
+	return r.origin.Name()
+}
+
+func (r *Reloader) IncludePaths() []string {
+	return r.origin.IncludePaths()
+}
+
+// ServeHTTP responds the reload status as JSON.
+// Status code is 200 if the latest reload succeeded, otherwise 500
+func (r *Reloader) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	status := r.Status()
+	w.Header().Set("Content-Type", "application/json")
+	if len(status.Errors) > 0 {
+		w.WriteHeader(http.StatusInternalServerError)
+	} else {
+		w.WriteHeader(http.StatusOK)
+	}
+	json.NewEncoder(w).Encode(status) // nolint:errcheck
+}
+
+// Watch reloads the program when some file in the include graph is changed until the context is canceled.
+// Parent directories are watched because editors may replace the file by renaming,
+// and watching directories are added when the include graph is changed.
+// Errors while watching are logged and watching is continued because the simulator must keep serving
+func (r *Reloader) Watch(ctx context.Context, onReload func(err error)) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer watcher.Close()
+
+	files := make(map[string]struct{})
+	watch := func() error {
+		for _, file := range r.Status().Files {
+			files[file] = struct{}{}
+			if err := watcher.Add(filepath.Dir(file)); err != nil {
+				return errors.WithStack(err)
+			}
+		}
+		return nil
+	}
+	if err := watch(); err != nil {
+		return errors.WithStack(err)
+	}
+
+	var timer <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if _, ok := files[event.Name]; !ok {
+				continue
+			}
+			if !event.Has(fsnotify.Write) && !event.Has(fsnotify.Create) && !event.Has(fsnotify.Rename) {
+				continue
+			}
+			timer = time.After(debounce)
+		case <-timer:
+			timer = nil
+			onReload(r.Reload())
+			if err := watch(); err != nil {
+				slog.Warn("Failed to watch VCL files", "error", err)
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			slog.Warn("Error occurred while watching VCL files", "error", err)
+		}
+	}
+}
+
+// snapshot holds the main VCL and included VCLs which are resolved on validation.
+// While recording, includes are resolved from origin and stored, after that only stored includes are served
+type snapshot struct {
+	origin   resolver.Resolver
+	main     *resolver.VCL
+	includes map[string]*resolver.VCL
+	record   bool
+	mu       sync.Mutex
+}
+
+func (s *snapshot) MainVCL() (*resolver.VCL, error) {
+	return &resolver.VCL{Name: s.main.Name, Data: s.main.Data}, nil
+}
+
+func (s *snapshot) Resolve(stmt *ast.IncludeStatement) (*resolver.VCL, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if v, ok := s.includes[stmt.Module.Value]; ok {
+		return &resolver.VCL{Name: v.Name, Data: v.Data}, nil
+	}
+	// Include which is not reached on validation is never read from origin after recording
+	// because the file may have been changed and the program would mix different versions
+	if !s.record {
+		return nil, errors.Errorf("Failed to resolve include file: %s is not loaded on the latest reload", stmt.Module.Value)
+	}
+	v, err := s.origin.Resolve(stmt)
+	if err != nil {
+		return nil, err
+	}
+	s.includes[stmt.Module.Value] = v
+	return &resolver.VCL{Name: v.Name, Data: v.Data}, nil
+}
+
+func (s *snapshot) Name() string {
+	return s.origin.Name()
+}
+
+func (s *snapshot) IncludePaths() []string {
+	return s.origin.IncludePaths()
+}
+
+// Absolute paths of the main VCL and included VCL files
+func (s *snapshot) files() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	names := []string{s.main.Name}
+	for _, v := range s.includes {
+		names = append(names, v.Name)
+	}
+	var files []string
+	for _, name := range names {
+		if abs, err := filepath.Abs(name); err == nil && !slices.Contains(files, abs) {
+			files = append(files, abs)
+		}
+	}
+	slices.Sort(files)
+	return files
+}
@@ -0,0 +1,136 @@
+package reload
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ysugimoto/falco/v2/ast"
+	"github.com/ysugimoto/falco/v2/resolver"
+)
+
+// Simple validator which resolves the include and reports the file which contains "BROKEN"
+func testValidator(rslv resolver.Resolver) []string {
+	var problems []string
+	main, err := rslv.MainVCL()
+	if err != nil {
+		return []string{err.Error()}
+	}
+	mod, err := rslv.Resolve(&ast.IncludeStatement{Module: &ast.String{Value: "mod"}})
+	if err != nil {
+		return []string{err.Error()}
+	}
+	for _, v := range []*resolver.VCL{main, mod} {
+		if strings.Contains(v.Data, "BROKEN") {
+			problems = append(problems, "broken "+filepath.Base(v.Name))
+		}
+	}
+	return problems
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+}
+
+func setup(t *testing.T) (string, *Reloader) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "main.vcl"), `include "mod";`)
+	writeFile(t, filepath.Join(dir, "mod.vcl"), "sub mod {}")
+
+	rslvs, err := resolver.NewFileResolvers(filepath.Join(dir, "main.vcl"), []string{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	r, err := New(rslvs[0], testValidator)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	return dir, r
+}
+
+func resolveMod(t *testing.T, r *Reloader) string {
+	t.Helper()
+	v, err := r.Resolve(&ast.IncludeStatement{Module: &ast.String{Value: "mod"}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	return v.Data
+}
+
+func TestReload(t *testing.T) {
+	dir, r := setup(t)
+
+	if len(r.Status().Files) != 2 {
+		t.Errorf("Include graph must be recorded, got=%v", r.Status().Files)
+	}
+
+	// Include which is not recorded on validation is never read from the file
+	writeFile(t, filepath.Join(dir, "other.vcl"), "sub other {}")
+	if _, err := r.Resolve(&ast.IncludeStatement{Module: &ast.String{Value: "other"}}); err == nil {
+		t.Errorf("Expected resolve error for the include which is not recorded")
+	}
+
+	// Snapshot is served until reloaded
+	writeFile(t, filepath.Join(dir, "mod.vcl"), "sub mod { set req.http.Foo = \"1\"; }")
+	if data := resolveMod(t, r); data != "sub mod {}" {
+		t.Errorf("Snapshot must be served before reloading, got=%s", data)
+	}
+	if err := r.Reload(); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if data := resolveMod(t, r); !strings.Contains(data, "Foo") {
+		t.Errorf("Reloaded program must be served, got=%s", data)
+	}
+
+	// Invalid program is not swapped and reported on status
+	writeFile(t, filepath.Join(dir, "mod.vcl"), "BROKEN")
+	if err := r.Reload(); err == nil {
+		t.Errorf("Expected reload error but got nil")
+	}
+	if data := resolveMod(t, r); !strings.Contains(data, "Foo") {
+		t.Errorf("Previous program must be kept on reload error, got=%s", data)
+	}
+	status := r.Status()
+	if status.Version != 1 || len(status.Errors) != 1 || status.FailedAt == nil {
+		t.Errorf("Unexpected status: %+v", status)
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if w.Code != http.StatusInternalServerError || !strings.Contains(w.Body.String(), "broken mod.vcl") {
+		t.Errorf("Status endpoint must report reload error, code=%d, body=%s", w.Code, w.Body.String())
+	}
+}
+
+func TestWatch(t *testing.T) {
+	dir, r := setup(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	reloaded := make(chan error, 10)
+	go r.Watch(ctx, func(err error) { reloaded <- err }) // nolint:errcheck
+
+	// Wait for the watcher is ready
+	time.Sleep(100 * time.Millisecond)
+	writeFile(t, filepath.Join(dir, "mod.vcl"), "sub mod { set req.http.Bar = \"1\"; }")
+
+	select {
+	case err := <-reloaded:
+		if err != nil {
+			t.Errorf("Unexpected error: %s", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Program is not reloaded on file change")
+	}
+	if data := resolveMod(t, r); !strings.Contains(data, "Bar") {
+		t.Errorf("Reloaded program must be served, got=%s", data)
+	}
+}
@@ -0,0 +1,185 @@
+package replay
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	FieldMethod   = "method"
+	FieldURL      = "url"
+	FieldHost     = "host"
+	FieldClientIP = "client_ip"
+	FieldStatus   = "status"
+	FieldCache    = "cache"
+	FieldBackend  = "backend"
+
+	// Prefix of the field which maps to the request header, e.g header.User-Agent
+	headerFieldPrefix = "header."
+
+	formatJSON       = "json"
+	formatJSONFields = "json-fields="
+)
+
+var knownFields = []string{
+	FieldMethod, FieldURL, FieldHost, FieldClientIP, FieldStatus, FieldCache, FieldBackend,
+}
+
+// Format describes how to convert the edge log line into the request and logged outcome.
+// Each field is mapped to the key of JSON log line, nested object key could be specified by dot separated path
+type Format struct {
+	fields  map[string]string
+	headers map[string]string
+}
+
+// ParseFormat parses format specifier.
+// "json" uses field names as JSON keys, and "json-fields=method:req_method,status:resp_status,..." maps the field to the key
+func ParseFormat(spec string) (*Format, error) {
+	f := &Format{
+		fields:  make(map[string]string),
+		headers: make(map[string]string),
+	}
+	for _, field := range knownFields {
+		f.fields[field] = field
+	}
+
+	switch {
+	case spec == formatJSON:
+		return f, nil
+	case strings.HasPrefix(spec, formatJSONFields):
+		for _, pair := range strings.Split(strings.TrimPrefix(spec, formatJSONFields), ",") {
+			pair = strings.TrimSpace(pair)
+			if pair == "" {
+				continue
+			}
+			field, key, ok := strings.Cut(pair, ":")
+			if !ok || key == "" {
+				return nil, errors.Errorf("invalid field mapping %q, must be field:key", pair)
+			}
+			if strings.HasPrefix(field, headerFieldPrefix) {
+				f.headers[strings.TrimPrefix(field, headerFieldPrefix)] = key
+				continue
+			}
+			if _, ok := f.fields[field]; !ok {
+				return nil, errors.Errorf(
+					"unknown field %q, must be one of %s or header.<name>", field, strings.Join(knownFields, ", "),
+				)
+			}
+			f.fields[field] = key
+		}
+		return f, nil
+	default:
+		return nil, errors.Errorf("unsupported log format %q, must be json or json-fields=...", spec)
+	}
+}
+
+// Entry is the converted log line
+type Entry struct {
+	Request *http.Request
+
+	// Logged outcomes, empty value means the outcome is not logged
+	Status  int
+	Cache   string
+	Backend string
+}
+
+// Parse converts log line to the entry
+func (f *Format) Parse(line []byte) (*Entry, error) {
+	var log map[string]any
+	dec := json.NewDecoder(bytes.NewReader(line))
+	dec.UseNumber()
+	if err := dec.Decode(&log); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	method := f.lookup(log, FieldMethod)
+	if method == "" {
+		method = http.MethodGet
+	}
+	rawURL := f.lookup(log, FieldURL)
+	if rawURL == "" {
+		return nil, errors.Errorf("url field %q is not found", f.fields[FieldURL])
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	// Log usually has only path and query, complement scheme and host to send to the simulator
+	if u.Scheme == "" {
+		u.Scheme = "http"
+	}
+	if host := f.lookup(log, FieldHost); host != "" {
+		u.Host = host
+	} else if u.Host == "" {
+		u.Host = "localhost"
+	}
+
+	req, err := http.NewRequest(method, u.String(), nil)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if ip := f.lookup(log, FieldClientIP); ip != "" {
+		req.RemoteAddr = net.JoinHostPort(ip, "0")
+	}
+	for name, key := range f.headers {
+		if v := lookupPath(log, key); v != "" {
+			req.Header.Set(name, v)
+		}
+	}
+
+	entry := &Entry{
+		Request: req,
+		Cache:   normalizeCacheState(f.lookup(log, FieldCache)),
+		Backend: f.lookup(log, FieldBackend),
+	}
+	if status := f.lookup(log, FieldStatus); status != "" {
+		if entry.Status, err = strconv.Atoi(status); err != nil {
+			return nil, errors.Errorf("invalid status %q", status)
+		}
+	}
+	return entry, nil
+}
+
+func (f *Format) lookup(log map[string]any, field string) string {
+	return lookupPath(log, f.fields[field])
+}
+
+// Lookup value by dot separated key path
+func lookupPath(log map[string]any, path string) string {
+	var v any = log
+	for _, key := range strings.Split(path, ".") {
+		m, ok := v.(map[string]any)
+		if !ok {
+			return ""
+		}
+		if v, ok = m[key]; !ok {
+			return ""
+		}
+	}
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return t
+	default:
+		return fmt.Sprint(t)
+	}
+}
+
+// Fastly logs cache state like HIT-CLUSTER, MISS-CLUSTER, HIT-STALE and so on.
+// Normalize them to HIT, MISS, PASS and ERROR to compare with the simulator
+func normalizeCacheState(state string) string {
+	state = strings.ToUpper(strings.TrimSpace(state))
+	if prefix, _, ok := strings.Cut(state, "-"); ok {
+		return prefix
+	}
+	return state
+}
@@ -0,0 +1,293 @@
+package replay
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/ysugimoto/falco/v2/interpreter/process"
+)
+
+// Maximum size of single log line
+const maxLineSize = 1024 * 1024
+
+// Simulator is the handler which can expose the process record of the last handled request.
+// *interpreter.Interpreter satisfies this interface
+type Simulator interface {
+	http.Handler
+	Process() *process.Process
+}
+
+// Divergence is the difference between logged and simulated outcome of the log line
+type Divergence struct {
+	Line      int    `json:"line"`
+	Request   string `json:"request"`
+	Field     string `json:"field"`
+	Logged    string `json:"logged"`
+	Simulated string `json:"simulated"`
+}
+
+func (d *Divergence) String() string {
+	return fmt.Sprintf("line %d %s %s: logged=%q, simulated=%q", d.Line, d.Request, d.Field, d.Logged, d.Simulated)
+}
+
+// Report is the aggregated result of replaying
+type Report struct {
+	Total   int `json:"total"`
+	Matched int `json:"matched"`
+	// Lines which could not be converted to the request
+	Skipped int `json:"skipped"`
+	// Divergence count for each field
+	Fields map[string]int `json:"fields"`
+	// Divergence samples, limited by the option
+	Samples []*Divergence `json:"samples"`
+	// Latency budget violations for each annotated route in order of count
+	Budgets []*RouteBudget `json:"budget_violations,omitempty"`
+	// Projected cache efficiency of the simulated cache
+	Cache *CacheStats `json:"cache"`
+	// Projected cache efficiency under the proposed VCL, only present when it is compared
+	ProposedCache *CacheStats `json:"proposed_cache,omitempty"`
+}
+
+// CacheStats is the projected cache efficiency which is measured by simulated cache states of replayed requests.
+// Body bytes of HIT responses are served from the cache, and ones of MISS and PASS responses are fetched from the origin
+type CacheStats struct {
+	Hits        int   `json:"hits"`
+	Misses      int   `json:"misses"`
+	Passes      int   `json:"passes"`
+	HitBytes    int64 `json:"hit_bytes"`
+	OriginBytes int64 `json:"origin_bytes"`
+	// Ratio of HIT in cacheable requests, PASS is not counted
+	HitRatio float64 `json:"hit_ratio"`
+	// Ratio of requests which are not sent to the origin in requests which reach to the cache lookup
+	OriginOffload float64 `json:"origin_offload"`
+	// Ratio of body bytes which are served from the cache
+	BandwidthSavings float64 `json:"bandwidth_savings"`
+}
+
+func (s *CacheStats) add(state string, bytes int64) {
+	switch state {
+	case "HIT":
+		s.Hits++
+		s.HitBytes += bytes
+	case "MISS":
+		s.Misses++
+		s.OriginBytes += bytes
+	case "PASS":
+		s.Passes++
+		s.OriginBytes += bytes
+	}
+}
+
+func (s *CacheStats) calculate() {
+	ratio := func(a, b int64) float64 {
+		if b == 0 {
+			return 0
+		}
+		return float64(a) / float64(b)
+	}
+	s.HitRatio = ratio(int64(s.Hits), int64(s.Hits+s.Misses))
+	s.OriginOffload = ratio(int64(s.Hits), int64(s.Hits+s.Misses+s.Passes))
+	s.BandwidthSavings = ratio(s.HitBytes, s.HitBytes+s.OriginBytes)
+}
+
+// RouteBudget is the aggregated latency budget violations of the route
+type RouteBudget struct {
+	Route      string `json:"route"`
+	File       string `json:"file,omitempty"`
+	Line       int    `json:"line"`
+	Budget     string `json:"budget"`
+	Violations int    `json:"violations"`
+	// Worst simulated TTFB of violated requests
+	Worst string `json:"worst"`
+
+	worst time.Duration
+}
+
+func (b *RouteBudget) String() string {
+	return fmt.Sprintf("%s: %d requests exceeded TTFB budget %s, worst %s", b.Route, b.Violations, b.Budget, b.Worst)
+}
+
+// Fidelity returns the ratio of the requests which simulator outcomes match the logged outcomes
+func (r *Report) Fidelity() float64 {
+	if r.Total == 0 {
+		return 0
+	}
+	return float64(r.Matched) / float64(r.Total)
+}
+
+// DivergedFields returns diverged field names in order of count
+func (r *Report) DivergedFields() []string {
+	fields := make([]string, 0, len(r.Fields))
+	for field := range r.Fields {
+		fields = append(fields, field)
+	}
+	sort.Slice(fields, func(i, j int) bool {
+		if r.Fields[fields[i]] == r.Fields[fields[j]] {
+			return fields[i] < fields[j]
+		}
+		return r.Fields[fields[i]] > r.Fields[fields[j]]
+	})
+	return fields
+}
+
+type Option func(r *Replayer)
+
+// WithSamples sets the maximum number of divergence samples in the report
+func WithSamples(n int) Option {
+	return func(r *Replayer) {
+		r.samples = n
+	}
+}
+
+// Replayer converts edge log lines to simulated requests and compares the outcomes
+type Replayer struct {
+	simulator Simulator
+	format    *Format
+	samples   int
+}
+
+func New(s Simulator, f *Format, opts ...Option) *Replayer {
+	r := &Replayer{
+		simulator: s,
+		format:    f,
+		samples:   10,
+	}
+	for i := range opts {
+		opts[i](r)
+	}
+	return r
+}
+
+// Replay reads log lines from reader and runs them through the simulator
+func (r *Replayer) Replay(rd io.Reader) (*Report, error) {
+	report := &Report{
+		Fields: make(map[string]int),
+		Cache:  &CacheStats{},
+	}
+	budgets := make(map[string]*RouteBudget)
+
+	scanner := bufio.NewScanner(rd)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+	var line int
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		entry, err := r.format.Parse(scanner.Bytes())
+		if err != nil {
+			report.Skipped++
+			continue
+		}
+
+		report.Total++
+		divergences := r.replay(entry, report.Cache)
+		r.aggregateBudgets(budgets)
+		if len(divergences) == 0 {
+			report.Matched++
+			continue
+		}
+		for _, d := range divergences {
+			d.Line = line
+			report.Fields[d.Field]++
+			if len(report.Samples) < r.samples {
+				report.Samples = append(report.Samples, d)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	report.Cache.calculate()
+
+	for _, b := range budgets {
+		b.Worst = b.worst.String()
+		report.Budgets = append(report.Budgets, b)
+	}
+	sort.Slice(report.Budgets, func(i, j int) bool {
+		if report.Budgets[i].Violations == report.Budgets[j].Violations {
+			return report.Budgets[i].Route < report.Budgets[j].Route
+		}
+		return report.Budgets[i].Violations > report.Budgets[j].Violations
+	})
+	return report, nil
+}
+
+// Aggregate latency budget violations of the last simulated request for each route
+func (r *Replayer) aggregateBudgets(budgets map[string]*RouteBudget) {
+	p := r.simulator.Process()
+	if p == nil {
+		return
+	}
+	for _, v := range p.BudgetViolations {
+		b, ok := budgets[v.Route]
+		if !ok {
+			b = &RouteBudget{
+				Route:  v.Route,
+				File:   v.File,
+				Line:   v.Line,
+				Budget: v.Budget,
+			}
+			budgets[v.Route] = b
+		}
+		b.Violations++
+		if p.TTFB > b.worst {
+			b.worst = p.TTFB
+		}
+	}
+}
+
+func (r *Replayer) replay(entry *Entry, stats *CacheStats) []*Divergence {
+	rec := httptest.NewRecorder()
+	r.simulator.ServeHTTP(rec, entry.Request)
+
+	var cache, backend string
+	if p := r.simulator.Process(); p != nil {
+		cache = simulatedCacheState(p)
+		if p.Backend != nil {
+			backend = p.Backend.String()
+		}
+	}
+	stats.add(cache, int64(rec.Body.Len()))
+
+	request := entry.Request.Method + " " + entry.Request.URL.RequestURI()
+	var divergences []*Divergence
+	// Only compare logged outcomes
+	add := func(field, logged, simulated string) {
+		if logged == "" || logged == simulated {
+			return
+		}
+		divergences = append(divergences, &Divergence{
+			Request:   request,
+			Field:     field,
+			Logged:    logged,
+			Simulated: simulated,
+		})
+	}
+	if entry.Status > 0 {
+		add(FieldStatus, strconv.Itoa(entry.Status), strconv.Itoa(rec.Code))
+	}
+	add(FieldCache, entry.Cache, cache)
+	add(FieldBackend, entry.Backend, backend)
+	return divergences
+}
+
+// Request which does not reach to the cache lookup, e.g error in vcl_recv, is logged as ERROR state
+func simulatedCacheState(p *process.Process) string {
+	if decision := p.CacheDecision(); decision != "" {
+		return decision
+	}
+	for _, t := range p.Transitions {
+		if t.To == "ERROR" {
+			return "ERROR"
+		}
+	}
+	return ""
+}
@@ -0,0 +1,174 @@
+package replay
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ysugimoto/falco/v2/interpreter/process"
+)
+
+type fakeSimulator struct {
+	process *process.Process
+}
+
+// Respond 404 for /missing path with MISS, and 200 for others with HIT. Body is the request path.
+// Simulated TTFB is given by "ttfb" query and violates 100ms budget of the "api" route
+func (f *fakeSimulator) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.process = process.New(time.Now())
+	if ttfb, err := time.ParseDuration(r.URL.Query().Get("ttfb")); err == nil {
+		f.process.TTFB = ttfb
+		f.process.Budgets = []*process.Budget{{Route: "api", Line: 1, TTFB: 100 * time.Millisecond}}
+		f.process.BudgetViolations = f.process.CheckBudgets()
+	}
+	if r.URL.Path == "/missing" {
+		f.process.Transitions = append(f.process.Transitions, process.NewTransition("HASH", "MISS", nil))
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(r.URL.Path)) // nolint:errcheck
+		return
+	}
+	f.process.Transitions = append(f.process.Transitions, process.NewTransition("HASH", "HIT", nil))
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(r.URL.Path)) // nolint:errcheck
+}
+
+func (f *fakeSimulator) Process() *process.Process {
+	return f.process
+}
+
+func TestParseFormat(t *testing.T) {
+	t.Run("json format uses field names", func(t *testing.T) {
+		f, err := ParseFormat("json")
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		entry, err := f.Parse([]byte(`{"method":"POST","url":"/foo?bar=baz","host":"example.com","status":201,"cache":"miss-cluster"}`))
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if entry.Request.Method != "POST" || entry.Request.URL.String() != "http://example.com/foo?bar=baz" {
+			t.Errorf("Unexpected request %s %s", entry.Request.Method, entry.Request.URL)
+		}
+		if entry.Status != 201 || entry.Cache != "MISS" || entry.Backend != "" {
+			t.Errorf("Unexpected outcome %d %s %s", entry.Status, entry.Cache, entry.Backend)
+		}
+	})
+
+	t.Run("json-fields format maps keys", func(t *testing.T) {
+		f, err := ParseFormat("json-fields=url:req.path,status:resp.status,header.User-Agent:ua,client_ip:ip")
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		entry, err := f.Parse([]byte(`{"req":{"path":"/"},"resp":{"status":"200"},"ua":"curl","ip":"192.0.2.1"}`))
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if entry.Request.Header.Get("User-Agent") != "curl" || entry.Request.RemoteAddr != "192.0.2.1:0" {
+			t.Errorf("Unexpected request header or client ip")
+		}
+		if entry.Status != 200 {
+			t.Errorf("Unexpected status %d", entry.Status)
+		}
+
+		entry, err = f.Parse([]byte(`{"req":{"path":"/"},"ip":"2001:db8::1"}`))
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if entry.Request.RemoteAddr != "[2001:db8::1]:0" {
+			t.Errorf("Unexpected IPv6 client address %s", entry.Request.RemoteAddr)
+		}
+	})
+
+	t.Run("invalid format", func(t *testing.T) {
+		for _, spec := range []string{"csv", "json-fields=unknown:foo", "json-fields=url"} {
+			if _, err := ParseFormat(spec); err == nil {
+				t.Errorf("Expected error for %s", spec)
+			}
+		}
+	})
+}
+
+func TestReplay(t *testing.T) {
+	f, err := ParseFormat("json")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	log := strings.Join([]string{
+		`{"url":"/","status":200,"cache":"HIT"}`,
+		`{"url":"/missing","status":404,"cache":"MISS-CLUSTER"}`,
+		`{"url":"/changed","status":301,"cache":"PASS"}`,
+		`not a json line`,
+		``,
+		`{"url":"/","status":200}`,
+	}, "\n")
+
+	report, err := New(&fakeSimulator{}, f, WithSamples(1)).Replay(strings.NewReader(log))
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if report.Total != 4 || report.Matched != 3 || report.Skipped != 1 {
+		t.Errorf("Unexpected report total=%d matched=%d skipped=%d", report.Total, report.Matched, report.Skipped)
+	}
+	if report.Fields[FieldStatus] != 1 || report.Fields[FieldCache] != 1 {
+		t.Errorf("Unexpected divergence fields %v", report.Fields)
+	}
+	if len(report.Samples) != 1 || report.Samples[0].Line != 3 {
+		t.Errorf("Samples should be limited to 1 and point line 3, got %v", report.Samples)
+	}
+	if report.Fidelity() != 0.75 {
+		t.Errorf("Unexpected fidelity %f", report.Fidelity())
+	}
+}
+
+func TestReplayBudgets(t *testing.T) {
+	f, err := ParseFormat("json")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	log := strings.Join([]string{
+		`{"url":"/?ttfb=50ms"}`,
+		`{"url":"/?ttfb=150ms"}`,
+		`{"url":"/?ttfb=300ms"}`,
+		`{"url":"/"}`,
+	}, "\n")
+
+	report, err := New(&fakeSimulator{}, f).Replay(strings.NewReader(log))
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if len(report.Budgets) != 1 {
+		t.Fatalf("Expected 1 route budget, got %d", len(report.Budgets))
+	}
+	if b := report.Budgets[0]; b.Route != "api" || b.Violations != 2 || b.Budget != "100ms" || b.Worst != "300ms" {
+		t.Errorf("Unexpected route budget %s", b)
+	}
+}
+
+func TestReplayCache(t *testing.T) {
+	f, err := ParseFormat("json")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	log := strings.Join([]string{
+		`{"url":"/"}`,
+		`{"url":"/"}`,
+		`{"url":"/"}`,
+		`{"url":"/missing"}`,
+	}, "\n")
+
+	report, err := New(&fakeSimulator{}, f).Replay(strings.NewReader(log))
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	c := report.Cache
+	if c.Hits != 3 || c.Misses != 1 || c.Passes != 0 || c.HitBytes != 3 || c.OriginBytes != 8 {
+		t.Errorf("Unexpected cache stats %+v", c)
+	}
+	if c.HitRatio != 0.75 || c.OriginOffload != 0.75 {
+		t.Errorf("Unexpected hit ratio %f, origin offload %f", c.HitRatio, c.OriginOffload)
+	}
+	if c.BandwidthSavings != 3.0/11 {
+		t.Errorf("Unexpected bandwidth savings %f", c.BandwidthSavings)
+	}
+}
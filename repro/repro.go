@@ -0,0 +1,118 @@
+// Package repro provides the file format to reproduce failed test.
+// The file is written on test failure and "falco repro" command reloads it to re-execute the failed test
+package repro
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Version of reproduction file format, increment when the format is changed incompatibly
+const Version = 1
+
+// File extension of reproduction file
+const Extension = ".repro.json"
+
+// HTTP represents request or response state
+type HTTP struct {
+	Method     string      `json:"method,omitempty"`
+	URL        string      `json:"url,omitempty"`
+	RemoteAddr string      `json:"remote_addr,omitempty"`
+	StatusCode int         `json:"status_code,omitempty"`
+	Header     http.Header `json:"header"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// Mock represents mocked subroutine in the testing
+type Mock struct {
+	From       string `json:"from"`
+	To         string `json:"to"`
+	Functional bool   `json:"functional,omitempty"`
+}
+
+// Variable represents typed value of local or override variable.
+// Value is formatted as the string which could be parsed by the type
+type Variable struct {
+	Type   string `json:"type"`
+	Value  string `json:"value"`
+	NotSet bool   `json:"not_set,omitempty"`
+}
+
+// CacheItem represents cached object in the interpreter
+type CacheItem struct {
+	Hash          string      `json:"hash"`
+	Response      *HTTP       `json:"resp"`
+	RequestHeader http.Header `json:"req_header,omitempty"`
+	EntryTime     time.Time   `json:"entry_time"`
+	Expires       time.Time   `json:"expires"`
+	Hits          int         `json:"hits,omitempty"`
+}
+
+type Repro struct {
+	Version int `json:"version"`
+
+	// Test identification
+	Main         string   `json:"main"`
+	IncludePaths []string `json:"include_paths,omitempty"`
+	TestFile     string   `json:"test_file"`
+	Group        string   `json:"group,omitempty"`
+	Suite        string   `json:"suite"`
+	Scope        string   `json:"scope"`
+	Error        string   `json:"error,omitempty"`
+
+	// Iteration of the test which is generated by @table or @case annotation.
+	// Iteration is the index in declaration order and Entry is the table key or case name
+	Iteration int    `json:"iteration,omitempty"`
+	Entry     string `json:"entry,omitempty"`
+
+	// Interpreter context state at the time before the test is processed
+	Seed            int64      `json:"seed"`
+	FixedTime       *time.Time `json:"fixed_time,omitempty"`
+	Request         *HTTP      `json:"req,omitempty"`
+	BackendRequest  *HTTP      `json:"bereq,omitempty"`
+	BackendResponse *HTTP      `json:"beresp,omitempty"`
+	Response        *HTTP      `json:"resp,omitempty"`
+	Mocks           []*Mock    `json:"mocks,omitempty"`
+
+	// Local variables which are declared by hooks, override variables and cached objects
+	// are also the state of the context. ACL and REGEX values are not recorded
+	LocalVariables    map[string]*Variable `json:"locals,omitempty"`
+	OverrideVariables map[string]*Variable `json:"overrides,omitempty"`
+	Cache             []*CacheItem         `json:"cache,omitempty"`
+}
+
+func (r *Repro) WriteFile(path string) error {
+	b, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(os.WriteFile(path, b, 0o644))
+}
+
+func ReadFile(path string) (*Repro, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	var r Repro
+	if err := json.Unmarshal(b, &r); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if r.Version != Version {
+		return nil, errors.Errorf("Unsupported reproduction file version %d", r.Version)
+	}
+	return &r, nil
+}
+
+var fileNameReplacer = regexp.MustCompile(`[^a-zA-Z0-9_\-.]+`)
+
+// FileName returns safe file name for the reproduction name
+func FileName(name string) string {
+	return strings.Trim(fileNameReplacer.ReplaceAllString(name, "_"), "_") + Extension
+}
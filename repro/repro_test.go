@@ -0,0 +1,56 @@
+package repro
+
+import (
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestReadWriteFile(t *testing.T) {
+	fixed := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	r := &Repro{
+		Version:   Version,
+		Main:      "default.vcl",
+		TestFile:  "default.test.vcl",
+		Suite:     "test_recv",
+		Scope:     "RECV",
+		Seed:      42,
+		FixedTime: &fixed,
+		Request: &HTTP{
+			Method: http.MethodGet,
+			URL:    "http://localhost/",
+			Header: http.Header{"X-Foo": {"bar"}},
+		},
+		Mocks: []*Mock{{From: "add_header", To: "mock_add_header"}},
+		LocalVariables: map[string]*Variable{
+			"var.count": {Type: "INTEGER", Value: "10"},
+		},
+		OverrideVariables: map[string]*Variable{
+			"client.geo.country_code": {Type: "STRING", Value: "JP"},
+		},
+		Cache: []*CacheItem{
+			{
+				Hash:      "hash",
+				Response:  &HTTP{StatusCode: http.StatusOK, Header: http.Header{}},
+				EntryTime: fixed,
+				Expires:   fixed.Add(time.Hour),
+				Hits:      1,
+			},
+		},
+	}
+
+	path := filepath.Join(t.TempDir(), "test"+Extension)
+	if err := r.WriteFile(path); err != nil {
+		t.Fatalf("Unexpected write error: %s", err)
+	}
+	actual, err := ReadFile(path)
+	if err != nil {
+		t.Fatalf("Unexpected read error: %s", err)
+	}
+	if diff := cmp.Diff(r, actual); diff != "" {
+		t.Errorf("Read reproduction mismatch, diff=%s", diff)
+	}
+}
@@ -0,0 +1,169 @@
+package shadow
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/ysugimoto/falco/v2/interpreter/process"
+)
+
+// Headers which always differ between runs so they are not compared as default
+var defaultIgnoreHeaders = []string{"Date", "X-Timer", "Fastly-Debug-Digest"}
+
+// Simulator is the handler which can expose the process record of the last handled request.
+// *interpreter.Interpreter satisfies this interface
+type Simulator interface {
+	http.Handler
+	Process() *process.Process
+}
+
+// Result is the comparable outcome of the simulated request
+type Result struct {
+	Status        int
+	CacheDecision string
+	Backend       string
+	Header        http.Header
+	Error         string
+}
+
+// Divergence is the difference of the outcome between A and B programs
+type Divergence struct {
+	Field string
+	A     string
+	B     string
+}
+
+func (d *Divergence) String() string {
+	return fmt.Sprintf("%s: a=%q, b=%q", d.Field, d.A, d.B)
+}
+
+// Reporter receives the divergences of the request. Called only when divergences are found
+type Reporter func(r *http.Request, divergences []*Divergence)
+
+type Option func(h *Handler)
+
+func WithIgnoreHeaders(headers []string) Option {
+	return func(h *Handler) {
+		for _, name := range headers {
+			h.ignoreHeaders[http.CanonicalHeaderKey(name)] = struct{}{}
+		}
+	}
+}
+
+// Handler runs every incoming request through both A and B programs,
+// responds the A's response to the client and reports divergences between them
+type Handler struct {
+	a, b          Simulator
+	report        Reporter
+	ignoreHeaders map[string]struct{}
+	mu            sync.Mutex
+}
+
+func New(a, b Simulator, report Reporter, opts ...Option) *Handler {
+	h := &Handler{
+		a:             a,
+		b:             b,
+		report:        report,
+		ignoreHeaders: make(map[string]struct{}),
+	}
+	for _, name := range defaultIgnoreHeaders {
+		h.ignoreHeaders[name] = struct{}{}
+	}
+	for i := range opts {
+		opts[i](h)
+	}
+	return h
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// Both programs must receive the same request body
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	r.Body.Close()
+
+	// Process record is overwritten by the following request so serialize them
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	recA, resultA := serve(h.a, r, body)
+	_, resultB := serve(h.b, r, body)
+
+	if divergences := h.Compare(resultA, resultB); len(divergences) > 0 && h.report != nil {
+		h.report(r, divergences)
+	}
+
+	for key, values := range recA.Header() {
+		for _, v := range values {
+			w.Header().Add(key, v)
+		}
+	}
+	w.WriteHeader(recA.Code)
+	w.Write(recA.Body.Bytes()) // nolint:errcheck
+}
+
+func serve(s Simulator, r *http.Request, body []byte) (*httptest.ResponseRecorder, *Result) {
+	req := r.Clone(r.Context())
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, req)
+
+	result := &Result{
+		Status: rec.Code,
+		Header: rec.Header().Clone(),
+	}
+	if p := s.Process(); p != nil {
+		result.CacheDecision = p.CacheDecision()
+		if p.Backend != nil {
+			result.Backend = p.Backend.String()
+		}
+		if p.Error != nil {
+			result.Error = p.Error.Error()
+		}
+	}
+	return rec, result
+}
+
+// Compare returns divergences between A and B results
+func (h *Handler) Compare(a, b *Result) []*Divergence {
+	var divergences []*Divergence
+	add := func(field, va, vb string) {
+		if va != vb {
+			divergences = append(divergences, &Divergence{Field: field, A: va, B: vb})
+		}
+	}
+
+	add("status", strconv.Itoa(a.Status), strconv.Itoa(b.Status))
+	add("cache", a.CacheDecision, b.CacheDecision)
+	add("backend", a.Backend, b.Backend)
+	add("error", a.Error, b.Error)
+
+	names := make(map[string]struct{})
+	for name := range a.Header {
+		names[name] = struct{}{}
+	}
+	for name := range b.Header {
+		names[name] = struct{}{}
+	}
+	keys := make([]string, 0, len(names))
+	for name := range names {
+		if _, ok := h.ignoreHeaders[name]; ok {
+			continue
+		}
+		keys = append(keys, name)
+	}
+	sort.Strings(keys)
+	for _, name := range keys {
+		add("header "+name, strings.Join(a.Header.Values(name), ", "), strings.Join(b.Header.Values(name), ", "))
+	}
+	return divergences
+}
@@ -0,0 +1,75 @@
+package shadow
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ysugimoto/falco/v2/interpreter/process"
+)
+
+type fakeSimulator struct {
+	status  int
+	header  map[string]string
+	cache   string
+	process *process.Process
+}
+
+func (f *fakeSimulator) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body) // nolint:errcheck
+	f.process = process.New(time.Now())
+	f.process.Transitions = append(f.process.Transitions, process.NewTransition("HASH", f.cache, nil))
+	for k, v := range f.header {
+		w.Header().Set(k, v)
+	}
+	w.WriteHeader(f.status)
+	w.Write(body) // nolint:errcheck
+}
+
+func (f *fakeSimulator) Process() *process.Process {
+	return f.process
+}
+
+func TestShadowHandler(t *testing.T) {
+	a := &fakeSimulator{status: 200, cache: "HIT", header: map[string]string{"X-Foo": "foo", "Date": "a"}}
+	b := &fakeSimulator{status: 200, cache: "MISS", header: map[string]string{"X-Foo": "bar", "Date": "b", "X-Ignored": "1"}}
+
+	var reported []*Divergence
+	h := New(a, b, func(r *http.Request, d []*Divergence) {
+		reported = d
+	}, WithIgnoreHeaders([]string{"x-ignored"}))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "http://localhost/", strings.NewReader("body")))
+
+	if rec.Code != 200 || rec.Body.String() != "body" || rec.Header().Get("X-Foo") != "foo" {
+		t.Errorf("Response of A program should be sent to the client")
+	}
+
+	expects := []string{"cache", "header X-Foo"}
+	if len(reported) != len(expects) {
+		t.Fatalf("Expected %d divergences but got %v", len(expects), reported)
+	}
+	for i := range expects {
+		if reported[i].Field != expects[i] {
+			t.Errorf("Unexpected divergence field %s, expected %s", reported[i].Field, expects[i])
+		}
+	}
+}
+
+func TestShadowHandlerNoDivergence(t *testing.T) {
+	a := &fakeSimulator{status: 200, cache: "PASS"}
+	b := &fakeSimulator{status: 200, cache: "PASS"}
+
+	var called bool
+	h := New(a, b, func(r *http.Request, d []*Divergence) {
+		called = true
+	})
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://localhost/", nil))
+	if called {
+		t.Errorf("Reporter should not be called when no divergence found")
+	}
+}
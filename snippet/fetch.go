@@ -1,7 +1,8 @@
 package snippet
 
 import (
-	"fmt"
+	"context"
+	"log/slog"
 	"sort"
 	"strings"
 
@@ -14,79 +15,80 @@ import (
 // Terraform Fetcher - Terraform Planned Result
 type Fetcher interface {
 	// Caching methods
-	LookupCache(bool) *Snippets
-	WriteCache(*Snippets)
+	LookupCache(context.Context, bool) *Snippets
+	WriteCache(context.Context, *Snippets)
 
 	// Resource fetching methods
-	Backends() ([]*Backend, error)
-	Directors() ([]*Director, error)
-	Dictionaries() ([]*Dictionary, error)
-	Acls() ([]*Acl, error)
-	Conditions() ([]*Condition, error)
-	Snippets() ([]*VCLSnippet, error)
-	Headers() ([]*Header, error)
-	ResponseObjects() ([]*ResponseObject, error)
-	RequestSetting() (*RequestSetting, error)
-	LoggingEndpoints() ([]string, error)
+	Backends(context.Context) ([]*Backend, error)
+	Directors(context.Context) ([]*Director, error)
+	Dictionaries(context.Context) ([]*Dictionary, error)
+	Acls(context.Context) ([]*Acl, error)
+	Conditions(context.Context) ([]*Condition, error)
+	Snippets(context.Context) ([]*VCLSnippet, error)
+	Headers(context.Context) ([]*Header, error)
+	ResponseObjects(context.Context) ([]*ResponseObject, error)
+	RequestSetting(context.Context) (*RequestSetting, error)
+	LoggingEndpoints(context.Context) ([]string, error)
 }
 
-func Fetch(fetcher Fetcher) (*Snippets, error) {
+// Fetch fetches all resources concurrently. If one of them fails, remaining requests are cancelled via the context
+func Fetch(ctx context.Context, fetcher Fetcher) (*Snippets, error) {
 	snippets := &Snippets{
 		ScopedSnippets:   ScopedSnippets{},
 		IncludeSnippets:  IncludeSnippets{},
 		LoggingEndpoints: LoggingEndpoints{},
 	}
 
-	var eg errgroup.Group
+	eg, ctx := errgroup.WithContext(ctx)
 
-	fmt.Print("Fething snippets...")
+	slog.Info("Fetching snippets")
 	eg.Go(func() (err error) {
-		snippets.Dictionaries, err = fetchEdgeDictionary(fetcher)
+		snippets.Dictionaries, err = fetchEdgeDictionary(ctx, fetcher)
 		return err
 	})
 	eg.Go(func() (err error) {
-		snippets.Acls, err = fetchAccessControl(fetcher)
+		snippets.Acls, err = fetchAccessControl(ctx, fetcher)
 		return err
 	})
 	eg.Go(func() (err error) {
-		snippets.Backends, err = fetchBackend(fetcher)
+		snippets.Backends, err = fetchBackend(ctx, fetcher)
 		return err
 	})
 	eg.Go(func() (err error) {
-		snippets.Directors, err = fetchDirector(fetcher)
+		snippets.Directors, err = fetchDirector(ctx, fetcher)
 		return err
 	})
 	eg.Go(func() (err error) {
-		snippets.ScopedSnippets, snippets.IncludeSnippets, err = fetchVCLSnippets(fetcher)
+		snippets.ScopedSnippets, snippets.IncludeSnippets, err = fetchVCLSnippets(ctx, fetcher)
 		return err
 	})
 	eg.Go(func() (err error) {
-		snippets.Conditions, err = fetchConditions(fetcher)
+		snippets.Conditions, err = fetchConditions(ctx, fetcher)
 		return err
 	})
 	eg.Go(func() (err error) {
-		snippets.Headers, err = fetcher.Headers()
+		snippets.Headers, err = fetcher.Headers(ctx)
 		return err
 	})
 	eg.Go(func() (err error) {
-		snippets.ResponseObjects, err = fetcher.ResponseObjects()
+		snippets.ResponseObjects, err = fetcher.ResponseObjects(ctx)
 		return err
 	})
 	eg.Go(func() (err error) {
-		snippets.RequestSetting, err = fetcher.RequestSetting()
+		snippets.RequestSetting, err = fetcher.RequestSetting(ctx)
 		return err
 	})
 
 	if err := eg.Wait(); err != nil {
-		fmt.Println("Error!")
+		slog.Error("Failed to fetch snippets", "error", err)
 		return nil, errors.WithStack(err)
 	}
-	fmt.Println("Done.")
+	slog.Info("Snippets fetched")
 	return snippets, nil
 }
 
-func fetchEdgeDictionary(fetcher Fetcher) ([]Item, error) {
-	dicts, err := fetcher.Dictionaries()
+func fetchEdgeDictionary(ctx context.Context, fetcher Fetcher) ([]Item, error) {
+	dicts, err := fetcher.Dictionaries(ctx)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -102,8 +104,8 @@ func fetchEdgeDictionary(fetcher Fetcher) ([]Item, error) {
 	return snippets, nil
 }
 
-func fetchAccessControl(fetcher Fetcher) ([]Item, error) {
-	acls, err := fetcher.Acls()
+func fetchAccessControl(ctx context.Context, fetcher Fetcher) ([]Item, error) {
+	acls, err := fetcher.Acls(ctx)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -119,9 +121,9 @@ func fetchAccessControl(fetcher Fetcher) ([]Item, error) {
 	return snippets, nil
 }
 
-func fetchBackend(fetcher Fetcher) ([]Item, error) {
+func fetchBackend(ctx context.Context, fetcher Fetcher) ([]Item, error) {
 	var snippets []Item
-	backends, err := fetcher.Backends()
+	backends, err := fetcher.Backends(ctx)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -149,9 +151,9 @@ func fetchBackend(fetcher Fetcher) ([]Item, error) {
 	return snippets, nil
 }
 
-func fetchDirector(fetcher Fetcher) ([]Item, error) {
+func fetchDirector(ctx context.Context, fetcher Fetcher) ([]Item, error) {
 	var snippets []Item
-	directors, err := fetcher.Directors()
+	directors, err := fetcher.Directors(ctx)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -201,8 +203,8 @@ func renderBackendShields(backends []*Backend) ([]Item, error) {
 	return snippets, nil
 }
 
-func fetchVCLSnippets(fetcher Fetcher) (ScopedSnippets, IncludeSnippets, error) {
-	snippets, err := fetcher.Snippets()
+func fetchVCLSnippets(ctx context.Context, fetcher Fetcher) (ScopedSnippets, IncludeSnippets, error) {
+	snippets, err := fetcher.Snippets(ctx)
 	if err != nil {
 		return nil, nil, errors.WithStack(err)
 	}
@@ -238,8 +240,8 @@ func fetchVCLSnippets(fetcher Fetcher) (ScopedSnippets, IncludeSnippets, error)
 	return scoped, include, nil
 }
 
-func fetchConditions(fetcher Fetcher) (map[string]*Condition, error) {
-	conditions, err := fetcher.Conditions()
+func fetchConditions(ctx context.Context, fetcher Fetcher) (map[string]*Condition, error) {
+	conditions, err := fetcher.Conditions(ctx)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -48,8 +48,8 @@ func (f *FastlyApiFetcher) getVersion(c context.Context) (int64, error) {
 	return v, nil
 }
 
-func (f *FastlyApiFetcher) LookupCache(refresh bool) *snippet.Snippets {
-	ctx, timeout := context.WithTimeout(context.Background(), f.timeout)
+func (f *FastlyApiFetcher) LookupCache(ctx context.Context, refresh bool) *snippet.Snippets {
+	ctx, timeout := context.WithTimeout(ctx, f.timeout)
 	defer timeout()
 	version, err := f.getVersion(ctx)
 	if err != nil {
@@ -81,8 +81,8 @@ func (f *FastlyApiFetcher) LookupCache(refresh bool) *snippet.Snippets {
 	return snippets
 }
 
-func (f *FastlyApiFetcher) WriteCache(snip *snippet.Snippets) {
-	ctx, timeout := context.WithTimeout(context.Background(), f.timeout)
+func (f *FastlyApiFetcher) WriteCache(ctx context.Context, snip *snippet.Snippets) {
+	ctx, timeout := context.WithTimeout(ctx, f.timeout)
 	defer timeout()
 	version, err := f.getVersion(ctx)
 	if err != nil {
@@ -103,8 +103,8 @@ func (f *FastlyApiFetcher) WriteCache(snip *snippet.Snippets) {
 	json.NewEncoder(fp).Encode(snip) // nolint:errcheck
 }
 
-func (f *FastlyApiFetcher) RequestSetting() (*snippet.RequestSetting, error) {
-	ctx, timeout := context.WithTimeout(context.Background(), f.timeout)
+func (f *FastlyApiFetcher) RequestSetting(ctx context.Context) (*snippet.RequestSetting, error) {
+	ctx, timeout := context.WithTimeout(ctx, f.timeout)
 	defer timeout()
 	version, err := f.getVersion(ctx)
 	if err != nil {
@@ -123,8 +123,8 @@ func (f *FastlyApiFetcher) RequestSetting() (*snippet.RequestSetting, error) {
 	}, nil
 }
 
-func (f *FastlyApiFetcher) ResponseObjects() ([]*snippet.ResponseObject, error) {
-	ctx, timeout := context.WithTimeout(context.Background(), f.timeout)
+func (f *FastlyApiFetcher) ResponseObjects(ctx context.Context) ([]*snippet.ResponseObject, error) {
+	ctx, timeout := context.WithTimeout(ctx, f.timeout)
 	defer timeout()
 	version, err := f.getVersion(ctx)
 	if err != nil {
@@ -163,8 +163,8 @@ func (f *FastlyApiFetcher) ResponseObjects() ([]*snippet.ResponseObject, error)
 	return r, nil
 }
 
-func (f *FastlyApiFetcher) Headers() ([]*snippet.Header, error) {
-	ctx, timeout := context.WithTimeout(context.Background(), f.timeout)
+func (f *FastlyApiFetcher) Headers(ctx context.Context) ([]*snippet.Header, error) {
+	ctx, timeout := context.WithTimeout(ctx, f.timeout)
 	defer timeout()
 	version, err := f.getVersion(ctx)
 	if err != nil {
@@ -208,8 +208,8 @@ func (f *FastlyApiFetcher) Headers() ([]*snippet.Header, error) {
 	return r, nil
 }
 
-func (f *FastlyApiFetcher) Conditions() ([]*snippet.Condition, error) {
-	ctx, timeout := context.WithTimeout(context.Background(), f.timeout)
+func (f *FastlyApiFetcher) Conditions(ctx context.Context) ([]*snippet.Condition, error) {
+	ctx, timeout := context.WithTimeout(ctx, f.timeout)
 	defer timeout()
 	version, err := f.getVersion(ctx)
 	if err != nil {
@@ -237,8 +237,8 @@ func (f *FastlyApiFetcher) Conditions() ([]*snippet.Condition, error) {
 	return r, nil
 }
 
-func (f *FastlyApiFetcher) Backends() ([]*snippet.Backend, error) {
-	ctx, timeout := context.WithTimeout(context.Background(), f.timeout)
+func (f *FastlyApiFetcher) Backends(ctx context.Context) ([]*snippet.Backend, error) {
+	ctx, timeout := context.WithTimeout(ctx, f.timeout)
 	defer timeout()
 	version, err := f.getVersion(ctx)
 	if err != nil {
@@ -261,8 +261,8 @@ func (f *FastlyApiFetcher) Backends() ([]*snippet.Backend, error) {
 	return r, nil
 }
 
-func (f *FastlyApiFetcher) Dictionaries() ([]*snippet.Dictionary, error) {
-	c, timeout := context.WithTimeout(context.Background(), f.timeout)
+func (f *FastlyApiFetcher) Dictionaries(ctx context.Context) ([]*snippet.Dictionary, error) {
+	c, timeout := context.WithTimeout(ctx, f.timeout)
 	defer timeout()
 	version, err := f.getVersion(c)
 	if err != nil {
@@ -291,8 +291,8 @@ func (f *FastlyApiFetcher) Dictionaries() ([]*snippet.Dictionary, error) {
 	return r, nil
 }
 
-func (f *FastlyApiFetcher) Acls() ([]*snippet.Acl, error) {
-	c, timeout := context.WithTimeout(context.Background(), f.timeout)
+func (f *FastlyApiFetcher) Acls(ctx context.Context) ([]*snippet.Acl, error) {
+	c, timeout := context.WithTimeout(ctx, f.timeout)
 	defer timeout()
 	version, err := f.getVersion(c)
 	if err != nil {
@@ -323,8 +323,8 @@ func (f *FastlyApiFetcher) Acls() ([]*snippet.Acl, error) {
 	return r, nil
 }
 
-func (f *FastlyApiFetcher) Directors() ([]*snippet.Director, error) {
-	c, timeout := context.WithTimeout(context.Background(), f.timeout)
+func (f *FastlyApiFetcher) Directors(ctx context.Context) ([]*snippet.Director, error) {
+	c, timeout := context.WithTimeout(ctx, f.timeout)
 	defer timeout()
 	version, err := f.getVersion(c)
 	if err != nil {
@@ -349,8 +349,8 @@ func (f *FastlyApiFetcher) Directors() ([]*snippet.Director, error) {
 	return r, nil
 }
 
-func (f *FastlyApiFetcher) Snippets() ([]*snippet.VCLSnippet, error) {
-	c, timeout := context.WithTimeout(context.Background(), f.timeout)
+func (f *FastlyApiFetcher) Snippets(ctx context.Context) ([]*snippet.VCLSnippet, error) {
+	c, timeout := context.WithTimeout(ctx, f.timeout)
 	defer timeout()
 
 	version, err := f.getVersion(c)
@@ -380,8 +380,8 @@ func (f *FastlyApiFetcher) Snippets() ([]*snippet.VCLSnippet, error) {
 	return r, nil
 }
 
-func (f *FastlyApiFetcher) LoggingEndpoints() ([]string, error) {
-	c, timeout := context.WithTimeout(context.Background(), f.timeout)
+func (f *FastlyApiFetcher) LoggingEndpoints(ctx context.Context) ([]string, error) {
+	c, timeout := context.WithTimeout(ctx, f.timeout)
 	defer timeout()
 
 	version, err := f.getVersion(c)
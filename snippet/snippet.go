@@ -1,6 +1,8 @@
 package snippet
 
 import (
+	"context"
+
 	"github.com/pkg/errors"
 )
 
@@ -100,8 +102,8 @@ func (s *Snippets) EmbedSnippets(enableTLS bool) ([]Item, error) {
 // but we need to be able to factory all endpoints for future works.
 // Fastly's logging endpoints API is divided for each services like BigQuery, S3, etc..
 // It means we need to make many API calls so implement as Snippets pointer method.
-func (s *Snippets) FetchLoggingEndpoint(fetcher Fetcher) error {
-	endpoints, err := fetcher.LoggingEndpoints()
+func (s *Snippets) FetchLoggingEndpoint(ctx context.Context, fetcher Fetcher) error {
+	endpoints, err := fetcher.LoggingEndpoints(ctx)
 	if err != nil {
 		return err
 	}
@@ -1,6 +1,7 @@
 package terraform
 
 import (
+	"context"
 	"strconv"
 	"strings"
 
@@ -22,12 +23,12 @@ func (f *TerraformFetcher) SetName(name string) {
 	f.currentName = name
 }
 
-func (f *TerraformFetcher) LookupCache(refresh bool) *snippet.Snippets {
+func (f *TerraformFetcher) LookupCache(_ context.Context, refresh bool) *snippet.Snippets {
 	// Terraform cache always null because the planned input should be provided from stdin
 	return nil
 }
 
-func (f *TerraformFetcher) WriteCache(snip *snippet.Snippets) {
+func (f *TerraformFetcher) WriteCache(_ context.Context, snip *snippet.Snippets) {
 	// noop
 }
 
@@ -43,7 +44,7 @@ func (f *TerraformFetcher) filterService() []*FastlyService {
 	return []*FastlyService{}
 }
 
-func (f *TerraformFetcher) RequestSetting() (*snippet.RequestSetting, error) {
+func (f *TerraformFetcher) RequestSetting(_ context.Context) (*snippet.RequestSetting, error) {
 	for _, s := range f.filterService() {
 		if len(s.RequestSettings) > 0 {
 			return &snippet.RequestSetting{
@@ -54,7 +55,7 @@ func (f *TerraformFetcher) RequestSetting() (*snippet.RequestSetting, error) {
 	return nil, nil
 }
 
-func (f *TerraformFetcher) ResponseObjects() ([]*snippet.ResponseObject, error) {
+func (f *TerraformFetcher) ResponseObjects(_ context.Context) ([]*snippet.ResponseObject, error) {
 	var ros []*snippet.ResponseObject
 	for _, s := range f.filterService() {
 		for _, ro := range s.ResponseObjects {
@@ -76,7 +77,7 @@ func (f *TerraformFetcher) ResponseObjects() ([]*snippet.ResponseObject, error)
 	return ros, nil
 }
 
-func (f *TerraformFetcher) Headers() ([]*snippet.Header, error) {
+func (f *TerraformFetcher) Headers(_ context.Context) ([]*snippet.Header, error) {
 	var h []*snippet.Header
 	for _, s := range f.filterService() {
 		for _, header := range s.Headers {
@@ -113,7 +114,7 @@ func (f *TerraformFetcher) Headers() ([]*snippet.Header, error) {
 	return h, nil
 }
 
-func (f *TerraformFetcher) Conditions() ([]*snippet.Condition, error) {
+func (f *TerraformFetcher) Conditions(_ context.Context) ([]*snippet.Condition, error) {
 	var c []*snippet.Condition
 	for _, s := range f.filterService() {
 		for _, cond := range s.Conditions {
@@ -128,7 +129,7 @@ func (f *TerraformFetcher) Conditions() ([]*snippet.Condition, error) {
 	return c, nil
 }
 
-func (f *TerraformFetcher) Backends() ([]*snippet.Backend, error) {
+func (f *TerraformFetcher) Backends(_ context.Context) ([]*snippet.Backend, error) {
 	var b []*snippet.Backend
 	for _, s := range f.filterService() {
 		for _, backend := range s.Backends {
@@ -142,7 +143,7 @@ func (f *TerraformFetcher) Backends() ([]*snippet.Backend, error) {
 	return b, nil
 }
 
-func (f *TerraformFetcher) Dictionaries() ([]*snippet.Dictionary, error) {
+func (f *TerraformFetcher) Dictionaries(_ context.Context) ([]*snippet.Dictionary, error) {
 	var d []*snippet.Dictionary
 	for _, s := range f.filterService() {
 		for _, dict := range s.Dictionaries {
@@ -167,7 +168,7 @@ func (f *TerraformFetcher) Dictionaries() ([]*snippet.Dictionary, error) {
 	return d, nil
 }
 
-func (f *TerraformFetcher) Acls() ([]*snippet.Acl, error) {
+func (f *TerraformFetcher) Acls(_ context.Context) ([]*snippet.Acl, error) {
 	var a []*snippet.Acl
 	for _, s := range f.filterService() {
 		for _, sACL := range s.Acls {
@@ -196,7 +197,7 @@ func (f *TerraformFetcher) Acls() ([]*snippet.Acl, error) {
 	return a, nil
 }
 
-func (f *TerraformFetcher) Directors() ([]*snippet.Director, error) {
+func (f *TerraformFetcher) Directors(_ context.Context) ([]*snippet.Director, error) {
 	var d []*snippet.Director
 	for _, s := range f.filterService() {
 		for _, director := range s.Directors {
@@ -212,7 +213,7 @@ func (f *TerraformFetcher) Directors() ([]*snippet.Director, error) {
 	return d, nil
 }
 
-func (f *TerraformFetcher) Snippets() ([]*snippet.VCLSnippet, error) {
+func (f *TerraformFetcher) Snippets(_ context.Context) ([]*snippet.VCLSnippet, error) {
 	var v []*snippet.VCLSnippet
 	for _, s := range f.filterService() {
 		for _, vcl := range s.Snippets {
@@ -227,7 +228,7 @@ func (f *TerraformFetcher) Snippets() ([]*snippet.VCLSnippet, error) {
 	return v, nil
 }
 
-func (f *TerraformFetcher) LoggingEndpoints() ([]string, error) {
+func (f *TerraformFetcher) LoggingEndpoints(_ context.Context) ([]string, error) {
 	var v []string
 	for _, s := range f.filterService() {
 		v = append(v, s.LoggingEndpoints...)
@@ -1,6 +1,7 @@
 package terraform
 
 import (
+	"context"
 	"os"
 	"testing"
 )
@@ -24,7 +25,7 @@ func TestStuff(t *testing.T) {
 
 		f := NewTerraformFetcher(services)
 
-		acls, _ := f.Acls()
+		acls, _ := f.Acls(context.Background())
 		if len(acls) != 1 {
 			t.Errorf("Length of ACLs should be %d, got %d", 1, len(acls))
 		}
@@ -33,7 +34,7 @@ func TestStuff(t *testing.T) {
 			t.Errorf("Acl name want %s, got %s", acls[0].Name, "foo_acl")
 		}
 
-		backends, _ := f.Backends()
+		backends, _ := f.Backends(context.Background())
 		if len(backends) != 1 {
 			t.Errorf("Length of Backends should be %d, got %d", 1, len(backends))
 		}
@@ -42,7 +43,7 @@ func TestStuff(t *testing.T) {
 			t.Errorf("Backend name want %s, got %s", backends[0].Name, "foo_backend")
 		}
 
-		dictionaries, _ := f.Dictionaries()
+		dictionaries, _ := f.Dictionaries(context.Background())
 		if len(dictionaries) != 1 {
 			t.Errorf("Length of dictionaries should be %d, got %d", 1, len(dictionaries))
 		}
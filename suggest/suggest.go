@@ -0,0 +1,97 @@
+// Package suggest finds the closest names of the unknown identifier
+// in order to show "did you mean" suggestions on errors
+package suggest
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Maximum number of suggestions
+const maxSuggestions = 3
+
+// Distance returns the edit distance of two names case-insensitively.
+// Transposition of adjacent characters like "Hots" and "Host" is counted as single edit
+func Distance(a, b string) int {
+	s, t := []rune(strings.ToLower(a)), []rune(strings.ToLower(b))
+
+	// Keep three rows for the transposition
+	prev2 := make([]int, len(t)+1)
+	prev := make([]int, len(t)+1)
+	cur := make([]int, len(t)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(s); i++ {
+		cur[0] = i
+		for j := 1; j <= len(t); j++ {
+			cost := 1
+			if s[i-1] == t[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && s[i-1] == t[j-2] && s[i-2] == t[j-1] {
+				cur[j] = min(cur[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	return prev[len(t)]
+}
+
+// Closest returns the candidates which are close to the name ordered by the distance, up to three names.
+// The name itself is not included even if it is one of candidates
+func Closest(name string, candidates []string) []string {
+	// Allow one edit for each four characters
+	threshold := max(1, len([]rune(name))/4)
+
+	type match struct {
+		name     string
+		distance int
+	}
+	var matches []match
+	seen := make(map[string]struct{})
+	for _, c := range candidates {
+		if c == name {
+			continue
+		}
+		if _, ok := seen[c]; ok {
+			continue
+		}
+		seen[c] = struct{}{}
+		if d := Distance(name, c); d <= threshold {
+			matches = append(matches, match{name: c, distance: d})
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].distance != matches[j].distance {
+			return matches[i].distance < matches[j].distance
+		}
+		return matches[i].name < matches[j].name
+	})
+
+	var names []string
+	for i := range min(len(matches), maxSuggestions) {
+		names = append(names, matches[i].name)
+	}
+	return names
+}
+
+// Message returns the message like `did you mean "foo" or "bar"?`, or empty string if there is no suggestion
+func Message(suggestions []string) string {
+	if len(suggestions) == 0 {
+		return ""
+	}
+	quoted := make([]string, len(suggestions))
+	for i := range suggestions {
+		quoted[i] = fmt.Sprintf("%q", suggestions[i])
+	}
+	if len(quoted) == 1 {
+		return fmt.Sprintf("did you mean %s?", quoted[0])
+	}
+	return fmt.Sprintf(
+		"did you mean %s or %s?",
+		strings.Join(quoted[:len(quoted)-1], ", "), quoted[len(quoted)-1],
+	)
+}
@@ -0,0 +1,61 @@
+package suggest
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		a, b   string
+		expect int
+	}{
+		{a: "req.url", b: "req.url", expect: 0},
+		{a: "req.URL", b: "req.url", expect: 0},
+		{a: "req.restrats", b: "req.restarts", expect: 1},
+		{a: "std.strln", b: "std.strlen", expect: 1},
+		{a: "F_orign", b: "F_origin", expect: 1},
+		{a: "kitten", b: "sitting", expect: 3},
+		{a: "", b: "abc", expect: 3},
+	}
+	for _, tt := range tests {
+		if actual := Distance(tt.a, tt.b); actual != tt.expect {
+			t.Errorf("Distance(%q, %q) expects %d, got %d", tt.a, tt.b, tt.expect, actual)
+		}
+	}
+}
+
+func TestClosest(t *testing.T) {
+	candidates := []string{"F_origin", "F_origin_2", "F_origin", "F_shield", "F_orig", "vcl_recv"}
+	tests := []struct {
+		name   string
+		expect []string
+	}{
+		{name: "F_orign", expect: []string{"F_orig", "F_origin"}},
+		{name: "F_shiled", expect: []string{"F_shield"}},
+		{name: "F_origin", expect: []string{"F_orig", "F_origin_2"}},
+		{name: "unknown", expect: nil},
+	}
+	for _, tt := range tests {
+		if diff := cmp.Diff(tt.expect, Closest(tt.name, candidates)); diff != "" {
+			t.Errorf("Closest(%q) mismatch, diff=%s", tt.name, diff)
+		}
+	}
+}
+
+func TestMessage(t *testing.T) {
+	tests := []struct {
+		suggestions []string
+		expect      string
+	}{
+		{suggestions: nil, expect: ""},
+		{suggestions: []string{"foo"}, expect: `did you mean "foo"?`},
+		{suggestions: []string{"foo", "bar", "baz"}, expect: `did you mean "foo", "bar" or "baz"?`},
+	}
+	for _, tt := range tests {
+		if actual := Message(tt.suggestions); actual != tt.expect {
+			t.Errorf("Message(%v) expects %s, got %s", tt.suggestions, tt.expect, actual)
+		}
+	}
+}
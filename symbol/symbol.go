@@ -0,0 +1,367 @@
+// Package symbol builds the project-wide index of declared symbols and where they are referenced,
+// and persists it in order to answer navigation queries without parsing all VCLs again
+package symbol
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/ysugimoto/falco/v2/ast"
+	"github.com/ysugimoto/falco/v2/linter/context"
+)
+
+const (
+	KindSubroutine  = "subroutine"
+	KindBackend     = "backend"
+	KindTable       = "table"
+	KindAcl         = "acl"
+	KindPenaltybox  = "penaltybox"
+	KindRatecounter = "ratecounter"
+)
+
+// Index format version, index file which has different version is rebuilt
+const version = 1
+
+// Fastly builtin function definitions to distinguish user defined subroutine calls
+var builtins = sync.OnceValue(func() *context.Context {
+	return context.New()
+})
+
+type Location struct {
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Position int    `json:"position"`
+}
+
+// Symbol is the declared symbol with declaration and reference locations across indexed files
+type Symbol struct {
+	Name         string      `json:"name"`
+	Kind         string      `json:"kind"`
+	Declarations []*Location `json:"declarations"`
+	References   []*Location `json:"references"`
+}
+
+// Occurrence of the symbol name in the file.
+// Kind of reference is empty if it could not be determined in the file like backend name,
+// then it is resolved by the declared symbols of all files
+type Occurrence struct {
+	Name string `json:"name"`
+	Kind string `json:"kind,omitempty"`
+	Location
+}
+
+type File struct {
+	Hash         string        `json:"hash"`
+	Declarations []*Occurrence `json:"declarations"`
+	References   []*Occurrence `json:"references"`
+}
+
+type Index struct {
+	Version int              `json:"version"`
+	Files   map[string]*File `json:"files"`
+}
+
+func New() *Index {
+	return &Index{
+		Version: version,
+		Files:   make(map[string]*File),
+	}
+}
+
+// Load index from the file. Empty index is returned if the file does not exist or has different format version
+func Load(path string) (*Index, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return New(), nil
+		}
+		return nil, errors.WithStack(err)
+	}
+	idx := New()
+	if err := json.Unmarshal(buf, idx); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if idx.Version != version || idx.Files == nil {
+		return New(), nil
+	}
+	return idx, nil
+}
+
+func (idx *Index) Save(path string) error {
+	buf, err := json.Marshal(idx)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if err := os.WriteFile(path, buf, 0o644); err != nil {
+		return errors.WithStack(err)
+	}
+	return nil
+}
+
+// Hash returns content hash of the VCL to determine the file should be indexed again
+func Hash(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// IsIndexed returns true if the file has been indexed with the same content
+func (idx *Index) IsIndexed(file, hash string) bool {
+	f, ok := idx.Files[file]
+	return ok && f.Hash == hash
+}
+
+// Indexed file paths in sorted order
+func (idx *Index) FilePaths() []string {
+	paths := make([]string, 0, len(idx.Files))
+	for path := range idx.Files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// Remove files which do not exist anymore from the index
+func (idx *Index) Prune() {
+	for path := range idx.Files {
+		if _, err := os.Stat(path); err != nil {
+			delete(idx.Files, path)
+		}
+	}
+}
+
+// Add indexes declarations and references in the VCL, overwrites the previous index of the file
+func (idx *Index) Add(file, hash string, vcl *ast.VCL) {
+	c := &collector{
+		file: &File{Hash: hash},
+	}
+	c.collect(vcl)
+	idx.Files[file] = c.file
+}
+
+// Symbols returns all declared symbols sorted by kind and name
+func (idx *Index) Symbols() []*Symbol {
+	symbols := make(map[string]*Symbol)
+	kinds := make(map[string][]string) // name -> declared kinds
+	for _, path := range idx.FilePaths() {
+		for _, d := range idx.Files[path].Declarations {
+			key := d.Kind + ":" + d.Name
+			s, ok := symbols[key]
+			if !ok {
+				s = &Symbol{Name: d.Name, Kind: d.Kind}
+				symbols[key] = s
+				kinds[d.Name] = append(kinds[d.Name], d.Kind)
+			}
+			loc := d.Location
+			s.Declarations = append(s.Declarations, &loc)
+		}
+	}
+
+	for _, path := range idx.FilePaths() {
+		for _, r := range idx.Files[path].References {
+			for _, kind := range kinds[r.Name] {
+				// Subroutine is referenced only by call statement or function call
+				if (r.Kind == "" && kind == KindSubroutine) || (r.Kind != "" && r.Kind != kind) {
+					continue
+				}
+				loc := r.Location
+				symbols[kind+":"+r.Name].References = append(symbols[kind+":"+r.Name].References, &loc)
+			}
+		}
+	}
+
+	list := make([]*Symbol, 0, len(symbols))
+	for _, s := range symbols {
+		list = append(list, s)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Kind != list[j].Kind {
+			return list[i].Kind < list[j].Kind
+		}
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
+
+// Lookup returns symbols which have the name
+func (idx *Index) Lookup(name string) []*Symbol {
+	var found []*Symbol
+	for _, s := range idx.Symbols() {
+		if s.Name == name {
+			found = append(found, s)
+		}
+	}
+	return found
+}
+
+type collector struct {
+	file *File
+}
+
+func (c *collector) add(list *[]*Occurrence, kind string, ident *ast.Ident) {
+	if ident == nil {
+		return
+	}
+	c.addName(list, kind, ident.Value, ident)
+}
+
+func (c *collector) addName(list *[]*Occurrence, kind, name string, ident *ast.Ident) {
+	*list = append(*list, &Occurrence{
+		Name: name,
+		Kind: kind,
+		Location: Location{
+			File:     ident.Token.File,
+			Line:     ident.Token.Line,
+			Position: ident.Token.Position,
+		},
+	})
+}
+
+func (c *collector) collect(vcl *ast.VCL) {
+	for _, stmt := range vcl.Statements {
+		switch t := stmt.(type) {
+		case *ast.SubroutineDeclaration:
+			c.add(&c.file.Declarations, KindSubroutine, t.Name)
+			c.collectBlock(t.Block)
+		case *ast.BackendDeclaration:
+			c.add(&c.file.Declarations, KindBackend, t.Name)
+		case *ast.TableDeclaration:
+			c.add(&c.file.Declarations, KindTable, t.Name)
+		case *ast.AclDeclaration:
+			c.add(&c.file.Declarations, KindAcl, t.Name)
+		case *ast.PenaltyboxDeclaration:
+			c.add(&c.file.Declarations, KindPenaltybox, t.Name)
+		case *ast.RatecounterDeclaration:
+			c.add(&c.file.Declarations, KindRatecounter, t.Name)
+		case *ast.DirectorDeclaration:
+			c.collectExpressions(t.Properties)
+		default:
+			// Snippet has statements on the root
+			c.collectStatement(stmt)
+		}
+	}
+}
+
+// collectIdent collects identifier which may refer to the declared symbol.
+// Identifiers with dot are variables except ratecounter variables like ratecounter.NAME.rate.10s
+func (c *collector) collectIdent(ident *ast.Ident) {
+	if ident == nil {
+		return
+	}
+	if !strings.Contains(ident.Value, ".") {
+		c.add(&c.file.References, "", ident)
+		return
+	}
+	if rest, ok := strings.CutPrefix(ident.Value, "ratecounter."); ok {
+		name, _, _ := strings.Cut(rest, ".")
+		c.addName(&c.file.References, KindRatecounter, name, ident)
+	}
+}
+
+// User defined subroutine which returns value is called as the function
+func (c *collector) collectFunction(ident *ast.Ident) {
+	if !builtins().IsBuiltinFunction(ident.Value) {
+		c.add(&c.file.References, KindSubroutine, ident)
+	}
+}
+
+func (c *collector) collectBlock(block *ast.BlockStatement) {
+	if block == nil {
+		return
+	}
+	c.collectStatements(block.Statements)
+}
+
+func (c *collector) collectStatements(statements []ast.Statement) {
+	for _, stmt := range statements {
+		c.collectStatement(stmt)
+	}
+}
+
+func (c *collector) collectStatement(stmt ast.Statement) {
+	switch t := stmt.(type) {
+	case *ast.BlockStatement:
+		c.collectBlock(t)
+	case *ast.SetStatement:
+		c.collectIdent(t.Ident)
+		c.collectExpression(t.Value)
+	case *ast.AddStatement:
+		c.collectIdent(t.Ident)
+		c.collectExpression(t.Value)
+	case *ast.IfStatement:
+		c.collectIfStatement(t)
+	case *ast.SwitchStatement:
+		c.collectExpression(t.Control.Expression)
+		for _, cs := range t.Cases {
+			c.collectStatements(cs.Statements)
+		}
+	case *ast.ErrorStatement:
+		c.collectExpression(t.Code)
+		c.collectExpression(t.Argument)
+	case *ast.LogStatement:
+		c.collectExpression(t.Value)
+	case *ast.SyntheticStatement:
+		c.collectExpression(t.Value)
+	case *ast.SyntheticBase64Statement:
+		c.collectExpression(t.Value)
+	case *ast.ReturnStatement:
+		c.collectExpression(t.ReturnExpression)
+	case *ast.CallStatement:
+		c.add(&c.file.References, KindSubroutine, t.Subroutine)
+		c.collectExpressions(t.Arguments)
+	case *ast.FunctionCallStatement:
+		c.collectFunction(t.Function)
+		c.collectExpressions(t.Arguments)
+	}
+}
+
+func (c *collector) collectIfStatement(stmt *ast.IfStatement) {
+	c.collectExpression(stmt.Condition)
+	c.collectBlock(stmt.Consequence)
+	for _, another := range stmt.Another {
+		c.collectIfStatement(another)
+	}
+	if stmt.Alternative != nil {
+		c.collectBlock(stmt.Alternative.Consequence)
+	}
+}
+
+func (c *collector) collectExpressions(expressions []ast.Expression) {
+	for i := range expressions {
+		c.collectExpression(expressions[i])
+	}
+}
+
+func (c *collector) collectExpression(expr ast.Expression) {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		c.collectIdent(t)
+	case *ast.PrefixExpression:
+		c.collectExpression(t.Right)
+	case *ast.PostfixExpression:
+		c.collectExpression(t.Left)
+	case *ast.GroupedExpression:
+		c.collectExpression(t.Right)
+	case *ast.InfixExpression:
+		c.collectExpression(t.Left)
+		c.collectExpression(t.Right)
+	case *ast.IfExpression:
+		c.collectExpression(t.Condition)
+		c.collectExpression(t.Consequence)
+		c.collectExpression(t.Alternative)
+	case *ast.FunctionCallExpression:
+		c.collectFunction(t.Function)
+		c.collectExpressions(t.Arguments)
+	case *ast.DirectorProperty:
+		c.collectExpression(t.Value)
+	case *ast.DirectorBackendObject:
+		for _, v := range t.Values {
+			c.collectExpression(v.Value)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package symbol
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/ysugimoto/falco/v2/lexer"
+	"github.com/ysugimoto/falco/v2/parser"
+)
+
+func indexVCL(t *testing.T, idx *Index, name, input string) {
+	vcl, err := parser.New(lexer.NewFromString(input, lexer.WithFile(name))).ParseVCL()
+	if err != nil {
+		t.Fatalf("Unexpected parser error: %s", err)
+	}
+	idx.Add(name, Hash(input), vcl)
+}
+
+func TestIndex(t *testing.T) {
+	idx := New()
+	indexVCL(t, idx, "main.vcl", `
+acl internal {
+  "192.0.2.0"/24;
+}
+penaltybox pbox {}
+ratecounter rc {}
+
+sub vcl_recv {
+  #FASTLY recv
+  if (client.ip ~ internal) {
+    set req.backend = F_origin;
+  }
+  if (ratelimit.check_rate(client.ip, rc, 1, 10, 100, pbox, 1m)) {
+    set req.http.Rate = ratecounter.rc.rate.10s;
+  }
+  set req.http.Value = table.lookup(values, "key");
+  call custom;
+}`)
+	indexVCL(t, idx, "custom.vcl", `
+backend F_origin {
+  .host = "example.com";
+}
+table values {
+  "key": "value",
+}
+
+sub custom {
+  set req.http.Bool = is_ok();
+}
+
+sub is_ok BOOL {
+  return true;
+}`)
+
+	expect := []*Symbol{
+		{
+			Name:         "internal",
+			Kind:         KindAcl,
+			Declarations: []*Location{{File: "main.vcl", Line: 2, Position: 5}},
+			References:   []*Location{{File: "main.vcl", Line: 10, Position: 19}},
+		},
+		{
+			Name:         "F_origin",
+			Kind:         KindBackend,
+			Declarations: []*Location{{File: "custom.vcl", Line: 2, Position: 9}},
+			References:   []*Location{{File: "main.vcl", Line: 11, Position: 23}},
+		},
+		{
+			Name:         "pbox",
+			Kind:         KindPenaltybox,
+			Declarations: []*Location{{File: "main.vcl", Line: 5, Position: 12}},
+			References:   []*Location{{File: "main.vcl", Line: 13, Position: 55}},
+		},
+		{
+			Name:         "rc",
+			Kind:         KindRatecounter,
+			Declarations: []*Location{{File: "main.vcl", Line: 6, Position: 13}},
+			References: []*Location{
+				{File: "main.vcl", Line: 13, Position: 39},
+				{File: "main.vcl", Line: 14, Position: 25},
+			},
+		},
+		{
+			Name:         "custom",
+			Kind:         KindSubroutine,
+			Declarations: []*Location{{File: "custom.vcl", Line: 9, Position: 5}},
+			References:   []*Location{{File: "main.vcl", Line: 17, Position: 8}},
+		},
+		{
+			Name:         "is_ok",
+			Kind:         KindSubroutine,
+			Declarations: []*Location{{File: "custom.vcl", Line: 13, Position: 5}},
+			References:   []*Location{{File: "custom.vcl", Line: 10, Position: 23}},
+		},
+		{
+			Name:         "vcl_recv",
+			Kind:         KindSubroutine,
+			Declarations: []*Location{{File: "main.vcl", Line: 8, Position: 5}},
+		},
+		{
+			Name:         "values",
+			Kind:         KindTable,
+			Declarations: []*Location{{File: "custom.vcl", Line: 5, Position: 7}},
+			References:   []*Location{{File: "main.vcl", Line: 16, Position: 37}},
+		},
+	}
+	if diff := cmp.Diff(expect, idx.Symbols()); diff != "" {
+		t.Errorf("Symbols mismatch, diff=%s", diff)
+	}
+
+	t.Run("lookup", func(t *testing.T) {
+		found := idx.Lookup("F_origin")
+		if len(found) != 1 || found[0].Kind != KindBackend {
+			t.Errorf("Expected backend F_origin is found, got %v", found)
+		}
+	})
+
+	t.Run("persistence", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "symbols.json")
+		if err := idx.Save(path); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		loaded, err := Load(path)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if diff := cmp.Diff(idx.Symbols(), loaded.Symbols()); diff != "" {
+			t.Errorf("Loaded symbols mismatch, diff=%s", diff)
+		}
+		if !loaded.IsIndexed("custom.vcl", idx.Files["custom.vcl"].Hash) {
+			t.Errorf("custom.vcl should be indexed with the same hash")
+		}
+		if loaded.IsIndexed("custom.vcl", Hash("changed")) {
+			t.Errorf("custom.vcl should not be indexed with the different hash")
+		}
+	})
+}
@@ -0,0 +1,279 @@
+package synthetic
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+	"github.com/ysugimoto/falco/v2/ast"
+	"github.com/ysugimoto/falco/v2/config"
+	"github.com/ysugimoto/falco/v2/resolver"
+)
+
+const (
+	TypeRedirect    = "redirect"
+	TypeMaintenance = "maintenance"
+
+	// Virtual file name of the generated VCL
+	FileName = "synthetic.vcl"
+
+	// Delimiter of the heredoc string for the synthetic response body
+	bodyDelimiter = "SYNTHETIC"
+)
+
+// Generate VCL of the built-in behavior from configuration
+func Generate(c *config.SyntheticConfig) (string, error) {
+	switch c.Type {
+	case TypeRedirect:
+		if c.Redirect == nil {
+			return "", errors.New("redirect configuration is not provided")
+		}
+		return generateRedirect(c.Redirect)
+	case TypeMaintenance:
+		if c.Maintenance == nil {
+			return "", errors.New("maintenance configuration is not provided")
+		}
+		return generateMaintenance(c.Maintenance)
+	default:
+		return "", errors.Errorf("unsupported synthetic service type %q, must be redirect or maintenance", c.Type)
+	}
+}
+
+// Resolver provides generated VCL as main VCL
+type Resolver struct {
+	vcl *resolver.VCL
+}
+
+func NewResolver(c *config.SyntheticConfig) (*Resolver, error) {
+	data, err := Generate(c)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &Resolver{
+		vcl: &resolver.VCL{
+			Name: FileName,
+			Data: data,
+		},
+	}, nil
+}
+
+func (r *Resolver) MainVCL() (*resolver.VCL, error) {
+	return r.vcl, nil
+}
+
+func (r *Resolver) Resolve(stmt *ast.IncludeStatement) (*resolver.VCL, error) {
+	return nil, errors.Errorf("Synthetic service could not include module %s", stmt.Module.Value)
+}
+
+func (r *Resolver) Name() string           { return "" }
+func (r *Resolver) IncludePaths() []string { return []string{} }
+
+var redirectTemplate = template.Must(template.New("redirect").Parse(`table synthetic_redirects {
+{{- range .Rules }}
+  "{{ .From }}": "{{ .To }}",
+{{- end }}
+}
+
+sub vcl_recv {
+#FASTLY RECV
+  if (table.contains(synthetic_redirects, req.url.path)) {
+    set req.http.Synthetic-Location = table.lookup(synthetic_redirects, req.url.path);
+    error 801;
+  }
+{{- if .Default }}
+  set req.http.Synthetic-Location = "{{ .Default }}";
+  error 801;
+{{- else }}
+  error 404;
+{{- end }}
+}
+
+sub vcl_error {
+#FASTLY ERROR
+  if (obj.status == 801) {
+    set obj.status = {{ .Status }};
+    set obj.response = "{{ .StatusText }}";
+    set obj.http.Location = req.http.Synthetic-Location;
+{{- if .PreserveQuery }}
+    if (req.url.qs != "") {
+      set obj.http.Location = obj.http.Location "?" req.url.qs;
+    }
+{{- end }}
+    synthetic "";
+    return(deliver);
+  }
+  if (obj.status == 404) {
+    set obj.http.Content-Type = "text/plain";
+    synthetic "Not Found";
+    return(deliver);
+  }
+}
+`))
+
+func generateRedirect(c *config.SyntheticRedirectConfig) (string, error) {
+	status := c.Status
+	if status == 0 {
+		status = http.StatusMovedPermanently
+	}
+	if status < 300 || status > 399 {
+		return "", errors.Errorf("redirect status must be 3xx, %d provided", status)
+	}
+
+	values := []string{c.Default}
+	for _, rule := range c.Rules {
+		if rule.From == "" || rule.To == "" {
+			return "", errors.New("redirect rule must have both from and to")
+		}
+		values = append(values, rule.From, rule.To)
+	}
+	if err := validateStrings(values...); err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	var buf bytes.Buffer
+	err := redirectTemplate.Execute(&buf, map[string]any{
+		"Rules":         c.Rules,
+		"Default":       c.Default,
+		"PreserveQuery": c.PreserveQuery,
+		"Status":        status,
+		"StatusText":    http.StatusText(status),
+	})
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	return buf.String(), nil
+}
+
+var maintenanceTemplate = template.Must(template.New("maintenance").Parse(`
+{{- if .Origin -}}
+backend F_synthetic_origin {
+  .host = "{{ .Origin.Host }}";
+  .port = "{{ .Origin.Port }}";
+{{- if .Origin.SSL }}
+  .ssl = true;
+  .ssl_cert_hostname = "{{ .Origin.Host }}";
+  .ssl_sni_hostname = "{{ .Origin.Host }}";
+{{- end }}
+}
+
+{{ end -}}
+{{- if .AllowIPs -}}
+acl synthetic_maintenance_allowed {
+{{- range .AllowIPs }}
+  {{ . }};
+{{- end }}
+}
+
+{{ end -}}
+sub vcl_recv {
+#FASTLY RECV
+{{- if .AllowIPs }}
+  if (client.ip ~ synthetic_maintenance_allowed) {
+    return(pass);
+  }
+{{- end }}
+  error 802;
+}
+
+sub vcl_error {
+#FASTLY ERROR
+  if (obj.status == 802) {
+    set obj.status = {{ .Status }};
+    set obj.response = "{{ .StatusText }}";
+    set obj.http.Content-Type = "{{ .ContentType }}";
+{{- if .RetryAfter }}
+    set obj.http.Retry-After = "{{ .RetryAfter }}";
+{{- end }}
+    synthetic {{ .Body }};
+    return(deliver);
+  }
+}
+`))
+
+type origin struct {
+	Host string
+	Port string
+	SSL  bool
+}
+
+func generateMaintenance(c *config.SyntheticMaintenanceConfig) (string, error) {
+	status := c.Status
+	if status == 0 {
+		status = http.StatusServiceUnavailable
+	}
+	if http.StatusText(status) == "" {
+		return "", errors.Errorf("unknown maintenance status %d", status)
+	}
+	contentType := c.ContentType
+	if contentType == "" {
+		contentType = "text/html"
+	}
+	if err := validateStrings(contentType, c.Origin); err != nil {
+		return "", errors.WithStack(err)
+	}
+	if strings.Contains(c.Body, `"`+bodyDelimiter+`}`) {
+		return "", errors.Errorf("maintenance body must not contain %q", `"`+bodyDelimiter+`}`)
+	}
+
+	// Allowed clients pass through to the origin so origin is required
+	if len(c.AllowIPs) > 0 && c.Origin == "" {
+		return "", errors.New("origin is required to allow clients during maintenance")
+	}
+	var allowIPs []string
+	for _, ip := range c.AllowIPs {
+		entry, err := aclEntry(ip)
+		if err != nil {
+			return "", errors.WithStack(err)
+		}
+		allowIPs = append(allowIPs, entry)
+	}
+
+	var o *origin
+	if c.Origin != "" {
+		o = &origin{Host: c.Origin, Port: "443", SSL: true}
+		if host, port, err := net.SplitHostPort(c.Origin); err == nil {
+			o = &origin{Host: host, Port: port, SSL: port == "443"}
+		}
+	}
+
+	var buf bytes.Buffer
+	err := maintenanceTemplate.Execute(&buf, map[string]any{
+		"Origin":      o,
+		"AllowIPs":    allowIPs,
+		"Status":      status,
+		"StatusText":  http.StatusText(status),
+		"ContentType": contentType,
+		"RetryAfter":  c.RetryAfter,
+		"Body":        "{" + bodyDelimiter + `"` + c.Body + `"` + bodyDelimiter + "}",
+	})
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	return buf.String(), nil
+}
+
+// Convert IP or CIDR string to ACL entry format
+func aclEntry(ip string) (string, error) {
+	if _, n, err := net.ParseCIDR(ip); err == nil {
+		size, _ := n.Mask.Size()
+		return fmt.Sprintf(`"%s"/%d`, n.IP.String(), size), nil
+	}
+	if v := net.ParseIP(ip); v != nil {
+		return fmt.Sprintf(`"%s"`, v.String()), nil
+	}
+	return "", errors.Errorf("invalid IP address %q in allow_ips", ip)
+}
+
+// VCL string literal could not contain double quote and newline
+func validateStrings(values ...string) error {
+	for _, v := range values {
+		if strings.ContainsAny(v, "\"\n") {
+			return errors.Errorf("value %q must not contain double quote or newline", v)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package synthetic
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ysugimoto/falco/v2/config"
+	"github.com/ysugimoto/falco/v2/lexer"
+	"github.com/ysugimoto/falco/v2/parser"
+)
+
+func TestGenerate(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   *config.SyntheticConfig
+		contains []string
+		isError  bool
+	}{
+		{
+			name: "redirect service",
+			config: &config.SyntheticConfig{
+				Type: TypeRedirect,
+				Redirect: &config.SyntheticRedirectConfig{
+					PreserveQuery: true,
+					Rules: []*config.SyntheticRedirectRule{
+						{From: "/old", To: "https://example.com/new"},
+					},
+				},
+			},
+			contains: []string{`"/old": "https://example.com/new"`, "set obj.status = 301;", "req.url.qs", "error 404;"},
+		},
+		{
+			name: "redirect service with default location",
+			config: &config.SyntheticConfig{
+				Type: TypeRedirect,
+				Redirect: &config.SyntheticRedirectConfig{
+					Status:  302,
+					Default: "https://example.com/",
+				},
+			},
+			contains: []string{`set req.http.Synthetic-Location = "https://example.com/";`, "set obj.status = 302;"},
+		},
+		{
+			name: "redirect status must be 3xx",
+			config: &config.SyntheticConfig{
+				Type:     TypeRedirect,
+				Redirect: &config.SyntheticRedirectConfig{Status: 200},
+			},
+			isError: true,
+		},
+		{
+			name: "maintenance service",
+			config: &config.SyntheticConfig{
+				Type: TypeMaintenance,
+				Maintenance: &config.SyntheticMaintenanceConfig{
+					Body:       `<h1 class="title">Maintenance</h1>`,
+					RetryAfter: 3600,
+					AllowIPs:   []string{"192.0.2.0/24", "198.51.100.1"},
+					Origin:     "example.com",
+				},
+			},
+			contains: []string{
+				`.host = "example.com";`,
+				`"192.0.2.0"/24;`,
+				`"198.51.100.1";`,
+				"set obj.status = 503;",
+				`set obj.http.Retry-After = "3600";`,
+			},
+		},
+		{
+			name: "allowed clients require origin",
+			config: &config.SyntheticConfig{
+				Type: TypeMaintenance,
+				Maintenance: &config.SyntheticMaintenanceConfig{
+					AllowIPs: []string{"192.0.2.1"},
+				},
+			},
+			isError: true,
+		},
+		{
+			name:    "unsupported type",
+			config:  &config.SyntheticConfig{Type: "unknown"},
+			isError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vcl, err := Generate(tt.config)
+			if tt.isError {
+				if err == nil {
+					t.Errorf("Expected error but nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("Unexpected error: %s", err)
+				return
+			}
+			for _, c := range tt.contains {
+				if !strings.Contains(vcl, c) {
+					t.Errorf("Generated VCL does not contain %s:\n%s", c, vcl)
+				}
+			}
+			if _, err := parser.New(lexer.NewFromString(vcl)).ParseVCL(); err != nil {
+				t.Errorf("Generated VCL could not be parsed: %s", err)
+			}
+		})
+	}
+}
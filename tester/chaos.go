@@ -0,0 +1,68 @@
+package tester
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/ysugimoto/falco/v2/interpreter"
+	"github.com/ysugimoto/falco/v2/interpreter/context"
+	"github.com/ysugimoto/falco/v2/tester/syntax"
+)
+
+// ChaosSeed returns the random seed of chaos testing which reproduces perturbations with --chaos-seed option
+func (t *Tester) ChaosSeed() int64 {
+	return t.chaosSeed
+}
+
+// Run the invariant test repeatedly with randomly perturbed conditions in chaos testing.
+// Perturbations are generated from the same seed for each test so the failure could be reproduced by the seed
+// regardless of other tests. Returns logs which describe the broken iteration and the error on the first failure
+func (t *Tester) runChaos(i *interpreter.Interpreter, metadata *Metadata, run func() error) ([]string, error) {
+	if !t.config.Chaos || !metadata.Invariant {
+		return nil, nil
+	}
+	skew, err := time.ParseDuration(t.config.ChaosClockSkew)
+	if err != nil {
+		return nil, errors.Errorf("Invalid chaos clock skew %q: %s", t.config.ChaosClockSkew, err)
+	}
+
+	r := rand.New(rand.NewSource(t.chaosSeed)) // nolint: gosec
+	for n := 1; n <= t.config.ChaosIterations; n++ {
+		d := NewDebugger()
+		i.Debugger = d
+		perturbations, restore := i.Perturb(r, skew)
+		// Each iteration asserts subroutine calls of its own run
+		i.ResetSubroutineCalls()
+		err := run()
+		restore()
+		if err == nil {
+			continue
+		}
+
+		logs := []string{
+			fmt.Sprintf("Chaos iteration %d/%d breaks the invariant with seed %d", n, t.config.ChaosIterations, t.chaosSeed),
+		}
+		if len(perturbations) == 0 {
+			logs = append(logs, "  - no perturbation")
+		}
+		for _, p := range perturbations {
+			logs = append(logs, "  - "+p)
+		}
+		return append(logs, d.stack...), errors.Cause(err)
+	}
+	return nil, nil
+}
+
+// Run the before_xxx or after_xxx hook of describe block that corresponds to the scope if exists
+func runHook(i *interpreter.Interpreter, hooks map[string]*syntax.HookStatement, name string, s context.Scope) error {
+	hook, ok := hooks[strings.ToLower(name+s.String())]
+	if !ok {
+		return nil
+	}
+	i.SetScope(s)
+	_, _, _, err := i.ProcessBlockStatement(hook.Block.Statements, interpreter.DebugPass, false)
+	return err
+}
@@ -0,0 +1,154 @@
+package tester
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/ysugimoto/falco/v2/ast"
+	"github.com/ysugimoto/falco/v2/lexer"
+	"github.com/ysugimoto/falco/v2/parser"
+	"github.com/ysugimoto/falco/v2/resolver"
+	"github.com/ysugimoto/falco/v2/tester/syntax"
+)
+
+// Dependencies maps each VCL file to the test files which are affected by the change of the file.
+// All test files run the main VCL so the files which are reachable from main VCL via include statements affect all test files,
+// and the test file affects itself and the files which are included from it
+type Dependencies struct {
+	testFiles []string
+	affects   map[string]map[string]struct{}
+}
+
+// Dependencies builds include graph of the main VCL and test files
+func (t *Tester) Dependencies(main string) (*Dependencies, error) {
+	testFiles, err := t.listTestFiles(main)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	d := &Dependencies{
+		testFiles: testFiles,
+		affects:   make(map[string]map[string]struct{}),
+	}
+
+	mainFiles, err := includeGraph(main, t.config.IncludePaths, nil)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	for _, file := range mainFiles {
+		d.add(file, testFiles...)
+	}
+	for _, testFile := range testFiles {
+		files, err := includeGraph(testFile, t.config.IncludePaths, syntax.CustomParsers())
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		for _, file := range files {
+			d.add(file, testFile)
+		}
+	}
+	return d, nil
+}
+
+func (d *Dependencies) add(file string, testFiles ...string) {
+	if _, ok := d.affects[file]; !ok {
+		d.affects[file] = make(map[string]struct{})
+	}
+	for i := range testFiles {
+		d.affects[file][testFiles[i]] = struct{}{}
+	}
+}
+
+// Files returns all VCL files in the include graph which should be watched
+func (d *Dependencies) Files() []string {
+	files := make([]string, 0, len(d.affects))
+	for file := range d.affects {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+	return files
+}
+
+// Affected returns test files which should be run again by changes of the files.
+// Test files are returned in the order of found so that the result is stable
+func (d *Dependencies) Affected(files ...string) []string {
+	targets := make(map[string]struct{})
+	for _, file := range files {
+		abs, err := filepath.Abs(file)
+		if err != nil {
+			continue
+		}
+		for testFile := range d.affects[abs] {
+			targets[testFile] = struct{}{}
+		}
+	}
+
+	var affected []string
+	for _, testFile := range d.testFiles {
+		if _, ok := targets[testFile]; ok {
+			affected = append(affected, testFile)
+		}
+	}
+	return affected
+}
+
+// includeGraph returns absolute paths of the file and all files which are reachable via include statements.
+// Unresolvable modules and Fastly managed snippets are ignored because they could not be watched
+func includeGraph(file string, includePaths []string, opts []parser.CustomParser) ([]string, error) {
+	resolvers, err := resolver.NewFileResolvers(file, includePaths)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	rslv := resolvers[0]
+	main, err := rslv.MainVCL()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	seen := make(map[string]struct{})
+	var files []string
+	var walk func(vcl *resolver.VCL)
+	walk = func(vcl *resolver.VCL) {
+		abs, err := filepath.Abs(vcl.Name)
+		if err != nil {
+			return
+		}
+		if _, ok := seen[abs]; ok {
+			return
+		}
+		seen[abs] = struct{}{}
+		files = append(files, abs)
+
+		l := lexer.NewFromString(vcl.Data, lexer.WithFile(vcl.Name))
+		parsed, err := parser.New(l, parser.WithCustomParser(opts...)).ParseVCL()
+		if err != nil {
+			// The file is still watched in order to run tests again after the syntax error is fixed
+			return
+		}
+		for _, include := range collectIncludeStatements(parsed.Statements) {
+			if strings.HasPrefix(include.Module.Value, "snippet::") {
+				continue
+			}
+			if module, err := rslv.Resolve(include); err == nil {
+				walk(module)
+			}
+		}
+	}
+	walk(main)
+	return files, nil
+}
+
+// Include statements could be placed in root and subroutine bodies
+func collectIncludeStatements(statements []ast.Statement) []*ast.IncludeStatement {
+	var includes []*ast.IncludeStatement
+	for _, stmt := range statements {
+		switch t := stmt.(type) {
+		case *ast.IncludeStatement:
+			includes = append(includes, t)
+		case *ast.SubroutineDeclaration:
+			includes = append(includes, collectIncludeStatements(t.Block.Statements)...)
+		}
+	}
+	return includes
+}
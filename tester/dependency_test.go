@@ -0,0 +1,94 @@
+package tester
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/ysugimoto/falco/v2/config"
+)
+
+func TestDependencies(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"main.vcl":                  `include "routing"; sub vcl_recv { call routing; }`,
+		"unused.vcl":                `sub unused { return; }`,
+		"modules/routing.vcl":       `sub routing { include "snippet::routing"; }`,
+		"modules/helper.vcl":        `sub helper { return; }`,
+		"tests/main.test.vcl":       `sub test_recv { testing.call_subroutine("vcl_recv"); }`,
+		"tests/helper.test.vcl":     `include "helper"; sub test_helper { testing.call_subroutine("helper"); }`,
+		"tests/describe.test.vcl":   `describe recv { sub test_recv { testing.call_subroutine("vcl_recv"); } }`,
+		"tests/unresolved.test.vcl": `include "not_found"; sub test_recv { testing.call_subroutine("vcl_recv"); }`,
+	}
+	for name, content := range files {
+		p := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+	}
+
+	c := &config.TestConfig{
+		Filter:       "*.test.vcl",
+		IncludePaths: []string{filepath.Join(dir, "modules")},
+	}
+	deps, err := New(c, nil).Dependencies(filepath.Join(dir, "main.vcl"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	allTests := []string{
+		filepath.Join(dir, "tests/describe.test.vcl"),
+		filepath.Join(dir, "tests/helper.test.vcl"),
+		filepath.Join(dir, "tests/main.test.vcl"),
+		filepath.Join(dir, "tests/unresolved.test.vcl"),
+	}
+	tests := []struct {
+		name   string
+		files  []string
+		expect []string
+	}{
+		{
+			name:   "main VCL affects all test files",
+			files:  []string{filepath.Join(dir, "main.vcl")},
+			expect: allTests,
+		},
+		{
+			name:   "included module from main VCL affects all test files",
+			files:  []string{filepath.Join(dir, "modules/routing.vcl")},
+			expect: allTests,
+		},
+		{
+			name:   "included module from test file affects the test file",
+			files:  []string{filepath.Join(dir, "modules/helper.vcl")},
+			expect: []string{filepath.Join(dir, "tests/helper.test.vcl")},
+		},
+		{
+			name: "test files affect themselves",
+			files: []string{
+				filepath.Join(dir, "tests/main.test.vcl"),
+				filepath.Join(dir, "tests/describe.test.vcl"),
+			},
+			expect: []string{
+				filepath.Join(dir, "tests/describe.test.vcl"),
+				filepath.Join(dir, "tests/main.test.vcl"),
+			},
+		},
+		{
+			name:   "file which is not included affects nothing",
+			files:  []string{filepath.Join(dir, "unused.vcl")},
+			expect: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := cmp.Diff(tt.expect, deps.Affected(tt.files...)); diff != "" {
+				t.Errorf("Affected test files mismatch, diff=%s", diff)
+			}
+		})
+	}
+}
@@ -16,42 +16,71 @@ type TestCase struct {
 	Time  int64 // msec order
 	Skip  bool
 	Logs  []string
+	// Subtests which are processed by testing.run in the test case
+	Subtests []*TestCase
+}
+
+// IsPassed returns true if the test case and all subtests of the test case have passed
+func (t *TestCase) IsPassed() bool {
+	if t.Error != nil {
+		return false
+	}
+	for i := range t.Subtests {
+		if !t.Subtests[i].IsPassed() {
+			return false
+		}
+	}
+	return true
+}
+
+// Failure of the test case with its location
+type failure struct {
+	Error    string `json:"error,omitempty"`
+	File     string `json:"file,omitempty"`     // blank is reserved for no value
+	Line     int    `json:"line,omitempty"`     // 1-based 0 is reserved for no value
+	Position int    `json:"position,omitempty"` // 1-based
+}
+
+func newFailure(err error) failure {
+	switch e := err.(type) {
+	case *errors.AssertionError:
+		return failure{Error: e.Message, File: e.Token.File, Line: e.Token.Line, Position: e.Token.Position}
+	case *errors.TestingError:
+		return failure{Error: e.Message, File: e.Token.File, Line: e.Token.Line, Position: e.Token.Position}
+	default:
+		return failure{Error: e.Error()}
+	}
 }
 
 func (t *TestCase) MarshalJSON() ([]byte, error) {
 	v := struct {
-		Name     string   `json:"name"`
-		Error    string   `json:"error,omitempty"`
-		Group    string   `json:"group,omitempty"`
-		Scope    string   `json:"scope"`
-		Time     int64    `json:"elapsed_time"`
-		Skip     bool     `json:"skip"`
-		Logs     []string `json:"logs"`
-		File     string   `json:"file,omitempty"`     // blank is reserved for no value
-		Line     int      `json:"line,omitempty"`     // 1-based 0 is reserved for no value
-		Position int      `json:"position,omitempty"` // 1-based
+		Name  string   `json:"name"`
+		Group string   `json:"group,omitempty"`
+		Scope string   `json:"scope"`
+		Time  int64    `json:"elapsed_time"`
+		Skip  bool     `json:"skip"`
+		Logs  []string `json:"logs"`
+		failure
+		// All failures of the test case in soft assertion mode, the first one is also set to the error
+		Failures []failure   `json:"failures,omitempty"`
+		Subtests []*TestCase `json:"subtests,omitempty"`
 	}{
-		Name:  t.Name,
-		Group: t.Group,
-		Scope: t.Scope,
-		Time:  t.Time,
-		Skip:  t.Skip,
-		Logs:  t.Logs,
+		Name:     t.Name,
+		Group:    t.Group,
+		Scope:    t.Scope,
+		Time:     t.Time,
+		Skip:     t.Skip,
+		Logs:     t.Logs,
+		Subtests: t.Subtests,
 	}
 	if t.Error != nil {
-		switch e := t.Error.(type) {
-		case *errors.AssertionError:
-			v.Error = e.Message
-			v.File = e.Token.File
-			v.Line = e.Token.Line
-			v.Position = e.Token.Position
-		case *errors.TestingError:
-			v.Error = e.Message
-			v.File = e.Token.File
-			v.Line = e.Token.Line
-			v.Position = e.Token.Position
-		default:
-			v.Error = e.Error()
+		if e, ok := t.Error.(*errors.AssertionErrors); ok {
+			for i := range e.Errors {
+				v.Failures = append(v.Failures, newFailure(e.Errors[i]))
+			}
+			v.failure = v.Failures[0]
+		} else {
+			v.failure = newFailure(t.Error)
 		}
 	}
 	return json.Marshal(v)
@@ -65,7 +94,7 @@ type TestResult struct {
 
 func (t *TestResult) IsPassed() bool {
 	for i := range t.Cases {
-		if t.Cases[i].Error != nil {
+		if !t.Cases[i].IsPassed() {
 			return false
 		}
 	}
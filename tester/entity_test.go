@@ -137,6 +137,72 @@ func TestTestCaseMarshalJSON(t *testing.T) {
 				"error":        "no location",
 			},
 		},
+		{
+			name: "soft assertion failures are serialized with the first one as error",
+			input: &TestCase{
+				Name:  "soft",
+				Scope: "recv",
+				Time:  1,
+				Logs:  []string{},
+				Error: &errors.AssertionErrors{
+					Errors: []error{
+						&errors.AssertionError{Token: tok, Message: "first"},
+						fmt.Errorf("boom"),
+					},
+				},
+			},
+			expect: map[string]any{
+				"name":         "soft",
+				"scope":        "recv",
+				"elapsed_time": num(1),
+				"skip":         false,
+				"logs":         []any{},
+				"error":        "first",
+				"file":         tok.File,
+				"line":         num(tok.Line),
+				"position":     num(tok.Position),
+				"failures": []any{
+					map[string]any{
+						"error":    "first",
+						"file":     tok.File,
+						"line":     num(tok.Line),
+						"position": num(tok.Position),
+					},
+					map[string]any{
+						"error": "boom",
+					},
+				},
+			},
+		},
+		{
+			name: "subtests are serialized with their own results",
+			input: &TestCase{
+				Name:  "parent",
+				Scope: "recv",
+				Time:  2,
+				Logs:  []string{},
+				Subtests: []*TestCase{
+					{Name: "child", Scope: "recv", Time: 1, Error: fmt.Errorf("boom")},
+				},
+			},
+			expect: map[string]any{
+				"name":         "parent",
+				"scope":        "recv",
+				"elapsed_time": num(2),
+				"skip":         false,
+				"logs":         []any{},
+				"subtests": []any{
+					map[string]any{
+						"name":         "child",
+						"scope":        "recv",
+						"elapsed_time": num(1),
+						"skip":         false,
+						"logs":         nil,
+						"error":        "boom",
+					},
+				},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,82 @@
+package tester
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/ysugimoto/falco/v2/config"
+)
+
+// testFilter selects test suites to run by the name pattern of --run option,
+// and the @tag annotation values of --only-tags and --skip-tags options.
+// Unlike @skip annotation, unselected test suites are not reported as skipped
+type testFilter struct {
+	pattern  *regexp.Regexp
+	tags     []string
+	skipTags []string
+}
+
+func newTestFilter(c *config.TestConfig) (*testFilter, error) {
+	f := &testFilter{
+		tags:     splitTags(c.OnlyTags),
+		skipTags: splitTags(c.SkipTags),
+	}
+	if c.Run != "" {
+		pattern, err := regexp.Compile(c.Run)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Invalid run pattern %s", c.Run)
+		}
+		f.pattern = pattern
+	}
+	return f, nil
+}
+
+// Match returns true if the test suite should run.
+// The pattern is matched against the test name which is prefixed with the describe name like "group › name"
+func (f *testFilter) Match(group string, m *Metadata) bool {
+	if f.pattern != nil {
+		name := m.Name
+		if group != "" {
+			name = group + " › " + m.Name
+		}
+		if !f.pattern.MatchString(name) {
+			return false
+		}
+	}
+	if len(f.tags) > 0 && !m.hasAnyTag(f.tags) {
+		return false
+	}
+	if len(f.skipTags) > 0 && m.hasAnyTag(f.skipTags) {
+		return false
+	}
+	return true
+}
+
+// Check the test suite has any of the tags, inversed tags like !prod are not concerned
+func (m *Metadata) hasAnyTag(tags []string) bool {
+	for _, v := range m.Tags {
+		if v.Inverse {
+			continue
+		}
+		for i := range tags {
+			if v.Name == tags[i] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Tags could be provided as comma separated value like --only-tags=slow,network
+func splitTags(values []string) []string {
+	var tags []string
+	for _, v := range values {
+		for _, tag := range strings.Split(v, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				tags = append(tags, tag)
+			}
+		}
+	}
+	return tags
+}
@@ -0,0 +1,83 @@
+package tester
+
+import (
+	"testing"
+
+	"github.com/ysugimoto/falco/v2/config"
+)
+
+func TestTestFilter(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   *config.TestConfig
+		group    string
+		metadata *Metadata
+		expect   bool
+	}{
+		{
+			name:     "no filters",
+			config:   &config.TestConfig{},
+			metadata: &Metadata{Name: "test_recv"},
+			expect:   true,
+		},
+		{
+			name:     "name matches pattern",
+			config:   &config.TestConfig{Run: "^test_.*_recv$"},
+			metadata: &Metadata{Name: "test_redirect_recv"},
+			expect:   true,
+		},
+		{
+			name:     "name does not match pattern",
+			config:   &config.TestConfig{Run: "redirect"},
+			metadata: &Metadata{Name: "test_auth_recv"},
+			expect:   false,
+		},
+		{
+			name:     "group name matches pattern",
+			config:   &config.TestConfig{Run: "^redirects › "},
+			group:    "redirects",
+			metadata: &Metadata{Name: "test_recv"},
+			expect:   true,
+		},
+		{
+			name:     "has one of tags",
+			config:   &config.TestConfig{OnlyTags: []string{"slow,network"}},
+			metadata: &Metadata{Name: "test_recv", Tags: []Tag{{Name: "network"}}},
+			expect:   true,
+		},
+		{
+			name:     "does not have tags",
+			config:   &config.TestConfig{OnlyTags: []string{"slow"}},
+			metadata: &Metadata{Name: "test_recv", Tags: []Tag{{Name: "slow", Inverse: true}}},
+			expect:   false,
+		},
+		{
+			name:     "has skip tag",
+			config:   &config.TestConfig{SkipTags: []string{"slow"}},
+			metadata: &Metadata{Name: "test_recv", Tags: []Tag{{Name: "prod"}, {Name: "slow"}}},
+			expect:   false,
+		},
+		{
+			name:     "does not have skip tag",
+			config:   &config.TestConfig{SkipTags: []string{"slow"}},
+			metadata: &Metadata{Name: "test_recv", Tags: []Tag{{Name: "prod"}}},
+			expect:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := newTestFilter(tt.config)
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			if actual := f.Match(tt.group, tt.metadata); actual != tt.expect {
+				t.Errorf("Match result expects %t but got %t", tt.expect, actual)
+			}
+		})
+	}
+
+	if _, err := newTestFilter(&config.TestConfig{Run: "("}); err == nil {
+		t.Errorf("Expected error for invalid pattern")
+	}
+}
@@ -0,0 +1,123 @@
+package function
+
+import (
+	"github.com/ysugimoto/falco/v2/interpreter/context"
+	"github.com/ysugimoto/falco/v2/interpreter/function/errors"
+	"github.com/ysugimoto/falco/v2/interpreter/value"
+)
+
+const Assert_backend_request_Name = "assert.backend_request"
+
+func Assert_backend_request_Validate(args []value.Value) error {
+	if len(args) < 2 || len(args) > 3 {
+		return errors.ArgumentNotInRange(Assert_backend_request_Name, 2, 3, args)
+	}
+	if err := validateBackendRequestArguments(Assert_backend_request_Name, args); err != nil {
+		return err
+	}
+	if len(args) == 3 {
+		if args[2].Type() != value.StringType {
+			return errors.TypeMismatch(Assert_backend_request_Name, 3, value.StringType, args[2].Type())
+		}
+	}
+	return nil
+}
+
+func Assert_backend_request(ctx *context.Context, args ...value.Value) (value.Value, error) {
+	if err := Assert_backend_request_Validate(args); err != nil {
+		return nil, errors.NewTestingError("%s", err.Error())
+	}
+
+	// Check custom message
+	var message string
+	if len(args) == 3 {
+		message = value.Unwrap[*value.String](args[2]).Value
+	}
+
+	if _, err := findBackendRequest(ctx, args, message); err != nil {
+		return &value.Boolean{}, err
+	}
+	return &value.Boolean{Value: true}, nil
+}
+
+// Backend request assertions take backend name (or backend ident) and 1-origin index of the request
+// to the backend as the first and second arguments
+func validateBackendRequestArguments(name string, args []value.Value) error {
+	switch args[0].Type() {
+	case value.StringType, value.BackendType:
+	default:
+		return errors.TypeMismatch(name, 1, value.StringType, args[0].Type())
+	}
+	if args[1].Type() != value.IntegerType {
+		return errors.TypeMismatch(name, 2, value.IntegerType, args[1].Type())
+	}
+	return nil
+}
+
+func findBackendRequest(ctx *context.Context, args []value.Value, message string) (*context.BackendRequest, error) {
+	var backend string
+	if args[0].Type() == value.BackendType {
+		backend = value.Unwrap[*value.Backend](args[0]).String()
+	} else {
+		backend = value.Unwrap[*value.String](args[0]).Value
+	}
+	nth := value.Unwrap[*value.Integer](args[1]).Value
+
+	req := ctx.FindBackendRequest(backend, int(nth))
+	if req == nil {
+		if message != "" {
+			return nil, errors.NewAssertionError(args[1], "%s", message)
+		}
+		return nil, errors.NewAssertionError(
+			args[1],
+			"Request #%d to backend %s is not sent, %d request(s) are recorded in total",
+			nth, backend, len(ctx.BackendRequests),
+		)
+	}
+	return req, nil
+}
+
+// Common assertion for the single field of the recorded backend request like method, url and body
+func assertBackendRequestField(
+	ctx *context.Context,
+	name, field string,
+	args []value.Value,
+	get func(req *context.BackendRequest) string,
+) (value.Value, error) {
+
+	if len(args) < 3 || len(args) > 4 {
+		return nil, errors.NewTestingError("%s", errors.ArgumentNotInRange(name, 3, 4, args).Error())
+	}
+	if err := validateBackendRequestArguments(name, args); err != nil {
+		return nil, errors.NewTestingError("%s", err.Error())
+	}
+	for i := 2; i < len(args); i++ {
+		if args[i].Type() != value.StringType {
+			return nil, errors.NewTestingError("%s", errors.TypeMismatch(name, i+1, value.StringType, args[i].Type()).Error())
+		}
+	}
+
+	// Check custom message
+	var message string
+	if len(args) == 4 {
+		message = value.Unwrap[*value.String](args[3]).Value
+	}
+
+	req, err := findBackendRequest(ctx, args, message)
+	if err != nil {
+		return &value.Boolean{}, err
+	}
+	expect := value.Unwrap[*value.String](args[2]).Value
+	actual := get(req)
+	if actual != expect {
+		if message != "" {
+			return &value.Boolean{}, errors.NewAssertionError(&value.String{Value: actual}, "%s", message)
+		}
+		return &value.Boolean{}, errors.NewAssertionError(
+			&value.String{Value: actual},
+			"Backend request %s should be %q but %q",
+			field, expect, actual,
+		)
+	}
+	return &value.Boolean{Value: true}, nil
+}
@@ -0,0 +1,20 @@
+package function
+
+import (
+	"github.com/ysugimoto/falco/v2/interpreter/context"
+	"github.com/ysugimoto/falco/v2/interpreter/value"
+)
+
+const Assert_backend_request_body_Name = "assert.backend_request.body"
+
+func Assert_backend_request_body(ctx *context.Context, args ...value.Value) (value.Value, error) {
+	return assertBackendRequestField(
+		ctx,
+		Assert_backend_request_body_Name,
+		"BODY",
+		args,
+		func(req *context.BackendRequest) string {
+			return req.Body
+		},
+	)
+}
@@ -0,0 +1,71 @@
+package function
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/ysugimoto/falco/v2/interpreter/context"
+	"github.com/ysugimoto/falco/v2/interpreter/function/errors"
+	"github.com/ysugimoto/falco/v2/interpreter/value"
+)
+
+const Assert_backend_request_header_Name = "assert.backend_request.header"
+
+func Assert_backend_request_header_Validate(args []value.Value) error {
+	if len(args) < 4 || len(args) > 5 {
+		return errors.ArgumentNotInRange(Assert_backend_request_header_Name, 4, 5, args)
+	}
+	if err := validateBackendRequestArguments(Assert_backend_request_header_Name, args); err != nil {
+		return err
+	}
+	for i := 2; i < len(args); i++ {
+		if args[i].Type() != value.StringType {
+			return errors.TypeMismatch(Assert_backend_request_header_Name, i+1, value.StringType, args[i].Type())
+		}
+	}
+	return nil
+}
+
+func Assert_backend_request_header(ctx *context.Context, args ...value.Value) (value.Value, error) {
+	if err := Assert_backend_request_header_Validate(args); err != nil {
+		return nil, errors.NewTestingError("%s", err.Error())
+	}
+
+	// Check custom message
+	var message string
+	if len(args) == 5 {
+		message = value.Unwrap[*value.String](args[4]).Value
+	}
+
+	req, err := findBackendRequest(ctx, args, message)
+	if err != nil {
+		return &value.Boolean{}, err
+	}
+
+	name := value.Unwrap[*value.String](args[2]).Value
+	expect := value.Unwrap[*value.String](args[3]).Value
+	values, ok := req.Header[http.CanonicalHeaderKey(name)]
+	if !ok {
+		if message != "" {
+			return &value.Boolean{}, errors.NewAssertionError(&value.String{IsNotSet: true}, "%s", message)
+		}
+		return &value.Boolean{}, errors.NewAssertionError(
+			&value.String{IsNotSet: true},
+			"Backend request header %s is not set",
+			name,
+		)
+	}
+
+	actual := strings.Join(values, ", ")
+	if actual != expect {
+		if message != "" {
+			return &value.Boolean{}, errors.NewAssertionError(&value.String{Value: actual}, "%s", message)
+		}
+		return &value.Boolean{}, errors.NewAssertionError(
+			&value.String{Value: actual},
+			"Backend request header %s should be %q but %q",
+			name, expect, actual,
+		)
+	}
+	return &value.Boolean{Value: true}, nil
+}
@@ -0,0 +1,65 @@
+package function
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/ysugimoto/falco/v2/interpreter/function/errors"
+	"github.com/ysugimoto/falco/v2/interpreter/value"
+)
+
+func Test_Assert_backend_request_header(t *testing.T) {
+	tests := []struct {
+		args []value.Value
+		err  error
+	}{
+		{
+			args: []value.Value{
+				&value.String{Value: "origin0"},
+				&value.Integer{Value: 1},
+				&value.String{Value: "x-auth"},
+				&value.String{Value: "token"},
+			},
+		},
+		{
+			args: []value.Value{
+				&value.String{Value: "origin0"},
+				&value.Integer{Value: 1},
+				&value.String{Value: "X-Auth"},
+				&value.String{Value: "invalid"},
+			},
+			err: &errors.AssertionError{},
+		},
+		{
+			args: []value.Value{
+				&value.String{Value: "origin0"},
+				&value.Integer{Value: 2},
+				&value.String{Value: "X-Auth"},
+				&value.String{Value: "token"},
+				&value.String{Value: "custom_message"},
+			},
+			err: &errors.AssertionError{},
+		},
+		{
+			args: []value.Value{
+				&value.String{Value: "origin0"},
+				&value.Integer{Value: 1},
+				&value.String{Value: "X-Auth"},
+			},
+			err: &errors.TestingError{},
+		},
+	}
+
+	for i := range tests {
+		_, err := Assert_backend_request_header(testBackendRequestContext(), tests[i].args...)
+		if diff := cmp.Diff(
+			tests[i].err,
+			err,
+			cmpopts.IgnoreFields(errors.AssertionError{}, "Message", "Actual"),
+			cmpopts.IgnoreFields(errors.TestingError{}, "Message"),
+		); diff != "" {
+			t.Errorf("Assert_backend_request_header()[%d] error: diff=%s", i, diff)
+		}
+	}
+}
@@ -0,0 +1,20 @@
+package function
+
+import (
+	"github.com/ysugimoto/falco/v2/interpreter/context"
+	"github.com/ysugimoto/falco/v2/interpreter/value"
+)
+
+const Assert_backend_request_method_Name = "assert.backend_request.method"
+
+func Assert_backend_request_method(ctx *context.Context, args ...value.Value) (value.Value, error) {
+	return assertBackendRequestField(
+		ctx,
+		Assert_backend_request_method_Name,
+		"METHOD",
+		args,
+		func(req *context.BackendRequest) string {
+			return req.Method
+		},
+	)
+}
@@ -0,0 +1,149 @@
+package function
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/ysugimoto/falco/v2/interpreter/context"
+	"github.com/ysugimoto/falco/v2/interpreter/function/errors"
+	"github.com/ysugimoto/falco/v2/interpreter/value"
+)
+
+func testBackendRequestContext() *context.Context {
+	return &context.Context{
+		BackendRequests: []*context.BackendRequest{
+			{
+				Backend: "origin0",
+				Method:  "GET",
+				URL:     "http://example.com/foo",
+				Header:  http.Header{"X-Auth": {"token"}},
+			},
+			{
+				Backend: "origin1",
+				Method:  "POST",
+				URL:     "http://example.com/bar",
+				Header:  http.Header{},
+				Body:    "body",
+			},
+			{
+				Backend: "origin0",
+				Method:  "GET",
+				URL:     "http://example.com/baz",
+				Header:  http.Header{},
+			},
+		},
+	}
+}
+
+func Test_Assert_backend_request(t *testing.T) {
+	tests := []struct {
+		args []value.Value
+		err  error
+	}{
+		{
+			args: []value.Value{&value.String{Value: "origin0"}, &value.Integer{Value: 2}},
+		},
+		{
+			args: []value.Value{&value.String{Value: "origin1"}, &value.Integer{Value: 2}},
+			err:  &errors.AssertionError{},
+		},
+		{
+			args: []value.Value{
+				&value.String{Value: "origin2"},
+				&value.Integer{Value: 1},
+				&value.String{Value: "custom_message"},
+			},
+			err: &errors.AssertionError{},
+		},
+		{
+			args: []value.Value{&value.String{Value: "origin0"}, &value.String{Value: "1"}},
+			err:  &errors.TestingError{},
+		},
+	}
+
+	for i := range tests {
+		_, err := Assert_backend_request(testBackendRequestContext(), tests[i].args...)
+		if diff := cmp.Diff(
+			tests[i].err,
+			err,
+			cmpopts.IgnoreFields(errors.AssertionError{}, "Message", "Actual"),
+			cmpopts.IgnoreFields(errors.TestingError{}, "Message"),
+		); diff != "" {
+			t.Errorf("Assert_backend_request()[%d] error: diff=%s", i, diff)
+		}
+	}
+}
+
+func Test_Assert_backend_request_fields(t *testing.T) {
+	tests := []struct {
+		fn   func(*context.Context, ...value.Value) (value.Value, error)
+		args []value.Value
+		err  error
+	}{
+		{
+			fn: Assert_backend_request_method,
+			args: []value.Value{
+				&value.String{Value: "origin1"},
+				&value.Integer{Value: 1},
+				&value.String{Value: "POST"},
+			},
+		},
+		{
+			fn: Assert_backend_request_method,
+			args: []value.Value{
+				&value.String{Value: "origin0"},
+				&value.Integer{Value: 1},
+				&value.String{Value: "POST"},
+			},
+			err: &errors.AssertionError{},
+		},
+		{
+			fn: Assert_backend_request_url,
+			args: []value.Value{
+				&value.String{Value: "origin0"},
+				&value.Integer{Value: 2},
+				&value.String{Value: "http://example.com/baz"},
+			},
+		},
+		{
+			fn: Assert_backend_request_url,
+			args: []value.Value{
+				&value.String{Value: "origin0"},
+				&value.Integer{Value: 3},
+				&value.String{Value: "http://example.com/baz"},
+			},
+			err: &errors.AssertionError{},
+		},
+		{
+			fn: Assert_backend_request_body,
+			args: []value.Value{
+				&value.String{Value: "origin1"},
+				&value.Integer{Value: 1},
+				&value.String{Value: "body"},
+				&value.String{Value: "custom_message"},
+			},
+		},
+		{
+			fn: Assert_backend_request_body,
+			args: []value.Value{
+				&value.String{Value: "origin1"},
+				&value.Integer{Value: 1},
+			},
+			err: &errors.TestingError{},
+		},
+	}
+
+	for i := range tests {
+		_, err := tests[i].fn(testBackendRequestContext(), tests[i].args...)
+		if diff := cmp.Diff(
+			tests[i].err,
+			err,
+			cmpopts.IgnoreFields(errors.AssertionError{}, "Message", "Actual"),
+			cmpopts.IgnoreFields(errors.TestingError{}, "Message"),
+		); diff != "" {
+			t.Errorf("Assert_backend_request fields[%d] error: diff=%s", i, diff)
+		}
+	}
+}
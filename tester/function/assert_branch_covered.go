@@ -0,0 +1,85 @@
+package function
+
+import (
+	"fmt"
+
+	"github.com/ysugimoto/falco/v2/interpreter/context"
+	"github.com/ysugimoto/falco/v2/interpreter/function/errors"
+	"github.com/ysugimoto/falco/v2/interpreter/value"
+)
+
+const Assert_branch_covered_Name = "assert.branch_covered"
+
+func Assert_branch_covered_Validate(args []value.Value) error {
+	if len(args) < 3 || len(args) > 4 {
+		return errors.ArgumentNotInRange(Assert_branch_covered_Name, 3, 4, args)
+	}
+
+	if args[0].Type() != value.StringType {
+		return errors.TypeMismatch(Assert_branch_covered_Name, 1, value.StringType, args[0].Type())
+	}
+	if args[1].Type() != value.IntegerType {
+		return errors.TypeMismatch(Assert_branch_covered_Name, 2, value.IntegerType, args[1].Type())
+	}
+	switch args[2].Type() {
+	case value.StringType, value.IntegerType:
+	default:
+		return errors.TypeMismatch(Assert_branch_covered_Name, 3, value.StringType, args[2].Type())
+	}
+	return nil
+}
+
+// Assert_branch_covered asserts the branch at the line of the file is executed in the test.
+// Branch is the number of if/switch branches which starts from 1, or "true"/"false" of the condition
+func Assert_branch_covered(ctx *context.Context, args ...value.Value) (value.Value, error) {
+	if err := Assert_branch_covered_Validate(args); err != nil {
+		return nil, errors.NewTestingError("%s", err.Error())
+	}
+
+	if ctx.Coverage == nil {
+		return &value.Boolean{}, errors.NewTestingError(
+			"%s: coverage is not measured, run test with --coverage option", Assert_branch_covered_Name,
+		)
+	}
+
+	file := value.Unwrap[*value.String](args[0]).Value
+	line := value.Unwrap[*value.Integer](args[1]).Value
+	var branch string
+	switch v := args[2].(type) {
+	case *value.Integer:
+		branch = fmt.Sprint(v.Value)
+	case *value.String:
+		branch = v.Value
+	}
+
+	// Check custom message
+	var message string
+	if len(args) == 4 { // (file, line, branch, message)
+		if args[3].Type() != value.StringType {
+			return &value.Boolean{}, errors.NewTestingError(
+				"%s: 4th argument must be STRING, %s provided",
+				Assert_branch_covered_Name, args[3].Type(),
+			)
+		}
+		message = value.Unwrap[*value.String](args[3]).Value
+	}
+
+	ids := ctx.Coverage.FindBranches(file, int(line), branch)
+	if len(ids) == 0 {
+		return &value.Boolean{}, errors.NewTestingError(
+			"%s: branch %s at %s:%d is not found", Assert_branch_covered_Name, branch, file, line,
+		)
+	}
+
+	for _, id := range ids {
+		if ctx.CoverageHits[id] > 0 {
+			return &value.Boolean{Value: true}, nil
+		}
+	}
+	if message != "" {
+		return &value.Boolean{}, errors.NewAssertionError(args[2], "%s", message)
+	}
+	return &value.Boolean{}, errors.NewAssertionError(
+		args[2], "Branch %s at %s:%d is not covered", branch, file, line,
+	)
+}
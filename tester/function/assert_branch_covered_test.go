@@ -0,0 +1,117 @@
+package function
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/ysugimoto/falco/v2/ast"
+	"github.com/ysugimoto/falco/v2/interpreter/context"
+	"github.com/ysugimoto/falco/v2/interpreter/function/errors"
+	"github.com/ysugimoto/falco/v2/interpreter/value"
+	"github.com/ysugimoto/falco/v2/tester/shared"
+	"github.com/ysugimoto/falco/v2/token"
+)
+
+func Test_Assert_branch_covered(t *testing.T) {
+	c := shared.NewCoverage()
+	ifStmt := &ast.IfStatement{
+		Meta: ast.New(token.Token{File: "/path/to/vcl/main.vcl", Line: 5, Position: 3}, 1),
+	}
+	c.SetupBranch("/path/to/vcl/main.vcl:branch_5_3_1", ifStmt)
+	c.SetupBranch("/path/to/vcl/main.vcl:branch_5_3_2", ifStmt)
+	expr := &ast.IfExpression{
+		Meta: ast.New(token.Token{File: "/path/to/vcl/main.vcl", Line: 12, Position: 20}, 1),
+	}
+	c.SetupBranch("/path/to/vcl/main.vcl:branch_12_20_true", expr)
+	c.SetupBranch("/path/to/vcl/main.vcl:branch_12_20_false", expr)
+
+	tests := []struct {
+		args []value.Value
+		err  error
+	}{
+		{
+			args: []value.Value{
+				&value.String{Value: "main.vcl"},
+				&value.Integer{Value: 5},
+				&value.Integer{Value: 1},
+			},
+		},
+		{
+			args: []value.Value{
+				&value.String{Value: "/path/to/vcl/main.vcl"},
+				&value.Integer{Value: 5},
+				&value.String{Value: "1"},
+			},
+		},
+		{
+			args: []value.Value{
+				&value.String{Value: "vcl/main.vcl"},
+				&value.Integer{Value: 5},
+				&value.Integer{Value: 2},
+			},
+			err: &errors.AssertionError{},
+		},
+		{
+			args: []value.Value{
+				&value.String{Value: "main.vcl"},
+				&value.Integer{Value: 12},
+				&value.String{Value: "false"},
+				&value.String{Value: "custom_message"},
+			},
+			err: &errors.AssertionError{},
+		},
+		{
+			args: []value.Value{
+				&value.String{Value: "main.vcl"},
+				&value.Integer{Value: 12},
+				&value.String{Value: "true"},
+			},
+		},
+		{
+			args: []value.Value{
+				&value.String{Value: "in/main.vcl"},
+				&value.Integer{Value: 5},
+				&value.Integer{Value: 1},
+			},
+			err: &errors.TestingError{},
+		},
+		{
+			args: []value.Value{
+				&value.String{Value: "main.vcl"},
+				&value.Integer{Value: 5},
+				&value.Integer{Value: 3},
+			},
+			err: &errors.TestingError{},
+		},
+		{
+			args: []value.Value{
+				&value.String{Value: "main.vcl"},
+				&value.String{Value: "5"},
+				&value.Integer{Value: 1},
+			},
+			err: &errors.TestingError{},
+		},
+	}
+
+	for i := range tests {
+		_, err := Assert_branch_covered(
+			&context.Context{
+				Coverage: c,
+				CoverageHits: map[string]uint64{
+					"/path/to/vcl/main.vcl:branch_5_3_1":      1,
+					"/path/to/vcl/main.vcl:branch_12_20_true": 1,
+				},
+			},
+			tests[i].args...,
+		)
+		if diff := cmp.Diff(
+			tests[i].err,
+			err,
+			cmpopts.IgnoreFields(errors.AssertionError{}, "Message", "Actual"),
+			cmpopts.IgnoreFields(errors.TestingError{}, "Message"),
+		); diff != "" {
+			t.Errorf("Assert_branch_covered()[%d] error: diff=%s", i, diff)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package function
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/ysugimoto/falco/v2/interpreter/context"
+	"github.com/ysugimoto/falco/v2/interpreter/function/errors"
+	"github.com/ysugimoto/falco/v2/interpreter/value"
+)
+
+const Assert_call_order_Name = "assert.call_order"
+
+func Assert_call_order_Validate(args []value.Value) error {
+	if len(args) < 2 {
+		return errors.ArgumentNotEnough(Assert_call_order_Name, 2, args)
+	}
+
+	for i := range args {
+		if args[i].Type() != value.StringType {
+			return errors.TypeMismatch(Assert_call_order_Name, i+1, value.StringType, args[i].Type())
+		}
+	}
+	return nil
+}
+
+// Assert subroutines are called in the order of arguments.
+// Other subroutines could be called between them, so the arguments should be the subsequence of the call trace
+func Assert_call_order(ctx *context.Context, args ...value.Value) (value.Value, error) {
+	if err := Assert_call_order_Validate(args); err != nil {
+		return nil, errors.NewTestingError("%s", err.Error())
+	}
+
+	names := make([]string, len(args))
+	expects := make(map[string]struct{}, len(args))
+	for i := range args {
+		names[i] = value.Unwrap[*value.String](args[i]).Value
+		expects[names[i]] = struct{}{}
+	}
+
+	var matched int
+	var actual []string
+	for _, name := range ctx.SubroutineCallTrace {
+		if _, ok := expects[name]; !ok {
+			continue
+		}
+		actual = append(actual, name)
+		if matched < len(names) && names[matched] == name {
+			matched++
+		}
+	}
+	if matched == len(names) {
+		return value.True, nil
+	}
+
+	for _, name := range names {
+		if !slices.Contains(actual, name) {
+			return value.False, errors.NewAssertionError(
+				&value.String{Value: strings.Join(actual, ", ")},
+				"Subroutine %s is not called",
+				name,
+			)
+		}
+	}
+	return value.False, errors.NewAssertionError(
+		&value.String{Value: strings.Join(actual, ", ")},
+		"Subroutines should be called in order of %s but actual order is %s",
+		strings.Join(names, ", "), strings.Join(actual, ", "),
+	)
+}
@@ -0,0 +1,91 @@
+package function
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/ysugimoto/falco/v2/interpreter/context"
+	"github.com/ysugimoto/falco/v2/interpreter/function/errors"
+	"github.com/ysugimoto/falco/v2/interpreter/value"
+)
+
+func Test_Assert_call_order(t *testing.T) {
+
+	tests := []struct {
+		args   []value.Value
+		err    error
+		expect *value.Boolean
+	}{
+		{
+			args: []value.Value{
+				&value.String{Value: "normalize_path"},
+				&value.String{Value: "add_cors_headers"},
+			},
+			expect: &value.Boolean{Value: true},
+		},
+		{
+			// Other subroutines could be called between them
+			args: []value.Value{
+				&value.String{Value: "vcl_recv"},
+				&value.String{Value: "vcl_deliver"},
+			},
+			expect: &value.Boolean{Value: true},
+		},
+		{
+			args: []value.Value{
+				&value.String{Value: "add_cors_headers"},
+				&value.String{Value: "normalize_path"},
+			},
+			expect: &value.Boolean{Value: false},
+			err:    &errors.AssertionError{},
+		},
+		{
+			args: []value.Value{
+				&value.String{Value: "normalize_path"},
+				&value.String{Value: "not_called"},
+			},
+			expect: &value.Boolean{Value: false},
+			err:    &errors.AssertionError{},
+		},
+		{
+			args: []value.Value{
+				&value.String{Value: "normalize_path"},
+			},
+			expect: nil,
+			err:    &errors.TestingError{},
+		},
+		{
+			args: []value.Value{
+				&value.String{Value: "normalize_path"},
+				&value.Integer{Value: 1},
+			},
+			expect: nil,
+			err:    &errors.TestingError{},
+		},
+	}
+
+	for i := range tests {
+		ret, err := Assert_call_order(
+			&context.Context{
+				SubroutineCallTrace: []string{
+					"test_vcl", "vcl_recv", "normalize_path", "vcl_deliver", "add_cors_headers",
+				},
+			},
+			tests[i].args...,
+		)
+		if diff := cmp.Diff(
+			tests[i].err,
+			err,
+			cmpopts.IgnoreFields(errors.AssertionError{}, "Message", "Actual"),
+			cmpopts.IgnoreFields(errors.TestingError{}, "Message"),
+		); diff != "" {
+			t.Errorf("Assert_call_order()[%d] error: diff=%s", i, diff)
+		}
+		if tests[i].expect != nil {
+			if diff := cmp.Diff(tests[i].expect, ret); diff != "" {
+				t.Errorf("Assert_call_order()[%d] return value mismatch: diff=%s", i, diff)
+			}
+		}
+	}
+}
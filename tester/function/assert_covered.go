@@ -0,0 +1,73 @@
+package function
+
+import (
+	"github.com/ysugimoto/falco/v2/interpreter/context"
+	"github.com/ysugimoto/falco/v2/interpreter/function/errors"
+	"github.com/ysugimoto/falco/v2/interpreter/value"
+)
+
+const Assert_covered_Name = "assert.covered"
+
+func Assert_covered_Validate(args []value.Value) error {
+	if len(args) < 1 || len(args) > 2 {
+		return errors.ArgumentNotInRange(Assert_covered_Name, 1, 2, args)
+	}
+
+	if args[0].Type() != value.StringType {
+		return errors.TypeMismatch(Assert_covered_Name, 1, value.StringType, args[0].Type())
+	}
+	return nil
+}
+
+// Assert_covered asserts the subroutine or the coverage marker is executed in the test.
+// The first argument accepts subroutine name or coverage marker id like "stmt_10_5"
+func Assert_covered(ctx *context.Context, args ...value.Value) (value.Value, error) {
+	if err := Assert_covered_Validate(args); err != nil {
+		return nil, errors.NewTestingError("%s", err.Error())
+	}
+
+	if ctx.Coverage == nil {
+		return &value.Boolean{}, errors.NewTestingError(
+			"%s: coverage is not measured, run test with --coverage option", Assert_covered_Name,
+		)
+	}
+
+	name := value.Unwrap[*value.String](args[0]).Value
+
+	// Check custom message
+	var message string
+	if len(args) == 2 { // (name, message)
+		if args[1].Type() != value.StringType {
+			return &value.Boolean{}, errors.NewTestingError(
+				"%s: 2nd argument must be STRING, %s provided",
+				Assert_covered_Name, args[1].Type(),
+			)
+		}
+		message = value.Unwrap[*value.String](args[1]).Value
+	}
+
+	var ids []string
+	if id, ok := ctx.Coverage.SubroutineID(name); ok {
+		ids = []string{id}
+	} else {
+		ids = ctx.Coverage.FindMarkers(name)
+	}
+	if len(ids) == 0 {
+		return &value.Boolean{}, errors.NewTestingError(
+			"%s: subroutine or coverage marker %s is not found", Assert_covered_Name, name,
+		)
+	}
+
+	// Markers which have the same id in different files are covered if some of them are executed
+	var hits uint64
+	for _, id := range ids {
+		hits += ctx.CoverageHits[id]
+	}
+	if hits == 0 {
+		if message != "" {
+			return &value.Boolean{}, errors.NewAssertionError(args[0], "%s", message)
+		}
+		return &value.Boolean{}, errors.NewAssertionError(args[0], "%s is not covered", name)
+	}
+	return &value.Boolean{Value: true}, nil
+}
@@ -0,0 +1,91 @@
+package function
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/ysugimoto/falco/v2/ast"
+	"github.com/ysugimoto/falco/v2/interpreter/context"
+	"github.com/ysugimoto/falco/v2/interpreter/function/errors"
+	"github.com/ysugimoto/falco/v2/interpreter/value"
+	"github.com/ysugimoto/falco/v2/tester/shared"
+	"github.com/ysugimoto/falco/v2/token"
+)
+
+func Test_Assert_covered(t *testing.T) {
+	c := shared.NewCoverage()
+	c.SetupSubroutine("main.vcl:sub_1_1", &ast.SubroutineDeclaration{
+		Meta: ast.New(token.Token{File: "main.vcl", Line: 1, Position: 1}, 0),
+		Name: &ast.Ident{Value: "vcl_recv"},
+	})
+	c.SetupSubroutine("main.vcl:sub_10_1", &ast.SubroutineDeclaration{
+		Meta: ast.New(token.Token{File: "main.vcl", Line: 10, Position: 1}, 0),
+		Name: &ast.Ident{Value: "vcl_fetch"},
+	})
+	c.SetupStatement("main.vcl:stmt_2_3", &ast.EsiStatement{
+		Meta: ast.New(token.Token{File: "main.vcl", Line: 2, Position: 3}, 1),
+	})
+
+	tests := []struct {
+		args []value.Value
+		err  error
+	}{
+		{
+			args: []value.Value{&value.String{Value: "vcl_recv"}},
+		},
+		{
+			args: []value.Value{&value.String{Value: "vcl_fetch"}},
+			err:  &errors.AssertionError{},
+		},
+		{
+			args: []value.Value{
+				&value.String{Value: "vcl_fetch"},
+				&value.String{Value: "custom_message"},
+			},
+			err: &errors.AssertionError{},
+		},
+		{
+			args: []value.Value{&value.String{Value: "stmt_2_3"}},
+		},
+		{
+			args: []value.Value{&value.String{Value: "main.vcl:stmt_2_3"}},
+		},
+		{
+			args: []value.Value{&value.String{Value: "vcl_deliver"}},
+			err:  &errors.TestingError{},
+		},
+		{
+			args: []value.Value{&value.Integer{Value: 1}},
+			err:  &errors.TestingError{},
+		},
+	}
+
+	for i := range tests {
+		_, err := Assert_covered(
+			&context.Context{
+				Coverage: c,
+				CoverageHits: map[string]uint64{
+					"main.vcl:sub_1_1":  1,
+					"main.vcl:stmt_2_3": 2,
+				},
+			},
+			tests[i].args...,
+		)
+		if diff := cmp.Diff(
+			tests[i].err,
+			err,
+			cmpopts.IgnoreFields(errors.AssertionError{}, "Message", "Actual"),
+			cmpopts.IgnoreFields(errors.TestingError{}, "Message"),
+		); diff != "" {
+			t.Errorf("Assert_covered()[%d] error: diff=%s", i, diff)
+		}
+	}
+
+	t.Run("coverage is not measured", func(t *testing.T) {
+		_, err := Assert_covered(&context.Context{}, &value.String{Value: "vcl_recv"})
+		if _, ok := err.(*errors.TestingError); !ok {
+			t.Errorf("Assert_covered() must return testing error without coverage, got %v", err)
+		}
+	})
+}
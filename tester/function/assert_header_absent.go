@@ -0,0 +1,37 @@
+package function
+
+import (
+	"github.com/ysugimoto/falco/v2/interpreter/context"
+	"github.com/ysugimoto/falco/v2/interpreter/function/errors"
+	"github.com/ysugimoto/falco/v2/interpreter/value"
+)
+
+const Assert_header_absent_Name = "assert.header_absent"
+
+func Assert_header_absent(ctx *context.Context, args ...value.Value) (value.Value, error) {
+	if err := Assert_header_exists_Validate(Assert_header_absent_Name, args); err != nil {
+		return nil, errors.NewTestingError("%s", err.Error())
+	}
+
+	ident := value.Unwrap[*value.Ident](args[0]).Value
+	actual, exists, err := findHeader(ctx, ident)
+	if err != nil {
+		return nil, errors.NewTestingError("%s", err.Error())
+	}
+	if !exists {
+		return &value.Boolean{Value: true}, nil
+	}
+
+	if len(args) == 2 {
+		return &value.Boolean{}, errors.NewAssertionError(
+			&value.String{Value: actual},
+			"%s",
+			value.Unwrap[*value.String](args[1]).Value,
+		)
+	}
+	return &value.Boolean{}, errors.NewAssertionError(
+		&value.String{Value: actual},
+		"Header %s should be absent but has value %q",
+		ident, actual,
+	)
+}
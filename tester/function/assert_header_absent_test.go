@@ -0,0 +1,51 @@
+package function
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/ysugimoto/falco/v2/interpreter/function/errors"
+	"github.com/ysugimoto/falco/v2/interpreter/value"
+)
+
+func Test_Assert_header_absent(t *testing.T) {
+	tests := []struct {
+		args []value.Value
+		err  error
+	}{
+		{
+			args: []value.Value{&value.Ident{Value: "req.http.X-Foo"}},
+		},
+		{
+			args: []value.Value{&value.Ident{Value: "beresp.http.X-Foo"}},
+		},
+		{
+			args: []value.Value{&value.Ident{Value: "req.http.X-Empty"}},
+			err:  &errors.AssertionError{},
+		},
+		{
+			args: []value.Value{
+				&value.Ident{Value: "resp.http.X-Foo"},
+				&value.String{Value: "custom_message"},
+			},
+			err: &errors.AssertionError{},
+		},
+		{
+			args: []value.Value{&value.Ident{Value: "client.http.X-Foo"}},
+			err:  &errors.TestingError{},
+		},
+	}
+
+	for i := range tests {
+		_, err := Assert_header_absent(testHeaderContext(), tests[i].args...)
+		if diff := cmp.Diff(
+			tests[i].err,
+			err,
+			cmpopts.IgnoreFields(errors.AssertionError{}, "Message", "Actual"),
+			cmpopts.IgnoreFields(errors.TestingError{}, "Message"),
+		); diff != "" {
+			t.Errorf("Assert_header_absent()[%d] error: diff=%s", i, diff)
+		}
+	}
+}
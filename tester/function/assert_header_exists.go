@@ -0,0 +1,98 @@
+package function
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/ysugimoto/falco/v2/interpreter/context"
+	"github.com/ysugimoto/falco/v2/interpreter/function/errors"
+	"github.com/ysugimoto/falco/v2/interpreter/value"
+)
+
+const Assert_header_exists_Name = "assert.header_exists"
+
+func Assert_header_exists_Validate(name string, args []value.Value) error {
+	if len(args) < 1 || len(args) > 2 {
+		return errors.ArgumentNotInRange(name, 1, 2, args)
+	}
+	if args[0].Type() != value.IdentType {
+		return errors.TypeMismatch(name, 1, value.IdentType, args[0].Type())
+	}
+	if len(args) == 2 && args[1].Type() != value.StringType {
+		return errors.TypeMismatch(name, 2, value.StringType, args[1].Type())
+	}
+	return nil
+}
+
+func Assert_header_exists(ctx *context.Context, args ...value.Value) (value.Value, error) {
+	if err := Assert_header_exists_Validate(Assert_header_exists_Name, args); err != nil {
+		return nil, errors.NewTestingError("%s", err.Error())
+	}
+
+	ident := value.Unwrap[*value.Ident](args[0]).Value
+	_, exists, err := findHeader(ctx, ident)
+	if err != nil {
+		return nil, errors.NewTestingError("%s", err.Error())
+	}
+	if exists {
+		return &value.Boolean{Value: true}, nil
+	}
+
+	if len(args) == 2 {
+		return &value.Boolean{}, errors.NewAssertionError(
+			&value.String{IsNotSet: true},
+			"%s",
+			value.Unwrap[*value.String](args[1]).Value,
+		)
+	}
+	return &value.Boolean{}, errors.NewAssertionError(&value.String{IsNotSet: true}, "Header %s should exist", ident)
+}
+
+// Find the header of the variable like resp.http.X-Foo in the actual HTTP object and returns the value and existence.
+// Header which has empty value is treated as exists unlike comparing with empty string
+func findHeader(ctx *context.Context, ident string) (string, bool, error) {
+	object, name, found := strings.Cut(ident, ".http.")
+	if !found || name == "" {
+		return "", false, fmt.Errorf("%s is not a header variable like resp.http.X-Foo", ident)
+	}
+	if strings.Contains(name, ":") {
+		return "", false, fmt.Errorf("Header subfield %s could not be asserted, use assert.is_notset instead", name)
+	}
+
+	// HTTP object which has not been created yet in the current scope does not have any headers
+	switch object {
+	case "req":
+		if ctx.Request == nil {
+			return "", false, nil
+		}
+		return lookupHeader(ctx.Request.Header, ctx.Request.IsAssigned, name)
+	case "bereq":
+		if ctx.BackendRequest == nil {
+			return "", false, nil
+		}
+		return lookupHeader(ctx.BackendRequest.Header, ctx.BackendRequest.IsAssigned, name)
+	case "beresp":
+		if ctx.BackendResponse == nil {
+			return "", false, nil
+		}
+		return lookupHeader(ctx.BackendResponse.Header, ctx.BackendResponse.IsAssigned, name)
+	case "obj":
+		if ctx.Object == nil {
+			return "", false, nil
+		}
+		return lookupHeader(ctx.Object.Header, ctx.Object.IsAssigned, name)
+	case "resp":
+		if ctx.Response == nil {
+			return "", false, nil
+		}
+		return lookupHeader(ctx.Response.Header, ctx.Response.IsAssigned, name)
+	default:
+		return "", false, fmt.Errorf("Header of %s could not be asserted, must be one of req, bereq, beresp, obj or resp", object)
+	}
+}
+
+func lookupHeader(h http.Header, isAssigned func(string) bool, name string) (string, bool, error) {
+	values, ok := h[http.CanonicalHeaderKey(name)]
+	return strings.Join(values, ", "), ok || isAssigned(name), nil
+}
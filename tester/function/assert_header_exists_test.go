@@ -0,0 +1,74 @@
+package function
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/ysugimoto/falco/v2/interpreter/context"
+	"github.com/ysugimoto/falco/v2/interpreter/function/errors"
+	ihttp "github.com/ysugimoto/falco/v2/interpreter/http"
+	"github.com/ysugimoto/falco/v2/interpreter/value"
+)
+
+func testHeaderContext() *context.Context {
+	req := ihttp.WrapRequest(&http.Request{Header: http.Header{"X-Empty": {""}}})
+	resp := ihttp.WrapResponse(&http.Response{Header: http.Header{"X-Foo": {"foo"}}})
+	return &context.Context{
+		Request:  req,
+		Response: resp,
+	}
+}
+
+func Test_Assert_header_exists(t *testing.T) {
+	tests := []struct {
+		args []value.Value
+		err  error
+	}{
+		{
+			args: []value.Value{&value.Ident{Value: "resp.http.X-Foo"}},
+		},
+		{
+			args: []value.Value{&value.Ident{Value: "resp.http.x-foo"}},
+		},
+		{
+			args: []value.Value{&value.Ident{Value: "req.http.X-Empty"}},
+		},
+		{
+			args: []value.Value{&value.Ident{Value: "req.http.X-Foo"}},
+			err:  &errors.AssertionError{},
+		},
+		{
+			args: []value.Value{
+				&value.Ident{Value: "bereq.http.X-Foo"},
+				&value.String{Value: "custom_message"},
+			},
+			err: &errors.AssertionError{},
+		},
+		{
+			args: []value.Value{&value.Ident{Value: "req.url"}},
+			err:  &errors.TestingError{},
+		},
+		{
+			args: []value.Value{&value.Ident{Value: "req.http.Cookie:session"}},
+			err:  &errors.TestingError{},
+		},
+		{
+			args: []value.Value{&value.String{Value: "X-Foo"}},
+			err:  &errors.TestingError{},
+		},
+	}
+
+	for i := range tests {
+		_, err := Assert_header_exists(testHeaderContext(), tests[i].args...)
+		if diff := cmp.Diff(
+			tests[i].err,
+			err,
+			cmpopts.IgnoreFields(errors.AssertionError{}, "Message", "Actual"),
+			cmpopts.IgnoreFields(errors.TestingError{}, "Message"),
+		); diff != "" {
+			t.Errorf("Assert_header_exists()[%d] error: diff=%s", i, diff)
+		}
+	}
+}
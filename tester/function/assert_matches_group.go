@@ -0,0 +1,90 @@
+package function
+
+import (
+	"github.com/ysugimoto/falco/v2/interpreter/context"
+	"github.com/ysugimoto/falco/v2/interpreter/function/errors"
+	"github.com/ysugimoto/falco/v2/interpreter/value"
+	regexp "go.elara.ws/pcre"
+)
+
+const Assert_matches_group_Name = "assert.matches_group"
+
+var Assert_matches_group_ArgumentTypes = []value.Type{value.StringType, value.StringType, value.IntegerType, value.StringType}
+
+func Assert_matches_group_Validate(args []value.Value) error {
+	if len(args) < 4 || len(args) > 5 {
+		return errors.ArgumentNotInRange(Assert_matches_group_Name, 4, 5, args)
+	}
+
+	for i := range Assert_matches_group_ArgumentTypes {
+		if args[i].Type() != Assert_matches_group_ArgumentTypes[i] {
+			return errors.TypeMismatch(Assert_matches_group_Name, i+1, Assert_matches_group_ArgumentTypes[i], args[i].Type())
+		}
+	}
+
+	if len(args) == 5 {
+		if args[4].Type() != value.StringType {
+			return errors.TypeMismatch(Assert_matches_group_Name, 5, value.StringType, args[4].Type())
+		}
+	}
+	return nil
+}
+
+func Assert_matches_group(ctx *context.Context, args ...value.Value) (value.Value, error) {
+	if err := Assert_matches_group_Validate(args); err != nil {
+		return nil, errors.NewTestingError("%s", err.Error())
+	}
+
+	// Check custom message
+	var message string
+	if len(args) == 5 {
+		message = value.Unwrap[*value.String](args[4]).Value
+	}
+
+	actual := value.Unwrap[*value.String](args[0])
+	pattern := value.Unwrap[*value.String](args[1])
+	group := value.Unwrap[*value.Integer](args[2])
+	expect := value.Unwrap[*value.String](args[3])
+
+	re, err := regexp.Compile(pattern.Value)
+	if err != nil {
+		return nil, errors.NewTestingError(
+			"Invalid regexp string provided: %s",
+			pattern.Value,
+		)
+	}
+
+	matches := re.FindStringSubmatch(actual.Value)
+	if len(matches) == 0 {
+		if message != "" {
+			return &value.Boolean{}, errors.NewAssertionError(actual, "%s", message)
+		}
+		return &value.Boolean{}, errors.NewAssertionError(
+			actual,
+			`"%s" should match against %s`,
+			actual.Value,
+			pattern.Value,
+		)
+	}
+
+	// Captured groups are not set to re.group.N because assertion must not change the program state
+	if group.Value < 0 || group.Value >= int64(len(matches)) {
+		return nil, errors.NewTestingError(
+			"Capture group %d does not exist in %s, it has %d groups",
+			group.Value, pattern.Value, len(matches)-1,
+		)
+	}
+
+	captured := &value.String{Value: matches[group.Value]}
+	if captured.Value != expect.Value {
+		if message != "" {
+			return &value.Boolean{}, errors.NewAssertionError(captured, "%s", message)
+		}
+		return &value.Boolean{}, errors.NewAssertionError(
+			captured,
+			`Capture group %d of %s should be "%s" but got "%s"`,
+			group.Value, pattern.Value, expect.Value, captured.Value,
+		)
+	}
+	return &value.Boolean{Value: true}, nil
+}
@@ -0,0 +1,130 @@
+package function
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/ysugimoto/falco/v2/interpreter/context"
+	"github.com/ysugimoto/falco/v2/interpreter/function/errors"
+	"github.com/ysugimoto/falco/v2/interpreter/value"
+)
+
+func Test_Assert_matches_group(t *testing.T) {
+
+	tests := []struct {
+		args   []value.Value
+		err    error
+		expect *value.Boolean
+	}{
+		{
+			args: []value.Value{
+				&value.String{Value: "/v2/users"},
+				&value.String{Value: `^/v(\d+)/(\w+)`},
+				&value.Integer{Value: 1},
+				&value.String{Value: "2"},
+			},
+			expect: &value.Boolean{Value: true},
+		},
+		{
+			args: []value.Value{
+				&value.String{Value: "/v2/users"},
+				&value.String{Value: `^/v(\d+)/(\w+)`},
+				&value.Integer{Value: 2},
+				&value.String{Value: "items"},
+			},
+			expect: &value.Boolean{Value: false},
+			err:    &errors.AssertionError{},
+		},
+		{
+			args: []value.Value{
+				&value.String{Value: "/users"},
+				&value.String{Value: `^/v(\d+)/`},
+				&value.Integer{Value: 1},
+				&value.String{Value: "2"},
+				&value.String{Value: "custom_message"},
+			},
+			expect: &value.Boolean{Value: false},
+			err: &errors.AssertionError{
+				Message: "custom_message",
+			},
+		},
+		{
+			args: []value.Value{
+				&value.String{Value: "/v2/users"},
+				&value.String{Value: `^/v(\d+)/`},
+				&value.Integer{Value: 2},
+				&value.String{Value: "users"},
+			},
+			expect: nil,
+			err:    &errors.TestingError{},
+		},
+		{
+			args: []value.Value{
+				&value.String{Value: "/v2/users"},
+				&value.String{Value: `^/v(\d+)/`},
+				&value.String{Value: "1"},
+				&value.String{Value: "2"},
+			},
+			expect: nil,
+			err:    &errors.TestingError{},
+		},
+		{
+			args: []value.Value{
+				&value.String{Value: "/v2/users"},
+				&value.String{Value: "^++a"},
+				&value.Integer{Value: 1},
+				&value.String{Value: "2"},
+			},
+			expect: nil,
+			err:    &errors.TestingError{},
+		},
+	}
+
+	for i := range tests {
+		ret, err := Assert_matches_group(
+			&context.Context{},
+			tests[i].args...,
+		)
+		if diff := cmp.Diff(
+			tests[i].err,
+			err,
+			cmpopts.IgnoreFields(errors.AssertionError{}, "Message", "Actual"),
+			cmpopts.IgnoreFields(errors.TestingError{}, "Message"),
+		); diff != "" {
+			t.Errorf("Assert_matches_group()[%d] error: diff=%s", i, diff)
+		}
+		if tests[i].expect != nil {
+			if diff := cmp.Diff(tests[i].expect, ret); diff != "" {
+				t.Errorf("Assert_matches_group()[%d] return value mismatch: diff=%s", i, diff)
+			}
+		}
+	}
+}
+
+func Test_Assert_matches_group_regex_state(t *testing.T) {
+	ctx := &context.Context{
+		RegexMatchedValues: map[string]*value.String{
+			"0": {Value: "/items/10"},
+			"1": {Value: "10"},
+		},
+	}
+	_, err := Assert_matches_group(
+		ctx,
+		&value.String{Value: "/v2/users"},
+		&value.String{Value: `^/v(\d+)/(\w+)`},
+		&value.Integer{Value: 1},
+		&value.String{Value: "2"},
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	// Assertion must not overwrite the values which are captured by the last matching in VCL
+	expect := map[string]*value.String{
+		"0": {Value: "/items/10"},
+		"1": {Value: "10"},
+	}
+	if diff := cmp.Diff(expect, ctx.RegexMatchedValues); diff != "" {
+		t.Errorf("Regex matched values mismatch: diff=%s", diff)
+	}
+}
@@ -1,6 +1,7 @@
 package function
 
 import (
+	"github.com/ysugimoto/falco/v2/interpreter/context"
 	"github.com/ysugimoto/falco/v2/interpreter/function/errors"
 	"github.com/ysugimoto/falco/v2/interpreter/value"
 	"github.com/ysugimoto/falco/v2/tester/shared"
@@ -28,6 +29,7 @@ func Coverage_Validate(t shared.CoverageType, args []value.Value) error {
 }
 
 func Coverage(
+	ctx *context.Context,
 	c *shared.Coverage,
 	t shared.CoverageType,
 	args ...value.Value,
@@ -38,6 +40,7 @@ func Coverage(
 	}
 
 	key := value.Unwrap[*value.String](args[0]).Value
+	ctx.CoverageHits[key]++
 	switch t {
 	case shared.CoverageTypeSubroutine:
 		c.MarkSubroutine(key)
@@ -48,3 +51,37 @@ func Coverage(
 	}
 	return value.Null, nil
 }
+
+const Coverage_decision_Name = "coverage.decision"
+
+var Coverage_decision_ArgumentTypes = []value.Type{value.StringType, value.StringType}
+
+func Coverage_decision_Validate(args []value.Value) error {
+	if len(args) != len(Coverage_decision_ArgumentTypes) {
+		return errors.ArgumentNotEnough(Coverage_decision_Name, len(Coverage_decision_ArgumentTypes), args)
+	}
+	for i := range args {
+		if args[i].Type() != Coverage_decision_ArgumentTypes[i] {
+			return errors.TypeMismatch(
+				Coverage_decision_Name,
+				i+1,
+				Coverage_decision_ArgumentTypes[i],
+				args[i].Type(),
+			)
+		}
+	}
+	return nil
+}
+
+// Coverage_decision records the evaluation vector of the compound condition for MC/DC
+func Coverage_decision(c *shared.Coverage, args ...value.Value) (value.Value, error) {
+	if err := Coverage_decision_Validate(args); err != nil {
+		return value.Null, errors.NewTestingError("%s", err.Error())
+	}
+
+	c.MarkDecision(
+		value.Unwrap[*value.String](args[0]).Value,
+		value.Unwrap[*value.String](args[1]).Value,
+	)
+	return value.Null, nil
+}
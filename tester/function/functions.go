@@ -23,22 +23,31 @@ type Definiions struct {
 
 type Functions map[string]*ifn.Function
 
-func TestingFunctions(i *interpreter.Interpreter, defs *Definiions, c *shared.Counter, cv *shared.Coverage) Functions {
+func TestingFunctions(
+	i *interpreter.Interpreter,
+	defs *Definiions,
+	c *shared.Counter,
+	cv *shared.Coverage,
+	s *shared.Snapshots,
+) Functions {
 	functions := Functions{}
 	maps.Copy(functions, testingFunctions(i, defs))
 	maps.Copy(functions, assertionFunctions(i, c))
+	maps.Copy(functions, snapshotFunctions(c, s))
 	if cv != nil {
-		maps.Copy(functions, coverageFunctions(cv))
+		maps.Copy(functions, CoverageFunctions(cv))
 	}
 	return functions
 }
 
-func coverageFunctions(c *shared.Coverage) Functions {
+// CoverageFunctions returns coverage marker functions.
+// Embedders which run the simulator with coverage measurement need to inject them to the interpreter
+func CoverageFunctions(c *shared.Coverage) Functions {
 	return Functions{
 		"coverage.subroutine": {
 			Scope: allScope,
 			Call: func(ctx *context.Context, args ...value.Value) (value.Value, error) {
-				return Coverage(c, shared.CoverageTypeSubroutine, args...)
+				return Coverage(ctx, c, shared.CoverageTypeSubroutine, args...)
 			},
 			CanStatementCall: true,
 			IsIdentArgument: func(i int) bool {
@@ -48,7 +57,7 @@ func coverageFunctions(c *shared.Coverage) Functions {
 		"coverage.statement": {
 			Scope: allScope,
 			Call: func(ctx *context.Context, args ...value.Value) (value.Value, error) {
-				return Coverage(c, shared.CoverageTypeStatement, args...)
+				return Coverage(ctx, c, shared.CoverageTypeStatement, args...)
 			},
 			CanStatementCall: true,
 			IsIdentArgument: func(i int) bool {
@@ -58,7 +67,17 @@ func coverageFunctions(c *shared.Coverage) Functions {
 		"coverage.branch": {
 			Scope: allScope,
 			Call: func(ctx *context.Context, args ...value.Value) (value.Value, error) {
-				return Coverage(c, shared.CoverageTypeBranch, args...)
+				return Coverage(ctx, c, shared.CoverageTypeBranch, args...)
+			},
+			CanStatementCall: true,
+			IsIdentArgument: func(i int) bool {
+				return false
+			},
+		},
+		"coverage.decision": {
+			Scope: allScope,
+			Call: func(ctx *context.Context, args ...value.Value) (value.Value, error) {
+				return Coverage_decision(c, args...)
 			},
 			CanStatementCall: true,
 			IsIdentArgument: func(i int) bool {
@@ -96,6 +115,24 @@ func testingFunctions(i *interpreter.Interpreter, defs *Definiions) Functions {
 				return false
 			},
 		},
+		"testing.call_subroutine_in_scope": {
+			Scope: allScope,
+			Call: func(ctx *context.Context, args ...value.Value) (value.Value, error) {
+				cr, err := Testing_call_subroutine_in_scope(ctx, i, args...)
+				if err != nil {
+					return value.Null, err
+				}
+				if cr.IsFunctional {
+					return cr.Value, nil
+				}
+				ctx.ReturnState = value.Unwrap[*value.String](cr.Value)
+				return value.Null, nil
+			},
+			CanStatementCall: true,
+			IsIdentArgument: func(i int) bool {
+				return false
+			},
+		},
 		"testing.fixed_time": {Scope: allScope,
 			Call: func(ctx *context.Context, args ...value.Value) (value.Value, error) {
 				unwrapped, err := unwrapIdentArguments(i, args)
@@ -161,6 +198,26 @@ func testingFunctions(i *interpreter.Interpreter, defs *Definiions) Functions {
 				return false
 			},
 		},
+		"testing.before_fetch": {
+			Scope: allScope,
+			Call: func(ctx *context.Context, args ...value.Value) (value.Value, error) {
+				return Testing_before_fetch(ctx, defs, args...)
+			},
+			CanStatementCall: true,
+			IsIdentArgument: func(i int) bool {
+				return false
+			},
+		},
+		"testing.after_fetch": {
+			Scope: allScope,
+			Call: func(ctx *context.Context, args ...value.Value) (value.Value, error) {
+				return Testing_after_fetch(ctx, defs, args...)
+			},
+			CanStatementCall: true,
+			IsIdentArgument: func(i int) bool {
+				return false
+			},
+		},
 		"testing.restore_mock": {
 			Scope:            allScope,
 			Call:             Testing_restore_mock,
@@ -203,6 +260,14 @@ func testingFunctions(i *interpreter.Interpreter, defs *Definiions) Functions {
 				return false
 			},
 		},
+		"testing.seed": {
+			Scope:            allScope,
+			Call:             Testing_seed,
+			CanStatementCall: true,
+			IsIdentArgument: func(i int) bool {
+				return false
+			},
+		},
 		"testing.set_backend_health": {
 			Scope:            allScope,
 			Call:             Testing_set_backend_health,
@@ -211,6 +276,80 @@ func testingFunctions(i *interpreter.Interpreter, defs *Definiions) Functions {
 				return false
 			},
 		},
+		"testing.mock_backend_response": {
+			Scope:            allScope,
+			Call:             Testing_mock_backend_response,
+			CanStatementCall: true,
+			IsIdentArgument: func(i int) bool {
+				return false
+			},
+		},
+		"testing.force_bucket": {
+			Scope:            allScope,
+			Call:             Testing_force_bucket,
+			CanStatementCall: true,
+			IsIdentArgument: func(i int) bool {
+				return false
+			},
+		},
+		"testing.acl_add": {
+			Scope:            allScope,
+			Call:             Testing_acl_add,
+			CanStatementCall: true,
+			IsIdentArgument: func(i int) bool {
+				return false
+			},
+		},
+		"testing.override_variable": {
+			Scope: allScope,
+			Call: func(ctx *context.Context, args ...value.Value) (value.Value, error) {
+				return Testing_override_variable(ctx, i, args...)
+			},
+			CanStatementCall: true,
+			IsIdentArgument: func(i int) bool {
+				return false
+			},
+		},
+		"testing.run": {
+			Scope: allScope,
+			Call: func(ctx *context.Context, args ...value.Value) (value.Value, error) {
+				return Testing_run(ctx, i, defs, args...)
+			},
+			CanStatementCall: true,
+			IsIdentArgument: func(i int) bool {
+				return false
+			},
+		},
+		"testing.soft_assertions": {
+			Scope:            allScope,
+			Call:             Testing_soft_assertions,
+			CanStatementCall: true,
+			IsIdentArgument: func(i int) bool {
+				return false
+			},
+		},
+	}
+}
+
+// Snapshot is counted as the assertion
+func snapshotFunctions(c *shared.Counter, s *shared.Snapshots) Functions {
+	return Functions{
+		"testing.snapshot": {
+			Scope: allScope,
+			Call: func(ctx *context.Context, args ...value.Value) (value.Value, error) {
+				v, err := Testing_snapshot(ctx, s, args...)
+				if err != nil {
+					c.Fail()
+				} else {
+					c.Pass()
+				}
+				return v, err
+			},
+			CanStatementCall: true,
+			IsIdentArgument: func(i int) bool {
+				return false
+			},
+		},
 	}
 }
 
@@ -257,6 +396,106 @@ func assertionFunctions(i *interpreter.Interpreter, c *shared.Counter) Functions
 				return false
 			},
 		},
+		"assert.backend_request": {
+			Scope: allScope,
+			Call: func(ctx *context.Context, args ...value.Value) (value.Value, error) {
+				unwrapped, err := unwrapIdentArguments(i, args)
+				if err != nil {
+					return value.Null, errors.WithStack(err)
+				}
+				v, err := Assert_backend_request(ctx, unwrapped...)
+				if err != nil {
+					c.Fail()
+				} else {
+					c.Pass()
+				}
+				return v, err
+			},
+			CanStatementCall: true,
+			IsIdentArgument: func(i int) bool {
+				return false
+			},
+		},
+		"assert.backend_request.method": {
+			Scope: allScope,
+			Call: func(ctx *context.Context, args ...value.Value) (value.Value, error) {
+				unwrapped, err := unwrapIdentArguments(i, args)
+				if err != nil {
+					return value.Null, errors.WithStack(err)
+				}
+				v, err := Assert_backend_request_method(ctx, unwrapped...)
+				if err != nil {
+					c.Fail()
+				} else {
+					c.Pass()
+				}
+				return v, err
+			},
+			CanStatementCall: true,
+			IsIdentArgument: func(i int) bool {
+				return false
+			},
+		},
+		"assert.backend_request.url": {
+			Scope: allScope,
+			Call: func(ctx *context.Context, args ...value.Value) (value.Value, error) {
+				unwrapped, err := unwrapIdentArguments(i, args)
+				if err != nil {
+					return value.Null, errors.WithStack(err)
+				}
+				v, err := Assert_backend_request_url(ctx, unwrapped...)
+				if err != nil {
+					c.Fail()
+				} else {
+					c.Pass()
+				}
+				return v, err
+			},
+			CanStatementCall: true,
+			IsIdentArgument: func(i int) bool {
+				return false
+			},
+		},
+		"assert.backend_request.header": {
+			Scope: allScope,
+			Call: func(ctx *context.Context, args ...value.Value) (value.Value, error) {
+				unwrapped, err := unwrapIdentArguments(i, args)
+				if err != nil {
+					return value.Null, errors.WithStack(err)
+				}
+				v, err := Assert_backend_request_header(ctx, unwrapped...)
+				if err != nil {
+					c.Fail()
+				} else {
+					c.Pass()
+				}
+				return v, err
+			},
+			CanStatementCall: true,
+			IsIdentArgument: func(i int) bool {
+				return false
+			},
+		},
+		"assert.backend_request.body": {
+			Scope: allScope,
+			Call: func(ctx *context.Context, args ...value.Value) (value.Value, error) {
+				unwrapped, err := unwrapIdentArguments(i, args)
+				if err != nil {
+					return value.Null, errors.WithStack(err)
+				}
+				v, err := Assert_backend_request_body(ctx, unwrapped...)
+				if err != nil {
+					c.Fail()
+				} else {
+					c.Pass()
+				}
+				return v, err
+			},
+			CanStatementCall: true,
+			IsIdentArgument: func(i int) bool {
+				return false
+			},
+		},
 		"assert.not_subroutine_called": {
 			Scope: allScope,
 			Call: func(ctx *context.Context, args ...value.Value) (value.Value, error) {
@@ -277,6 +516,66 @@ func assertionFunctions(i *interpreter.Interpreter, c *shared.Counter) Functions
 				return false
 			},
 		},
+		"assert.call_order": {
+			Scope: allScope,
+			Call: func(ctx *context.Context, args ...value.Value) (value.Value, error) {
+				unwrapped, err := unwrapIdentArguments(i, args)
+				if err != nil {
+					return value.Null, errors.WithStack(err)
+				}
+				v, err := Assert_call_order(ctx, unwrapped...)
+				if err != nil {
+					c.Fail()
+				} else {
+					c.Pass()
+				}
+				return v, err
+			},
+			CanStatementCall: true,
+			IsIdentArgument: func(i int) bool {
+				return false
+			},
+		},
+		"assert.covered": {
+			Scope: allScope,
+			Call: func(ctx *context.Context, args ...value.Value) (value.Value, error) {
+				unwrapped, err := unwrapIdentArguments(i, args)
+				if err != nil {
+					return value.Null, errors.WithStack(err)
+				}
+				v, err := Assert_covered(ctx, unwrapped...)
+				if err != nil {
+					c.Fail()
+				} else {
+					c.Pass()
+				}
+				return v, err
+			},
+			CanStatementCall: true,
+			IsIdentArgument: func(i int) bool {
+				return false
+			},
+		},
+		"assert.branch_covered": {
+			Scope: allScope,
+			Call: func(ctx *context.Context, args ...value.Value) (value.Value, error) {
+				unwrapped, err := unwrapIdentArguments(i, args)
+				if err != nil {
+					return value.Null, errors.WithStack(err)
+				}
+				v, err := Assert_branch_covered(ctx, unwrapped...)
+				if err != nil {
+					c.Fail()
+				} else {
+					c.Pass()
+				}
+				return v, err
+			},
+			CanStatementCall: true,
+			IsIdentArgument: func(i int) bool {
+				return false
+			},
+		},
 		"assert.restart": {
 			Scope: allScope,
 			Call: func(ctx *context.Context, args ...value.Value) (value.Value, error) {
@@ -437,6 +736,40 @@ func assertionFunctions(i *interpreter.Interpreter, c *shared.Counter) Functions
 				return false
 			},
 		},
+		"assert.header_exists": {
+			Scope: allScope,
+			Call: func(ctx *context.Context, args ...value.Value) (value.Value, error) {
+				// Header variable is passed as ident in order to find the header in the actual HTTP object
+				v, err := Assert_header_exists(ctx, args...)
+				if err != nil {
+					c.Fail()
+				} else {
+					c.Pass()
+				}
+				return v, err
+			},
+			CanStatementCall: true,
+			IsIdentArgument: func(i int) bool {
+				return i == 0
+			},
+		},
+		"assert.header_absent": {
+			Scope: allScope,
+			Call: func(ctx *context.Context, args ...value.Value) (value.Value, error) {
+				// Header variable is passed as ident in order to find the header in the actual HTTP object
+				v, err := Assert_header_absent(ctx, args...)
+				if err != nil {
+					c.Fail()
+				} else {
+					c.Pass()
+				}
+				return v, err
+			},
+			CanStatementCall: true,
+			IsIdentArgument: func(i int) bool {
+				return i == 0
+			},
+		},
 		"assert.true": {
 			Scope: allScope,
 			Call: func(ctx *context.Context, args ...value.Value) (value.Value, error) {
@@ -617,6 +950,26 @@ func assertionFunctions(i *interpreter.Interpreter, c *shared.Counter) Functions
 				return false
 			},
 		},
+		"assert.matches_group": {
+			Scope: allScope,
+			Call: func(ctx *context.Context, args ...value.Value) (value.Value, error) {
+				unwrapped, err := unwrapIdentArguments(i, args)
+				if err != nil {
+					return value.Null, errors.WithStack(err)
+				}
+				v, err := Assert_matches_group(ctx, unwrapped...)
+				if err != nil {
+					c.Fail()
+				} else {
+					c.Pass()
+				}
+				return v, err
+			},
+			CanStatementCall: true,
+			IsIdentArgument: func(i int) bool {
+				return false
+			},
+		},
 		"assert.contains": {
 			Scope: allScope,
 			Call: func(ctx *context.Context, args ...value.Value) (value.Value, error) {
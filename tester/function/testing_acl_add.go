@@ -0,0 +1,92 @@
+package function
+
+import (
+	"net"
+	"strings"
+
+	"github.com/ysugimoto/falco/v2/ast"
+	"github.com/ysugimoto/falco/v2/interpreter/context"
+	"github.com/ysugimoto/falco/v2/interpreter/function/errors"
+	"github.com/ysugimoto/falco/v2/interpreter/value"
+)
+
+const Testing_acl_add_Name = "testing.acl_add"
+
+var Testing_acl_add_ArgumentTypes = []value.Type{value.AclType, value.StringType}
+
+func Testing_acl_add_Validate(args []value.Value) error {
+	if len(args) != 2 {
+		return errors.ArgumentNotEnough(Testing_acl_add_Name, 2, args)
+	}
+
+	// ACL could be specified as string
+	if t := args[0].Type(); t != value.AclType && t != value.StringType {
+		return errors.TypeMismatch(Testing_acl_add_Name, 1, value.AclType, t)
+	}
+	if args[1].Type() != value.StringType {
+		return errors.TypeMismatch(Testing_acl_add_Name, 2, value.StringType, args[1].Type())
+	}
+	return nil
+}
+
+// Inject the entry to the ACL like "203.0.113.0/24", the mask could be omitted for the single IP.
+// The entry which is prefixed with "!" excludes the IP from the ACL
+func Testing_acl_add(
+	ctx *context.Context,
+	args ...value.Value,
+) (value.Value, error) {
+
+	if err := Testing_acl_add_Validate(args); err != nil {
+		return nil, errors.NewTestingError("%s", err.Error())
+	}
+
+	var name string
+	if args[0].Type() == value.AclType {
+		name = value.Unwrap[*value.Acl](args[0]).String()
+	} else {
+		name = value.Unwrap[*value.String](args[0]).Value
+	}
+	if _, ok := ctx.Acls[name]; !ok {
+		return value.Null, errors.NewTestingError("acl %s not found in VCL", name)
+	}
+
+	entry, err := Testing_acl_ParseEntry(value.Unwrap[*value.String](args[1]).Value)
+	if err != nil {
+		return value.Null, err
+	}
+
+	if ctx.InjectedAclEntries == nil {
+		ctx.InjectedAclEntries = make(map[string][]*ast.AclCidr)
+	}
+	ctx.InjectedAclEntries[name] = append(ctx.InjectedAclEntries[name], entry)
+	return value.Null, nil
+}
+
+func Testing_acl_ParseEntry(spec string) (*ast.AclCidr, error) {
+	spec = strings.TrimSpace(spec)
+	inverse, spec := strings.HasPrefix(spec, "!"), strings.TrimPrefix(spec, "!")
+
+	if !strings.Contains(spec, "/") {
+		ip := net.ParseIP(spec)
+		if ip == nil {
+			return nil, errors.NewTestingError("invalid ACL entry %s", spec)
+		}
+		if ip.To4() != nil {
+			spec += "/32"
+		} else {
+			spec += "/128"
+		}
+	}
+	ip, ipnet, err := net.ParseCIDR(spec)
+	if err != nil {
+		return nil, errors.NewTestingError("invalid ACL entry %s", spec)
+	}
+	mask, _ := ipnet.Mask.Size()
+
+	return &ast.AclCidr{
+		Meta:    &ast.Meta{},
+		Inverse: &ast.Boolean{Meta: &ast.Meta{}, Value: inverse},
+		IP:      &ast.IP{Meta: &ast.Meta{}, Value: ip.String()},
+		Mask:    &ast.Integer{Meta: &ast.Meta{}, Value: int64(mask)},
+	}, nil
+}
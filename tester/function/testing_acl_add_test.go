@@ -0,0 +1,70 @@
+package function
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/ysugimoto/falco/v2/ast"
+	"github.com/ysugimoto/falco/v2/interpreter/context"
+	"github.com/ysugimoto/falco/v2/interpreter/value"
+)
+
+func Test_acl_add(t *testing.T) {
+	acl := &value.Acl{
+		Value: &ast.AclDeclaration{
+			Name: &ast.Ident{Value: "internal"},
+		},
+	}
+
+	t.Run("inject entries", func(t *testing.T) {
+		c := &context.Context{
+			Acls: map[string]*value.Acl{"internal": acl},
+		}
+		for _, args := range [][]value.Value{
+			{acl, &value.String{Value: "203.0.113.0/24"}},
+			{&value.String{Value: "internal"}, &value.String{Value: "!192.0.2.1"}},
+			{acl, &value.String{Value: "2001:db8::1"}},
+		} {
+			if _, err := Testing_acl_add(c, args...); err != nil {
+				t.Errorf("Unexpected error: %s", err)
+				return
+			}
+		}
+
+		type entry struct {
+			Inverse bool
+			IP      string
+			Mask    int64
+		}
+		var actual []entry
+		for _, e := range c.InjectedAclEntries["internal"] {
+			actual = append(actual, entry{e.Inverse.Value, e.IP.Value, e.Mask.Value})
+		}
+		expect := []entry{
+			{false, "203.0.113.0", 24},
+			{true, "192.0.2.1", 32},
+			{false, "2001:db8::1", 128},
+		}
+		if diff := cmp.Diff(expect, actual); diff != "" {
+			t.Errorf("Injected entries mismatch, diff=%s", diff)
+		}
+	})
+
+	t.Run("invalid arguments", func(t *testing.T) {
+		c := &context.Context{
+			Acls: map[string]*value.Acl{"internal": acl},
+		}
+		tests := [][]value.Value{
+			{acl},
+			{&value.String{Value: "unknown"}, &value.String{Value: "192.0.2.1"}},
+			{acl, &value.String{Value: "invalid"}},
+			{acl, &value.String{Value: "192.0.2.0/33"}},
+			{&value.Integer{Value: 1}, &value.String{Value: "192.0.2.1"}},
+		}
+		for i, args := range tests {
+			if _, err := Testing_acl_add(c, args...); err == nil {
+				t.Errorf("[%d] Expected error but got nil", i)
+			}
+		}
+	})
+}
@@ -0,0 +1,31 @@
+package function
+
+import (
+	"github.com/ysugimoto/falco/v2/interpreter/context"
+	"github.com/ysugimoto/falco/v2/interpreter/function/errors"
+	"github.com/ysugimoto/falco/v2/interpreter/value"
+)
+
+const Testing_after_fetch_Name = "testing.after_fetch"
+
+func Testing_after_fetch_Validate(args []value.Value) error {
+	return validateFetchHookArguments(Testing_after_fetch_Name, args)
+}
+
+func Testing_after_fetch(
+	ctx *context.Context,
+	defs *Definiions,
+	args ...value.Value,
+) (value.Value, error) {
+
+	if err := Testing_after_fetch_Validate(args); err != nil {
+		return nil, errors.NewTestingError("%s", err.Error())
+	}
+
+	hook, err := lookupFetchHook(defs, args[0])
+	if err != nil {
+		return value.Null, err
+	}
+	ctx.AfterFetchHook = hook
+	return value.Null, nil
+}
@@ -0,0 +1,55 @@
+package function
+
+import (
+	"github.com/ysugimoto/falco/v2/ast"
+	"github.com/ysugimoto/falco/v2/interpreter/context"
+	"github.com/ysugimoto/falco/v2/interpreter/function/errors"
+	"github.com/ysugimoto/falco/v2/interpreter/value"
+)
+
+const Testing_before_fetch_Name = "testing.before_fetch"
+
+func Testing_before_fetch_Validate(args []value.Value) error {
+	return validateFetchHookArguments(Testing_before_fetch_Name, args)
+}
+
+func Testing_before_fetch(
+	ctx *context.Context,
+	defs *Definiions,
+	args ...value.Value,
+) (value.Value, error) {
+
+	if err := Testing_before_fetch_Validate(args); err != nil {
+		return nil, errors.NewTestingError("%s", err.Error())
+	}
+
+	hook, err := lookupFetchHook(defs, args[0])
+	if err != nil {
+		return value.Null, err
+	}
+	ctx.BeforeFetchHook = hook
+	return value.Null, nil
+}
+
+func validateFetchHookArguments(name string, args []value.Value) error {
+	if len(args) != 1 {
+		return errors.ArgumentNotEnough(name, 1, args)
+	}
+	if args[0].Type() != value.StringType {
+		return errors.TypeMismatch(name, 1, value.StringType, args[0].Type())
+	}
+	return nil
+}
+
+// Hook subroutine must be declared in testing VCL and must not be functional subroutine
+func lookupFetchHook(defs *Definiions, arg value.Value) (*ast.SubroutineDeclaration, error) {
+	name := value.Unwrap[*value.String](arg).Value
+	hook, ok := defs.Subroutines[name]
+	if !ok {
+		return nil, errors.NewTestingError("hook subroutine %s is not declared in testing VCL", name)
+	}
+	if hook.ReturnType != nil {
+		return nil, errors.NewTestingError("hook subroutine %s must not be functional subroutine", name)
+	}
+	return hook, nil
+}
@@ -0,0 +1,72 @@
+package function
+
+import (
+	"testing"
+
+	"github.com/ysugimoto/falco/v2/ast"
+	"github.com/ysugimoto/falco/v2/interpreter/context"
+	"github.com/ysugimoto/falco/v2/interpreter/value"
+	"github.com/ysugimoto/falco/v2/lexer"
+	"github.com/ysugimoto/falco/v2/parser"
+)
+
+func Test_before_fetch(t *testing.T) {
+	tests := []struct {
+		name    string
+		hook    string
+		isError bool
+	}{
+		{
+			name: "register hook subroutine",
+			hook: `sub hook {
+					set beresp.http.X-Origin = "1";
+				}`,
+		},
+		{
+			name: "hook subroutine is not declared",
+			hook: `sub undefined {
+					set beresp.http.X-Origin = "1";
+				}`,
+			isError: true,
+		},
+		{
+			name: "hook subroutine must not be functional",
+			hook: `sub hook STRING {
+					return "FOO";
+				}`,
+			isError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vcl, err := parser.New(lexer.NewFromString(tt.hook)).ParseVCL()
+			if err != nil {
+				t.Errorf("Unexpected hook parse error: %s", err)
+				return
+			}
+			hook := vcl.Statements[0].(*ast.SubroutineDeclaration)
+
+			c := &context.Context{}
+			defs := &Definiions{
+				Subroutines: map[string]*ast.SubroutineDeclaration{
+					hook.Name.Value: hook,
+				},
+			}
+			_, err = Testing_before_fetch(c, defs, &value.String{Value: "hook"})
+			if tt.isError {
+				if err == nil {
+					t.Errorf("Expected error but nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("Unexpected error: %s", err)
+				return
+			}
+			if c.BeforeFetchHook != hook {
+				t.Errorf("Expected before fetch hook is registered but not found")
+			}
+		})
+	}
+}
@@ -1,14 +1,19 @@
 package function
 
 import (
+	"fmt"
+
+	perrors "github.com/pkg/errors"
 	"github.com/ysugimoto/falco/v2/ast"
 	"github.com/ysugimoto/falco/v2/interpreter"
 	"github.com/ysugimoto/falco/v2/interpreter/context"
+	"github.com/ysugimoto/falco/v2/interpreter/exception"
 	"github.com/ysugimoto/falco/v2/interpreter/function/errors"
 	"github.com/ysugimoto/falco/v2/interpreter/value"
 )
 
 const Testing_call_subroutine_Name = "testing.call_subroutine"
+const Testing_call_subroutine_in_scope_Name = "testing.call_subroutine_in_scope"
 
 // CallResult distinguishes functional subroutine return values from
 // scoped subroutine state names so the caller can branch correctly
@@ -33,6 +38,23 @@ func Testing_call_subroutine_Validate(args []value.Value) error {
 	return nil
 }
 
+func Testing_call_subroutine_in_scope_Validate(args []value.Value) error {
+	if len(args) < 2 {
+		return errors.ArgumentNotEnough(Testing_call_subroutine_in_scope_Name, 2, args)
+	}
+	for i := range 2 {
+		if args[i].Type() != value.StringType {
+			return errors.TypeMismatch(
+				Testing_call_subroutine_in_scope_Name,
+				i+1,
+				value.StringType,
+				args[i].Type(),
+			)
+		}
+	}
+	return nil
+}
+
 // resolveSubroutine looks up a subroutine by name in ctx, checking mocked
 // overrides first. It returns the declaration and whether it is a functional
 // (return-typed) subroutine.
@@ -71,6 +93,53 @@ func Testing_call_subroutine(
 	name := value.Unwrap[*value.String](args[0]).Value
 	subArgs := args[1:]
 
+	// Scope could be specified before the arguments like testing.call_subroutine("normalize_path", "RECV").
+	// It is decided by the number of arguments, not by the value, so that STRING argument which
+	// looks like a scope name is passed to the subroutine when the count matches its parameters
+	if sub, _ := resolveSubroutine(ctx, name); sub != nil && len(subArgs) == len(sub.Parameters)+1 {
+		scope, err := callSubroutineScope(Testing_call_subroutine_Name, subArgs[0])
+		if err != nil {
+			return nil, errors.NewTestingError("%s", err.Error())
+		}
+		defer i.SetScope(ctx.Scope)
+		i.SetScope(scope)
+		subArgs = subArgs[1:]
+	}
+	return callSubroutine(ctx, i, name, subArgs)
+}
+
+// Call subroutine in the chosen scope like testing.call_subroutine_in_scope("normalize_path", "RECV")
+// so that utility subroutines are called without going through the state machine.
+// Unlike testing.call_subroutine, the scope is always required.
+// Testing scope is restored after the call
+func Testing_call_subroutine_in_scope(
+	ctx *context.Context,
+	i *interpreter.Interpreter,
+	args ...value.Value,
+) (*CallResult, error) {
+
+	if err := Testing_call_subroutine_in_scope_Validate(args); err != nil {
+		return nil, errors.NewTestingError("%s", err.Error())
+	}
+
+	name := value.Unwrap[*value.String](args[0]).Value
+	scope, err := callSubroutineScope(Testing_call_subroutine_in_scope_Name, args[1])
+	if err != nil {
+		return nil, errors.NewTestingError("%s", err.Error())
+	}
+	defer i.SetScope(ctx.Scope)
+	i.SetScope(scope)
+
+	return callSubroutine(ctx, i, name, args[2:])
+}
+
+func callSubroutine(
+	ctx *context.Context,
+	i *interpreter.Interpreter,
+	name string,
+	subArgs []value.Value,
+) (*CallResult, error) {
+
 	sub, isFunctional := resolveSubroutine(ctx, name)
 	if sub == nil {
 		return nil, errors.NewTestingError(
@@ -93,7 +162,7 @@ func Testing_call_subroutine(
 		)
 		ctx.TestingReturnValue = retVal
 		if err != nil {
-			return nil, errors.NewTestingError("%s", err.Error())
+			return nil, subroutineError(err)
 		}
 		return &CallResult{
 			Value:        retVal,
@@ -101,13 +170,64 @@ func Testing_call_subroutine(
 		}, nil
 	}
 
+	// Backend response has been received before vcl_fetch is processed
+	if name == context.FastlyVclNameFetch {
+		i.ProcessMockBackendResponse()
+		if err := i.ProcessFetchHook(ctx.AfterFetchHook); err != nil {
+			return nil, subroutineError(err)
+		}
+	}
+
 	state, err := i.ProcessSubroutine(sub, interpreter.DebugPass, subArgs)
 	if err != nil {
-		return nil, errors.NewTestingError("%s", err.Error())
+		return nil, subroutineError(err)
 	}
 	i.TestingState = state
+
+	// Backend request is sent when vcl_miss or vcl_pass moves to the fetch state
+	if isBackendFetchState(ctx.Scope, state) {
+		if err := i.ProcessFetchHook(ctx.BeforeFetchHook); err != nil {
+			return nil, subroutineError(err)
+		}
+		if err := i.RecordBackendRequest(); err != nil {
+			return nil, subroutineError(err)
+		}
+	}
 	return &CallResult{
 		Value:        &value.String{Value: string(state)},
 		IsFunctional: false,
 	}, nil
 }
+
+// Exceptions which occur in the called subroutine are returned as they are
+// so that the failure is reported with the excerpt of the main VCL, not the testing VCL
+func subroutineError(err error) error {
+	if e, ok := perrors.Cause(err).(*exception.Exception); ok {
+		return e
+	}
+	return errors.NewTestingError("%s", err.Error())
+}
+
+func callSubroutineScope(fn string, v value.Value) (context.Scope, error) {
+	if v.Type() != value.StringType {
+		return context.UnknownScope, fmt.Errorf(
+			"%s: scope must be STRING like \"RECV\", got %s", fn, v.Type(),
+		)
+	}
+	name := value.Unwrap[*value.String](v).Value
+	scope := context.ScopeByString(name)
+	if scope == context.UnknownScope {
+		return context.UnknownScope, fmt.Errorf("%s: unknown scope %s", fn, name)
+	}
+	return scope, nil
+}
+
+func isBackendFetchState(scope context.Scope, state interpreter.State) bool {
+	switch scope {
+	case context.MissScope:
+		return state == interpreter.FETCH || state == interpreter.NONE
+	case context.PassScope:
+		return state == interpreter.PASS || state == interpreter.NONE
+	}
+	return false
+}
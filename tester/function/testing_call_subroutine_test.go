@@ -1,14 +1,17 @@
 package function
 
 import (
+	"net/http/httptest"
 	"testing"
 
 	"github.com/ysugimoto/falco/v2/ast"
 	"github.com/ysugimoto/falco/v2/interpreter"
 	iCtx "github.com/ysugimoto/falco/v2/interpreter/context"
+	"github.com/ysugimoto/falco/v2/interpreter/http"
 	"github.com/ysugimoto/falco/v2/interpreter/value"
 	"github.com/ysugimoto/falco/v2/lexer"
 	"github.com/ysugimoto/falco/v2/parser"
+	"github.com/ysugimoto/falco/v2/resolver"
 )
 
 // newCallSubCtx returns a minimal context with all subroutine maps initialised
@@ -143,3 +146,220 @@ func Test_call_subroutine_arg_count_mismatch(t *testing.T) {
 		}
 	})
 }
+
+// --- Testing_call_subroutine_in_scope ---
+
+func Test_call_subroutine_in_scope_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []value.Value
+		isError bool
+	}{
+		{
+			name:    "scope is missing",
+			args:    []value.Value{&value.String{Value: "normalize_path"}},
+			isError: true,
+		},
+		{
+			name: "scope is not STRING",
+			args: []value.Value{
+				&value.String{Value: "normalize_path"},
+				&value.Integer{Value: 1},
+			},
+			isError: true,
+		},
+		{
+			name: "subroutine name and scope — valid",
+			args: []value.Value{
+				&value.String{Value: "normalize_path"},
+				&value.String{Value: "RECV"},
+			},
+			isError: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Testing_call_subroutine_in_scope_Validate(tt.args)
+			if tt.isError && err == nil {
+				t.Errorf("expected error but got nil")
+			} else if !tt.isError && err != nil {
+				t.Errorf("unexpected error: %s", err)
+			}
+		})
+	}
+}
+
+func Test_call_subroutine_scope_by_argument_count(t *testing.T) {
+	sub := parseSub(t, `sub takes_string(STRING var.s) STRING {
+  return var.s;
+}`)
+
+	ctx := newCallSubCtx()
+	ctx.SubroutineFunctions["takes_string"] = sub
+	scoped := parseSub(t, `sub normalize_path {
+  set req.url = "/";
+}`)
+	ctx.Subroutines["normalize_path"] = scoped
+
+	newInterpreter := func(t *testing.T) *interpreter.Interpreter {
+		t.Helper()
+		i := interpreter.New(iCtx.WithResolver(resolver.NewStaticResolver("main", "sub vcl_recv {}")))
+		if err := i.TestProcessInit(http.WrapRequest(httptest.NewRequest("GET", "http://localhost", nil))); err != nil {
+			t.Fatalf("Unexpected interpreter initialization error: %s", err)
+		}
+		return i
+	}
+
+	t.Run("scope is specified before the argument", func(t *testing.T) {
+		i := newInterpreter(t)
+		ret, err := Testing_call_subroutine(
+			ctx, i,
+			&value.String{Value: "takes_string"},
+			&value.String{Value: "RECV"},
+			&value.String{Value: "x"},
+		)
+		if err != nil {
+			t.Errorf("unexpected error: %s", err)
+			return
+		}
+		if v := value.Unwrap[*value.String](ret.Value).Value; v != "x" {
+			t.Errorf("return value mismatch, expect=x, actual=%s", v)
+		}
+	})
+
+	t.Run("scope is specified for the subroutine without parameters", func(t *testing.T) {
+		i := newInterpreter(t)
+		if _, err := Testing_call_subroutine(
+			ctx, i,
+			&value.String{Value: "normalize_path"},
+			&value.String{Value: "RECV"},
+		); err != nil {
+			t.Errorf("unexpected error: %s", err)
+		}
+	})
+
+	t.Run("extra argument is not a scope", func(t *testing.T) {
+		i := newInterpreter(t)
+		_, err := Testing_call_subroutine(
+			ctx, i,
+			&value.String{Value: "takes_string"},
+			&value.String{Value: "x"},
+			&value.String{Value: "RECV"},
+		)
+		if err == nil {
+			t.Errorf("expected unknown scope error but got nil")
+		}
+	})
+}
+
+func Test_call_subroutine_string_parameter_is_not_scope(t *testing.T) {
+	sub := parseSub(t, `sub takes_string(STRING var.s) STRING {
+  return var.s;
+}`)
+
+	ctx := newCallSubCtx()
+	ctx.SubroutineFunctions["takes_string"] = sub
+
+	newInterpreter := func(t *testing.T) *interpreter.Interpreter {
+		t.Helper()
+		i := interpreter.New(iCtx.WithResolver(resolver.NewStaticResolver("main", "sub vcl_recv {}")))
+		if err := i.TestProcessInit(http.WrapRequest(httptest.NewRequest("GET", "http://localhost", nil))); err != nil {
+			t.Fatalf("Unexpected interpreter initialization error: %s", err)
+		}
+		return i
+	}
+
+	t.Run("scope name is passed as the argument", func(t *testing.T) {
+		i := newInterpreter(t)
+		ret, err := Testing_call_subroutine(
+			ctx, i,
+			&value.String{Value: "takes_string"},
+			&value.String{Value: "FETCH"},
+		)
+		if err != nil {
+			t.Errorf("unexpected error: %s", err)
+			return
+		}
+		if v := value.Unwrap[*value.String](ret.Value).Value; v != "FETCH" {
+			t.Errorf("return value mismatch, expect=FETCH, actual=%s", v)
+		}
+	})
+
+	t.Run("scope is specified before the argument", func(t *testing.T) {
+		i := newInterpreter(t)
+		ret, err := Testing_call_subroutine_in_scope(
+			ctx, i,
+			&value.String{Value: "takes_string"},
+			&value.String{Value: "RECV"},
+			&value.String{Value: "FETCH"},
+		)
+		if err != nil {
+			t.Errorf("unexpected error: %s", err)
+			return
+		}
+		if v := value.Unwrap[*value.String](ret.Value).Value; v != "FETCH" {
+			t.Errorf("return value mismatch, expect=FETCH, actual=%s", v)
+		}
+	})
+}
+
+func Test_call_subroutine_invalid_scope(t *testing.T) {
+	sub := parseSub(t, `sub normalize_path {
+  set req.url = "/";
+}`)
+
+	ctx := newCallSubCtx()
+	ctx.Subroutines["normalize_path"] = sub
+	i := interpreter.New()
+
+	t.Run("unknown scope name", func(t *testing.T) {
+		_, err := Testing_call_subroutine_in_scope(
+			ctx, i,
+			&value.String{Value: "normalize_path"},
+			&value.String{Value: "UNKNOWN"},
+		)
+		if err == nil {
+			t.Errorf("expected error for unknown scope but got nil")
+		}
+	})
+
+	t.Run("scope is not STRING", func(t *testing.T) {
+		_, err := Testing_call_subroutine_in_scope(
+			ctx, i,
+			&value.String{Value: "normalize_path"},
+			&value.Integer{Value: 1},
+		)
+		if err == nil {
+			t.Errorf("expected error for non-STRING scope but got nil")
+		}
+	})
+}
+
+func Test_callSubroutineScope(t *testing.T) {
+	tests := []struct {
+		input   value.Value
+		expect  iCtx.Scope
+		isError bool
+	}{
+		{input: &value.String{Value: "RECV"}, expect: iCtx.RecvScope},
+		{input: &value.String{Value: "deliver"}, expect: iCtx.DeliverScope},
+		{input: &value.String{Value: "any"}, isError: true},
+		{input: &value.Integer{Value: 1}, isError: true},
+	}
+
+	for _, tt := range tests {
+		scope, err := callSubroutineScope(Testing_call_subroutine_Name, tt.input)
+		if tt.isError {
+			if err == nil {
+				t.Errorf("expected error for %s but got nil", tt.input)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("unexpected error: %s", err)
+		} else if scope != tt.expect {
+			t.Errorf("scope mismatch, expect=%s, actual=%s", tt.expect, scope)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package function
+
+import (
+	"github.com/ysugimoto/falco/v2/ast"
+	"github.com/ysugimoto/falco/v2/experiment"
+	"github.com/ysugimoto/falco/v2/interpreter/context"
+	"github.com/ysugimoto/falco/v2/interpreter/function/errors"
+	"github.com/ysugimoto/falco/v2/interpreter/value"
+)
+
+const Testing_force_bucket_Name = "testing.force_bucket"
+
+var Testing_force_bucket_ArgumentTypes = []value.Type{value.StringType, value.StringType}
+
+func Testing_force_bucket_Validate(args []value.Value) error {
+	if len(args) != 2 {
+		return errors.ArgumentNotEnough(Testing_force_bucket_Name, 2, args)
+	}
+
+	for i := range Testing_force_bucket_ArgumentTypes {
+		if args[i].Type() != Testing_force_bucket_ArgumentTypes[i] {
+			return errors.TypeMismatch(
+				Testing_force_bucket_Name, i+1, Testing_force_bucket_ArgumentTypes[i], args[i].Type(),
+			)
+		}
+	}
+	return nil
+}
+
+// Force the variant of the experiment which is compiled by falco experiment.
+// The experiment subroutine is mocked by the subroutine which always returns the variant,
+// so the assignment can be restored by testing.restore_mock with the subroutine name
+func Testing_force_bucket(
+	ctx *context.Context,
+	args ...value.Value,
+) (value.Value, error) {
+
+	if err := Testing_force_bucket_Validate(args); err != nil {
+		return nil, errors.NewTestingError("%s", err.Error())
+	}
+
+	name := value.Unwrap[*value.String](args[0]).Value
+	variant := value.Unwrap[*value.String](args[1]).Value
+
+	subName := experiment.SubroutinePrefix + name
+	sub, ok := ctx.SubroutineFunctions[subName]
+	if !ok {
+		return value.Null, errors.NewTestingError("experiment %s is not compiled in VCL, subroutine %s is not declared", name, subName)
+	}
+
+	var found bool
+	for _, v := range experimentVariants(sub.Block.Statements) {
+		found = found || v == variant
+	}
+	if !found {
+		return value.Null, errors.NewTestingError("variant %s is not declared in experiment %s", variant, name)
+	}
+
+	ctx.MockedFunctioncalSubroutines[subName] = &ast.SubroutineDeclaration{
+		Meta:       sub.Meta,
+		Name:       sub.Name,
+		ReturnType: sub.ReturnType,
+		Block: &ast.BlockStatement{
+			Meta: sub.Block.Meta,
+			Statements: []ast.Statement{
+				&ast.ReturnStatement{
+					Meta:             sub.Block.Meta,
+					ReturnExpression: &ast.String{Meta: sub.Block.Meta, Value: variant},
+				},
+			},
+		},
+	}
+	return value.Null, nil
+}
+
+// Collect variant names which are returned in the compiled experiment subroutine
+func experimentVariants(statements []ast.Statement) []string {
+	var variants []string
+	for _, stmt := range statements {
+		switch t := stmt.(type) {
+		case *ast.ReturnStatement:
+			if s, ok := t.ReturnExpression.(*ast.String); ok {
+				variants = append(variants, s.Value)
+			}
+		case *ast.IfStatement:
+			variants = append(variants, experimentVariants(t.Consequence.Statements)...)
+		}
+	}
+	return variants
+}
@@ -0,0 +1,73 @@
+package function
+
+import (
+	"testing"
+
+	"github.com/ysugimoto/falco/v2/ast"
+	"github.com/ysugimoto/falco/v2/experiment"
+	"github.com/ysugimoto/falco/v2/interpreter/context"
+	"github.com/ysugimoto/falco/v2/interpreter/value"
+	"github.com/ysugimoto/falco/v2/lexer"
+	"github.com/ysugimoto/falco/v2/parser"
+)
+
+func Test_force_bucket(t *testing.T) {
+	compiled := experiment.Compile([]*experiment.Experiment{
+		{
+			Name: "checkout",
+			Key:  "req.http.Cookie:uid",
+			Variants: []*experiment.Variant{
+				{Name: "control", Weight: 50},
+				{Name: "new_flow", Weight: 50},
+			},
+		},
+	})
+	vcl, err := parser.New(lexer.NewFromString(compiled)).ParseVCL()
+	if err != nil {
+		t.Fatalf("Unexpected parse error: %s", err)
+	}
+	newContext := func() *context.Context {
+		c := &context.Context{
+			SubroutineFunctions:          map[string]*ast.SubroutineDeclaration{},
+			MockedFunctioncalSubroutines: map[string]*ast.SubroutineDeclaration{},
+		}
+		for _, stmt := range vcl.Statements {
+			if sub, ok := stmt.(*ast.SubroutineDeclaration); ok && sub.ReturnType != nil {
+				c.SubroutineFunctions[sub.Name.Value] = sub
+			}
+		}
+		return c
+	}
+
+	t.Run("force declared variant", func(t *testing.T) {
+		c := newContext()
+		_, err := Testing_force_bucket(c, &value.String{Value: "checkout"}, &value.String{Value: "new_flow"})
+		if err != nil {
+			t.Errorf("Unexpected error: %s", err)
+			return
+		}
+		mocked, ok := c.MockedFunctioncalSubroutines["experiment_checkout"]
+		if !ok {
+			t.Errorf("Experiment subroutine is not mocked")
+			return
+		}
+		variants := experimentVariants(mocked.Block.Statements)
+		if len(variants) != 1 || variants[0] != "new_flow" {
+			t.Errorf("Mocked subroutine should return new_flow, got %v", variants)
+		}
+	})
+
+	t.Run("undeclared variant", func(t *testing.T) {
+		_, err := Testing_force_bucket(newContext(), &value.String{Value: "checkout"}, &value.String{Value: "unknown"})
+		if err == nil {
+			t.Errorf("Expected error but got nil")
+		}
+	})
+
+	t.Run("undeclared experiment", func(t *testing.T) {
+		_, err := Testing_force_bucket(newContext(), &value.String{Value: "banner"}, &value.String{Value: "on"})
+		if err == nil {
+			t.Errorf("Expected error but got nil")
+		}
+	})
+}
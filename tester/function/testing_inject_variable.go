@@ -29,12 +29,25 @@ func Testing_inject_variable(
 
 	name := value.Unwrap[*value.String](args[0])
 
-	// Important: second argument will be provided as literal
-	// but overrided value must not be literal in the interpreter process
-	// so we turn off the literal flag to false.
-	// Unfortunately value.Value interface does not have to change the literal flag
-	// so need type assertion for primitive values - it's annoying but just special case.
-	switch t := args[1].(type) {
+	unliteral(args[1])
+	ctx.OverrideVariables[name.Value] = args[1]
+
+	// If overriding request protocol, also set req.is_ssl accordingly
+	if name.Value == "req.protocol" {
+		if s, ok := args[1].(*value.String); ok {
+			ctx.OverrideVariables["req.is_ssl"] = &value.Boolean{Value: s.Value == "https"}
+		}
+	}
+	return value.Null, nil
+}
+
+// Important: testing function argument will be provided as literal
+// but overrided value must not be literal in the interpreter process
+// so we turn off the literal flag to false.
+// Unfortunately value.Value interface does not have to change the literal flag
+// so need type assertion for primitive values - it's annoying but just special case.
+func unliteral(v value.Value) {
+	switch t := v.(type) {
 	case *value.Acl:
 		t.Literal = false
 	case *value.Backend:
@@ -55,13 +68,4 @@ func Testing_inject_variable(
 		t.Literal = false
 		// Note: *value.Time value could not be specified as literal
 	}
-	ctx.OverrideVariables[name.Value] = args[1]
-
-	// If overriding request protocol, also set req.is_ssl accordingly
-	if name.Value == "req.protocol" {
-		if s, ok := args[1].(*value.String); ok {
-			ctx.OverrideVariables["req.is_ssl"] = &value.Boolean{Value: s.Value == "https"}
-		}
-	}
-	return value.Null, nil
 }
@@ -0,0 +1,104 @@
+package function
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/ysugimoto/falco/v2/interpreter/context"
+	"github.com/ysugimoto/falco/v2/interpreter/function/errors"
+	"github.com/ysugimoto/falco/v2/interpreter/value"
+)
+
+const Testing_mock_backend_response_Name = "testing.mock_backend_response"
+
+var Testing_mock_backend_response_ArgumentTypes = []value.Type{
+	value.BackendType,
+	value.IntegerType,
+	value.StringType,
+	value.StringType,
+	value.RTimeType,
+}
+
+func Testing_mock_backend_response_Validate(args []value.Value) error {
+	if len(args) < 2 || len(args) > 5 {
+		return errors.ArgumentNotInRange(Testing_mock_backend_response_Name, 2, 5, args)
+	}
+
+	for i := range args {
+		if args[i].Type() != Testing_mock_backend_response_ArgumentTypes[i] {
+			return errors.TypeMismatch(
+				Testing_mock_backend_response_Name, i+1, Testing_mock_backend_response_ArgumentTypes[i], args[i].Type(),
+			)
+		}
+	}
+	return nil
+}
+
+// Declare the canned response of the backend, optional arguments are headers, body and latency.
+// Headers are specified as "Name: value" lines which are separated by newline
+func Testing_mock_backend_response(
+	ctx *context.Context,
+	args ...value.Value,
+) (value.Value, error) {
+
+	if err := Testing_mock_backend_response_Validate(args); err != nil {
+		return nil, errors.NewTestingError("%s", err.Error())
+	}
+
+	backend := value.Unwrap[*value.Backend](args[0]).String()
+	if _, ok := ctx.Backends[backend]; !ok {
+		return value.Null, errors.NewTestingError("Backend %s not found in context", backend)
+	}
+
+	status := value.Unwrap[*value.Integer](args[1]).Value
+	if status < 100 || status > 999 {
+		return value.Null, errors.NewTestingError("Invalid status code %d of the mock backend response", status)
+	}
+	mock := &context.MockBackendResponse{
+		Status: int(status),
+		Header: http.Header{},
+	}
+	if len(args) > 2 {
+		header, err := parseMockHeaders(value.Unwrap[*value.String](args[2]).Value)
+		if err != nil {
+			return value.Null, err
+		}
+		mock.Header = header
+	}
+	if len(args) > 3 {
+		mock.Body = value.Unwrap[*value.String](args[3]).Value
+	}
+	if len(args) > 4 {
+		mock.Latency = value.Unwrap[*value.RTime](args[4]).Value
+	}
+
+	if ctx.MockBackendResponses == nil {
+		ctx.MockBackendResponses = make(map[string]*context.MockBackendResponse)
+	}
+	ctx.MockBackendResponses[backend] = mock
+
+	// Testing process has already received the default backend response of the current backend,
+	// replace it immediately so that beresp, obj and resp reflect the mock
+	if ctx.Backend != nil && ctx.Backend.String() == backend {
+		ctx.BackendResponse = mock.Response(ctx.BackendRequest)
+		ctx.Object = ctx.BackendResponse.Clone()
+		ctx.Response = ctx.BackendResponse.Clone()
+	}
+	return value.Null, nil
+}
+
+func parseMockHeaders(spec string) (http.Header, error) {
+	header := http.Header{}
+	for _, line := range strings.Split(spec, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		name, val, found := strings.Cut(line, ":")
+		if !found || strings.TrimSpace(name) == "" {
+			return nil, errors.NewTestingError("Invalid header line %q of the mock backend response", line)
+		}
+		header.Add(strings.TrimSpace(name), strings.TrimSpace(val))
+	}
+	return header, nil
+}
@@ -0,0 +1,109 @@
+package function
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/ysugimoto/falco/v2/ast"
+	"github.com/ysugimoto/falco/v2/interpreter/context"
+	"github.com/ysugimoto/falco/v2/interpreter/value"
+)
+
+func Test_mock_backend_response(t *testing.T) {
+	newBackend := func(name string) *value.Backend {
+		return &value.Backend{
+			Value: &ast.BackendDeclaration{
+				Name: &ast.Ident{Value: name},
+			},
+		}
+	}
+
+	t.Run("mock current backend", func(t *testing.T) {
+		origin := newBackend("F_origin")
+		c := &context.Context{
+			Backend: origin,
+			Backends: map[string]*value.Backend{
+				"F_origin": origin,
+			},
+		}
+		_, err := Testing_mock_backend_response(
+			c,
+			origin,
+			&value.Integer{Value: 404},
+			&value.String{Value: "Content-Type: text/html\nCache-Control: max-age=60"},
+			&value.String{Value: "not found"},
+			&value.RTime{Value: 150 * time.Millisecond},
+		)
+		if err != nil {
+			t.Errorf("Unexpected error: %s", err)
+			return
+		}
+		mock := c.FindMockBackendResponse("F_origin")
+		if mock == nil {
+			t.Errorf("Mock is not declared")
+			return
+		}
+		if mock.Latency != 150*time.Millisecond {
+			t.Errorf("Latency mismatch, expect=150ms, actual=%s", mock.Latency)
+		}
+		if c.BackendResponse == nil || c.Response == nil || c.Object == nil {
+			t.Errorf("Backend response is not replaced")
+			return
+		}
+		if c.BackendResponse.StatusCode != 404 {
+			t.Errorf("Status code mismatch, expect=404, actual=%d", c.BackendResponse.StatusCode)
+		}
+		if v := c.BackendResponse.Header.Get("Cache-Control"); v != "max-age=60" {
+			t.Errorf("Cache-Control header mismatch, expect=max-age=60, actual=%s", v)
+		}
+		body, _ := io.ReadAll(c.Response.Body) // nolint:errcheck
+		if string(body) != "not found" {
+			t.Errorf("Body mismatch, expect=not found, actual=%s", string(body))
+		}
+	})
+
+	t.Run("mock another backend", func(t *testing.T) {
+		origin := newBackend("F_origin")
+		api := newBackend("F_api")
+		c := &context.Context{
+			Backend: origin,
+			Backends: map[string]*value.Backend{
+				"F_origin": origin,
+				"F_api":    api,
+			},
+		}
+		_, err := Testing_mock_backend_response(c, api, &value.Integer{Value: 503})
+		if err != nil {
+			t.Errorf("Unexpected error: %s", err)
+			return
+		}
+		if c.FindMockBackendResponse("F_api") == nil {
+			t.Errorf("Mock is not declared")
+		}
+		if c.BackendResponse != nil {
+			t.Errorf("Backend response of the current backend should not be replaced")
+		}
+	})
+
+	t.Run("invalid arguments", func(t *testing.T) {
+		origin := newBackend("F_origin")
+		c := &context.Context{
+			Backends: map[string]*value.Backend{
+				"F_origin": origin,
+			},
+		}
+		tests := [][]value.Value{
+			{origin},
+			{origin, &value.String{Value: "200"}},
+			{origin, &value.Integer{Value: 42}},
+			{origin, &value.Integer{Value: 200}, &value.String{Value: "Invalid header"}},
+			{newBackend("F_unknown"), &value.Integer{Value: 200}},
+		}
+		for i, args := range tests {
+			if _, err := Testing_mock_backend_response(c, args...); err == nil {
+				t.Errorf("[%d] Expected error but got nil", i)
+			}
+		}
+	})
+}
@@ -3,6 +3,7 @@ package function
 import (
 	"github.com/ysugimoto/falco/v2/interpreter/context"
 	"github.com/ysugimoto/falco/v2/interpreter/function/errors"
+	"github.com/ysugimoto/falco/v2/interpreter/function/shared"
 	"github.com/ysugimoto/falco/v2/interpreter/value"
 )
 
@@ -27,6 +28,10 @@ func Testing_override_host(
 	switch args[0].Type() {
 	case value.StringType:
 		override := value.Unwrap[*value.String](args[0]).Value
+		// Internationalized hostname is sent as punycode on the actual request
+		if encoded, err := shared.IdnaToASCII(override); err == nil {
+			override = encoded
+		}
 		ctx.OriginalHost = override
 		if ctx.Request != nil {
 			ctx.Request.Header.Set("Host", override)
@@ -0,0 +1,68 @@
+package function
+
+import (
+	"net"
+
+	"github.com/ysugimoto/falco/v2/interpreter"
+	"github.com/ysugimoto/falco/v2/interpreter/context"
+	"github.com/ysugimoto/falco/v2/interpreter/function/errors"
+	"github.com/ysugimoto/falco/v2/interpreter/value"
+)
+
+const Testing_override_variable_Name = "testing.override_variable"
+
+func Testing_override_variable_Validate(args []value.Value) error {
+	if len(args) != 2 {
+		return errors.ArgumentNotEnough(Testing_override_variable_Name, 2, args)
+	}
+	if args[0].Type() != value.StringType {
+		return errors.TypeMismatch(Testing_override_variable_Name, 1, value.StringType, args[0].Type())
+	}
+	return nil
+}
+
+// Override the value of any predefined variable including read-only ones like client.geo.country_code for the current test case.
+// The value must be the type of the variable in the interpreter, STRING is accepted for IP and INTEGER is accepted for FLOAT
+func Testing_override_variable(
+	ctx *context.Context,
+	i *interpreter.Interpreter,
+	args ...value.Value,
+) (value.Value, error) {
+
+	if err := Testing_override_variable_Validate(args); err != nil {
+		return nil, errors.NewTestingError("%s", err.Error())
+	}
+
+	name := value.Unwrap[*value.String](args[0]).Value
+	expect, err := i.TestVariableType(name)
+	if err != nil {
+		return value.Null, errors.NewTestingError("%s could not be overridden: %s", name, err)
+	}
+
+	v := args[1]
+	switch {
+	case expect == value.IpType && v.Type() == value.StringType:
+		ip := net.ParseIP(value.Unwrap[*value.String](v).Value)
+		if ip == nil {
+			return value.Null, errors.NewTestingError("invalid IP %s for %s", v.String(), name)
+		}
+		v = &value.IP{Value: ip}
+	case expect == value.FloatType && v.Type() == value.IntegerType:
+		v = &value.Float{Value: float64(value.Unwrap[*value.Integer](v).Value)}
+	case expect != v.Type():
+		return value.Null, errors.NewTestingError("%s is %s type but %s is provided", name, expect, v.Type())
+	default:
+		v = v.Copy()
+	}
+	unliteral(v)
+
+	// Keep the original value to restore it before the next test case
+	if ctx.OverrideBackups == nil {
+		ctx.OverrideBackups = make(map[string]value.Value)
+	}
+	if _, ok := ctx.OverrideBackups[name]; !ok {
+		ctx.OverrideBackups[name] = ctx.OverrideVariables[name]
+	}
+	ctx.OverrideVariables[name] = v
+	return value.Null, nil
+}
@@ -0,0 +1,112 @@
+package function
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/ysugimoto/falco/v2/interpreter"
+	"github.com/ysugimoto/falco/v2/interpreter/context"
+	"github.com/ysugimoto/falco/v2/interpreter/http"
+	"github.com/ysugimoto/falco/v2/interpreter/value"
+	"github.com/ysugimoto/falco/v2/resolver"
+)
+
+func newOverrideVariableInterpreter(t *testing.T) *interpreter.Interpreter {
+	t.Helper()
+	i := interpreter.New(context.WithResolver(resolver.NewStaticResolver("main", "sub vcl_recv {}")))
+	if err := i.TestProcessInit(http.WrapRequest(httptest.NewRequest("GET", "http://localhost", nil))); err != nil {
+		t.Fatalf("Unexpected interpreter initialization error: %s", err)
+	}
+	i.SetScope(context.RecvScope)
+	return i
+}
+
+func Test_override_variable(t *testing.T) {
+	i := newOverrideVariableInterpreter(t)
+
+	t.Run("override read-only variables", func(t *testing.T) {
+		c := context.New()
+		tests := []struct {
+			name   string
+			value  value.Value
+			expect value.Value
+		}{
+			{
+				name:   "client.geo.country_code",
+				value:  &value.String{Value: "JP", Literal: true},
+				expect: &value.String{Value: "JP"},
+			},
+			{
+				name:   "fastly.ff.visits_this_service",
+				value:  &value.Integer{Value: 2, Literal: true},
+				expect: &value.Integer{Value: 2},
+			},
+			{
+				name:   "client.geo.latitude",
+				value:  &value.Integer{Value: 35, Literal: true},
+				expect: &value.Float{Value: 35},
+			},
+			{
+				name:   "server.datacenter",
+				value:  &value.String{Value: "NRT", Literal: true},
+				expect: &value.String{Value: "NRT"},
+			},
+		}
+		for _, tt := range tests {
+			if _, err := Testing_override_variable(c, i, &value.String{Value: tt.name}, tt.value); err != nil {
+				t.Errorf("[%s] Unexpected error: %s", tt.name, err)
+				continue
+			}
+			if diff := cmp.Diff(tt.expect, c.OverrideVariables[tt.name]); diff != "" {
+				t.Errorf("[%s] Override value mismatch, diff=%s", tt.name, diff)
+			}
+			if v, ok := c.OverrideBackups[tt.name]; !ok || v != nil {
+				t.Errorf("[%s] Original absence of the override should be backed up, got %v", tt.name, v)
+			}
+		}
+	})
+
+	t.Run("coerce string to IP", func(t *testing.T) {
+		c := context.New()
+		if _, err := Testing_override_variable(c, i, &value.String{Value: "client.ip"}, &value.String{Value: "198.51.100.1"}); err != nil {
+			t.Errorf("Unexpected error: %s", err)
+			return
+		}
+		ip, ok := c.OverrideVariables["client.ip"].(*value.IP)
+		if !ok || ip.Value.String() != "198.51.100.1" {
+			t.Errorf("Expected IP 198.51.100.1, got %v", c.OverrideVariables["client.ip"])
+		}
+	})
+
+	t.Run("back up the override value of configuration", func(t *testing.T) {
+		c := context.New()
+		original := &value.String{Value: "ASIA"}
+		c.OverrideVariables["server.region"] = original
+		for _, region := range []string{"EU", "US"} {
+			if _, err := Testing_override_variable(c, i, &value.String{Value: "server.region"}, &value.String{Value: region}); err != nil {
+				t.Errorf("Unexpected error: %s", err)
+				return
+			}
+		}
+		if c.OverrideBackups["server.region"] != original {
+			t.Errorf("First override value should be backed up, got %v", c.OverrideBackups["server.region"])
+		}
+	})
+
+	t.Run("invalid arguments", func(t *testing.T) {
+		c := context.New()
+		tests := [][]value.Value{
+			{&value.String{Value: "server.datacenter"}},
+			{&value.Integer{Value: 1}, &value.String{Value: "NRT"}},
+			{&value.String{Value: "unknown.variable"}, &value.String{Value: "NRT"}},
+			{&value.String{Value: "fastly.ff.visits_this_service"}, &value.String{Value: "2"}},
+			{&value.String{Value: "client.ip"}, &value.String{Value: "invalid"}},
+		}
+		for n, args := range tests {
+			if _, err := Testing_override_variable(c, i, args...); err == nil {
+				t.Errorf("[%d] Expected error but got nil", n)
+			}
+		}
+	})
+}
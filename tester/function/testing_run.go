@@ -0,0 +1,58 @@
+package function
+
+import (
+	"github.com/ysugimoto/falco/v2/interpreter"
+	"github.com/ysugimoto/falco/v2/interpreter/context"
+	"github.com/ysugimoto/falco/v2/interpreter/function/errors"
+	"github.com/ysugimoto/falco/v2/interpreter/value"
+)
+
+const Testing_run_Name = "testing.run"
+
+var Testing_run_ArgumentTypes = []value.Type{value.StringType, value.StringType}
+
+func Testing_run_Validate(args []value.Value) error {
+	if len(args) != 2 {
+		return errors.ArgumentNotEnough(Testing_run_Name, 2, args)
+	}
+
+	for i := range Testing_run_ArgumentTypes {
+		if args[i].Type() != Testing_run_ArgumentTypes[i] {
+			return errors.TypeMismatch(
+				Testing_run_Name, i+1, Testing_run_ArgumentTypes[i], args[i].Type(),
+			)
+		}
+	}
+	return nil
+}
+
+// Testing_run processes the subroutine in the testing VCL as the subtest of the running test case.
+// Returns true if the subtest has passed, failures of the subtest do not abort the test case
+func Testing_run(
+	ctx *context.Context,
+	i *interpreter.Interpreter,
+	defs *Definiions,
+	args ...value.Value,
+) (value.Value, error) {
+
+	if err := Testing_run_Validate(args); err != nil {
+		return nil, errors.NewTestingError("%s", err.Error())
+	}
+
+	name := value.Unwrap[*value.String](args[0]).Value
+	subName := value.Unwrap[*value.String](args[1]).Value
+	if name == "" {
+		return value.Null, errors.NewTestingError("subtest name must not be empty")
+	}
+
+	sub, ok := defs.Subroutines[subName]
+	if !ok {
+		return value.Null, errors.NewTestingError("subtest subroutine %s is not declared in testing VCL", subName)
+	}
+	if sub.ReturnType != nil || len(sub.Parameters) > 0 {
+		return value.Null, errors.NewTestingError(
+			"subtest subroutine %s must not be functional subroutine and must not have parameters", subName,
+		)
+	}
+	return &value.Boolean{Value: i.ProcessSubtest(name, sub)}, nil
+}
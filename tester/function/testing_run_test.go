@@ -0,0 +1,59 @@
+package function
+
+import (
+	"testing"
+
+	"github.com/ysugimoto/falco/v2/ast"
+	"github.com/ysugimoto/falco/v2/interpreter"
+	"github.com/ysugimoto/falco/v2/interpreter/context"
+	"github.com/ysugimoto/falco/v2/interpreter/value"
+)
+
+func Test_run(t *testing.T) {
+	defs := &Definiions{
+		Subroutines: map[string]*ast.SubroutineDeclaration{
+			"functional": parseSub(t, `sub functional STRING { return "foo"; }`),
+			"parameterized": parseSub(t, `sub parameterized(STRING var.path) {
+  set req.http.Foo = var.path;
+}`),
+		},
+	}
+
+	tests := []struct {
+		name string
+		args []value.Value
+	}{
+		{
+			name: "subtest name is not provided",
+			args: []value.Value{&value.String{Value: "subtest"}},
+		},
+		{
+			name: "subroutine name is not STRING",
+			args: []value.Value{&value.String{Value: "subtest"}, &value.Integer{Value: 1}},
+		},
+		{
+			name: "subtest name is empty",
+			args: []value.Value{&value.String{Value: ""}, &value.String{Value: "functional"}},
+		},
+		{
+			name: "subroutine is not declared in testing VCL",
+			args: []value.Value{&value.String{Value: "subtest"}, &value.String{Value: "undefined"}},
+		},
+		{
+			name: "subroutine is functional",
+			args: []value.Value{&value.String{Value: "subtest"}, &value.String{Value: "functional"}},
+		},
+		{
+			name: "subroutine has parameters",
+			args: []value.Value{&value.String{Value: "subtest"}, &value.String{Value: "parameterized"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Testing_run(&context.Context{}, interpreter.New(), defs, tt.args...); err == nil {
+				t.Errorf("Expected error but nil")
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package function
+
+import (
+	"github.com/ysugimoto/falco/v2/interpreter/context"
+	"github.com/ysugimoto/falco/v2/interpreter/function/errors"
+	"github.com/ysugimoto/falco/v2/interpreter/value"
+)
+
+const Testing_seed_Name = "testing.seed"
+
+var Testing_seed_ArgumentTypes = []value.Type{value.IntegerType}
+
+func Testing_seed_Validate(args []value.Value) error {
+	if len(args) != 1 {
+		return errors.ArgumentNotEnough(Testing_seed_Name, 1, args)
+	}
+	for i := range args {
+		if args[i].Type() != Testing_seed_ArgumentTypes[i] {
+			return errors.TypeMismatch(Testing_seed_Name, i+1, Testing_seed_ArgumentTypes[i], args[i].Type())
+		}
+	}
+	return nil
+}
+
+func Testing_seed(
+	ctx *context.Context,
+	args ...value.Value,
+) (value.Value, error) {
+
+	if err := Testing_seed_Validate(args); err != nil {
+		return nil, errors.NewTestingError("%s", err.Error())
+	}
+
+	ctx.Seed(value.Unwrap[*value.Integer](args[0]).Value)
+	return value.Null, nil
+}
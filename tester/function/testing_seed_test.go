@@ -0,0 +1,40 @@
+package function
+
+import (
+	"testing"
+
+	"github.com/ysugimoto/falco/v2/interpreter/context"
+	"github.com/ysugimoto/falco/v2/interpreter/value"
+)
+
+func Test_Testing_seed(t *testing.T) {
+	t.Run("Same seed generates same random values", func(t *testing.T) {
+		a := &context.Context{}
+		b := &context.Context{}
+		for _, c := range []*context.Context{a, b} {
+			if _, err := Testing_seed(c, &value.Integer{Value: 42}); err != nil {
+				t.Errorf("Unexpected error on Testing_seed, %s", err)
+				return
+			}
+		}
+		for range 10 {
+			if x, y := a.Rand().Int63(), b.Rand().Int63(); x != y {
+				t.Errorf("Random values are different, %d != %d", x, y)
+			}
+		}
+	})
+
+	t.Run("Argument type error", func(t *testing.T) {
+		_, err := Testing_seed(&context.Context{}, &value.String{Value: "42"})
+		if err == nil {
+			t.Errorf("Expected error but got nil")
+		}
+	})
+
+	t.Run("Argument count error", func(t *testing.T) {
+		_, err := Testing_seed(&context.Context{})
+		if err == nil {
+			t.Errorf("Expected error but got nil")
+		}
+	})
+}
@@ -0,0 +1,111 @@
+package function
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/ysugimoto/falco/v2/interpreter/context"
+	"github.com/ysugimoto/falco/v2/interpreter/function/errors"
+	"github.com/ysugimoto/falco/v2/interpreter/value"
+	"github.com/ysugimoto/falco/v2/tester/shared"
+)
+
+const Testing_snapshot_Name = "testing.snapshot"
+
+// Headers which differ on every run are excluded from snapshots
+var volatileSnapshotHeaders = []string{"Date", "Age"}
+
+func Testing_snapshot_Validate(args []value.Value) error {
+	for i, arg := range args {
+		if arg.Type() != value.StringType {
+			return errors.TypeMismatch(Testing_snapshot_Name, i+1, value.StringType, arg.Type())
+		}
+	}
+	return nil
+}
+
+// Take the snapshot of request and response state, and compare with the golden snapshot of the test case.
+// Arguments are variable names to be included in the snapshot additionally,
+// or the name prefixed with "-" like "-resp.http.X-Request-Id" which is excluded from the snapshot
+func Testing_snapshot(
+	ctx *context.Context,
+	s *shared.Snapshots,
+	args ...value.Value,
+) (value.Value, error) {
+
+	if err := Testing_snapshot_Validate(args); err != nil {
+		return value.Null, errors.NewTestingError("%s", err.Error())
+	}
+
+	var includes, excludes []string
+	for _, arg := range args {
+		name := value.Unwrap[*value.String](arg).Value
+		if v, ok := strings.CutPrefix(name, "-"); ok {
+			excludes = append(excludes, strings.ToLower(v))
+		} else {
+			includes = append(includes, name)
+		}
+	}
+
+	snapshot, err := Testing_snapshot_Take(ctx, includes, excludes)
+	if err != nil {
+		return value.Null, err
+	}
+	if err := s.Match(snapshot); err != nil {
+		return value.Null, errors.NewTestingError("%s", err.Error())
+	}
+	return value.Null, nil
+}
+
+func Testing_snapshot_Take(ctx *context.Context, includes, excludes []string) (string, error) {
+	var lines []string
+	add := func(name, v string) {
+		if slices.Contains(excludes, strings.ToLower(name)) {
+			return
+		}
+		lines = append(lines, fmt.Sprintf("%s: %s", name, v))
+	}
+	addHeaders := func(prefix string, h http.Header) {
+		for name, values := range h {
+			if slices.Contains(volatileSnapshotHeaders, name) {
+				continue
+			}
+			for _, v := range values {
+				add(prefix+name, v)
+			}
+		}
+	}
+
+	if ctx.Request != nil {
+		add("req.method", ctx.Request.Method)
+		add("req.url", ctx.Request.URL.RequestURI())
+		addHeaders("req.http.", ctx.Request.Header)
+	}
+	if ctx.BackendRequest != nil {
+		add("bereq.method", ctx.BackendRequest.Method)
+		add("bereq.url", ctx.BackendRequest.URL.RequestURI())
+		addHeaders("bereq.http.", ctx.BackendRequest.Header)
+	}
+	if ctx.BackendResponse != nil {
+		add("beresp.status", fmt.Sprint(ctx.BackendResponse.StatusCode))
+		addHeaders("beresp.http.", ctx.BackendResponse.Header)
+	}
+	if ctx.Response != nil {
+		add("resp.status", fmt.Sprint(ctx.Response.StatusCode))
+		addHeaders("resp.http.", ctx.Response.Header)
+	}
+	for _, name := range includes {
+		v, err := Testing_inspect(ctx, &value.String{Value: name})
+		if err != nil {
+			return "", err
+		}
+		add(name, v.String())
+	}
+
+	// Sort lines in order to be stable regardless of header map order
+	sort.Strings(lines)
+	return strings.Join(lines, "\n"), nil
+}
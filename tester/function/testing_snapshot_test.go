@@ -0,0 +1,61 @@
+package function
+
+import (
+	ghttp "net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/ysugimoto/falco/v2/interpreter/context"
+	"github.com/ysugimoto/falco/v2/interpreter/http"
+	"github.com/ysugimoto/falco/v2/interpreter/value"
+	"github.com/ysugimoto/falco/v2/tester/shared"
+)
+
+func Test_snapshot(t *testing.T) {
+	newContext := func(status int) *context.Context {
+		req, _ := ghttp.NewRequest(ghttp.MethodGet, "http://localhost/path?q=1", nil)
+		req.Header.Set("Host", "example.com")
+		resp := &ghttp.Response{StatusCode: status, Header: ghttp.Header{}}
+		resp.Header.Set("Cache-Control", "max-age=60")
+		resp.Header.Set("Date", "Mon, 02 Jan 2006 15:04:05 GMT")
+		resp.Header.Set("X-Request-Id", "abcdef")
+		return &context.Context{Request: http.WrapRequest(req), Response: http.WrapResponse(resp)}
+	}
+
+	t.Run("take snapshot", func(t *testing.T) {
+		actual, err := Testing_snapshot_Take(newContext(200), nil, []string{"resp.http.x-request-id"})
+		if err != nil {
+			t.Errorf("Unexpected error: %s", err)
+			return
+		}
+		expect := `req.http.Host: example.com
+req.method: GET
+req.url: /path?q=1
+resp.http.Cache-Control: max-age=60
+resp.status: 200`
+		if diff := cmp.Diff(expect, actual); diff != "" {
+			t.Errorf("Snapshot mismatch, diff=%s", diff)
+		}
+	})
+
+	t.Run("compare with golden snapshot", func(t *testing.T) {
+		testFile := filepath.Join(t.TempDir(), "main.test.vcl")
+		s := shared.NewSnapshots(false)
+		s.Begin(testFile, "test_recv [recv]")
+		if _, err := Testing_snapshot(newContext(200), s); err != nil {
+			t.Errorf("Unexpected error: %s", err)
+		}
+		s.Begin(testFile, "test_recv [recv]")
+		if _, err := Testing_snapshot(newContext(404), s); err == nil {
+			t.Errorf("Expected error but got nil")
+		}
+	})
+
+	t.Run("invalid argument", func(t *testing.T) {
+		s := shared.NewSnapshots(false)
+		if _, err := Testing_snapshot(newContext(200), s, &value.Integer{Value: 1}); err == nil {
+			t.Errorf("Expected error but got nil")
+		}
+	})
+}
@@ -0,0 +1,33 @@
+package function
+
+import (
+	"github.com/ysugimoto/falco/v2/interpreter/context"
+	"github.com/ysugimoto/falco/v2/interpreter/function/errors"
+	"github.com/ysugimoto/falco/v2/interpreter/value"
+)
+
+const Testing_soft_assertions_Name = "testing.soft_assertions"
+
+func Testing_soft_assertions_Validate(args []value.Value) error {
+	if len(args) != 1 {
+		return errors.ArgumentNotEnough(Testing_soft_assertions_Name, 1, args)
+	}
+	if args[0].Type() != value.BooleanType {
+		return errors.TypeMismatch(Testing_soft_assertions_Name, 1, value.BooleanType, args[0].Type())
+	}
+	return nil
+}
+
+// Enable or disable soft assertion mode for the current test case.
+// In soft assertion mode, the test case continues after failed assertions and all failures are reported together
+func Testing_soft_assertions(
+	ctx *context.Context,
+	args ...value.Value,
+) (value.Value, error) {
+
+	if err := Testing_soft_assertions_Validate(args); err != nil {
+		return value.Null, errors.NewTestingError("%s", err.Error())
+	}
+	ctx.SoftAssertions = value.Unwrap[*value.Boolean](args[0]).Value
+	return value.Null, nil
+}
@@ -0,0 +1,29 @@
+package function
+
+import (
+	"testing"
+
+	"github.com/ysugimoto/falco/v2/interpreter/context"
+	"github.com/ysugimoto/falco/v2/interpreter/value"
+)
+
+func Test_soft_assertions(t *testing.T) {
+	c := &context.Context{}
+	if _, err := Testing_soft_assertions(c, &value.Boolean{Value: true}); err != nil {
+		t.Errorf("Unexpected error: %s", err)
+		return
+	}
+	if !c.SoftAssertions {
+		t.Errorf("Soft assertion mode should be enabled")
+	}
+	if _, err := Testing_soft_assertions(c, &value.Boolean{Value: false}); err != nil {
+		t.Errorf("Unexpected error: %s", err)
+		return
+	}
+	if c.SoftAssertions {
+		t.Errorf("Soft assertion mode should be disabled")
+	}
+	if _, err := Testing_soft_assertions(c, &value.String{Value: "true"}); err == nil {
+		t.Errorf("Expected error for non-bool argument")
+	}
+}
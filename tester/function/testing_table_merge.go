@@ -17,6 +17,10 @@ func Testing_table_merge_Validate(args []value.Value) error {
 	}
 
 	for i := range Testing_table_merge_ArgumentTypes {
+		// Table name could be specified as string
+		if args[i].Type() == value.StringType {
+			continue
+		}
 		if args[i].Type() != Testing_table_merge_ArgumentTypes[i] {
 			return errors.TypeMismatch(
 				Testing_table_merge_Name, i+1, Testing_table_merge_ArgumentTypes[i], args[i].Type(),
@@ -36,12 +40,12 @@ func Testing_table_merge(
 		return nil, errors.NewTestingError("%s", err.Error())
 	}
 
-	baseTable := value.Unwrap[*value.Ident](args[0]).Value
+	baseTable := Testing_table_Name(args[0])
 	base, baseOK := ctx.Tables[baseTable]
 	if !baseOK {
 		return value.Null, errors.NewTestingError("table %s not found in VCL", baseTable)
 	}
-	mergeTable := value.Unwrap[*value.Ident](args[1]).Value
+	mergeTable := Testing_table_Name(args[1])
 	merge, mergeOK := defs.Tables[mergeTable]
 	if !mergeOK {
 		return value.Null, errors.NewTestingError("table %s not found in testing VCL", mergeTable)
@@ -51,6 +55,7 @@ func Testing_table_merge(
 	}
 
 	// Merge table fields
+	Testing_table_Backup(ctx, baseTable, base)
 	for i := range merge.Properties {
 		Testing_table_MergeProperty(base, merge.Properties[i])
 	}
@@ -74,6 +79,25 @@ func Testing_table_merge_CompareType(base, merge *ast.TableDeclaration) error {
 	return nil
 }
 
+// Keep original properties of the table before the first modification in the test case
+func Testing_table_Backup(ctx *context.Context, name string, table *ast.TableDeclaration) {
+	if ctx.TableBackups == nil {
+		ctx.TableBackups = make(map[string][]*ast.TableProperty)
+	}
+	if _, ok := ctx.TableBackups[name]; ok {
+		return
+	}
+	ctx.TableBackups[name] = append([]*ast.TableProperty{}, table.Properties...)
+}
+
+// Table name could be specified as both identifier and string
+func Testing_table_Name(v value.Value) string {
+	if v.Type() == value.IdentType {
+		return value.Unwrap[*value.Ident](v).Value
+	}
+	return value.Unwrap[*value.String](v).Value
+}
+
 func Testing_table_MergeProperty(base *ast.TableDeclaration, prop *ast.TableProperty) {
 	for i := range base.Properties {
 		// Check the same field name and replace it if found
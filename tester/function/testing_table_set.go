@@ -18,6 +18,10 @@ func Testing_table_set_Validate(args []value.Value) error {
 	}
 
 	for i := range Testing_table_set_ArgumentTypes {
+		// Table name could be specified as string
+		if i == 0 && args[i].Type() == value.StringType {
+			continue
+		}
 		if args[i].Type() != Testing_table_set_ArgumentTypes[i] {
 			return errors.TypeMismatch(
 				Testing_table_set_Name, i+1, Testing_table_set_ArgumentTypes[i], args[i].Type(),
@@ -36,7 +40,7 @@ func Testing_table_set(
 		return nil, errors.NewTestingError("%s", err.Error())
 	}
 
-	tableName := value.Unwrap[*value.Ident](args[0]).Value
+	tableName := Testing_table_Name(args[0])
 	// Check table existence
 	v, ok := ctx.Tables[tableName]
 	if !ok {
@@ -56,6 +60,7 @@ func Testing_table_set(
 	// Set Table value with virtual AST
 	key := value.Unwrap[*value.String](args[1]).Value
 	val := value.Unwrap[*value.String](args[2]).Value
+	Testing_table_Backup(ctx, tableName, v)
 	Testing_table_MergeProperty(v, &ast.TableProperty{
 		Meta: &ast.Meta{
 			Token: token.Null, // Null token for virtual AST
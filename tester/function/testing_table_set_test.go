@@ -94,6 +94,44 @@ table example {
 			return
 		}
 	})
+	t.Run("Table name as string and original properties are kept", func(t *testing.T) {
+		main := `
+table example {
+  "foo": "bar",
+}
+`
+		vcl, err := parser.New(lexer.NewFromString(main)).ParseVCL()
+		if err != nil {
+			t.Errorf("Parse error for main VCL: %s", err)
+		}
+		table := vcl.Statements[0].(*ast.TableDeclaration)
+		original := table.Properties[0]
+		c := &context.Context{
+			Tables: map[string]*ast.TableDeclaration{
+				table.Name.Value: table,
+			},
+		}
+		for _, kv := range [][2]string{{"foo", "baz"}, {"dog", "bark"}} {
+			_, err = Testing_table_set(
+				c,
+				&value.String{Value: "example"},
+				&value.String{Value: kv[0]},
+				&value.String{Value: kv[1]},
+			)
+			if err != nil {
+				t.Errorf("Error should be nil, got: %s", err)
+				return
+			}
+		}
+		if len(table.Properties) != 2 {
+			t.Errorf("Table property must have 2 properties, got: %d", len(table.Properties))
+			return
+		}
+		backup := c.TableBackups["example"]
+		if len(backup) != 1 || backup[0] != original {
+			t.Errorf("Original table properties should be kept, got: %v", backup)
+		}
+	})
 
 	t.Run("Error on table not found", func(t *testing.T) {
 		c := &context.Context{}
@@ -0,0 +1,114 @@
+package tester
+
+import (
+	"github.com/pkg/errors"
+	"github.com/ysugimoto/falco/v2/ast"
+	"github.com/ysugimoto/falco/v2/interpreter"
+	"github.com/ysugimoto/falco/v2/interpreter/context"
+)
+
+// Subroutine names of hooks in the test file which are processed around test cases instead of running as tests
+const (
+	hookBeforeAll  = "before_all"
+	hookBeforeEach = "before_each"
+	hookAfterEach  = "after_each"
+	hookAfterAll   = "after_all"
+)
+
+// Hook subroutines which are declared in the top level of the test file
+type fileHooks struct {
+	beforeAll  *ast.SubroutineDeclaration
+	beforeEach *ast.SubroutineDeclaration
+	afterEach  *ast.SubroutineDeclaration
+	afterAll   *ast.SubroutineDeclaration
+}
+
+func isFileHook(name string) bool {
+	switch name {
+	case hookBeforeAll, hookBeforeEach, hookAfterEach, hookAfterAll:
+		return true
+	}
+	return false
+}
+
+// Find hook subroutines in the test file
+func findFileHooks(vcl *ast.VCL) *fileHooks {
+	hooks := &fileHooks{}
+	for _, stmt := range vcl.Statements {
+		sub, ok := stmt.(*ast.SubroutineDeclaration)
+		if !ok {
+			continue
+		}
+		switch sub.Name.Value {
+		case hookBeforeAll:
+			hooks.beforeAll = sub
+		case hookBeforeEach:
+			hooks.beforeEach = sub
+		case hookAfterEach:
+			hooks.afterEach = sub
+		case hookAfterAll:
+			hooks.afterAll = sub
+		}
+	}
+	return hooks
+}
+
+// Process before_all hook once for the interpreter, and commit fixtures which are set up in the hook
+// so that they are restored on each test case instead of removing
+func (h *fileHooks) runBeforeAll(i *interpreter.Interpreter) error {
+	if h.beforeAll == nil {
+		return nil
+	}
+	if err := runFileHook(i, h.beforeAll, getTestMetadata(h.beforeAll).Scopes[0]); err != nil {
+		return errors.Wrap(err, "Failed to process before_all hook")
+	}
+	i.CommitTestFixtures()
+	return nil
+}
+
+// Process after_all hook once after all test cases of the interpreter are finished
+func (h *fileHooks) runAfterAll(i *interpreter.Interpreter) error {
+	if h.afterAll == nil {
+		return nil
+	}
+	if err := runFileHook(i, h.afterAll, getTestMetadata(h.afterAll).Scopes[0]); err != nil {
+		return errors.Wrap(err, "Failed to process after_all hook")
+	}
+	return nil
+}
+
+// Process before_each hook in the scope of the test case
+func (h *fileHooks) runBeforeEach(i *interpreter.Interpreter, s context.Scope) error {
+	return runFileHook(i, h.beforeEach, s)
+}
+
+// Process after_each hook in the scope of the test case
+func (h *fileHooks) runAfterEach(i *interpreter.Interpreter, s context.Scope) error {
+	return runFileHook(i, h.afterEach, s)
+}
+
+// Process the testing subroutine surrounded by before_each and after_each hooks.
+// after_each hook is processed even if the test fails so that it could clean up fixtures
+func (h *fileHooks) process(
+	i *interpreter.Interpreter,
+	s context.Scope,
+	sub *ast.SubroutineDeclaration,
+	args ...ast.Expression,
+) error {
+
+	err := h.runBeforeEach(i, s)
+	if err == nil {
+		err = i.ProcessTestSubroutine(s, sub, args...)
+	}
+	if hookErr := h.runAfterEach(i, s); err == nil {
+		err = hookErr
+	}
+	return err
+}
+
+func runFileHook(i *interpreter.Interpreter, hook *ast.SubroutineDeclaration, s context.Scope) error {
+	if hook == nil {
+		return nil
+	}
+	return errors.WithStack(i.ProcessTestSubroutine(s, hook))
+}
@@ -0,0 +1,189 @@
+package tester
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/ysugimoto/falco/v2/interpreter/exception"
+	ife "github.com/ysugimoto/falco/v2/interpreter/function/errors"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name          `xml:"testsuites"`
+	Name     string            `xml:"name,attr"`
+	Tests    int               `xml:"tests,attr"`
+	Failures int               `xml:"failures,attr"`
+	Skipped  int               `xml:"skipped,attr"`
+	Time     string            `xml:"time,attr"`
+	Suites   []*junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string           `xml:"name,attr"`
+	Tests     int              `xml:"tests,attr"`
+	Failures  int              `xml:"failures,attr"`
+	Skipped   int              `xml:"skipped,attr"`
+	Time      string           `xml:"time,attr"`
+	Timestamp string           `xml:"timestamp,attr"`
+	Cases     []*junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Skipped   *struct{}     `xml:"skipped"`
+	Failure   *junitFailure `xml:"failure"`
+	SystemOut *junitText    `xml:"system-out"`
+}
+
+// Multiline text is written as CDATA to keep line breaks readable
+type junitText struct {
+	Text string `xml:",cdata"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",cdata"`
+}
+
+// JUnitEncoder encodes test results into JUnit XML format which is rendered natively by CI systems.
+// Each test file is mapped to the testsuite and each test case is mapped to the testcase
+type JUnitEncoder struct {
+	w    io.Writer
+	base string
+	now  func() time.Time
+}
+
+// NewJUnitEncoder creates JUnit encoder.
+// Test file paths are converted to relative path from base directory
+func NewJUnitEncoder(w io.Writer, base string) *JUnitEncoder {
+	return &JUnitEncoder{
+		w:    w,
+		base: base,
+		now:  time.Now,
+	}
+}
+
+func (e *JUnitEncoder) Encode(f *TestFactory) error {
+	root := &junitTestSuites{Name: "falco"}
+	timestamp := e.now().UTC().Format("2006-01-02T15:04:05")
+
+	var total int64
+	for _, r := range f.Results {
+		suite, elapsed := e.encodeResult(r)
+		suite.Timestamp = timestamp
+		root.Suites = append(root.Suites, suite)
+		root.Tests += suite.Tests
+		root.Failures += suite.Failures
+		root.Skipped += suite.Skipped
+		total += elapsed
+	}
+	root.Time = junitSeconds(total)
+
+	out, err := xml.MarshalIndent(root, "", "  ")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	_, err = io.WriteString(e.w, xml.Header+string(out)+"\n")
+	return errors.WithStack(err)
+}
+
+func (e *JUnitEncoder) encodeResult(r *TestResult) (*junitTestSuite, int64) {
+	file := r.Filename
+	if e.base != "" {
+		if rel, err := filepath.Rel(e.base, file); err == nil {
+			file = rel
+		}
+	}
+	suite := &junitTestSuite{
+		Name: filepath.ToSlash(file),
+	}
+
+	var elapsed int64
+	for _, c := range r.Cases {
+		// Test name is the same as the console output
+		name := fmt.Sprintf("[VCL_%s] %s", c.Scope, c.Name)
+		if c.Group != "" {
+			name = fmt.Sprintf("[VCL_%s] %s › %s", c.Scope, c.Group, c.Name)
+		}
+		suite.add(name, c)
+		// Subtests are reported as test cases which have the nested name
+		for _, st := range c.Subtests {
+			suite.add(name+" › "+st.Name, st)
+		}
+		elapsed += c.Time
+	}
+	suite.Time = junitSeconds(elapsed)
+	return suite, elapsed
+}
+
+func (s *junitTestSuite) add(name string, c *TestCase) {
+	tc := &junitTestCase{
+		Name:      name,
+		Classname: s.Name,
+		Time:      junitSeconds(c.Time),
+	}
+	if len(c.Logs) > 0 {
+		tc.SystemOut = &junitText{Text: strings.Join(c.Logs, "\n")}
+	}
+	switch {
+	case c.Skip:
+		tc.Skipped = &struct{}{}
+		s.Skipped++
+	case c.Error != nil:
+		tc.Failure = newJUnitFailure(c.Error)
+		s.Failures++
+	}
+	s.Tests++
+	s.Cases = append(s.Cases, tc)
+}
+
+// Create failure element of the error. All failures are listed in the text with their positions
+// and the message of the first one is used for the message attribute in soft assertion mode
+func newJUnitFailure(err error) *junitFailure {
+	errs := []error{err}
+	if e, ok := err.(*ife.AssertionErrors); ok {
+		errs = e.Errors
+	}
+
+	f := &junitFailure{Type: junitFailureType(errs[0])}
+	lines := make([]string, len(errs))
+	for i := range errs {
+		v := newFailure(errs[i])
+		if i == 0 {
+			f.Message = v.Error
+		}
+		if v.File != "" {
+			lines[i] = fmt.Sprintf("%s\n    at %s:%d:%d", v.Error, v.File, v.Line, v.Position)
+		} else {
+			lines[i] = v.Error
+		}
+	}
+	f.Text = strings.Join(lines, "\n\n")
+	return f
+}
+
+func junitFailureType(err error) string {
+	switch e := errors.Cause(err).(type) {
+	case *exception.Exception:
+		return string(e.Type)
+	case *ife.AssertionError:
+		return "AssertionError"
+	case *ife.TestingError:
+		return "TestingError"
+	default:
+		return "Error"
+	}
+}
+
+// JUnit reports elapsed time in seconds
+func junitSeconds(msec int64) string {
+	return fmt.Sprintf("%.3f", float64(msec)/1000)
+}
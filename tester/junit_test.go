@@ -0,0 +1,80 @@
+package tester
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/ysugimoto/falco/v2/interpreter/function/errors"
+	"github.com/ysugimoto/falco/v2/token"
+)
+
+func TestJUnitEncoder(t *testing.T) {
+	factory := &TestFactory{
+		Results: []*TestResult{
+			{
+				Filename: "/work/tests/main.test.vcl",
+				Cases: []*TestCase{
+					{
+						Name:     "passes",
+						Scope:    "RECV",
+						Time:     12,
+						Logs:     []string{"log 1", "log 2"},
+						Subtests: []*TestCase{{Name: "subtest", Scope: "RECV", Time: 2}},
+					},
+					{
+						Name:  "fails",
+						Group: "group",
+						Scope: "FETCH",
+						Time:  3,
+						Error: &errors.AssertionErrors{
+							Errors: []error{
+								&errors.AssertionError{
+									Token:   token.Token{File: "main.vcl", Line: 42, Position: 7},
+									Message: `expected "foo"`,
+								},
+								&errors.AssertionError{
+									Token:   token.Token{File: "main.vcl", Line: 43, Position: 7},
+									Message: "not matched",
+								},
+							},
+						},
+					},
+					{Name: "skipped", Scope: "RECV", Skip: true},
+				},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	enc := NewJUnitEncoder(&buf, "/work")
+	enc.now = func() time.Time { return time.UnixMilli(1700000000000) }
+	if err := enc.Encode(factory); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	expect := `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites name="falco" tests="4" failures="1" skipped="1" time="0.015">
+  <testsuite name="tests/main.test.vcl" tests="4" failures="1" skipped="1" time="0.015" timestamp="2023-11-14T22:13:20">
+    <testcase name="[VCL_RECV] passes" classname="tests/main.test.vcl" time="0.012">
+      <system-out><![CDATA[log 1
+log 2]]></system-out>
+    </testcase>
+    <testcase name="[VCL_RECV] passes › subtest" classname="tests/main.test.vcl" time="0.002"></testcase>
+    <testcase name="[VCL_FETCH] group › fails" classname="tests/main.test.vcl" time="0.003">
+      <failure message="expected &#34;foo&#34;" type="AssertionError"><![CDATA[expected "foo"
+    at main.vcl:42:7
+
+not matched
+    at main.vcl:43:7]]></failure>
+    </testcase>
+    <testcase name="[VCL_RECV] skipped" classname="tests/main.test.vcl" time="0.000">
+      <skipped></skipped>
+    </testcase>
+  </testsuite>
+</testsuites>
+`
+	if diff := cmp.Diff(expect, buf.String()); diff != "" {
+		t.Errorf("JUnit XML mismatch, diff=%s", diff)
+	}
+}
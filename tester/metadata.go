@@ -5,8 +5,12 @@ import (
 	"io"
 	"strings"
 
+	"github.com/pkg/errors"
 	"github.com/ysugimoto/falco/v2/ast"
 	"github.com/ysugimoto/falco/v2/interpreter/context"
+	"github.com/ysugimoto/falco/v2/lexer"
+	"github.com/ysugimoto/falco/v2/parser"
+	"github.com/ysugimoto/falco/v2/token"
 )
 
 // Testing tag struct.
@@ -23,6 +27,22 @@ type Metadata struct {
 	Scopes []context.Scope
 	Skip   bool
 	Tags   []Tag
+	// Table name to generate test iteration for each entry
+	Table string
+	// Parameterized cases to generate test iteration for each case
+	Cases []*Case
+	// Assertions of the test must hold under perturbed conditions in chaos testing
+	Invariant bool
+	// Subroutine is processed only as the subtest by testing.run, not run as the test case
+	Subtest bool
+}
+
+// Case is the parameterized test case which is declared by @case annotation,
+// arguments are passed to parameters of the test subroutine
+type Case struct {
+	Name      string // Declared arguments like `"/old", "/new"`
+	Arguments []ast.Expression
+	Error     error // Failed to parse arguments
 }
 
 func (m *Metadata) MatchTags(tags []string) bool {
@@ -80,6 +100,32 @@ func getTestMetadata(sub *ast.SubroutineDeclaration) *Metadata {
 			continue
 		}
 
+		// If @table annotation found, run test for each entry of the table
+		if trimmed, found := strings.CutPrefix(l, "@table:"); found {
+			metadata.Table = strings.TrimSpace(trimmed)
+			continue
+		}
+
+		// If @case annotation found, run test for each case with arguments
+		if trimmed, found := strings.CutPrefix(l, "@case:"); found {
+			c := &Case{Name: strings.TrimSpace(trimmed)}
+			c.Arguments, c.Error = parseCaseArguments(c.Name)
+			metadata.Cases = append(metadata.Cases, c)
+			continue
+		}
+
+		// If @invariant annotation found, the test is repeated with perturbed conditions in chaos testing
+		if strings.HasPrefix(l, "@invariant") {
+			metadata.Invariant = true
+			continue
+		}
+
+		// If @subtest annotation found, the subroutine is processed only via testing.run
+		if strings.HasPrefix(l, "@subtest") {
+			metadata.Subtest = true
+			continue
+		}
+
 		// If @skip annotation found. mark as skipped test
 		if strings.HasPrefix(l, "@skip") {
 			metadata.Skip = true
@@ -177,3 +223,25 @@ END:
 
 	return tags
 }
+
+// Parse comma separated expressions of @case annotation like `"/old", "/new", 301`
+func parseCaseArguments(args string) ([]ast.Expression, error) {
+	p := parser.New(lexer.NewFromString(args))
+	var expressions []ast.Expression
+	for {
+		exp, err := p.ParseExpression(parser.LOWEST)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		expressions = append(expressions, exp)
+		if !p.PeekTokenIs(token.COMMA) {
+			break
+		}
+		p.NextToken() // point to comma
+		p.NextToken() // point to next expression
+	}
+	if !p.PeekTokenIs(token.EOF) {
+		return nil, errors.Errorf("Invalid @case arguments: %s", args)
+	}
+	return expressions, nil
+}
@@ -1,6 +1,7 @@
 package tester
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -98,6 +99,48 @@ sub test_subroutine {}
 				},
 			},
 		},
+		{
+			name: "table driven",
+			vcl: `
+// @suite: metadata test
+// @scope: recv
+// @table: redirects
+sub test_subroutine {}
+`,
+			expect: &Metadata{
+				Name:   "metadata test",
+				Scopes: []context.Scope{context.RecvScope},
+				Tags:   []Tag{},
+				Table:  "redirects",
+			},
+		},
+		{
+			name: "invariant",
+			vcl: `
+// @scope: recv
+// @invariant
+sub test_subroutine {}
+`,
+			expect: &Metadata{
+				Name:      "test_subroutine",
+				Scopes:    []context.Scope{context.RecvScope},
+				Tags:      []Tag{},
+				Invariant: true,
+			},
+		},
+		{
+			name: "subtest",
+			vcl: `
+// @subtest
+sub subtest_recv {}
+`,
+			expect: &Metadata{
+				Name:    "subtest_recv",
+				Scopes:  []context.Scope{context.RecvScope},
+				Tags:    []Tag{},
+				Subtest: true,
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -116,6 +159,53 @@ sub test_subroutine {}
 	}
 }
 
+func TestGetMetadataCases(t *testing.T) {
+	vcl, err := parser.New(lexer.NewFromString(`
+// @scope: recv
+// @case: "/old", "/new"
+// @case: "/foo" + "/bar", "/baz"
+// @case: "/invalid";
+sub test_subroutine(STRING var.url, STRING var.location) {}
+`)).ParseVCL()
+	if err != nil {
+		t.Errorf("VCL parser error: %s", err)
+		return
+	}
+	metadata := getTestMetadata(vcl.Statements[0].(*ast.SubroutineDeclaration))
+	if len(metadata.Cases) != 3 {
+		t.Errorf("Expected 3 cases, got %d", len(metadata.Cases))
+		return
+	}
+
+	expects := []struct {
+		name      string
+		arguments []string
+	}{
+		{name: `"/old", "/new"`, arguments: []string{`"/old"`, `"/new"`}},
+		{name: `"/foo" + "/bar", "/baz"`, arguments: []string{`("/foo" + "/bar")`, `"/baz"`}},
+	}
+	for i, expect := range expects {
+		c := metadata.Cases[i]
+		if c.Error != nil {
+			t.Errorf("Unexpected error on case %d: %s", i, c.Error)
+			continue
+		}
+		if diff := cmp.Diff(expect.name, c.Name); diff != "" {
+			t.Errorf("Case name mismatch, diff=%s", diff)
+		}
+		var arguments []string
+		for _, arg := range c.Arguments {
+			arguments = append(arguments, strings.TrimSpace(arg.String()))
+		}
+		if diff := cmp.Diff(expect.arguments, arguments); diff != "" {
+			t.Errorf("Case arguments mismatch, diff=%s", diff)
+		}
+	}
+	if metadata.Cases[2].Error == nil {
+		t.Errorf("Expected error for invalid case arguments")
+	}
+}
+
 // https://github.com/ysugimoto/falco/issues/457
 func TestTagsMatch(t *testing.T) {
 	tests := []struct {
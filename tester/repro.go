@@ -0,0 +1,196 @@
+package tester
+
+import (
+	ghttp "net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/ysugimoto/falco/v2/ast"
+	"github.com/ysugimoto/falco/v2/interpreter"
+	"github.com/ysugimoto/falco/v2/interpreter/context"
+	"github.com/ysugimoto/falco/v2/interpreter/http"
+	"github.com/ysugimoto/falco/v2/lexer"
+	"github.com/ysugimoto/falco/v2/parser"
+	"github.com/ysugimoto/falco/v2/repro"
+	"github.com/ysugimoto/falco/v2/resolver"
+	tf "github.com/ysugimoto/falco/v2/tester/function"
+	"github.com/ysugimoto/falco/v2/tester/shared"
+	"github.com/ysugimoto/falco/v2/tester/syntax"
+)
+
+// Take snapshot of the interpreter context before the test if reproduction output is enabled
+func (t *Tester) snapshot(i *interpreter.Interpreter) *repro.Repro {
+	if t.config.ReproDir == "" {
+		return nil
+	}
+	// Failing to take snapshot should not affect to the testing result
+	r, err := i.Snapshot()
+	if err != nil {
+		return nil
+	}
+	return r
+}
+
+// Write reproduction file of the failed test
+func (t *Tester) writeRepro(
+	r *repro.Repro,
+	testFile, group string,
+	sub *ast.SubroutineDeclaration,
+	index int,
+	it *iteration,
+	scope context.Scope,
+	err error,
+) {
+
+	if r == nil || err == nil {
+		return
+	}
+	r.Main = t.main
+	r.IncludePaths = t.config.IncludePaths
+	r.TestFile = testFile
+	r.Group = group
+	r.Suite = sub.Name.Value
+	r.Scope = scope.String()
+	r.Error = errors.Cause(err).Error()
+	r.Iteration = index
+	r.Entry = it.name
+
+	parts := []string{filepath.Base(testFile), group, sub.Name.Value}
+	// Each iteration has its own file so that failed iterations do not overwrite each other
+	if it.name != "" {
+		parts = append(parts, strconv.Itoa(index), it.name)
+	}
+	name := strings.Join(append(parts, r.Scope), " ")
+	r.WriteFile(filepath.Join(t.config.ReproDir, repro.FileName(name))) // nolint: errcheck
+}
+
+// Reproduce re-executes the failed test with restoring the interpreter context from reproduction file
+func (t *Tester) Reproduce(r *repro.Repro) (*TestCase, error) {
+	t.main = r.Main
+
+	resolvers, err := resolver.NewFileResolvers(r.TestFile, t.config.IncludePaths)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	main, err := resolvers[0].MainVCL()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	vcl, err := parser.New(
+		lexer.NewFromString(main.Data, lexer.WithFile(main.Name)),
+		parser.WithCustomParser(syntax.CustomParsers()...),
+	).ParseVCL()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	defs := t.factoryDefinitions(vcl)
+	sub, before, err := findReproSubroutine(vcl, defs, r)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	// Snapshots are not compared on reproduction
+	i := t.setupInterpreter(defs, main, shared.NewSnapshots(false))
+	mockRequest, err := http.NewRequest(ghttp.MethodGet, "http://localhost", ghttp.NoBody)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	mockRequest.RemoteAddr = "192.0.2.1:11111"
+	if err := i.TestProcessInit(mockRequest); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if err := i.Restore(r, func(name string) *ast.SubroutineDeclaration {
+		return defs.Subroutines[name]
+	}); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	it, err := t.reproIteration(i, defs, sub, r)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	i.SetTestTableEntry(it.entry)
+
+	debugger := NewDebugger()
+	i.Debugger = debugger
+	scope := context.ScopeByString(r.Scope)
+	hooks := findFileHooks(vcl)
+
+	start := time.Now()
+	err = hooks.runBeforeEach(i, scope)
+	if err == nil && before != nil {
+		i.SetScope(scope)
+		if _, _, _, err := i.ProcessBlockStatement(before.Block.Statements, interpreter.DebugPass, false); err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+	if err == nil {
+		err = i.ProcessTestSubroutine(scope, sub, it.arguments...)
+	}
+	if hookErr := hooks.runAfterEach(i, scope); err == nil {
+		err = hookErr
+	}
+	return &TestCase{
+		Name:  iterationCaseName(getTestMetadata(sub).Name, it),
+		Group: r.Group,
+		Error: errors.Cause(err),
+		Scope: r.Scope,
+		Time:  time.Since(start).Milliseconds(),
+		Logs:  debugger.stack,
+	}, nil
+}
+
+// Find the iteration of the testing subroutine which is recorded in reproduction.
+// Iterations are generated again from the annotation, so the entry name is verified
+// in order to detect that the table or cases are changed after the reproduction file is written
+func (t *Tester) reproIteration(
+	i *interpreter.Interpreter,
+	defs *tf.Definiions,
+	sub *ast.SubroutineDeclaration,
+	r *repro.Repro,
+) (*iteration, error) {
+
+	iterations, err := t.iterations(i, defs, getTestMetadata(sub))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if r.Iteration < 0 || r.Iteration >= len(iterations) || iterations[r.Iteration].name != r.Entry {
+		return nil, errors.Errorf("Iteration %d [%s] of %s is not found in %s", r.Iteration, r.Entry, r.Suite, r.TestFile)
+	}
+	return iterations[r.Iteration], nil
+}
+
+// Find the testing subroutine and before hook which corresponds to the scope in reproduction.
+// Subroutines inside describe statement are added to the definitions to be able to mock them
+func findReproSubroutine(
+	vcl *ast.VCL,
+	defs *tf.Definiions,
+	r *repro.Repro,
+) (*ast.SubroutineDeclaration, *syntax.HookStatement, error) {
+
+	for _, stmt := range vcl.Statements {
+		switch st := stmt.(type) {
+		case *syntax.DescribeStatement:
+			if st.Name.String() != r.Group {
+				continue
+			}
+			for _, sub := range st.Subroutines {
+				defs.Subroutines[sub.Name.Value] = sub
+			}
+			for _, sub := range st.Subroutines {
+				if sub.Name.Value == r.Suite {
+					before := st.Befores[strings.ToLower("before_"+r.Scope)]
+					return sub, before, nil
+				}
+			}
+		case *ast.SubroutineDeclaration:
+			if r.Group == "" && st.Name.Value == r.Suite {
+				return st, nil, nil
+			}
+		}
+	}
+	return nil, nil, errors.Errorf("Test subroutine %s is not found in %s", r.Suite, r.TestFile)
+}
@@ -0,0 +1,204 @@
+package shared
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const coberturaDocType = `<!DOCTYPE coverage SYSTEM "http://cobertura.sourceforge.net/xml/coverage-04.dtd">`
+
+type coberturaCoverage struct {
+	XMLName         xml.Name            `xml:"coverage"`
+	LineRate        float64             `xml:"line-rate,attr"`
+	BranchRate      float64             `xml:"branch-rate,attr"`
+	LinesCovered    int                 `xml:"lines-covered,attr"`
+	LinesValid      int                 `xml:"lines-valid,attr"`
+	BranchesCovered int                 `xml:"branches-covered,attr"`
+	BranchesValid   int                 `xml:"branches-valid,attr"`
+	Complexity      int                 `xml:"complexity,attr"`
+	Version         string              `xml:"version,attr"`
+	Timestamp       int64               `xml:"timestamp,attr"`
+	Sources         []string            `xml:"sources>source"`
+	Packages        []*coberturaPackage `xml:"packages>package"`
+}
+
+type coberturaPackage struct {
+	Name       string            `xml:"name,attr"`
+	LineRate   float64           `xml:"line-rate,attr"`
+	BranchRate float64           `xml:"branch-rate,attr"`
+	Complexity int               `xml:"complexity,attr"`
+	Classes    []*coberturaClass `xml:"classes>class"`
+}
+
+type coberturaClass struct {
+	Name       string           `xml:"name,attr"`
+	Filename   string           `xml:"filename,attr"`
+	LineRate   float64          `xml:"line-rate,attr"`
+	BranchRate float64          `xml:"branch-rate,attr"`
+	Complexity int              `xml:"complexity,attr"`
+	Methods    struct{}         `xml:"methods"`
+	Lines      []*coberturaLine `xml:"lines>line"`
+}
+
+type coberturaLine struct {
+	Number            int    `xml:"number,attr"`
+	Hits              uint64 `xml:"hits,attr"`
+	Branch            bool   `xml:"branch,attr"`
+	ConditionCoverage string `xml:"condition-coverage,attr,omitempty"`
+
+	branchOnly        bool
+	branches, covered int
+}
+
+// Count of covered and valid lines and branches, used for calculating rates of each element
+type coberturaCounter struct {
+	lines, linesCovered, branches, branchesCovered int
+}
+
+func (c *coberturaCounter) add(v *coberturaCounter) {
+	c.lines += v.lines
+	c.linesCovered += v.linesCovered
+	c.branches += v.branches
+	c.branchesCovered += v.branchesCovered
+}
+
+func (c *coberturaCounter) rates() (float64, float64) {
+	return coberturaRate(c.linesCovered, c.lines), coberturaRate(c.branchesCovered, c.branches)
+}
+
+// Rate is 1 if there is nothing to cover
+func coberturaRate(covered, valid int) float64 {
+	if valid == 0 {
+		return 1
+	}
+	return math.Round(float64(covered)/float64(valid)*10000) / 10000
+}
+
+// CoberturaEncoder encodes coverage into Cobertura XML format which is consumed by CI services like GitLab.
+// Each VCL file is mapped to the package and each subroutine in the file is mapped to the class
+// which has lines of statement and branch markers in the subroutine
+type CoberturaEncoder struct {
+	w    io.Writer
+	base string
+	now  func() time.Time
+}
+
+// NewCoberturaEncoder creates Cobertura encoder.
+// VCL file paths are converted to relative path from base directory which is reported as the source root
+func NewCoberturaEncoder(w io.Writer, base string) *CoberturaEncoder {
+	return &CoberturaEncoder{
+		w:    w,
+		base: base,
+		now:  time.Now,
+	}
+}
+
+func (e *CoberturaEncoder) Encode(c *CoverageFactory) error {
+	root := &coberturaCoverage{
+		Version:   "falco",
+		Timestamp: e.now().UnixMilli(),
+	}
+	if e.base != "" {
+		root.Sources = []string{filepath.ToSlash(e.base)}
+	}
+
+	files := c.Files()
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var total coberturaCounter
+	for _, name := range names {
+		pkg, counter := e.encodeFile(relativeSourcePath(e.base, name), files[name])
+		root.Packages = append(root.Packages, pkg)
+		total.add(counter)
+	}
+	root.LineRate, root.BranchRate = total.rates()
+	root.LinesValid, root.LinesCovered = total.lines, total.linesCovered
+	root.BranchesValid, root.BranchesCovered = total.branches, total.branchesCovered
+
+	out, err := xml.MarshalIndent(root, "", "  ")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	_, err = io.WriteString(e.w, xml.Header+coberturaDocType+"\n"+string(out)+"\n")
+	return errors.WithStack(err)
+}
+
+func (e *CoberturaEncoder) encodeFile(file string, c *CoverageFactory) (*coberturaPackage, *coberturaCounter) {
+	pkg := &coberturaPackage{Name: file}
+
+	var total coberturaCounter
+	for _, sub := range c.SplitSubroutines() {
+		class, counter := e.encodeSubroutine(file, sub)
+		pkg.Classes = append(pkg.Classes, class)
+		total.add(counter)
+	}
+	pkg.LineRate, pkg.BranchRate = total.rates()
+	return pkg, &total
+}
+
+func (e *CoberturaEncoder) encodeSubroutine(file string, sub *SubroutineCoverage) (*coberturaClass, *coberturaCounter) {
+	lines := make(map[int]*coberturaLine)
+
+	// Line hits is the minimum count of markers on the line like LCOV output
+	markers := sortedMarkers(sub.Subroutines, sub.NodeMap)
+	for _, m := range append(markers, sortedMarkers(sub.Statements, sub.NodeMap)...) {
+		if line, ok := lines[m.tok.Line]; !ok {
+			lines[m.tok.Line] = &coberturaLine{Number: m.tok.Line, Hits: m.count}
+		} else if m.count < line.Hits {
+			line.Hits = m.count
+		}
+	}
+	// Line which has only branch markers is hit when some of branches are executed
+	for _, m := range sortedMarkers(sub.Branches, sub.NodeMap) {
+		line, ok := lines[m.tok.Line]
+		if !ok {
+			line = &coberturaLine{Number: m.tok.Line, Hits: m.count, branchOnly: true}
+			lines[m.tok.Line] = line
+		} else if line.branchOnly && m.count > line.Hits {
+			line.Hits = m.count
+		}
+		line.Branch = true
+		line.branches++
+		if m.count > 0 {
+			line.covered++
+		}
+	}
+
+	class := &coberturaClass{
+		Name:     markerName(sub.ID),
+		Filename: file,
+	}
+	var counter coberturaCounter
+	for _, line := range lines {
+		if line.Branch {
+			line.ConditionCoverage = fmt.Sprintf(
+				"%d%% (%d/%d)",
+				int(math.Round(float64(line.covered)/float64(line.branches)*100)),
+				line.covered, line.branches,
+			)
+			counter.branches += line.branches
+			counter.branchesCovered += line.covered
+		}
+		counter.lines++
+		if line.Hits > 0 {
+			counter.linesCovered++
+		}
+		class.Lines = append(class.Lines, line)
+	}
+	sort.Slice(class.Lines, func(i, j int) bool {
+		return class.Lines[i].Number < class.Lines[j].Number
+	})
+	class.LineRate, class.BranchRate = counter.rates()
+	return class, &counter
+}
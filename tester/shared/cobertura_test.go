@@ -0,0 +1,72 @@
+package shared
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/ysugimoto/falco/v2/ast"
+	"github.com/ysugimoto/falco/v2/token"
+)
+
+func TestCoberturaEncoder(t *testing.T) {
+	node := func(line, position int) ast.Node {
+		return &ast.Ident{Meta: &ast.Meta{Token: token.Token{File: "/work/main.vcl", Line: line, Position: position}}}
+	}
+
+	c := NewCoverage()
+	c.SetupSubroutine("/work/main.vcl:sub_1_1", node(1, 1))
+	c.SetupStatement("/work/main.vcl:stmt_2_3", node(2, 3))
+	c.SetupBranch("/work/main.vcl:branch_2_3_1", node(2, 3))
+	c.SetupBranch("/work/main.vcl:branch_2_3_2", node(2, 3))
+	c.SetupStatement("/work/main.vcl:stmt_3_5", node(3, 5))
+	c.SetupSubroutine("/work/main.vcl:sub_7_1", node(7, 1))
+	c.SetupStatement("/work/main.vcl:stmt_8_3", node(8, 3))
+	c.SetupBranch("/work/main.vcl:branch_9_5_true", node(9, 5))
+	c.SetupBranch("/work/main.vcl:branch_9_5_false", node(9, 5))
+
+	c.MarkSubroutine("/work/main.vcl:sub_1_1")
+	c.MarkStatement("/work/main.vcl:stmt_2_3")
+	c.MarkBranch("/work/main.vcl:branch_2_3_2")
+
+	var buf bytes.Buffer
+	enc := NewCoberturaEncoder(&buf, "/work")
+	enc.now = func() time.Time { return time.UnixMilli(1700000000000) }
+	if err := enc.Encode(c.Factory()); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	expect := `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE coverage SYSTEM "http://cobertura.sourceforge.net/xml/coverage-04.dtd">
+<coverage line-rate="0.3333" branch-rate="0.25" lines-covered="2" lines-valid="6" branches-covered="1" branches-valid="4" complexity="0" version="falco" timestamp="1700000000000">
+  <sources>
+    <source>/work</source>
+  </sources>
+  <packages>
+    <package name="main.vcl" line-rate="0.3333" branch-rate="0.25" complexity="0">
+      <classes>
+        <class name="sub_1_1" filename="main.vcl" line-rate="0.6667" branch-rate="0.5" complexity="0">
+          <methods></methods>
+          <lines>
+            <line number="1" hits="1" branch="false"></line>
+            <line number="2" hits="1" branch="true" condition-coverage="50% (1/2)"></line>
+            <line number="3" hits="0" branch="false"></line>
+          </lines>
+        </class>
+        <class name="sub_7_1" filename="main.vcl" line-rate="0" branch-rate="0" complexity="0">
+          <methods></methods>
+          <lines>
+            <line number="7" hits="0" branch="false"></line>
+            <line number="8" hits="0" branch="false"></line>
+            <line number="9" hits="0" branch="true" condition-coverage="0% (0/2)"></line>
+          </lines>
+        </class>
+      </classes>
+    </package>
+  </packages>
+</coverage>
+`
+	if diff := cmp.Diff(expect, buf.String()); diff != "" {
+		t.Errorf("Cobertura mismatch, diff=%s", diff)
+	}
+}
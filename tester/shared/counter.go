@@ -1,10 +1,15 @@
 package shared
 
+import "sync"
+
+// Counter is safe for concurrent use because test files could run in parallel
 type Counter struct {
 	Asserts int `json:"asserts"`
 	Passes  int `json:"passes"`
 	Fails   int `json:"fails"`
 	Skips   int `json:"skips"`
+
+	mu sync.Mutex
 }
 
 func NewCounter() *Counter {
@@ -12,15 +17,21 @@ func NewCounter() *Counter {
 }
 
 func (c *Counter) Pass() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	c.Asserts++
 	c.Passes++
 }
 
 func (c *Counter) Fail() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	c.Asserts++
 	c.Fails++
 }
 
 func (c *Counter) Skip() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	c.Skips++
 }
@@ -1,7 +1,11 @@
 package shared
 
 import (
+	"fmt"
 	"math"
+	"path/filepath"
+	"sort"
+	"strings"
 	"sync"
 
 	"github.com/ysugimoto/falco/v2/ast"
@@ -29,11 +33,31 @@ func (t CoverageType) String() string {
 	}
 }
 
+// CoverageSink receives coverage markers while VCL is processing.
+// Embedders could implement this interface to stream coverage to external systems
+// e.g. live coverage dashboard during exploratory testing against the simulator
+type CoverageSink interface {
+	// Setup is called when the marker is instrumented to the VCL
+	Setup(t CoverageType, key string, tok token.Token)
+	// Mark is called when the marker is executed with accumulated count
+	Mark(t CoverageType, key string, count uint64)
+}
+
 type Coverage struct {
 	Subroutines *sync.Map // map[string]uint64
 	Statements  *sync.Map // map[string]uint64
 	Branches    *sync.Map // map[string]uint64
 	NodeMap     *sync.Map // map[string]token.Token
+	Exclusions  *sync.Map // map[string]*CoverageExclusion
+	Dead        *sync.Map // map[string]*CoverageExclusion
+
+	subroutineIDs *sync.Map // map[string]string
+	stableIDs     *sync.Map // map[string]string
+	decisions     *decisionRecorder
+	sinks         []CoverageSink
+
+	// Serialize counting up markers and notifying sinks because test files could run in parallel
+	mu sync.Mutex
 }
 
 func NewCoverage() *Coverage {
@@ -42,40 +66,161 @@ func NewCoverage() *Coverage {
 		Statements:  &sync.Map{},
 		Branches:    &sync.Map{},
 		NodeMap:     &sync.Map{},
+		Exclusions:  &sync.Map{},
+		Dead:        &sync.Map{},
+
+		subroutineIDs: &sync.Map{},
+		stableIDs:     &sync.Map{},
+		decisions:     newDecisionRecorder(),
 	}
 }
 
+// AddSink adds coverage sinks. Sinks must be added before the coverage measurement starts
+func (c *Coverage) AddSink(sinks ...CoverageSink) {
+	c.sinks = append(c.sinks, sinks...)
+}
+
 func (c *Coverage) MarkSubroutine(key string) {
-	if v, ok := c.Subroutines.Load(key); ok {
-		c.Subroutines.Swap(key, v.(uint64)+1) // nolint:errcheck
-	}
+	c.mark(CoverageTypeSubroutine, c.Subroutines, key)
 }
 
 func (c *Coverage) MarkStatement(key string) {
-	if v, ok := c.Statements.Load(key); ok {
-		c.Statements.Swap(key, v.(uint64)+1) // nolint:errcheck
-	}
+	c.mark(CoverageTypeStatement, c.Statements, key)
 }
 
 func (c *Coverage) MarkBranch(key string) {
-	if v, ok := c.Branches.Load(key); ok {
-		c.Branches.Swap(key, v.(uint64)+1) // nolint:errcheck
+	c.mark(CoverageTypeBranch, c.Branches, key)
+}
+
+func (c *Coverage) mark(t CoverageType, m *sync.Map, key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	v, ok := m.Load(key)
+	if !ok {
+		return
+	}
+	count := v.(uint64) + 1 // nolint:errcheck
+	m.Swap(key, count)
+	for _, sink := range c.sinks {
+		sink.Mark(t, key, count)
 	}
 }
 
 func (c *Coverage) SetupSubroutine(key string, node ast.Node) {
-	c.Subroutines.LoadOrStore(key, uint64(0))
-	c.NodeMap.LoadOrStore(key, node.GetMeta().Token)
+	if sub, ok := node.(*ast.SubroutineDeclaration); ok {
+		c.subroutineIDs.LoadOrStore(sub.Name.Value, key)
+	}
+	c.setup(CoverageTypeSubroutine, c.Subroutines, key, node)
 }
 
 func (c *Coverage) SetupStatement(key string, node ast.Node) {
-	c.Statements.LoadOrStore(key, uint64(0))
-	c.NodeMap.LoadOrStore(key, node.GetMeta().Token)
+	c.setup(CoverageTypeStatement, c.Statements, key, node)
 }
 
 func (c *Coverage) SetupBranch(key string, node ast.Node) {
-	c.Branches.LoadOrStore(key, uint64(0))
-	c.NodeMap.LoadOrStore(key, node.GetMeta().Token)
+	c.setup(CoverageTypeBranch, c.Branches, key, node)
+}
+
+func (c *Coverage) setup(t CoverageType, m *sync.Map, key string, node ast.Node) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	m.LoadOrStore(key, uint64(0))
+	tok := node.GetMeta().Token
+	c.NodeMap.LoadOrStore(key, tok)
+	for _, sink := range c.sinks {
+		sink.Setup(t, key, tok)
+	}
+}
+
+// SetStableID associates the marker or decision id with the structural id which does not depend on source positions
+func (c *Coverage) SetStableID(key, id string) {
+	c.stableIDs.LoadOrStore(key, id)
+}
+
+// SetupDecision registers the compound condition which is measured for MC/DC
+func (c *Coverage) SetupDecision(d *Decision) {
+	c.decisions.setup(d)
+}
+
+// MarkDecision records the evaluation vector of the decision
+func (c *Coverage) MarkDecision(id, vector string) {
+	c.decisions.mark(id, vector)
+}
+
+// SubroutineID returns the marker id of the subroutine which is named as name
+func (c *Coverage) SubroutineID(name string) (string, bool) {
+	if v, ok := c.subroutineIDs.Load(name); ok {
+		return v.(string), true // nolint:errcheck
+	}
+	return "", false
+}
+
+// FindMarkers returns marker ids which are the same as id, or end with id like "stmt_10_5" without the file prefix
+func (c *Coverage) FindMarkers(id string) []string {
+	var ids []string
+	c.NodeMap.Range(func(key, _ any) bool {
+		k := key.(string) // nolint:errcheck
+		if k == id || strings.HasSuffix(k, ":"+id) {
+			ids = append(ids, k)
+		}
+		return true
+	})
+	sort.Strings(ids)
+	return ids
+}
+
+// FindBranches returns branch marker ids at the line of the file which have the branch suffix like "1" or "true".
+// File matches the marker file with the same path or the trailing path like "includes/main.vcl"
+func (c *Coverage) FindBranches(file string, line int, branch string) []string {
+	file = filepath.ToSlash(file)
+	var ids []string
+	c.Branches.Range(func(key, _ any) bool {
+		k := key.(string) // nolint:errcheck
+		v, ok := c.NodeMap.Load(k)
+		if !ok {
+			return true
+		}
+		tok := v.(token.Token) // nolint:errcheck
+		if tok.Line != line {
+			return true
+		}
+		if f := filepath.ToSlash(tok.File); f != file && !strings.HasSuffix(f, "/"+file) {
+			return true
+		}
+		// Branch marker name is "branch_[line]_[position]_[branch]"
+		if parts := strings.SplitN(markerName(k), "_", 4); len(parts) == 4 && parts[3] == branch {
+			ids = append(ids, k)
+		}
+		return true
+	})
+	sort.Strings(ids)
+	return ids
+}
+
+// Exclude records the region which is excluded from coverage by the pragma comment
+func (c *Coverage) Exclude(tok token.Token, endLine int) {
+	if endLine < tok.Line {
+		endLine = tok.Line
+	}
+	e := &CoverageExclusion{
+		File:      tok.File,
+		StartLine: tok.Line,
+		EndLine:   endLine,
+	}
+	c.Exclusions.LoadOrStore(e.String(), e)
+}
+
+// MarkDead records the region of statements which can never be executed like statements after return statement
+func (c *Coverage) MarkDead(tok token.Token, endLine int) {
+	if endLine < tok.Line {
+		endLine = tok.Line
+	}
+	e := &CoverageExclusion{
+		File:      tok.File,
+		StartLine: tok.Line,
+		EndLine:   endLine,
+	}
+	c.Dead.LoadOrStore(e.String(), e)
 }
 
 func (c *Coverage) Factory() *CoverageFactory {
@@ -84,6 +229,9 @@ func (c *Coverage) Factory() *CoverageFactory {
 		Statements:  make(CoverageFactoryItem),
 		Branches:    make(CoverageFactoryItem),
 		NodeMap:     make(map[string]token.Token),
+
+		SubroutineNames: make(map[string]string),
+		StableIDs:       make(map[string]string),
 	}
 
 	c.Subroutines.Range(func(key, val any) bool {
@@ -102,6 +250,25 @@ func (c *Coverage) Factory() *CoverageFactory {
 		r.NodeMap[key.(string)] = val.(token.Token) // nolint:errcheck
 		return true
 	})
+	c.subroutineIDs.Range(func(key, val any) bool {
+		r.SubroutineNames[val.(string)] = key.(string) // nolint:errcheck
+		return true
+	})
+	c.stableIDs.Range(func(key, val any) bool {
+		r.StableIDs[key.(string)] = val.(string) // nolint:errcheck
+		return true
+	})
+	c.Exclusions.Range(func(key, val any) bool {
+		r.Exclusions = append(r.Exclusions, val.(*CoverageExclusion)) // nolint:errcheck
+		return true
+	})
+	sortExclusions(r.Exclusions)
+	c.Dead.Range(func(key, val any) bool {
+		r.Dead = append(r.Dead, val.(*CoverageExclusion)) // nolint:errcheck
+		return true
+	})
+	sortExclusions(r.Dead)
+	r.Decisions = c.decisions.list()
 
 	return r
 }
@@ -113,6 +280,101 @@ type CoverageFactory struct {
 	Statements  CoverageFactoryItem
 	Branches    CoverageFactoryItem
 	NodeMap     map[string]token.Token
+	// Subroutine names of subroutine markers, the marker id is the key
+	SubroutineNames map[string]string
+	// Structural ids of markers and decisions like "vcl_recv/if[1]/then/set[0]",
+	// which are stable across formatting-only changes. The marker or decision id is the key
+	StableIDs map[string]string
+
+	// Regions which are excluded from coverage by pragma comments, sorted by file and line
+	Exclusions []*CoverageExclusion
+	// Regions of statements which can never be executed, sorted by file and line
+	Dead []*CoverageExclusion
+	// Compound conditions which are measured for MC/DC, sorted by file and position
+	Decisions []*Decision
+}
+
+// CoverageExclusion is the source region which is excluded from coverage measurement
+// by "falco:coverage-ignore" pragma comments, or which is unreachable
+type CoverageExclusion struct {
+	File      string `json:"file"`
+	StartLine int    `json:"start_line"`
+	EndLine   int    `json:"end_line"`
+}
+
+func (e *CoverageExclusion) String() string {
+	if e.StartLine == e.EndLine {
+		return fmt.Sprintf("%s:%d", e.File, e.StartLine)
+	}
+	return fmt.Sprintf("%s:%d-%d", e.File, e.StartLine, e.EndLine)
+}
+
+func sortExclusions(exclusions []*CoverageExclusion) {
+	sort.Slice(exclusions, func(i, j int) bool {
+		a, b := exclusions[i], exclusions[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.StartLine != b.StartLine {
+			return a.StartLine < b.StartLine
+		}
+		return a.EndLine < b.EndLine
+	})
+}
+
+// Files splits coverage into each source file of the marker.
+// Key of the returned map is the file name which is recorded in the token
+func (c *CoverageFactory) Files() map[string]*CoverageFactory {
+	files := make(map[string]*CoverageFactory)
+	factory := func(file string) *CoverageFactory {
+		if _, ok := files[file]; !ok {
+			files[file] = &CoverageFactory{
+				Subroutines: make(CoverageFactoryItem),
+				Statements:  make(CoverageFactoryItem),
+				Branches:    make(CoverageFactoryItem),
+				NodeMap:     make(map[string]token.Token),
+			}
+		}
+		return files[file]
+	}
+
+	for id, count := range c.Subroutines {
+		f := factory(c.NodeMap[id].File)
+		f.Subroutines[id] = count
+		f.NodeMap[id] = c.NodeMap[id]
+		if name, ok := c.SubroutineNames[id]; ok {
+			if f.SubroutineNames == nil {
+				f.SubroutineNames = make(map[string]string)
+			}
+			f.SubroutineNames[id] = name
+		}
+	}
+	for id, count := range c.Statements {
+		f := factory(c.NodeMap[id].File)
+		f.Statements[id] = count
+		f.NodeMap[id] = c.NodeMap[id]
+	}
+	for id, count := range c.Branches {
+		f := factory(c.NodeMap[id].File)
+		f.Branches[id] = count
+		f.NodeMap[id] = c.NodeMap[id]
+	}
+	for _, d := range c.Decisions {
+		f := factory(d.File)
+		f.Decisions = append(f.Decisions, d)
+	}
+	// Exclusions are attached only to files which have markers, entirely excluded file is not a coverage target
+	for _, e := range c.Exclusions {
+		if f, ok := files[e.File]; ok {
+			f.Exclusions = append(f.Exclusions, e)
+		}
+	}
+	for _, e := range c.Dead {
+		if f, ok := files[e.File]; ok {
+			f.Dead = append(f.Dead, e)
+		}
+	}
+	return files
 }
 
 func (c *CoverageFactory) Report() *CoverageReport {
@@ -120,7 +382,22 @@ func (c *CoverageFactory) Report() *CoverageReport {
 		Subroutines: c.calculate(c.Subroutines),
 		Statements:  c.calculate(c.Statements),
 		Branches:    c.calculate(c.Branches),
+		Conditions:  c.calculateConditions(),
+	}
+}
+
+// Count conditions which are shown to independently affect the outcome of decisions
+func (c *CoverageFactory) calculateConditions() *CoverageReportItem {
+	item := &CoverageReportItem{}
+	for _, d := range c.Decisions {
+		r := d.Report()
+		item.Executed += r.Executed
+		item.Total += r.Total
+	}
+	if item.Total > 0 {
+		item.Percent = math.Round(float64(item.Executed)/float64(item.Total)*10000) / 100
 	}
+	return item
 }
 
 func (c *CoverageFactory) calculate(v CoverageFactoryItem) *CoverageReportItem {
@@ -154,4 +431,49 @@ type CoverageReport struct {
 	Subroutines *CoverageReportItem
 	Statements  *CoverageReportItem
 	Branches    *CoverageReportItem
+	// MC/DC of compound conditions, zero total if there is no compound condition
+	Conditions *CoverageReportItem
+}
+
+// coverageMarker is the marker with position and count which is used for encoding coverage into other formats
+type coverageMarker struct {
+	id    string
+	tok   token.Token
+	count uint64
+}
+
+func sortedMarkers(item CoverageFactoryItem, nodes map[string]token.Token) []*coverageMarker {
+	markers := make([]*coverageMarker, 0, len(item))
+	for id, count := range item {
+		markers = append(markers, &coverageMarker{id: id, tok: nodes[id], count: count})
+	}
+	sort.Slice(markers, func(i, j int) bool {
+		a, b := markers[i].tok, markers[j].tok
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		if a.Position != b.Position {
+			return a.Position < b.Position
+		}
+		return markers[i].id < markers[j].id
+	})
+	return markers
+}
+
+// Trim file prefix of the marker id like "main.vcl:sub_1_1"
+func markerName(id string) string {
+	if i := strings.LastIndex(id, ":"); i >= 0 {
+		return id[i+1:]
+	}
+	return id
+}
+
+// Convert VCL file path to relative path from base directory if possible
+func relativeSourcePath(base, file string) string {
+	if base != "" && strings.EqualFold(filepath.Ext(file), ".vcl") {
+		if rel, err := filepath.Rel(base, file); err == nil {
+			file = rel
+		}
+	}
+	return filepath.ToSlash(file)
 }
@@ -0,0 +1,115 @@
+package shared
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ysugimoto/falco/v2/ast"
+	"github.com/ysugimoto/falco/v2/token"
+)
+
+type recordSink struct {
+	setups []string
+	marks  map[string]uint64
+}
+
+func (r *recordSink) Setup(t CoverageType, key string, tok token.Token) {
+	r.setups = append(r.setups, t.String()+":"+key)
+}
+
+func (r *recordSink) Mark(t CoverageType, key string, count uint64) {
+	r.marks[t.String()+":"+key] = count
+}
+
+func TestCoverageSink(t *testing.T) {
+	sink := &recordSink{marks: make(map[string]uint64)}
+	c := NewCoverage()
+	c.AddSink(sink)
+
+	node := &ast.Ident{Meta: &ast.Meta{Token: token.Token{Line: 1, Position: 1}}, Value: "foo"}
+	c.SetupSubroutine("sub_1_1", node)
+	c.SetupStatement("stmt_1_1", node)
+	c.MarkSubroutine("sub_1_1")
+	c.MarkSubroutine("sub_1_1")
+	c.MarkBranch("branch_1_1") // not setup, should be ignored
+
+	if len(sink.setups) != 2 || sink.setups[0] != "subroutine:sub_1_1" || sink.setups[1] != "statement:stmt_1_1" {
+		t.Errorf("Unexpected setups %v", sink.setups)
+	}
+	if len(sink.marks) != 1 || sink.marks["subroutine:sub_1_1"] != 2 {
+		t.Errorf("Unexpected marks %v", sink.marks)
+	}
+	if v, _ := c.Subroutines.Load("sub_1_1"); v.(uint64) != 2 {
+		t.Errorf("Coverage should be accumulated, got %v", v)
+	}
+}
+
+func TestCoverageConcurrentMark(t *testing.T) {
+	c := NewCoverage()
+	node := &ast.Ident{Meta: &ast.Meta{Token: token.Token{Line: 1, Position: 1}}, Value: "foo"}
+	c.SetupStatement("stmt_1_1", node)
+
+	// Test files could run in parallel and mark the same marker concurrently
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 100 {
+				c.MarkStatement("stmt_1_1")
+			}
+		}()
+	}
+	wg.Wait()
+
+	if v, _ := c.Statements.Load("stmt_1_1"); v.(uint64) != 1000 {
+		t.Errorf("Unexpected count, expect=1000, got=%d", v)
+	}
+}
+
+func TestCoverageFactoryFiles(t *testing.T) {
+	c := NewCoverage()
+	main := &ast.Ident{Meta: &ast.Meta{Token: token.Token{File: "main.vcl", Line: 1, Position: 1}}}
+	include := &ast.Ident{Meta: &ast.Meta{Token: token.Token{File: "include.vcl", Line: 1, Position: 1}}}
+	c.SetupSubroutine("main.vcl:sub_1_1", main)
+	c.SetupStatement("main.vcl:stmt_1_1", main)
+	c.SetupSubroutine("include.vcl:sub_1_1", include)
+	c.MarkSubroutine("include.vcl:sub_1_1")
+
+	files := c.Factory().Files()
+	if len(files) != 2 {
+		t.Fatalf("Expected 2 files, got %d", len(files))
+	}
+	if r := files["main.vcl"].Report(); r.Subroutines.Percent != 0 || r.Statements.Total != 1 {
+		t.Errorf("Unexpected main.vcl report %+v", r)
+	}
+	if r := files["include.vcl"].Report(); r.Subroutines.Percent != 100 || r.Statements.Total != 0 {
+		t.Errorf("Unexpected include.vcl report %+v", r)
+	}
+}
+
+func TestCoverageExclusions(t *testing.T) {
+	c := NewCoverage()
+	main := &ast.Ident{Meta: &ast.Meta{Token: token.Token{File: "main.vcl", Line: 1, Position: 1}}}
+	c.SetupSubroutine("main.vcl:sub_1_1", main)
+	// Same region is recorded for each instrumentation, and excluded region in the file without markers
+	c.Exclude(token.Token{File: "main.vcl", Line: 8}, 10)
+	c.Exclude(token.Token{File: "main.vcl", Line: 3}, 3)
+	c.Exclude(token.Token{File: "main.vcl", Line: 8}, 10)
+	c.Exclude(token.Token{File: "generated.vcl", Line: 1}, 20)
+
+	factory := c.Factory()
+	var regions []string
+	for _, e := range factory.Exclusions {
+		regions = append(regions, e.String())
+	}
+	if strings.Join(regions, ",") != "generated.vcl:1-20,main.vcl:3,main.vcl:8-10" {
+		t.Errorf("Unexpected exclusions %v", regions)
+	}
+
+	files := factory.Files()
+	if len(files) != 1 || len(files["main.vcl"].Exclusions) != 2 {
+		t.Errorf("Exclusions should be attached to files which have markers, got %v", files)
+	}
+}
@@ -0,0 +1,174 @@
+package shared
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Values of the condition in the evaluation vector
+const (
+	ConditionTrue         = 'T'
+	ConditionFalse        = 'F'
+	ConditionNotEvaluated = '-'
+)
+
+// Decision is the compound boolean expression of the if condition which is measured for
+// modified condition/decision coverage (MC/DC).
+// Executed evaluation vectors are recorded like "TF-:F" which means the first condition is true,
+// the second is false, the third is not evaluated by short-circuit and the decision outcome is false
+type Decision struct {
+	ID         string            `json:"id"`
+	File       string            `json:"file"`
+	Line       int               `json:"line"`
+	Position   int               `json:"position"`
+	Conditions []string          `json:"conditions"`
+	Vectors    map[string]uint64 `json:"vectors"`
+}
+
+// Vector creates the key of the evaluation vector from condition values and the outcome
+func Vector(values []byte, outcome bool) string {
+	if outcome {
+		return string(values) + ":T"
+	}
+	return string(values) + ":F"
+}
+
+// Independent reports whether each condition is shown to independently affect the decision outcome.
+// The condition is covered when there are two executed vectors which have different outcomes
+// and the condition is the only difference between them.
+// Conditions which are not evaluated by short-circuit in either vector are treated as don't care
+func (d *Decision) Independent() []bool {
+	type vector struct {
+		values  string
+		outcome string
+	}
+	var vectors []vector
+	for key, count := range d.Vectors {
+		values, outcome, ok := strings.Cut(key, ":")
+		if !ok || count == 0 || len(values) != len(d.Conditions) {
+			continue
+		}
+		vectors = append(vectors, vector{values: values, outcome: outcome})
+	}
+
+	covered := make([]bool, len(d.Conditions))
+	for i := range vectors {
+		for j := i + 1; j < len(vectors); j++ {
+			a, b := vectors[i], vectors[j]
+			if a.outcome == b.outcome {
+				continue
+			}
+			if index := uniqueCause(a.values, b.values); index >= 0 {
+				covered[index] = true
+			}
+		}
+	}
+	return covered
+}
+
+// Find the only condition which has different values in both vectors, returns -1 if not found
+func uniqueCause(a, b string) int {
+	index := -1
+	for i := 0; i < len(a); i++ {
+		if a[i] == ConditionNotEvaluated || b[i] == ConditionNotEvaluated || a[i] == b[i] {
+			continue
+		}
+		if index >= 0 {
+			return -1
+		}
+		index = i
+	}
+	return index
+}
+
+// Report returns the count of covered conditions and all conditions
+func (d *Decision) Report() *CoverageReportItem {
+	item := &CoverageReportItem{Total: uint64(len(d.Conditions))}
+	for _, v := range d.Independent() {
+		if v {
+			item.Executed++
+		}
+	}
+	if item.Total > 0 {
+		item.Percent = math.Round(float64(item.Executed)/float64(item.Total)*10000) / 100
+	}
+	return item
+}
+
+func (d *Decision) String() string {
+	return fmt.Sprintf("%s:%d (%s)", d.File, d.Line, strings.Join(d.Conditions, ", "))
+}
+
+func (d *Decision) clone() *Decision {
+	v := *d
+	v.Conditions = append([]string{}, d.Conditions...)
+	v.Vectors = make(map[string]uint64, len(d.Vectors))
+	for key, count := range d.Vectors {
+		v.Vectors[key] = count
+	}
+	return &v
+}
+
+// Records executed vectors of decisions, vectors map is guarded by the mutex
+type decisionRecorder struct {
+	mu        sync.Mutex
+	decisions map[string]*Decision
+}
+
+func newDecisionRecorder() *decisionRecorder {
+	return &decisionRecorder{
+		decisions: make(map[string]*Decision),
+	}
+}
+
+func (r *decisionRecorder) setup(d *Decision) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.decisions[d.ID]; ok {
+		return
+	}
+	if d.Vectors == nil {
+		d.Vectors = make(map[string]uint64)
+	}
+	r.decisions[d.ID] = d
+}
+
+func (r *decisionRecorder) mark(id, vector string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if d, ok := r.decisions[id]; ok {
+		d.Vectors[vector]++
+	}
+}
+
+// Copy decisions sorted by position
+func (r *decisionRecorder) list() []*Decision {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var decisions []*Decision
+	for _, d := range r.decisions {
+		decisions = append(decisions, d.clone())
+	}
+	SortDecisions(decisions)
+	return decisions
+}
+
+// SortDecisions sorts decisions by file and position
+func SortDecisions(decisions []*Decision) {
+	sort.Slice(decisions, func(i, j int) bool {
+		a, b := decisions[i], decisions[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		if a.Position != b.Position {
+			return a.Position < b.Position
+		}
+		return a.ID < b.ID
+	})
+}
@@ -0,0 +1,81 @@
+package shared
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDecisionIndependent(t *testing.T) {
+	tests := []struct {
+		name       string
+		conditions []string
+		vectors    map[string]uint64
+		expect     []bool
+	}{
+		{
+			name:       "all conditions are covered",
+			conditions: []string{"req.http.A", "req.http.B"},
+			vectors:    map[string]uint64{"TT:T": 1, "TF:F": 2, "F-:F": 1},
+			expect:     []bool{true, true},
+		},
+		{
+			name:       "short-circuited condition is not covered",
+			conditions: []string{"req.http.A", "req.http.B"},
+			vectors:    map[string]uint64{"TT:T": 1, "F-:F": 1, "TF:F": 0},
+			expect:     []bool{true, false},
+		},
+		{
+			name:       "multiple conditions change at once",
+			conditions: []string{"req.http.A", "req.http.B"},
+			vectors:    map[string]uint64{"TT:T": 1, "FF:F": 1},
+			expect:     []bool{false, false},
+		},
+		{
+			name:       "or conditions",
+			conditions: []string{"req.http.A", "req.http.B"},
+			vectors:    map[string]uint64{"T-:T": 1, "FT:T": 1, "FF:F": 1},
+			expect:     []bool{true, true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Decision{Conditions: tt.conditions, Vectors: tt.vectors}
+			if diff := cmp.Diff(tt.expect, d.Independent()); diff != "" {
+				t.Errorf("Independent() mismatch, diff=%s", diff)
+			}
+		})
+	}
+}
+
+func TestCoverageDecision(t *testing.T) {
+	c := NewCoverage()
+	c.SetupDecision(&Decision{
+		ID:         "main.vcl:decision_3_2",
+		File:       "main.vcl",
+		Line:       3,
+		Position:   2,
+		Conditions: []string{"req.http.A", "req.http.B"},
+	})
+	c.MarkDecision("main.vcl:decision_3_2", Vector([]byte("TT"), true))
+	c.MarkDecision("main.vcl:decision_3_2", Vector([]byte("F-"), false))
+	c.MarkDecision("main.vcl:decision_3_2", Vector([]byte("F-"), false))
+	// Unknown decision is ignored
+	c.MarkDecision("main.vcl:decision_9_2", Vector([]byte("TT"), true))
+
+	factory := c.Factory()
+	if len(factory.Decisions) != 1 {
+		t.Fatalf("Factory should have 1 decision, got %d", len(factory.Decisions))
+	}
+	if diff := cmp.Diff(map[string]uint64{"TT:T": 1, "F-:F": 2}, factory.Decisions[0].Vectors); diff != "" {
+		t.Errorf("Vectors mismatch, diff=%s", diff)
+	}
+	report := factory.Report().Conditions
+	if report.Executed != 1 || report.Total != 2 || report.Percent != 50 {
+		t.Errorf("Unexpected MC/DC report %+v", report)
+	}
+	if files := factory.Files(); len(files["main.vcl"].Decisions) != 1 {
+		t.Errorf("Decision should be attributed to the file")
+	}
+}
@@ -0,0 +1,123 @@
+package shared
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// LCOVEncoder encodes coverage into LCOV tracefile format which is accepted by Coveralls, Codecov and genhtml.
+// Coverage markers are mapped back to file/line records:
+// subroutine markers become FN/FNDA records, statement markers become DA records
+// and branch markers which are instrumented at the same node become a BRDA block
+type LCOVEncoder struct {
+	w    io.Writer
+	base string
+}
+
+// NewLCOVEncoder creates LCOV encoder.
+// VCL file paths are converted to relative path from base directory if it is not empty,
+// because coverage services resolve source files from the repository root
+func NewLCOVEncoder(w io.Writer, base string) *LCOVEncoder {
+	return &LCOVEncoder{
+		w:    w,
+		base: base,
+	}
+}
+
+func (e *LCOVEncoder) Encode(c *CoverageFactory) error {
+	files := c.Files()
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var buf strings.Builder
+	for _, name := range names {
+		e.encodeFile(&buf, name, files[name])
+	}
+	_, err := io.WriteString(e.w, buf.String())
+	return errors.WithStack(err)
+}
+
+func (e *LCOVEncoder) encodeFile(buf *strings.Builder, file string, c *CoverageFactory) {
+	buf.WriteString("TN:\n")
+	buf.WriteString("SF:" + relativeSourcePath(e.base, file) + "\n")
+
+	// Line execution count is the minimum count of markers on the line,
+	// so the line is reported as hit only when all statements on the line are executed
+	lines := make(map[int]uint64)
+	var lineOrder []int
+	hitLine := func(m *coverageMarker) {
+		if v, ok := lines[m.tok.Line]; !ok {
+			lines[m.tok.Line] = m.count
+			lineOrder = append(lineOrder, m.tok.Line)
+		} else if m.count < v {
+			lines[m.tok.Line] = m.count
+		}
+	}
+
+	// Subroutine marker id is used as function name because the marker does not know the name
+	// and the id is unique in the file
+	var fnHit int
+	subroutines := sortedMarkers(c.Subroutines, c.NodeMap)
+	for _, m := range subroutines {
+		buf.WriteString(fmt.Sprintf("FN:%d,%s\n", m.tok.Line, markerName(m.id)))
+	}
+	for _, m := range subroutines {
+		buf.WriteString(fmt.Sprintf("FNDA:%d,%s\n", m.count, markerName(m.id)))
+		if m.count > 0 {
+			fnHit++
+		}
+		hitLine(m)
+	}
+	buf.WriteString(fmt.Sprintf("FNF:%d\n", len(subroutines)))
+	buf.WriteString(fmt.Sprintf("FNH:%d\n", fnHit))
+
+	for _, m := range sortedMarkers(c.Statements, c.NodeMap) {
+		hitLine(m)
+	}
+
+	// Branch markers which point to the same node are outcomes of the same decision,
+	// so they are grouped into a block which is numbered in order of position on the line
+	var brHit, block, line, position int
+	branches := sortedMarkers(c.Branches, c.NodeMap)
+	for i, m := range branches {
+		if i > 0 && m.tok.Line == line && m.tok.Position != position {
+			block++
+		} else if i == 0 || m.tok.Line != line {
+			block = 0
+		}
+		var branch int
+		for j := i - 1; j >= 0; j-- {
+			if branches[j].tok.Line != m.tok.Line || branches[j].tok.Position != m.tok.Position {
+				break
+			}
+			branch++
+		}
+		line, position = m.tok.Line, m.tok.Position
+
+		buf.WriteString(fmt.Sprintf("BRDA:%d,%d,%d,%d\n", m.tok.Line, block, branch, m.count))
+		if m.count > 0 {
+			brHit++
+		}
+	}
+	buf.WriteString(fmt.Sprintf("BRF:%d\n", len(branches)))
+	buf.WriteString(fmt.Sprintf("BRH:%d\n", brHit))
+
+	sort.Ints(lineOrder)
+	var lineHit int
+	for _, l := range lineOrder {
+		buf.WriteString(fmt.Sprintf("DA:%d,%d\n", l, lines[l]))
+		if lines[l] > 0 {
+			lineHit++
+		}
+	}
+	buf.WriteString(fmt.Sprintf("LF:%d\n", len(lineOrder)))
+	buf.WriteString(fmt.Sprintf("LH:%d\n", lineHit))
+	buf.WriteString("end_of_record\n")
+}
@@ -0,0 +1,74 @@
+package shared
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/ysugimoto/falco/v2/ast"
+	"github.com/ysugimoto/falco/v2/token"
+)
+
+func TestLCOVEncoder(t *testing.T) {
+	node := func(file string, line, position int) ast.Node {
+		return &ast.Ident{Meta: &ast.Meta{Token: token.Token{File: file, Line: line, Position: position}}}
+	}
+
+	c := NewCoverage()
+	c.SetupSubroutine("/work/main.vcl:sub_1_1", node("/work/main.vcl", 1, 1))
+	c.SetupStatement("/work/main.vcl:stmt_2_3", node("/work/main.vcl", 2, 3))
+	c.SetupStatement("/work/main.vcl:stmt_3_3", node("/work/main.vcl", 3, 3))
+	c.SetupStatement("/work/main.vcl:stmt_3_20", node("/work/main.vcl", 3, 20))
+	c.SetupBranch("/work/main.vcl:branch_2_3_1", node("/work/main.vcl", 2, 3))
+	c.SetupBranch("/work/main.vcl:branch_2_3_2", node("/work/main.vcl", 2, 3))
+	c.SetupBranch("/work/main.vcl:branch_2_7_true", node("/work/main.vcl", 2, 7))
+	c.SetupBranch("/work/main.vcl:branch_2_7_false", node("/work/main.vcl", 2, 7))
+	c.SetupSubroutine("/work/lib/include.vcl:sub_1_1", node("/work/lib/include.vcl", 1, 1))
+
+	c.MarkSubroutine("/work/main.vcl:sub_1_1")
+	c.MarkStatement("/work/main.vcl:stmt_2_3")
+	c.MarkStatement("/work/main.vcl:stmt_2_3")
+	c.MarkStatement("/work/main.vcl:stmt_3_3")
+	c.MarkBranch("/work/main.vcl:branch_2_3_1")
+	c.MarkBranch("/work/main.vcl:branch_2_7_false")
+
+	var buf bytes.Buffer
+	if err := NewLCOVEncoder(&buf, "/work").Encode(c.Factory()); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	expect := `TN:
+SF:lib/include.vcl
+FN:1,sub_1_1
+FNDA:0,sub_1_1
+FNF:1
+FNH:0
+BRF:0
+BRH:0
+DA:1,0
+LF:1
+LH:0
+end_of_record
+TN:
+SF:main.vcl
+FN:1,sub_1_1
+FNDA:1,sub_1_1
+FNF:1
+FNH:1
+BRDA:2,0,0,1
+BRDA:2,0,1,0
+BRDA:2,1,0,1
+BRDA:2,1,1,0
+BRF:4
+BRH:2
+DA:1,1
+DA:2,2
+DA:3,0
+LF:3
+LH:2
+end_of_record
+`
+	if diff := cmp.Diff(expect, buf.String()); diff != "" {
+		t.Errorf("LCOV mismatch, diff=%s", diff)
+	}
+}
@@ -0,0 +1,204 @@
+package shared
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// Directory of snapshot files which is placed next to the test file
+	SnapshotDir = "__snapshots__"
+	// Snapshot file extension, snapshot file is named as test file name with this extension
+	SnapshotExtension = ".snap"
+
+	snapshotHeaderPrefix = "=== "
+)
+
+// SnapshotMismatch is the error of the snapshot which differs from the golden one
+type SnapshotMismatch struct {
+	Key  string
+	File string
+	Diff []string
+}
+
+func (e *SnapshotMismatch) Error() string {
+	return fmt.Sprintf(
+		"snapshot %q mismatch in %s, run with --update-snapshots to regenerate\n%s",
+		e.Key, e.File, strings.Join(e.Diff, "\n"),
+	)
+}
+
+type snapshotFile struct {
+	entries map[string]string
+	dirty   bool
+}
+
+// Snapshots stores golden snapshots of test cases.
+// The snapshot is recorded on first run and compared with the golden one on subsequent runs,
+// or always recorded in update mode
+type Snapshots struct {
+	update bool
+	files  map[string]*snapshotFile
+
+	// Current test case which takes snapshots, empty if snapshots are not compared
+	file  string
+	key   string
+	index int
+}
+
+func NewSnapshots(update bool) *Snapshots {
+	return &Snapshots{
+		update: update,
+		files:  make(map[string]*snapshotFile),
+	}
+}
+
+// SnapshotPath returns the snapshot file path of the test file
+func SnapshotPath(testFile string) string {
+	return filepath.Join(filepath.Dir(testFile), SnapshotDir, filepath.Base(testFile)+SnapshotExtension)
+}
+
+// Begin starts taking snapshots of the test case
+func (s *Snapshots) Begin(testFile, key string) {
+	s.file = SnapshotPath(testFile)
+	s.key = key
+	s.index = 0
+}
+
+// End stops taking snapshots, snapshots are not compared until the next test case begins
+func (s *Snapshots) End() {
+	s.file = ""
+	s.key = ""
+}
+
+// Match compares the snapshot with the golden one of the current test case.
+// Test case could take multiple snapshots, they are numbered in taken order
+func (s *Snapshots) Match(snapshot string) error {
+	if s.file == "" {
+		return nil
+	}
+	s.index++
+	key := fmt.Sprintf("%s #%d", s.key, s.index)
+
+	f, err := s.load(s.file)
+	if err != nil {
+		return err
+	}
+	golden, ok := f.entries[key]
+	if !ok || s.update {
+		if !ok || golden != snapshot {
+			f.entries[key] = snapshot
+			f.dirty = true
+		}
+		return nil
+	}
+	if golden == snapshot {
+		return nil
+	}
+	return &SnapshotMismatch{
+		Key:  key,
+		File: s.file,
+		Diff: diffLines(golden, snapshot),
+	}
+}
+
+// Save writes snapshot files which have new or updated snapshots
+func (s *Snapshots) Save() error {
+	for path, f := range s.files {
+		if !f.dirty {
+			continue
+		}
+		keys := make([]string, 0, len(f.entries))
+		for key := range f.entries {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		var buf strings.Builder
+		for _, key := range keys {
+			buf.WriteString(snapshotHeaderPrefix + key + "\n")
+			buf.WriteString(f.entries[key])
+			buf.WriteString("\n")
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return errors.WithStack(err)
+		}
+		if err := os.WriteFile(path, []byte(buf.String()), 0o644); err != nil {
+			return errors.WithStack(err)
+		}
+		f.dirty = false
+	}
+	return nil
+}
+
+func (s *Snapshots) load(path string) (*snapshotFile, error) {
+	if f, ok := s.files[path]; ok {
+		return f, nil
+	}
+	f := &snapshotFile{entries: make(map[string]string)}
+	s.files[path] = f
+
+	fp, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return f, nil
+		}
+		return nil, errors.WithStack(err)
+	}
+	defer fp.Close()
+
+	var key string
+	var lines []string
+	flush := func() {
+		if key != "" {
+			f.entries[key] = strings.Join(lines, "\n")
+		}
+	}
+	scanner := bufio.NewScanner(fp)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if k, ok := strings.CutPrefix(line, snapshotHeaderPrefix); ok {
+			flush()
+			key, lines = k, nil
+			continue
+		}
+		lines = append(lines, line)
+	}
+	flush()
+	return f, errors.WithStack(scanner.Err())
+}
+
+// Snapshot lines are sorted so that the difference is shown as removed and added lines
+func diffLines(golden, actual string) []string {
+	expect := make(map[string]int)
+	for _, line := range strings.Split(golden, "\n") {
+		expect[line]++
+	}
+	got := make(map[string]int)
+	for _, line := range strings.Split(actual, "\n") {
+		got[line]++
+	}
+
+	var diff []string
+	for _, line := range strings.Split(golden, "\n") {
+		if got[line] > 0 {
+			got[line]--
+			continue
+		}
+		diff = append(diff, "- "+line)
+	}
+	for _, line := range strings.Split(actual, "\n") {
+		if expect[line] > 0 {
+			expect[line]--
+			continue
+		}
+		diff = append(diff, "+ "+line)
+	}
+	return diff
+}
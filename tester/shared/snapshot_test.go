@@ -0,0 +1,87 @@
+package shared
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSnapshots(t *testing.T) {
+	testFile := filepath.Join(t.TempDir(), "main.test.vcl")
+	golden := "req.http.Host: example.com\nresp.status: 200"
+
+	t.Run("record on first run", func(t *testing.T) {
+		s := NewSnapshots(false)
+		s.Begin(testFile, "test_recv [recv]")
+		if err := s.Match(golden); err != nil {
+			t.Errorf("Unexpected error: %s", err)
+		}
+		if err := s.Match(""); err != nil {
+			t.Errorf("Unexpected error: %s", err)
+		}
+		s.End()
+		if err := s.Save(); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		buf, err := os.ReadFile(SnapshotPath(testFile))
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		expect := "=== test_recv [recv] #1\n" + golden + "\n=== test_recv [recv] #2\n\n"
+		if diff := cmp.Diff(expect, string(buf)); diff != "" {
+			t.Errorf("Snapshot file mismatch, diff=%s", diff)
+		}
+	})
+
+	t.Run("match golden snapshot", func(t *testing.T) {
+		s := NewSnapshots(false)
+		s.Begin(testFile, "test_recv [recv]")
+		if err := s.Match(golden); err != nil {
+			t.Errorf("Unexpected error: %s", err)
+		}
+		if err := s.Match(""); err != nil {
+			t.Errorf("Unexpected error: %s", err)
+		}
+	})
+
+	t.Run("mismatch golden snapshot", func(t *testing.T) {
+		s := NewSnapshots(false)
+		s.Begin(testFile, "test_recv [recv]")
+		err := s.Match("req.http.Host: example.com\nresp.status: 404")
+		var mismatch *SnapshotMismatch
+		if !errors.As(err, &mismatch) {
+			t.Fatalf("Expected SnapshotMismatch error, got %v", err)
+		}
+		if diff := cmp.Diff([]string{"- resp.status: 200", "+ resp.status: 404"}, mismatch.Diff); diff != "" {
+			t.Errorf("Diff mismatch, diff=%s", diff)
+		}
+	})
+
+	t.Run("not compared outside of test case", func(t *testing.T) {
+		s := NewSnapshots(false)
+		if err := s.Match("resp.status: 404"); err != nil {
+			t.Errorf("Unexpected error: %s", err)
+		}
+	})
+
+	t.Run("update golden snapshot", func(t *testing.T) {
+		s := NewSnapshots(true)
+		s.Begin(testFile, "test_recv [recv]")
+		if err := s.Match("resp.status: 404"); err != nil {
+			t.Errorf("Unexpected error: %s", err)
+		}
+		s.End()
+		if err := s.Save(); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+
+		s = NewSnapshots(false)
+		s.Begin(testFile, "test_recv [recv]")
+		if err := s.Match("resp.status: 404"); err != nil {
+			t.Errorf("Unexpected error: %s", err)
+		}
+	})
+}
@@ -0,0 +1,149 @@
+package shared
+
+import (
+	"sort"
+
+	"github.com/ysugimoto/falco/v2/token"
+)
+
+// CoverageThreshold is the minimum percent of each coverage type, zero value disables the check
+type CoverageThreshold struct {
+	Subroutine float64
+	Statement  float64
+	Branch     float64
+}
+
+// CoverageShortfall is the coverage type which falls below the threshold
+type CoverageShortfall struct {
+	Type      CoverageType
+	Percent   float64
+	Threshold float64
+}
+
+// Check returns coverage types which fall below the threshold
+func (c *CoverageFactory) Check(t *CoverageThreshold) []*CoverageShortfall {
+	report := c.Report()
+
+	var shortfalls []*CoverageShortfall
+	for _, v := range []struct {
+		t         CoverageType
+		threshold float64
+	}{
+		{CoverageTypeSubroutine, t.Subroutine},
+		{CoverageTypeStatement, t.Statement},
+		{CoverageTypeBranch, t.Branch},
+	} {
+		item := report.Item(v.t)
+		if v.threshold <= 0 || item.Total == 0 || item.Percent >= v.threshold {
+			continue
+		}
+		shortfalls = append(shortfalls, &CoverageShortfall{
+			Type:      v.t,
+			Percent:   item.Percent,
+			Threshold: v.threshold,
+		})
+	}
+	return shortfalls
+}
+
+// Item returns the coverage report of the type
+func (r *CoverageReport) Item(t CoverageType) *CoverageReportItem {
+	switch t {
+	case CoverageTypeSubroutine:
+		return r.Subroutines
+	case CoverageTypeStatement:
+		return r.Statements
+	default:
+		return r.Branches
+	}
+}
+
+// SubroutineCoverage is the coverage of markers in the subroutine
+type SubroutineCoverage struct {
+	// Subroutine marker id like "main.vcl:sub_1_1", its name and position
+	ID       string
+	Name     string
+	File     string
+	Line     int
+	Position int
+
+	*CoverageFactory
+}
+
+// SplitSubroutines splits coverage into each subroutine.
+// Subroutines do not nest, so markers belong to the last subroutine which starts before them in the same file.
+// Returned subroutines are sorted by file and position
+func (c *CoverageFactory) SplitSubroutines() []*SubroutineCoverage {
+	var subroutines []*SubroutineCoverage
+	files := c.Files()
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		f := files[name]
+		subs := sortedMarkers(f.Subroutines, f.NodeMap)
+		if len(subs) == 0 {
+			continue
+		}
+		split := make([]*SubroutineCoverage, len(subs))
+		for i, m := range subs {
+			split[i] = &SubroutineCoverage{
+				ID:       m.id,
+				Name:     c.SubroutineNames[m.id],
+				File:     name,
+				Line:     m.tok.Line,
+				Position: m.tok.Position,
+				CoverageFactory: &CoverageFactory{
+					Subroutines: CoverageFactoryItem{m.id: m.count},
+					Statements:  make(CoverageFactoryItem),
+					Branches:    make(CoverageFactoryItem),
+					NodeMap:     map[string]token.Token{m.id: m.tok},
+				},
+			}
+		}
+		owner := func(m *coverageMarker) *SubroutineCoverage {
+			index := 0
+			for i, sub := range subs {
+				if sub.tok.Line > m.tok.Line || (sub.tok.Line == m.tok.Line && sub.tok.Position > m.tok.Position) {
+					break
+				}
+				index = i
+			}
+			return split[index]
+		}
+		for _, m := range sortedMarkers(f.Statements, f.NodeMap) {
+			sub := owner(m)
+			sub.Statements[m.id] = m.count
+			sub.NodeMap[m.id] = m.tok
+		}
+		for _, m := range sortedMarkers(f.Branches, f.NodeMap) {
+			sub := owner(m)
+			sub.Branches[m.id] = m.count
+			sub.NodeMap[m.id] = m.tok
+		}
+		subroutines = append(subroutines, split...)
+	}
+	return subroutines
+}
+
+// UncoveredLines returns lines which have markers which are not executed in ascending order
+func (s *SubroutineCoverage) UncoveredLines() []int {
+	seen := make(map[int]struct{})
+	for _, item := range []CoverageFactoryItem{s.Subroutines, s.Statements, s.Branches} {
+		for id, count := range item {
+			if count == 0 {
+				seen[s.NodeMap[id].Line] = struct{}{}
+			}
+		}
+	}
+
+	lines := make([]int, 0, len(seen))
+	for line := range seen {
+		lines = append(lines, line)
+	}
+	sort.Ints(lines)
+	return lines
+}
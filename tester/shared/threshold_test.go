@@ -0,0 +1,124 @@
+package shared
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+
+	"github.com/ysugimoto/falco/v2/ast"
+	"github.com/ysugimoto/falco/v2/token"
+)
+
+func newThresholdCoverage() *Coverage {
+	node := func(file string, line, position int) ast.Node {
+		return &ast.Ident{Meta: &ast.Meta{Token: token.Token{File: file, Line: line, Position: position}}}
+	}
+
+	c := NewCoverage()
+	c.SetupSubroutine("main.vcl:sub_1_1", node("main.vcl", 1, 1))
+	c.SetupStatement("main.vcl:stmt_2_3", node("main.vcl", 2, 3))
+	c.SetupBranch("main.vcl:branch_2_3_1", node("main.vcl", 2, 3))
+	c.SetupBranch("main.vcl:branch_2_3_2", node("main.vcl", 2, 3))
+	c.SetupSubroutine("main.vcl:sub_6_1", node("main.vcl", 6, 1))
+	c.SetupStatement("main.vcl:stmt_7_3", node("main.vcl", 7, 3))
+	c.SetupStatement("main.vcl:stmt_8_3", node("main.vcl", 8, 3))
+	c.SetupSubroutine("include.vcl:sub_1_1", node("include.vcl", 1, 1))
+	c.SetupStatement("include.vcl:stmt_2_3", node("include.vcl", 2, 3))
+
+	c.MarkSubroutine("main.vcl:sub_1_1")
+	c.MarkStatement("main.vcl:stmt_2_3")
+	c.MarkBranch("main.vcl:branch_2_3_1")
+	c.MarkSubroutine("include.vcl:sub_1_1")
+	c.MarkStatement("include.vcl:stmt_2_3")
+	return c
+}
+
+func TestCoverageFactoryCheck(t *testing.T) {
+	factory := newThresholdCoverage().Factory()
+
+	tests := []struct {
+		name      string
+		threshold *CoverageThreshold
+		expects   []CoverageType
+	}{
+		{name: "disabled", threshold: &CoverageThreshold{}},
+		{name: "satisfied", threshold: &CoverageThreshold{Statement: 50, Branch: 50, Subroutine: 60}},
+		{name: "statement", threshold: &CoverageThreshold{Statement: 60, Branch: 50}, expects: []CoverageType{CoverageTypeStatement}},
+		{
+			name:      "all",
+			threshold: &CoverageThreshold{Statement: 100, Branch: 100, Subroutine: 100},
+			expects:   []CoverageType{CoverageTypeSubroutine, CoverageTypeStatement, CoverageTypeBranch},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shortfalls := factory.Check(tt.threshold)
+			if len(shortfalls) != len(tt.expects) {
+				t.Fatalf("Expect %d shortfalls but got %d", len(tt.expects), len(shortfalls))
+			}
+			for i := range shortfalls {
+				if shortfalls[i].Type != tt.expects[i] {
+					t.Errorf("Expect %s shortfall but got %s", tt.expects[i], shortfalls[i].Type)
+				}
+			}
+		})
+	}
+}
+
+func TestCoverageFactorySplitSubroutines(t *testing.T) {
+	subroutines := newThresholdCoverage().Factory().SplitSubroutines()
+
+	expects := []struct {
+		id         string
+		statements int
+		branches   int
+		executed   uint64
+	}{
+		{id: "include.vcl:sub_1_1", statements: 1, executed: 1},
+		{id: "main.vcl:sub_1_1", statements: 1, branches: 2, executed: 1},
+		{id: "main.vcl:sub_6_1", statements: 2, executed: 0},
+	}
+	if len(subroutines) != len(expects) {
+		t.Fatalf("Expect %d subroutines but got %d", len(expects), len(subroutines))
+	}
+	for i, e := range expects {
+		sub := subroutines[i]
+		if sub.ID != e.id {
+			t.Errorf("Expect subroutine %s but got %s", e.id, sub.ID)
+			continue
+		}
+		report := sub.Report()
+		if report.Statements.Total != uint64(e.statements) || report.Branches.Total != uint64(e.branches) {
+			t.Errorf("Unexpected markers of %s: %+v", e.id, report)
+		}
+		if report.Statements.Executed != e.executed {
+			t.Errorf("Expect %d executed statements of %s but got %d", e.executed, e.id, report.Statements.Executed)
+		}
+	}
+}
+
+func TestSubroutineCoverageUncoveredLines(t *testing.T) {
+	c := newThresholdCoverage()
+	c.subroutineIDs.Store("vcl_recv", "main.vcl:sub_1_1")
+	subroutines := c.Factory().SplitSubroutines()
+
+	expects := map[string]struct {
+		name  string
+		lines []int
+	}{
+		"include.vcl:sub_1_1": {},
+		"main.vcl:sub_1_1":    {name: "vcl_recv", lines: []int{2}},
+		"main.vcl:sub_6_1":    {lines: []int{6, 7, 8}},
+	}
+	for _, sub := range subroutines {
+		e := expects[sub.ID]
+		if sub.Name != e.name {
+			t.Errorf("Expect subroutine name %q of %s but got %q", e.name, sub.ID, sub.Name)
+		}
+		if diff := cmp.Diff(e.lines, sub.UncoveredLines(), cmpopts.EquateEmpty()); diff != "" {
+			t.Errorf("Uncovered lines of %s mismatch, diff=%s", sub.ID, diff)
+		}
+	}
+}
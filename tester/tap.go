@@ -0,0 +1,121 @@
+package tester
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	ife "github.com/ysugimoto/falco/v2/interpreter/function/errors"
+)
+
+// TAPEncoder encodes test results into TAP version 13 format which is consumed by TAP harnesses and aggregators.
+// Test cases of all test files are reported as test points in single plan,
+// and failures are reported with YAML diagnostics which have the assertion message, actual value and the position
+type TAPEncoder struct {
+	w    io.Writer
+	base string
+}
+
+// NewTAPEncoder creates TAP encoder.
+// Test file paths are converted to relative path from base directory
+func NewTAPEncoder(w io.Writer, base string) *TAPEncoder {
+	return &TAPEncoder{
+		w:    w,
+		base: base,
+	}
+}
+
+func (e *TAPEncoder) Encode(f *TestFactory) error {
+	var total int
+	for _, r := range f.Results {
+		for _, c := range r.Cases {
+			total += 1 + len(c.Subtests)
+		}
+	}
+
+	var buf strings.Builder
+	buf.WriteString("TAP version 13\n")
+	buf.WriteString(fmt.Sprintf("1..%d\n", total))
+
+	var n int
+	for _, r := range f.Results {
+		file := r.Filename
+		if e.base != "" {
+			if rel, err := filepath.Rel(e.base, file); err == nil {
+				file = rel
+			}
+		}
+		buf.WriteString("# " + filepath.ToSlash(file) + "\n")
+
+		for _, c := range r.Cases {
+			// Test name is the same as the console output
+			name := fmt.Sprintf("[VCL_%s] %s", c.Scope, c.Name)
+			if c.Group != "" {
+				name = fmt.Sprintf("[VCL_%s] %s › %s", c.Scope, c.Group, c.Name)
+			}
+			n++
+			writeTAPTestPoint(&buf, n, name, c)
+			// Subtests are reported as test points which have the nested name
+			for _, st := range c.Subtests {
+				n++
+				writeTAPTestPoint(&buf, n, name+" › "+st.Name, st)
+			}
+		}
+	}
+
+	_, err := io.WriteString(e.w, buf.String())
+	return errors.WithStack(err)
+}
+
+func writeTAPTestPoint(buf *strings.Builder, n int, name string, c *TestCase) {
+	switch {
+	case c.Skip:
+		buf.WriteString(fmt.Sprintf("ok %d - %s # SKIP\n", n, tapEscape(name)))
+	case c.Error != nil:
+		buf.WriteString(fmt.Sprintf("not ok %d - %s\n", n, tapEscape(name)))
+		writeTAPDiagnostics(buf, c.Error)
+	default:
+		buf.WriteString(fmt.Sprintf("ok %d - %s\n", n, tapEscape(name)))
+	}
+}
+
+// Write YAML diagnostics block of the failure.
+// All failures are listed in soft assertion mode
+func writeTAPDiagnostics(buf *strings.Builder, err error) {
+	buf.WriteString("  ---\n")
+	if e, ok := err.(*ife.AssertionErrors); ok {
+		buf.WriteString(fmt.Sprintf("  message: %s\n", strconv.Quote(fmt.Sprintf("%d failures", len(e.Errors)))))
+		buf.WriteString("  severity: fail\n")
+		buf.WriteString("  failures:\n")
+		for i := range e.Errors {
+			writeTAPFailure(buf, "    - ", "      ", e.Errors[i])
+		}
+	} else {
+		writeTAPFailure(buf, "  ", "  ", err)
+		buf.WriteString("  severity: fail\n")
+	}
+	buf.WriteString("  ...\n")
+}
+
+// Write fields of the failure, first line is written with the head indent to start the list item
+func writeTAPFailure(buf *strings.Builder, head, indent string, err error) {
+	f := newFailure(err)
+	buf.WriteString(fmt.Sprintf("%smessage: %s\n", head, strconv.Quote(f.Error)))
+	if e, ok := err.(*ife.AssertionError); ok && e.Actual != nil {
+		buf.WriteString(fmt.Sprintf("%sactual: %s\n", indent, strconv.Quote(e.Actual.String())))
+	}
+	if f.File != "" {
+		buf.WriteString(fmt.Sprintf("%sat:\n", indent))
+		buf.WriteString(fmt.Sprintf("%s  file: %s\n", indent, strconv.Quote(f.File)))
+		buf.WriteString(fmt.Sprintf("%s  line: %d\n", indent, f.Line))
+		buf.WriteString(fmt.Sprintf("%s  column: %d\n", indent, f.Position))
+	}
+}
+
+// "#" in the description is escaped not to be parsed as the directive
+func tapEscape(name string) string {
+	return strings.ReplaceAll(name, "#", `\#`)
+}
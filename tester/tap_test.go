@@ -0,0 +1,94 @@
+package tester
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/ysugimoto/falco/v2/interpreter/function/errors"
+	"github.com/ysugimoto/falco/v2/interpreter/value"
+	"github.com/ysugimoto/falco/v2/token"
+)
+
+func TestTAPEncoder(t *testing.T) {
+	tok := token.Token{File: "main.vcl", Line: 42, Position: 7}
+	factory := &TestFactory{
+		Results: []*TestResult{
+			{
+				Filename: "/work/tests/main.test.vcl",
+				Cases: []*TestCase{
+					{
+						Name:     "passes",
+						Scope:    "RECV",
+						Subtests: []*TestCase{{Name: "subtest #1", Scope: "RECV"}},
+					},
+					{
+						Name:  "fails",
+						Group: "group",
+						Scope: "FETCH",
+						Error: &errors.AssertionError{
+							Token:   tok,
+							Actual:  &value.String{Value: "bar"},
+							Message: "Assertion error: expect=foo, actual=bar",
+						},
+					},
+					{Name: "skipped", Scope: "RECV", Skip: true},
+				},
+			},
+			{
+				Filename: "/work/tests/sub.test.vcl",
+				Cases: []*TestCase{
+					{
+						Name:  "soft",
+						Scope: "DELIVER",
+						Error: &errors.AssertionErrors{
+							Errors: []error{
+								&errors.AssertionError{Token: tok, Message: "first"},
+								fmt.Errorf("boom"),
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := NewTAPEncoder(&buf, "/work").Encode(factory); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	expect := `TAP version 13
+1..5
+# tests/main.test.vcl
+ok 1 - [VCL_RECV] passes
+ok 2 - [VCL_RECV] passes › subtest \#1
+not ok 3 - [VCL_FETCH] group › fails
+  ---
+  message: "Assertion error: expect=foo, actual=bar"
+  actual: "bar"
+  at:
+    file: "main.vcl"
+    line: 42
+    column: 7
+  severity: fail
+  ...
+ok 4 - [VCL_RECV] skipped # SKIP
+# tests/sub.test.vcl
+not ok 5 - [VCL_DELIVER] soft
+  ---
+  message: "2 failures"
+  severity: fail
+  failures:
+    - message: "first"
+      at:
+        file: "main.vcl"
+        line: 42
+        column: 7
+    - message: "boom"
+  ...
+`
+	if diff := cmp.Diff(expect, buf.String()); diff != "" {
+		t.Errorf("TAP output mismatch, diff=%s", diff)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	ghttp "net/http"
 	"path/filepath"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -12,10 +13,9 @@ import (
 	"github.com/ysugimoto/falco/v2/config"
 	"github.com/ysugimoto/falco/v2/interpreter"
 	"github.com/ysugimoto/falco/v2/interpreter/context"
-	"github.com/ysugimoto/falco/v2/interpreter/function"
+	"github.com/ysugimoto/falco/v2/interpreter/exception"
 	"github.com/ysugimoto/falco/v2/interpreter/http"
 	"github.com/ysugimoto/falco/v2/interpreter/value"
-	"github.com/ysugimoto/falco/v2/interpreter/variable"
 	"github.com/ysugimoto/falco/v2/lexer"
 	"github.com/ysugimoto/falco/v2/parser"
 	"github.com/ysugimoto/falco/v2/resolver"
@@ -23,6 +23,7 @@ import (
 	"github.com/ysugimoto/falco/v2/tester/shared"
 	"github.com/ysugimoto/falco/v2/tester/syntax"
 	tv "github.com/ysugimoto/falco/v2/tester/variable"
+	"github.com/ysugimoto/falco/v2/trace"
 )
 
 var (
@@ -35,6 +36,16 @@ type Tester struct {
 	config             *config.TestConfig
 	counter            *shared.Counter
 	coverage           *shared.Coverage
+	main               string
+
+	// Callback which receives the result every time the test file has finished
+	onResult func(*TestResult)
+	// Run only the matched test case if set
+	only *TestCase
+	// Run only the test suites which match name pattern and tags if set
+	filter *testFilter
+	// Random seed of chaos testing
+	chaosSeed int64
 }
 
 func New(c *config.TestConfig, opts []context.Option) *Tester {
@@ -43,13 +54,135 @@ func New(c *config.TestConfig, opts []context.Option) *Tester {
 		config:             c,
 		counter:            shared.NewCounter(),
 	}
+	// Testing variables are injected to each interpreter so that test files could run concurrently
+	t.interpreterOptions = append(t.interpreterOptions, context.WithInjectedVariable(&tv.TestingVariables{}))
 	if c.Coverage {
 		t.coverage = shared.NewCoverage()
 		t.interpreterOptions = append(t.interpreterOptions, context.WithCoverage(t.coverage))
 	}
+	if c.RecordTrace != "" {
+		t.interpreterOptions = append(t.interpreterOptions, context.WithTraceDir(c.RecordTrace))
+	}
+	if c.Chaos {
+		t.chaosSeed = int64(c.ChaosSeed)
+		if t.chaosSeed == 0 {
+			t.chaosSeed = time.Now().UnixNano()
+		}
+	}
 	return t
 }
 
+// AddCoverageSink adds sinks which receive coverage markers during testing.
+// Coverage measurement is enabled if it is not enabled by the configuration
+func (t *Tester) AddCoverageSink(sinks ...shared.CoverageSink) {
+	if t.coverage == nil {
+		t.coverage = shared.NewCoverage()
+		t.interpreterOptions = append(t.interpreterOptions, context.WithCoverage(t.coverage))
+	}
+	t.coverage.AddSink(sinks...)
+}
+
+// OnResult sets the callback which is called with the result every time the test file has finished.
+// The callback is called in the testing goroutine so UI could update the progress
+func (t *Tester) OnResult(fn func(*TestResult)) {
+	t.onResult = fn
+}
+
+// RunCase runs only the test case which has the name in the test file.
+// Group is the name of describe block, empty group means the test subroutine which is not described
+func (t *Tester) RunCase(testFile, group, name string) (*TestResult, error) {
+	t.only = &TestCase{Group: group, Name: name}
+	defer func() {
+		t.only = nil
+	}()
+	return t.run(testFile)
+}
+
+func (t *Tester) isTarget(group string, metadata *Metadata) bool {
+	if t.filter != nil && !t.filter.Match(group, metadata) {
+		return false
+	}
+	if t.only == nil {
+		return true
+	}
+	name := metadata.Name
+	// Test cases which are generated from the table entries or cases have the name like "suite [key]"
+	return t.only.Group == group && (t.only.Name == name || strings.HasPrefix(t.only.Name, name+" ["))
+}
+
+// Check the test case which is generated from the table entry or case is the target
+func (t *Tester) isTargetEntry(name string) bool {
+	return t.only == nil || !strings.HasSuffix(t.only.Name, "]") || t.only.Name == name
+}
+
+// Iteration of the test suite which is generated for each table entry of @table annotation
+// or each case of @case annotation
+type iteration struct {
+	name      string
+	entry     *ast.TableProperty
+	arguments []ast.Expression
+}
+
+// Find iterations of the test suite in declaration order.
+// Table of @table annotation is looked up from main VCL first and then testing VCL.
+// Returns single empty iteration when the annotation is not specified so that the test runs once
+func (t *Tester) iterations(
+	i *interpreter.Interpreter,
+	defs *tf.Definiions,
+	metadata *Metadata,
+) ([]*iteration, error) {
+
+	switch {
+	case metadata.Table != "" && len(metadata.Cases) > 0:
+		return nil, errors.New("@table and @case annotations could not be used together")
+	case len(metadata.Cases) > 0:
+		var iterations []*iteration
+		for _, c := range metadata.Cases {
+			if c.Error != nil {
+				return nil, errors.WithStack(c.Error)
+			}
+			iterations = append(iterations, &iteration{name: c.Name, arguments: c.Arguments})
+		}
+		return iterations, nil
+	case metadata.Table == "":
+		return []*iteration{{}}, nil
+	}
+
+	table, ok := i.TestTable(metadata.Table)
+	if !ok {
+		if table, ok = defs.Tables[metadata.Table]; !ok {
+			return nil, errors.Errorf("Table %s is not found which is specified in @table annotation", metadata.Table)
+		}
+	}
+	if len(table.Properties) == 0 {
+		return nil, errors.Errorf("Table %s has no entries to generate test cases", metadata.Table)
+	}
+	iterations := make([]*iteration, len(table.Properties))
+	for j, entry := range table.Properties {
+		iterations[j] = &iteration{name: entry.Key.Value, entry: entry}
+	}
+	return iterations, nil
+}
+
+// Test case name for the iteration like "redirects [/old-path]"
+func iterationCaseName(name string, it *iteration) string {
+	if it.name == "" {
+		return name
+	}
+	return name + " [" + it.name + "]"
+}
+
+// Write execution trace file of the failed test if trace recording is enabled
+func (t *Tester) recordTrace(i *interpreter.Interpreter, name string, err error) {
+	if t.config.RecordTrace == "" || err == nil {
+		return
+	}
+	tr := i.Trace(name)
+	tr.Error = errors.Cause(err).Error()
+	// Failing to write trace file should not affect to the testing result
+	tr.WriteFile(filepath.Join(t.config.RecordTrace, trace.FileName(name))) // nolint: errcheck
+}
+
 // Find test target VCL files
 // Note that:
 // - Test files must have ".test.vcl" extension e.g default.test.vcl
@@ -78,14 +211,21 @@ func (t *Tester) Run(main string) (*TestFactory, error) {
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
+	return t.RunFiles(main, targetFiles)
+}
+
+// RunFiles runs only the provided test files, used for running affected test files on watch mode
+func (t *Tester) RunFiles(main string, targetFiles []string) (*TestFactory, error) {
+	t.main = main
+	filter, err := newTestFilter(t.config)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	t.filter = filter
 	// Run tests
-	var results []*TestResult
-	for i := range targetFiles {
-		result, err := t.run(targetFiles[i])
-		if err != nil {
-			return nil, errors.WithStack(err)
-		}
-		results = append(results, result)
+	results, err := t.runFiles(targetFiles)
+	if err != nil {
+		return nil, errors.WithStack(err)
 	}
 
 	factory := &TestFactory{
@@ -98,6 +238,55 @@ func (t *Tester) Run(main string) (*TestFactory, error) {
 	return factory, nil
 }
 
+// Run test files with the worker pool which has the number of workers specified by parallel option.
+// Each test file runs on isolated interpreters so independent files could run concurrently.
+// Results are returned in the order of files and the callback is called in the finished order
+func (t *Tester) runFiles(files []string) ([]*TestResult, error) {
+	results := make([]*TestResult, len(files))
+	errs := make([]error, len(files))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var failed atomic.Bool
+	for range min(max(1, t.config.Parallel), len(files)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := range jobs {
+				// Remaining files are not run after some file has failed to run
+				if failed.Load() {
+					continue
+				}
+				result, err := t.run(files[n])
+				if err != nil {
+					errs[n] = err
+					failed.Store(true)
+					continue
+				}
+				results[n] = result
+				if t.onResult != nil {
+					mu.Lock()
+					t.onResult(result)
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	for n := range files {
+		jobs <- n
+	}
+	close(jobs)
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+	}
+	return results, nil
+}
+
 // Actually run testing method
 func (t *Tester) run(testFile string) (*TestResult, error) {
 	resolvers, err := resolver.NewFileResolvers(testFile, t.config.IncludePaths)
@@ -118,6 +307,8 @@ func (t *Tester) run(testFile string) (*TestResult, error) {
 
 	errChan := make(chan error)
 	finishChan := make(chan []*TestCase)
+	// Snapshots are taken for each test file so that test files could run concurrently
+	snapshots := shared.NewSnapshots(t.config.UpdateSnapshots)
 
 	timeout := defaultTimeout
 	if t.config.Timeout > 0 {
@@ -126,13 +317,30 @@ func (t *Tester) run(testFile string) (*TestResult, error) {
 	timeoutChan := time.After(time.Duration(timeout) * time.Minute)
 
 	go func(vcl *ast.VCL) {
+		var cases []*TestCase
+		// Report panic as the failed result of this test file instead of crashing the process,
+		// so that other test files continue to run
+		defer func() {
+			if r := recover(); r != nil {
+				t.counter.Fail()
+				finishChan <- append(cases, &TestCase{
+					Name:  filepath.Base(testFile),
+					Error: exception.Panic(r),
+				})
+			}
+		}()
+
 		// Factory definitions in the test file
 		defs := t.factoryDefinitions(vcl)
-		var cases []*TestCase
+		// Hooks which are processed around test cases in the test file
+		hooks := findFileHooks(vcl)
 		for _, stmt := range vcl.Statements {
 			switch st := stmt.(type) {
 			case *syntax.DescribeStatement:
-				results, err := t.runDescribedTests(defs, st)
+				if t.only != nil && t.only.Group != st.Name.String() {
+					continue
+				}
+				results, err := t.runDescribedTests(testFile, main, defs, snapshots, hooks, st)
 				if len(results) > 0 {
 					cases = append(cases, results...)
 				}
@@ -141,9 +349,12 @@ func (t *Tester) run(testFile string) (*TestResult, error) {
 					return
 				}
 			case *ast.SubroutineDeclaration:
+				if isFileHook(st.Name.Value) || getTestMetadata(st).Subtest || !t.isTarget("", getTestMetadata(st)) {
+					continue
+				}
 				// Some functions like "testing.table_set()" will take side-effect for another testing subroutine
 				// so we always initialize interpreter, inject testing functions for each subroutine
-				i := t.setupInterpreter(defs)
+				i := t.setupInterpreter(defs, main, snapshots)
 
 				mockRequest, err := http.NewRequest(ghttp.MethodGet, "http://localhost", ghttp.NoBody)
 				if err != nil {
@@ -156,12 +367,13 @@ func (t *Tester) run(testFile string) (*TestResult, error) {
 					errChan <- errors.WithStack(err)
 					return
 				}
+				if err := hooks.runBeforeAll(i); err != nil {
+					errChan <- errors.WithStack(err)
+					return
+				}
 				metadata := getTestMetadata(st)
+				iterations, iterationsErr := t.iterations(i, defs, metadata)
 				for _, s := range metadata.Scopes {
-					// Attach new debugger for each test suite
-					d := NewDebugger()
-					i.Debugger = d
-
 					// Skip this testsuite when marked as @skip or @tag matched
 					if metadata.Skip || metadata.MatchTags(t.config.Tags) {
 						cases = append(cases, &TestCase{
@@ -172,20 +384,62 @@ func (t *Tester) run(testFile string) (*TestResult, error) {
 						t.counter.Skip()
 						continue
 					}
-
-					start := time.Now()
-					err := i.ProcessTestSubroutine(s, st)
-					cases = append(cases, &TestCase{
-						Name:  metadata.Name,
-						Error: errors.Cause(err),
-						Scope: s.String(),
-						Time:  time.Since(start).Milliseconds(),
-						Logs:  d.stack,
-					})
-					if err != nil {
+					if iterationsErr != nil {
+						cases = append(cases, &TestCase{
+							Name:  metadata.Name,
+							Error: iterationsErr,
+							Scope: s.String(),
+						})
 						t.counter.Fail()
+						continue
+					}
+
+					for index, it := range iterations {
+						name := iterationCaseName(metadata.Name, it)
+						if !t.isTargetEntry(name) {
+							continue
+						}
+						// Attach new debugger for each test suite
+						d := NewDebugger()
+						i.Debugger = d
+						i.SetTestTableEntry(it.entry)
+						resetTestState(i)
+
+						i.ResetTrace()
+						snapshot := t.snapshot(i)
+						start := time.Now()
+						snapshots.Begin(testFile, snapshotKey("", name, s))
+						err := hooks.process(i, s, st, it.arguments...)
+						// Chaos iterations do not compare snapshots because conditions are perturbed
+						snapshots.End()
+						t.recordTrace(i, strings.Join([]string{filepath.Base(testFile), name, s.String()}, " "), err)
+						t.writeRepro(snapshot, testFile, "", st, index, it, s, err)
+						logs := d.stack
+						subtests := t.subtests(i, "", s)
+						if err == nil {
+							if chaosLogs, chaosErr := t.runChaos(i, metadata, func() error {
+								return hooks.process(i, s, st, it.arguments...)
+							}); chaosErr != nil {
+								logs, err = chaosLogs, chaosErr
+							}
+						}
+						cases = append(cases, &TestCase{
+							Name:     name,
+							Error:    errors.Cause(err),
+							Scope:    s.String(),
+							Time:     time.Since(start).Milliseconds(),
+							Logs:     logs,
+							Subtests: subtests,
+						})
+						if err != nil {
+							t.counter.Fail()
+						}
 					}
 				}
+				if err := hooks.runAfterAll(i); err != nil {
+					errChan <- errors.WithStack(err)
+					return
+				}
 			}
 		}
 
@@ -199,6 +453,10 @@ func (t *Tester) run(testFile string) (*TestResult, error) {
 	case <-timeoutChan:
 		return nil, ErrTimeout
 	case cases := <-finishChan:
+		// Write snapshots which are recorded on first run or updated
+		if err := snapshots.Save(); err != nil {
+			return nil, errors.WithStack(err)
+		}
 		return &TestResult{
 			Filename: testFile,
 			Cases:    cases,
@@ -208,7 +466,11 @@ func (t *Tester) run(testFile string) (*TestResult, error) {
 }
 
 func (t *Tester) runDescribedTests(
+	testFile string,
+	main *resolver.VCL,
 	defs *tf.Definiions,
+	snapshots *shared.Snapshots,
+	hooks *fileHooks,
 	d *syntax.DescribeStatement,
 ) ([]*TestCase, error) {
 
@@ -221,11 +483,14 @@ func (t *Tester) runDescribedTests(
 	mockRequest.RemoteAddr = "192.0.2.1:11111"
 
 	// describe should run as group testing, create interpreter once through tests
-	i := t.setupInterpreter(defs)
+	i := t.setupInterpreter(defs, main, snapshots)
 
 	if err := i.TestProcessInit(mockRequest); err != nil {
 		return cases, errors.WithStack(err)
 	}
+	if err := hooks.runBeforeAll(i); err != nil {
+		return cases, errors.WithStack(err)
+	}
 
 	defer func() {
 		// Remove all stored subroutines
@@ -241,68 +506,176 @@ func (t *Tester) runDescribedTests(
 
 	for _, sub := range d.Subroutines {
 		metadata := getTestMetadata(sub)
+		if metadata.Subtest || !t.isTarget(d.Name.String(), metadata) {
+			continue
+		}
+		iterations, iterationsErr := t.iterations(i, defs, metadata)
 		for _, s := range metadata.Scopes {
-			// Attach new debugger for each test suite
-			debugger := NewDebugger()
-			i.Debugger = debugger
-
 			// Skip this testsuite when marked as @skip or @tag matched
 			if metadata.Skip || metadata.MatchTags(t.config.Tags) {
 				cases = append(cases, &TestCase{
 					Name:  metadata.Name,
+					Group: d.Name.String(),
 					Scope: s.String(),
 					Skip:  true,
 				})
 				t.counter.Skip()
 				continue
 			}
+			if iterationsErr != nil {
+				cases = append(cases, &TestCase{
+					Name:  metadata.Name,
+					Group: d.Name.String(),
+					Error: iterationsErr,
+					Scope: s.String(),
+				})
+				t.counter.Fail()
+				continue
+			}
 
-			// Run before_xxx hook that corresponds to scope is exists
-			if hook, ok := d.Befores[strings.ToLower("before_"+s.String())]; ok {
-				i.SetScope(s)
-				if _, _, _, err := i.ProcessBlockStatement(
-					hook.Block.Statements,
-					interpreter.DebugPass,
-					false,
-				); err != nil {
-					return cases, err
+			for index, it := range iterations {
+				name := iterationCaseName(metadata.Name, it)
+				if !t.isTargetEntry(name) {
+					continue
+				}
+				// Attach new debugger for each test suite
+				debugger := NewDebugger()
+				i.Debugger = debugger
+				i.SetTestTableEntry(it.entry)
+				resetTestState(i)
+
+				// Take snapshot before running hook because reproduction also runs the hook
+				snapshot := t.snapshot(i)
+
+				// Run before_each hook of the test file at first, and then before_xxx hook that corresponds to scope is exists
+				beforeErr := hooks.runBeforeEach(i, s)
+				if beforeErr == nil {
+					if err := runHook(i, d.Befores, "before_", s); err != nil {
+						return cases, err
+					}
 				}
-			}
 
-			start := time.Now()
-			err := i.ProcessTestSubroutine(s, sub)
-			cases = append(cases, &TestCase{
-				Name:  metadata.Name,
-				Group: d.Name.String(),
-				Error: errors.Cause(err),
-				Scope: s.String(),
-				Time:  time.Since(start).Milliseconds(),
-				Logs:  debugger.stack,
-			})
-			if err != nil {
-				t.counter.Fail()
-			}
+				i.ResetTrace()
+				start := time.Now()
+				snapshots.Begin(testFile, snapshotKey(d.Name.String(), name, s))
+				err := beforeErr
+				if err == nil {
+					err = i.ProcessTestSubroutine(s, sub, it.arguments...)
+				}
+				snapshots.End()
+				t.recordTrace(i, strings.Join([]string{filepath.Base(testFile), d.Name.String(), name, s.String()}, " "), err)
+				t.writeRepro(snapshot, testFile, d.Name.String(), sub, index, it, s, err)
+				tc := &TestCase{
+					Name:     name,
+					Group:    d.Name.String(),
+					Error:    errors.Cause(err),
+					Scope:    s.String(),
+					Time:     time.Since(start).Milliseconds(),
+					Logs:     debugger.stack,
+					Subtests: t.subtests(i, d.Name.String(), s),
+				}
+				cases = append(cases, tc)
+				if err != nil {
+					t.counter.Fail()
+				}
 
-			// Run after_xxx hook that corresponds to scope is exists
-			if hook, ok := d.Afters[strings.ToLower("after_"+s.String())]; ok {
-				i.SetScope(s)
-				if _, _, _, err := i.ProcessBlockStatement(
-					hook.Block.Statements,
-					interpreter.DebugPass,
-					false,
-				); err != nil {
+				// Run after_xxx hook that corresponds to scope is exists, and then after_each hook of the test file
+				if err := runHook(i, d.Afters, "after_", s); err != nil {
 					return cases, err
 				}
+				if hookErr := hooks.runAfterEach(i, s); hookErr != nil && err == nil {
+					err = hookErr
+					tc.Error = errors.Cause(err)
+					t.counter.Fail()
+				}
+
+				// Chaos iterations also run hooks around the test so that each iteration starts from the same condition
+				if err != nil {
+					continue
+				}
+				if logs, chaosErr := t.runChaos(i, metadata, func() error {
+					if err := hooks.runBeforeEach(i, s); err != nil {
+						return err
+					}
+					if err := runHook(i, d.Befores, "before_", s); err != nil {
+						return err
+					}
+					err := i.ProcessTestSubroutine(s, sub, it.arguments...)
+					if hookErr := runHook(i, d.Afters, "after_", s); err == nil {
+						err = hookErr
+					}
+					if hookErr := hooks.runAfterEach(i, s); err == nil {
+						err = hookErr
+					}
+					return err
+				}); chaosErr != nil {
+					tc.Error = chaosErr
+					tc.Logs = logs
+					t.counter.Fail()
+				}
 			}
 		}
 	}
 
+	if err := hooks.runAfterAll(i); err != nil {
+		return cases, errors.WithStack(err)
+	}
 	return cases, nil
 }
 
+// Reset the interpreter state which is modified by the previous test case.
+// Table values, ACL entries, variables, soft assertion mode, subtests, subroutine calls, backend requests,
+// mocked backend responses, fetch hooks and coverage hits of the previous test case should not affect to the next one
+func resetTestState(i *interpreter.Interpreter) {
+	i.RestoreTestTables()
+	i.ResetInjectedAcls()
+	i.RestoreOverrideVariables()
+	i.ResetSoftAssertions()
+	i.ResetSubtests()
+	i.ResetSubroutineCalls()
+	i.ResetBackendRequests()
+	i.ResetMockBackendResponses()
+	i.ResetFetchHooks()
+	i.ResetCoverageHits()
+}
+
+// Convert results of subtests which are processed by testing.run in the test case.
+// Failed subtests are counted independently from the test case
+func (t *Tester) subtests(i *interpreter.Interpreter, group string, scope context.Scope) []*TestCase {
+	var cases []*TestCase
+	for _, st := range i.Subtests() {
+		cases = append(cases, &TestCase{
+			Name:  st.Name,
+			Group: group,
+			Error: st.Error,
+			Scope: scope.String(),
+			Time:  st.Time,
+		})
+		if st.Error != nil {
+			t.counter.Fail()
+		}
+	}
+	return cases
+}
+
+// Snapshot key is unique in the test file
+func snapshotKey(group, name string, scope context.Scope) string {
+	if group != "" {
+		name = group + " > " + name
+	}
+	return name + " [" + scope.String() + "]"
+}
+
 // Set up interprete for each test subroutines
-func (t *Tester) setupInterpreter(defs *tf.Definiions) *interpreter.Interpreter {
+func (t *Tester) setupInterpreter(
+	defs *tf.Definiions,
+	main *resolver.VCL,
+	snapshots *shared.Snapshots,
+) *interpreter.Interpreter {
+
 	i := interpreter.New(t.interpreterOptions...)
+	// Exceptions in testing VCL are also rendered with the source excerpt
+	i.AddSource(main.Name, main.Data)
 	i.Debugger = NewDebugger() // store the default debugger
 	i.IdentResolver = func(val string) value.Value {
 		if v, ok := defs.Backends[val]; ok {
@@ -317,8 +690,12 @@ func (t *Tester) setupInterpreter(defs *tf.Definiions) *interpreter.Interpreter
 		}
 		return nil
 	}
-	variable.Inject(&tv.TestingVariables{})
-	function.Inject(tf.TestingFunctions(i, defs, t.counter, t.coverage))
+	// Headers which are set in testing VCL are also known on strict headers mode
+	for _, sub := range defs.Subroutines {
+		i.CollectHeaders(sub.Block.Statements)
+	}
+	// Testing functions are injected to each interpreter because they are bound to the interpreter
+	i.InjectFunctions(tf.TestingFunctions(i, defs, t.counter, t.coverage, snapshots))
 
 	return i
 }
@@ -0,0 +1,315 @@
+package tester
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ysugimoto/falco/v2/config"
+	"github.com/ysugimoto/falco/v2/interpreter/context"
+	"github.com/ysugimoto/falco/v2/interpreter/exception"
+	"github.com/ysugimoto/falco/v2/repro"
+	"github.com/ysugimoto/falco/v2/resolver"
+)
+
+// Run test files which are written to the temporary directory with main.vcl
+func runTestFiles(t *testing.T, c *config.TestConfig, files map[string]string, opts ...context.Option) *TestFactory {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+	}
+	main := filepath.Join(dir, "main.vcl")
+	resolvers, err := resolver.NewFileResolvers(main, []string{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if c.Filter == "" {
+		c.Filter = "*.test.vcl"
+	}
+	factory, err := New(c, append([]context.Option{context.WithResolver(resolvers[0])}, opts...)).Run(main)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	return factory
+}
+
+// Collect errors of test cases keyed by test case name, nil means the test case has passed
+func caseErrors(factory *TestFactory) map[string]error {
+	errs := make(map[string]error)
+	for _, result := range factory.Results {
+		for _, c := range result.Cases {
+			errs[c.Name] = c.Error
+		}
+	}
+	return errs
+}
+
+const isolationMainVCL = `
+backend origin0 {
+  .host = "example.com";
+}
+
+sub vcl_recv {
+#FASTLY RECV
+  return(pass);
+}
+
+sub vcl_pass {
+#FASTLY PASS
+  return(pass);
+}
+
+sub vcl_fetch {
+#FASTLY FETCH
+  return(deliver);
+}
+`
+
+func TestDescribedTestIsolation(t *testing.T) {
+	t.Run("backend requests", func(t *testing.T) {
+		errs := caseErrors(runTestFiles(t, &config.TestConfig{}, map[string]string{
+			"main.vcl": isolationMainVCL,
+			"main.test.vcl": `
+describe isolation {
+  // @scope: pass
+  sub test_send {
+    testing.call_subroutine("vcl_pass");
+    assert.backend_request("origin0", 1);
+  }
+
+  // @scope: pass
+  sub test_not_send {
+    assert.backend_request("origin0", 1);
+  }
+}`,
+		}))
+		if errs["test_send"] != nil {
+			t.Errorf("Unexpected error: %s", errs["test_send"])
+		}
+		if errs["test_not_send"] == nil {
+			t.Errorf("Backend request of the previous test case must not be recorded")
+		}
+	})
+
+	t.Run("mocked backend responses", func(t *testing.T) {
+		errs := caseErrors(runTestFiles(t, &config.TestConfig{}, map[string]string{
+			"main.vcl": isolationMainVCL,
+			"main.test.vcl": `
+describe isolation {
+  // @scope: fetch
+  sub test_mock {
+    testing.mock_backend_response(origin0, 503);
+    testing.call_subroutine("vcl_fetch");
+    assert.equal(beresp.status, 503);
+  }
+
+  // @scope: fetch
+  sub test_not_mock {
+    set beresp.status = 200;
+    testing.call_subroutine("vcl_fetch");
+    assert.equal(beresp.status, 200);
+  }
+}`,
+		}))
+		for _, name := range []string{"test_mock", "test_not_mock"} {
+			if errs[name] != nil {
+				t.Errorf("Unexpected error on %s: %s", name, errs[name])
+			}
+		}
+	})
+
+	t.Run("fetch hooks", func(t *testing.T) {
+		errs := caseErrors(runTestFiles(t, &config.TestConfig{}, map[string]string{
+			"main.vcl": isolationMainVCL,
+			"main.test.vcl": `
+sub origin_returns_no_store {
+  set beresp.http.Cache-Control = "no-store";
+}
+
+describe isolation {
+  // @scope: fetch
+  sub test_hook {
+    testing.after_fetch("origin_returns_no_store");
+    testing.call_subroutine("vcl_fetch");
+    assert.equal(beresp.http.Cache-Control, "no-store");
+  }
+
+  // @scope: fetch
+  sub test_no_hook {
+    set beresp.http.Cache-Control = "public";
+    testing.call_subroutine("vcl_fetch");
+    assert.equal(beresp.http.Cache-Control, "public");
+  }
+}`,
+		}))
+		for _, name := range []string{"test_hook", "test_no_hook"} {
+			if errs[name] != nil {
+				t.Errorf("Unexpected error on %s: %s", name, errs[name])
+			}
+		}
+	})
+
+	t.Run("coverage hits", func(t *testing.T) {
+		errs := caseErrors(runTestFiles(t, &config.TestConfig{Coverage: true}, map[string]string{
+			"main.vcl": isolationMainVCL,
+			"main.test.vcl": `
+describe isolation {
+  // @scope: recv
+  sub test_call {
+    testing.call_subroutine("vcl_recv");
+    assert.covered("vcl_recv");
+  }
+
+  // @scope: recv
+  sub test_not_call {
+    assert.covered("vcl_recv");
+  }
+}`,
+		}))
+		if errs["test_call"] != nil {
+			t.Errorf("Unexpected error: %s", errs["test_call"])
+		}
+		if errs["test_not_call"] == nil {
+			t.Errorf("Coverage hits of the previous test case must not be counted")
+		}
+	})
+}
+
+func TestReproduceIteration(t *testing.T) {
+	dir := t.TempDir()
+	c := &config.TestConfig{ReproDir: dir}
+	factory := runTestFiles(t, c, map[string]string{
+		"main.vcl": isolationMainVCL,
+		"main.test.vcl": `
+// @scope: recv
+// @case: "/ok"
+// @case: "/first"
+// @case: "/second"
+sub test_path(STRING var.path) {
+  set req.url = var.path;
+  assert.equal(req.url, "/ok");
+}`,
+	})
+	errs := caseErrors(factory)
+	if errs[`test_path ["/ok"]`] != nil {
+		t.Fatalf("Unexpected error: %s", errs[`test_path ["/ok"]`])
+	}
+
+	// Both failed iterations must be dumped into individual files
+	for index, entry := range []string{`"/first"`, `"/second"`} {
+		name := "main.test.vcl test_path " + strconv.Itoa(index+1) + " " + entry + " RECV"
+		r, err := repro.ReadFile(filepath.Join(dir, repro.FileName(name)))
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if r.Iteration != index+1 || r.Entry != entry {
+			t.Errorf("Unexpected iteration, expect=%d %s, actual=%d %s", index+1, entry, r.Iteration, r.Entry)
+		}
+
+		resolvers, err := resolver.NewFileResolvers(r.Main, []string{})
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		tc, err := New(c, []context.Option{context.WithResolver(resolvers[0])}).Reproduce(r)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if tc.Name != "test_path ["+entry+"]" {
+			t.Errorf("Unexpected test case name: %s", tc.Name)
+		}
+		if tc.Error == nil || tc.Error.Error() != r.Error {
+			t.Errorf("Reproduced error must be the same as recorded one, expect=%s, actual=%v", r.Error, tc.Error)
+		}
+	}
+}
+
+func TestCallSubroutineException(t *testing.T) {
+	errs := caseErrors(runTestFiles(t, &config.TestConfig{}, map[string]string{
+		"main.vcl": `
+sub vcl_recv {
+#FASTLY RECV
+  set req.http.Foo = var.undefined;
+}`,
+		"main.test.vcl": `
+sub test_recv {
+  testing.call_subroutine("vcl_recv");
+}`,
+	}))
+	e, ok := errs["test_recv"].(*exception.Exception)
+	if !ok {
+		t.Fatalf("Expected exception but got %v", errs["test_recv"])
+	}
+	// Exception should point to the main VCL, not testing.call_subroutine in the testing VCL
+	if e.Token == nil || filepath.Base(e.Token.File) != "main.vcl" || e.Token.Line != 4 {
+		t.Errorf("Unexpected exception location: %v", e.Token)
+	}
+	if e.Excerpt == nil {
+		t.Errorf("Excerpt of the main VCL should be attached")
+	}
+}
+
+func TestParallelRun(t *testing.T) {
+	files := map[string]string{"main.vcl": isolationMainVCL}
+	for _, name := range []string{"a", "b", "c", "d"} {
+		files[name+".test.vcl"] = `
+// @scope: recv
+sub test_` + name + ` {
+  testing.call_subroutine("vcl_recv");
+  assert.equal(testing.state, "PASS");
+}`
+	}
+
+	// Testers also run concurrently like the test server does
+	for _, name := range []string{"tester1", "tester2"} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			errs := caseErrors(runTestFiles(t, &config.TestConfig{Parallel: 4}, files))
+			if len(errs) != 4 {
+				t.Fatalf("All test files should run, got %d test cases", len(errs))
+			}
+			for name, err := range errs {
+				if err != nil {
+					t.Errorf("Unexpected error on %s: %s", name, err)
+				}
+			}
+		})
+	}
+}
+
+func TestPanicInTestFile(t *testing.T) {
+	files := map[string]string{"main.vcl": isolationMainVCL}
+	for _, name := range []string{"a", "b"} {
+		files[name+".test.vcl"] = `
+// @scope: recv
+sub test_` + name + ` {
+  testing.call_subroutine("vcl_recv");
+  assert.state(pass);
+}`
+	}
+
+	// Interpreter initialization panics only once so that only the first test file fails
+	var panicked atomic.Bool
+	factory := runTestFiles(t, &config.TestConfig{}, files, func(c *context.Context) {
+		if !panicked.Swap(true) {
+			panic("unexpected")
+		}
+	})
+	if len(factory.Results) != 2 {
+		t.Fatalf("Both test files should be reported, got %d results", len(factory.Results))
+	}
+	errs := caseErrors(factory)
+	if e, ok := errs["a.test.vcl"].(*exception.Exception); !ok || e.Type != exception.SystemType {
+		t.Errorf("Panic should be reported as the failure of the test file, got %v", errs["a.test.vcl"])
+	}
+	if err, ok := errs["test_b"]; !ok || err != nil {
+		t.Errorf("Other test file should run and pass, got %v", err)
+	}
+	if factory.Statistics.Fails != 1 || factory.Statistics.Passes != 1 {
+		t.Errorf("Unexpected statistics, fails=%d, passes=%d", factory.Statistics.Fails, factory.Statistics.Passes)
+	}
+}
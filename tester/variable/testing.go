@@ -2,6 +2,7 @@ package variable
 
 import (
 	"io"
+	"strconv"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -17,6 +18,9 @@ const (
 	TESTING_SYNTHETIC_BODY     = "testing.synthetic_body"
 	TESTING_ORIGIN_HOST_HEADER = "testing.origin_host_header"
 	TESTING_RETURN_VALUE       = "testing.return_value"
+	TESTING_TABLE_KEY          = "testing.table_key"
+	TESTING_TABLE_VALUE        = "testing.table_value"
+	TESTING_REQUEST_ID         = "testing.request_id"
 )
 
 type TestingVariables struct {
@@ -62,6 +66,18 @@ func (v *TestingVariables) Get(ctx *context.Context, scope context.Scope, name s
 			return value.Null, nil
 		}
 		return ctx.TestingReturnValue, nil
+	case TESTING_TABLE_KEY:
+		if ctx.TestingTableEntry == nil {
+			return nil, errors.New("table entry is not provided, the test must be annotated with @table")
+		}
+		return &value.String{Value: ctx.TestingTableEntry.Key.Value}, nil
+	case TESTING_TABLE_VALUE:
+		if ctx.TestingTableEntry == nil {
+			return nil, errors.New("table entry is not provided, the test must be annotated with @table")
+		}
+		return &value.String{Value: tableValueString(ctx.TestingTableEntry.Value)}, nil
+	case TESTING_REQUEST_ID:
+		return &value.String{Value: ctx.CorrelationID}, nil
 	}
 
 	return nil, errors.New("Not Found")
@@ -78,6 +94,26 @@ func (v *TestingVariables) Set(
 	return errors.New("Testing variables are read-only")
 }
 
+// Non-string table values like BACKEND or RTIME are stringified as declared
+func tableValueString(v ast.Expression) string {
+	switch t := v.(type) {
+	case *ast.String:
+		return t.Value
+	case *ast.Ident:
+		return t.Value
+	case *ast.RTime:
+		return t.Value
+	case *ast.Integer:
+		return strconv.FormatInt(t.Value, 10)
+	case *ast.Float:
+		return strconv.FormatFloat(t.Value, 'f', -1, 64)
+	case *ast.Boolean:
+		return strconv.FormatBool(t.Value)
+	default:
+		return strings.TrimSpace(v.String())
+	}
+}
+
 // Get override host header for dynamic backend
 func getDynamicBackendHostHeader(backend *value.Backend) string {
 	// For dynamic backend, `.dynamic = true;` property should be found and value should be true
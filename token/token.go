@@ -132,6 +132,9 @@ const (
 	BREAK            = "BREAK"            // break
 	FALLTHROUGH      = "FALLTHROUGH"      // fallthrough
 
+	// falco extension keywords, must be expanded before uploading to Fastly
+	CONST = "CONST" // const
+
 	// Fastly Generated control syntaxes
 	// Fastly automatically generates some control syntaxes like "pragma".
 	// falco should lex them
@@ -175,6 +178,7 @@ var keywords = map[string]TokenType{
 	"break":            BREAK,
 	"fallthrough":      FALLTHROUGH,
 	"pragma":           PRAGMA,
+	"const":            CONST,
 }
 
 func LookupIdent(ident string) TokenType {
@@ -0,0 +1,142 @@
+// Package trace provides the file format of recorded execution traces.
+// The trace is recorded per request or testing subroutine and could be viewed by "falco trace view" command
+package trace
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Version of trace file format, increment when the format is changed incompatibly
+const Version = 1
+
+// File extension of trace file
+const Extension = ".trace"
+
+// Change represents variable or header change which is made while processing the step.
+// Read values are also recorded in order to know the value which is not changed through the VCL
+type Change struct {
+	Kind  string `json:"kind"`
+	Name  string `json:"name"`
+	Value string `json:"value,omitempty"`
+}
+
+// Step represents single statement execution
+type Step struct {
+	Scope      string    `json:"scope"`
+	Subroutine string    `json:"subroutine,omitempty"`
+	File       string    `json:"file"`
+	Line       int       `json:"line"`
+	Position   int       `json:"position"`
+	Changes    []*Change `json:"changes,omitempty"`
+}
+
+type Trace struct {
+	Version int    `json:"version"`
+	Name    string `json:"name"`
+	// Request ID of the traced request to correlate with logs and backend requests
+	RequestID string `json:"request_id,omitempty"`
+	Error     string `json:"error,omitempty"`
+	// VCL sources that are referenced from steps, the trace is viewable without the original files
+	Sources map[string]string `json:"sources"`
+	Steps   []*Step           `json:"steps"`
+}
+
+func New(name string) *Trace {
+	return &Trace{
+		Version: Version,
+		Name:    name,
+		Sources: make(map[string]string),
+		Steps:   []*Step{},
+	}
+}
+
+// Snapshot returns variable values at the time before the step of index is processed
+func (t *Trace) Snapshot(index int) map[string]string {
+	snapshot := make(map[string]string)
+	for i := 0; i < index && i < len(t.Steps); i++ {
+		for _, c := range t.Steps[i].Changes {
+			if c.Kind == "unset" {
+				delete(snapshot, c.Name)
+				continue
+			}
+			snapshot[c.Name] = c.Value
+		}
+	}
+	return snapshot
+}
+
+// LoadSources reads VCL files which are referenced from steps.
+// Files which could not be read are ignored because the viewer could display the trace without source
+func (t *Trace) LoadSources() {
+	for _, s := range t.Steps {
+		if s.File == "" {
+			continue
+		}
+		if _, ok := t.Sources[s.File]; ok {
+			continue
+		}
+		if b, err := os.ReadFile(s.File); err == nil {
+			t.Sources[s.File] = string(b)
+		}
+	}
+}
+
+// Write trace as gzip compressed JSON
+func (t *Trace) Write(w io.Writer) error {
+	gw := gzip.NewWriter(w)
+	if err := json.NewEncoder(gw).Encode(t); err != nil {
+		return errors.WithStack(err)
+	}
+	return errors.WithStack(gw.Close())
+}
+
+func (t *Trace) WriteFile(path string) error {
+	fp, err := os.Create(path)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer fp.Close()
+
+	return t.Write(fp)
+}
+
+func Read(r io.Reader) (*Trace, error) {
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer gr.Close()
+
+	var t Trace
+	if err := json.NewDecoder(gr).Decode(&t); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if t.Version != Version {
+		return nil, errors.Errorf("Unsupported trace file version %d", t.Version)
+	}
+	return &t, nil
+}
+
+func ReadFile(path string) (*Trace, error) {
+	fp, err := os.Open(path)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer fp.Close()
+
+	return Read(fp)
+}
+
+var fileNameReplacer = regexp.MustCompile(`[^a-zA-Z0-9_\-.]+`)
+
+// FileName returns safe file name for the trace name
+func FileName(name string) string {
+	return strings.Trim(fileNameReplacer.ReplaceAllString(name, "_"), "_") + Extension
+}
@@ -0,0 +1,58 @@
+package trace
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSnapshot(t *testing.T) {
+	tr := New("test")
+	tr.Steps = []*Step{
+		{Changes: []*Change{{Kind: "read", Name: "req.url", Value: "/"}}},
+		{Changes: []*Change{{Kind: "write", Name: "req.http.Foo", Value: "bar"}}},
+		{Changes: []*Change{{Kind: "unset", Name: "req.http.Foo"}}},
+		{},
+	}
+
+	tests := []struct {
+		index  int
+		expect map[string]string
+	}{
+		{index: 0, expect: map[string]string{}},
+		{index: 2, expect: map[string]string{"req.url": "/", "req.http.Foo": "bar"}},
+		{index: 3, expect: map[string]string{"req.url": "/"}},
+		{index: 10, expect: map[string]string{"req.url": "/"}},
+	}
+
+	for _, tt := range tests {
+		if diff := cmp.Diff(tt.expect, tr.Snapshot(tt.index)); diff != "" {
+			t.Errorf("Snapshot(%d) mismatch, diff=%s", tt.index, diff)
+		}
+	}
+}
+
+func TestReadWrite(t *testing.T) {
+	tr := New("GET /")
+	tr.Sources["main.vcl"] = "sub vcl_recv {}"
+	tr.Steps = append(tr.Steps, &Step{Scope: "RECV", File: "main.vcl", Line: 1, Position: 1})
+
+	var buf bytes.Buffer
+	if err := tr.Write(&buf); err != nil {
+		t.Fatalf("Unexpected write error: %s", err)
+	}
+	actual, err := Read(&buf)
+	if err != nil {
+		t.Fatalf("Unexpected read error: %s", err)
+	}
+	if diff := cmp.Diff(tr, actual); diff != "" {
+		t.Errorf("Read trace mismatch, diff=%s", diff)
+	}
+}
+
+func TestFileName(t *testing.T) {
+	if actual := FileName("GET /foo?bar=baz"); actual != "GET_foo_bar_baz.trace" {
+		t.Errorf("FileName mismatch, actual=%s", actual)
+	}
+}